@@ -3,12 +3,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/randalmurphal/code-indexer/internal/config"
 	"github.com/randalmurphal/code-indexer/internal/mcp"
@@ -24,23 +29,53 @@ const (
 var rootCmd = &cobra.Command{
 	Use:   "code-index-mcp",
 	Short: "MCP server for semantic code search",
-	Long:  `An MCP (Model Context Protocol) server that provides semantic code search tools for Claude Code.`,
+	Long: `An MCP (Model Context Protocol) server that provides semantic code search tools for Claude Code.
+
+The global config is read from --config, $CODE_INDEX_CONFIG, or
+~/.config/code-index/config.yaml, and any field can be overridden with a
+CODE_INDEX_<SECTION>_<FIELD> environment variable.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if configFile == "" {
+			return nil
+		}
+		if _, err := os.Stat(configFile); err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("--config: %w", err)
+		}
+		return nil
+	},
 }
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the MCP server",
-	Long:  `Start the MCP server listening on stdin/stdout for JSON-RPC messages.`,
-	RunE:  runServe,
+	Long: `Start the MCP server listening on stdin/stdout for JSON-RPC messages.
+
+With --http, serve MCP over HTTP at /mcp instead. HTTP callers must present a
+bearer token configured under mcp.http in the global config.`,
+	RunE: runServe,
+}
+
+var hashTokenCmd = &cobra.Command{
+	Use:   "hash-token",
+	Short: "Print the SHA-256 of a bearer token read from stdin",
+	Long:  `Read a bearer token from stdin and print the hash to configure as mcp.http.tokens[].sha256.`,
+	Args:  cobra.NoArgs,
+	RunE:  runHashToken,
 }
 
 var (
-	logFile string
+	configFile string
+	logFile    string
+	httpAddr   string
 )
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Global config file (default: $CODE_INDEX_CONFIG or ~/.config/code-index/config.yaml)")
 	serveCmd.Flags().StringVar(&logFile, "log-file", "", "Log file path (defaults to ~/.cache/code-index-mcp/server.log)")
+	serveCmd.Flags().StringVar(&httpAddr, "http", "", "Serve MCP over HTTP on this address (e.g. :8080) instead of stdio")
 	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(hashTokenCmd)
 }
 
 func main() {
@@ -61,7 +96,10 @@ func runServe(cmd *cobra.Command, args []string) error {
 	logger.Info("starting MCP server", "name", serverName, "version", serverVersion)
 
 	// Load configuration
-	cfg := config.DefaultConfig()
+	cfg, err := config.LoadConfig(globalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
 
 	// Get Voyage API key from environment
 	voyageKey := os.Getenv("VOYAGE_API_KEY")
@@ -78,6 +116,20 @@ func runServe(cmd *cobra.Command, args []string) error {
 
 	// Create server
 	server := mcp.NewServer(serverName, serverVersion, handler, logger)
+	server.SetLimits(mcp.Limits{
+		RequestsPerMinute: cfg.MCP.RateLimitPerMinute,
+		Burst:             cfg.MCP.RateLimitBurst,
+		MaxArgumentBytes:  cfg.MCP.MaxArgumentBytes,
+		MaxConcurrent:     cfg.MCP.MaxConcurrent,
+	})
+	if cfg.MCP.Audit.Enabled {
+		audit, err := mcp.NewAuditLog(auditPath(cfg.MCP.Audit), time.Duration(cfg.MCP.Audit.RetentionDays)*24*time.Hour)
+		if err != nil {
+			return fmt.Errorf("failed to open audit log: %w", err)
+		}
+		defer audit.Close()
+		server.SetAudit(audit)
+	}
 
 	// Set up graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -92,6 +144,10 @@ func runServe(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
+	if httpAddr != "" {
+		return serveHTTP(ctx, server, cfg, logger)
+	}
+
 	// Run server with stdin/stdout
 	if err := server.Run(ctx, os.Stdin, os.Stdout); err != nil {
 		if err == context.Canceled {
@@ -104,6 +160,81 @@ func runServe(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// serveHTTP serves MCP at /mcp behind bearer-token authentication until ctx
+// is cancelled.
+func serveHTTP(ctx context.Context, server *mcp.Server, cfg *config.Config, logger *slog.Logger) error {
+	verifier, err := buildVerifier(cfg.MCP.HTTP)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.RequireBearer(verifier, logger, server.HTTPHandler()))
+	httpServer := &http.Server{
+		Addr:              httpAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("serving MCP over HTTP", "addr", httpAddr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http server error: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("http shutdown: %w", err)
+	}
+	logger.Info("server stopped")
+	return nil
+}
+
+// auditPath is the configured audit log, by default next to the metrics log.
+func auditPath(cfg config.MCPAuditConfig) string {
+	if cfg.Path != "" {
+		return cfg.Path
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".local", "share", "code-index", "audit.jsonl")
+}
+
+// buildVerifier refuses to serve HTTP unauthenticated.
+func buildVerifier(cfg config.MCPHTTPConfig) (mcp.TokenVerifier, error) {
+	verifier, err := mcp.VerifierFromConfig(cfg)
+	if errors.Is(err, mcp.ErrNoAuthConfigured) {
+		return nil, fmt.Errorf("--http requires authentication: configure mcp.http.tokens or mcp.http.introspection in %s", globalConfigPath())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mcp.http: %w", err)
+	}
+	return verifier, nil
+}
+
+func runHashToken(cmd *cobra.Command, args []string) error {
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, 4096))
+	if err != nil {
+		return fmt.Errorf("read token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return fmt.Errorf("no token on stdin")
+	}
+	fmt.Println(mcp.HashToken(token))
+	return nil
+}
+
+func globalConfigPath() string {
+	return config.ConfigPath(configFile)
+}
+
 func setupLogging() (*slog.Logger, func(), error) {
 	path := logFile
 	if path == "" {
@@ -0,0 +1,201 @@
+// cmd/code-indexer/cache.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/cache"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the query cache",
+	Long: `Inspect and clear the Redis cache of search_code results. Lookups are
+recorded in the metrics log, so stats work from it even without Redis.`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache hit rates, lookup latency, and cached queries",
+	Long: `Show query cache lookups from the metrics log over --last: hits and
+misses overall and per repo, lookup latency, and how old cached entries were
+when they were hit. An entry's age at a hit is bounded by cache.query_ttl_minutes;
+if most hits come close to it, a longer TTL would likely turn misses into hits.
+With Redis reachable, also counts the cached queries per repo.`,
+	Args: cobra.NoArgs,
+	RunE: runCacheStats,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete cached query results",
+	Long: `Delete every cached search result, or with --repo only the repo's (and
+cross-repo searches, which may include it), bumping its index version.`,
+	Args: cobra.NoArgs,
+	RunE: runCacheClear,
+}
+
+var (
+	cacheSince string
+	cacheJSON  bool
+	cacheRepo  string
+)
+
+func init() {
+	cacheStatsCmd.Flags().StringVar(&cacheSince, "last", "7d", "Time period (e.g., 1h, 24h, 7d, 30d)")
+	cacheStatsCmd.Flags().BoolVar(&cacheJSON, "json", false, "Output as JSON")
+	cacheClearCmd.Flags().StringVar(&cacheRepo, "repo", "", "Only clear this repository's cached queries")
+	cacheClearCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+
+	cacheCmd.AddCommand(cacheStatsCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// cacheStatsOutput is the JSON of cache stats.
+type cacheStatsOutput struct {
+	*metrics.CacheStats
+	TTLMinutes    int            `json:"ttl_minutes"`
+	CachedQueries map[string]int `json:"cached_queries,omitempty"` // Per repo; omitted without Redis
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	duration, err := parseDuration(cacheSince)
+	if err != nil {
+		return fmt.Errorf("invalid time period: %w", err)
+	}
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	stats, err := metrics.NewAnalyzer(metricsLogPath()).CacheStats(duration)
+	if err != nil {
+		return fmt.Errorf("failed to read metrics: %w", err)
+	}
+	out := cacheStatsOutput{CacheStats: stats, TTLMinutes: cfg.Cache.QueryTTLMinutes}
+
+	if cfg.Storage.RedisEnabled() {
+		if redisCache, err := cache.NewRedisCacheFromConfig(cfg.Storage); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Redis unavailable, cached queries not counted: %v\n", err)
+		} else {
+			defer redisCache.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if out.CachedQueries, err = redisCache.QueryKeyCounts(ctx); err != nil {
+				return fmt.Errorf("failed to count cached queries: %w", err)
+			}
+		}
+	}
+
+	if cacheJSON {
+		return printJSON(out)
+	}
+
+	fmt.Printf("Query cache (last %s, TTL %dm):\n\n", cacheSince, out.TTLMinutes)
+	if stats.Lookups == 0 {
+		fmt.Println("  No cache lookups recorded.")
+	} else {
+		fmt.Printf("  Lookups:        %d\n", stats.Lookups)
+		fmt.Printf("  Hit rate:       %.1f%% (%d hits, %d misses)\n", stats.HitRate*100, stats.Hits, stats.Misses)
+		fmt.Printf("  Lookup latency: %.2fms avg, %.2fms p95\n", stats.AvgLatencyMs, stats.P95LatencyMs)
+		if stats.SemanticTried > 0 {
+			fmt.Printf("  Semantic hits:  %d of %d exact misses\n", stats.SemanticHits, stats.SemanticTried)
+		}
+		if stats.Hits > 0 {
+			fmt.Printf("  Age at hit:     %s median, %s p90\n",
+				time.Duration(stats.MedianHitAgeS)*time.Second, time.Duration(stats.P90HitAgeS)*time.Second)
+		}
+	}
+	if len(stats.ByRepo) == 0 && out.CachedQueries == nil {
+		return nil
+	}
+
+	repos := make(map[string]bool)
+	for repo := range stats.ByRepo {
+		repos[repo] = true
+	}
+	for repo := range out.CachedQueries {
+		repos[repo] = true
+	}
+	names := make([]string, 0, len(repos))
+	for repo := range repos {
+		names = append(names, repo)
+	}
+	sort.Strings(names)
+
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  REPO\tHITS\tMISSES\tHIT RATE\tCACHED")
+	for _, repo := range names {
+		counts := stats.ByRepo[repo]
+		if counts == nil {
+			counts = &metrics.CacheCounts{}
+		}
+		rate := "-"
+		if total := counts.Hits + counts.Misses; total > 0 {
+			rate = fmt.Sprintf("%.1f%%", float64(counts.Hits)/float64(total)*100)
+		}
+		cached := "-"
+		if out.CachedQueries != nil {
+			cached = fmt.Sprintf("%d", out.CachedQueries[repo])
+		}
+		name := repo
+		if name == "" {
+			name = "(unscoped)"
+		}
+		fmt.Fprintf(tw, "  %s\t%d\t%d\t%s\t%s\n", name, counts.Hits, counts.Misses, rate, cached)
+	}
+	return tw.Flush()
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+	if !cfg.Storage.RedisEnabled() {
+		fmt.Println("No Redis configured (storage.redis_url); nothing is cached.")
+		return nil
+	}
+	redisCache, err := cache.NewRedisCacheFromConfig(cfg.Storage)
+	if err != nil {
+		return err
+	}
+	defer redisCache.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	counts, err := redisCache.QueryKeyCounts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count cached queries: %w", err)
+	}
+
+	if cacheRepo == "" {
+		if err := redisCache.ClearQueries(ctx); err != nil {
+			return fmt.Errorf("failed to clear query cache: %w", err)
+		}
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+		fmt.Printf("Cleared %d cached queries.\n", total)
+		return nil
+	}
+
+	version, err := redisCache.InvalidateRepo(ctx, cacheRepo)
+	if err != nil {
+		return fmt.Errorf("failed to clear query cache: %w", err)
+	}
+	fmt.Printf("Cleared %d cached queries for %s and %d cross-repo (index version %d).\n",
+		counts[cacheRepo], cacheRepo, counts["all"]+counts[""], version)
+	return nil
+}
@@ -0,0 +1,81 @@
+// cmd/code-indexer/completion.go
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the index lookup so a down Qdrant doesn't hang
+// the shell.
+const completionTimeout = 2 * time.Second
+
+// completeRepoNames completes repository names from the index, plus
+// configured but not yet indexed repos under ~/repos. It backs --repo flags
+// and repo arguments; cobra's built-in "completion bash|zsh|fish" command
+// generates the scripts that call it.
+func completeRepoNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names := make(map[string]bool)
+
+	if cfg, err := config.LoadConfig(getGlobalConfigPath()); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+		if listings, err := listIndexedRepos(ctx, cfg, false); err == nil {
+			for _, l := range listings {
+				names[l.Name] = true
+			}
+		}
+	}
+	if paths, err := discoverRepos(); err == nil {
+		for _, p := range paths {
+			names[filepath.Base(p)] = true
+		}
+	}
+
+	var matches []string
+	for name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRepoArgs completes repo names for index, falling back to file
+// completion since paths are accepted too.
+func completeRepoArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, _ := completeRepoNames(cmd, args, toComplete)
+	return names, cobra.ShellCompDirectiveDefault
+}
+
+// completeIndexedRepoNames completes names that have something to remove.
+func completeIndexedRepoNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	listings, err := listIndexedRepos(ctx, cfg, false)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var matches []string
+	for _, l := range listings {
+		if strings.HasPrefix(l.Name, toComplete) {
+			matches = append(matches, l.Name)
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
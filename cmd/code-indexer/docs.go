@@ -0,0 +1,200 @@
+// cmd/code-indexer/docs.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+	"github.com/randalmurphal/code-indexer/internal/search"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Check and draft a repository's navigation docs (AGENTS.md, CLAUDE.md)",
+}
+
+var docsLintCmd = &cobra.Command{
+	Use:   "lint [repo-name-or-path]",
+	Short: "Check AGENTS.md and CLAUDE.md files against the index",
+	Long: `Check every AGENTS.md and CLAUDE.md of a repository against its indexed code
+and list, per doc:
+
+  broken files     paths in inline code that are neither on disk nor indexed
+                   (file.go:10-20 line suffixes are ignored; shortened paths
+                   such as search/handler.go match the indexed file they end)
+  broken symbols   types (UserService, Config.Load) and calls (get_user())
+                   that no indexed symbol is named
+  undocumented     subdirectories and source files next to the doc holding
+                   indexed code that the doc never mentions (drift)
+
+Test code doesn't count. Run 'code-indexer index' first so the index matches
+the working tree. Defaults to the repository in the current directory.
+
+Exits non-zero if any doc has broken references; drift is reported only.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRepoArgs,
+	RunE:              runDocsLint,
+}
+
+var docsGenerateCmd = &cobra.Command{
+	Use:   "generate <repo>/<module>",
+	Short: "Draft an AGENTS.md for a module from the index",
+	Long: `Print a draft AGENTS.md for a module (a module root, as list_modules shows
+them) built from what the index knows:
+
+  entry points    the module's most-called functions (fan-in from the Neo4j
+                  graph; by retrieval weight without it)
+  key classes     its most-called classes
+  patterns        detected patterns its code follows, with canonical examples
+  dependencies    modules it imports and is imported by (needs Neo4j)
+
+The draft marks what only a person knows with TODOs; edit it before
+committing. With --output it is written to a file, which must not exist
+unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDocsGenerate,
+}
+
+var (
+	docsLintJSON       bool
+	docsGenerateOutput string
+	docsGenerateForce  bool
+)
+
+func init() {
+	docsLintCmd.Flags().BoolVar(&docsLintJSON, "json", false, "Print the report as JSON")
+	docsGenerateCmd.Flags().StringVarP(&docsGenerateOutput, "output", "o", "", "Write the draft to this file instead of stdout")
+	docsGenerateCmd.Flags().BoolVar(&docsGenerateForce, "force", false, "Overwrite the --output file if it exists")
+	docsCmd.AddCommand(docsLintCmd, docsGenerateCmd)
+	rootCmd.AddCommand(docsCmd)
+}
+
+func runDocsLint(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	repoArg := "."
+	if len(args) > 0 {
+		repoArg = args[0]
+	}
+	absPath, err := resolveRepoPath(repoArg)
+	if err != nil {
+		return err
+	}
+	repoCfg, err := config.LoadRepoConfig(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to load repo config: %w\nRun 'code-indexer init %s' first", err, absPath)
+	}
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	qdrantStore, err := store.NewQdrantStore(cfg.Storage.QdrantURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Qdrant: %w", err)
+	}
+	defer qdrantStore.Close()
+
+	idx := indexer.NewIndexerWithClients(cfg, nil, qdrantStore)
+	report, err := idx.LintDocs(context.Background(), absPath, repoCfg)
+	if err != nil {
+		return fmt.Errorf("docs lint failed: %w", err)
+	}
+
+	if docsLintJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printDocsLint(os.Stdout, report)
+	}
+	if report.Broken() {
+		return fmt.Errorf("navigation docs of %s have broken references", report.Repo)
+	}
+	return nil
+}
+
+func printDocsLint(out io.Writer, report *indexer.DocsLintReport) {
+	fmt.Fprintf(out, "Linting %d navigation doc(s) of %s against %d indexed file(s), %d symbol(s)\n",
+		len(report.Docs), report.Repo, report.FilesIndexed, report.SymbolsIndexed)
+	if report.FilesIndexed == 0 {
+		fmt.Fprintln(out, "\nNote: nothing indexed for this repo; run 'code-indexer index' first")
+	}
+
+	printList := func(label string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(out, "  %s (%d):\n", label, len(items))
+		for _, item := range items {
+			fmt.Fprintf(out, "    %s\n", item)
+		}
+	}
+	ok := 0
+	for _, d := range report.Docs {
+		if !d.Broken() && !d.Drifted() {
+			ok++
+			continue
+		}
+		fmt.Fprintf(out, "\n%s\n", d.Path)
+		printList("Broken files", d.BrokenFiles)
+		printList("Broken symbols", d.BrokenSymbols)
+		printList("Undocumented", d.Undocumented)
+	}
+	fmt.Fprintf(out, "\n%d of %d doc(s) up to date\n", ok, len(report.Docs))
+}
+
+func runDocsGenerate(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	repo, module, ok := strings.Cut(args[0], "/")
+	if !ok || repo == "" || module == "" {
+		return fmt.Errorf("expected <repo>/<module>, got %q", args[0])
+	}
+	if docsGenerateOutput != "" && !docsGenerateForce {
+		if _, err := os.Stat(docsGenerateOutput); err == nil {
+			return fmt.Errorf("%s already exists; use --force to overwrite it", docsGenerateOutput)
+		}
+	}
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+	// The overview reads stored chunks and the graph; nothing is embedded
+	handler, err := search.NewHandler(cfg, os.Getenv("VOYAGE_API_KEY"), slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	if err != nil {
+		return fmt.Errorf("failed to create handler: %w", err)
+	}
+	defer handler.Close()
+
+	overview, err := handler.LoadModuleOverview(context.Background(), repo, module)
+	if err != nil {
+		return fmt.Errorf("load module %s: %w", module, err)
+	}
+	if overview.Empty() {
+		return fmt.Errorf("module %q not found in %s; run 'code-indexer index' first or check the module name", module, repo)
+	}
+	if len(overview.Docs) > 0 {
+		fmt.Fprintf(os.Stderr, "Note: %s already has navigation docs (%s); the draft doesn't include them\n", module, overview.Docs[0].FilePath)
+	}
+
+	draft := search.RenderAgentsDraft(*overview)
+	if docsGenerateOutput == "" {
+		fmt.Print(draft)
+		return nil
+	}
+	if err := os.WriteFile(docsGenerateOutput, []byte(draft), 0o644); err != nil {
+		return fmt.Errorf("write draft: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote draft to %s\n", docsGenerateOutput)
+	return nil
+}
@@ -0,0 +1,268 @@
+// cmd/code-indexer/doctor.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/cache"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/embedding"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration and backend connectivity",
+	Long: `Check everything indexing and search depend on: required environment
+variables, the global config, connectivity to Qdrant, Neo4j, Redis, and Voyage,
+whether the chunks collection matches the configured embedding dimension,
+whether APOC is installed, and whether the log and metrics directories are
+writable. Each problem is printed with a suggested fix.
+
+Exits non-zero if any required check fails. Neo4j and Redis are optional, so
+problems with them are reported as warnings.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+var doctorTimeout time.Duration
+
+func init() {
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 10*time.Second, "Timeout for each connectivity check")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+type checkStatus string
+
+const (
+	checkOK   checkStatus = "ok"
+	checkWarn checkStatus = "warn"
+	checkFail checkStatus = "FAIL"
+)
+
+type checkResult struct {
+	Name   string
+	Status checkStatus
+	Detail string
+	Fix    string
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	var results []checkResult
+	report := func(r checkResult) {
+		results = append(results, r)
+		printCheck(r)
+	}
+
+	configPath := getGlobalConfigPath()
+	cfg, err := config.LoadConfig(configPath)
+	var overrides []string
+	for _, name := range config.EnvNames() {
+		if _, ok := os.LookupEnv(name); ok {
+			overrides = append(overrides, name)
+		}
+	}
+	overridden := ""
+	if len(overrides) > 0 {
+		overridden = ", overridden by " + strings.Join(overrides, ", ")
+	}
+	switch {
+	case err != nil && strings.HasPrefix(err.Error(), config.EnvPrefix):
+		report(checkResult{"config", checkFail, err.Error(), "fix or unset the environment variable"})
+		cfg = config.DefaultConfig()
+	case err != nil:
+		report(checkResult{"config", checkFail, err.Error(),
+			fmt.Sprintf("fix the YAML in %s or move it aside to use defaults", configPath)})
+		cfg = config.DefaultConfig()
+	case fileExists(configPath):
+		report(checkResult{"config", checkOK, configPath + overridden, ""})
+	default:
+		report(checkResult{"config", checkOK, "no " + configPath + ", using defaults" + overridden, ""})
+	}
+
+	voyageKey := os.Getenv("VOYAGE_API_KEY")
+	if voyageKey == "" {
+		report(checkResult{"VOYAGE_API_KEY", checkFail, "not set",
+			"export VOYAGE_API_KEY=<key> (create one at https://dash.voyageai.com)"})
+	} else {
+		report(checkResult{"VOYAGE_API_KEY", checkOK, "set", ""})
+	}
+
+	embedder := embedding.NewVoyageClient(voyageKey, cfg.Embedding.Model)
+	report(checkQdrant(ctx, cfg, embedder.Dimension()))
+	for _, r := range checkNeo4j(cfg) {
+		report(r)
+	}
+	report(checkRedis(cfg))
+	if voyageKey != "" {
+		report(checkVoyage(ctx, cfg, embedder))
+	}
+	report(checkWritableDir("log directory", mcpLogDir()))
+	report(checkWritableDir("metrics directory", metricsDir()))
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		// The report already says what's wrong; usage text would bury it
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	fmt.Println("\nAll required checks passed.")
+	return nil
+}
+
+func printCheck(r checkResult) {
+	fmt.Printf("[%-4s] %-20s %s\n", r.Status, r.Name, r.Detail)
+	if r.Fix != "" {
+		fmt.Printf("       %-20s fix: %s\n", "", r.Fix)
+	}
+}
+
+func checkQdrant(ctx context.Context, cfg *config.Config, dimension int) checkResult {
+	const name = "Qdrant"
+	url := cfg.Storage.QdrantURL
+
+	qdrantStore, err := store.NewQdrantStore(url)
+	if err != nil {
+		return checkResult{name, checkFail, err.Error(), "check storage.qdrant_url in the global config"}
+	}
+	defer qdrantStore.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	exists, err := qdrantStore.CollectionExists(ctx, "chunks")
+	if err != nil {
+		return checkResult{name, checkFail, fmt.Sprintf("cannot reach %s: %v", url, err),
+			"start Qdrant (docker run -p 6333:6333 -p 6334:6334 qdrant/qdrant) or fix storage.qdrant_url"}
+	}
+	if !exists {
+		return checkResult{name, checkWarn, fmt.Sprintf("%s reachable, no chunks collection yet", url),
+			"run code-indexer index <repo> to create it"}
+	}
+
+	info, err := qdrantStore.CollectionInfo(ctx, "chunks")
+	if err != nil {
+		return checkResult{name, checkFail, fmt.Sprintf("failed to read chunks collection: %v", err), ""}
+	}
+	if info.VectorSize != dimension {
+		return checkResult{name, checkFail,
+			fmt.Sprintf("chunks collection has %d-dim vectors but %s produces %d", info.VectorSize, cfg.Embedding.Model, dimension),
+			"set embedding.model back to the model the index was built with, or delete the chunks collection and re-index every repo"}
+	}
+	return checkResult{name, checkOK,
+		fmt.Sprintf("%s, chunks: %d points, %d-dim, %s", url, info.PointsCount, info.VectorSize, info.Status), ""}
+}
+
+// checkNeo4j reports connectivity and, when connected, APOC availability.
+func checkNeo4j(cfg *config.Config) []checkResult {
+	const name = "Neo4j"
+	if cfg.Storage.Neo4jURL == "" {
+		return []checkResult{{name, checkWarn, "storage.neo4j_url not set; relationship tools are disabled", ""}}
+	}
+	if os.Getenv("NEO4J_PASSWORD") == "" {
+		return []checkResult{{name, checkWarn, "NEO4J_PASSWORD not set; relationship tools are disabled",
+			"export NEO4J_PASSWORD=<password> (and NEO4J_USER if not neo4j)"}}
+	}
+
+	graphStore, err := openGraphStore(cfg.Storage.Neo4jURL, "NEO4J_USER", "NEO4J_PASSWORD")
+	if err != nil {
+		return []checkResult{{name, checkWarn, err.Error(),
+			"start Neo4j, check storage.neo4j_url, and verify NEO4J_USER/NEO4J_PASSWORD"}}
+	}
+	defer graphStore.Close(context.Background())
+
+	caps := graphStore.Capabilities()
+	results := []checkResult{{name, checkOK, fmt.Sprintf("%s (%s)", cfg.Storage.Neo4jURL, caps.Agent), ""}}
+	if caps.APOC {
+		results = append(results, checkResult{"APOC", checkOK, "installed", ""})
+	} else {
+		results = append(results, checkResult{"APOC", checkWarn, "not installed; graph queries use slower fallbacks",
+			"install the APOC plugin (NEO4J_PLUGINS='[\"apoc\"]' for the Docker image)"})
+	}
+	return results
+}
+
+func checkRedis(cfg *config.Config) checkResult {
+	const name = "Redis"
+	if !cfg.Storage.RedisEnabled() {
+		return checkResult{name, checkWarn, "storage.redis_url (or storage.redis.addrs) not set; query caching is disabled", ""}
+	}
+	redisCache, err := cache.NewRedisCacheFromConfig(cfg.Storage)
+	if err != nil {
+		return checkResult{name, checkWarn, err.Error(),
+			"start Redis (docker run -p 6379:6379 redis) or fix storage.redis_url / storage.redis"}
+	}
+	defer redisCache.Close()
+	switch mode := cfg.Storage.Redis.Mode; mode {
+	case config.RedisCluster, config.RedisSentinel:
+		return checkResult{name, checkOK, fmt.Sprintf("%s: %s", mode, strings.Join(cfg.Storage.Redis.Addrs, ", ")), ""}
+	}
+	return checkResult{name, checkOK, cfg.Storage.RedisURL, ""}
+}
+
+func checkVoyage(ctx context.Context, cfg *config.Config, embedder *embedding.VoyageClient) checkResult {
+	const name = "Voyage"
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	vectors, err := embedder.Embed(ctx, []string{"code-indexer doctor"})
+	if err != nil {
+		return checkResult{name, checkFail, err.Error(),
+			"check VOYAGE_API_KEY is valid and embedding.model is a Voyage model"}
+	}
+	if len(vectors) != 1 || len(vectors[0]) != embedder.Dimension() {
+		got := 0
+		if len(vectors) > 0 {
+			got = len(vectors[0])
+		}
+		return checkResult{name, checkFail,
+			fmt.Sprintf("%s returned %d-dim vectors, expected %d", cfg.Embedding.Model, got, embedder.Dimension()), ""}
+	}
+	return checkResult{name, checkOK, fmt.Sprintf("%s, %d-dim", cfg.Embedding.Model, embedder.Dimension()), ""}
+}
+
+// checkWritableDir creates dir if needed and writes a scratch file into it.
+func checkWritableDir(name, dir string) checkResult {
+	fix := fmt.Sprintf("make %s writable by this user (chown/chmod)", dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return checkResult{name, checkFail, err.Error(), fix}
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return checkResult{name, checkFail, err.Error(), fix}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return checkResult{name, checkOK, dir, ""}
+}
+
+// mcpLogDir mirrors the MCP server's default log location.
+func mcpLogDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = "/tmp"
+	}
+	return filepath.Join(cacheDir, "code-index-mcp")
+}
+
+func metricsDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".local", "share", "code-index")
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,177 @@
+// cmd/code-indexer/eval.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/eval"
+	"github.com/randalmurphal/code-indexer/internal/search"
+	"github.com/spf13/cobra"
+)
+
+var evalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Measure retrieval quality against a golden query set",
+	Long: `Run each query in a golden file through search_code on the live index and
+report recall@k, MRR (mean reciprocal rank of the first relevant file), hit
+rate, and latency, so chunking or embedding model changes can be compared.
+
+Golden file format:
+
+  repo: r3          # Default repo for every case
+  k: 10             # Default cutoff (--k overrides)
+  cases:
+    - query: where do we retry failed imports
+      expected: [fisio/imports/retry.py]
+    - query: auth middleware
+      repo: web
+      expected: ["src/auth/**"]   # Globs match any file under them
+
+Ranks count distinct files, so several chunks from one file take one slot.
+The query cache is bypassed and eval queries are not logged to the usage
+metrics.`,
+	Args: cobra.NoArgs,
+	RunE: runEval,
+}
+
+var (
+	evalGolden string
+	evalK      int
+	evalRepo   string
+	evalJSON   bool
+)
+
+func init() {
+	evalCmd.Flags().StringVar(&evalGolden, "golden", "", "Golden query file (YAML)")
+	evalCmd.Flags().IntVar(&evalK, "k", 0, "Cutoff for recall@k and MRR (default: the file's k, else 10)")
+	evalCmd.Flags().StringVar(&evalRepo, "repo", "", "Repository for cases that don't name one (overrides the file's repo)")
+	evalCmd.Flags().BoolVar(&evalJSON, "json", false, "Print the report as JSON")
+	evalCmd.MarkFlagRequired("golden")
+	evalCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+	rootCmd.AddCommand(evalCmd)
+}
+
+func runEval(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	set, err := eval.LoadGolden(evalGolden)
+	if err != nil {
+		return fmt.Errorf("failed to load golden file: %w", err)
+	}
+	if evalRepo != "" {
+		set.Repo = evalRepo
+	}
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+	// Cached answers would hide model and chunking changes and flatter latency
+	cfg.Storage.RedisURL, cfg.Storage.Redis = "", config.RedisConfig{}
+
+	voyageKey := os.Getenv("VOYAGE_API_KEY")
+	if voyageKey == "" {
+		return fmt.Errorf("VOYAGE_API_KEY environment variable not set")
+	}
+
+	handler, err := search.NewHandler(cfg, voyageKey, slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	if err != nil {
+		return fmt.Errorf("failed to create handler: %w", err)
+	}
+	defer handler.Close()
+	handler.DisableMetrics()
+
+	k := evalK
+	if k <= 0 {
+		k = set.K
+	}
+	if k <= 0 {
+		k = eval.DefaultK
+	}
+
+	report, err := eval.Run(context.Background(), set, k, evalSearch(handler, k))
+	if err != nil {
+		return fmt.Errorf("eval failed: %w", err)
+	}
+
+	if evalJSON {
+		return printJSON(report)
+	}
+	printEvalReport(os.Stdout, report)
+	return nil
+}
+
+// evalSearch adapts search_code to eval.SearchFunc. It asks for a few
+// chunks per file slot so k distinct files usually come back.
+func evalSearch(handler *search.Handler, k int) eval.SearchFunc {
+	return func(ctx context.Context, query, repo string) ([]string, error) {
+		args := map[string]interface{}{"query": query, "limit": float64(k * 3)}
+		if repo != "" {
+			args["repo"] = repo
+		}
+		result, err := handler.CallTool(ctx, "search_code", args)
+		if err != nil {
+			return nil, err
+		}
+
+		text := ""
+		if len(result.Content) > 0 {
+			text = result.Content[0].Text
+		}
+		if result.IsError {
+			return nil, errors.New(text)
+		}
+
+		var response search.PaginatedResponse
+		if json.Unmarshal([]byte(text), &response) != nil {
+			return nil, nil // Plain-text "no results" answer
+		}
+		paths := make([]string, 0, len(response.Results))
+		for _, r := range response.Results {
+			paths = append(paths, r.FilePath)
+		}
+		return paths, nil
+	}
+}
+
+func printEvalReport(out io.Writer, report *eval.Report) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "QUERY\tRANK\tRECALL\tLATENCY")
+	for _, c := range report.Cases {
+		query := c.Query
+		if len(query) > 50 {
+			query = query[:47] + "..."
+		}
+		rank := "-"
+		if c.Rank > 0 {
+			rank = fmt.Sprint(c.Rank)
+		}
+		if c.Error != "" {
+			rank = "error"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.0fms\n", query, rank, c.Recall, c.LatencyMS)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(out, "\n%d queries, k=%d\n", len(report.Cases), report.K)
+	fmt.Fprintf(out, "  recall@%d  %.3f\n", report.K, report.RecallAtK)
+	fmt.Fprintf(out, "  MRR        %.3f\n", report.MRR)
+	fmt.Fprintf(out, "  hit rate   %.3f\n", report.HitRate)
+	fmt.Fprintf(out, "  latency    p50 %.0fms, p95 %.0fms\n", report.LatencyP50MS, report.LatencyP95MS)
+	if report.Errors > 0 {
+		fmt.Fprintf(out, "  errors     %d (counted as misses)\n", report.Errors)
+		for _, c := range report.Cases {
+			if c.Error != "" {
+				fmt.Fprintf(out, "    %q: %s\n", c.Query, c.Error)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// cmd/code-indexer/graph_diff.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/spf13/cobra"
+)
+
+var graphDiffCmd = &cobra.Command{
+	Use:   "graph-diff",
+	Short: "Show dependency edges added or removed between index runs",
+	Long: `Compares IMPORTS, CALLS, and EXTENDS edges recorded by two index runs.
+Each index run keeps a snapshot of the repo's edges (graph.history_versions
+controls how many). Use --list to see recorded versions.
+
+Examples:
+  code-indexer graph-diff --repo r3 --since 7d
+  code-indexer graph-diff --repo r3 --from 1a2b3c --to 4d5e6f`,
+	RunE: runGraphDiff,
+}
+
+var (
+	graphDiffRepo  string
+	graphDiffSince string
+	graphDiffFrom  string
+	graphDiffTo    string
+	graphDiffList  bool
+)
+
+func init() {
+	graphDiffCmd.Flags().StringVar(&graphDiffRepo, "repo", "", "Repository name (default: from .ai-devtools.yaml in cwd)")
+	graphDiffCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+	graphDiffCmd.Flags().StringVar(&graphDiffSince, "since", "", "Compare against the newest version at least this old (e.g., 7d, 24h)")
+	graphDiffCmd.Flags().StringVar(&graphDiffFrom, "from", "", "Older version commit (prefix)")
+	graphDiffCmd.Flags().StringVar(&graphDiffTo, "to", "", "Newer version commit (prefix, default: latest)")
+	graphDiffCmd.Flags().BoolVar(&graphDiffList, "list", false, "List recorded versions")
+	rootCmd.AddCommand(graphDiffCmd)
+}
+
+func runGraphDiff(cmd *cobra.Command, args []string) error {
+	repo, err := resolveRepoName(graphDiffRepo)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	graphStore, err := openGraphStore(cfg.Storage.Neo4jURL, "NEO4J_USER", "NEO4J_PASSWORD")
+	if err != nil {
+		return fmt.Errorf("failed to connect to Neo4j: %w", err)
+	}
+	ctx := context.Background()
+	defer graphStore.Close(ctx)
+
+	versions, err := graphStore.ListVersions(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("failed to list graph versions: %w", err)
+	}
+	if len(versions) == 0 {
+		fmt.Printf("No graph versions recorded for %s. Index it with Neo4j configured first.\n", repo)
+		return nil
+	}
+
+	if graphDiffList {
+		for _, v := range versions {
+			fmt.Printf("  %s  %s  %d edges\n", v.IndexedAt.Format("2006-01-02 15:04"), shortCommit(v.Commit), v.EdgeCount)
+		}
+		return nil
+	}
+
+	newer := versions[0]
+	if graphDiffTo != "" {
+		v, ok := findVersion(versions, graphDiffTo)
+		if !ok {
+			return fmt.Errorf("no recorded version for commit %s", graphDiffTo)
+		}
+		newer = v
+	}
+
+	var older graph.GraphVersion
+	switch {
+	case graphDiffFrom != "":
+		v, ok := findVersion(versions, graphDiffFrom)
+		if !ok {
+			return fmt.Errorf("no recorded version for commit %s", graphDiffFrom)
+		}
+		older = v
+	case graphDiffSince != "":
+		since, err := parseDuration(graphDiffSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+		older = versionBefore(versions, time.Now().Add(-since))
+	default:
+		if len(versions) < 2 {
+			fmt.Println("Only one version recorded; nothing to compare.")
+			return nil
+		}
+		older = versions[1]
+	}
+
+	oldEdges, err := graphStore.VersionEdges(ctx, older)
+	if err != nil {
+		return err
+	}
+	newEdges, err := graphStore.VersionEdges(ctx, newer)
+	if err != nil {
+		return err
+	}
+
+	added, removed := graph.DiffEdges(oldEdges, newEdges)
+
+	fmt.Printf("%s: %s (%s) -> %s (%s)\n", repo,
+		shortCommit(older.Commit), older.IndexedAt.Format("2006-01-02 15:04"),
+		shortCommit(newer.Commit), newer.IndexedAt.Format("2006-01-02 15:04"))
+	fmt.Printf("\nAdded (%d):\n", len(added))
+	for _, e := range added {
+		fmt.Printf("  + %s\n", formatEdge(e))
+	}
+	fmt.Printf("\nRemoved (%d):\n", len(removed))
+	for _, e := range removed {
+		fmt.Printf("  - %s\n", formatEdge(e))
+	}
+
+	return nil
+}
+
+// findVersion returns the newest version whose commit starts with prefix.
+func findVersion(versions []graph.GraphVersion, prefix string) (graph.GraphVersion, bool) {
+	for _, v := range versions {
+		if v.Commit != "" && strings.HasPrefix(v.Commit, prefix) {
+			return v, true
+		}
+	}
+	return graph.GraphVersion{}, false
+}
+
+// versionBefore returns the newest version indexed at or before t, or the
+// oldest recorded version if all are newer.
+func versionBefore(versions []graph.GraphVersion, t time.Time) graph.GraphVersion {
+	for _, v := range versions {
+		if !v.IndexedAt.After(t) {
+			return v
+		}
+	}
+	return versions[len(versions)-1]
+}
+
+func formatEdge(e graph.Edge) string {
+	if e.Type == graph.RelImports {
+		return fmt.Sprintf("%s IMPORTS %s", e.SourceFile, e.TargetFile)
+	}
+	return fmt.Sprintf("%s:%s %s %s:%s", e.SourceFile, e.SourceName, e.Type, e.TargetFile, e.TargetName)
+}
+
+func shortCommit(commit string) string {
+	if commit == "" {
+		return "(no commit)"
+	}
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
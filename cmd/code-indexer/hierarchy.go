@@ -0,0 +1,105 @@
+// cmd/code-indexer/hierarchy.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/spf13/cobra"
+)
+
+var hierarchyCmd = &cobra.Command{
+	Use:   "hierarchy [class-name]",
+	Short: "Show base classes and subclasses of a class",
+	Long: `Traverses EXTENDS edges in the graph in both directions and prints the
+inheritance tree around a class with file locations. Requires Neo4j.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHierarchy,
+}
+
+var (
+	hierarchyRepo  string
+	hierarchyDepth int
+)
+
+func init() {
+	hierarchyCmd.Flags().StringVar(&hierarchyRepo, "repo", "", "Repository name (default: from .ai-devtools.yaml in cwd)")
+	hierarchyCmd.Flags().IntVar(&hierarchyDepth, "depth", graph.DefaultHierarchyDepth, "Maximum levels in each direction")
+	hierarchyCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+	rootCmd.AddCommand(hierarchyCmd)
+}
+
+func runHierarchy(cmd *cobra.Command, args []string) error {
+	className := args[0]
+
+	repo, err := resolveRepoName(hierarchyRepo)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	graphStore, err := openGraphStore(cfg.Storage.Neo4jURL, "NEO4J_USER", "NEO4J_PASSWORD")
+	if err != nil {
+		return fmt.Errorf("failed to connect to Neo4j: %w", err)
+	}
+	ctx := context.Background()
+	defer graphStore.Close(ctx)
+
+	hierarchies, err := graphStore.FindClassHierarchy(ctx, repo, className, hierarchyDepth)
+	if err != nil {
+		return err
+	}
+	if len(hierarchies) == 0 {
+		fmt.Printf("No class named %q found in %s\n", className, repo)
+		return nil
+	}
+
+	for i, h := range hierarchies {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s:%d)\n", h.Class.Name, h.Class.FilePath, h.Class.StartLine)
+		fmt.Println("  Base classes:")
+		printHierarchy(h.Ancestors, 2)
+		fmt.Println("  Subclasses:")
+		printHierarchy(h.Descendants, 2)
+	}
+
+	return nil
+}
+
+func printHierarchy(nodes []*graph.HierarchyNode, depth int) {
+	if len(nodes) == 0 && depth == 2 {
+		fmt.Println("    (none)")
+		return
+	}
+	for _, n := range nodes {
+		fmt.Printf("%s%s (%s:%d)\n", strings.Repeat("  ", depth), n.Name, n.FilePath, n.StartLine)
+		printHierarchy(n.Children, depth+1)
+	}
+}
+
+// resolveRepoName returns the given repo name, falling back to the name in
+// the current directory's repo config.
+func resolveRepoName(repo string) (string, error) {
+	if repo != "" {
+		return repo, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("--repo required: %w", err)
+	}
+	repoCfg, err := config.LoadRepoConfig(cwd)
+	if err != nil || repoCfg.Name == "" {
+		return "", fmt.Errorf("--repo required (no repo config in %s)", cwd)
+	}
+	return repoCfg.Name, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [repo]",
+	Short: "Show past index runs",
+	Long: `Show past index runs, newest first: options, files, chunks, embedding tokens,
+errors, and duration. Every 'index' and 'watch' run is appended to
+~/.local/share/code-index/history.jsonl, including failed ones.
+
+CHUNKS +/- compares a complete full run with the previous complete full run
+of the same repo, so a sudden jump or drop in index size stands out.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRepoArgs,
+	RunE:              runHistory,
+}
+
+var (
+	historyLimit int
+	historyJSON  bool
+)
+
+func init() {
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Runs to show (0 for all)")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Print JSON instead of a table")
+	rootCmd.AddCommand(historyCmd)
+}
+
+// indexHistoryPath is the log every index run is appended to.
+func indexHistoryPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".local", "share", "code-index", "history.jsonl")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	runs, err := indexer.LoadRuns(indexHistoryPath())
+	if err != nil {
+		return err
+	}
+
+	var repo string
+	if len(args) == 1 {
+		repo = args[0]
+	}
+	deltas := chunkDeltas(runs)
+
+	// Newest first, limited after filtering
+	var shown []indexer.RunRecord
+	var shownDeltas []string
+	for i := len(runs) - 1; i >= 0; i-- {
+		if repo != "" && runs[i].Repo != repo {
+			continue
+		}
+		if historyLimit > 0 && len(shown) == historyLimit {
+			break
+		}
+		shown = append(shown, runs[i])
+		shownDeltas = append(shownDeltas, deltas[i])
+	}
+
+	if historyJSON {
+		if shown == nil {
+			shown = []indexer.RunRecord{}
+		}
+		return printJSON(shown)
+	}
+
+	if len(shown) == 0 {
+		fmt.Println("No index runs recorded. Run 'code-indexer index <repo>' first.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STARTED\tREPO\tMODE\tFILES\tUNCHANGED\tCHUNKS\t+/-\tTOKENS\tERRORS\tDURATION\tSTATUS")
+	for i, r := range shown {
+		status := "ok"
+		if r.Failed != "" {
+			status = "FAILED"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%s\t%d\t%d\t%s\t%s\n",
+			r.StartedAt.Local().Format(time.DateTime), r.Repo, runMode(r), r.FilesProcessed, r.FilesSkipped,
+			r.ChunksCreated, shownDeltas[i], r.EmbeddingTokens, len(r.Errors), r.Duration().Round(100*time.Millisecond), status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// Failures are rare and their reason is the point of looking
+	for _, r := range shown {
+		if r.Failed != "" {
+			fmt.Printf("\n%s %s failed: %s\n", r.StartedAt.Local().Format(time.DateTime), r.Repo, r.Failed)
+		}
+	}
+	return nil
+}
+
+// runMode describes the options a run was started with.
+func runMode(r indexer.RunRecord) string {
+	mode := "full"
+	if r.Incremental {
+		mode = "incremental"
+	}
+	if r.Module != "" {
+		mode += " " + r.Module
+	}
+	if r.Resume {
+		mode += " (resumed)"
+	}
+	if r.Trigger == "watch" {
+		mode += " [watch]"
+	}
+	return mode
+}
+
+// chunkDeltas returns, per run, the change in chunks since the previous
+// complete full run of the same repo; blank for other runs and first runs.
+// Only complete full runs store every chunk, so only they compare.
+func chunkDeltas(runs []indexer.RunRecord) []string {
+	deltas := make([]string, len(runs))
+	last := make(map[string]int)
+	for i, r := range runs {
+		if r.Incremental || r.Module != "" || r.Resume || r.Failed != "" {
+			continue
+		}
+		if prev, ok := last[r.Repo]; ok {
+			deltas[i] = fmt.Sprintf("%+d", r.ChunksCreated-prev)
+		}
+		last[r.Repo] = r.ChunksCreated
+	}
+	return deltas
+}
@@ -3,56 +3,191 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
+	"github.com/randalmurphal/code-indexer/internal/cache"
 	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/embedding"
 	"github.com/randalmurphal/code-indexer/internal/graph"
 	"github.com/randalmurphal/code-indexer/internal/indexer"
+	"github.com/randalmurphal/code-indexer/internal/metrics"
+	"github.com/randalmurphal/code-indexer/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var indexCmd = &cobra.Command{
-	Use:   "index [repo-name-or-path]",
-	Short: "Index a repository",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runIndex,
+	Use:   "index [repo-name-or-path...]",
+	Short: "Index one or more repositories",
+	Long: `Index repositories into Qdrant (and Neo4j when configured).
+
+Pass one or more repo names (resolved under ~/repos) or paths, or --all to
+index every repository under ~/repos that has an .ai-devtools.yaml. Several
+repos are indexed concurrently, --jobs at a time, and finish with a combined
+summary table; one failing repo does not stop the others. Within a repo,
+files are read and parsed by --workers goroutines (one per CPU by default)
+while earlier chunks are embedded and stored.
+
+Each run logs the files it has finished storing to
+~/.local/share/code-index/checkpoints/<repo>.jsonl, removed when the run
+completes. If a run dies partway (sleep, API outage), --resume continues it:
+files in the log whose content is unchanged are parsed again for the graph
+but not re-embedded. Every run, failed or not, is recorded for
+'code-indexer history'.
+
+--module restricts a run to one module subtree by its dotted path, e.g.
+--module fisio.imports: only its files are walked, removed when deleted, and
+updated in the graph. Patterns and navigation docs are left to full runs.
+
+Embedding and storage failures are retried per batch; a batch that still
+fails is skipped and its files are listed as failed, to be retried by the
+next run. The command exits non-zero only when failed files pass
+indexing.error_budget_percent (default 5) of the walked files, which also
+stops the run.
+
+Hooks in the repo config (hooks.pre_index, hooks.post_index) run before and
+after each repo, with the run summary as JSON on stdin; a failing pre_index
+hook fails the repo. --no-hooks skips them.
+
+Progress is shown on stderr: a live line covering the overlapping parse,
+embed, and store stages on a terminal, or one line per finished stage
+otherwise (one line per finished repo when indexing several). --verbose
+replaces it with per-file debug logs, --quiet prints only warnings and
+errors, and --json prints a machine-readable summary on stdout for CI (an
+array when indexing several repos).`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if indexAll && len(args) > 0 {
+			return fmt.Errorf("--all takes no repository arguments")
+		}
+		if !indexAll && len(args) == 0 {
+			return fmt.Errorf("requires at least one repository, or --all")
+		}
+		if indexModule != "" && (indexAll || len(args) > 1) {
+			return fmt.Errorf("--module takes a single repository")
+		}
+		return nil
+	},
+	ValidArgsFunction: completeRepoArgs,
+	RunE:              runIndex,
 }
 
 var (
 	indexIncremental bool
+	indexVerbose     bool
+	indexQuiet       bool
+	indexJSON        bool
+	indexAll         bool
+	indexJobs        int
+	indexWorkers     int
+	indexResume      bool
+	indexModule      string
+	indexNoHooks     bool
 )
 
 func init() {
 	indexCmd.Flags().BoolVar(&indexIncremental, "incremental", false, "Only index changed files")
+	indexCmd.Flags().BoolVarP(&indexVerbose, "verbose", "v", false, "Log every file instead of showing progress")
+	indexCmd.Flags().BoolVarP(&indexQuiet, "quiet", "q", false, "Print only warnings and errors")
+	indexCmd.Flags().BoolVar(&indexJSON, "json", false, "Print a JSON summary on stdout")
+	indexCmd.Flags().BoolVar(&indexAll, "all", false, "Index every configured repository under ~/repos")
+	indexCmd.Flags().IntVarP(&indexJobs, "jobs", "j", 3, "Repositories to index concurrently")
+	indexCmd.Flags().IntVar(&indexWorkers, "workers", 0, "Files parsed concurrently per repository (default: one per CPU)")
+	indexCmd.Flags().BoolVar(&indexResume, "resume", false, "Continue an interrupted run without re-embedding files it already stored")
+	indexCmd.Flags().StringVar(&indexModule, "module", "", "Only reindex this module subtree (dotted path, e.g. fisio.imports)")
+	indexCmd.Flags().BoolVar(&indexNoHooks, "no-hooks", false, "Skip the repo's pre_index and post_index hooks")
+	indexCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
 	rootCmd.AddCommand(indexCmd)
 }
 
+// indexSummary is the --json output of index, one per repository.
+type indexSummary struct {
+	Repo             string                `json:"repo"`
+	Path             string                `json:"path"`
+	Incremental      bool                  `json:"incremental"`
+	Module           string                `json:"module,omitempty"`
+	FilesProcessed   int                   `json:"files_processed"`
+	FilesSkipped     int                   `json:"files_skipped"`
+	FilesDeleted     int                   `json:"files_deleted"`
+	FilesRenamed     int                   `json:"files_renamed"`
+	FilesResumed     int                   `json:"files_resumed"`
+	Filtered         indexer.WalkStats     `json:"filtered"`
+	ChunksCreated    int                   `json:"chunks_created"`
+	ChunksDeduped    int                   `json:"chunks_deduped"`
+	ChunksSummarized int                   `json:"chunks_summarized"`
+	SecretFiles      []string              `json:"secret_files_skipped,omitempty"` // Left out for containing secrets
+	SecretChunks     int                   `json:"secret_chunks_skipped"`
+	EmbeddingTokens  int                   `json:"embedding_tokens"`
+	Commit           string                `json:"commit,omitempty"`
+	DurationMS       int64                 `json:"duration_ms"`
+	Errors           []string              `json:"errors"`
+	ErrorPhases      map[indexer.Phase]int `json:"error_phases,omitempty"`
+	FailedFiles      []string              `json:"failed_files,omitempty"` // Retried by the next run
+	Failed           string                `json:"failed,omitempty"`       // Why the repo could not be indexed
+}
+
+// indexClients are shared by every repository indexed in one invocation.
+type indexClients struct {
+	cfg        *config.Config
+	embedder   *embedding.VoyageClient
+	store      *store.QdrantStore
+	graphStore *graph.Neo4jStore
+	cache      *cache.RedisCache // Optional: query cache invalidated by runs
+	usage      *metrics.Logger   // Optional: embedding spend of runs
+}
+
 func runIndex(cmd *cobra.Command, args []string) error {
-	repoArg := args[0]
+	// Arguments are valid by now; usage text would bury indexing errors
+	cmd.SilenceUsage = true
 
-	// Resolve repo path
-	repoPath := repoArg
-	if !filepath.IsAbs(repoPath) {
-		// Check if it's a registered repo name or relative path
-		if _, err := os.Stat(repoPath); os.IsNotExist(err) {
-			// Try ~/repos/{name}
-			homeDir, err := os.UserHomeDir()
-			if err != nil {
-				return fmt.Errorf("repository not found: %s (unable to check ~/repos)", repoPath)
-			}
-			repoPath = filepath.Join(homeDir, "repos", repoArg)
-		}
+	// Status lines go to stdout unless it carries the JSON summary
+	var out io.Writer = os.Stdout
+	switch {
+	case indexQuiet:
+		out = io.Discard
+	case indexJSON:
+		out = os.Stderr
 	}
 
-	absPath, err := filepath.Abs(repoPath)
-	if err != nil {
-		return fmt.Errorf("invalid path: %w", err)
+	logLevel := slog.LevelWarn
+	switch {
+	case indexVerbose:
+		logLevel = slog.LevelDebug
+	case indexQuiet:
+		logLevel = slog.LevelError
 	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
 
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return fmt.Errorf("repository not found: %s", absPath)
+	var repoPaths []string
+	if indexAll {
+		var err error
+		repoPaths, err = discoverRepos()
+		if err != nil {
+			return err
+		}
+		if len(repoPaths) == 0 {
+			return fmt.Errorf("no repositories with .ai-devtools.yaml found under ~/repos")
+		}
+	} else {
+		seen := make(map[string]bool)
+		for _, arg := range args {
+			absPath, err := resolveRepoPath(arg)
+			if err != nil {
+				return err
+			}
+			if !seen[absPath] {
+				seen[absPath] = true
+				repoPaths = append(repoPaths, absPath)
+			}
+		}
 	}
 
 	// Load configs
@@ -61,88 +196,411 @@ func runIndex(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load global config: %w", err)
 	}
 
-	repoCfg, err := config.LoadRepoConfig(absPath)
-	if err != nil {
-		return fmt.Errorf("failed to load repo config: %w\nRun 'code-indexer init %s' first", err, absPath)
-	}
-
 	// Get API key
 	voyageKey := os.Getenv("VOYAGE_API_KEY")
 	if voyageKey == "" {
 		return fmt.Errorf("VOYAGE_API_KEY environment variable not set")
 	}
 
-	// Create indexer
-	idx, err := indexer.NewIndexer(globalCfg, voyageKey)
+	qdrantStore, err := store.NewQdrantStore(globalCfg.Storage.QdrantURL)
 	if err != nil {
-		return fmt.Errorf("failed to create indexer: %w", err)
+		return fmt.Errorf("failed to connect to Qdrant: %w", err)
 	}
+	defer qdrantStore.Close()
 
 	ctx := context.Background()
 
-	// Connect to Neo4j for relationship storage and incremental indexing (optional)
-	var graphStore *graph.Neo4jStore
-	if globalCfg.Storage.Neo4jURL != "" {
-		neo4jUser := os.Getenv("NEO4J_USER")
-		if neo4jUser == "" {
-			neo4jUser = "neo4j"
+	clients := indexClients{
+		cfg:        globalCfg,
+		embedder:   embedding.NewVoyageClient(voyageKey, globalCfg.Embedding.Model),
+		store:      qdrantStore,
+		graphStore: connectIndexGraph(ctx, globalCfg),
+	}
+	if clients.graphStore != nil {
+		defer clients.graphStore.Close(ctx)
+	}
+	if clients.cache = connectIndexCache(globalCfg); clients.cache != nil {
+		defer clients.cache.Close()
+	}
+	if usage, err := openMetricsLog(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: metrics log unavailable, embedding spend will not be recorded: %v\n", err)
+	} else {
+		defer usage.Close()
+		clients.usage = usage
+	}
+
+	if len(repoPaths) == 1 {
+		return indexOne(ctx, clients, repoPaths[0], out)
+	}
+	return indexMany(ctx, clients, repoPaths, out)
+}
+
+// connectIndexGraph connects to Neo4j for relationship storage and
+// incremental indexing. It is optional, so failures only warn.
+// connectIndexCache connects to the Redis query cache so index runs can
+// invalidate it, or returns nil when Redis is not configured or unreachable.
+func connectIndexCache(cfg *config.Config) *cache.RedisCache {
+	if !cfg.Storage.RedisEnabled() {
+		return nil
+	}
+	redisCache, err := cache.NewRedisCacheFromConfig(cfg.Storage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Redis unavailable, cached queries will expire on their own: %v\n", err)
+		return nil
+	}
+	return redisCache
+}
+
+func connectIndexGraph(ctx context.Context, cfg *config.Config) *graph.Neo4jStore {
+	if cfg.Storage.Neo4jURL == "" {
+		return nil
+	}
+	neo4jUser := os.Getenv("NEO4J_USER")
+	if neo4jUser == "" {
+		neo4jUser = "neo4j"
+	}
+	neo4jPass := os.Getenv("NEO4J_PASSWORD")
+	if neo4jPass == "" {
+		if indexIncremental {
+			fmt.Fprintf(os.Stderr, "Warning: NEO4J_PASSWORD not set, falling back to full indexing\n")
 		}
-		neo4jPass := os.Getenv("NEO4J_PASSWORD")
-		if neo4jPass != "" {
-			graphStore, err = graph.NewNeo4jStore(globalCfg.Storage.Neo4jURL, neo4jUser, neo4jPass)
-			if err != nil {
-				fmt.Printf("Warning: Neo4j unavailable, relationships will not be stored: %v\n", err)
-			} else {
-				// Ensure schema exists for relationship storage
-				if schemaErr := graphStore.EnsureSchema(ctx); schemaErr != nil {
-					fmt.Printf("Warning: Failed to ensure Neo4j schema: %v\n", schemaErr)
+		return nil
+	}
+
+	graphStore, err := graph.NewNeo4jStore(cfg.Storage.Neo4jURL, neo4jUser, neo4jPass)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Neo4j unavailable, relationships will not be stored: %v\n", err)
+		return nil
+	}
+	// Ensure schema exists for relationship storage
+	if schemaErr := graphStore.EnsureSchema(ctx); schemaErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to ensure Neo4j schema: %v\n", schemaErr)
+	}
+	return graphStore
+}
+
+func indexOne(ctx context.Context, clients indexClients, absPath string, out io.Writer) error {
+	var progress *progressPrinter
+	var onProgress indexer.ProgressFunc
+	if !indexQuiet && !indexVerbose {
+		progress = newProgressPrinter(os.Stderr, isTerminal(os.Stderr))
+		onProgress = progress.update
+	}
+
+	summary, err := indexRepo(ctx, clients, absPath, out, onProgress)
+	if progress != nil {
+		progress.finish()
+	}
+	if err != nil {
+		if indexJSON {
+			summary.Failed = err.Error()
+			if jsonErr := printJSON(summary); jsonErr != nil {
+				return jsonErr
+			}
+		}
+		return err
+	}
+
+	if indexJSON {
+		return printJSON(summary)
+	}
+
+	// Report results
+	fmt.Fprintf(out, "\nIndexing complete:\n")
+	fmt.Fprintf(out, "  Files processed: %d\n", summary.FilesProcessed)
+	if summary.FilesSkipped > 0 {
+		fmt.Fprintf(out, "  Files unchanged: %d\n", summary.FilesSkipped)
+	}
+	if summary.FilesResumed > 0 {
+		fmt.Fprintf(out, "  Files resumed:   %d (already stored, not re-embedded)\n", summary.FilesResumed)
+	}
+	if f := summary.Filtered; f.Total() > 0 {
+		fmt.Fprintf(out, "  Files filtered:  %d (%d too large, %d binary, %d minified, %d symlinks)\n", f.Total(), f.TooLarge, f.Binary, f.Minified, f.Symlinks)
+	}
+	if summary.FilesDeleted > 0 {
+		fmt.Fprintf(out, "  Files deleted:   %d\n", summary.FilesDeleted)
+	}
+	if summary.FilesRenamed > 0 {
+		fmt.Fprintf(out, "  Files renamed:   %d\n", summary.FilesRenamed)
+	}
+	fmt.Fprintf(out, "  Chunks created:  %d\n", summary.ChunksCreated)
+	if summary.ChunksDeduped > 0 {
+		fmt.Fprintf(out, "  Chunks deduped:  %d (identical content, stored once)\n", summary.ChunksDeduped)
+	}
+	if summary.ChunksSummarized > 0 {
+		fmt.Fprintf(out, "  Chunks summarized: %d\n", summary.ChunksSummarized)
+	}
+	if len(summary.SecretFiles) > 0 {
+		fmt.Fprintf(out, "  Secret files:    %d skipped (%s)\n", len(summary.SecretFiles), strings.Join(summary.SecretFiles, ", "))
+	}
+	if summary.SecretChunks > 0 {
+		fmt.Fprintf(out, "  Secret chunks:   %d skipped\n", summary.SecretChunks)
+	}
+	if summary.EmbeddingTokens > 0 {
+		fmt.Fprintf(out, "  Embed tokens:    %d\n", summary.EmbeddingTokens)
+	}
+	if summary.Commit != "" {
+		fmt.Fprintf(out, "  Commit:          %s\n", summary.Commit)
+	}
+	fmt.Fprintf(out, "  Duration:        %s\n", (time.Duration(summary.DurationMS) * time.Millisecond).Round(100*time.Millisecond))
+
+	if len(summary.Errors) > 0 {
+		fmt.Fprintf(os.Stderr, "  Errors: %d (%s)\n", len(summary.Errors), formatErrorPhases(summary.ErrorPhases))
+		for _, e := range summary.Errors {
+			fmt.Fprintf(os.Stderr, "    - %s\n", e)
+		}
+	}
+	if len(summary.FailedFiles) > 0 {
+		fmt.Fprintf(os.Stderr, "  Failed files: %d (retried by the next run)\n", len(summary.FailedFiles))
+	}
+
+	return nil
+}
+
+// formatErrorPhases lists error counts in pipeline order, e.g.
+// "2 parse, 1 store".
+func formatErrorPhases(counts map[indexer.Phase]int) string {
+	var parts []string
+	for _, phase := range []indexer.Phase{indexer.PhaseParse, indexer.PhaseEmbed, indexer.PhaseStore, indexer.PhasePrune} {
+		if counts[phase] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[phase], phase))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// indexMany indexes repos with a bounded worker pool, reporting each as it
+// finishes and then a combined table.
+func indexMany(ctx context.Context, clients indexClients, repoPaths []string, out io.Writer) error {
+	jobs := max(1, min(indexJobs, len(repoPaths)))
+	fmt.Fprintf(out, "Indexing %d repositories, %d at a time...\n", len(repoPaths), jobs)
+
+	summaries := make([]indexSummary, len(repoPaths))
+	work := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				summary, err := indexRepo(ctx, clients, repoPaths[i], io.Discard, nil)
+				if err != nil {
+					summary.Failed = err.Error()
+				}
+				summaries[i] = summary
+
+				mu.Lock()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "  failed %s: %v\n", summary.Repo, err)
+				} else {
+					fmt.Fprintf(out, "  done   %s: %d files, %d chunks in %s\n", summary.Repo, summary.FilesProcessed,
+						summary.ChunksCreated, (time.Duration(summary.DurationMS) * time.Millisecond).Round(100*time.Millisecond))
 				}
+				mu.Unlock()
 			}
-		} else if indexIncremental {
-			fmt.Printf("Warning: NEO4J_PASSWORD not set, falling back to full indexing\n")
+		}()
+	}
+	for i := range repoPaths {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	failed := 0
+	for _, s := range summaries {
+		if s.Failed != "" {
+			failed++
+		}
+	}
+
+	if indexJSON {
+		if err := printJSON(summaries); err != nil {
+			return err
 		}
+	} else {
+		fmt.Fprintln(out)
+		printIndexTable(out, summaries)
 	}
 
-	// Run indexing
+	if failed > 0 {
+		return fmt.Errorf("%d of %d repositories failed to index", failed, len(summaries))
+	}
+	return nil
+}
 
+// indexRepo indexes one repository with its own Indexer, since an Indexer
+// holds per-repo state, sharing the connections in clients.
+func indexRepo(ctx context.Context, clients indexClients, absPath string, out io.Writer, progress indexer.ProgressFunc) (indexSummary, error) {
+	summary := indexSummary{
+		Repo:        filepath.Base(absPath),
+		Path:        absPath,
+		Incremental: indexIncremental,
+		Module:      indexModule,
+		Errors:      []string{},
+	}
+
+	repoCfg, err := config.LoadRepoConfig(absPath)
+	if err != nil {
+		return summary, fmt.Errorf("failed to load repo config: %w\nRun 'code-indexer init %s' first", err, absPath)
+	}
+	summary.Repo = repoCfg.Name
+
+	target := repoCfg.Name
+	if indexModule != "" {
+		target += " module " + indexModule
+	}
 	if indexIncremental {
-		fmt.Printf("Incremental indexing %s (%s)...\n", repoCfg.Name, absPath)
+		fmt.Fprintf(out, "Incremental indexing %s (%s)...\n", target, absPath)
 	} else {
-		fmt.Printf("Indexing %s (%s)...\n", repoCfg.Name, absPath)
+		fmt.Fprintf(out, "Indexing %s (%s)...\n", target, absPath)
 	}
 
+	idx := indexer.NewIndexerWithClients(clients.cfg, clients.embedder, clients.store)
+	if clients.cache != nil {
+		idx.SetCache(clients.cache)
+	}
+	if clients.usage != nil {
+		idx.SetMetrics(clients.usage)
+	}
+	started := time.Now()
 	result, err := idx.IndexWithOptions(ctx, absPath, repoCfg, indexer.IndexOptions{
 		Incremental: indexIncremental,
-		GraphStore:  graphStore,
+		GraphStore:  clients.graphStore,
+		Progress:    progress,
+		Workers:     indexWorkers,
+		Module:      indexModule,
+
+		CheckpointPath: indexCheckpointPath(repoCfg.Name, indexModule),
+		Resume:         indexResume,
+
+		HistoryPath: indexHistoryPath(),
+		Trigger:     "cli",
+		SkipHooks:   indexNoHooks,
 	})
+	summary.DurationMS = time.Since(started).Milliseconds()
+	if result != nil {
+		fillIndexSummary(&summary, result)
+	}
 	if err != nil {
-		return fmt.Errorf("indexing failed: %w", err)
+		return summary, fmt.Errorf("indexing failed: %w", err)
+	}
+	return summary, nil
+}
+
+// fillIndexSummary copies a run's counts and errors into summary. A run
+// stopped by its error budget still reports how far it got.
+func fillIndexSummary(summary *indexSummary, result *indexer.IndexResult) {
+	summary.FilesProcessed = result.FilesProcessed
+	summary.FilesSkipped = result.FilesSkipped
+	summary.FilesDeleted = result.FilesDeleted
+	summary.FilesRenamed = len(result.Renamed)
+	summary.FilesResumed = result.FilesResumed
+	summary.Filtered = result.Filtered
+	summary.ChunksCreated = result.ChunksCreated
+	summary.ChunksDeduped = result.ChunksDeduped
+	summary.ChunksSummarized = result.ChunksSummarized
+	summary.SecretFiles = result.SecretFilesSkipped
+	summary.SecretChunks = result.SecretChunksSkipped
+	summary.EmbeddingTokens = result.EmbeddingTokens
+	summary.Commit = result.Commit
+	for _, e := range result.Errors {
+		summary.Errors = append(summary.Errors, e.Error())
 	}
+	summary.ErrorPhases = result.ErrorCounts()
+	summary.FailedFiles = result.FailedFiles
+}
 
-	if graphStore != nil {
-		graphStore.Close(ctx)
+// indexCheckpointPath is where an index run of repo, or of one module of
+// it, logs its progress.
+func indexCheckpointPath(repo, module string) string {
+	name := repo
+	if module != "" {
+		name += "@" + module
 	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".local", "share", "code-index", "checkpoints", name+".jsonl")
+}
 
-	// Report results
-	fmt.Printf("\nIndexing complete:\n")
-	fmt.Printf("  Files processed: %d\n", result.FilesProcessed)
-	fmt.Printf("  Chunks created:  %d\n", result.ChunksCreated)
+func printIndexTable(out io.Writer, summaries []indexSummary) {
+	sorted := append([]indexSummary(nil), summaries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Repo < sorted[j].Repo })
 
-	if len(result.Errors) > 0 {
-		fmt.Printf("  Errors: %d\n", len(result.Errors))
-		for _, e := range result.Errors {
-			fmt.Printf("    - %v\n", e)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPO\tFILES\tUNCHANGED\tCHUNKS\tERRORS\tDURATION\tSTATUS")
+	var files, skipped, chunks, errs int
+	for _, s := range sorted {
+		status := "ok"
+		if s.Failed != "" {
+			status = "FAILED"
 		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n", s.Repo, s.FilesProcessed, s.FilesSkipped, s.ChunksCreated,
+			len(s.Errors), (time.Duration(s.DurationMS) * time.Millisecond).Round(100*time.Millisecond), status)
+		files += s.FilesProcessed
+		skipped += s.FilesSkipped
+		chunks += s.ChunksCreated
+		errs += len(s.Errors)
 	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\t%d\t\t\n", files, skipped, chunks, errs)
+	tw.Flush()
+}
 
-	return nil
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
 }
 
-func getGlobalConfigPath() string {
+// resolveRepoPath accepts an absolute path, a relative path, or a repo name
+// under ~/repos.
+func resolveRepoPath(repoArg string) (string, error) {
+	repoPath := repoArg
+	if !filepath.IsAbs(repoPath) {
+		// Check if it's a registered repo name or relative path
+		if _, err := os.Stat(repoPath); os.IsNotExist(err) {
+			// Try ~/repos/{name}
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("repository not found: %s (unable to check ~/repos)", repoPath)
+			}
+			repoPath = filepath.Join(homeDir, "repos", repoArg)
+		}
+	}
+
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("repository not found: %s", absPath)
+	}
+	return absPath, nil
+}
+
+// discoverRepos returns every directory directly under ~/repos that has an
+// .ai-devtools.yaml, sorted by name.
+func discoverRepos() ([]string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		// Fallback to current directory config
-		return ".code-index-config.yaml"
+		return nil, fmt.Errorf("unable to find home directory: %w", err)
+	}
+	reposDir := filepath.Join(homeDir, "repos")
+
+	entries, err := os.ReadDir(reposDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", reposDir, err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(reposDir, entry.Name())
+		if _, err := os.Stat(filepath.Join(path, ".ai-devtools.yaml")); err == nil {
+			paths = append(paths, path)
+		}
 	}
-	return filepath.Join(homeDir, ".config", "code-index", "config.yaml")
+	return paths, nil
 }
@@ -2,28 +2,48 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/indexer"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init [repo-path]",
 	Short: "Initialize indexing configuration for a repository",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runInit,
+	Long: `Scan a repository and write its .ai-devtools.yaml.
+
+Modules are detected from package markers (__init__.py, package.json, go.mod),
+include globs are proposed for each indexable file extension found, and
+vendored or generated directories are proposed as excludes. The proposal is
+shown for confirmation unless --yes is given or stdin is not a terminal.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runInit,
 }
 
+var (
+	initYes   bool
+	initForce bool
+)
+
 func init() {
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Accept the proposed config without prompting")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing config")
 	rootCmd.AddCommand(initCmd)
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
-	repoPath := args[0]
+	repoPath := "."
+	if len(args) > 0 {
+		repoPath = args[0]
+	}
 
 	// Resolve to absolute path
 	absPath, err := filepath.Abs(repoPath)
@@ -38,87 +58,109 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	// Check for existing config
 	configPath := filepath.Join(absPath, ".ai-devtools.yaml")
-	if _, err := os.Stat(configPath); err == nil {
-		fmt.Printf("Config already exists at %s\n", configPath)
+	if _, err := os.Stat(configPath); err == nil && !initForce {
+		fmt.Printf("Config already exists at %s (use --force to regenerate)\n", configPath)
 		return nil
 	}
 
-	// Detect project type and create config
-	repoName := filepath.Base(absPath)
-	defaultBranch := detectDefaultBranch(absPath)
-
-	config := map[string]interface{}{
-		"code-index": map[string]interface{}{
-			"name":           repoName,
-			"default_branch": defaultBranch,
-			"include":        detectIncludes(absPath),
-			"exclude":        []string{},
-		},
+	proposal, err := indexer.ProposeRepoConfig(absPath, detectDefaultBranch(absPath))
+	if err != nil {
+		return fmt.Errorf("failed to scan repository: %w", err)
 	}
+	cfg := proposal.Config
 
-	data, err := yaml.Marshal(config)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	printProposal(proposal)
+
+	if !initYes && isTerminal(os.Stdin) {
+		in := bufio.NewReader(os.Stdin)
+		cfg.Name = prompt(in, "Repository name", cfg.Name)
+		cfg.DefaultBranch = prompt(in, "Default branch", cfg.DefaultBranch)
+		if answer := prompt(in, fmt.Sprintf("Write %s?", configPath), "Y"); !strings.HasPrefix(strings.ToLower(answer), "y") {
+			fmt.Println("Aborted; nothing written.")
+			return nil
+		}
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if _, err := config.SaveRepoConfig(absPath, &cfg); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
 	fmt.Printf("Created %s\n", configPath)
 	fmt.Println("\nNext steps:")
 	fmt.Printf("  1. Review and customize the config file\n")
-	fmt.Printf("  2. Run: code-indexer index %s\n", repoName)
+	fmt.Printf("  2. Run: code-indexer index %s\n", cfg.Name)
 
 	return nil
 }
 
-func detectDefaultBranch(repoPath string) string {
-	// Try to read from git
-	headPath := filepath.Join(repoPath, ".git", "HEAD")
-	if data, err := os.ReadFile(headPath); err == nil {
-		// Parse "ref: refs/heads/main" or similar
-		content := string(data)
-		if strings.HasPrefix(content, "ref: refs/heads/") {
-			branch := strings.TrimPrefix(content, "ref: refs/heads/")
-			return strings.TrimSpace(branch)
+func printProposal(p *indexer.ConfigProposal) {
+	fmt.Printf("Repository: %s (branch %s)\n", p.Config.Name, p.Config.DefaultBranch)
+
+	if len(p.Extensions) == 0 {
+		fmt.Println("\nNo supported source files found; the indexer's default includes will be used.")
+	} else {
+		fmt.Println("\nSource files:")
+		for _, ext := range sortedKeys(p.Extensions) {
+			fmt.Printf("  %-6s %d\n", ext, p.Extensions[ext])
 		}
 	}
-	return "main"
-}
-
-func detectIncludes(repoPath string) []string {
-	includes := []string{}
 
-	// Check for Python
-	if hasFiles(repoPath, "*.py") {
-		includes = append(includes, "**/*.py")
+	if len(p.Excluded) > 0 {
+		fmt.Println("\nProposed excludes:")
+		for _, pattern := range sortedKeys(p.Excluded) {
+			fmt.Printf("  %-22s %d files\n", pattern, p.Excluded[pattern])
+		}
 	}
 
-	// Check for Go
-	if hasFiles(repoPath, "*.go") {
-		includes = append(includes, "**/*.go")
+	if len(p.Config.Modules) > 0 {
+		fmt.Println("\nModules:")
+		names := make([]string, 0, len(p.Config.Modules))
+		for name := range p.Config.Modules {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %-20s %s\n", name, p.Config.Modules[name].Description)
+		}
 	}
+	fmt.Println()
+}
 
-	// Check for JavaScript/TypeScript
-	if hasFiles(repoPath, "*.js") || hasFiles(repoPath, "*.ts") {
-		includes = append(includes, "**/*.js", "**/*.ts", "**/*.jsx", "**/*.tsx")
+// prompt asks a question, returning def for an empty answer or on EOF.
+func prompt(in *bufio.Reader, question, def string) string {
+	fmt.Printf("%s [%s]: ", question, def)
+	line, err := in.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" || (err != nil && err != io.EOF) {
+		return def
 	}
+	return line
+}
 
-	if len(includes) == 0 {
-		// Default
-		includes = []string{"**/*.py", "**/*.go", "**/*.js", "**/*.ts"}
-	}
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
-	return includes
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
-func hasFiles(dir string, pattern string) bool {
-	matches, _ := filepath.Glob(filepath.Join(dir, pattern))
-	if len(matches) > 0 {
-		return true
+func detectDefaultBranch(repoPath string) string {
+	// Try to read from git
+	headPath := filepath.Join(repoPath, ".git", "HEAD")
+	if data, err := os.ReadFile(headPath); err == nil {
+		// Parse "ref: refs/heads/main" or similar
+		content := string(data)
+		if strings.HasPrefix(content, "ref: refs/heads/") {
+			branch := strings.TrimPrefix(content, "ref: refs/heads/")
+			return strings.TrimSpace(branch)
+		}
 	}
-	// Check one level down
-	matches, _ = filepath.Glob(filepath.Join(dir, "*", pattern))
-	return len(matches) > 0
+	return "main"
 }
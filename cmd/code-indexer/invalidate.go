@@ -40,14 +40,17 @@ func runInvalidateFile(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load config
-	cfg := config.DefaultConfig()
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return nil // Silent fail - don't break the hook
+	}
 
 	// Connect to Redis
-	if cfg.Storage.RedisURL == "" {
+	if !cfg.Storage.RedisEnabled() {
 		return nil // No Redis configured
 	}
 
-	redisCache, err := cache.NewRedisCache(cfg.Storage.RedisURL)
+	redisCache, err := cache.NewRedisCacheFromConfig(cfg.Storage)
 	if err != nil {
 		return nil // Silent fail - don't break Claude's write
 	}
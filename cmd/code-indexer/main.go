@@ -5,15 +5,24 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/randalmurphal/code-indexer/internal/config"
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "code-indexer",
 	Short: "Semantic code indexing for Claude Code",
-	Long:  `Index codebases for semantic search and context-aware retrieval.`,
+	Long: `Index codebases for semantic search and context-aware retrieval.
+
+The global config is read from --config, $CODE_INDEX_CONFIG, or
+~/.config/code-index/config.yaml. Any field can be overridden with a
+CODE_INDEX_<SECTION>_<FIELD> environment variable, e.g.
+CODE_INDEX_STORAGE_QDRANT_URL or CODE_INDEX_EMBEDDING_MODEL.`,
+	PersistentPreRunE: checkConfigFlag,
 }
 
+var configFlag string
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
@@ -23,9 +32,28 @@ var versionCmd = &cobra.Command{
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Global config file (default: $CODE_INDEX_CONFIG or ~/.config/code-index/config.yaml)")
 	rootCmd.AddCommand(versionCmd)
 }
 
+// checkConfigFlag rejects a --config file that doesn't exist. Without the
+// flag a missing file just means defaults.
+func checkConfigFlag(cmd *cobra.Command, args []string) error {
+	if configFlag == "" {
+		return nil
+	}
+	if _, err := os.Stat(configFlag); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("--config: %w", err)
+	}
+	return nil
+}
+
+// getGlobalConfigPath is the global config file to load, honouring --config.
+func getGlobalConfigPath() string {
+	return config.ConfigPath(configFlag)
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
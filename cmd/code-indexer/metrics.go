@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/randalmurphal/code-indexer/internal/config"
 	"github.com/randalmurphal/code-indexer/internal/metrics"
 	"github.com/spf13/cobra"
 )
@@ -18,15 +19,35 @@ var metricsCmd = &cobra.Command{
 	RunE:  runMetrics,
 }
 
+// latestExperiment is --experiment without a name.
+const latestExperiment = "latest"
+
 var (
 	metricsSince       string
 	metricsZeroResults bool
+	metricsRescues     bool
+	metricsModules     bool
+	metricsFreshness   bool
+	metricsLatency     bool
+	metricsUsage       bool
+	metricsCost        bool
+	metricsExperiment  string
+	metricsRepo        string
 	metricsJSON        bool
 )
 
 func init() {
 	metricsCmd.Flags().StringVar(&metricsSince, "last", "7d", "Time period (e.g., 1h, 24h, 7d, 30d)")
 	metricsCmd.Flags().BoolVar(&metricsZeroResults, "zero-results", false, "Show only zero-result queries")
+	metricsCmd.Flags().BoolVar(&metricsRescues, "rescues", false, "Show whether zero-result searches were rescued by a follow-up search or abandoned")
+	metricsCmd.Flags().BoolVar(&metricsModules, "modules", false, "Show search volume and zero-result rate per repo and module")
+	metricsCmd.Flags().BoolVar(&metricsFreshness, "freshness", false, "Show how long after their commits syncs brought each repo's index up to date")
+	metricsCmd.Flags().BoolVar(&metricsLatency, "latency", false, "Show search latency percentiles (p50/p95/p99) in total and per stage")
+	metricsCmd.Flags().BoolVar(&metricsUsage, "usage", false, "Show how often each strategy's results were opened (mark_used) and its precision proxy")
+	metricsCmd.Flags().BoolVar(&metricsCost, "cost", false, "Show embedding tokens and their cost (embedding.pricing) per repo and index run")
+	metricsCmd.Flags().StringVar(&metricsExperiment, "experiment", "", "Compare the arms of a search experiment (without a name: the latest)")
+	metricsCmd.Flags().Lookup("experiment").NoOptDefVal = latestExperiment
+	metricsCmd.Flags().StringVar(&metricsRepo, "repo", "", "Only analyze events of this repo")
 	metricsCmd.Flags().BoolVar(&metricsJSON, "json", false, "Output as JSON")
 	rootCmd.AddCommand(metricsCmd)
 }
@@ -39,8 +60,7 @@ func runMetrics(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get metrics path
-	homeDir, _ := os.UserHomeDir()
-	metricsPath := filepath.Join(homeDir, ".local", "share", "code-index", "metrics.jsonl")
+	metricsPath := metricsLogPath()
 
 	if _, err := os.Stat(metricsPath); os.IsNotExist(err) {
 		fmt.Println("No metrics data found. Use the search_code tool to generate metrics.")
@@ -48,6 +68,120 @@ func runMetrics(cmd *cobra.Command, args []string) error {
 	}
 
 	analyzer := metrics.NewAnalyzer(metricsPath)
+	analyzer.SetRepo(metricsRepo)
+
+	if metricsExperiment != "" {
+		name := metricsExperiment
+		if name == latestExperiment {
+			name = ""
+		}
+		report, err := analyzer.Experiment(duration, name)
+		if err != nil {
+			return err
+		}
+
+		if metricsJSON {
+			data, _ := json.MarshalIndent(report, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			printExperiment(report)
+		}
+		return nil
+	}
+
+	if metricsCost {
+		cfg, err := config.LoadConfig(getGlobalConfigPath())
+		if err != nil {
+			return fmt.Errorf("failed to load global config: %w", err)
+		}
+		spend, err := analyzer.Spend(duration, cfg.Embedding.Pricing)
+		if err != nil {
+			return err
+		}
+
+		if metricsJSON {
+			data, _ := json.MarshalIndent(spend, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			printSpend(spend)
+		}
+		return nil
+	}
+
+	if metricsUsage {
+		usage, err := analyzer.Usage(duration)
+		if err != nil {
+			return err
+		}
+
+		if metricsJSON {
+			data, _ := json.MarshalIndent(usage, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			printUsage(usage)
+		}
+		return nil
+	}
+
+	if metricsLatency {
+		latency, err := analyzer.Latencies(duration)
+		if err != nil {
+			return err
+		}
+
+		if metricsJSON {
+			data, _ := json.MarshalIndent(latency, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			printLatencies(latency)
+		}
+		return nil
+	}
+
+	if metricsFreshness {
+		freshness, err := analyzer.Freshness(duration)
+		if err != nil {
+			return err
+		}
+
+		if metricsJSON {
+			data, _ := json.MarshalIndent(freshness, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			printFreshness(freshness)
+		}
+		return nil
+	}
+
+	if metricsModules {
+		modules, err := analyzer.Modules(duration)
+		if err != nil {
+			return err
+		}
+
+		if metricsJSON {
+			data, _ := json.MarshalIndent(modules, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			printModules(modules)
+		}
+		return nil
+	}
+
+	if metricsRescues {
+		stats, err := analyzer.Rescues(duration)
+		if err != nil {
+			return err
+		}
+
+		if metricsJSON {
+			data, _ := json.MarshalIndent(stats, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			printRescues(stats)
+		}
+		return nil
+	}
 
 	if metricsZeroResults {
 		queries, err := analyzer.GetZeroResultQueries(duration)
@@ -84,6 +218,9 @@ func runMetrics(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Avg latency:         %dms\n", summary.AvgLatencyMs)
 		fmt.Printf("  Cache hits:          %d\n", summary.CacheHits)
 		fmt.Printf("  Zero-result queries: %d\n", summary.ZeroResultCount)
+		if summary.FeedbackUseful+summary.FeedbackNot > 0 {
+			fmt.Printf("  Result feedback:     %d useful, %d not useful\n", summary.FeedbackUseful, summary.FeedbackNot)
+		}
 		fmt.Println()
 		if len(summary.SearchesByType) > 0 {
 			fmt.Println("  Searches by type:")
@@ -103,6 +240,161 @@ func runMetrics(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// metricsLogPath is the usage metrics log written by search handlers and
+// sync daemons.
+func metricsLogPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".local", "share", "code-index", "metrics.jsonl")
+}
+
+// openMetricsLog opens the usage metrics log for appending.
+func openMetricsLog() (*metrics.Logger, error) {
+	path := metricsLogPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create metrics dir: %w", err)
+	}
+	return metrics.NewLogger(path)
+}
+
+func printExperiment(report *metrics.ExperimentReport) {
+	if len(report.Arms) == 0 {
+		fmt.Printf("No experiment searches found (last %s).\n", metricsSince)
+		return
+	}
+	fmt.Printf("Experiment %q (last %s):\n\n", report.Name, metricsSince)
+	fmt.Printf("  %-10s %9s %7s %11s %11s %9s %9s %13s\n", "ARM", "SEARCHES", "CACHED", "ZERO-RESULT", "AVG RESULTS", "P50", "P95", "USEFUL VOTES")
+	for _, arm := range report.Arms {
+		fmt.Printf("  %-10s %9d %7d %10.0f%% %11.1f %7.0fms %7.0fms %5d/%-3d %3.0f%%\n", arm.Variant, arm.Searches, arm.CacheHits,
+			arm.ZeroResultRate*100, arm.AvgResults, arm.Latency.P50, arm.Latency.P95, arm.Useful, arm.Useful+arm.NotUseful, arm.UsefulRate*100)
+	}
+}
+
+func printSpend(report *metrics.SpendReport) {
+	fmt.Printf("Embedding spend (last %s):\n\n", metricsSince)
+	if len(report.Repos) == 0 {
+		fmt.Println("  No index runs recorded.")
+		return
+	}
+	fmt.Printf("  %-20s %6s %12s %12s %10s\n", "REPO", "RUNS", "TOKENS", "FILE TOKENS", "COST")
+	for _, r := range report.Repos {
+		fmt.Printf("  %-20s %6d %12d %12d %10.2f\n", r.Repo, r.Runs, r.Tokens, r.FileTokens, r.Cost)
+	}
+	fmt.Printf("  %-20s %6s %12d %12s %10.2f\n", "total", "", report.Tokens, "", report.Cost)
+
+	if len(report.Runs) > 0 {
+		runs := report.Runs
+		if len(runs) > 20 {
+			runs = runs[:20]
+		}
+		fmt.Printf("\nRecent runs:\n\n")
+		fmt.Printf("  %-20s %-17s %-8s %-16s %7s %12s %10s\n", "REPO", "STARTED", "TRIGGER", "MODEL", "FILES", "TOKENS", "COST")
+		for _, run := range runs {
+			fmt.Printf("  %-20s %-17s %-8s %-16s %7d %12d %10.2f\n", run.Repo, run.Run.Local().Format("2006-01-02 15:04"),
+				run.Trigger, run.Model, run.Files, run.Tokens, run.Cost)
+		}
+	}
+	if len(report.Unpriced) > 0 {
+		fmt.Printf("\n  No price for %v: set embedding.pricing (per million tokens) to cost them.\n", report.Unpriced)
+	}
+}
+
+func printUsage(stats *metrics.UsageStats) {
+	fmt.Printf("Result usage (last %s):\n\n", metricsSince)
+	if len(stats.Strategies) == 0 {
+		fmt.Println("  No searches found.")
+		return
+	}
+	fmt.Printf("  %-20s %9s %6s %9s %8s %7s %10s\n", "STRATEGY", "SEARCHES", "USED", "USE RATE", "RESULTS", "OPENED", "PRECISION")
+	for _, u := range stats.Strategies {
+		fmt.Printf("  %-20s %9d %6d %8.0f%% %8d %7d %9.0f%%\n", u.Strategy, u.Searches, u.SearchesUsed, u.UseRate*100, u.Results, u.Opened, u.Precision*100)
+	}
+	fmt.Printf("\n  mark_used calls: %d (%d not matched to a search)\n", stats.Used, stats.Unattributed)
+}
+
+func printLatencies(stats *metrics.LatencyStats) {
+	fmt.Printf("Search latency (last %s):\n\n", metricsSince)
+	if stats.Total.Count == 0 {
+		fmt.Println("  No searches found.")
+		return
+	}
+	fmt.Printf("  %-12s %7s %9s %9s %9s %9s\n", "STAGE", "COUNT", "P50", "P95", "P99", "MAX")
+	row := func(name string, p metrics.Percentiles) {
+		fmt.Printf("  %-12s %7d %7.0fms %7.0fms %7.0fms %7.0fms\n", name, p.Count, p.P50, p.P95, p.P99, p.Max)
+	}
+	row("total", stats.Total)
+	row("uncached", stats.Uncached)
+	for _, stage := range stats.StageNames() {
+		row(stage, stats.Stages[stage])
+	}
+}
+
+func printFreshness(repos []metrics.RepoFreshness) {
+	fmt.Printf("Index freshness (last %s):\n\n", metricsSince)
+	if len(repos) == 0 {
+		fmt.Println("  No syncs recorded. Freshness is logged by the watch and webhook daemons.")
+		return
+	}
+	fmt.Printf("  %-20s %6s %-17s %10s %10s %10s %10s\n", "REPO", "SYNCS", "LAST SYNC", "LAST LAG", "P50", "P95", "MAX")
+	for _, f := range repos {
+		fmt.Printf("  %-20s %6d %-17s %10s %10s %10s %10s\n", f.Repo, f.Syncs, f.LastSync.Local().Format("2006-01-02 15:04"),
+			lagString(f.LastLagS), lagString(f.P50LagS), lagString(f.P95LagS), lagString(f.MaxLagS))
+	}
+}
+
+func lagString(seconds float64) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+func printModules(modules []metrics.ModuleStats) {
+	fmt.Printf("Searches by module (last %s):\n\n", metricsSince)
+	if len(modules) == 0 {
+		fmt.Println("  No searches found.")
+		return
+	}
+	fmt.Printf("  %-20s %-30s %9s %12s\n", "REPO", "MODULE", "SEARCHES", "ZERO-RESULT")
+	for _, m := range modules {
+		repo, module := m.Repo, m.Module
+		if repo == "" || repo == "all" {
+			repo = "(all repos)"
+		}
+		if module == "" {
+			module = "(whole repo)"
+		}
+		fmt.Printf("  %-20s %-30s %9d %5d (%3.0f%%)\n", repo, module, m.Searches, m.ZeroResults, m.ZeroResultRate*100)
+	}
+}
+
+func printRescues(stats *metrics.RescueStats) {
+	fmt.Printf("Zero-result drill-down (last %s):\n\n", metricsSince)
+	if stats.ZeroResults == 0 {
+		fmt.Println("  No zero-result searches in sessions.")
+	} else {
+		fmt.Printf("  Zero-result searches: %d\n", stats.ZeroResults)
+		fmt.Printf("  Rescued:              %d (%.0f%%)\n", stats.Rescued, stats.RescueRate*100)
+		fmt.Printf("    via a suggestion:   %d (%.0f%%)\n", stats.ViaSuggestion, stats.SuggestionRate*100)
+		fmt.Printf("  Abandoned:            %d\n", stats.Abandoned)
+	}
+	if stats.Untracked > 0 {
+		fmt.Printf("  Without a session:    %d (not classified)\n", stats.Untracked)
+	}
+	if len(stats.Abandons) > 0 {
+		fmt.Println("\n  Most abandoned queries:")
+		for _, q := range stats.Abandons {
+			fmt.Printf("    - \"%s\" (%d times)\n", q.Query, q.Count)
+		}
+	}
+	if len(stats.Rescues) > 0 {
+		fmt.Println("\n  Recent rescues:")
+		for _, r := range stats.Rescues {
+			via := ""
+			if r.ViaSuggestion {
+				via = " (suggested)"
+			}
+			fmt.Printf("    - \"%s\" -> \"%s\"%s\n", r.Query, r.RescuedBy, via)
+		}
+	}
+}
+
 func parseDuration(s string) (time.Duration, error) {
 	// Handle day suffix
 	if len(s) > 0 && s[len(s)-1] == 'd' {
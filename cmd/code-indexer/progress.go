@@ -0,0 +1,120 @@
+// cmd/code-indexer/progress.go
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+)
+
+var stageUnits = map[indexer.Stage]string{
+	indexer.StageParse: "files",
+	indexer.StageEmbed: "chunks",
+	indexer.StageStore: "batches",
+}
+
+// progressPrinter renders indexer progress. Stages overlap (chunks are
+// embedded and stored while files are still parsed), so on a terminal it
+// redraws one line covering every stage, with a bar for the first one still
+// running; otherwise it prints one line as each stage finishes so CI logs
+// stay readable.
+type progressPrinter struct {
+	w        io.Writer
+	live     bool
+	stages   []indexer.Stage // In the order first reported
+	last     map[indexer.Stage]indexer.Progress
+	started  map[indexer.Stage]time.Time
+	printed  map[indexer.Stage]bool
+	lastDraw time.Time
+}
+
+func newProgressPrinter(w io.Writer, live bool) *progressPrinter {
+	p := &progressPrinter{w: w, live: live}
+	p.reset()
+	return p
+}
+
+func (p *progressPrinter) reset() {
+	p.stages = nil
+	p.last = make(map[indexer.Stage]indexer.Progress)
+	p.started = make(map[indexer.Stage]time.Time)
+	p.printed = make(map[indexer.Stage]bool)
+}
+
+func (p *progressPrinter) update(pr indexer.Progress) {
+	now := time.Now()
+	if _, seen := p.last[pr.Stage]; !seen {
+		p.stages = append(p.stages, pr.Stage)
+		p.started[pr.Stage] = now
+	}
+	p.last[pr.Stage] = pr
+	done := stageDone(pr)
+
+	if !p.live {
+		if done && !p.printed[pr.Stage] {
+			p.printed[pr.Stage] = true
+			fmt.Fprintf(p.w, "%-6s %d %s in %s\n", pr.Stage, pr.Total, stageUnits[pr.Stage], now.Sub(p.started[pr.Stage]).Round(100*time.Millisecond))
+		}
+		return
+	}
+
+	if !done && now.Sub(p.lastDraw) < 100*time.Millisecond {
+		return
+	}
+	p.lastDraw = now
+	fmt.Fprintf(p.w, "\r\033[K%s", p.line(now))
+}
+
+// finish terminates the progress line.
+func (p *progressPrinter) finish() {
+	if p.live && len(p.stages) > 0 {
+		fmt.Fprintf(p.w, "\r\033[K%s\n", p.line(time.Now()))
+	}
+	p.reset()
+}
+
+func stageDone(pr indexer.Progress) bool {
+	return pr.Total > 0 && pr.Done >= pr.Total
+}
+
+func (p *progressPrinter) line(now time.Time) string {
+	const width = 30
+
+	// The bar follows the first stage that is still running; a stage with
+	// no total yet can't be measured, so the bar waits on the one before it
+	var lead indexer.Progress
+	for _, stage := range p.stages {
+		lead = p.last[stage]
+		if !stageDone(lead) {
+			break
+		}
+	}
+	filled := width
+	if !stageDone(lead) {
+		filled = 0
+		if lead.Total > 0 {
+			filled = min(width, lead.Done*width/lead.Total)
+		}
+	}
+
+	parts := []string{"[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"}
+	for _, stage := range p.stages {
+		pr := p.last[stage]
+		if pr.Total > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d/%d %s", pr.Stage, pr.Done, pr.Total, stageUnits[pr.Stage]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %d %s", pr.Stage, pr.Done, stageUnits[pr.Stage]))
+		}
+	}
+
+	elapsed := now.Sub(p.started[lead.Stage])
+	if eta := lead.ETA(elapsed); eta > 0 {
+		parts = append(parts, fmt.Sprintf("ETA %s", eta.Round(time.Second)))
+	} else if len(p.stages) > 0 && stageDone(lead) {
+		parts = append(parts, now.Sub(p.started[p.stages[0]]).Round(100*time.Millisecond).String())
+	}
+	return strings.Join(parts, "  ")
+}
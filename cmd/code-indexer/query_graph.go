@@ -0,0 +1,184 @@
+// cmd/code-indexer/query_graph.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/spf13/cobra"
+)
+
+var queryGraphCmd = &cobra.Command{
+	Use:     "query-graph",
+	Aliases: []string{"qg"},
+	Short:   "Run common relationship queries against the graph",
+	Long: `Query the Neo4j graph without writing Cypher. Requires Neo4j and
+NEO4J_PASSWORD. Each subcommand prints a table, or JSON with --json.`,
+}
+
+var callersCmd = &cobra.Command{
+	Use:   "callers [symbol]",
+	Short: "List the symbols that call a symbol",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSymbolQuery(args[0], "callers", (*graph.Neo4jStore).FindCallers)
+	},
+}
+
+var calleesCmd = &cobra.Command{
+	Use:   "callees [symbol]",
+	Short: "List the symbols a symbol calls",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSymbolQuery(args[0], "callees", (*graph.Neo4jStore).FindCallees)
+	},
+}
+
+var relatedCmd = &cobra.Command{
+	Use:   "related [file]",
+	Short: "List files related to a file by imports or calls",
+	Long: `List files that the given file imports, that import it, or that hold
+symbols it calls or is called by. The file path is relative to the repo root.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRelated,
+}
+
+var (
+	queryGraphRepo  string
+	queryGraphJSON  bool
+	queryGraphLimit int
+)
+
+func init() {
+	queryGraphCmd.PersistentFlags().StringVar(&queryGraphRepo, "repo", "", "Repository name (default: from .ai-devtools.yaml in cwd)")
+	queryGraphCmd.PersistentFlags().BoolVar(&queryGraphJSON, "json", false, "Print JSON instead of a table")
+	relatedCmd.Flags().IntVar(&queryGraphLimit, "limit", 20, "Maximum files to list")
+	queryGraphCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+
+	queryGraphCmd.AddCommand(callersCmd, calleesCmd, relatedCmd)
+	rootCmd.AddCommand(queryGraphCmd)
+}
+
+// graphSymbol is a symbol row in query-graph output.
+type graphSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// graphFile is a file row in query-graph output.
+type graphFile struct {
+	Path       string `json:"path"`
+	ModuleRoot string `json:"module_root,omitempty"`
+}
+
+// connectQueryGraph resolves the repo and opens the graph for a query.
+func connectQueryGraph() (string, *graph.Neo4jStore, error) {
+	repo, err := resolveRepoName(queryGraphRepo)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	graphStore, err := openGraphStore(cfg.Storage.Neo4jURL, "NEO4J_USER", "NEO4J_PASSWORD")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to connect to Neo4j: %w", err)
+	}
+	return repo, graphStore, nil
+}
+
+func runSymbolQuery(symbol, relation string, find func(*graph.Neo4jStore, context.Context, string, string) ([]graph.Symbol, error)) error {
+	repo, graphStore, err := connectQueryGraph()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	defer graphStore.Close(ctx)
+
+	symbols, err := find(graphStore, ctx, repo, symbol)
+	if err != nil {
+		return fmt.Errorf("failed to find %s: %w", relation, err)
+	}
+
+	rows := make([]graphSymbol, 0, len(symbols))
+	for _, s := range symbols {
+		rows = append(rows, graphSymbol{
+			Name:      s.Name,
+			Kind:      s.Kind,
+			FilePath:  s.FilePath,
+			StartLine: s.StartLine,
+			EndLine:   s.EndLine,
+			Signature: s.Signature,
+		})
+	}
+
+	if queryGraphJSON {
+		return printJSON(map[string]interface{}{
+			"repo":   repo,
+			"symbol": symbol,
+			relation: rows,
+		})
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("No %s of %q found in %s\n", relation, symbol, repo)
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tKIND\tLOCATION")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s:%d-%d\n", r.Name, r.Kind, r.FilePath, r.StartLine, r.EndLine)
+	}
+	return tw.Flush()
+}
+
+func runRelated(cmd *cobra.Command, args []string) error {
+	filePath := args[0]
+
+	repo, graphStore, err := connectQueryGraph()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	defer graphStore.Close(ctx)
+
+	files, err := graphStore.FindRelatedFiles(ctx, repo, filePath, queryGraphLimit)
+	if err != nil {
+		return fmt.Errorf("failed to find related files: %w", err)
+	}
+
+	rows := make([]graphFile, 0, len(files))
+	for _, f := range files {
+		rows = append(rows, graphFile{Path: f.Path, ModuleRoot: f.ModuleRoot})
+	}
+
+	if queryGraphJSON {
+		return printJSON(map[string]interface{}{
+			"repo":    repo,
+			"file":    filePath,
+			"related": rows,
+		})
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("No files related to %s found in %s\n", filePath, repo)
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tMODULE")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\n", r.Path, r.ModuleRoot)
+	}
+	return tw.Flush()
+}
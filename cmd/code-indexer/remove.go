@@ -0,0 +1,142 @@
+// cmd/code-indexer/remove.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/cache"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var removeCmd = &cobra.Command{
+	Use:     "remove [repo-name]",
+	Aliases: []string{"purge"},
+	Short:   "Remove a repository from the index",
+	Long: `Delete everything indexed for a repository: its Qdrant chunks, its Neo4j
+repository, module, file, and symbol nodes, and its Redis query cache and stale
+markers. The repo's index version is bumped so any cached results still held
+elsewhere are orphaned. The repository's files and .ai-devtools.yaml are not
+touched.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeIndexedRepoNames,
+	RunE:              runRemove,
+}
+
+var removeYes bool
+
+func init() {
+	removeCmd.Flags().BoolVarP(&removeYes, "yes", "y", false, "Remove without prompting")
+	rootCmd.AddCommand(removeCmd)
+}
+
+func runRemove(cmd *cobra.Command, args []string) error {
+	repo := args[0]
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	ctx := context.Background()
+
+	qdrantStore, err := store.NewQdrantStore(cfg.Storage.QdrantURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Qdrant at %s: %w", cfg.Storage.QdrantURL, err)
+	}
+	defer qdrantStore.Close()
+
+	counts, err := qdrantStore.CountByField(ctx, "chunks", "repo", map[string]interface{}{"repo": repo})
+	if err != nil {
+		return fmt.Errorf("failed to count chunks: %w", err)
+	}
+	chunks := counts[repo]
+
+	graphStore, err := openGraphStore(cfg.Storage.Neo4jURL, "NEO4J_USER", "NEO4J_PASSWORD")
+	if err != nil {
+		fmt.Printf("Warning: skipping graph store: %v\n", err)
+		graphStore = nil
+	} else {
+		defer graphStore.Close(ctx)
+	}
+
+	// The repo path locates its stale markers; default to ~/repos/<name>
+	var repoNode *graph.Repository
+	repoPath := ""
+	if graphStore != nil {
+		repoNode, err = graphStore.GetRepository(ctx, repo)
+		if err != nil {
+			fmt.Printf("Warning: failed to look up repository in Neo4j: %v\n", err)
+		}
+		if repoNode != nil {
+			repoPath = repoNode.Path
+		}
+	}
+	if repoPath == "" {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			repoPath = filepath.Join(homeDir, "repos", repo)
+		}
+	}
+
+	if chunks == 0 && repoNode == nil {
+		return fmt.Errorf("repository %q is not indexed", repo)
+	}
+
+	fmt.Printf("Removing %s: %d chunks", repo, chunks)
+	if repoNode != nil {
+		fmt.Print(", graph nodes")
+	}
+	fmt.Println(", cached queries")
+
+	if !removeYes {
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("refusing to remove %s without confirmation; pass --yes", repo)
+		}
+		answer := prompt(bufio.NewReader(os.Stdin), "Continue?", "n")
+		if !strings.HasPrefix(strings.ToLower(answer), "y") {
+			fmt.Println("Aborted; nothing removed.")
+			return nil
+		}
+	}
+
+	if err := qdrantStore.DeleteByFilter(ctx, "chunks", map[string]interface{}{"repo": repo}); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	fmt.Printf("  Qdrant: deleted %d chunks\n", chunks)
+
+	if graphStore != nil {
+		if err := graphStore.DeleteRepository(ctx, repo); err != nil {
+			return fmt.Errorf("failed to delete graph nodes: %w", err)
+		}
+		fmt.Println("  Neo4j:  deleted repository nodes")
+	}
+
+	if cfg.Storage.RedisEnabled() {
+		redisCache, err := cache.NewRedisCacheFromConfig(cfg.Storage)
+		if err != nil {
+			fmt.Printf("Warning: Redis unavailable, cached queries will expire on their own: %v\n", err)
+			return nil
+		}
+		defer redisCache.Close()
+
+		if repoPath != "" {
+			if err := redisCache.DeletePattern(ctx, "stale:"+repoPath+"/*"); err != nil {
+				return fmt.Errorf("failed to delete stale markers: %w", err)
+			}
+		}
+		version, err := redisCache.InvalidateRepo(ctx, repo)
+		if err != nil {
+			return fmt.Errorf("failed to clear query cache: %w", err)
+		}
+		fmt.Printf("  Redis:  cleared cache (index version %d)\n", version)
+	}
+
+	return nil
+}
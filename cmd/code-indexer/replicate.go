@@ -0,0 +1,219 @@
+// cmd/code-indexer/replicate.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/cache"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/replication"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var replicateCmd = &cobra.Command{
+	Use:   "replicate",
+	Short: "Mirror owned repos to a standby deployment",
+	Long: `Copies chunks (with vectors) and graph data for the repos this deployment
+owns to a standby Qdrant/Neo4j pair. Progress is checkpointed per repo so an
+interrupted run resumes where it stopped, and each repo can only be replicated
+by one source, preventing two primaries from overwriting each other.
+
+Standby Neo4j credentials come from STANDBY_NEO4J_USER / STANDBY_NEO4J_PASSWORD.`,
+	RunE: runReplicate,
+}
+
+var (
+	replicateSource   string
+	replicateQdrant   string
+	replicateNeo4j    string
+	replicateRepos    string
+	replicateWatch    bool
+	replicateInterval string
+)
+
+func init() {
+	replicateCmd.Flags().StringVar(&replicateSource, "source", "", "Name of this primary (default: replication.source or hostname)")
+	replicateCmd.Flags().StringVar(&replicateQdrant, "to-qdrant", "", "Standby Qdrant URL (default: replication.qdrant_url)")
+	replicateCmd.Flags().StringVar(&replicateNeo4j, "to-neo4j", "", "Standby Neo4j URL (default: replication.neo4j_url)")
+	replicateCmd.Flags().StringVar(&replicateRepos, "repos", "", "Comma-separated repos to replicate (default: replication.repos)")
+	replicateCmd.Flags().BoolVar(&replicateWatch, "watch", false, "Keep running and replicate on an interval")
+	replicateCmd.Flags().StringVar(&replicateInterval, "interval", "", "Watch interval (default: replication.interval_seconds)")
+	rootCmd.AddCommand(replicateCmd)
+}
+
+func runReplicate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+	repCfg := cfg.Replication
+
+	source := firstNonEmpty(replicateSource, repCfg.Source)
+	if source == "" {
+		source, _ = os.Hostname()
+	}
+	standbyQdrant := firstNonEmpty(replicateQdrant, repCfg.QdrantURL)
+	if standbyQdrant == "" {
+		return fmt.Errorf("standby Qdrant URL required (--to-qdrant or replication.qdrant_url)")
+	}
+
+	repos := repCfg.Repos
+	if replicateRepos != "" {
+		repos = nil
+		for _, r := range strings.Split(replicateRepos, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				repos = append(repos, r)
+			}
+		}
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repos to replicate (--repos or replication.repos)")
+	}
+
+	primary, err := store.NewQdrantStore(cfg.Storage.QdrantURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to primary Qdrant: %w", err)
+	}
+	defer primary.Close()
+
+	standby, err := store.NewQdrantStore(standbyQdrant)
+	if err != nil {
+		return fmt.Errorf("failed to connect to standby Qdrant: %w", err)
+	}
+	defer standby.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := replication.Options{
+		Source:  source,
+		Primary: primary,
+		Standby: standby,
+		Logger: slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+		})),
+	}
+
+	// Graph replication is optional and needs both sides
+	if standbyNeo4j := firstNonEmpty(replicateNeo4j, repCfg.Neo4jURL); standbyNeo4j != "" {
+		primaryGraph, err := openGraphStore(cfg.Storage.Neo4jURL, "NEO4J_USER", "NEO4J_PASSWORD")
+		if err != nil {
+			fmt.Printf("Warning: primary Neo4j unavailable, graph will not be replicated: %v\n", err)
+		}
+		standbyGraph, err := openGraphStore(standbyNeo4j, "STANDBY_NEO4J_USER", "STANDBY_NEO4J_PASSWORD")
+		if err != nil {
+			fmt.Printf("Warning: standby Neo4j unavailable, graph will not be replicated: %v\n", err)
+		} else if schemaErr := standbyGraph.EnsureSchema(ctx); schemaErr != nil {
+			fmt.Printf("Warning: Failed to ensure standby Neo4j schema: %v\n", schemaErr)
+		}
+		if primaryGraph != nil {
+			defer primaryGraph.Close(ctx)
+		}
+		if standbyGraph != nil {
+			defer standbyGraph.Close(ctx)
+		}
+		opts.PrimaryGraph = primaryGraph
+		opts.StandbyGraph = standbyGraph
+	}
+
+	// Index versions let unchanged repos be skipped
+	if cfg.Storage.RedisEnabled() {
+		if versions, err := cache.NewRedisCacheFromConfig(cfg.Storage); err == nil {
+			defer versions.Close()
+			opts.Versions = versions
+		}
+	}
+
+	checkpointPath := repCfg.CheckpointPath
+	if checkpointPath == "" {
+		homeDir, _ := os.UserHomeDir()
+		checkpointPath = filepath.Join(homeDir, ".local", "share", "code-index", "replication.json")
+	}
+	checkpoints, err := replication.LoadCheckpoints(checkpointPath)
+	if err != nil {
+		return err
+	}
+	opts.Checkpoints = checkpoints
+
+	replicator := replication.NewReplicator(opts)
+
+	if !replicateWatch {
+		var failed int
+		for _, repo := range repos {
+			result, err := replicator.ReplicateRepo(ctx, repo)
+			if err != nil {
+				fmt.Printf("  %s: failed: %v\n", repo, err)
+				failed++
+				continue
+			}
+			if result.Skipped {
+				fmt.Printf("  %s: up to date\n", repo)
+				continue
+			}
+			fmt.Printf("  %s: %d points copied, %d stale points deleted, graph copied: %v\n",
+				repo, result.PointsCopied, result.PointsDeleted, result.GraphCopied)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d repo(s) failed to replicate", failed)
+		}
+		return nil
+	}
+
+	interval := time.Duration(repCfg.IntervalSeconds) * time.Second
+	if replicateInterval != "" {
+		interval, err = time.ParseDuration(replicateInterval)
+		if err != nil {
+			return fmt.Errorf("invalid interval: %w", err)
+		}
+	}
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		cancel()
+	}()
+
+	if err := replicator.Run(ctx, repos, interval); err != nil && err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+// openGraphStore connects to Neo4j using credentials from the given env vars.
+func openGraphStore(url, userEnv, passwordEnv string) (*graph.Neo4jStore, error) {
+	if url == "" {
+		return nil, fmt.Errorf("no Neo4j URL configured")
+	}
+	user := os.Getenv(userEnv)
+	if user == "" {
+		user = "neo4j"
+	}
+	password := os.Getenv(passwordEnv)
+	if password == "" {
+		return nil, fmt.Errorf("%s not set", passwordEnv)
+	}
+	return graph.NewNeo4jStore(url, user, password)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+// cmd/code-indexer/repos.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/search"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "List indexed repositories",
+	Long: `List every repository with chunks in the index, with its path and indexed
+commit when Neo4j is available. --json prints the same shape as the MCP
+list_repos tool ({"repos": [...]}, without modules) for other tools to consume.`,
+	Args: cobra.NoArgs,
+	RunE: runRepos,
+}
+
+var reposJSON bool
+
+func init() {
+	reposCmd.Flags().BoolVar(&reposJSON, "json", false, "Print JSON instead of a table")
+	rootCmd.AddCommand(reposCmd)
+}
+
+func runRepos(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	ctx := context.Background()
+	listings, err := listIndexedRepos(ctx, cfg, true)
+	if err != nil {
+		return err
+	}
+
+	if reposJSON {
+		return printJSON(map[string]interface{}{"repos": listings})
+	}
+
+	if len(listings) == 0 {
+		fmt.Println("No repositories indexed. Run 'code-indexer index <repo>' first.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tCHUNKS\tBRANCH\tCOMMIT\tINDEXED\tPATH")
+	for _, l := range listings {
+		indexedAt := ""
+		if !l.IndexedAt.IsZero() {
+			indexedAt = l.IndexedAt.Local().Format(time.DateTime)
+		}
+		commit := l.IndexedCommit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n", l.Name, l.Chunks, l.IndexedBranch, commit, indexedAt, l.Path)
+	}
+	return tw.Flush()
+}
+
+// listIndexedRepos merges Qdrant chunk counts with Neo4j repository nodes.
+// The graph is optional; withGraph=false skips it for speed.
+func listIndexedRepos(ctx context.Context, cfg *config.Config, withGraph bool) ([]search.RepoListing, error) {
+	qdrantStore, err := store.NewQdrantStore(cfg.Storage.QdrantURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Qdrant at %s: %w", cfg.Storage.QdrantURL, err)
+	}
+	defer qdrantStore.Close()
+
+	counts, err := qdrantStore.CountByField(ctx, "chunks", "repo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chunks: %w", err)
+	}
+
+	var repos []graph.Repository
+	if withGraph && os.Getenv("NEO4J_PASSWORD") != "" {
+		graphStore, err := openGraphStore(cfg.Storage.Neo4jURL, "NEO4J_USER", "NEO4J_PASSWORD")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Neo4j unavailable, paths and commits omitted: %v\n", err)
+		} else {
+			defer graphStore.Close(ctx)
+			repos, err = graphStore.ListRepositories(ctx)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to list repositories from Neo4j: %v\n", err)
+			}
+		}
+	}
+
+	return search.MergeRepos(repos, counts), nil
+}
@@ -0,0 +1,114 @@
+// cmd/code-indexer/serve_api.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/api"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/randalmurphal/code-indexer/internal/search"
+	"github.com/spf13/cobra"
+)
+
+const apiVersion = "0.1.0"
+
+var serveAPICmd = &cobra.Command{
+	Use:   "serve-api",
+	Short: "Serve the index over a REST/JSON HTTP API",
+	Long: `Serve search over plain HTTP for dashboards and bots that don't speak MCP:
+
+  GET /search?q=...          semantic search (search_code)
+  GET /symbols/{name}        symbol definitions (get_symbol)
+  GET /callers?symbol=...    callers from the graph (needs Neo4j)
+  GET /status                index health (index_status)
+  GET /openapi.json          OpenAPI 3.1 spec
+
+Requests need a bearer token from the same mcp.http.tokens or
+mcp.http.introspection config as the MCP HTTP transport, and tokens scoped to
+repos are held to them. Use --openapi to print the spec without serving.`,
+	Args: cobra.NoArgs,
+	RunE: runServeAPI,
+}
+
+var (
+	serveAPIAddr    string
+	serveAPIOpenAPI bool
+)
+
+func init() {
+	serveAPICmd.Flags().StringVar(&serveAPIAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	serveAPICmd.Flags().BoolVar(&serveAPIOpenAPI, "openapi", false, "Print the OpenAPI spec and exit")
+	rootCmd.AddCommand(serveAPICmd)
+}
+
+func runServeAPI(cmd *cobra.Command, args []string) error {
+	if serveAPIOpenAPI {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(api.Spec(apiVersion))
+	}
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	verifier, err := mcp.VerifierFromConfig(cfg.MCP.HTTP)
+	if errors.Is(err, mcp.ErrNoAuthConfigured) {
+		return fmt.Errorf("serve-api requires authentication: configure mcp.http.tokens or mcp.http.introspection in %s", getGlobalConfigPath())
+	}
+	if err != nil {
+		return fmt.Errorf("mcp.http: %w", err)
+	}
+
+	voyageKey := os.Getenv("VOYAGE_API_KEY")
+	if voyageKey == "" {
+		return fmt.Errorf("VOYAGE_API_KEY environment variable not set")
+	}
+
+	logger := slog.Default()
+	handler, err := search.NewHandler(cfg, voyageKey, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create handler: %w", err)
+	}
+	defer handler.Close()
+
+	httpServer := &http.Server{
+		Addr:              serveAPIAddr,
+		Handler:           mcp.RequireBearer(verifier, logger, api.NewServer(handler, apiVersion, logger)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("serving REST API", "addr", serveAPIAddr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http server error: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("http shutdown: %w", err)
+	}
+	logger.Info("server stopped")
+	return nil
+}
@@ -63,7 +63,10 @@ func runSuggestContext(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load config
-	cfg := config.DefaultConfig()
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return nil // Silent fail - don't break the hook
+	}
 
 	// Connect to Qdrant
 	qdrantStore, err := store.NewQdrantStore(cfg.Storage.QdrantURL)
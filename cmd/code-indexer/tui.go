@@ -0,0 +1,369 @@
+// cmd/code-indexer/tui.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/search"
+	"github.com/spf13/cobra"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui [query]",
+	Short: "Browse search results and the call graph in the terminal",
+	Long: `Interactive search for developers working outside Claude. Type a query and
+press enter; results are listed on the left with a highlighted preview of the
+selected one, and its callers and callees from Neo4j below the preview.
+
+Keys:
+  enter        search (in the search box)
+  tab          switch between the search box and the results
+  up/down j/k  select a result
+  pgup/pgdn    scroll the preview (left/right scrolls sideways)
+  /            back to the search box
+  q, ctrl+c    quit
+
+Searches the repo given by --repo, or the repo of the current directory.
+Requires VOYAGE_API_KEY; the callers pane also needs NEO4J_PASSWORD.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTUI,
+}
+
+var tuiRepo string
+
+func init() {
+	tuiCmd.Flags().StringVar(&tuiRepo, "repo", "", "Repository to search, or all (default: from .ai-devtools.yaml in cwd)")
+	tuiCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+	rootCmd.AddCommand(tuiCmd)
+}
+
+func runTUI(cmd *cobra.Command, args []string) error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return fmt.Errorf("tui needs an interactive terminal; use the MCP tools or serve-api instead")
+	}
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	voyageKey := os.Getenv("VOYAGE_API_KEY")
+	if voyageKey == "" {
+		return fmt.Errorf("VOYAGE_API_KEY environment variable not set")
+	}
+
+	// Anything logged would draw over the screen
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	handler, err := search.NewHandler(cfg, voyageKey, slog.Default())
+	if err != nil {
+		return fmt.Errorf("failed to create handler: %w", err)
+	}
+	defer handler.Close()
+
+	query := ""
+	if len(args) > 0 {
+		query = args[0]
+	}
+
+	program := tea.NewProgram(newTUIModel(context.Background(), handler, tuiRepo, query), tea.WithAltScreen())
+	if _, err := program.Run(); err != nil {
+		return fmt.Errorf("tui: %w", err)
+	}
+	return nil
+}
+
+type tuiFocus int
+
+const (
+	focusSearch tuiFocus = iota
+	focusResults
+)
+
+// searchDoneMsg carries a finished search_code call.
+type searchDoneMsg struct {
+	query   string
+	results []search.SearchResult
+	notice  string // The tool's plain-text answer when nothing was found
+	err     error
+}
+
+// callGraphMsg carries the callers and callees of a selected symbol.
+type callGraphMsg struct {
+	symbol  string
+	callers []search.SymbolRef
+	callees []search.SymbolRef
+	err     error
+}
+
+type tuiModel struct {
+	ctx     context.Context
+	handler *search.Handler
+	repo    string
+
+	input   textinput.Model
+	preview viewport.Model
+	graph   viewport.Model
+	focus   tuiFocus
+	width   int
+	height  int
+
+	query     string
+	results   []search.SearchResult
+	selected  int
+	offset    int // First result row shown
+	searching bool
+	status    string
+
+	graphSymbol string
+	callers     []search.SymbolRef
+	callees     []search.SymbolRef
+	graphErr    error
+	graphReady  bool
+}
+
+func newTUIModel(ctx context.Context, handler *search.Handler, repo, query string) tuiModel {
+	input := textinput.New()
+	input.Prompt = "Search: "
+	input.Placeholder = "what are you looking for?"
+	input.SetValue(query)
+	input.Focus()
+
+	m := tuiModel{
+		ctx:     ctx,
+		handler: handler,
+		repo:    repo,
+		input:   input,
+		preview: viewport.New(0, 0),
+		graph:   viewport.New(0, 0),
+		focus:   focusSearch,
+	}
+	if query != "" {
+		m.startSearch(query)
+	}
+	return m
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	if m.query == "" {
+		return textinput.Blink
+	}
+	return m.searchCmd(m.query)
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		m.refreshPanes()
+		return m, nil
+
+	case searchDoneMsg:
+		if msg.query != m.query {
+			return m, nil // Superseded by a newer search
+		}
+		m.searching = false
+		m.results, m.selected, m.offset = msg.results, 0, 0
+		switch {
+		case msg.err != nil:
+			m.status = "Search failed: " + msg.err.Error()
+		case len(msg.results) == 0:
+			m.status = msg.notice
+		default:
+			m.status = fmt.Sprintf("%d result(s) for %q", len(msg.results), msg.query)
+			m.focus = focusResults
+			m.input.Blur()
+		}
+		return m, m.selectionChanged()
+
+	case callGraphMsg:
+		if msg.symbol != m.graphSymbol {
+			return m, nil // Selection moved on
+		}
+		m.callers, m.callees, m.graphErr, m.graphReady = msg.callers, msg.callees, msg.err, true
+		m.refreshGraph()
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if msg.String() == "tab" {
+			m.toggleFocus()
+			return m, nil
+		}
+		if m.focus == focusSearch {
+			return m.updateSearch(msg)
+		}
+		return m.updateResults(msg)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m tuiModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if m.input.Value() == "" {
+			return m, nil
+		}
+		m.startSearch(m.input.Value())
+		return m, m.searchCmd(m.query)
+	case "esc":
+		if len(m.results) > 0 {
+			m.toggleFocus()
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m tuiModel) updateResults(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "/":
+		m.toggleFocus()
+		return m, nil
+	case "up", "k":
+		return m, m.moveSelection(-1)
+	case "down", "j":
+		return m, m.moveSelection(1)
+	case "home", "g":
+		return m, m.moveSelection(-len(m.results))
+	case "end", "G":
+		return m, m.moveSelection(len(m.results))
+	case "pgup", "ctrl+u":
+		m.preview.HalfPageUp()
+	case "pgdown", "ctrl+d":
+		m.preview.HalfPageDown()
+	case "left", "h":
+		m.preview.ScrollLeft(4)
+	case "right", "l":
+		m.preview.ScrollRight(4)
+	}
+	return m, nil
+}
+
+func (m *tuiModel) toggleFocus() {
+	if m.focus == focusSearch {
+		m.focus = focusResults
+		m.input.Blur()
+		return
+	}
+	m.focus = focusSearch
+	m.input.Focus()
+}
+
+func (m *tuiModel) moveSelection(delta int) tea.Cmd {
+	if len(m.results) == 0 {
+		return nil
+	}
+	selected := max(0, min(len(m.results)-1, m.selected+delta))
+	if selected == m.selected {
+		return nil
+	}
+	m.selected = selected
+
+	// Keep the selection within the visible rows
+	rows := m.resultRows()
+	if m.selected < m.offset {
+		m.offset = m.selected
+	}
+	if m.selected >= m.offset+rows {
+		m.offset = m.selected - rows + 1
+	}
+	return m.selectionChanged()
+}
+
+// selectionChanged redraws the preview and starts loading the call graph
+// for the newly selected result.
+func (m *tuiModel) selectionChanged() tea.Cmd {
+	m.refreshPanes()
+	m.callers, m.callees, m.graphErr, m.graphReady = nil, nil, nil, false
+	m.graphSymbol = ""
+	if len(m.results) == 0 {
+		m.refreshGraph()
+		return nil
+	}
+
+	m.graphSymbol = m.results[m.selected].SymbolName
+	m.refreshGraph()
+	if m.graphSymbol == "" {
+		return nil
+	}
+	return m.loadCallGraph(m.graphSymbol)
+}
+
+func (m *tuiModel) startSearch(query string) {
+	m.query = query
+	m.searching = true
+	m.status = "Searching..."
+}
+
+// searchCmd runs search_code for query. Results arrive as a searchDoneMsg.
+func (m tuiModel) searchCmd(query string) tea.Cmd {
+	ctx, handler := m.ctx, m.handler
+	args := map[string]interface{}{"query": query, "limit": float64(50)}
+	if m.repo != "" {
+		args["repo"] = m.repo
+	}
+	return func() tea.Msg {
+		msg := searchDoneMsg{query: query}
+		result, err := handler.CallTool(ctx, "search_code", args)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+
+		text := ""
+		if len(result.Content) > 0 {
+			text = result.Content[0].Text
+		}
+		var response search.PaginatedResponse
+		switch {
+		case result.IsError:
+			msg.err = errors.New(text)
+		case json.Unmarshal([]byte(text), &response) != nil:
+			msg.notice = text // "No results found ..." guidance
+		default:
+			msg.results = response.Results
+		}
+		return msg
+	}
+}
+
+func (m tuiModel) loadCallGraph(symbol string) tea.Cmd {
+	ctx, handler, repo := m.ctx, m.handler, m.repo
+	return func() tea.Msg {
+		msg := callGraphMsg{symbol: symbol}
+		callers, err := handler.Callers(ctx, repo, symbol)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		callees, err := handler.Callees(ctx, repo, symbol)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		msg.callers, msg.callees = callers.Callers, callees.Callees
+		return msg
+	}
+}
@@ -0,0 +1,212 @@
+// cmd/code-indexer/tui_view.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/randalmurphal/code-indexer/internal/parser"
+	"github.com/randalmurphal/code-indexer/internal/search"
+)
+
+var (
+	paneStyle       = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240"))
+	activePaneStyle = paneStyle.BorderForeground(lipgloss.Color("63"))
+	titleStyle      = lipgloss.NewStyle().Bold(true)
+	dimStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	selectedStyle   = lipgloss.NewStyle().Reverse(true)
+	gutterStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	tokenStyles = map[parser.TokenClass]lipgloss.Style{
+		parser.TokenKeyword:  lipgloss.NewStyle().Foreground(lipgloss.Color("205")),
+		parser.TokenString:   lipgloss.NewStyle().Foreground(lipgloss.Color("114")),
+		parser.TokenComment:  lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Italic(true),
+		parser.TokenNumber:   lipgloss.NewStyle().Foreground(lipgloss.Color("215")),
+		parser.TokenConstant: lipgloss.NewStyle().Foreground(lipgloss.Color("215")),
+		parser.TokenFunction: lipgloss.NewStyle().Foreground(lipgloss.Color("75")),
+		parser.TokenType:     lipgloss.NewStyle().Foreground(lipgloss.Color("221")),
+	}
+)
+
+// Panes: results on the left, preview over the call graph on the right,
+// between a one-line search box and a one-line status bar.
+func (m tuiModel) paneSizes() (listW, rightW, bodyH, previewH, graphH int) {
+	bodyH = max(6, m.height-2)
+	listW = max(24, m.width*2/5)
+	rightW = max(24, m.width-listW)
+	previewH = bodyH * 3 / 5
+	graphH = bodyH - previewH
+	return
+}
+
+// layout sizes the viewports to fit inside their bordered panes, below a
+// title line.
+func (m *tuiModel) layout() {
+	m.input.Width = max(10, m.width-len(m.input.Prompt)-1)
+
+	_, rightW, _, previewH, graphH := m.paneSizes()
+	m.preview.Width, m.preview.Height = rightW-2, max(1, previewH-3)
+	m.graph.Width, m.graph.Height = rightW-2, max(1, graphH-3)
+}
+
+func (m *tuiModel) refreshPanes() {
+	if len(m.results) == 0 {
+		m.preview.SetContent(dimStyle.Render("Type a query and press enter. tab switches panes, q quits."))
+		return
+	}
+	r := m.results[m.selected]
+	m.preview.SetContent(highlightCode(r.Content, r.FilePath, r.StartLine))
+	m.preview.GotoTop()
+	m.preview.SetXOffset(0)
+}
+
+func (m *tuiModel) refreshGraph() {
+	var b strings.Builder
+	switch {
+	case len(m.results) == 0:
+	case m.graphSymbol == "":
+		b.WriteString(dimStyle.Render("The selected result is not a named symbol"))
+	case !m.graphReady:
+		b.WriteString(dimStyle.Render("Loading callers of " + m.graphSymbol + "..."))
+	case errors.Is(m.graphErr, search.ErrGraphUnavailable):
+		b.WriteString(dimStyle.Render("Neo4j is not connected; set NEO4J_PASSWORD to see callers and callees"))
+	case errors.Is(m.graphErr, search.ErrRepoRequired):
+		b.WriteString(dimStyle.Render("Pass --repo (or run from a repo) to see callers and callees"))
+	case m.graphErr != nil:
+		b.WriteString("Call graph failed: " + m.graphErr.Error())
+	default:
+		writeRefs(&b, "Callers", m.callers)
+		b.WriteString("\n")
+		writeRefs(&b, "Callees", m.callees)
+	}
+	m.graph.SetContent(b.String())
+	m.graph.GotoTop()
+}
+
+func writeRefs(b *strings.Builder, label string, refs []search.SymbolRef) {
+	b.WriteString(titleStyle.Render(fmt.Sprintf("%s (%d)", label, len(refs))))
+	b.WriteString("\n")
+	if len(refs) == 0 {
+		b.WriteString(dimStyle.Render("  none"))
+		b.WriteString("\n")
+	}
+	for _, r := range refs {
+		fmt.Fprintf(b, "  %s %s\n", r.Name, dimStyle.Render(fmt.Sprintf("%s:%d", r.FilePath, r.StartLine)))
+	}
+}
+
+func (m tuiModel) View() string {
+	if m.width == 0 {
+		return "" // No size yet
+	}
+	listW, rightW, bodyH, previewH, graphH := m.paneSizes()
+
+	listPane, rightPane := paneStyle, paneStyle
+	if m.focus == focusResults {
+		listPane = activePaneStyle
+	}
+
+	list := listPane.Width(listW - 2).Height(bodyH - 2).Render(m.resultsView(listW - 2))
+
+	previewTitle := "Preview"
+	if len(m.results) > 0 {
+		r := m.results[m.selected]
+		previewTitle = fmt.Sprintf("%s:%d-%d", r.FilePath, r.StartLine, r.EndLine)
+	}
+	preview := rightPane.Width(rightW - 2).Height(previewH - 2).Render(
+		titleStyle.Render(ansi.Truncate(previewTitle, rightW-2, "…")) + "\n" + m.preview.View())
+	graph := rightPane.Width(rightW - 2).Height(graphH - 2).Render(
+		titleStyle.Render("Call graph") + "\n" + m.graph.View())
+
+	status := m.status
+	if m.repo != "" {
+		status = "[" + m.repo + "] " + status
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.input.View(),
+		lipgloss.JoinHorizontal(lipgloss.Top, list, lipgloss.JoinVertical(lipgloss.Left, preview, graph)),
+		dimStyle.Render(ansi.Truncate(status, m.width, "…")),
+	)
+}
+
+// resultRows is how many results fit in the list pane under its title.
+func (m tuiModel) resultRows() int {
+	_, _, bodyH, _, _ := m.paneSizes()
+	return max(1, bodyH-3)
+}
+
+// resultsView renders the visible slice of the result list.
+func (m tuiModel) resultsView(width int) string {
+	header := titleStyle.Render("Results")
+	if m.searching {
+		header += dimStyle.Render(" (searching)")
+	}
+	if len(m.results) == 0 {
+		return header
+	}
+
+	lines := []string{header}
+	for i := m.offset; i < len(m.results) && i < m.offset+m.resultRows(); i++ {
+		r := m.results[i]
+		name := r.SymbolName
+		if name == "" {
+			name = r.Kind
+		}
+		location := fmt.Sprintf("%s:%d", r.FilePath, r.StartLine)
+		row := ansi.Truncate(name+"  "+location, width, "…")
+		if i == m.selected {
+			row = selectedStyle.Render(row + strings.Repeat(" ", max(0, width-ansi.StringWidth(row))))
+		}
+		lines = append(lines, row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightCode renders a result's content with tree-sitter highlighting and
+// a line number gutter. Languages the parser doesn't know are shown plain.
+func highlightCode(content, filePath string, startLine int) string {
+	var tokens []parser.Token
+	if lang, ok := parser.DetectLanguage(filePath); ok {
+		if p, err := parser.NewParser(lang); err == nil {
+			tokens, _ = p.Highlight([]byte(content))
+		}
+	}
+
+	var b strings.Builder
+	emit := func(text string, style *lipgloss.Style) {
+		text = strings.ReplaceAll(text, "\t", "    ")
+		// Style each line separately so escapes never span the gutter
+		for i, line := range strings.Split(text, "\n") {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			if style != nil && line != "" {
+				line = style.Render(line)
+			}
+			b.WriteString(line)
+		}
+	}
+
+	pos := 0
+	for _, tok := range tokens {
+		if tok.Start < pos || tok.End > len(content) {
+			continue
+		}
+		emit(content[pos:tok.Start], nil)
+		style := tokenStyles[tok.Class]
+		emit(content[tok.Start:tok.End], &style)
+		pos = tok.End
+	}
+	emit(content[pos:], nil)
+
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	width := len(fmt.Sprint(startLine + len(lines)))
+	for i, line := range lines {
+		lines[i] = gutterStyle.Render(fmt.Sprintf("%*d ", width, startLine+i)) + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,186 @@
+// cmd/code-indexer/verify.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/embedding"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [repo-name-or-path]",
+	Short: "Check the index against the repository's working tree",
+	Long: `Compare what is indexed for a repository with the files on disk and list:
+
+  missing   files on disk that have no chunks
+  stale     chunks or graph nodes for files that no longer exist
+  changed   files whose content differs from the hash stored in Neo4j
+            (requires NEO4J_PASSWORD; skipped otherwise)
+
+and whether the chunks collection's vector size matches the embedding model.
+Defaults to the repository in the current directory.
+
+With --fix, every listed file is re-indexed on its own (deleted files have
+their chunks and graph nodes removed); this needs VOYAGE_API_KEY. A dimension
+mismatch can't be fixed per file: the collection is shared by all repos and
+must be recreated, then every repo re-indexed.
+
+Exits non-zero if the index is inconsistent and was not repaired.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRepoArgs,
+	RunE:              runVerify,
+}
+
+var (
+	verifyFix  bool
+	verifyJSON bool
+)
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyFix, "fix", false, "Re-index missing, stale, and changed files")
+	verifyCmd.Flags().BoolVar(&verifyJSON, "json", false, "Print the report as JSON")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// verifyOutput is the --json document.
+type verifyOutput struct {
+	*indexer.VerifyReport
+	Repaired int      `json:"repaired"`
+	Errors   []string `json:"errors"`
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	repoArg := "."
+	if len(args) > 0 {
+		repoArg = args[0]
+	}
+	absPath, err := resolveRepoPath(repoArg)
+	if err != nil {
+		return err
+	}
+	repoCfg, err := config.LoadRepoConfig(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to load repo config: %w\nRun 'code-indexer init %s' first", err, absPath)
+	}
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		return fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	voyageKey := os.Getenv("VOYAGE_API_KEY")
+	if verifyFix && voyageKey == "" {
+		return fmt.Errorf("VOYAGE_API_KEY environment variable not set (needed by --fix)")
+	}
+
+	qdrantStore, err := store.NewQdrantStore(cfg.Storage.QdrantURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Qdrant: %w", err)
+	}
+	defer qdrantStore.Close()
+
+	ctx := context.Background()
+
+	var graphStore *graph.Neo4jStore
+	if os.Getenv("NEO4J_PASSWORD") != "" && cfg.Storage.Neo4jURL != "" {
+		graphStore, err = openGraphStore(cfg.Storage.Neo4jURL, "NEO4J_USER", "NEO4J_PASSWORD")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Neo4j unavailable, skipping hash comparison: %v\n", err)
+			graphStore = nil
+		} else {
+			defer graphStore.Close(ctx)
+		}
+	}
+
+	// Status lines go to stderr when stdout carries the JSON report
+	var out io.Writer = os.Stdout
+	if verifyJSON {
+		out = os.Stderr
+	}
+
+	idx := indexer.NewIndexerWithClients(cfg, embedding.NewVoyageClient(voyageKey, cfg.Embedding.Model), qdrantStore)
+	report, err := idx.Verify(ctx, absPath, repoCfg, graphStore)
+	if err != nil {
+		return fmt.Errorf("verify failed: %w", err)
+	}
+
+	output := verifyOutput{VerifyReport: report, Errors: []string{}}
+	if !verifyJSON {
+		printVerifyReport(out, report)
+	}
+
+	if verifyFix && !report.Consistent() {
+		fmt.Fprintf(out, "\nRepairing %s...\n", report.Repo)
+		repaired, errs := idx.Repair(ctx, absPath, repoCfg, report, graphStore)
+		output.Repaired = repaired
+		for _, e := range errs {
+			output.Errors = append(output.Errors, e.Error())
+			fmt.Fprintf(os.Stderr, "  Error: %v\n", e)
+		}
+		fmt.Fprintf(out, "Re-indexed %d file(s)\n", repaired)
+
+		if verifyJSON {
+			if err := printJSON(output); err != nil {
+				return err
+			}
+		}
+		for _, e := range errs {
+			if errors.Is(e, indexer.ErrDimensionMismatch) {
+				return fmt.Errorf("%w\nDelete the chunks collection and run 'code-indexer index --all' to rebuild it", e)
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%d file(s) could not be repaired", len(errs))
+		}
+		return nil
+	}
+
+	if verifyJSON {
+		if err := printJSON(output); err != nil {
+			return err
+		}
+	}
+	if !report.Consistent() {
+		return fmt.Errorf("index for %s is inconsistent; run 'code-indexer verify --fix' to repair", report.Repo)
+	}
+	return nil
+}
+
+func printVerifyReport(out io.Writer, report *indexer.VerifyReport) {
+	fmt.Fprintf(out, "Verifying %s: %d file(s) on disk, %d indexed\n", report.Repo, report.FilesOnDisk, report.FilesIndexed)
+
+	printPaths := func(label string, paths []string) {
+		if len(paths) == 0 {
+			return
+		}
+		fmt.Fprintf(out, "\n%s (%d):\n", label, len(paths))
+		for _, p := range paths {
+			fmt.Fprintf(out, "  %s\n", p)
+		}
+	}
+	printPaths("Missing from index", report.Missing)
+	printPaths("Stale (deleted from disk)", report.Stale)
+	printPaths("Changed since indexing", report.Changed)
+
+	if !report.HashesChecked {
+		fmt.Fprintln(out, "\nNote: Neo4j not available, changed files were not checked")
+	}
+	if report.DimensionMismatch() {
+		fmt.Fprintf(out, "\nDimension mismatch: collection vectors are %d, the embedding model produces %d\n",
+			report.CollectionDim, report.EmbeddingDim)
+	}
+	if report.Consistent() {
+		fmt.Fprintln(out, "\nIndex is consistent with the working tree")
+	}
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -13,6 +16,7 @@ import (
 
 	"github.com/randalmurphal/code-indexer/internal/config"
 	"github.com/randalmurphal/code-indexer/internal/indexer"
+	"github.com/randalmurphal/code-indexer/internal/search"
 	"github.com/randalmurphal/code-indexer/internal/sync"
 	"github.com/spf13/cobra"
 )
@@ -27,11 +31,21 @@ var watchCmd = &cobra.Command{
 var (
 	watchRepos    string
 	watchInterval string
+	watchFiles    bool
+	watchDirty    bool
+	watchDebounce time.Duration
+	watchAdmin    string
+	watchWorkers  int
 )
 
 func init() {
 	watchCmd.Flags().StringVar(&watchRepos, "repos", "", "Comma-separated repo names to watch (e.g., r3,m32rimm)")
-	watchCmd.Flags().StringVar(&watchInterval, "interval", "60s", "Check interval (e.g., 30s, 5m)")
+	watchCmd.Flags().StringVar(&watchInterval, "interval", "60s", "Check interval of repos without a sync.interval or sync.schedule in their config (e.g., 30s, 5m)")
+	watchCmd.Flags().BoolVar(&watchFiles, "files", false, "Also reindex files as they are saved, including uncommitted edits")
+	watchCmd.Flags().BoolVar(&watchDirty, "dirty", false, "Also index uncommitted changes (git status) at every check, tagged dirty for search")
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", 500*time.Millisecond, "With --files, wait for this long without changes before reindexing")
+	watchCmd.Flags().IntVar(&watchWorkers, "concurrency", 2, "Repos synced at once")
+	watchCmd.Flags().StringVar(&watchAdmin, "admin", "", "Serve /healthz, /status, and /sync-now/<repo> on this address (e.g., 127.0.0.1:9100)")
 	rootCmd.AddCommand(watchCmd)
 }
 
@@ -44,6 +58,12 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid interval: %w", err)
 	}
+	if watchWorkers < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if watchFiles && watchDebounce <= 0 {
+		return fmt.Errorf("--debounce must be positive")
+	}
 
 	// Setup logging
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
@@ -51,9 +71,7 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	}))
 
 	// Load global config
-	homeDir, _ := os.UserHomeDir()
-	globalConfigPath := filepath.Join(homeDir, ".config", "code-index", "config.yaml")
-	cfg, err := config.LoadConfig(globalConfigPath)
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
 	if err != nil {
 		cfg = config.DefaultConfig()
 	}
@@ -69,12 +87,105 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create indexer: %w", err)
 	}
+	if redisCache := connectIndexCache(cfg); redisCache != nil {
+		defer redisCache.Close()
+		idx.SetCache(redisCache)
+	}
+
+	repos, err := loadRepoWatches(watchRepos, logger)
+	if err != nil {
+		return err
+	}
+
+	// Create and run daemon
+	daemon := sync.NewDaemon(repos, interval, idx, logger)
+	daemon.SetHistoryPath(indexHistoryPath())
+	daemon.SetStatePath(syncStatePath())
+	daemon.SetAlerts(cfg.Sync.Alerts)
+	if usage, err := openMetricsLog(); err != nil {
+		logger.Warn("freshness and spend metrics disabled", "error", err)
+	} else {
+		defer usage.Close()
+		daemon.SetMetrics(usage)
+		idx.SetMetrics(usage)
+	}
+	daemon.SetPausePath(syncPausePath())
+	daemon.SetConcurrency(watchWorkers)
+	daemon.SetDirtyTracking(watchDirty)
+	if watchFiles {
+		daemon.SetFileWatch(watchDebounce)
+	}
+	if cfg.Cache.Warm.Queries > 0 && cfg.Storage.RedisEnabled() {
+		handler, err := search.NewHandler(cfg, voyageKey, logger)
+		if err != nil {
+			logger.Warn("cache warming disabled", "error", err)
+		} else {
+			defer handler.Close()
+			daemon.SetWarmer(handler)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle shutdown
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		logger.Info("received shutdown signal")
+		cancel()
+	}()
+
+	if watchAdmin != "" {
+		if err := serveAdmin(ctx, watchAdmin, daemon, logger); err != nil {
+			return err
+		}
+	}
+
+	return daemon.Run(ctx)
+}
+
+// serveAdmin serves the daemon's admin endpoints on addr until ctx is done.
+// It fails if addr cannot be listened on.
+func serveAdmin(ctx context.Context, addr string, daemon *sync.Daemon, logger *slog.Logger) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("admin listener: %w", err)
+	}
+	server := &http.Server{
+		Handler:           daemon.AdminHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Info("serving admin endpoints", "addr", listener.Addr().String())
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("admin server failed", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	return nil
+}
+
+// syncStatePath is the state file shared by watch and webhook daemons
+// (sync.Daemon.SetStatePath).
+func syncStatePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".local", "share", "code-index", "daemon-state.json")
+}
 
-	// Build repo list
-	repoNames := strings.Split(watchRepos, ",")
+// loadRepoWatches resolves comma-separated repo names to ~/repos/<name>,
+// skipping missing ones, with default patterns for repos without a config.
+func loadRepoWatches(names string, logger *slog.Logger) ([]sync.RepoWatch, error) {
+	homeDir, _ := os.UserHomeDir()
 	var repos []sync.RepoWatch
 
-	for _, name := range repoNames {
+	for _, name := range strings.Split(names, ",") {
 		name = strings.TrimSpace(name)
 		repoPath := filepath.Join(homeDir, "repos", name)
 
@@ -103,23 +214,7 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(repos) == 0 {
-		return fmt.Errorf("no valid repos found")
+		return nil, fmt.Errorf("no valid repos found")
 	}
-
-	// Create and run daemon
-	daemon := sync.NewDaemon(repos, interval, idx, logger)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Handle shutdown
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		logger.Info("received shutdown signal")
-		cancel()
-	}()
-
-	return daemon.Run(ctx)
+	return repos, nil
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+	"github.com/randalmurphal/code-indexer/internal/search"
+	"github.com/randalmurphal/code-indexer/internal/sync"
+	"github.com/spf13/cobra"
+)
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Sync repositories when GitHub or GitLab pushes to them",
+	Long: `Receive push webhooks instead of polling, for shared servers:
+
+  code-indexer webhook --listen :9000 --repos r3,m32rimm
+
+Point a GitHub or GitLab push webhook at http://<host>:9000/ with the secret
+from $CODE_INDEX_WEBHOOK_SECRET (--secret-env). GitHub deliveries are checked
+against their HMAC-SHA256 signature, GitLab ones against their token.
+
+A push to the branch checked out in ~/repos/<name> pulls it (fast-forward
+only) and reindexes it: incrementally when Neo4j is configured and
+NEO4J_PASSWORD is set, else in full. Pushes are matched to repos by remote
+URL, else by name. Every repo is synced once at startup.`,
+	Args: cobra.NoArgs,
+	RunE: runWebhook,
+}
+
+var (
+	webhookListen    string
+	webhookRepos     string
+	webhookSecretEnv string
+	webhookAdmin     string
+	webhookWorkers   int
+)
+
+func init() {
+	webhookCmd.Flags().StringVar(&webhookListen, "listen", ":9000", "Address to receive webhooks on")
+	webhookCmd.Flags().StringVar(&webhookRepos, "repos", "", "Comma-separated repo names to sync (e.g., r3,m32rimm)")
+	webhookCmd.Flags().StringVar(&webhookSecretEnv, "secret-env", "CODE_INDEX_WEBHOOK_SECRET", "Env var holding the webhook secret")
+	webhookCmd.Flags().IntVar(&webhookWorkers, "concurrency", 2, "Repos synced at once")
+	webhookCmd.Flags().StringVar(&webhookAdmin, "admin", "", "Serve /healthz, /status, and /sync-now/<repo> on this address (e.g., 127.0.0.1:9100)")
+	rootCmd.AddCommand(webhookCmd)
+}
+
+func runWebhook(cmd *cobra.Command, args []string) error {
+	if webhookRepos == "" {
+		return fmt.Errorf("--repos is required")
+	}
+	if webhookWorkers < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	secret := os.Getenv(webhookSecretEnv)
+	if secret == "" {
+		return fmt.Errorf("%s not set: webhooks are only accepted with a secret", webhookSecretEnv)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	cfg, err := config.LoadConfig(getGlobalConfigPath())
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	voyageKey := os.Getenv("VOYAGE_API_KEY")
+	if voyageKey == "" {
+		return fmt.Errorf("VOYAGE_API_KEY not set")
+	}
+
+	idx, err := indexer.NewIndexer(cfg, voyageKey)
+	if err != nil {
+		return fmt.Errorf("failed to create indexer: %w", err)
+	}
+	if redisCache := connectIndexCache(cfg); redisCache != nil {
+		defer redisCache.Close()
+		idx.SetCache(redisCache)
+	}
+
+	repos, err := loadRepoWatches(webhookRepos, logger)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Interval checks are off; pushes trigger the syncs
+	daemon := sync.NewDaemon(repos, 0, idx, logger)
+	daemon.SetHistoryPath(indexHistoryPath())
+	daemon.SetPausePath(syncPausePath())
+	daemon.SetStatePath(syncStatePath())
+	daemon.SetAlerts(cfg.Sync.Alerts)
+	if usage, err := openMetricsLog(); err != nil {
+		logger.Warn("freshness and spend metrics disabled", "error", err)
+	} else {
+		defer usage.Close()
+		daemon.SetMetrics(usage)
+		idx.SetMetrics(usage)
+	}
+	daemon.SetConcurrency(webhookWorkers)
+	if cfg.Storage.Neo4jURL != "" {
+		graphStore, err := openGraphStore(cfg.Storage.Neo4jURL, "NEO4J_USER", "NEO4J_PASSWORD")
+		if err != nil {
+			logger.Warn("Neo4j unavailable, syncing without the graph", "error", err)
+		} else {
+			defer graphStore.Close(context.Background())
+			if err := graphStore.EnsureSchema(ctx); err != nil {
+				logger.Warn("failed to ensure Neo4j schema", "error", err)
+			}
+			daemon.SetGraphStore(graphStore)
+		}
+	}
+	if cfg.Cache.Warm.Queries > 0 && cfg.Storage.RedisEnabled() {
+		handler, err := search.NewHandler(cfg, voyageKey, logger)
+		if err != nil {
+			logger.Warn("cache warming disabled", "error", err)
+		} else {
+			defer handler.Close()
+			daemon.SetWarmer(handler)
+		}
+	}
+
+	webhook, err := sync.NewWebhook(ctx, daemon, secret)
+	if err != nil {
+		return err
+	}
+	if webhookAdmin != "" {
+		if err := serveAdmin(ctx, webhookAdmin, daemon, logger); err != nil {
+			return err
+		}
+	}
+	httpServer := &http.Server{
+		Addr:              webhookListen,
+		Handler:           webhook,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		logger.Info("receiving webhooks", "addr", webhookListen)
+		errCh <- httpServer.ListenAndServe()
+	}()
+	go func() {
+		errCh <- webhook.Run(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		if ctx.Err() == nil {
+			return fmt.Errorf("webhook server error: %w", err)
+		}
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("http shutdown: %w", err)
+	}
+	logger.Info("webhook server stopped")
+	return nil
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// route is one API endpoint. The same table registers handlers and
+// generates the OpenAPI spec, so the two cannot drift apart.
+type route struct {
+	method      string
+	path        string
+	tool        string // MCP tool backing the route, if any
+	operationID string
+	summary     string
+	params      []param
+	handle      func(s *Server, w http.ResponseWriter, r *http.Request, rt route)
+}
+
+type param struct {
+	name        string
+	arg         string // Tool argument name when it differs from name
+	in          string // query | path
+	typ         string // string | integer
+	enum        []string
+	required    bool
+	description string
+}
+
+var repoParam = param{name: "repo", in: "query", typ: "string", description: "Repository name, or all (default: inferred from the server's working directory)"}
+
+var routes = []route{
+	{
+		method:      http.MethodGet,
+		path:        "/search",
+		tool:        "search_code",
+		operationID: "searchCode",
+		summary:     "Semantic code search",
+		params: []param{
+			{name: "q", arg: "query", in: "query", typ: "string", required: true, description: "What you're looking for, in natural language; -word leaves out code containing word"},
+			repoParam,
+			{name: "module", in: "query", typ: "string", description: "Only search this module, e.g. fisio.imports"},
+			{name: "owner", in: "query", typ: "string", description: "Only code last modified by this author (name or email), or owned by a CODEOWNERS user or team (@user, @org/team)"},
+			{name: "path_glob", in: "query", typ: "string", description: "Only code whose path matches this glob, e.g. api/**/*.py"},
+			{name: "language", in: "query", typ: "string", enum: []string{"python", "javascript", "typescript"}, description: "Only code in this language"},
+			{name: "kind", in: "query", typ: "string", enum: []string{"function", "class", "method", "doc", "pattern", "file", "adr"}, description: "Only this kind of chunk"},
+			{name: "exclude_modules", in: "query", typ: "string", description: "Comma-separated modules to leave out, with their submodules"},
+			{name: "exclude_paths", in: "query", typ: "string", description: "Comma-separated paths or globs to leave out, e.g. generated/,migrations/"},
+			{name: "group_by", in: "query", typ: "string", description: "file: one result per file with its best snippet"},
+			{name: "include_tests", in: "query", typ: "string", enum: []string{"include", "exclude", "only"}, description: "Test file handling (default include)"},
+			{name: "limit", in: "query", typ: "integer", description: "Maximum results (default 10)"},
+			{name: "cursor", in: "query", typ: "string", description: "Pagination cursor from a previous response"},
+		},
+		handle: (*Server).handleTool,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/symbols/{name}",
+		tool:        "get_symbol",
+		operationID: "getSymbol",
+		summary:     "Look up a symbol's definitions by name",
+		params: []param{
+			{name: "name", in: "path", typ: "string", required: true, description: "Symbol name, or Class.method"},
+			repoParam,
+			{name: "kind", in: "query", typ: "string", description: "Only definitions of this kind (function, class, method)"},
+			{name: "limit", in: "query", typ: "integer", description: "Maximum definitions (default 5)"},
+		},
+		handle: (*Server).handleTool,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/callers",
+		operationID: "findCallers",
+		summary:     "List the symbols that call a symbol (requires Neo4j)",
+		params: []param{
+			{name: "symbol", in: "query", typ: "string", required: true, description: "Name of the called symbol"},
+			repoParam,
+		},
+		handle: (*Server).handleCallers,
+	},
+	{
+		method:      http.MethodGet,
+		path:        "/status",
+		tool:        "index_status",
+		operationID: "indexStatus",
+		summary:     "Index health, and a repo's indexed commit and chunk count",
+		params:      []param{repoParam},
+		handle:      (*Server).handleTool,
+	},
+}
+
+// Spec returns the OpenAPI 3.1 document describing the API.
+func Spec(version string) map[string]interface{} {
+	errorRef := map[string]interface{}{
+		"description": "Error",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+			},
+		},
+	}
+
+	paths := make(map[string]interface{})
+	for _, rt := range routes {
+		var params []interface{}
+		for _, p := range rt.params {
+			schema := map[string]interface{}{"type": p.typ}
+			if len(p.enum) > 0 {
+				schema["enum"] = p.enum
+			}
+			params = append(params, map[string]interface{}{
+				"name":        p.name,
+				"in":          p.in,
+				"required":    p.required,
+				"description": p.description,
+				"schema":      schema,
+			})
+		}
+
+		description := rt.summary
+		if rt.tool != "" {
+			description = "Returns the result of the " + rt.tool + " MCP tool."
+		}
+		op := map[string]interface{}{
+			"operationId": rt.operationID,
+			"summary":     rt.summary,
+			"description": description,
+			"parameters":  params,
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "OK",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": map[string]interface{}{"type": "object"},
+						},
+					},
+				},
+				"400": errorRef,
+				"401": errorRef,
+				"403": errorRef,
+				"404": errorRef,
+			},
+		}
+
+		item, _ := paths[rt.path].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[rt.path] = item
+		}
+		item[strings.ToLower(rt.method)] = op
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   "code-indexer API",
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"error": map[string]interface{}{"type": "string"}},
+					"required":   []string{"error"},
+				},
+			},
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"bearer": []string{}}},
+	}
+}
@@ -0,0 +1,172 @@
+// Package api serves the search handler as a REST/JSON HTTP API for
+// dashboards and bots that don't speak MCP.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/randalmurphal/code-indexer/internal/search"
+)
+
+// Backend is what the API serves from. *search.Handler implements it.
+type Backend interface {
+	CallTool(ctx context.Context, name string, args map[string]interface{}) (*mcp.CallToolResult, error)
+	Callers(ctx context.Context, repo, symbol string) (*search.CallersResponse, error)
+}
+
+// Server routes REST requests to the backend's tools. Responses are the
+// tool's JSON unchanged, so they match what MCP clients see.
+type Server struct {
+	backend Backend
+	logger  *slog.Logger
+	version string
+	mux     *http.ServeMux
+}
+
+// NewServer creates an API server. version is reported in the OpenAPI spec.
+func NewServer(backend Backend, version string, logger *slog.Logger) *Server {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	s := &Server{
+		backend: backend,
+		logger:  logger,
+		version: version,
+		mux:     http.NewServeMux(),
+	}
+	for _, rt := range routes {
+		s.mux.HandleFunc(rt.method+" "+rt.path, func(w http.ResponseWriter, r *http.Request) {
+			rt.handle(s, w, r, rt)
+		})
+	}
+	s.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Spec(s.version))
+	})
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleTool serves a route backed directly by an MCP tool.
+func (s *Server) handleTool(w http.ResponseWriter, r *http.Request, rt route) {
+	args, ok := s.toolArgs(w, r, rt)
+	if !ok {
+		return
+	}
+	s.callTool(w, r, rt.tool, args)
+}
+
+func (s *Server) handleCallers(w http.ResponseWriter, r *http.Request, _ route) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, http.StatusBadRequest, "symbol parameter is required")
+		return
+	}
+
+	resp, err := s.backend.Callers(r.Context(), r.URL.Query().Get("repo"), symbol)
+	switch {
+	case errors.Is(err, search.ErrRepoRequired):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, search.ErrRepoNotPermitted):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, search.ErrGraphUnavailable):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	case err != nil:
+		s.logger.Error("callers failed", "symbol", symbol, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	default:
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// toolArgs converts a route's documented parameters into tool arguments,
+// typed the way JSON-RPC would deliver them.
+func (s *Server) toolArgs(w http.ResponseWriter, r *http.Request, rt route) (map[string]interface{}, bool) {
+	query := r.URL.Query()
+	args := make(map[string]interface{})
+
+	for _, p := range rt.params {
+		value := query.Get(p.name)
+		if p.in == "path" {
+			value = r.PathValue(p.name)
+		}
+		if value == "" {
+			if p.required {
+				writeError(w, http.StatusBadRequest, p.name+" parameter is required")
+				return nil, false
+			}
+			continue
+		}
+		key := p.name
+		if p.arg != "" {
+			key = p.arg
+		}
+		if p.typ == "integer" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, p.name+" must be an integer")
+				return nil, false
+			}
+			args[key] = float64(n)
+			continue
+		}
+		args[key] = value
+	}
+
+	// The handler would refuse too, but as a tool error; answer 403 directly
+	if repo, ok := args["repo"].(string); ok && !mcp.RepoAllowed(r.Context(), repo) {
+		writeError(w, http.StatusForbidden, search.ErrRepoNotPermitted.Error())
+		return nil, false
+	}
+	return args, true
+}
+
+// callTool runs a tool and maps its result onto HTTP: tool errors are 400,
+// plain-text (non-JSON) answers are the tools' "nothing found" messages.
+func (s *Server) callTool(w http.ResponseWriter, r *http.Request, name string, args map[string]interface{}) {
+	result, err := s.backend.CallTool(r.Context(), name, args)
+	if err != nil {
+		s.logger.Error("tool call failed", "tool", name, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	text := ""
+	if len(result.Content) > 0 {
+		text = result.Content[0].Text
+	}
+	switch {
+	case result.IsError:
+		writeError(w, http.StatusBadRequest, text)
+	case !json.Valid([]byte(text)):
+		writeError(w, http.StatusNotFound, text)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(text))
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/randalmurphal/code-indexer/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	tool    string
+	args    map[string]interface{}
+	result  *mcp.CallToolResult
+	callers *search.CallersResponse
+	err     error
+}
+
+func (f *fakeBackend) CallTool(ctx context.Context, name string, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	f.tool, f.args = name, args
+	return f.result, f.err
+}
+
+func (f *fakeBackend) Callers(ctx context.Context, repo, symbol string) (*search.CallersResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.callers, nil
+}
+
+func textResult(text string, isError bool) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []mcp.Content{{Type: "text", Text: text}}, IsError: isError}
+}
+
+func serve(t *testing.T, ctx context.Context, backend Backend, target string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	server := NewServer(backend, "test", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+	return rec, body
+}
+
+func TestSearchMapsQueryToToolArgs(t *testing.T) {
+	backend := &fakeBackend{result: textResult(`{"results": []}`, false)}
+
+	rec, body := serve(t, context.Background(), backend, "/search?q=retry+logic&repo=r3&limit=5&include_tests=exclude")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Contains(t, body, "results")
+	assert.Equal(t, "search_code", backend.tool)
+	assert.Equal(t, map[string]interface{}{
+		"query":         "retry logic",
+		"repo":          "r3",
+		"limit":         float64(5),
+		"include_tests": "exclude",
+	}, backend.args)
+}
+
+func TestSearchValidatesParams(t *testing.T) {
+	backend := &fakeBackend{result: textResult(`{}`, false)}
+
+	rec, body := serve(t, context.Background(), backend, "/search?repo=r3")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "q parameter is required", body["error"])
+
+	rec, body = serve(t, context.Background(), backend, "/search?q=x&limit=ten")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "limit must be an integer", body["error"])
+	assert.Empty(t, backend.tool, "invalid requests never reach the backend")
+}
+
+func TestSymbolUsesPathName(t *testing.T) {
+	backend := &fakeBackend{result: textResult(`{"query": "Foo.bar", "definitions": []}`, false)}
+
+	rec, _ := serve(t, context.Background(), backend, "/symbols/Foo.bar?kind=method")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "get_symbol", backend.tool)
+	assert.Equal(t, map[string]interface{}{"name": "Foo.bar", "kind": "method"}, backend.args)
+}
+
+func TestToolResultStatusCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *mcp.CallToolResult
+		err    error
+		status int
+	}{
+		{"tool error", textResult("invalid cursor", true), nil, http.StatusBadRequest},
+		{"plain text is not found", textResult("No symbol matching \"x\" found.", false), nil, http.StatusNotFound},
+		{"backend failure", nil, assert.AnError, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fakeBackend{result: tt.result, err: tt.err}
+			rec, body := serve(t, context.Background(), backend, "/symbols/x")
+			assert.Equal(t, tt.status, rec.Code)
+			assert.NotEmpty(t, body["error"])
+		})
+	}
+}
+
+func TestRepoOutsideTokenScopeIsForbidden(t *testing.T) {
+	backend := &fakeBackend{result: textResult(`{}`, false)}
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{Subject: "bot", Repos: []string{"r3"}})
+
+	rec, _ := serve(t, ctx, backend, "/status?repo=m32rimm")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, backend.tool)
+
+	rec, _ = serve(t, ctx, backend, "/status?repo=r3")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "index_status", backend.tool)
+}
+
+func TestCallers(t *testing.T) {
+	backend := &fakeBackend{callers: &search.CallersResponse{
+		Symbol:  "validate",
+		Repo:    "r3",
+		Callers: []search.SymbolRef{{Name: "handle", Kind: "function", FilePath: "api.py", StartLine: 3, EndLine: 9}},
+	}}
+
+	rec, body := serve(t, context.Background(), backend, "/callers?symbol=validate&repo=r3")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "validate", body["symbol"])
+	assert.Len(t, body["callers"], 1)
+
+	rec, _ = serve(t, context.Background(), backend, "/callers?repo=r3")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	for err, status := range map[error]int{
+		search.ErrRepoRequired:     http.StatusBadRequest,
+		search.ErrRepoNotPermitted: http.StatusForbidden,
+		search.ErrGraphUnavailable: http.StatusServiceUnavailable,
+	} {
+		rec, _ = serve(t, context.Background(), &fakeBackend{err: err}, "/callers?symbol=validate")
+		assert.Equal(t, status, rec.Code, err.Error())
+	}
+}
+
+func TestUnknownMethodAndPath(t *testing.T) {
+	server := NewServer(&fakeBackend{}, "test", nil)
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/search?q=x", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSpecDescribesEveryRoute(t *testing.T) {
+	_, body := serve(t, context.Background(), &fakeBackend{}, "/openapi.json")
+
+	assert.Equal(t, "3.1.0", body["openapi"])
+	paths, ok := body["paths"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Len(t, paths, len(routes))
+	for _, rt := range routes {
+		item, ok := paths[rt.path].(map[string]interface{})
+		require.True(t, ok, rt.path)
+		op, ok := item["get"].(map[string]interface{})
+		require.True(t, ok, rt.path)
+		assert.Equal(t, rt.operationID, op["operationId"])
+		assert.Len(t, op["parameters"], len(rt.params))
+	}
+}
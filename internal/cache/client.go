@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// newRedisClient builds the client for storage's Redis deployment without
+// connecting: a single server from storage.redis_url, a cluster, or a
+// sentinel-monitored master.
+func newRedisClient(storage config.StorageConfig) (redis.UniversalClient, error) {
+	rc := storage.Redis
+	tlsConfig, err := redisTLS(rc.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	switch rc.Mode {
+	case "", config.RedisStandalone:
+		opts, err := redis.ParseURL(storage.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Redis URL: %w", err)
+		}
+		if rc.Username != "" {
+			opts.Username = rc.Username
+		}
+		if rc.Password != "" {
+			opts.Password = rc.Password
+		}
+		if rc.DB != 0 {
+			opts.DB = rc.DB
+		}
+		if tlsConfig != nil {
+			opts.TLSConfig = tlsConfig
+		}
+		return redis.NewClient(opts), nil
+
+	case config.RedisCluster:
+		if len(rc.Addrs) == 0 {
+			return nil, fmt.Errorf("redis cluster mode needs storage.redis.addrs")
+		}
+		if rc.DB != 0 {
+			return nil, fmt.Errorf("redis cluster mode only has db 0, got storage.redis.db %d", rc.DB)
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:     rc.Addrs,
+			Username:  rc.Username,
+			Password:  rc.Password,
+			TLSConfig: tlsConfig,
+		}), nil
+
+	case config.RedisSentinel:
+		if len(rc.Addrs) == 0 || rc.MasterName == "" {
+			return nil, fmt.Errorf("redis sentinel mode needs storage.redis.addrs and storage.redis.master_name")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       rc.MasterName,
+			SentinelAddrs:    rc.Addrs,
+			SentinelPassword: rc.SentinelPassword,
+			Username:         rc.Username,
+			Password:         rc.Password,
+			DB:               rc.DB,
+			TLSConfig:        tlsConfig,
+		}), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported storage.redis.mode %q (supported: %s, %s, %s)",
+			rc.Mode, config.RedisStandalone, config.RedisCluster, config.RedisSentinel)
+	}
+}
+
+// redisTLS returns the TLS config for cfg, or nil when TLS is disabled.
+func redisTLS(cfg config.RedisTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read Redis CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in Redis CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load Redis client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
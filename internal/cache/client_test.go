@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRedisClientStandalone(t *testing.T) {
+	client, err := newRedisClient(config.StorageConfig{
+		RedisURL: "redis://localhost:6379/1",
+		Redis:    config.RedisConfig{Username: "indexer", Password: "s3cret", DB: 2, TLS: config.RedisTLSConfig{Enabled: true, ServerName: "redis.internal"}},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	opts := client.(*redis.Client).Options()
+	assert.Equal(t, "indexer", opts.Username)
+	assert.Equal(t, "s3cret", opts.Password)
+	assert.Equal(t, 2, opts.DB, "storage.redis.db overrides the URL's")
+	require.NotNil(t, opts.TLSConfig)
+	assert.Equal(t, "redis.internal", opts.TLSConfig.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), opts.TLSConfig.MinVersion)
+}
+
+func TestNewRedisClientCluster(t *testing.T) {
+	client, err := newRedisClient(config.StorageConfig{
+		Redis: config.RedisConfig{Mode: config.RedisCluster, Addrs: []string{"a:6379", "b:6379"}, Password: "s3cret"},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	opts := client.(*redis.ClusterClient).Options()
+	assert.Equal(t, []string{"a:6379", "b:6379"}, opts.Addrs)
+	assert.Equal(t, "s3cret", opts.Password)
+
+	_, err = newRedisClient(config.StorageConfig{Redis: config.RedisConfig{Mode: config.RedisCluster}})
+	assert.ErrorContains(t, err, "storage.redis.addrs")
+	_, err = newRedisClient(config.StorageConfig{Redis: config.RedisConfig{Mode: config.RedisCluster, Addrs: []string{"a:6379"}, DB: 1}})
+	assert.ErrorContains(t, err, "only has db 0")
+}
+
+func TestNewRedisClientSentinel(t *testing.T) {
+	client, err := newRedisClient(config.StorageConfig{
+		Redis: config.RedisConfig{Mode: config.RedisSentinel, Addrs: []string{"s1:26379"}, MasterName: "cache", DB: 3},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	_, ok := client.(*redis.Client)
+	assert.True(t, ok, "sentinel mode fails over to a plain client of the master")
+
+	_, err = newRedisClient(config.StorageConfig{Redis: config.RedisConfig{Mode: config.RedisSentinel, Addrs: []string{"s1:26379"}}})
+	assert.ErrorContains(t, err, "master_name")
+
+	_, err = newRedisClient(config.StorageConfig{Redis: config.RedisConfig{Mode: "replicas"}})
+	assert.ErrorContains(t, err, "unsupported storage.redis.mode")
+}
+
+func TestRedisTLS(t *testing.T) {
+	tlsConfig, err := redisTLS(config.RedisTLSConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig, "disabled")
+
+	_, err = redisTLS(config.RedisTLSConfig{Enabled: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorContains(t, err, "CA file")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+	_, err = redisTLS(config.RedisTLSConfig{Enabled: true, CAFile: notPEM})
+	assert.ErrorContains(t, err, "no certificates")
+
+	_, err = redisTLS(config.RedisTLSConfig{Enabled: true, CertFile: notPEM, KeyFile: notPEM})
+	assert.ErrorContains(t, err, "client certificate")
+}
@@ -4,31 +4,41 @@ package cache
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/randalmurphal/code-indexer/internal/config"
 	"github.com/redis/go-redis/v9"
 )
 
 // RedisCache provides caching via Redis.
 type RedisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
-// NewRedisCache creates a new Redis cache.
+// NewRedisCache creates a new Redis cache for a single server URL.
 func NewRedisCache(url string) (*RedisCache, error) {
-	opts, err := redis.ParseURL(url)
+	return NewRedisCacheFromConfig(config.StorageConfig{RedisURL: url})
+}
+
+// NewRedisCacheFromConfig creates a Redis cache for storage's deployment:
+// standalone (storage.redis_url), cluster, or sentinel, with the auth, db,
+// and TLS settings of storage.redis.
+func NewRedisCacheFromConfig(storage config.StorageConfig) (*RedisCache, error) {
+	client, err := newRedisClient(storage)
 	if err != nil {
-		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+		return nil, err
 	}
 
-	client := redis.NewClient(opts)
-
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("Redis connection failed: %w", err)
 	}
 
@@ -44,6 +54,25 @@ func (c *RedisCache) Get(ctx context.Context, key string) (string, error) {
 	return val, err
 }
 
+// GetWithTTL is Get that also returns how long the key has left to live:
+// 0 when it is missing or never expires.
+func (c *RedisCache) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	pipe := c.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return "", 0, err
+	}
+	val, err := get.Result()
+	if err == redis.Nil {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return val, max(pttl.Val(), 0), nil
+}
+
 // Set stores a value in cache with TTL.
 func (c *RedisCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
 	return c.client.Set(ctx, key, value, ttl).Err()
@@ -56,12 +85,11 @@ func (c *RedisCache) Delete(ctx context.Context, key string) error {
 
 // DeletePattern removes all keys matching pattern using batched pipeline.
 func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
-	iter := c.client.Scan(ctx, 0, pattern, 100).Iterator()
 	pipe := c.client.Pipeline()
 	count := 0
 
-	for iter.Next(ctx) {
-		pipe.Del(ctx, iter.Val())
+	err := c.scanKeys(ctx, pattern, func(key string) error {
+		pipe.Del(ctx, key)
 		count++
 		if count >= 100 {
 			if _, err := pipe.Exec(ctx); err != nil {
@@ -70,6 +98,10 @@ func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
 			pipe = c.client.Pipeline()
 			count = 0
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	if count > 0 {
@@ -77,7 +109,33 @@ func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
 			return err
 		}
 	}
+	return nil
+}
 
+// scanKeys calls fn with every key matching pattern, one call at a time. A
+// cluster is scanned on each master, since SCAN only sees one node's keys.
+func (c *RedisCache) scanKeys(ctx context.Context, pattern string, fn func(key string) error) error {
+	cluster, ok := c.client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, c.client, pattern, fn)
+	}
+	var mu sync.Mutex
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return scanNode(ctx, node, pattern, func(key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return fn(key)
+		})
+	})
+}
+
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string, fn func(key string) error) error {
+	iter := client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		if err := fn(iter.Val()); err != nil {
+			return err
+		}
+	}
 	return iter.Err()
 }
 
@@ -95,6 +153,107 @@ func (c *RedisCache) IncrIndexVersion(ctx context.Context, repo string) (int64,
 	return c.client.Incr(ctx, "index:version:"+repo).Result()
 }
 
+// InvalidateRepo bumps repo's index version and deletes its cached queries,
+// along with cached searches across all repos, which may include it. It
+// returns the new version.
+func (c *RedisCache) InvalidateRepo(ctx context.Context, repo string) (int64, error) {
+	version, err := c.IncrIndexVersion(ctx, repo)
+	if err != nil {
+		return 0, fmt.Errorf("bump index version: %w", err)
+	}
+	for _, scope := range []string{repo, "all", ""} {
+		if err := c.DeletePattern(ctx, "query:"+scope+":*"); err != nil {
+			return version, fmt.Errorf("delete cached queries: %w", err)
+		}
+		if err := c.DeletePattern(ctx, "semantic:"+scope+":*"); err != nil {
+			return version, fmt.Errorf("delete cached query embeddings: %w", err)
+		}
+	}
+	return version, nil
+}
+
+// SemanticEntry is a cached query's embedding and the key of its response.
+type SemanticEntry struct {
+	Query  string    `json:"query"`
+	Vector []float32 `json:"vector"`
+	Key    string    `json:"key"`
+}
+
+// SemanticCacheKey is the key of the embeddings of cached queries sharing a
+// repo, search options (variant), and index version.
+func SemanticCacheKey(repo, variant string, version int64) string {
+	h := sha256.Sum256([]byte(variant))
+	return fmt.Sprintf("semantic:%s:%x:%d", repo, h[:8], version)
+}
+
+// AddSemantic records entry under key, most recent first, keeping at most
+// maxEntries and expiring them all ttl after the latest.
+func (c *RedisCache) AddSemantic(ctx context.Context, key string, entry SemanticEntry, maxEntries int, ttl time.Duration) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode semantic entry: %w", err)
+	}
+	pipe := c.client.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, int64(maxEntries)-1)
+	pipe.Expire(ctx, key, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// SemanticEntries returns the entries under key, most recent first.
+func (c *RedisCache) SemanticEntries(ctx context.Context, key string) ([]SemanticEntry, error) {
+	values, err := c.client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]SemanticEntry, 0, len(values))
+	for _, v := range values {
+		var entry SemanticEntry
+		if err := json.Unmarshal([]byte(v), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// QueryKeyCounts returns how many cached queries each repo has ("all" and
+// "" for cross-repo searches).
+func (c *RedisCache) QueryKeyCounts(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+	err := c.scanKeys(ctx, "query:*", func(key string) error {
+		if repo, ok := queryKeyRepo(key); ok {
+			counts[repo]++
+		}
+		return nil
+	})
+	return counts, err
+}
+
+// ClearQueries deletes every cached query and query embedding.
+func (c *RedisCache) ClearQueries(ctx context.Context) error {
+	if err := c.DeletePattern(ctx, "query:*"); err != nil {
+		return err
+	}
+	return c.DeletePattern(ctx, "semantic:*")
+}
+
+// queryKeyRepo returns the repo of a QueryCacheKey.
+func queryKeyRepo(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, "query:")
+	if !ok {
+		return "", false
+	}
+	for range 2 { // Drop the version, then the hash
+		i := strings.LastIndexByte(rest, ':')
+		if i < 0 {
+			return "", false
+		}
+		rest = rest[:i]
+	}
+	return rest, true
+}
+
 // Close closes the Redis connection.
 func (c *RedisCache) Close() error {
 	return c.client.Close()
@@ -115,6 +115,92 @@ func TestRedisCacheDeletePattern(t *testing.T) {
 	_ = cache.Delete(ctx, "test:other:c")
 }
 
+func TestRedisCacheInvalidateRepo(t *testing.T) {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		redisURL = "redis://localhost:6379"
+	}
+
+	cache, err := NewRedisCache(redisURL)
+	if err != nil {
+		t.Skip("Redis not available")
+	}
+
+	ctx := context.Background()
+	repo := "test-repo-invalidate"
+	_ = cache.Delete(ctx, "index:version:"+repo)
+
+	_ = cache.Set(ctx, QueryCacheKey(repo, "q", 0), "1", time.Minute)
+	_ = cache.Set(ctx, QueryCacheKey("all", "q", 0), "2", time.Minute)
+	_ = cache.Set(ctx, QueryCacheKey("test-other-repo", "q", 0), "3", time.Minute)
+
+	version, err := cache.InvalidateRepo(ctx, repo)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+
+	got, _ := cache.Get(ctx, QueryCacheKey(repo, "q", 0))
+	assert.Empty(t, got)
+	got, _ = cache.Get(ctx, QueryCacheKey("all", "q", 0))
+	assert.Empty(t, got, "cross-repo searches may include the repo")
+	got, _ = cache.Get(ctx, QueryCacheKey("test-other-repo", "q", 0))
+	assert.Equal(t, "3", got)
+
+	// Clean up
+	_ = cache.Delete(ctx, QueryCacheKey("test-other-repo", "q", 0))
+	_ = cache.Delete(ctx, "index:version:"+repo)
+}
+
+func TestRedisCacheSemanticEntries(t *testing.T) {
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		redisURL = "redis://localhost:6379"
+	}
+
+	cache, err := NewRedisCache(redisURL)
+	if err != nil {
+		t.Skip("Redis not available")
+	}
+
+	ctx := context.Background()
+	key := SemanticCacheKey("test-repo-semantic", "\x00group_by:file", 1)
+	_ = cache.Delete(ctx, key)
+
+	for _, q := range []string{"a", "b", "c"} {
+		entry := SemanticEntry{Query: q, Vector: []float32{1, 0}, Key: QueryCacheKey("test-repo-semantic", q, 1)}
+		require.NoError(t, cache.AddSemantic(ctx, key, entry, 2, time.Minute))
+	}
+
+	entries, err := cache.SemanticEntries(ctx, key)
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "older entries are trimmed")
+	assert.Equal(t, "c", entries[0].Query)
+	assert.Equal(t, []float32{1, 0}, entries[0].Vector)
+
+	// Clean up
+	_ = cache.Delete(ctx, key)
+}
+
+func TestSemanticCacheKey(t *testing.T) {
+	key := SemanticCacheKey("r3", "\x00group_by:file", 4)
+	assert.Contains(t, key, "semantic:r3:")
+	assert.Contains(t, key, ":4")
+	assert.NotEqual(t, key, SemanticCacheKey("r3", "", 4), "options are part of the key")
+	assert.NotEqual(t, key, SemanticCacheKey("r3", "\x00group_by:file", 5), "so is the index version")
+}
+
+func TestQueryKeyRepo(t *testing.T) {
+	repo, ok := queryKeyRepo(QueryCacheKey("my-repo", "auth", 3))
+	assert.True(t, ok)
+	assert.Equal(t, "my-repo", repo)
+
+	repo, ok = queryKeyRepo(QueryCacheKey("", "auth", 0))
+	assert.True(t, ok)
+	assert.Empty(t, repo)
+
+	_, ok = queryKeyRepo("index:version:my-repo")
+	assert.False(t, ok)
+}
+
 func TestQueryCacheKey(t *testing.T) {
 	key := QueryCacheKey("test-repo", "hello world", 42)
 	assert.Contains(t, key, "query:")
@@ -9,6 +9,9 @@ const (
 	ChunkTypeDoc  ChunkType = "doc"
 )
 
+// WorkspaceDirty is the Workspace of chunks indexed from uncommitted changes.
+const WorkspaceDirty = "dirty"
+
 // Chunk represents an indexable unit of code or documentation.
 type Chunk struct {
 	// Identity
@@ -34,6 +37,7 @@ type Chunk struct {
 	ContextHeader string `json:"context_header,omitempty"` // Injected context for methods
 	Signature     string `json:"signature,omitempty"`
 	Docstring     string `json:"docstring,omitempty"`
+	Summary       string `json:"summary,omitempty"` // Index-time LLM description of large symbols
 
 	// Metadata
 	IsTest          bool    `json:"is_test"`
@@ -41,6 +45,31 @@ type Chunk struct {
 	HasSecrets      bool    `json:"has_secrets"`
 	FollowsPattern  string  `json:"follows_pattern,omitempty"`
 
+	// Other places with identical content, stored once under this chunk
+	// when the repo deduplicates chunks
+	Duplicates []Location `json:"duplicates,omitempty"`
+
+	// Custom tags set by indexer enrichers
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Ownership (from git history of the file)
+	LastAuthor      string `json:"last_author,omitempty"`
+	LastAuthorEmail string `json:"last_author_email,omitempty"`
+	LastCommit      string `json:"last_commit,omitempty"`
+
+	// Owners from the repo's CODEOWNERS file (users, teams, or emails)
+	Owners []string `json:"owners,omitempty"`
+
+	// License of the file: an SPDX identifier, "proprietary", or "unknown"
+	License string `json:"license,omitempty"`
+
+	// Branch checked out when the chunk was indexed; empty when detached
+	Branch string `json:"branch,omitempty"`
+
+	// WorkspaceDirty for chunks of files with uncommitted changes; empty
+	// for committed code
+	Workspace string `json:"workspace,omitempty"`
+
 	// Vector (populated after embedding)
 	Vector []float32 `json:"vector,omitempty"`
 
@@ -48,6 +77,13 @@ type Chunk struct {
 	Score float32 `json:"-"`
 }
 
+// Location is a span of a file.
+type Location struct {
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
 // TokenEstimate returns rough token count for the chunk.
 func (c *Chunk) TokenEstimate() int {
 	// Rough estimate: ~4 chars per token
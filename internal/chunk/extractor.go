@@ -11,31 +11,59 @@ import (
 
 // Extractor converts parsed symbols into chunks.
 type Extractor struct {
-	testPatterns        []string
+	tests               *TestMatcher
 	hierarchical        bool
+	fileSummaries       bool
 	hierarchicalChunker *HierarchicalChunker
 	secretDetector      *security.SecretDetector
+	secretPolicy        SecretPolicy
+	testWeight          float32
+	fileSummaryWeight   float32
 }
 
 // NewExtractor creates a chunk extractor with default test patterns.
 func NewExtractor() *Extractor {
 	return &Extractor{
-		testPatterns: []string{
-			"test_",
-			"_test.py",
-			"_test.go",
-			".test.js",
-			".test.ts",
-			".spec.js",
-			".spec.ts",
-			"/tests/",
-			"/__tests__/",
-		},
+		tests:               NewTestMatcher(nil, nil),
 		hierarchicalChunker: NewHierarchicalChunker(),
 		secretDetector:      security.NewSecretDetector(),
+		secretPolicy:        SecretsRedact,
+		testWeight:          TestWeight,
+		fileSummaryWeight:   FileSummaryWeight,
 	}
 }
 
+// SetTestMatcher replaces the default test file detection, e.g. with a
+// repo's configured patterns.
+func (e *Extractor) SetTestMatcher(m *TestMatcher) {
+	e.tests = m
+}
+
+// SetSecretDetector replaces the default secret detection, e.g. with one
+// that has a repo's extra rules and allowlist.
+func (e *Extractor) SetSecretDetector(d *security.SecretDetector) {
+	e.secretDetector = d
+}
+
+// SetSecretPolicy decides what happens to chunks containing secrets.
+func (e *Extractor) SetSecretPolicy(p SecretPolicy) {
+	e.secretPolicy = p
+}
+
+// SetWeights replaces the default retrieval weights: test multiplies the
+// weight of every chunk from a test file, and fileSummary is the weight of
+// file summary chunks.
+func (e *Extractor) SetWeights(test, fileSummary float32) {
+	e.testWeight = test
+	e.fileSummaryWeight = fileSummary
+	e.hierarchicalChunker.testWeight = test
+}
+
+// SetFileSummaries enables or disables one KindFileSummary chunk per file.
+func (e *Extractor) SetFileSummaries(enabled bool) {
+	e.fileSummaries = enabled
+}
+
 // SetHierarchicalChunking enables or disables hierarchical chunking for large files.
 func (e *Extractor) SetHierarchicalChunking(enabled bool) {
 	e.hierarchical = enabled
@@ -45,6 +73,9 @@ func (e *Extractor) SetHierarchicalChunking(enabled bool) {
 type ExtractResult struct {
 	Chunks        []Chunk
 	Relationships []parser.Relationship
+
+	SecretChunksSkipped int  // Dropped for containing secrets (SecretsSkipChunk)
+	SecretsSkippedFile  bool // The file contains secrets and yielded nothing (SecretsSkipFile)
 }
 
 // Extract parses code and returns chunks.
@@ -63,6 +94,10 @@ func (e *Extractor) ExtractWithRelationships(source []byte, filePath, repo, modu
 		return nil, fmt.Errorf("unsupported file type: %s", filePath)
 	}
 
+	if e.secretPolicy == SecretsSkipFile && !e.secretDetector.PathAllowed(filePath) && e.secretDetector.HasSecrets(string(source)) {
+		return &ExtractResult{SecretsSkippedFile: true}, nil
+	}
+
 	p, err := parser.NewParser(lang)
 	if err != nil {
 		return nil, err
@@ -79,16 +114,24 @@ func (e *Extractor) ExtractWithRelationships(source []byte, filePath, repo, modu
 
 	isTest := e.isTestFile(filePath)
 
+	var summary []Chunk
+	if e.fileSummaries {
+		if c, ok := e.fileSummary(source, filePath, repo, modulePath, isTest, parseResult); ok {
+			summary = append(summary, c)
+		}
+	}
+
 	// Use hierarchical chunking if enabled
 	if e.hierarchical {
 		chunks := e.hierarchicalChunker.ChunkSymbols(symbols, filePath, repo, modulePath, isTest)
-		return &ExtractResult{Chunks: chunks, Relationships: relationships}, nil
+		chunks, skipped := e.applySecretPolicy(append(summary, chunks...))
+		return &ExtractResult{Chunks: chunks, Relationships: relationships, SecretChunksSkipped: skipped}, nil
 	}
 
 	// Standard chunking
 	moduleRoot, submodule := parseModulePath(modulePath)
 
-	var chunks []Chunk
+	chunks := summary
 
 	for _, sym := range symbols {
 		chunk := Chunk{
@@ -110,7 +153,7 @@ func (e *Extractor) ExtractWithRelationships(source []byte, filePath, repo, modu
 
 		// Set retrieval weight
 		if isTest {
-			chunk.RetrievalWeight = 0.5
+			chunk.RetrievalWeight = e.testWeight
 		} else {
 			chunk.RetrievalWeight = 1.0
 		}
@@ -123,27 +166,43 @@ func (e *Extractor) ExtractWithRelationships(source []byte, filePath, repo, modu
 		// Generate ID
 		chunk.ID = generateChunkID(repo, filePath, sym.Name, sym.StartLine)
 
-		// Detect and redact secrets
-		if e.secretDetector.HasSecrets(chunk.Content) {
-			secrets := e.secretDetector.Detect(chunk.Content)
-			chunk.Content = e.secretDetector.Redact(chunk.Content, secrets)
-			chunk.HasSecrets = true
-		}
-
 		chunks = append(chunks, chunk)
 	}
 
-	return &ExtractResult{Chunks: chunks, Relationships: relationships}, nil
+	chunks, skipped := e.applySecretPolicy(chunks)
+	return &ExtractResult{Chunks: chunks, Relationships: relationships, SecretChunksSkipped: skipped}, nil
 }
 
-func (e *Extractor) isTestFile(filePath string) bool {
-	lower := strings.ToLower(filePath)
-	for _, pattern := range e.testPatterns {
-		if strings.Contains(lower, pattern) {
-			return true
+// ApplySecretPolicy applies the secrets policy to chunks built outside the
+// extractor, such as doc site sections, returning how many were dropped.
+func (e *Extractor) ApplySecretPolicy(chunks []Chunk) ([]Chunk, int) {
+	return e.applySecretPolicy(chunks)
+}
+
+// applySecretPolicy redacts the secrets of chunks and flags them, or with
+// SecretsSkipChunk drops the chunks containing any, returning how many were
+// dropped. Docstrings are checked too: a JSDoc comment is outside its
+// symbol's content. Chunks of allowlisted files are kept as they are.
+func (e *Extractor) applySecretPolicy(chunks []Chunk) ([]Chunk, int) {
+	kept := chunks[:0]
+	for _, c := range chunks {
+		if e.secretDetector.PathAllowed(c.FilePath) || (!e.secretDetector.HasSecrets(c.Content) && !e.secretDetector.HasSecrets(c.Docstring)) {
+			kept = append(kept, c)
+			continue
+		}
+		if e.secretPolicy == SecretsSkipChunk {
+			continue
 		}
+		c.Content = e.secretDetector.Redact(c.Content, e.secretDetector.Detect(c.Content))
+		c.Docstring = e.secretDetector.Redact(c.Docstring, e.secretDetector.Detect(c.Docstring))
+		c.HasSecrets = true
+		kept = append(kept, c)
 	}
-	return false
+	return kept, len(chunks) - len(kept)
+}
+
+func (e *Extractor) isTestFile(filePath string) bool {
+	return e.tests.Match(filePath)
 }
 
 func parseModulePath(modulePath string) (root, sub string) {
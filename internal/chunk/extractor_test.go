@@ -169,6 +169,76 @@ def connect_db():
 	assert.NotContains(t, chunk.Content, "supersecret", "should not contain original secret")
 }
 
+func TestExtractRedactsJSDocSecrets(t *testing.T) {
+	code := `/**
+ * Connects to the database.
+ * Defaults to postgres://app:supersecret123456@db:5432/app
+ */
+function connect(dsn) {
+    return new Database(dsn);
+}
+`
+
+	extractor := NewExtractor()
+	chunks, err := extractor.Extract([]byte(code), "db.js", "repo", "module")
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+
+	assert.True(t, chunks[0].HasSecrets, "a secret in the JSDoc flags the chunk")
+	assert.Contains(t, chunks[0].Docstring, "Connects to the database.")
+	assert.NotContains(t, chunks[0].Docstring, "supersecret")
+}
+
+func TestExtractSecretPolicy(t *testing.T) {
+	code := `
+def connect_db():
+    password = "supersecret123456"
+    return Database(password=password)
+
+
+def ping():
+    return True
+`
+
+	extractor := NewExtractor()
+	extractor.SetSecretPolicy(SecretsSkipChunk)
+	result, err := extractor.ExtractWithRelationships([]byte(code), "db.py", "repo", "module")
+	require.NoError(t, err)
+	require.Len(t, result.Chunks, 1)
+	assert.Equal(t, "ping", result.Chunks[0].SymbolName)
+	assert.Equal(t, 1, result.SecretChunksSkipped)
+
+	extractor.SetSecretPolicy(SecretsSkipFile)
+	result, err = extractor.ExtractWithRelationships([]byte(code), "db.py", "repo", "module")
+	require.NoError(t, err)
+	assert.True(t, result.SecretsSkippedFile)
+	assert.Empty(t, result.Chunks)
+
+	// Hierarchical chunks are redacted like standard ones
+	extractor.SetSecretPolicy(SecretsRedact)
+	extractor.SetHierarchicalChunking(true)
+	result, err = extractor.ExtractWithRelationships([]byte(code), "db.py", "repo", "module")
+	require.NoError(t, err)
+	for _, c := range result.Chunks {
+		assert.NotContains(t, c.Content, "supersecret")
+	}
+	assert.False(t, result.SecretsSkippedFile)
+	assert.Zero(t, result.SecretChunksSkipped)
+}
+
+func TestParseSecretPolicy(t *testing.T) {
+	policy, err := ParseSecretPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, SecretsRedact, policy)
+
+	policy, err = ParseSecretPolicy("skip_file")
+	require.NoError(t, err)
+	assert.Equal(t, SecretsSkipFile, policy)
+
+	_, err = ParseSecretPolicy("drop")
+	assert.ErrorContains(t, err, "unknown secrets policy")
+}
+
 func findChunkByName(chunks []Chunk, name string) *Chunk {
 	for i := range chunks {
 		if chunks[i].SymbolName == name {
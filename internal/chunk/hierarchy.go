@@ -18,6 +18,7 @@ const (
 type HierarchicalChunker struct {
 	maxTokens           int
 	largeClassThreshold int
+	testWeight          float32
 }
 
 // NewHierarchicalChunker creates a new chunker.
@@ -25,6 +26,7 @@ func NewHierarchicalChunker() *HierarchicalChunker {
 	return &HierarchicalChunker{
 		maxTokens:           MaxChunkTokens,
 		largeClassThreshold: LargeClassMethods,
+		testWeight:          TestWeight,
 	}
 }
 
@@ -46,7 +48,7 @@ func (h *HierarchicalChunker) ChunkSymbols(symbols []parser.Symbol, filePath, re
 	moduleRoot, submodule := parseModulePath(modulePath)
 	weight := float32(1.0)
 	if isTest {
-		weight = 0.5
+		weight = h.testWeight
 	}
 
 	// Process top-level symbols
@@ -77,6 +79,9 @@ func (h *HierarchicalChunker) ChunkSymbols(symbols []parser.Symbol, filePath, re
 		}
 	}
 
+	for i := range chunks {
+		chunks[i].IsTest = isTest
+	}
 	return chunks
 }
 
@@ -106,7 +111,6 @@ func (h *HierarchicalChunker) createClassSummary(class parser.Symbol, methods []
 		SymbolName:      class.Name,
 		Content:         summary,
 		Docstring:       class.Docstring,
-		IsTest:          weight < 1.0,
 		RetrievalWeight: weight,
 	}
 }
@@ -130,7 +134,6 @@ func (h *HierarchicalChunker) createMethodChunk(method parser.Symbol, className,
 		ContextHeader:   contextHeader,
 		Signature:       method.Signature,
 		Docstring:       method.Docstring,
-		IsTest:          weight < 1.0,
 		RetrievalWeight: weight,
 	}
 }
@@ -150,7 +153,6 @@ func (h *HierarchicalChunker) createClassChunk(class parser.Symbol, filePath, re
 		SymbolName:      class.Name,
 		Content:         class.Content,
 		Docstring:       class.Docstring,
-		IsTest:          weight < 1.0,
 		RetrievalWeight: weight,
 	}
 }
@@ -171,7 +173,6 @@ func (h *HierarchicalChunker) createSymbolChunk(sym parser.Symbol, filePath, rep
 		Content:         sym.Content,
 		Signature:       sym.Signature,
 		Docstring:       sym.Docstring,
-		IsTest:          weight < 1.0,
 		RetrievalWeight: weight,
 	}
 }
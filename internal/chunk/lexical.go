@@ -0,0 +1,83 @@
+package chunk
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxLexicalTokenLen skips hashes, base64 blobs, and other long runs that
+// never help keyword search.
+const maxLexicalTokenLen = 64
+
+// LexicalTerms is the keyword index text of a chunk: the distinct words of
+// its symbol, header, signature, docstring, summary, and content, lowercased,
+// with identifiers also split into their camelCase and snake_case parts.
+func LexicalTerms(c Chunk) string {
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for _, text := range []string{c.SymbolName, c.ContextHeader, c.Signature, c.Docstring, c.Summary, c.Content} {
+		for _, token := range LexicalTokens(text) {
+			if seen[token] {
+				continue
+			}
+			seen[token] = true
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(token)
+		}
+	}
+	return b.String()
+}
+
+// LexicalTokens splits text into lowercased words in first-seen order, each
+// identifier followed by its parts ("parseHTTPConfig" yields
+// parsehttpconfig, parse, http, config). Duplicates are dropped.
+func LexicalTokens(text string) []string {
+	var tokens []string
+	seen := make(map[string]bool)
+	add := func(token string) {
+		token = strings.ToLower(token)
+		if len(token) < 2 || len(token) > maxLexicalTokenLen || seen[token] {
+			return
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+	isWordRune := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) }) {
+		add(strings.Trim(word, "_"))
+		parts := identifierParts(word)
+		if len(parts) > 1 {
+			for _, part := range parts {
+				add(part)
+			}
+		}
+	}
+	return tokens
+}
+
+// identifierParts splits an identifier at underscores and case changes.
+func identifierParts(word string) []string {
+	var parts []string
+	for _, piece := range strings.Split(word, "_") {
+		runes := []rune(piece)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			lowerToUpper := unicode.IsLower(prev) && unicode.IsUpper(cur)
+			// The last capital of an acronym starts the next word: HTTPServer
+			acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, string(runes[start:]))
+		}
+	}
+	return parts
+}
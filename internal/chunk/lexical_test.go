@@ -0,0 +1,38 @@
+package chunk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLexicalTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"parseHTTPConfig", []string{"parsehttpconfig", "parse", "http", "config"}},
+		{"load_repo_config()", []string{"load_repo_config", "load", "repo", "config"}},
+		{"ECONNREFUSED: connection refused", []string{"econnrefused", "connection", "refused"}},
+		{"__init__ a x", []string{"init"}},
+		{"Retry retry RETRY", []string{"retry"}},
+		{strings.Repeat("f", 65) + " ok", []string{"ok"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, LexicalTokens(tt.text))
+		})
+	}
+}
+
+func TestLexicalTerms(t *testing.T) {
+	c := Chunk{
+		SymbolName: "UploadFile",
+		Signature:  "def UploadFile(path):",
+		Docstring:  "Upload a file to S3.",
+		Content:    "def UploadFile(path):\n    raise UploadError(\"bucket not found\")",
+	}
+
+	assert.Equal(t, "uploadfile upload file def path to s3 raise uploaderror error bucket not found", LexicalTerms(c))
+}
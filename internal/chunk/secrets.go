@@ -0,0 +1,23 @@
+package chunk
+
+import "fmt"
+
+// SecretPolicy decides what happens to chunks containing secrets.
+type SecretPolicy string
+
+const (
+	SecretsRedact    SecretPolicy = "redact"     // Redact the secrets, keep the chunk (default)
+	SecretsSkipChunk SecretPolicy = "skip_chunk" // Leave chunks containing secrets out
+	SecretsSkipFile  SecretPolicy = "skip_file"  // Leave files containing secrets out entirely
+)
+
+// ParseSecretPolicy parses a repo's secrets policy; empty is SecretsRedact.
+func ParseSecretPolicy(s string) (SecretPolicy, error) {
+	switch p := SecretPolicy(s); p {
+	case "":
+		return SecretsRedact, nil
+	case SecretsRedact, SecretsSkipChunk, SecretsSkipFile:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown secrets policy %q (want redact, skip_chunk, or skip_file)", s)
+}
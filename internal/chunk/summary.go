@@ -0,0 +1,101 @@
+package chunk
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/parser"
+)
+
+// KindFileSummary marks the one chunk per file that outlines it.
+const KindFileSummary = "file_summary"
+
+// FileSummaryWeight scales a file summary's retrieval weight below the
+// file's symbols, so it wins broad queries without crowding out code.
+const FileSummaryWeight = 0.7
+
+// TestWeight scales the retrieval weight of chunks from test files.
+const TestWeight = 0.5
+
+// fileSummary builds the summary chunk of a file: its module doc, imports,
+// and top-level symbols by kind. It returns false for files with none.
+func (e *Extractor) fileSummary(source []byte, filePath, repo, modulePath string, isTest bool, parsed *parser.ParseResult) (Chunk, bool) {
+	var imports []string
+	seen := make(map[string]bool)
+	for _, rel := range parsed.Relationships {
+		if rel.Kind == parser.RelationshipImports && rel.TargetPath != "" && !seen[rel.TargetPath] {
+			seen[rel.TargetPath] = true
+			imports = append(imports, rel.TargetPath)
+		}
+	}
+	var classes, functions, other []string
+	for _, sym := range parsed.Symbols {
+		if sym.Parent != "" {
+			continue
+		}
+		switch sym.Kind {
+		case parser.SymbolClass:
+			classes = append(classes, sym.Name)
+		case parser.SymbolFunction:
+			functions = append(functions, sym.Name)
+		default:
+			other = append(other, sym.Name)
+		}
+	}
+	if parsed.ModuleDoc == "" && len(imports) == 0 && len(classes)+len(functions)+len(other) == 0 {
+		return Chunk{}, false
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "File: %s\n", filePath)
+	if modulePath != "" {
+		fmt.Fprintf(&b, "Module: %s\n", modulePath)
+	}
+	if parsed.ModuleDoc != "" {
+		fmt.Fprintf(&b, "\n%s\n\n", parsed.ModuleDoc)
+	}
+	for _, list := range []struct {
+		label string
+		names []string
+	}{
+		{"Imports", imports},
+		{"Classes", classes},
+		{"Functions", functions},
+		{"Other", other},
+	} {
+		if len(list.names) > 0 {
+			fmt.Fprintf(&b, "%s: %s\n", list.label, strings.Join(list.names, ", "))
+		}
+	}
+
+	weight := e.fileSummaryWeight
+	if isTest {
+		weight *= e.testWeight
+	}
+	moduleRoot, submodule := parseModulePath(modulePath)
+	c := Chunk{
+		ID:              generateChunkID(repo, filePath, KindFileSummary, 0),
+		Repo:            repo,
+		FilePath:        filePath,
+		StartLine:       1,
+		EndLine:         lineCount(source),
+		Type:            ChunkTypeCode,
+		Kind:            KindFileSummary,
+		ModulePath:      modulePath,
+		ModuleRoot:      moduleRoot,
+		Submodule:       submodule,
+		Content:         strings.TrimSpace(b.String()),
+		IsTest:          isTest,
+		RetrievalWeight: weight,
+	}
+	return c, true
+}
+
+func lineCount(source []byte) int {
+	n := bytes.Count(source, []byte("\n"))
+	if len(source) > 0 && source[len(source)-1] != '\n' {
+		n++
+	}
+	return max(n, 1)
+}
@@ -0,0 +1,92 @@
+package chunk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSummary(t *testing.T) {
+	code := `"""User lookups backed by the AWS directory."""
+import os
+import boto3
+from fisio.common import cache
+
+class UserService:
+    def get(self, user_id):
+        return cache.get(user_id)
+
+def get_user(user_id):
+    return UserService().get(user_id)
+`
+
+	extractor := NewExtractor()
+	extractor.SetFileSummaries(true)
+	chunks, err := extractor.Extract([]byte(code), "fisio/users.py", "m32rimm", "fisio.users")
+	require.NoError(t, err)
+
+	require.NotEmpty(t, chunks)
+	summary := chunks[0]
+	assert.Equal(t, KindFileSummary, summary.Kind)
+	assert.Equal(t, "fisio/users.py", summary.FilePath)
+	assert.Equal(t, 1, summary.StartLine)
+	assert.Equal(t, 11, summary.EndLine)
+	assert.Empty(t, summary.SymbolName)
+	assert.Equal(t, float32(FileSummaryWeight), summary.RetrievalWeight)
+	assert.Equal(t, strings.Join([]string{
+		"File: fisio/users.py",
+		"Module: fisio.users",
+		"",
+		"User lookups backed by the AWS directory.",
+		"",
+		"Imports: os, boto3, fisio.common",
+		"Classes: UserService",
+		"Functions: get_user",
+	}, "\n"), summary.Content)
+
+	for _, c := range chunks[1:] {
+		assert.NotEqual(t, KindFileSummary, c.Kind)
+	}
+}
+
+func TestFileSummaryTestFile(t *testing.T) {
+	extractor := NewExtractor()
+	extractor.SetFileSummaries(true)
+	chunks, err := extractor.Extract([]byte("import pytest\n\ndef test_get_user():\n    pass\n"), "tests/test_users.py", "m32rimm", "tests.test_users")
+	require.NoError(t, err)
+
+	require.NotEmpty(t, chunks)
+	assert.Equal(t, KindFileSummary, chunks[0].Kind)
+	assert.True(t, chunks[0].IsTest)
+	assert.Equal(t, float32(FileSummaryWeight*0.5), chunks[0].RetrievalWeight)
+}
+
+func TestExtractorSetWeights(t *testing.T) {
+	extractor := NewExtractor()
+	extractor.SetFileSummaries(true)
+	extractor.SetWeights(0.25, 0.9)
+	chunks, err := extractor.Extract([]byte("import pytest\n\ndef test_get_user():\n    pass\n"), "tests/test_users.py", "m32rimm", "tests.test_users")
+	require.NoError(t, err)
+
+	require.Len(t, chunks, 2)
+	assert.Equal(t, float32(0.9*0.25), chunks[0].RetrievalWeight)
+	assert.Equal(t, float32(0.25), chunks[1].RetrievalWeight)
+	assert.True(t, chunks[1].IsTest)
+}
+
+func TestFileSummarySkipsEmptyFiles(t *testing.T) {
+	extractor := NewExtractor()
+	extractor.SetFileSummaries(true)
+	chunks, err := extractor.Extract([]byte("# Package marker\n"), "fisio/__init__.py", "m32rimm", "fisio")
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+}
+
+func TestFileSummaryDisabledByDefault(t *testing.T) {
+	chunks, err := NewExtractor().Extract([]byte("def f():\n    pass\n"), "f.py", "repo", "f")
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "function", chunks[0].Kind)
+}
@@ -0,0 +1,95 @@
+package chunk
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// DefaultTestPatterns are the file name globs that mark test files, per
+// language. A repo's tests.patterns entry for a language replaces its
+// defaults here.
+var DefaultTestPatterns = map[string][]string{
+	"python":     {"test_*.py", "*_test.py", "conftest.py"},
+	"javascript": {"*.test.js", "*.spec.js", "*.test.jsx", "*.spec.jsx", "*.test.mjs", "*.spec.mjs"},
+	"typescript": {"*.test.ts", "*.spec.ts", "*.test.tsx", "*.spec.tsx"},
+	"go":         {"*_test.go"},
+	"ruby":       {"*_spec.rb", "*_test.rb", "test_*.rb"},
+	"java":       {"Test*.java", "*Test.java", "*Tests.java", "*IT.java"},
+}
+
+// DefaultTestDirs are directory names whose files are all tests.
+var DefaultTestDirs = []string{"tests", "test", "__tests__", "spec"}
+
+// TestMatcher decides which files are tests. Patterns without a slash match
+// the file name, others the repo-relative path. Test directories without a
+// slash match any directory of that name; others match repo-relative
+// directory paths, with globs.
+type TestMatcher struct {
+	patterns []string
+	dirs     []string
+}
+
+// NewTestMatcher combines the defaults with a repo's overrides: patterns
+// replace the defaults of the languages they name, and dirs are added to
+// DefaultTestDirs.
+func NewTestMatcher(patterns map[string][]string, dirs []string) *TestMatcher {
+	m := &TestMatcher{}
+	for lang, defaults := range DefaultTestPatterns {
+		if _, overridden := patterns[lang]; !overridden {
+			m.patterns = append(m.patterns, defaults...)
+		}
+	}
+	for _, langPatterns := range patterns {
+		m.patterns = append(m.patterns, langPatterns...)
+	}
+	m.dirs = append(m.dirs, DefaultTestDirs...)
+	for _, dir := range dirs {
+		if dir = strings.Trim(dir, "/"); dir != "" {
+			m.dirs = append(m.dirs, dir)
+		}
+	}
+	return m
+}
+
+// Match reports whether the repo-relative path is a test file.
+func (m *TestMatcher) Match(filePath string) bool {
+	filePath = filepath.ToSlash(filePath)
+	name := path.Base(filePath)
+	for _, pattern := range m.patterns {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = filePath
+		}
+		if ok, _ := doublestar.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return m.inTestDir(filePath)
+}
+
+func (m *TestMatcher) inTestDir(filePath string) bool {
+	dir := path.Dir(strings.TrimPrefix(filePath, "/"))
+	if dir == "." {
+		return false
+	}
+	parts := strings.Split(dir, "/")
+	for _, testDir := range m.dirs {
+		if !strings.Contains(testDir, "/") {
+			for _, part := range parts {
+				if part == testDir {
+					return true
+				}
+			}
+			continue
+		}
+		for i := range parts {
+			if ok, _ := doublestar.Match(testDir, strings.Join(parts[:i+1], "/")); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
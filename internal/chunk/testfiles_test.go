@@ -0,0 +1,61 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestMatcherDefaults(t *testing.T) {
+	m := NewTestMatcher(nil, nil)
+
+	tests := []struct {
+		filePath string
+		isTest   bool
+	}{
+		{"spec/models/user_spec.rb", true},
+		{"app/models/user_spec.rb", true},
+		{"src/main/java/com/acme/UserService.java", false},
+		{"src/main/java/com/acme/TestUserService.java", true},
+		{"src/main/java/com/acme/UserServiceTest.java", true},
+		{"src/test/java/com/acme/Fixtures.java", true},
+		{"tests/helpers.py", true},
+		{"pkg/conftest.py", true},
+		{"web/src/__tests__/app.tsx", true},
+		{"contests/scoring.py", false},
+		{"contests/latest_results.py", false},
+		{"app/attestation.py", false},
+		{"testing/utils.py", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			assert.Equal(t, tt.isTest, m.Match(tt.filePath))
+		})
+	}
+}
+
+func TestTestMatcherOverrides(t *testing.T) {
+	m := NewTestMatcher(
+		map[string][]string{
+			"python": {"check_*.py"},        // Replaces test_*.py and friends
+			"ruby":   {},                    // No Ruby test files
+			"elixir": {"*_test.exs"},        // A language without defaults
+			"custom": {"qa/**/scenario.py"}, // Slash: matched against the whole path
+		},
+		[]string{"e2e", "services/*/checks/"},
+	)
+
+	assert.True(t, m.Match("check_users.py"))
+	assert.False(t, m.Match("test_users.py"))
+	assert.False(t, m.Match("user_spec.rb"))
+	assert.True(t, m.Match("user_test.exs"))
+	assert.True(t, m.Match("qa/login/scenario.py"))
+	assert.False(t, m.Match("scenario.py"))
+	assert.True(t, m.Match("users_test.go"), "other languages keep their defaults")
+
+	assert.True(t, m.Match("e2e/login.ts"))
+	assert.True(t, m.Match("web/e2e/flows/login.ts"))
+	assert.True(t, m.Match("services/billing/checks/invoice.py"))
+	assert.False(t, m.Match("services/billing/src/checks.py"))
+	assert.True(t, m.Match("tests/helpers.py"), "default directories stay")
+}
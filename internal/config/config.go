@@ -10,25 +10,329 @@ import (
 
 // Config holds global configuration
 type Config struct {
-	Embedding EmbeddingConfig `yaml:"embedding"`
-	Storage   StorageConfig   `yaml:"storage"`
-	Logging   LoggingConfig   `yaml:"logging"`
-	Cache     CacheConfig     `yaml:"cache"`
+	Embedding   EmbeddingConfig   `yaml:"embedding"`
+	Storage     StorageConfig     `yaml:"storage"`
+	Logging     LoggingConfig     `yaml:"logging"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Replication ReplicationConfig `yaml:"replication"`
+	Graph       GraphConfig       `yaml:"graph"`
+	MCP         MCPConfig         `yaml:"mcp"`
+	Indexing    IndexingConfig    `yaml:"indexing"`
+	Summaries   SummariesConfig   `yaml:"summaries"`
+	Search      SearchConfig      `yaml:"search"`
+	Secrets     SecretsConfig     `yaml:"secrets"`
+	Sync        SyncConfig        `yaml:"sync"`
 }
 
 type CacheConfig struct {
-	QueryTTLMinutes int `yaml:"query_ttl_minutes"` // Query cache TTL in minutes (default: 10)
+	QueryTTLMinutes int                 `yaml:"query_ttl_minutes"` // Query cache TTL in minutes (default: 10)
+	Semantic        SemanticCacheConfig `yaml:"semantic"`
+	Warm            CacheWarmConfig     `yaml:"warm"`
+}
+
+// CacheWarmConfig re-runs a repo's most frequent recent searches after the
+// daemon reindexes it, so the first searches after a sync hit the cache.
+type CacheWarmConfig struct {
+	Queries     int `yaml:"queries"`      // Top queries to re-run per sync (default: 20, 0 disables)
+	WindowHours int `yaml:"window_hours"` // Metrics window to rank queries by (default: 168)
+}
+
+// SemanticCacheConfig serves cached responses of similar queries, not only
+// identical ones.
+type SemanticCacheConfig struct {
+	Enabled    bool    `yaml:"enabled"`     // Default: false
+	Threshold  float64 `yaml:"threshold"`   // Least cosine similarity of query embeddings (default: 0.95)
+	MaxEntries int     `yaml:"max_entries"` // Queries remembered per repo, search options, and index version (default: 200)
+}
+
+// ReplicationConfig describes a standby deployment that mirrors this one.
+type ReplicationConfig struct {
+	Source          string   `yaml:"source"`           // Name of this primary, recorded as repo owner on the standby
+	QdrantURL       string   `yaml:"qdrant_url"`       // Standby Qdrant endpoint
+	Neo4jURL        string   `yaml:"neo4j_url"`        // Standby Neo4j endpoint (optional)
+	Repos           []string `yaml:"repos"`            // Repos owned by this primary
+	IntervalSeconds int      `yaml:"interval_seconds"` // Watch mode poll interval (default: 300)
+	CheckpointPath  string   `yaml:"checkpoint_path"`  // Default: ~/.local/share/code-index/replication.json
+}
+
+// SearchConfig tunes how search_code retrieves results.
+type SearchConfig struct {
+	Hybrid            bool `yaml:"hybrid"`             // Fuse keyword matches into semantic search (default: true)
+	LexicalCandidates int  `yaml:"lexical_candidates"` // Keyword matches ranked per query (default: 100)
+	RRFK              int  `yaml:"rrf_k"`              // Reciprocal rank fusion constant; higher flattens rank differences (default: 60)
+
+	DescribedBoost float64 `yaml:"described_boost"` // Multiplies the weight of code a doc site section among the candidates describes (default: 1.3; 0 or 1 disables)
+
+	Boosts        []BoostConfig       `yaml:"boosts"`
+	Classifier    ClassifierConfig    `yaml:"classifier"`
+	Strategies    StrategiesConfig    `yaml:"strategies"`
+	Weights       WeightsConfig       `yaml:"weights"`
+	Context       ContextConfig       `yaml:"context"`
+	Federation    FederationConfig    `yaml:"federation"`
+	Rerank        RerankConfig        `yaml:"rerank"`
+	SessionMemory SessionMemoryConfig `yaml:"session_memory"`
+	Feedback      FeedbackConfig      `yaml:"feedback"`
+	Experiment    ExperimentConfig    `yaml:"experiment"`
+
+	ExcludeLicenses      []string `yaml:"exclude_licenses"`       // Chunks tagged with these licenses (e.g. "GPL-3.0") never appear in search_code results
+	WithholdSecretBodies bool     `yaml:"withhold_secret_bodies"` // Return only the signature and docstring of chunks with secrets unless a tool asks for include_sensitive (default: false)
+}
+
+// BoostConfig multiplies the ranking weight of results in a repo, module,
+// or directory. A result matching every field set gets the boost; several
+// matching boosts multiply.
+type BoostConfig struct {
+	Repo   string  `yaml:"repo"`
+	Module string  `yaml:"module"` // Dotted module path, with its submodules (e.g., "fisio.core")
+	Path   string  `yaml:"path"`   // Repo-relative directory prefix (e.g., "legacy/")
+	Weight float64 `yaml:"weight"` // Above 1 boosts, below 1 downweights; entries without a positive weight are ignored
+}
+
+// ClassifierConfig picks how search_code classifies queries: by keyword
+// rules, or by the nearest centroid of embedded example queries per type.
+type ClassifierConfig struct {
+	Mode          string  `yaml:"mode"`           // rules or embedding (default: rules)
+	MinConfidence float64 `yaml:"min_confidence"` // Embedding decisions below this search as concept queries (default: 0.5)
+}
+
+// ExperimentConfig routes a share of search_code queries through another
+// strategy and tags their metrics with the variant, so `code-indexer metrics
+// --experiment` can compare it with the usual routing.
+type ExperimentConfig struct {
+	Name     string `yaml:"name"`     // Tags metrics; empty disables the experiment
+	Percent  int    `yaml:"percent"`  // Queries routed to the variant, 0-100
+	Strategy string `yaml:"strategy"` // The variant: semantic, hybrid, symbol, or pattern, as search_code's strategy
+}
+
+// FederationConfig splits searches across all repos into one search per
+// repo, fused by per-repo rank, so a large repo can't crowd out the rest.
+type FederationConfig struct {
+	Enabled  bool `yaml:"enabled"`   // Default: true
+	MaxRepos int  `yaml:"max_repos"` // Largest repos searched separately; the rest share one search (default: 16)
+	Quota    int  `yaml:"quota"`     // Results per repo (default: 0, an even share of the page)
+}
+
+// ContextConfig bounds the bundles codeindex://relevant assembles for a
+// query.
+type ContextConfig struct {
+	MaxTokens  int `yaml:"max_tokens"` // Default bundle budget; the tokens URI parameter overrides it (default: 8000)
+	Candidates int `yaml:"candidates"` // Chunks retrieved before deduplication and packing (default: 40)
+}
+
+// StrategiesConfig routes each classified query type to a retrieval
+// strategy. Fields left out of the config file keep their defaults.
+type StrategiesConfig struct {
+	Symbol       StrategyConfig `yaml:"symbol"`       // Default: symbol index, 10 results
+	Concept      StrategyConfig `yaml:"concept"`      // Default: semantic, graph depth 1, 10 results
+	Relationship StrategyConfig `yaml:"relationship"` // Default: symbol index, graph depth 1, 20 results
+	Flow         StrategyConfig `yaml:"flow"`         // Default: semantic, graph depth 3, 15 results
+	Pattern      StrategyConfig `yaml:"pattern"`      // Default: pattern index, 5 results
+}
+
+// StrategyConfig is how search_code retrieves one query type.
+type StrategyConfig struct {
+	Retrieval      string `yaml:"retrieval"`       // semantic, symbol, or pattern (unknown values search semantically)
+	GraphExpansion bool   `yaml:"graph_expansion"` // Add the results' graph neighbors (needs Neo4j)
+	GraphDepth     int    `yaml:"graph_depth"`     // Hops expanded
+	MaxResults     int    `yaml:"max_results"`     // Caps the page size (0 disables)
+}
+
+// WeightsConfig sets the retrieval weights chunks are indexed with; search
+// ranks by score times weight. Changes apply to chunks indexed afterwards,
+// so run a full reindex to retune an existing index.
+type WeightsConfig struct {
+	Test        float64 `yaml:"test"`         // Multiplier for chunks of test files (default: 0.5)
+	FileSummary float64 `yaml:"file_summary"` // File summary chunks (default: 0.7)
+	Pattern     float64 `yaml:"pattern"`      // Detected pattern chunks (default: 1.5)
+	Navigation  float64 `yaml:"navigation"`   // AGENTS.md / CLAUDE.md sections (default: 1.5)
+	Site        float64 `yaml:"site"`         // Sphinx / MkDocs page sections (default: 1.2)
+	ADR         float64 `yaml:"adr"`          // Architecture decision records; superseded, deprecated, and rejected ones get half (default: 1.6)
+}
+
+// FeedbackConfig turns search_feedback votes into retrieval weight: each
+// chunk's weight is multiplied by 1 + step * (useful - not useful votes),
+// clamped to [min_weight, max_weight].
+type FeedbackConfig struct {
+	Step      float64 `yaml:"step"`       // Weight change per net vote (default: 0.1, 0 disables)
+	MinWeight float64 `yaml:"min_weight"` // Default: 0.5
+	MaxWeight float64 `yaml:"max_weight"` // Default: 2.0
+}
+
+// SessionMemoryConfig remembers what search_code returned to each MCP
+// session, so later searches favor the files the agent is working in and
+// leave out chunks it has already been shown.
+type SessionMemoryConfig struct {
+	Enabled    bool    `yaml:"enabled"`
+	MaxQueries int     `yaml:"max_queries"` // Recent queries remembered per session (default: 20)
+	Boost      float64 `yaml:"boost"`       // Score bonus for results in files recent queries returned (default: 0.2, same module: half)
+}
+
+// RerankConfig enables a second ranking stage: a reranking model reads the
+// query with each of the top candidates and reorders them by relevance.
+type RerankConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Provider       string   `yaml:"provider"`        // voyage, cohere, or local (a cross-encoder server speaking the TEI /rerank API) (default: voyage)
+	URL            string   `yaml:"url"`             // Rerank endpoint (default: the provider's; required for local)
+	Model          string   `yaml:"model"`           // Default: rerank-2.5 (voyage), rerank-v3.5 (cohere)
+	APIKeyEnv      string   `yaml:"api_key_env"`     // Env var holding the API key (default: VOYAGE_API_KEY or COHERE_API_KEY); unset sends none
+	TopN           int      `yaml:"top_n"`           // Candidates reranked per query (default: 50)
+	QueryTypes     []string `yaml:"query_types"`     // Query types reranked (default: concept, relationship, flow, pattern)
+	TimeoutSeconds int      `yaml:"timeout_seconds"` // Per request (default: 10)
+}
+
+// IndexingConfig sets how index runs ride out embedding and storage failures.
+type IndexingConfig struct {
+	BatchRetries       int `yaml:"batch_retries"`        // Extra attempts per failed embed or upsert batch (default: 3)
+	ErrorBudgetPercent int `yaml:"error_budget_percent"` // Percent of walked files that may fail before the run aborts (default: 5, -1 disables)
+}
+
+// SummariesConfig enables index-time natural-language summaries of large
+// symbols from an OpenAI-compatible chat completions endpoint. The summary is
+// stored on the chunk and embedded with its code.
+type SummariesConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	URL            string `yaml:"url"`             // Chat completions endpoint (default: https://api.openai.com/v1/chat/completions)
+	Model          string `yaml:"model"`           // e.g. "gpt-4o-mini"
+	APIKeyEnv      string `yaml:"api_key_env"`     // Env var holding the API key (default: OPENAI_API_KEY); unset sends none
+	MinLines       int    `yaml:"min_lines"`       // Symbols at least this long are summarized (default: 40)
+	MaxConcurrent  int    `yaml:"max_concurrent"`  // Requests in flight per embed batch (default: 4)
+	TimeoutSeconds int    `yaml:"timeout_seconds"` // Per request (default: 60)
+}
+
+// SecretsConfig adds secret detection rules to the built-in patterns, e.g.
+// for internal token formats. Every repo's chunks are redacted with them.
+type SecretsConfig struct {
+	Rules        []SecretRule `yaml:"rules,omitempty"`
+	GitleaksFile string       `yaml:"gitleaks_file,omitempty"` // gitleaks TOML config whose [[rules]] and [allowlist] are added too
+}
+
+// SecretRule is one additional secret pattern.
+type SecretRule struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`          // Go regexp
+	Redact  string `yaml:"redact,omitempty"` // Replacement with $1-style group references (default: [REDACTED_<NAME>])
+}
+
+// SyncConfig configures the sync daemons (code-indexer watch and webhook).
+type SyncConfig struct {
+	Alerts SyncAlertsConfig `yaml:"alerts"`
+}
+
+// SyncAlertsConfig alerts when a repo's syncs keep failing, and backs off
+// the checks of a failing repo until it syncs again.
+type SyncAlertsConfig struct {
+	AfterFailures     int            `yaml:"after_failures"`      // Consecutive failures that fire an alert (default: 3, 0 disables alerts)
+	MaxBackoffMinutes int            `yaml:"max_backoff_minutes"` // Longest wait between checks of a failing repo (default: 60, 0 disables backoff)
+	Webhooks          []AlertWebhook `yaml:"webhooks"`
+}
+
+// AlertWebhook is an endpoint sync alerts are posted to.
+type AlertWebhook struct {
+	URL    string `yaml:"url"`
+	URLEnv string `yaml:"url_env"` // Env var holding the URL instead, as Slack webhook URLs are secrets
+	Format string `yaml:"format"`  // slack (incoming webhook) or json (default: json)
+}
+
+type GraphConfig struct {
+	HistoryVersions int `yaml:"history_versions"` // Edge snapshots kept per repo for diffing (default: 10, 0 disables)
+}
+
+// MCPConfig bounds how much work one MCP client can request. Zero disables a limit.
+type MCPConfig struct {
+	RateLimitPerMinute int `yaml:"rate_limit_per_minute"` // tools/call + resources/read per session (default: 120)
+	RateLimitBurst     int `yaml:"rate_limit_burst"`      // Requests allowed at once above the rate (default: 20)
+	MaxArgumentBytes   int `yaml:"max_argument_bytes"`    // Largest tool arguments payload (default: 65536)
+	MaxConcurrent      int `yaml:"max_concurrent"`        // Tool calls running at once (default: 8)
+
+	HTTP  MCPHTTPConfig  `yaml:"http"`
+	Audit MCPAuditConfig `yaml:"audit"`
+}
+
+// MCPAuditConfig records every tool call (caller, tool, arguments hash,
+// result size, duration) in an audit JSONL file separate from the metrics log.
+type MCPAuditConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Path          string `yaml:"path"`           // Default: ~/.local/share/code-index/audit.jsonl; its arguments HMAC key is <path>.key
+	RetentionDays int    `yaml:"retention_days"` // Older entries are dropped (default: 90, 0 keeps all)
+}
+
+// MCPHTTPConfig authenticates the HTTP transport. Serving over HTTP requires
+// at least one token or an introspection endpoint.
+type MCPHTTPConfig struct {
+	Tokens        []MCPToken             `yaml:"tokens"`
+	Introspection MCPIntrospectionConfig `yaml:"introspection"`
+}
+
+// MCPToken is a static bearer token, stored as its SHA-256 hash.
+type MCPToken struct {
+	Name    string              `yaml:"name"`    // Caller identity, used in logs and to bind sessions
+	SHA256  string              `yaml:"sha256"`  // Hex SHA-256 of the token (code-index-mcp hash-token)
+	Repos   []string            `yaml:"repos"`   // Readable repos; empty allows all
+	Modules map[string][]string `yaml:"modules"` // Repo → its only readable top-level modules; other repos are readable whole
+}
+
+// MCPIntrospectionConfig verifies OAuth access tokens via RFC 7662. Tokens
+// need "repo:<name>" scopes ("repo:*" for all) or "module:<repo>/<module>"
+// scopes for part of a repo.
+type MCPIntrospectionConfig struct {
+	URL             string `yaml:"url"`
+	ClientID        string `yaml:"client_id"`
+	ClientSecretEnv string `yaml:"client_secret_env"` // Env var holding the client secret
 }
 
 type EmbeddingConfig struct {
-	Provider string `yaml:"provider"` // "voyage"
-	Model    string `yaml:"model"`    // "voyage-4-large"
+	Provider string             `yaml:"provider"` // "voyage"
+	Model    string             `yaml:"model"`    // "voyage-4-large"
+	Pricing  map[string]float64 `yaml:"pricing"`  // Price per million tokens by model, for `metrics --cost`; unpriced models show tokens only
 }
 
 type StorageConfig struct {
-	QdrantURL string `yaml:"qdrant_url"`
-	Neo4jURL  string `yaml:"neo4j_url"`
-	RedisURL  string `yaml:"redis_url"`
+	QdrantURL string      `yaml:"qdrant_url"`
+	Neo4jURL  string      `yaml:"neo4j_url"`
+	RedisURL  string      `yaml:"redis_url"` // Standalone Redis
+	Redis     RedisConfig `yaml:"redis"`
+}
+
+// RedisEnabled reports whether a Redis deployment is configured: a URL in
+// standalone mode, or the nodes of a cluster or the sentinels.
+func (s StorageConfig) RedisEnabled() bool {
+	switch s.Redis.Mode {
+	case RedisCluster, RedisSentinel:
+		return len(s.Redis.Addrs) > 0
+	default:
+		return s.RedisURL != ""
+	}
+}
+
+// Redis deployment modes (storage.redis.mode).
+const (
+	RedisStandalone = "standalone"
+	RedisCluster    = "cluster"
+	RedisSentinel   = "sentinel"
+)
+
+// RedisConfig sets up production Redis deployments. Settings here override
+// those in storage.redis_url.
+type RedisConfig struct {
+	Mode             string         `yaml:"mode"`              // standalone (default; storage.redis_url), cluster, or sentinel
+	Addrs            []string       `yaml:"addrs"`             // host:port of cluster nodes or sentinels
+	MasterName       string         `yaml:"master_name"`       // Sentinel: the monitored master
+	Username         string         `yaml:"username"`          // ACL user
+	Password         string         `yaml:"password"`          // Prefer CODE_INDEX_STORAGE_REDIS_PASSWORD
+	SentinelPassword string         `yaml:"sentinel_password"` // Sentinel: the sentinels' own password
+	DB               int            `yaml:"db"`                // Not in cluster mode, which only has db 0
+	TLS              RedisTLSConfig `yaml:"tls"`
+}
+
+// RedisTLSConfig enables TLS to Redis, optionally with a private CA and a
+// client certificate.
+type RedisTLSConfig struct {
+	Enabled            bool   `yaml:"enabled"`
+	CAFile             string `yaml:"ca_file"`              // PEM CA bundle; default: system roots
+	CertFile           string `yaml:"cert_file"`            // Client certificate (mutual TLS)
+	KeyFile            string `yaml:"key_file"`             // Client key
+	ServerName         string `yaml:"server_name"`          // Default: the host dialed
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"` // Testing only
 }
 
 type LoggingConfig struct {
@@ -41,14 +345,80 @@ type LoggingConfig struct {
 type RepoConfig struct {
 	Name          string            `yaml:"name"`
 	DefaultBranch string            `yaml:"default_branch"`
-	Modules       map[string]Module `yaml:"modules"`
+	Modules       map[string]Module `yaml:"modules,omitempty"`
 	Include       []string          `yaml:"include"`
 	Exclude       []string          `yaml:"exclude"`
+
+	MaxFileKB        int  `yaml:"max_file_kb,omitempty"`         // Skip larger files (default: 512, -1 disables)
+	MaxAvgLineLength int  `yaml:"max_avg_line_length,omitempty"` // Skip files with longer average lines, i.e. minified (default: 300, -1 disables)
+	FollowSymlinks   bool `yaml:"follow_symlinks,omitempty"`     // Enter symlinked directories and index symlinked files (default: skip links)
+	DedupChunks      bool `yaml:"dedup_chunks,omitempty"`        // Store identical chunks once, listing the other locations on it
+
+	Hooks HooksConfig    `yaml:"hooks,omitempty"`
+	Tests TestsConfig    `yaml:"tests,omitempty"`
+	Sync  RepoSyncConfig `yaml:"sync,omitempty"`
+
+	Secrets RepoSecretsConfig `yaml:"secrets,omitempty"`
+	License string            `yaml:"license,omitempty"` // License of files without a license header or LICENSE file of their own, e.g. "proprietary"
+
+	// Roots split a monorepo into independent projects. When set, only files
+	// under a root are indexed, each with that root's rules; Include is the
+	// default for roots without their own and Exclude applies everywhere.
+	Roots []RootConfig `yaml:"roots,omitempty"`
+}
+
+// RootConfig is one project directory of a monorepo.
+type RootConfig struct {
+	Path         string   `yaml:"path"`                    // Repo-relative directory, e.g. "frontend"
+	Include      []string `yaml:"include,omitempty"`       // Relative to Path
+	Exclude      []string `yaml:"exclude,omitempty"`       // Relative to Path
+	Languages    []string `yaml:"languages,omitempty"`     // python, javascript, typescript; empty allows any
+	ModulePrefix string   `yaml:"module_prefix,omitempty"` // Module paths become prefix + path below the root (default: from the repo root)
+}
+
+// TestsConfig decides which files are tests, whose chunks rank lower.
+type TestsConfig struct {
+	Patterns map[string][]string `yaml:"patterns,omitempty"` // File name globs by language (python, go, ruby, java, ...), replacing that language's defaults
+	Dirs     []string            `yaml:"dirs,omitempty"`     // Directories whose files are all tests, besides tests, test, __tests__, and spec
+}
+
+// RepoSecretsConfig decides what happens to code containing secrets, and
+// allowlists known fake credentials, e.g. in test fixtures, so they are
+// neither flagged nor redacted.
+type RepoSecretsConfig struct {
+	Policy         string   `yaml:"policy,omitempty"`          // redact, skip_chunk, or skip_file (default: redact)
+	Allowlist      []string `yaml:"allowlist,omitempty"`       // Regexps; detected secrets containing a match are ignored
+	AllowlistPaths []string `yaml:"allowlist_paths,omitempty"` // Globs of files whose secrets are ignored
+}
+
+// UnmarshalYAML also takes the policy alone, as in "secrets: skip_file".
+func (c *RepoSecretsConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.Policy = node.Value
+		return nil
+	}
+	type plain RepoSecretsConfig
+	return node.Decode((*plain)(c))
+}
+
+// RepoSyncConfig sets when the sync daemon checks the repo for changes,
+// instead of the daemon's --interval. Schedule wins over Interval.
+type RepoSyncConfig struct {
+	Interval string `yaml:"interval,omitempty"` // Go duration, e.g. "5m"
+	Schedule string `yaml:"schedule,omitempty"` // Five-field cron in local time, e.g. "0 */2 * * 1-5", or @hourly/@daily/@weekly
+}
+
+// HooksConfig lists shell commands run in the repo root around an index run.
+// Each gets the run summary as JSON on stdin.
+type HooksConfig struct {
+	PreIndex       []string `yaml:"pre_index,omitempty"`       // Before walking, e.g. code generation; a failure aborts the run
+	PostIndex      []string `yaml:"post_index,omitempty"`      // After the run, failed or not, e.g. notifications; failures are logged
+	TimeoutSeconds int      `yaml:"timeout_seconds,omitempty"` // Per command (default: 300)
 }
 
 type Module struct {
 	Description string            `yaml:"description"`
-	Submodules  map[string]string `yaml:"submodules"`
+	Submodules  map[string]string `yaml:"submodules,omitempty"`
 }
 
 // DefaultConfig returns sensible defaults
@@ -70,26 +440,116 @@ func DefaultConfig() *Config {
 		},
 		Cache: CacheConfig{
 			QueryTTLMinutes: 10,
+			Semantic: SemanticCacheConfig{
+				Threshold:  0.95,
+				MaxEntries: 200,
+			},
+			Warm: CacheWarmConfig{
+				Queries:     20,
+				WindowHours: 168,
+			},
+		},
+		Replication: ReplicationConfig{
+			IntervalSeconds: 300,
+		},
+		Graph: GraphConfig{
+			HistoryVersions: 10,
+		},
+		Sync: SyncConfig{
+			Alerts: SyncAlertsConfig{
+				AfterFailures:     3,
+				MaxBackoffMinutes: 60,
+			},
+		},
+		MCP: MCPConfig{
+			RateLimitPerMinute: 120,
+			RateLimitBurst:     20,
+			MaxArgumentBytes:   64 * 1024,
+			MaxConcurrent:      8,
+			Audit: MCPAuditConfig{
+				RetentionDays: 90,
+			},
+		},
+		Indexing: IndexingConfig{
+			BatchRetries:       3,
+			ErrorBudgetPercent: 5,
+		},
+		Summaries: SummariesConfig{
+			URL:            "https://api.openai.com/v1/chat/completions",
+			APIKeyEnv:      "OPENAI_API_KEY",
+			MinLines:       40,
+			MaxConcurrent:  4,
+			TimeoutSeconds: 60,
+		},
+		Search: SearchConfig{
+			Hybrid:            true,
+			LexicalCandidates: 100,
+			RRFK:              60,
+			DescribedBoost:    1.3,
+			Classifier: ClassifierConfig{
+				Mode:          "rules",
+				MinConfidence: 0.5,
+			},
+			Strategies: StrategiesConfig{
+				Symbol:       StrategyConfig{Retrieval: "symbol", MaxResults: 10},
+				Concept:      StrategyConfig{Retrieval: "semantic", GraphExpansion: true, GraphDepth: 1, MaxResults: 10},
+				Relationship: StrategyConfig{Retrieval: "symbol", GraphExpansion: true, GraphDepth: 1, MaxResults: 20},
+				Flow:         StrategyConfig{Retrieval: "semantic", GraphExpansion: true, GraphDepth: 3, MaxResults: 15},
+				Pattern:      StrategyConfig{Retrieval: "pattern", MaxResults: 5},
+			},
+			Weights: WeightsConfig{
+				Test:        0.5,
+				FileSummary: 0.7,
+				Pattern:     1.5,
+				Navigation:  1.5,
+				Site:        1.2,
+				ADR:         1.6,
+			},
+			Federation: FederationConfig{
+				Enabled:  true,
+				MaxRepos: 16,
+			},
+			Context: ContextConfig{
+				MaxTokens:  8000,
+				Candidates: 40,
+			},
+			Rerank: RerankConfig{
+				Provider:       "voyage",
+				TopN:           50,
+				QueryTypes:     []string{"concept", "relationship", "flow", "pattern"},
+				TimeoutSeconds: 10,
+			},
+			SessionMemory: SessionMemoryConfig{
+				MaxQueries: 20,
+				Boost:      0.2,
+			},
+			Feedback: FeedbackConfig{
+				Step:      0.1,
+				MinWeight: 0.5,
+				MaxWeight: 2.0,
+			},
 		},
 	}
 }
 
-// LoadConfig loads config from file or returns defaults
+// LoadConfig loads config from file or returns defaults, then applies
+// CODE_INDEX_* environment overrides (see ApplyEnv).
 func LoadConfig(path string) (*Config, error) {
 	cfg := DefaultConfig()
 
 	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return cfg, nil // Use defaults
-		}
+	if err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, err
+		}
+	}
 
-	if err := yaml.Unmarshal(data, cfg); err != nil {
+	if err := ApplyEnv(cfg); err != nil {
 		return nil, err
 	}
-
 	return cfg, nil
 }
 
@@ -112,3 +572,18 @@ func LoadRepoConfig(repoPath string) (*RepoConfig, error) {
 
 	return &wrapper.CodeIndex, nil
 }
+
+// SaveRepoConfig writes .ai-devtools.yaml to the repo root, nested under the
+// code-index key LoadRepoConfig reads. It returns the file's path.
+func SaveRepoConfig(repoPath string, cfg *RepoConfig) (string, error) {
+	data, err := yaml.Marshal(map[string]*RepoConfig{"code-index": cfg})
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(repoPath, ".ai-devtools.yaml")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisEnabled(t *testing.T) {
+	assert.True(t, StorageConfig{RedisURL: "redis://localhost:6379"}.RedisEnabled())
+	assert.False(t, StorageConfig{}.RedisEnabled())
+	assert.False(t, StorageConfig{RedisURL: "redis://x", Redis: RedisConfig{Mode: RedisCluster}}.RedisEnabled())
+	assert.True(t, StorageConfig{Redis: RedisConfig{Mode: RedisSentinel, Addrs: []string{"s1:26379"}}}.RedisEnabled())
+}
+
+func TestRepoSecretsConfig(t *testing.T) {
+	repo := t.TempDir()
+	write := func(content string) *RepoConfig {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(repo, ".ai-devtools.yaml"), []byte(content), 0644))
+		cfg, err := LoadRepoConfig(repo)
+		require.NoError(t, err)
+		return cfg
+	}
+
+	cfg := write("code-index:\n  name: r3\n  secrets: skip_file\n")
+	assert.Equal(t, RepoSecretsConfig{Policy: "skip_file"}, cfg.Secrets)
+
+	cfg = write("code-index:\n  name: r3\n  secrets:\n    policy: skip_chunk\n    allowlist_paths: [\"tests/fixtures/**\"]\n")
+	assert.Equal(t, RepoSecretsConfig{Policy: "skip_chunk", AllowlistPaths: []string{"tests/fixtures/**"}}, cfg.Secrets)
+}
@@ -0,0 +1,141 @@
+// internal/config/env.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts every environment variable that overrides a config field.
+const EnvPrefix = "CODE_INDEX_"
+
+// ConfigPathEnv names the global config file when no --config flag is given.
+const ConfigPathEnv = EnvPrefix + "CONFIG"
+
+// DefaultConfigPath is ~/.config/code-index/config.yaml, or
+// .code-index-config.yaml in the working directory without a home directory.
+func DefaultConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".code-index-config.yaml"
+	}
+	return filepath.Join(homeDir, ".config", "code-index", "config.yaml")
+}
+
+// ConfigPath picks the global config file: the --config flag value if set,
+// then $CODE_INDEX_CONFIG, then DefaultConfigPath.
+func ConfigPath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if path := os.Getenv(ConfigPathEnv); path != "" {
+		return path
+	}
+	return DefaultConfigPath()
+}
+
+// ApplyEnv overrides config fields from CODE_INDEX_* environment variables.
+// A field's variable is its YAML path upper-cased and joined with
+// underscores: storage.qdrant_url is CODE_INDEX_STORAGE_QDRANT_URL. String
+// lists are comma-separated; lists of objects (mcp.http.tokens) are YAML or
+// JSON.
+func ApplyEnv(cfg *Config) error {
+	return applyEnv(reflect.ValueOf(cfg).Elem(), EnvPrefix)
+}
+
+// EnvNames lists every override variable ApplyEnv reads, in field order.
+func EnvNames() []string {
+	var names []string
+	walkEnvFields(reflect.ValueOf(&Config{}).Elem(), EnvPrefix, func(name string, _ reflect.Value) error {
+		names = append(names, name)
+		return nil
+	})
+	return names
+}
+
+func applyEnv(v reflect.Value, prefix string) error {
+	return walkEnvFields(v, prefix, func(name string, field reflect.Value) error {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+		if err := setFromEnv(field, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// walkEnvFields calls fn with the variable name of every non-struct field,
+// descending into nested config sections.
+func walkEnvFields(v reflect.Value, prefix string, fn func(name string, field reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := prefix + strings.ToUpper(tag)
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := walkEnvFields(field, name+"_", fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(name, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setFromEnv(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.String {
+			items := []string{}
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			field.Set(reflect.ValueOf(items))
+			return nil
+		}
+		fallthrough
+	default:
+		fresh := reflect.New(field.Type())
+		if err := yaml.Unmarshal([]byte(value), fresh.Interface()); err != nil {
+			return fmt.Errorf("invalid value: %w", err)
+		}
+		field.Set(fresh.Elem())
+	}
+	return nil
+}
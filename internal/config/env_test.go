@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigEnvOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("storage:\n  qdrant_url: http://file:6333\n  neo4j_url: bolt://file:7687\n"), 0644))
+
+	t.Setenv("CODE_INDEX_STORAGE_QDRANT_URL", "http://qdrant:6333")
+	t.Setenv("CODE_INDEX_EMBEDDING_MODEL", "voyage-code-3")
+	t.Setenv("CODE_INDEX_CACHE_QUERY_TTL_MINUTES", "30")
+	t.Setenv("CODE_INDEX_REPLICATION_REPOS", "r3, fisio,")
+	t.Setenv("CODE_INDEX_MCP_HTTP_TOKENS", `[{"name": "ci", "sha256": "abc", "repos": ["r3"]}]`)
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://qdrant:6333", cfg.Storage.QdrantURL, "env beats the file")
+	assert.Equal(t, "bolt://file:7687", cfg.Storage.Neo4jURL, "unset vars keep the file value")
+	assert.Equal(t, "redis://localhost:6379", cfg.Storage.RedisURL, "and the default")
+	assert.Equal(t, "voyage-code-3", cfg.Embedding.Model)
+	assert.Equal(t, 30, cfg.Cache.QueryTTLMinutes)
+	assert.Equal(t, []string{"r3", "fisio"}, cfg.Replication.Repos)
+	assert.Equal(t, []MCPToken{{Name: "ci", SHA256: "abc", Repos: []string{"r3"}}}, cfg.MCP.HTTP.Tokens)
+}
+
+func TestLoadConfigEnvWithoutFile(t *testing.T) {
+	t.Setenv("CODE_INDEX_STORAGE_NEO4J_URL", "")
+
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Storage.Neo4jURL, "an empty value disables an optional backend")
+}
+
+func TestLoadConfigEnvInvalid(t *testing.T) {
+	t.Setenv("CODE_INDEX_MCP_MAX_CONCURRENT", "lots")
+
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "CODE_INDEX_MCP_MAX_CONCURRENT")
+}
+
+func TestEnvNames(t *testing.T) {
+	names := EnvNames()
+
+	assert.Contains(t, names, "CODE_INDEX_STORAGE_QDRANT_URL")
+	assert.Contains(t, names, "CODE_INDEX_MCP_HTTP_INTROSPECTION_CLIENT_SECRET_ENV")
+	assert.NotContains(t, names, "CODE_INDEX_STORAGE", "sections aren't variables")
+}
+
+func TestConfigPath(t *testing.T) {
+	t.Setenv(ConfigPathEnv, "")
+	assert.Equal(t, DefaultConfigPath(), ConfigPath(""))
+
+	t.Setenv(ConfigPathEnv, "/etc/code-index.yaml")
+	assert.Equal(t, "/etc/code-index.yaml", ConfigPath(""))
+	assert.Equal(t, "/tmp/flag.yaml", ConfigPath("/tmp/flag.yaml"), "the flag wins")
+}
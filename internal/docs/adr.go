@@ -0,0 +1,271 @@
+package docs
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+)
+
+// KindADR is the chunk kind of architecture decision records.
+const KindADR = "adr"
+
+// Metadata keys of ADR chunks.
+const (
+	MetaADRNumber = "adr_number"
+	MetaADRStatus = "adr_status"
+	MetaADRDate   = "adr_date"
+)
+
+// adrFileRe matches numbered records in the directories ADR tooling
+// (adr-tools, log4brains, MADR) creates, e.g. docs/adr/0007-use-kafka.md.
+var adrFileRe = regexp.MustCompile(`(?:^|/)(?:adr|adrs|decisions)/(\d{3,5})-[^/]+\.md$`)
+
+// IsADR reports whether the repo-relative relPath is an architecture
+// decision record: a numbered Markdown file in an adr, adrs, or decisions
+// directory.
+func IsADR(relPath string) bool {
+	return adrFileRe.MatchString(relPath)
+}
+
+// ADR is a parsed architecture decision record.
+type ADR struct {
+	Path   string
+	Repo   string
+	Number string // From the file name, e.g. "0007"
+	Title  string
+
+	// Status is the first word of StatusText, lowercased ("accepted",
+	// "superseded"); StatusText is the status as written.
+	Status     string
+	StatusText string
+	Date       string
+
+	Summary      string // Text between the title and the first section
+	Context      string
+	Decision     string
+	Consequences string
+	Other        []Section // Sections besides the three above, in order
+	EndLine      int
+}
+
+// adrInactive are the statuses of decisions no longer in force.
+var adrInactive = map[string]bool{
+	"superseded": true,
+	"deprecated": true,
+	"rejected":   true,
+	"abandoned":  true,
+}
+
+// Active reports whether the decision is still in force, or not known to
+// be otherwise.
+func (a *ADR) Active() bool {
+	return !adrInactive[a.Status]
+}
+
+var adrTitleNumberRe = regexp.MustCompile(`^(?:ADR[-\s]?)?\d+[.:]?\s+`)
+
+// ParseADR parses an ADR in the Nygard (## Status / ## Context / ## Decision
+// / ## Consequences) or MADR layout, taking the status and date from a
+// Status section or a "Status:" line, bulleted or in front matter.
+func ParseADR(content []byte, filePath, repo string) *ADR {
+	lines := strings.Split(string(content), "\n")
+	adr := &ADR{Path: filePath, Repo: repo, EndLine: len(lines)}
+	if m := adrFileRe.FindStringSubmatch(filePath); m != nil {
+		adr.Number = m[1]
+	}
+
+	var current *Section
+	var summary strings.Builder
+	inFrontMatter := false
+	flush := func() {
+		if current == nil {
+			return
+		}
+		body := strings.TrimSpace(current.Content)
+		switch adrSectionName(current.Heading) {
+		case "status":
+			if adr.StatusText == "" {
+				adr.StatusText = firstLine(body)
+			}
+		case "context":
+			adr.Context = joinParagraphs(adr.Context, body)
+		case "decision":
+			adr.Decision = joinParagraphs(adr.Decision, body)
+		case "consequences":
+			adr.Consequences = joinParagraphs(adr.Consequences, body)
+		default:
+			if body != "" {
+				current.Content = body
+				adr.Other = append(adr.Other, *current)
+			}
+		}
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "---" && (i == 0 || inFrontMatter) {
+			inFrontMatter = i == 0
+			continue
+		}
+		if m := mdHeadingRe.FindStringSubmatch(line); m != nil {
+			level := len(m[1])
+			if level == 1 && adr.Title == "" {
+				adr.Title = adrTitleNumberRe.ReplaceAllString(m[2], "")
+				continue
+			}
+			if level == 2 {
+				flush()
+				current = &Section{Heading: m[2], HeadingPath: m[2], Level: level, StartLine: i + 1}
+				continue
+			}
+		}
+
+		// Header fields: front matter, or "* Status: accepted" lines
+		if key, value, ok := adrField(line); ok && (current == nil || adrSectionName(current.Heading) == "status") {
+			switch key {
+			case "status":
+				if adr.StatusText == "" {
+					adr.StatusText = value
+				}
+				continue
+			case "date":
+				if adr.Date == "" {
+					adr.Date = value
+				}
+				continue
+			case "title":
+				if adr.Title == "" {
+					adr.Title = value
+				}
+				continue
+			}
+		}
+		if current != nil {
+			current.Content += line + "\n"
+		} else if !inFrontMatter {
+			summary.WriteString(line + "\n")
+		}
+	}
+	flush()
+	adr.Summary = strings.TrimSpace(summary.String())
+
+	if adr.Title == "" {
+		adr.Title = strings.TrimSuffix(path.Base(filePath), ".md")
+	}
+	adr.StatusText = strings.Trim(adr.StatusText, "*_ ")
+	if fields := strings.Fields(adr.StatusText); len(fields) > 0 {
+		adr.Status = strings.ToLower(strings.Trim(fields[0], ".,;:*_[]"))
+	}
+	return adr
+}
+
+// adrSectionName maps a section heading to the part of the record it
+// holds, covering the headings MADR uses for them.
+func adrSectionName(heading string) string {
+	h := strings.ToLower(strings.TrimSpace(heading))
+	switch {
+	case h == "status":
+		return "status"
+	case strings.HasPrefix(h, "context"):
+		return "context"
+	case h == "decision" || h == "decision outcome":
+		return "decision"
+	case strings.HasSuffix(h, "consequences"):
+		return "consequences"
+	}
+	return ""
+}
+
+// adrField parses a "key: value" header line, allowing a bullet and bold
+// markers ("* **Status**: Accepted").
+func adrField(line string) (key, value string, ok bool) {
+	line = strings.TrimLeft(strings.TrimSpace(line), "-*+ ")
+	key, value, ok = strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+	key = strings.ToLower(strings.Trim(key, "*_ "))
+	value = strings.Trim(strings.TrimSpace(value), "*_ \"'")
+	if strings.Contains(key, " ") || value == "" {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
+
+func joinParagraphs(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + "\n\n" + b
+}
+
+// Heading names the record as search results show it, e.g.
+// "ADR 0007: Use Kafka for domain events".
+func (a *ADR) Heading() string {
+	if a.Number == "" {
+		return a.Title
+	}
+	return "ADR " + a.Number + ": " + a.Title
+}
+
+// ToChunk converts the record to one doc chunk of kind "adr", its status,
+// context, decision, and consequences laid out in that order so the
+// rationale embeds together.
+func (a *ADR) ToChunk() chunk.Chunk {
+	var b strings.Builder
+	b.WriteString(a.Heading() + "\n")
+	if a.StatusText != "" {
+		b.WriteString("Status: " + a.StatusText + "\n")
+	}
+	if a.Date != "" {
+		b.WriteString("Date: " + a.Date + "\n")
+	}
+	if a.Summary != "" {
+		b.WriteString("\n" + a.Summary + "\n")
+	}
+	for _, part := range []struct{ heading, body string }{
+		{"Context", a.Context},
+		{"Decision", a.Decision},
+		{"Consequences", a.Consequences},
+	} {
+		if part.body != "" {
+			b.WriteString("\n## " + part.heading + "\n\n" + part.body + "\n")
+		}
+	}
+	for _, s := range a.Other {
+		b.WriteString("\n## " + s.Heading + "\n\n" + s.Content + "\n")
+	}
+
+	c := chunk.Chunk{
+		Repo:            a.Repo,
+		FilePath:        a.Path,
+		StartLine:       1,
+		EndLine:         a.EndLine,
+		Type:            chunk.ChunkTypeDoc,
+		Kind:            KindADR,
+		HeadingPath:     a.Heading(),
+		Content:         b.String(),
+		RetrievalWeight: 1.6,
+		Metadata:        map[string]string{},
+	}
+	if a.Number != "" {
+		c.Metadata[MetaADRNumber] = a.Number
+	}
+	if a.Status != "" {
+		c.Metadata[MetaADRStatus] = a.Status
+	}
+	if a.Date != "" {
+		c.Metadata[MetaADRDate] = a.Date
+	}
+	c.ID = chunk.GenerateID(a.Repo, a.Path, KindADR, 1)
+	return c
+}
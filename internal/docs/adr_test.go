@@ -0,0 +1,114 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsADR(t *testing.T) {
+	assert.True(t, IsADR("docs/adr/0007-use-kafka.md"))
+	assert.True(t, IsADR("doc/architecture/decisions/0012-split-billing.md"))
+	assert.True(t, IsADR("adr/001-record-decisions.md"))
+	assert.False(t, IsADR("docs/adr/README.md"))
+	assert.False(t, IsADR("docs/adr/template.md"))
+	assert.False(t, IsADR("docs/0007-use-kafka.md"))
+	assert.False(t, IsADR("docs/adr/0007-use-kafka.rst"))
+}
+
+func TestParseADRNygard(t *testing.T) {
+	content := `# 7. Use Kafka for domain events
+
+Date: 2025-03-14
+
+## Status
+
+Superseded by [ADR 0012](0012-use-nats.md)
+
+## Context
+
+Services poll each other's databases for changes.
+
+## Decision
+
+We will publish domain events to Kafka.
+
+## Consequences
+
+Consumers must be idempotent.
+`
+	adr := ParseADR([]byte(content), "docs/adr/0007-use-kafka.md", "app")
+	assert.Equal(t, "0007", adr.Number)
+	assert.Equal(t, "Use Kafka for domain events", adr.Title)
+	assert.Equal(t, "2025-03-14", adr.Date)
+	assert.Equal(t, "superseded", adr.Status)
+	assert.Equal(t, "Superseded by [ADR 0012](0012-use-nats.md)", adr.StatusText)
+	assert.False(t, adr.Active())
+	assert.Equal(t, "Services poll each other's databases for changes.", adr.Context)
+	assert.Equal(t, "We will publish domain events to Kafka.", adr.Decision)
+	assert.Equal(t, "Consumers must be idempotent.", adr.Consequences)
+	assert.Empty(t, adr.Summary)
+	assert.Empty(t, adr.Other)
+}
+
+func TestParseADRMADR(t *testing.T) {
+	content := `---
+status: accepted
+date: 2025-06-01
+---
+# Use PostgreSQL for the ledger
+
+Ledger writes need transactions across accounts.
+
+## Context and Problem Statement
+
+Balances drift under concurrent transfers.
+
+## Considered Options
+
+* PostgreSQL
+* DynamoDB
+
+## Decision Outcome
+
+Chosen option: PostgreSQL, because it gives serializable transactions.
+
+### Positive Consequences
+
+* No drift.
+`
+	adr := ParseADR([]byte(content), "docs/decisions/0003-ledger-store.md", "app")
+	assert.Equal(t, "0003", adr.Number)
+	assert.Equal(t, "Use PostgreSQL for the ledger", adr.Title)
+	assert.Equal(t, "accepted", adr.Status)
+	assert.Equal(t, "2025-06-01", adr.Date)
+	assert.True(t, adr.Active())
+	assert.Equal(t, "Ledger writes need transactions across accounts.", adr.Summary)
+	assert.Equal(t, "Balances drift under concurrent transfers.", adr.Context)
+	assert.Contains(t, adr.Decision, "Chosen option: PostgreSQL")
+	assert.Contains(t, adr.Decision, "### Positive Consequences", "subsections stay with their section")
+	require.Len(t, adr.Other, 1)
+	assert.Equal(t, "Considered Options", adr.Other[0].Heading)
+}
+
+func TestParseADRBulletedStatus(t *testing.T) {
+	content := "# ADR-0002: Record decisions\n\n* **Status**: Proposed\n* Deciders: platform team\n\n## Decision\n\nWe keep ADRs in docs/adr.\n"
+	adr := ParseADR([]byte(content), "docs/adr/0002-record-decisions.md", "app")
+	assert.Equal(t, "Record decisions", adr.Title)
+	assert.Equal(t, "proposed", adr.Status)
+	assert.Equal(t, "* Deciders: platform team", adr.Summary)
+}
+
+func TestADRToChunk(t *testing.T) {
+	content := "# 7. Use Kafka\n\n## Status\n\nAccepted\n\n## Decision\n\nPublish events to Kafka.\n\n## Context\n\nPolling is slow.\n"
+	c := ParseADR([]byte(content), "docs/adr/0007-use-kafka.md", "app").ToChunk()
+
+	assert.Equal(t, KindADR, c.Kind)
+	assert.Equal(t, "ADR 0007: Use Kafka", c.HeadingPath)
+	assert.Equal(t, 1, c.StartLine)
+	assert.NotEmpty(t, c.ID)
+	assert.Equal(t, map[string]string{MetaADRNumber: "0007", MetaADRStatus: "accepted"}, c.Metadata)
+	assert.Equal(t, "ADR 0007: Use Kafka\nStatus: Accepted\n\n## Context\n\nPolling is slow.\n\n## Decision\n\nPublish events to Kafka.\n", c.Content,
+		"context comes before the decision whatever the file's order")
+}
@@ -0,0 +1,432 @@
+package docs
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+)
+
+// Doc site generators DetectSite recognizes.
+const (
+	SiteMkDocs = "mkdocs"
+	SiteSphinx = "sphinx"
+)
+
+// Metadata keys of site chunks listing what a section describes, as
+// comma-separated dotted targets and repo-relative file paths.
+const (
+	MetaDescribesSymbols = "describes_symbols"
+	MetaDescribesModules = "describes_modules"
+	MetaDescribesFiles   = "describes_files"
+)
+
+// Site is a documentation site built from a directory of the repo.
+type Site struct {
+	Generator string // SiteMkDocs or SiteSphinx
+	Dir       string // Repo-relative source directory of the pages
+}
+
+// sphinxDirs are where Sphinx projects keep conf.py, in lookup order.
+var sphinxDirs = []string{"docs", "doc", "docs/source", "doc/source"}
+
+// DetectSite returns the doc site of the repo at repoPath: an mkdocs.yml at
+// the root, or a Sphinx conf.py in one of the usual docs directories. It
+// returns nil for repos without either.
+func DetectSite(repoPath string) *Site {
+	for _, name := range []string{"mkdocs.yml", "mkdocs.yaml"} {
+		if _, err := os.Stat(filepath.Join(repoPath, name)); err == nil {
+			return &Site{Generator: SiteMkDocs, Dir: mkdocsDocsDir(filepath.Join(repoPath, name))}
+		}
+	}
+	for _, dir := range sphinxDirs {
+		if _, err := os.Stat(filepath.Join(repoPath, dir, "conf.py")); err == nil {
+			return &Site{Generator: SiteSphinx, Dir: dir}
+		}
+	}
+	return nil
+}
+
+// mkdocsDocsDir reads docs_dir from an mkdocs config. The config may use
+// custom YAML tags (!!python/name), so it is scanned rather than decoded.
+func mkdocsDocsDir(configPath string) string {
+	f, err := os.Open(configPath)
+	if err != nil {
+		return "docs"
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || key != "docs_dir" {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if value != "" {
+			return filepath.ToSlash(filepath.Clean(value))
+		}
+	}
+	return "docs"
+}
+
+// IsPage reports whether relPath is a source page of the site.
+func (s *Site) IsPage(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	if !strings.HasPrefix(relPath, s.Dir+"/") {
+		return false
+	}
+	switch path.Ext(relPath) {
+	case ".md":
+		return true
+	case ".rst":
+		return s.Generator == SiteSphinx
+	}
+	return false
+}
+
+// SitePage is a parsed page of a doc site.
+type SitePage struct {
+	Path     string
+	Repo     string
+	Sections []SiteSection
+}
+
+// SiteSection is a section of a site page with the code it references.
+type SiteSection struct {
+	Section
+	Symbols []string // Dotted targets of :func: roles, autodoc directives, and ::: blocks
+	Modules []string // Dotted modules of :mod: roles and automodule; ::: blocks may be either
+	Files   []string // Repo-relative source files it links to
+}
+
+var (
+	mdHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*$`)
+
+	// :func:`target`, :py:class:`~pkg.Name`, and MyST's {py:meth}`target`
+	roleRe = regexp.MustCompile(`(?::|\{)(?:[a-z]+:)?(func|class|meth|attr|exc|obj|data|const|mod|type|member|struct|var)(?::|\})` + "`([^`]+)`")
+	// .. autofunction:: pkg.mod.name and friends
+	autodocRe = regexp.MustCompile(`^\s*\.\.\s+auto(function|class|method|module|attribute|exception|data)::\s*(\S+)`)
+	// .. literalinclude:: ../src/app.py
+	includeRe = regexp.MustCompile(`^\s*\.\.\s+literalinclude::\s*(\S+)`)
+	// mkdocstrings' ::: pkg.mod.Name
+	mkdocstringsRe = regexp.MustCompile(`^:::\s+([\w.]+)`)
+	// autorefs' [text][pkg.mod.Name]
+	autorefRe = regexp.MustCompile(`\[[^\]]*\]\[([A-Za-z_][\w]*(?:\.[\w]+)+)\]`)
+	// [text](../src/app.py#L10)
+	mdLinkRe = regexp.MustCompile(`\[[^\]]*\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+)
+
+// ParseSitePage parses a Markdown or reStructuredText page at the
+// repo-relative pagePath into sections, with the symbols and source files
+// each one references.
+func ParseSitePage(content []byte, pagePath, repo string) *SitePage {
+	page := &SitePage{Path: pagePath, Repo: repo}
+	lines := strings.Split(string(content), "\n")
+	rst := path.Ext(pagePath) == ".rst"
+
+	var current *SiteSection
+	var headingStack []string
+	var rstStyles []string
+	inFence := false
+
+	startSection := func(heading string, level, line int) {
+		if current != nil {
+			current.EndLine = line - 1
+			page.Sections = append(page.Sections, *current)
+		}
+		for len(headingStack) >= level {
+			headingStack = headingStack[:len(headingStack)-1]
+		}
+		headingStack = append(headingStack, heading)
+		current = &SiteSection{Section: Section{
+			Heading:     heading,
+			HeadingPath: strings.Join(headingStack, " > "),
+			Level:       level,
+			StartLine:   line,
+		}}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+
+		if !inFence && !rst {
+			if m := mdHeadingRe.FindStringSubmatch(line); m != nil {
+				startSection(m[2], len(m[1]), i+1)
+				continue
+			}
+		}
+		if rst && i+1 < len(lines) && trimmed != "" && rstAdornment(line) == "" {
+			if style := rstAdornment(lines[i+1]); style != "" && len(strings.TrimRight(lines[i+1], " \t")) >= len(trimmed) {
+				if i > 0 && rstAdornment(lines[i-1]) != "" {
+					style = "over" + style
+				}
+				level := rstLevel(&rstStyles, style)
+				startSection(trimmed, level, i+1)
+				i++ // Skip the underline
+				continue
+			}
+		}
+		if rst && rstAdornment(line) != "" {
+			continue // An overline, or a transition
+		}
+
+		if current == nil {
+			// Text above the first heading still describes the page
+			startSection(pageTitle(pagePath), 1, i+1)
+		}
+		current.Content += line + "\n"
+		if !inFence {
+			symbols, modules := lineTargets(line)
+			current.Symbols = append(current.Symbols, symbols...)
+			current.Modules = append(current.Modules, modules...)
+		}
+		current.Files = append(current.Files, lineFiles(line, pagePath)...)
+	}
+
+	if current != nil {
+		current.EndLine = len(lines)
+		page.Sections = append(page.Sections, *current)
+	}
+	for i := range page.Sections {
+		page.Sections[i].Symbols = uniqueSorted(page.Sections[i].Symbols)
+		page.Sections[i].Modules = uniqueSorted(page.Sections[i].Modules)
+		page.Sections[i].Files = uniqueSorted(page.Sections[i].Files)
+	}
+	return page
+}
+
+// rstAdornment returns the character of a reST heading under- or overline
+// (three or more of the same punctuation), or "" if line is not one.
+func rstAdornment(line string) string {
+	line = strings.TrimRight(line, " \t")
+	if len(line) < 3 || !strings.ContainsRune(`=-~^"'*+#:.`+"`"+`_`, rune(line[0])) {
+		return ""
+	}
+	if strings.Trim(line, line[:1]) != "" {
+		return ""
+	}
+	return line[:1]
+}
+
+// rstLevel returns the heading level of an adornment style: reST ranks
+// styles in the order a document first uses them.
+func rstLevel(styles *[]string, style string) int {
+	for i, s := range *styles {
+		if s == style {
+			return i + 1
+		}
+	}
+	*styles = append(*styles, style)
+	return len(*styles)
+}
+
+// pageTitle names the untitled lead of a page after its file.
+func pageTitle(pagePath string) string {
+	return strings.TrimSuffix(path.Base(pagePath), path.Ext(pagePath))
+}
+
+// lineTargets returns the dotted symbols and modules line references.
+// mkdocstrings blocks and autorefs links don't say which they name, so
+// their targets are both.
+func lineTargets(line string) (symbols, modules []string) {
+	add := func(list *[]string, target string) {
+		if target != "" {
+			*list = append(*list, target)
+		}
+	}
+	for _, m := range roleRe.FindAllStringSubmatch(line, -1) {
+		if m[1] == "mod" {
+			add(&modules, roleTarget(m[2]))
+		} else {
+			add(&symbols, roleTarget(m[2]))
+		}
+	}
+	if m := autodocRe.FindStringSubmatch(line); m != nil {
+		if m[1] == "module" {
+			add(&modules, m[2])
+		} else {
+			add(&symbols, m[2])
+		}
+	}
+	var either []string
+	if m := mkdocstringsRe.FindStringSubmatch(line); m != nil {
+		either = append(either, m[1])
+	}
+	for _, m := range autorefRe.FindAllStringSubmatch(line, -1) {
+		either = append(either, m[1])
+	}
+	for _, t := range either {
+		add(&symbols, t)
+		add(&modules, t)
+	}
+	return symbols, modules
+}
+
+// roleTarget strips a role's explicit title ("title <target>") and display
+// prefixes (~, !) and a trailing call ("()") off its target.
+func roleTarget(text string) string {
+	if open := strings.LastIndex(text, "<"); open >= 0 && strings.HasSuffix(text, ">") {
+		text = text[open+1 : len(text)-1]
+	}
+	text = strings.TrimLeft(strings.TrimSpace(text), "~!.")
+	return strings.TrimSuffix(text, "()")
+}
+
+// lineFiles returns the repo-relative source files line links to. Links
+// between pages, to other sites, and to images are not source files.
+func lineFiles(line, pagePath string) []string {
+	var targets []string
+	for _, m := range mdLinkRe.FindAllStringSubmatch(line, -1) {
+		targets = append(targets, m[1])
+	}
+	if m := includeRe.FindStringSubmatch(line); m != nil {
+		targets = append(targets, m[1])
+	}
+
+	var files []string
+	for _, t := range targets {
+		if strings.Contains(t, "://") || strings.HasPrefix(t, "#") || strings.HasPrefix(t, "mailto:") {
+			continue
+		}
+		t, _, _ = strings.Cut(t, "#")
+		t, _, _ = strings.Cut(t, "?")
+		if !isSourceFile(t) {
+			continue
+		}
+		var rel string
+		if strings.HasPrefix(t, "/") {
+			rel = path.Clean(strings.TrimPrefix(t, "/"))
+		} else {
+			rel = path.Clean(path.Join(path.Dir(pagePath), t))
+		}
+		if rel == "." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+		files = append(files, rel)
+	}
+	return files
+}
+
+// sourceExts are the extensions of files the indexer parses.
+var sourceExts = map[string]bool{
+	".py": true, ".go": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true,
+	".mjs": true, ".cjs": true, ".java": true, ".rs": true, ".rb": true,
+}
+
+func isSourceFile(p string) bool {
+	return sourceExts[path.Ext(p)]
+}
+
+func uniqueSorted(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Strings(values)
+	kept := values[:1]
+	for _, v := range values[1:] {
+		if v != kept[len(kept)-1] {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// ToChunks converts the page to indexable chunks of kind "site", listing
+// what each section describes in its metadata.
+func (p *SitePage) ToChunks() []chunk.Chunk {
+	var chunks []chunk.Chunk
+	for _, section := range p.Sections {
+		if strings.TrimSpace(section.Content) == "" {
+			continue
+		}
+		c := chunk.Chunk{
+			Repo:            p.Repo,
+			FilePath:        p.Path,
+			StartLine:       section.StartLine,
+			EndLine:         section.EndLine,
+			Type:            chunk.ChunkTypeDoc,
+			Kind:            "site",
+			HeadingPath:     section.HeadingPath,
+			Content:         section.Content,
+			RetrievalWeight: 1.2,
+		}
+		if len(section.Symbols) > 0 || len(section.Modules) > 0 || len(section.Files) > 0 {
+			c.Metadata = map[string]string{}
+			if len(section.Symbols) > 0 {
+				c.Metadata[MetaDescribesSymbols] = strings.Join(section.Symbols, ",")
+			}
+			if len(section.Modules) > 0 {
+				c.Metadata[MetaDescribesModules] = strings.Join(section.Modules, ",")
+			}
+			if len(section.Files) > 0 {
+				c.Metadata[MetaDescribesFiles] = strings.Join(section.Files, ",")
+			}
+		}
+		c.ID = chunk.GenerateID(p.Repo, p.Path, section.Heading, section.StartLine)
+		chunks = append(chunks, c)
+	}
+	return chunks
+}
+
+// Describes returns the symbol and module targets and the files a site
+// chunk describes, as ToChunks recorded them.
+func Describes(c chunk.Chunk) (symbols, modules, files []string) {
+	split := func(key string) []string {
+		if v := c.Metadata[key]; v != "" {
+			return strings.Split(v, ",")
+		}
+		return nil
+	}
+	return split(MetaDescribesSymbols), split(MetaDescribesModules), split(MetaDescribesFiles)
+}
+
+// SymbolTarget is one reading of a dotted target as an indexed symbol: a
+// symbol named Name in a file ending in one of Files (any file when
+// empty), nested in the class Parent when that is set.
+type SymbolTarget struct {
+	Name   string
+	Parent string
+	Files  []string
+}
+
+// SymbolTargets returns the readings of a dotted target. Where the module
+// part ends is unknown, so "pkg.Client.get" is function get of module
+// pkg.Client or method get of class Client in module pkg. A bare name is
+// every symbol of that name.
+func SymbolTargets(target string) []SymbolTarget {
+	parts := strings.Split(target, ".")
+	n := len(parts)
+	name := parts[n-1]
+	if n == 1 {
+		return []SymbolTarget{{Name: name}}
+	}
+
+	var targets []SymbolTarget
+	if files := ModuleFiles(strings.Join(parts[:n-1], ".")); files != nil {
+		targets = append(targets, SymbolTarget{Name: name, Files: files})
+	}
+	// Under a one-part module (or none), any file may hold the class
+	return append(targets, SymbolTarget{Name: name, Parent: parts[n-2], Files: ModuleFiles(strings.Join(parts[:n-2], "."))})
+}
+
+// ModuleFiles returns the path suffixes of the Python files a dotted target
+// names if it is a module, e.g. "pkg/mod.py" and "pkg/mod/__init__.py".
+// One-part names return nil: as a suffix, "mod.py" would match any
+// directory's mod.py.
+func ModuleFiles(target string) []string {
+	if !strings.Contains(target, ".") {
+		return nil
+	}
+	base := strings.ReplaceAll(target, ".", "/")
+	return []string{base + ".py", base + "/__init__.py"}
+}
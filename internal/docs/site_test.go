@@ -0,0 +1,140 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSite(t *testing.T) {
+	assert.Nil(t, DetectSite(t.TempDir()))
+
+	mkdocs := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(mkdocs, "mkdocs.yml"), []byte("site_name: App\ndocs_dir: 'site-src/'\nplugins:\n  - mkdocstrings\n"), 0o644))
+	assert.Equal(t, &Site{Generator: SiteMkDocs, Dir: "site-src"}, DetectSite(mkdocs))
+
+	sphinx := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sphinx, "doc", "source"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(sphinx, "doc", "source", "conf.py"), []byte("project = 'app'\n"), 0o644))
+	site := DetectSite(sphinx)
+	assert.Equal(t, &Site{Generator: SiteSphinx, Dir: "doc/source"}, site)
+
+	assert.True(t, site.IsPage("doc/source/guide/intro.rst"))
+	assert.True(t, site.IsPage("doc/source/index.md"))
+	assert.False(t, site.IsPage("doc/source/conf.py"))
+	assert.False(t, site.IsPage("docs/intro.rst"))
+	assert.False(t, (&Site{Generator: SiteMkDocs, Dir: "docs"}).IsPage("docs/intro.rst"))
+}
+
+func TestParseSitePageRST(t *testing.T) {
+	content := `=========
+Ingestion
+=========
+
+Imports run through :func:` + "`~app.imports.run_import`" + ` and
+:py:class:` + "`the base class <app.imports.BaseImporter>`" + `.
+
+Scheduling
+----------
+
+.. automodule:: app.scheduler
+
+.. autofunction:: app.scheduler.schedule
+
+.. literalinclude:: ../src/app/scheduler.py
+`
+	page := ParseSitePage([]byte(content), "docs/ingest.rst", "app")
+	require.Len(t, page.Sections, 2)
+
+	intro := page.Sections[0]
+	assert.Equal(t, "Ingestion", intro.Heading)
+	assert.Equal(t, 1, intro.Level)
+	assert.Equal(t, []string{"app.imports.BaseImporter", "app.imports.run_import"}, intro.Symbols)
+	assert.Empty(t, intro.Modules)
+	assert.Empty(t, intro.Files)
+
+	sched := page.Sections[1]
+	assert.Equal(t, "Ingestion > Scheduling", sched.HeadingPath)
+	assert.Equal(t, 2, sched.Level)
+	assert.Equal(t, []string{"app.scheduler.schedule"}, sched.Symbols, "automodule names a module, not a symbol")
+	assert.Equal(t, []string{"app.scheduler"}, sched.Modules)
+	assert.Equal(t, []string{"src/app/scheduler.py"}, sched.Files)
+}
+
+func TestParseSitePageMarkdown(t *testing.T) {
+	content := `Lead paragraph.
+
+# API
+
+::: app.client.Client
+
+See [the retry helper][app.client.retry], [the source](../app/client.py#L10),
+[another page](usage.md), and [upstream](https://example.org/app.py).
+
+` + "```python" + `
+# not a heading
+::: not.a.target
+` + "```" + `
+
+## Errors
+
+Raised as {py:exc}` + "`app.errors.ClientError`" + `.
+`
+	page := ParseSitePage([]byte(content), "docs/api.md", "app")
+	require.Len(t, page.Sections, 3)
+
+	assert.Equal(t, "api", page.Sections[0].Heading, "text above the first heading is titled after the page")
+
+	api := page.Sections[1]
+	assert.Equal(t, "API", api.Heading)
+	assert.Equal(t, []string{"app.client.Client", "app.client.retry"}, api.Symbols)
+	assert.Equal(t, api.Symbols, api.Modules, "::: and autorefs targets may be modules too")
+	assert.Equal(t, []string{"app/client.py"}, api.Files)
+	assert.Contains(t, api.Content, "# not a heading", "fenced code is not a heading")
+
+	errs := page.Sections[2]
+	assert.Equal(t, "API > Errors", errs.HeadingPath)
+	assert.Equal(t, []string{"app.errors.ClientError"}, errs.Symbols)
+}
+
+func TestSitePageToChunks(t *testing.T) {
+	content := "# Client\n\n::: app.client.Client\n\nLinks to [the source](../app/client.py).\n\n## Notes\n\nNothing to see.\n\n## Empty\n"
+	chunks := ParseSitePage([]byte(content), "docs/client.md", "app").ToChunks()
+	require.Len(t, chunks, 2, "sections without content are skipped")
+
+	assert.Equal(t, "site", chunks[0].Kind)
+	assert.Equal(t, "docs/client.md", chunks[0].FilePath)
+	assert.NotEmpty(t, chunks[0].ID)
+	symbols, modules, files := Describes(chunks[0])
+	assert.Equal(t, []string{"app.client.Client"}, symbols)
+	assert.Equal(t, []string{"app.client.Client"}, modules)
+	assert.Equal(t, []string{"app/client.py"}, files)
+
+	assert.Nil(t, chunks[1].Metadata)
+	symbols, modules, files = Describes(chunks[1])
+	assert.Empty(t, symbols)
+	assert.Empty(t, modules)
+	assert.Empty(t, files)
+}
+
+func TestSymbolTargetsAndModuleFiles(t *testing.T) {
+	assert.Equal(t, []SymbolTarget{
+		{Name: "get", Files: []string{"pkg/Client.py", "pkg/Client/__init__.py"}},
+		{Name: "get", Parent: "Client"},
+	}, SymbolTargets("pkg.Client.get"), "function of module pkg.Client or method of class Client in pkg")
+	assert.Equal(t, []SymbolTarget{
+		{Name: "retry", Files: []string{"app/client.py", "app/client/__init__.py"}},
+		{Name: "retry", Parent: "client"},
+	}, SymbolTargets("app.client.retry"))
+	assert.Equal(t, []SymbolTarget{
+		{Name: "get", Files: []string{"app/http/Client.py", "app/http/Client/__init__.py"}},
+		{Name: "get", Parent: "Client", Files: []string{"app/http.py", "app/http/__init__.py"}},
+	}, SymbolTargets("app.http.Client.get"))
+	assert.Equal(t, []SymbolTarget{{Name: "get", Parent: "Client"}}, SymbolTargets("Client.get"))
+	assert.Equal(t, []SymbolTarget{{Name: "retry"}}, SymbolTargets("retry"))
+	assert.Equal(t, []string{"app/client.py", "app/client/__init__.py"}, ModuleFiles("app.client"))
+	assert.Nil(t, ModuleFiles("client"))
+}
@@ -53,8 +53,15 @@ type voyageUsage struct {
 
 // Embed generates embeddings for the given texts.
 func (c *VoyageClient) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors, _, err := c.EmbedWithUsage(ctx, texts)
+	return vectors, err
+}
+
+// EmbedWithUsage is Embed that also returns the tokens the API billed for
+// the request.
+func (c *VoyageClient) EmbedWithUsage(ctx context.Context, texts []string) ([][]float32, int, error) {
 	if len(texts) == 0 {
-		return nil, nil
+		return nil, 0, nil
 	}
 
 	// Filter out empty strings and track their positions
@@ -74,7 +81,7 @@ func (c *VoyageClient) Embed(ctx context.Context, texts []string) ([][]float32,
 		for i := range vectors {
 			vectors[i] = make([]float32, c.Dimension())
 		}
-		return vectors, nil
+		return vectors, 0, nil
 	}
 
 	reqBody := voyageRequest{
@@ -85,12 +92,12 @@ func (c *VoyageClient) Embed(ctx context.Context, texts []string) ([][]float32,
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, 0, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", voyageAPIURL, bytes.NewReader(jsonBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -98,22 +105,22 @@ func (c *VoyageClient) Embed(ctx context.Context, texts []string) ([][]float32,
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, 0, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, 0, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var voyageResp voyageResponse
 	if err := json.Unmarshal(body, &voyageResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, 0, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	// Build result vectors, inserting zero vectors for empty inputs
@@ -135,16 +142,24 @@ func (c *VoyageClient) Embed(ctx context.Context, texts []string) ([][]float32,
 		}
 	}
 
-	return vectors, nil
+	return vectors, voyageResp.Usage.TotalTokens, nil
 }
 
 // EmbedBatched handles large inputs by batching.
 func (c *VoyageClient) EmbedBatched(ctx context.Context, texts []string, batchSize int) ([][]float32, error) {
+	vectors, _, err := c.EmbedBatchedWithUsage(ctx, texts, batchSize)
+	return vectors, err
+}
+
+// EmbedBatchedWithUsage is EmbedBatched that also returns the tokens billed
+// for every batch.
+func (c *VoyageClient) EmbedBatchedWithUsage(ctx context.Context, texts []string, batchSize int) ([][]float32, int, error) {
 	if batchSize <= 0 {
 		batchSize = 128 // Voyage default max
 	}
 
 	var allVectors [][]float32
+	tokens := 0
 
 	for i := 0; i < len(texts); i += batchSize {
 		end := i + batchSize
@@ -153,15 +168,21 @@ func (c *VoyageClient) EmbedBatched(ctx context.Context, texts []string, batchSi
 		}
 
 		batch := texts[i:end]
-		vectors, err := c.Embed(ctx, batch)
+		vectors, used, err := c.EmbedWithUsage(ctx, batch)
+		tokens += used
 		if err != nil {
-			return nil, fmt.Errorf("batch %d-%d failed: %w", i, end, err)
+			return nil, tokens, fmt.Errorf("batch %d-%d failed: %w", i, end, err)
 		}
 
 		allVectors = append(allVectors, vectors...)
 	}
 
-	return allVectors, nil
+	return allVectors, tokens, nil
+}
+
+// Model returns the embedding model, for pricing its usage.
+func (c *VoyageClient) Model() string {
+	return c.model
 }
 
 // Dimension returns the vector dimension for the model.
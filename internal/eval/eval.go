@@ -0,0 +1,208 @@
+// Package eval measures retrieval quality against a golden set of queries
+// with known relevant files.
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultK is the cutoff used when neither the golden file nor the caller
+// sets one.
+const DefaultK = 10
+
+// GoldenSet is a golden file: queries paired with the files a good search
+// should return.
+type GoldenSet struct {
+	Repo  string `yaml:"repo"` // Default repo for cases that don't set one
+	K     int    `yaml:"k"`    // Default cutoff
+	Cases []Case `yaml:"cases"`
+}
+
+// Case is one query and its relevant files. Expected entries are
+// repo-relative paths or doublestar globs ("fisio/imports/**").
+type Case struct {
+	Query    string   `yaml:"query"`
+	Expected []string `yaml:"expected"`
+	Repo     string   `yaml:"repo"`
+}
+
+// LoadGolden reads and validates a golden file.
+func LoadGolden(path string) (*GoldenSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set GoldenSet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(set.Cases) == 0 {
+		return nil, fmt.Errorf("%s has no cases", path)
+	}
+	for i, c := range set.Cases {
+		if c.Query == "" {
+			return nil, fmt.Errorf("case %d: query is required", i+1)
+		}
+		if len(c.Expected) == 0 {
+			return nil, fmt.Errorf("case %d (%q): expected is required", i+1, c.Query)
+		}
+		for _, pattern := range c.Expected {
+			if !doublestar.ValidatePattern(pattern) {
+				return nil, fmt.Errorf("case %d (%q): invalid pattern %q", i+1, c.Query, pattern)
+			}
+		}
+	}
+	return &set, nil
+}
+
+// SearchFunc runs a query and returns the matching file paths, best first.
+// Paths may repeat when several chunks of a file match.
+type SearchFunc func(ctx context.Context, query, repo string) ([]string, error)
+
+// CaseResult is how one query fared.
+type CaseResult struct {
+	Query     string   `json:"query"`
+	Repo      string   `json:"repo,omitempty"`
+	Expected  []string `json:"expected"`
+	Retrieved []string `json:"retrieved"` // Distinct files, top k
+	Rank      int      `json:"rank"`      // 1-based rank of the first relevant file; 0 if none in the top k
+	Recall    float64  `json:"recall"`    // Fraction of expected entries found in the top k
+	LatencyMS float64  `json:"latency_ms"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Report aggregates a run. Failed queries count as misses.
+type Report struct {
+	K            int          `json:"k"`
+	Cases        []CaseResult `json:"cases"`
+	RecallAtK    float64      `json:"recall_at_k"`
+	MRR          float64      `json:"mrr"`
+	HitRate      float64      `json:"hit_rate"` // Cases with any relevant file in the top k
+	LatencyP50MS float64      `json:"latency_p50_ms"`
+	LatencyP95MS float64      `json:"latency_p95_ms"`
+	Errors       int          `json:"errors"`
+}
+
+// Run evaluates every case in order. k <= 0 falls back to the set's k, then
+// DefaultK. Only a cancelled ctx stops the run early.
+func Run(ctx context.Context, set *GoldenSet, k int, search SearchFunc) (*Report, error) {
+	if k <= 0 {
+		k = set.K
+	}
+	if k <= 0 {
+		k = DefaultK
+	}
+
+	report := &Report{K: k, Cases: make([]CaseResult, 0, len(set.Cases))}
+	var latencies []float64
+
+	for _, c := range set.Cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		repo := c.Repo
+		if repo == "" {
+			repo = set.Repo
+		}
+		result := CaseResult{Query: c.Query, Repo: repo, Expected: c.Expected, Retrieved: []string{}}
+
+		started := time.Now()
+		paths, err := search(ctx, c.Query, repo)
+		result.LatencyMS = float64(time.Since(started).Microseconds()) / 1000
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+			result.Error = err.Error()
+			report.Errors++
+		} else {
+			latencies = append(latencies, result.LatencyMS)
+			result.Retrieved = topFiles(paths, k)
+			result.Rank, result.Recall = score(c.Expected, result.Retrieved)
+		}
+
+		report.Cases = append(report.Cases, result)
+		report.RecallAtK += result.Recall
+		if result.Rank > 0 {
+			report.MRR += 1 / float64(result.Rank)
+			report.HitRate++
+		}
+	}
+
+	n := float64(len(report.Cases))
+	report.RecallAtK /= n
+	report.MRR /= n
+	report.HitRate /= n
+	report.LatencyP50MS = percentile(latencies, 50)
+	report.LatencyP95MS = percentile(latencies, 95)
+	return report, nil
+}
+
+// topFiles dedups paths, keeping first occurrences, and truncates to k.
+func topFiles(paths []string, k int) []string {
+	seen := make(map[string]bool)
+	files := []string{}
+	for _, p := range paths {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		files = append(files, p)
+		if len(files) == k {
+			break
+		}
+	}
+	return files
+}
+
+// score returns the rank of the first retrieved file matching any expected
+// entry, and the fraction of expected entries matched by some file.
+func score(expected, retrieved []string) (rank int, recall float64) {
+	found := 0
+	for _, pattern := range expected {
+		for _, path := range retrieved {
+			if matches(pattern, path) {
+				found++
+				break
+			}
+		}
+	}
+
+	for i, path := range retrieved {
+		for _, pattern := range expected {
+			if matches(pattern, path) {
+				return i + 1, float64(found) / float64(len(expected))
+			}
+		}
+	}
+	return 0, 0
+}
+
+func matches(pattern, path string) bool {
+	if pattern == path {
+		return true
+	}
+	ok, _ := doublestar.Match(pattern, path)
+	return ok
+}
+
+// percentile uses the nearest-rank method; 0 for no samples.
+func percentile(values []float64, p int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeGolden(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "golden.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadGolden(t *testing.T) {
+	set, err := LoadGolden(writeGolden(t, `
+repo: r3
+k: 5
+cases:
+  - query: retry failed imports
+    expected: [fisio/imports/retry.py]
+  - query: auth middleware
+    repo: web
+    expected: ["src/auth/**"]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "r3", set.Repo)
+	assert.Equal(t, 5, set.K)
+	require.Len(t, set.Cases, 2)
+	assert.Equal(t, "web", set.Cases[1].Repo)
+}
+
+func TestLoadGoldenValidates(t *testing.T) {
+	tests := map[string]string{
+		"no cases":         "repo: r3\n",
+		"missing query":    "cases:\n  - expected: [a.py]\n",
+		"missing expected": "cases:\n  - query: x\n",
+		"bad pattern":      "cases:\n  - query: x\n    expected: [\"a/[b\"]\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadGolden(writeGolden(t, content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	set := &GoldenSet{
+		Repo: "r3",
+		Cases: []Case{
+			{Query: "first hit", Expected: []string{"a.py"}},
+			{Query: "second hit", Expected: []string{"b.py", "c.py"}},
+			{Query: "miss", Expected: []string{"z.py"}},
+			{Query: "glob", Expected: []string{"pkg/**"}, Repo: "web"},
+		},
+	}
+	results := map[string][]string{
+		"first hit":  {"a.py", "a.py", "b.py"},
+		"second hit": {"x.py", "x.py", "b.py", "y.py", "c.py"},
+		"miss":       {"a.py", "b.py"},
+		"glob":       {"pkg/sub/mod.py"},
+	}
+	var repos []string
+	search := func(ctx context.Context, query, repo string) ([]string, error) {
+		repos = append(repos, repo)
+		return results[query], nil
+	}
+
+	report, err := Run(context.Background(), set, 3, search)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.K)
+	assert.Equal(t, []string{"r3", "r3", "r3", "web"}, repos)
+
+	assert.Equal(t, 1, report.Cases[0].Rank)
+	assert.Equal(t, []string{"a.py", "b.py"}, report.Cases[0].Retrieved, "files are deduplicated")
+	assert.Equal(t, 2, report.Cases[1].Rank)
+	assert.Equal(t, 0.5, report.Cases[1].Recall, "c.py is past the cutoff of 3 files")
+	assert.Equal(t, 0, report.Cases[2].Rank)
+	assert.Equal(t, 1, report.Cases[3].Rank)
+
+	assert.InDelta(t, (1+0.5+0+1)/4.0, report.RecallAtK, 1e-9)
+	assert.InDelta(t, (1+0.5+0+1)/4.0, report.MRR, 1e-9)
+	assert.InDelta(t, 0.75, report.HitRate, 1e-9)
+}
+
+func TestRunCountsErrorsAsMisses(t *testing.T) {
+	set := &GoldenSet{K: 5, Cases: []Case{
+		{Query: "ok", Expected: []string{"a.py"}},
+		{Query: "broken", Expected: []string{"a.py"}},
+	}}
+	search := func(ctx context.Context, query, repo string) ([]string, error) {
+		if query == "broken" {
+			return nil, errors.New("qdrant down")
+		}
+		return []string{"a.py"}, nil
+	}
+
+	report, err := Run(context.Background(), set, 0, search)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.K, "k falls back to the golden file")
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, "qdrant down", report.Cases[1].Error)
+	assert.InDelta(t, 0.5, report.MRR, 1e-9)
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{50, 10, 40, 20, 30}
+	assert.Equal(t, 30.0, percentile(values, 50))
+	assert.Equal(t, 50.0, percentile(values, 95))
+	assert.Equal(t, 0.0, percentile(nil, 50))
+}
@@ -0,0 +1,231 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// maxCallerFanIn bounds how many callers one breadth-first level of an
+// entry-point search reads, so a hub called from everywhere cannot make a
+// level arbitrarily expensive. Paths through the callers past the bound are
+// missed.
+const maxCallerFanIn = 2000
+
+// symbolProjection returns a node's Symbol properties as a map; both
+// dialects support map projections.
+const symbolProjection = "{.name, .kind, .file_path, .start_line, .end_line, .signature}"
+
+// FindCallPaths returns up to limit CALLS chains of at most maxDepth hops
+// that end at a symbol named in to, shortest first, each ordered from caller
+// to callee. Chains start at a symbol named in from, or with an empty from,
+// at an entry point: a symbol nothing calls.
+func (s *Neo4jStore) FindCallPaths(ctx context.Context, repo string, from, to []string, maxDepth, limit int) ([][]Symbol, error) {
+	if len(from) == 0 {
+		return s.findEntryPaths(ctx, repo, to, maxDepth, limit)
+	}
+
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, s.caps.callPathQuery(maxDepth), map[string]interface{}{
+		"repo":  repo,
+		"from":  from,
+		"to":    to,
+		"limit": limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var paths [][]Symbol
+	for result.Next(ctx) {
+		val, _ := result.Record().Get("hops")
+		nodes, _ := val.([]interface{})
+		path := make([]Symbol, 0, len(nodes))
+		for _, node := range nodes {
+			props, _ := node.(map[string]interface{})
+			path = append(path, symbolFromProps(repo, props))
+		}
+		paths = append(paths, path)
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// callPathQuery returns the shortest-path query between named symbols in
+// the server's dialect: Neo4j's shortestPath, or Memgraph's breadth-first
+// expansion, which has no shortestPath.
+func (c Capabilities) callPathQuery(maxDepth int) string {
+	// Variable-length bounds cannot be parameters
+	if c.Flavor == FlavorMemgraph {
+		return fmt.Sprintf(`
+			MATCH (src:Symbol {repo: $repo}) WHERE src.name IN $from
+			MATCH (dst:Symbol {repo: $repo}) WHERE dst.name IN $to AND dst <> src
+			MATCH p = (src)-[:CALLS *BFS ..%d]->(dst)
+			RETURN [n IN nodes(p) | n %s] AS hops
+			ORDER BY size(relationships(p))
+			LIMIT $limit
+		`, maxDepth, symbolProjection)
+	}
+	return fmt.Sprintf(`
+		MATCH (src:Symbol {repo: $repo}) WHERE src.name IN $from
+		MATCH (dst:Symbol {repo: $repo}) WHERE dst.name IN $to AND dst <> src
+		MATCH p = shortestPath((src)-[:CALLS*..%d]->(dst))
+		RETURN [n IN nodes(p) | n %s] AS hops
+		ORDER BY length(p)
+		LIMIT $limit
+	`, maxDepth, symbolProjection)
+}
+
+// findEntryPaths finds chains from entry points to the symbols named in to
+// by expanding callers backwards from them one level per query, so the
+// search stops as soon as limit chains are found instead of enumerating
+// every path up to maxDepth.
+func (s *Neo4jStore) findEntryPaths(ctx context.Context, repo string, to []string, maxDepth, limit int) ([][]Symbol, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (dst:Symbol {repo: $repo}) WHERE dst.name IN $to
+		RETURN dst `+symbolProjection+` AS symbol
+	`, map[string]interface{}{"repo": repo, "to": to})
+	if err != nil {
+		return nil, err
+	}
+	var targets []Symbol
+	for result.Next(ctx) {
+		val, _ := result.Record().Get("symbol")
+		props, _ := val.(map[string]interface{})
+		targets = append(targets, symbolFromProps(repo, props))
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+
+	callers := func(frontier []Symbol) ([]callEdge, error) {
+		keys := make([]map[string]interface{}, len(frontier))
+		for i, sym := range frontier {
+			keys[i] = map[string]interface{}{"file_path": sym.FilePath, "name": sym.Name, "start_line": sym.StartLine}
+		}
+		result, err := session.Run(ctx, `
+			UNWIND $frontier AS key
+			MATCH (callee:Symbol {repo: $repo, file_path: key.file_path, name: key.name, start_line: key.start_line})
+			MATCH (caller:Symbol {repo: $repo})-[:CALLS]->(callee)
+			RETURN callee `+symbolProjection+` AS callee, caller `+symbolProjection+` AS caller
+			LIMIT $fan_in
+		`, map[string]interface{}{"repo": repo, "frontier": keys, "fan_in": maxCallerFanIn})
+		if err != nil {
+			return nil, err
+		}
+		var edges []callEdge
+		for result.Next(ctx) {
+			record := result.Record()
+			callee, _ := record.Get("callee")
+			caller, _ := record.Get("caller")
+			calleeProps, _ := callee.(map[string]interface{})
+			callerProps, _ := caller.(map[string]interface{})
+			edges = append(edges, callEdge{Caller: symbolFromProps(repo, callerProps), Callee: symbolFromProps(repo, calleeProps)})
+		}
+		return edges, result.Err()
+	}
+
+	return searchEntryPaths(targets, callers, maxDepth, limit)
+}
+
+// callEdge is a Caller CALLS Callee relationship.
+type callEdge struct {
+	Caller Symbol
+	Callee Symbol
+}
+
+// searchEntryPaths runs a breadth-first search from targets over callers,
+// which returns the callers of a level's symbols. A symbol found at depth d
+// without callers is an entry point, and the chain from it to the target
+// it was reached from is d hops long, so chains come out shortest first,
+// one per entry point. Symbols at maxDepth are only checked for callers.
+// The search stops at the level where limit chains have been found.
+func searchEntryPaths(targets []Symbol, callers func([]Symbol) ([]callEdge, error), maxDepth, limit int) ([][]Symbol, error) {
+	next := make(map[string]Symbol) // Symbol key -> the callee it was reached through
+	visited := make(map[string]bool)
+	for _, t := range targets {
+		visited[symbolKey(t)] = true
+	}
+
+	var paths [][]Symbol
+	frontier := targets
+	for depth := 0; depth <= maxDepth && len(frontier) > 0 && len(paths) < limit; depth++ {
+		edges, err := callers(frontier)
+		if err != nil {
+			return nil, err
+		}
+		// Past the fan-in bound, a symbol without callers in edges may
+		// still have some, so the level yields no entry points
+		complete := len(edges) < maxCallerFanIn
+		called := make(map[string]bool)
+		var discovered []Symbol
+		for _, e := range edges {
+			called[symbolKey(e.Callee)] = true
+			key := symbolKey(e.Caller)
+			if depth == maxDepth || visited[key] {
+				continue
+			}
+			visited[key] = true
+			next[key] = e.Callee
+			discovered = append(discovered, e.Caller)
+		}
+
+		if depth > 0 && complete {
+			for _, sym := range sortedSymbols(frontier) {
+				if called[symbolKey(sym)] || len(paths) == limit {
+					continue
+				}
+				path := []Symbol{sym}
+				for cur := sym; ; {
+					callee, ok := next[symbolKey(cur)]
+					if !ok {
+						break
+					}
+					path = append(path, callee)
+					cur = callee
+				}
+				paths = append(paths, path)
+			}
+		}
+		frontier = discovered
+	}
+
+	return paths, nil
+}
+
+func sortedSymbols(symbols []Symbol) []Symbol {
+	sorted := append([]Symbol(nil), symbols...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].FilePath != sorted[j].FilePath {
+			return sorted[i].FilePath < sorted[j].FilePath
+		}
+		return sorted[i].StartLine < sorted[j].StartLine
+	})
+	return sorted
+}
+
+// symbolFromProps converts a symbolProjection map into a Symbol.
+func symbolFromProps(repo string, props map[string]interface{}) Symbol {
+	sym := Symbol{Repo: repo}
+	sym.Name, _ = props["name"].(string)
+	sym.Kind, _ = props["kind"].(string)
+	sym.FilePath, _ = props["file_path"].(string)
+	sym.Signature, _ = props["signature"].(string)
+	if v, ok := props["start_line"].(int64); ok {
+		sym.StartLine = int(v)
+	}
+	if v, ok := props["end_line"].(int64); ok {
+		sym.EndLine = int(v)
+	}
+	return sym
+}
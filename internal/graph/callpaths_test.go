@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallPathQuery(t *testing.T) {
+	neo := Capabilities{Flavor: FlavorNeo4j}.callPathQuery(4)
+	assert.Contains(t, neo, "shortestPath((src)-[:CALLS*..4]->(dst))")
+	assert.Equal(t, neo, Capabilities{Flavor: FlavorUnknown}.callPathQuery(4), "unknown servers get the Neo4j dialect")
+
+	mg := Capabilities{Flavor: FlavorMemgraph}.callPathQuery(4)
+	assert.NotContains(t, mg, "shortestPath")
+	assert.Contains(t, mg, "[:CALLS *BFS ..4]")
+}
+
+func TestSearchEntryPaths(t *testing.T) {
+	sym := func(name string, line int) Symbol { return Symbol{Name: name, FilePath: "app.py", StartLine: line} }
+	main, cli, handler, service, validate := sym("main", 1), sym("cli", 5), sym("handler", 10), sym("service", 20), sym("validate", 30)
+	loopA, loopB := sym("loop_a", 40), sym("loop_b", 50)
+
+	// main -> handler -> service -> validate, cli -> service, and a cycle
+	// loop_a <-> loop_b calling validate, which has no entry point
+	callersOf := map[string][]Symbol{
+		"validate": {service, loopA},
+		"service":  {handler, cli},
+		"handler":  {main},
+		"loop_a":   {loopB},
+		"loop_b":   {loopA},
+	}
+	var levels [][]string
+	callers := func(frontier []Symbol) ([]callEdge, error) {
+		var names []string
+		var edges []callEdge
+		for _, callee := range frontier {
+			names = append(names, callee.Name)
+			for _, caller := range callersOf[callee.Name] {
+				edges = append(edges, callEdge{Caller: caller, Callee: callee})
+			}
+		}
+		levels = append(levels, names)
+		return edges, nil
+	}
+
+	paths, err := searchEntryPaths([]Symbol{validate}, callers, 6, 10)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+	assert.Equal(t, "cli -> service -> validate", pathNames(paths[0]), "shortest first")
+	assert.Equal(t, "main -> handler -> service -> validate", pathNames(paths[1]))
+
+	levels = nil
+	paths, err = searchEntryPaths([]Symbol{validate}, callers, 6, 1)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	assert.Len(t, levels, 3, "the search stops at the level reaching the limit")
+
+	paths, err = searchEntryPaths([]Symbol{validate}, callers, 2, 10)
+	require.NoError(t, err)
+	require.Len(t, paths, 1, "chains longer than maxDepth are not followed")
+	assert.Equal(t, "cli -> service -> validate", pathNames(paths[0]))
+
+	paths, err = searchEntryPaths([]Symbol{main}, callers, 6, 10)
+	require.NoError(t, err)
+	assert.Empty(t, paths, "a target nothing calls is no chain")
+}
+
+func pathNames(path []Symbol) string {
+	names := make([]string, len(path))
+	for i, s := range path {
+		names[i] = s.Name
+	}
+	return strings.Join(names, " -> ")
+}
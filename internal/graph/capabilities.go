@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Flavor identifies the Bolt server implementation.
+type Flavor string
+
+const (
+	FlavorNeo4j    Flavor = "neo4j"
+	FlavorMemgraph Flavor = "memgraph"
+	FlavorUnknown  Flavor = "unknown"
+)
+
+// Capabilities describes what the connected Bolt server supports. Queries
+// that depend on optional features check these instead of failing at runtime.
+type Capabilities struct {
+	Agent  string // Server agent string, e.g. "Neo4j/5.26.0"
+	Flavor Flavor
+	APOC   bool // apoc.* procedures installed
+}
+
+// flavorFromAgent classifies a server agent string. Memgraph advertises itself
+// as Neo4j-compatible, so it is checked first.
+func flavorFromAgent(agent string) Flavor {
+	lower := strings.ToLower(agent)
+	switch {
+	case strings.Contains(lower, "memgraph"):
+		return FlavorMemgraph
+	case strings.HasPrefix(lower, "neo4j/"):
+		return FlavorNeo4j
+	default:
+		return FlavorUnknown
+	}
+}
+
+// detectCapabilities queries the server agent and probes for APOC. Failures
+// leave the conservative defaults (unknown flavor, no APOC).
+func detectCapabilities(ctx context.Context, driver neo4j.DriverWithContext) Capabilities {
+	caps := Capabilities{Flavor: FlavorUnknown}
+
+	if info, err := driver.GetServerInfo(ctx); err == nil {
+		caps.Agent = info.Agent()
+		caps.Flavor = flavorFromAgent(caps.Agent)
+	}
+
+	// Memgraph has no APOC path procedures even with MAGE installed
+	if caps.Flavor == FlavorMemgraph {
+		return caps
+	}
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, "RETURN apoc.version() AS version", nil)
+	if err == nil {
+		_, err = result.Consume(ctx)
+	}
+	caps.APOC = err == nil
+
+	return caps
+}
+
+// schemaStatements returns the constraint and index DDL for the server's
+// dialect. Neo4j 5 syntax is used unless the server is known to be Memgraph.
+func (c Capabilities) schemaStatements() (constraints, indexes []string) {
+	if c.Flavor == FlavorMemgraph {
+		constraints = []string{
+			"CREATE CONSTRAINT ON (r:Repository) ASSERT r.name IS UNIQUE",
+			"CREATE CONSTRAINT ON (f:File) ASSERT f.repo, f.path IS UNIQUE",
+			"CREATE CONSTRAINT ON (s:Symbol) ASSERT s.repo, s.file_path, s.name, s.start_line IS UNIQUE",
+			"CREATE CONSTRAINT ON (m:Module) ASSERT m.repo, m.path IS UNIQUE",
+			"CREATE CONSTRAINT ON (p:Pattern) ASSERT p.module, p.name IS UNIQUE",
+			"CREATE CONSTRAINT ON (d:DocChunk) ASSERT d.id IS UNIQUE",
+		}
+		indexes = []string{
+			"CREATE INDEX ON :File(repo)",
+			"CREATE INDEX ON :File(hash)",
+			"CREATE INDEX ON :Symbol(repo)",
+			"CREATE INDEX ON :Symbol(kind)",
+			"CREATE INDEX ON :Symbol(name)",
+			"CREATE INDEX ON :Module(repo)",
+			"CREATE INDEX ON :GraphVersion(repo)",
+			"CREATE INDEX ON :DocChunk(repo)",
+		}
+		return constraints, indexes
+	}
+
+	constraints = []string{
+		"CREATE CONSTRAINT repo_name IF NOT EXISTS FOR (r:Repository) REQUIRE r.name IS UNIQUE",
+		"CREATE CONSTRAINT file_path IF NOT EXISTS FOR (f:File) REQUIRE (f.repo, f.path) IS UNIQUE",
+		"CREATE CONSTRAINT symbol_id IF NOT EXISTS FOR (s:Symbol) REQUIRE (s.repo, s.file_path, s.name, s.start_line) IS UNIQUE",
+		"CREATE CONSTRAINT module_path IF NOT EXISTS FOR (m:Module) REQUIRE (m.repo, m.path) IS UNIQUE",
+		"CREATE CONSTRAINT pattern_name IF NOT EXISTS FOR (p:Pattern) REQUIRE (p.module, p.name) IS UNIQUE",
+		"CREATE CONSTRAINT doc_chunk_id IF NOT EXISTS FOR (d:DocChunk) REQUIRE d.id IS UNIQUE",
+	}
+	indexes = []string{
+		"CREATE INDEX file_repo IF NOT EXISTS FOR (f:File) ON (f.repo)",
+		"CREATE INDEX file_hash IF NOT EXISTS FOR (f:File) ON (f.hash)",
+		"CREATE INDEX symbol_repo IF NOT EXISTS FOR (s:Symbol) ON (s.repo)",
+		"CREATE INDEX symbol_kind IF NOT EXISTS FOR (s:Symbol) ON (s.kind)",
+		"CREATE INDEX symbol_name IF NOT EXISTS FOR (s:Symbol) ON (s.name)",
+		"CREATE INDEX module_repo IF NOT EXISTS FOR (m:Module) ON (m.repo)",
+		"CREATE INDEX graph_version_repo IF NOT EXISTS FOR (v:GraphVersion) ON (v.repo)",
+		"CREATE INDEX doc_chunk_repo IF NOT EXISTS FOR (d:DocChunk) ON (d.repo)",
+	}
+	return constraints, indexes
+}
+
+// isAlreadyExists reports whether a DDL error only means the constraint or
+// index is already present (dialects without IF NOT EXISTS).
+func isAlreadyExists(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "already exists")
+}
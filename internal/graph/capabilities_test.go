@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlavorFromAgent(t *testing.T) {
+	tests := []struct {
+		agent string
+		want  Flavor
+	}{
+		{"Neo4j/5.26.0", FlavorNeo4j},
+		{"Neo4j/v5.11.0 compatible graph database server - Memgraph", FlavorMemgraph},
+		{"Memgraph/2.18", FlavorMemgraph},
+		{"FalkorDB", FlavorUnknown},
+		{"", FlavorUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agent, func(t *testing.T) {
+			assert.Equal(t, tt.want, flavorFromAgent(tt.agent))
+		})
+	}
+}
+
+func TestSchemaStatements(t *testing.T) {
+	constraints, indexes := Capabilities{Flavor: FlavorNeo4j}.schemaStatements()
+	for _, stmt := range append(constraints, indexes...) {
+		assert.Contains(t, stmt, "IF NOT EXISTS")
+	}
+
+	// Unknown Bolt servers get the Neo4j dialect
+	unknownConstraints, _ := Capabilities{Flavor: FlavorUnknown}.schemaStatements()
+	assert.Equal(t, constraints, unknownConstraints)
+
+	mgConstraints, mgIndexes := Capabilities{Flavor: FlavorMemgraph}.schemaStatements()
+	assert.Len(t, mgConstraints, len(constraints))
+	assert.Len(t, mgIndexes, len(indexes))
+	for _, stmt := range mgConstraints {
+		assert.True(t, strings.HasPrefix(stmt, "CREATE CONSTRAINT ON"), stmt)
+		assert.Contains(t, stmt, "ASSERT")
+	}
+	for _, stmt := range mgIndexes {
+		assert.True(t, strings.HasPrefix(stmt, "CREATE INDEX ON :"), stmt)
+	}
+}
+
+func TestIsAlreadyExists(t *testing.T) {
+	assert.True(t, isAlreadyExists(errors.New("Constraint already exists")))
+	assert.False(t, isAlreadyExists(errors.New("syntax error")))
+	assert.False(t, isAlreadyExists(nil))
+}
@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// DocChunk is a section of a doc site page and the code it describes.
+type DocChunk struct {
+	ID          string
+	Repo        string
+	FilePath    string
+	HeadingPath string
+	StartLine   int
+	Symbols     []DocSymbol // Symbols it describes
+	Files       []string    // Repo-relative paths, or path suffixes such as "pkg/mod.py"
+}
+
+// DocSymbol is a symbol a doc chunk describes: one named Name in a file
+// Files names (any file when empty), inside the class Parent when set.
+type DocSymbol struct {
+	Name   string
+	Parent string
+	Files  []string
+}
+
+// ReplaceDocChunks replaces the repo's DocChunk nodes with docs, linking
+// each to what it describes with DESCRIBES edges. A DocSymbol matches the
+// symbols of its name in its files whose span lies within a symbol named
+// Parent of the same file; a path matches the files it equals or ends
+// (after a slash).
+func (s *Neo4jStore) ReplaceDocChunks(ctx context.Context, repo string, docs []DocChunk) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	if _, err := session.Run(ctx, `
+		MATCH (d:DocChunk {repo: $repo})
+		DETACH DELETE d
+	`, map[string]interface{}{"repo": repo}); err != nil {
+		return fmt.Errorf("clear doc chunks: %w", err)
+	}
+
+	for _, doc := range docs {
+		params := map[string]interface{}{
+			"id":           doc.ID,
+			"repo":         repo,
+			"file_path":    doc.FilePath,
+			"heading_path": doc.HeadingPath,
+			"start_line":   doc.StartLine,
+			"symbols":      docSymbolParams(doc.Symbols),
+			"files":        doc.Files,
+		}
+		if _, err := session.Run(ctx, `
+			MERGE (d:DocChunk {id: $id})
+			SET d.repo = $repo,
+			    d.file_path = $file_path,
+			    d.heading_path = $heading_path,
+			    d.start_line = $start_line
+		`, params); err != nil {
+			return fmt.Errorf("store doc chunk %s: %w", doc.FilePath, err)
+		}
+		if len(doc.Symbols) > 0 {
+			if _, err := session.Run(ctx, `
+				UNWIND $symbols AS t
+				MATCH (d:DocChunk {id: $id})
+				MATCH (s:Symbol {repo: $repo, name: t.name})
+				WHERE size(t.files) = 0 OR any(f IN t.files WHERE s.file_path = f OR s.file_path ENDS WITH '/' + f)
+				OPTIONAL MATCH (p:Symbol {repo: $repo, file_path: s.file_path, name: t.parent})
+				WHERE p.start_line <= s.start_line AND p.end_line >= s.end_line
+				WITH d, s, t, p
+				WHERE t.parent = '' OR p IS NOT NULL
+				MERGE (d)-[:DESCRIBES]->(s)
+			`, params); err != nil {
+				return fmt.Errorf("link doc chunk %s: %w", doc.FilePath, err)
+			}
+		}
+		if len(doc.Files) > 0 {
+			if _, err := session.Run(ctx, `
+				MATCH (d:DocChunk {id: $id})
+				MATCH (f:File {repo: $repo})
+				WHERE any(p IN $files WHERE f.path = p OR f.path ENDS WITH '/' + p)
+				MERGE (d)-[:DESCRIBES]->(f)
+			`, params); err != nil {
+				return fmt.Errorf("link doc chunk %s: %w", doc.FilePath, err)
+			}
+		}
+	}
+	return nil
+}
+
+// docSymbolParams converts symbols to Cypher parameter maps. Files is never
+// nil, so size() works on every server.
+func docSymbolParams(symbols []DocSymbol) []map[string]interface{} {
+	params := make([]map[string]interface{}, len(symbols))
+	for i, sym := range symbols {
+		files := sym.Files
+		if files == nil {
+			files = []string{}
+		}
+		params[i] = map[string]interface{}{"name": sym.Name, "parent": sym.Parent, "files": files}
+	}
+	return params
+}
@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// DefaultHierarchyDepth bounds EXTENDS traversal in each direction.
+const DefaultHierarchyDepth = 10
+
+// InheritanceEdge is a Child EXTENDS Parent relationship.
+type InheritanceEdge struct {
+	Child  Symbol
+	Parent Symbol
+}
+
+// HierarchyNode is a class in an inheritance tree. In an ancestor tree the
+// children are base classes; in a descendant tree they are subclasses.
+type HierarchyNode struct {
+	Name      string           `json:"name"`
+	FilePath  string           `json:"file_path"`
+	StartLine int              `json:"start_line"`
+	Children  []*HierarchyNode `json:"children,omitempty"`
+}
+
+// ClassHierarchy is the inheritance tree around one class definition.
+type ClassHierarchy struct {
+	Class       HierarchyNode    `json:"class"`
+	Ancestors   []*HierarchyNode `json:"ancestors"`
+	Descendants []*HierarchyNode `json:"descendants"`
+}
+
+// FindClassHierarchy returns the ancestors and descendants of every class
+// named className in repo, following EXTENDS edges up to maxDepth levels in
+// each direction. Classes defined in several files yield one hierarchy each.
+func (s *Neo4jStore) FindClassHierarchy(ctx context.Context, repo, className string, maxDepth int) ([]ClassHierarchy, error) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultHierarchyDepth
+	}
+
+	roots, err := s.FindSymbolByName(ctx, repo, className)
+	if err != nil {
+		return nil, fmt.Errorf("find class: %w", err)
+	}
+
+	var classes []Symbol
+	for _, sym := range roots {
+		if sym.Kind == "class" {
+			classes = append(classes, sym)
+		}
+	}
+	if len(classes) == 0 {
+		return nil, nil
+	}
+
+	// Variable-length bounds cannot be parameterized; maxDepth is an int.
+	up, err := s.inheritanceEdges(ctx, repo, className,
+		fmt.Sprintf("(root)-[:EXTENDS*1..%d]->(:Symbol)", maxDepth))
+	if err != nil {
+		return nil, fmt.Errorf("find ancestors: %w", err)
+	}
+	down, err := s.inheritanceEdges(ctx, repo, className,
+		fmt.Sprintf("(root)<-[:EXTENDS*1..%d]-(:Symbol)", maxDepth))
+	if err != nil {
+		return nil, fmt.Errorf("find descendants: %w", err)
+	}
+
+	return BuildClassHierarchies(classes, append(up, down...)), nil
+}
+
+// inheritanceEdges returns every EXTENDS edge on paths matching pattern, where
+// root is bound to the classes named className.
+func (s *Neo4jStore) inheritanceEdges(ctx context.Context, repo, className, pattern string) ([]InheritanceEdge, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (root:Symbol {repo: $repo, name: $name, kind: 'class'})
+		MATCH p = `+pattern+`
+		UNWIND relationships(p) AS r
+		WITH DISTINCT r
+		WITH startNode(r) AS child, endNode(r) AS parent
+		RETURN child.name, child.kind, child.file_path, child.start_line, child.end_line,
+		       parent.name, parent.kind, parent.file_path, parent.start_line, parent.end_line
+	`, map[string]interface{}{
+		"repo": repo,
+		"name": className,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var edges []InheritanceEdge
+	for result.Next(ctx) {
+		record := result.Record()
+		edges = append(edges, InheritanceEdge{
+			Child: Symbol{
+				Name:      getString(record, "child.name"),
+				Kind:      getString(record, "child.kind"),
+				Repo:      repo,
+				FilePath:  getString(record, "child.file_path"),
+				StartLine: getInt(record, "child.start_line"),
+				EndLine:   getInt(record, "child.end_line"),
+			},
+			Parent: Symbol{
+				Name:      getString(record, "parent.name"),
+				Kind:      getString(record, "parent.kind"),
+				Repo:      repo,
+				FilePath:  getString(record, "parent.file_path"),
+				StartLine: getInt(record, "parent.start_line"),
+				EndLine:   getInt(record, "parent.end_line"),
+			},
+		})
+	}
+
+	return edges, result.Err()
+}
+
+// BuildClassHierarchies assembles ancestor and descendant trees for each class
+// from a flat list of inheritance edges. Cycles are cut at the repeated class.
+func BuildClassHierarchies(classes []Symbol, edges []InheritanceEdge) []ClassHierarchy {
+	parentsOf := make(map[string][]Symbol)
+	childrenOf := make(map[string][]Symbol)
+	seenEdge := make(map[string]bool)
+
+	for _, e := range edges {
+		edgeKey := symbolKey(e.Child) + "->" + symbolKey(e.Parent)
+		if seenEdge[edgeKey] {
+			continue
+		}
+		seenEdge[edgeKey] = true
+		parentsOf[symbolKey(e.Child)] = append(parentsOf[symbolKey(e.Child)], e.Parent)
+		childrenOf[symbolKey(e.Parent)] = append(childrenOf[symbolKey(e.Parent)], e.Child)
+	}
+
+	hierarchies := make([]ClassHierarchy, len(classes))
+	for i, c := range classes {
+		hierarchies[i] = ClassHierarchy{
+			Class:       HierarchyNode{Name: c.Name, FilePath: c.FilePath, StartLine: c.StartLine},
+			Ancestors:   buildHierarchyTree(c, parentsOf, map[string]bool{symbolKey(c): true}),
+			Descendants: buildHierarchyTree(c, childrenOf, map[string]bool{symbolKey(c): true}),
+		}
+	}
+
+	return hierarchies
+}
+
+func buildHierarchyTree(sym Symbol, next map[string][]Symbol, onPath map[string]bool) []*HierarchyNode {
+	var nodes []*HierarchyNode
+	for _, n := range next[symbolKey(sym)] {
+		key := symbolKey(n)
+		node := &HierarchyNode{Name: n.Name, FilePath: n.FilePath, StartLine: n.StartLine}
+		if !onPath[key] {
+			onPath[key] = true
+			node.Children = buildHierarchyTree(n, next, onPath)
+			delete(onPath, key)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func symbolKey(s Symbol) string {
+	return fmt.Sprintf("%s:%s:%d", s.FilePath, s.Name, s.StartLine)
+}
@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildClassHierarchies(t *testing.T) {
+	base := Symbol{Name: "Base", Kind: "class", FilePath: "base.py", StartLine: 1}
+	importer := Symbol{Name: "BaseImporter", Kind: "class", FilePath: "importer.py", StartLine: 3}
+	csv := Symbol{Name: "CSVImporter", Kind: "class", FilePath: "csv.py", StartLine: 5}
+	excel := Symbol{Name: "ExcelImporter", Kind: "class", FilePath: "excel.py", StartLine: 7}
+	xlsx := Symbol{Name: "XLSXImporter", Kind: "class", FilePath: "excel.py", StartLine: 40}
+
+	edges := []InheritanceEdge{
+		{Child: importer, Parent: base},
+		{Child: csv, Parent: importer},
+		{Child: excel, Parent: importer},
+		{Child: xlsx, Parent: excel},
+		{Child: csv, Parent: importer}, // duplicate from overlapping paths
+	}
+
+	hierarchies := BuildClassHierarchies([]Symbol{importer}, edges)
+
+	require.Len(t, hierarchies, 1)
+	h := hierarchies[0]
+	assert.Equal(t, "BaseImporter", h.Class.Name)
+
+	require.Len(t, h.Ancestors, 1)
+	assert.Equal(t, "Base", h.Ancestors[0].Name)
+	assert.Empty(t, h.Ancestors[0].Children)
+
+	require.Len(t, h.Descendants, 2)
+	assert.Equal(t, "CSVImporter", h.Descendants[0].Name)
+	assert.Equal(t, "ExcelImporter", h.Descendants[1].Name)
+	require.Len(t, h.Descendants[1].Children, 1)
+	assert.Equal(t, "XLSXImporter", h.Descendants[1].Children[0].Name)
+	assert.Equal(t, 40, h.Descendants[1].Children[0].StartLine)
+}
+
+func TestBuildClassHierarchiesCycle(t *testing.T) {
+	a := Symbol{Name: "A", Kind: "class", FilePath: "a.py", StartLine: 1}
+	b := Symbol{Name: "B", Kind: "class", FilePath: "b.py", StartLine: 1}
+
+	hierarchies := BuildClassHierarchies([]Symbol{a}, []InheritanceEdge{
+		{Child: a, Parent: b},
+		{Child: b, Parent: a},
+	})
+
+	require.Len(t, hierarchies, 1)
+	require.Len(t, hierarchies[0].Ancestors, 1)
+	assert.Equal(t, "B", hierarchies[0].Ancestors[0].Name)
+	require.Len(t, hierarchies[0].Ancestors[0].Children, 1)
+	assert.Equal(t, "A", hierarchies[0].Ancestors[0].Children[0].Name)
+	assert.Empty(t, hierarchies[0].Ancestors[0].Children[0].Children)
+}
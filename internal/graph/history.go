@@ -0,0 +1,248 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// NodeGraphVersion stores the edge set of a repository at one index run.
+const NodeGraphVersion = "GraphVersion"
+
+// GraphVersion identifies a recorded edge set. IndexedAt distinguishes runs
+// at the same commit (e.g. with a dirty working tree).
+type GraphVersion struct {
+	Repo      string
+	Commit    string
+	IndexedAt time.Time
+	EdgeCount int
+}
+
+// RecordVersion snapshots the repository's current IMPORTS, CALLS, and EXTENDS
+// edges as a GraphVersion, tags File and Symbol nodes and edges not seen
+// before with commit, and prunes all but the newest keep versions. keep <= 0
+// only tags.
+func (s *Neo4jStore) RecordVersion(ctx context.Context, repo, commit string, indexedAt time.Time, keep int) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	params := map[string]interface{}{
+		"repo":       repo,
+		"commit":     commit,
+		"indexed_at": indexedAt.Unix(),
+	}
+	// Each pattern is label-qualified so the repo lookup uses the indexes
+	// instead of scanning every node
+	tags := []struct{ what, query string }{
+		{"files", `
+			MATCH (n:File {repo: $repo})
+			WHERE n.first_seen_at IS NULL
+			SET n.first_seen_commit = $commit, n.first_seen_at = $indexed_at
+		`},
+		{"symbols", `
+			MATCH (n:Symbol {repo: $repo})
+			WHERE n.first_seen_at IS NULL
+			SET n.first_seen_commit = $commit, n.first_seen_at = $indexed_at
+		`},
+		{"import edges", `
+			MATCH (:File {repo: $repo})-[r:IMPORTS]->(:File {repo: $repo})
+			WHERE r.first_seen_at IS NULL
+			SET r.first_seen_commit = $commit, r.first_seen_at = $indexed_at
+		`},
+		{"call edges", `
+			MATCH (:Symbol {repo: $repo})-[r:CALLS|EXTENDS]->(:Symbol {repo: $repo})
+			WHERE r.first_seen_at IS NULL
+			SET r.first_seen_commit = $commit, r.first_seen_at = $indexed_at
+		`},
+	}
+	for _, tag := range tags {
+		if _, err := session.Run(ctx, tag.query, params); err != nil {
+			return fmt.Errorf("tag new %s: %w", tag.what, err)
+		}
+	}
+
+	if keep <= 0 {
+		return nil
+	}
+
+	edges, err := exportEdges(ctx, session, repo)
+	if err != nil {
+		return err
+	}
+	encoded := make([]string, len(edges))
+	for i, e := range edges {
+		encoded[i] = encodeEdge(e)
+	}
+
+	_, err = session.Run(ctx, `
+		CREATE (:GraphVersion {repo: $repo, commit: $commit, indexed_at: $indexed_at, edges: $edges})
+	`, map[string]interface{}{
+		"repo":       repo,
+		"commit":     commit,
+		"indexed_at": indexedAt.Unix(),
+		"edges":      encoded,
+	})
+	if err != nil {
+		return fmt.Errorf("store graph version: %w", err)
+	}
+
+	_, err = session.Run(ctx, `
+		MATCH (v:GraphVersion {repo: $repo})
+		WITH v ORDER BY v.indexed_at DESC
+		SKIP $keep
+		DELETE v
+	`, map[string]interface{}{
+		"repo": repo,
+		"keep": keep,
+	})
+	if err != nil {
+		return fmt.Errorf("prune graph versions: %w", err)
+	}
+
+	return nil
+}
+
+// ListVersions returns the recorded graph versions of a repository, newest first.
+func (s *Neo4jStore) ListVersions(ctx context.Context, repo string) ([]GraphVersion, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (v:GraphVersion {repo: $repo})
+		RETURN v.commit, v.indexed_at, size(v.edges) AS edge_count
+		ORDER BY v.indexed_at DESC
+	`, map[string]interface{}{"repo": repo})
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []GraphVersion
+	for result.Next(ctx) {
+		record := result.Record()
+		versions = append(versions, GraphVersion{
+			Repo:      repo,
+			Commit:    getString(record, "v.commit"),
+			IndexedAt: time.Unix(getInt64(record, "v.indexed_at"), 0),
+			EdgeCount: getInt(record, "edge_count"),
+		})
+	}
+
+	return versions, nil
+}
+
+// VersionEdges returns the edge set recorded by a graph version.
+func (s *Neo4jStore) VersionEdges(ctx context.Context, version GraphVersion) ([]Edge, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (v:GraphVersion {repo: $repo, indexed_at: $indexed_at})
+		RETURN v.edges
+		LIMIT 1
+	`, map[string]interface{}{
+		"repo":       version.Repo,
+		"indexed_at": version.IndexedAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Next(ctx) {
+		return nil, fmt.Errorf("graph version %s@%s not found", version.Repo, version.IndexedAt.Format(time.RFC3339))
+	}
+
+	raw, _ := result.Record().Get("v.edges")
+	values, _ := raw.([]interface{})
+
+	edges := make([]Edge, 0, len(values))
+	for _, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		e, err := decodeEdge(str)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+
+	return edges, nil
+}
+
+// DiffEdges returns edges present only in newer (added) and only in older
+// (removed). Symbol edges are compared by file and name so that code moving
+// within a file is not reported as a change.
+func DiffEdges(older, newer []Edge) (added, removed []Edge) {
+	oldKeys := make(map[string]bool, len(older))
+	for _, e := range older {
+		oldKeys[edgeIdentity(e)] = true
+	}
+	newKeys := make(map[string]bool, len(newer))
+	for _, e := range newer {
+		newKeys[edgeIdentity(e)] = true
+	}
+
+	for _, e := range newer {
+		if !oldKeys[edgeIdentity(e)] {
+			added = append(added, e)
+		}
+	}
+	for _, e := range older {
+		if !newKeys[edgeIdentity(e)] {
+			removed = append(removed, e)
+		}
+	}
+
+	sortEdges(added)
+	sortEdges(removed)
+	return added, removed
+}
+
+func edgeIdentity(e Edge) string {
+	return strings.Join([]string{e.Type, e.SourceFile, e.SourceName, e.TargetFile, e.TargetName}, "\t")
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		return edgeIdentity(edges[i]) < edgeIdentity(edges[j])
+	})
+}
+
+// encodeEdge packs an edge into a tab-separated string for storage as a
+// list property; Neo4j properties cannot hold maps.
+func encodeEdge(e Edge) string {
+	return strings.Join([]string{
+		e.Type,
+		e.SourceFile, e.SourceName, strconv.Itoa(e.SourceLine),
+		e.TargetFile, e.TargetName, strconv.Itoa(e.TargetLine),
+	}, "\t")
+}
+
+func decodeEdge(s string) (Edge, error) {
+	parts := strings.Split(s, "\t")
+	if len(parts) != 7 {
+		return Edge{}, fmt.Errorf("malformed edge %q", s)
+	}
+	sourceLine, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return Edge{}, fmt.Errorf("malformed edge %q: %w", s, err)
+	}
+	targetLine, err := strconv.Atoi(parts[6])
+	if err != nil {
+		return Edge{}, fmt.Errorf("malformed edge %q: %w", s, err)
+	}
+	return Edge{
+		Type:       parts[0],
+		SourceFile: parts[1],
+		SourceName: parts[2],
+		SourceLine: sourceLine,
+		TargetFile: parts[4],
+		TargetName: parts[5],
+		TargetLine: targetLine,
+	}, nil
+}
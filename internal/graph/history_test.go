@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeEdge(t *testing.T) {
+	edges := []Edge{
+		{Type: RelImports, SourceFile: "a.py", TargetFile: "b.py"},
+		{Type: RelCalls, SourceFile: "a.py", SourceName: "run", SourceLine: 10, TargetFile: "b.py", TargetName: "load", TargetLine: 3},
+	}
+
+	for _, e := range edges {
+		decoded, err := decodeEdge(encodeEdge(e))
+		require.NoError(t, err)
+		assert.Equal(t, e, decoded)
+	}
+
+	_, err := decodeEdge("CALLS\ta.py")
+	assert.Error(t, err)
+}
+
+func TestDiffEdges(t *testing.T) {
+	older := []Edge{
+		{Type: RelImports, SourceFile: "a.py", TargetFile: "b.py"},
+		{Type: RelCalls, SourceFile: "a.py", SourceName: "run", SourceLine: 10, TargetFile: "b.py", TargetName: "load", TargetLine: 3},
+	}
+	newer := []Edge{
+		// Same call, moved down the file: not a change
+		{Type: RelCalls, SourceFile: "a.py", SourceName: "run", SourceLine: 25, TargetFile: "b.py", TargetName: "load", TargetLine: 3},
+		{Type: RelImports, SourceFile: "a.py", TargetFile: "c.py"},
+	}
+
+	added, removed := DiffEdges(older, newer)
+
+	require.Len(t, added, 1)
+	assert.Equal(t, "c.py", added[0].TargetFile)
+	require.Len(t, removed, 1)
+	assert.Equal(t, "b.py", removed[0].TargetFile)
+	assert.Equal(t, RelImports, removed[0].Type)
+}
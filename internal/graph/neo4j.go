@@ -4,6 +4,7 @@ package graph
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
@@ -12,6 +13,7 @@ import (
 // Neo4jStore handles graph storage in Neo4j.
 type Neo4jStore struct {
 	driver neo4j.DriverWithContext
+	caps   Capabilities
 }
 
 // Node types in the graph
@@ -42,6 +44,11 @@ const (
 type Repository struct {
 	Name string
 	Path string
+
+	// State of the most recent index run
+	IndexedCommit string
+	IndexedBranch string // Empty when HEAD was detached
+	IndexedAt     time.Time
 }
 
 // Module represents a module within a repository.
@@ -59,6 +66,15 @@ type File struct {
 	ModuleRoot  string
 	Hash        string
 	LastIndexed time.Time
+
+	// Last commit touching the file (from git log)
+	LastAuthor      string
+	LastAuthorEmail string
+	LastCommit      string
+	LastModified    time.Time
+
+	// Owners from the repo's CODEOWNERS file
+	Owners []string
 }
 
 // Symbol represents a code symbol (function, class, method).
@@ -104,7 +120,15 @@ func NewNeo4jStore(uri, username, password string) (*Neo4jStore, error) {
 		return nil, fmt.Errorf("failed to connect to Neo4j: %w", err)
 	}
 
-	return &Neo4jStore{driver: driver}, nil
+	return &Neo4jStore{
+		driver: driver,
+		caps:   detectCapabilities(ctx, driver),
+	}, nil
+}
+
+// Capabilities returns what the connected server supports.
+func (s *Neo4jStore) Capabilities() Capabilities {
+	return s.caps
 }
 
 // Close closes the Neo4j driver.
@@ -112,42 +136,23 @@ func (s *Neo4jStore) Close(ctx context.Context) error {
 	return s.driver.Close(ctx)
 }
 
-// EnsureSchema creates indexes and constraints.
+// EnsureSchema creates indexes and constraints using the server's dialect.
 func (s *Neo4jStore) EnsureSchema(ctx context.Context) error {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
 	defer session.Close(ctx)
 
-	constraints := []string{
-		// Unique constraints
-		"CREATE CONSTRAINT repo_name IF NOT EXISTS FOR (r:Repository) REQUIRE r.name IS UNIQUE",
-		"CREATE CONSTRAINT file_path IF NOT EXISTS FOR (f:File) REQUIRE (f.repo, f.path) IS UNIQUE",
-		"CREATE CONSTRAINT symbol_id IF NOT EXISTS FOR (s:Symbol) REQUIRE (s.repo, s.file_path, s.name, s.start_line) IS UNIQUE",
-		"CREATE CONSTRAINT module_path IF NOT EXISTS FOR (m:Module) REQUIRE (m.repo, m.path) IS UNIQUE",
-		"CREATE CONSTRAINT pattern_name IF NOT EXISTS FOR (p:Pattern) REQUIRE (p.module, p.name) IS UNIQUE",
-	}
-
-	indexes := []string{
-		// Performance indexes
-		"CREATE INDEX file_repo IF NOT EXISTS FOR (f:File) ON (f.repo)",
-		"CREATE INDEX file_hash IF NOT EXISTS FOR (f:File) ON (f.hash)",
-		"CREATE INDEX symbol_repo IF NOT EXISTS FOR (s:Symbol) ON (s.repo)",
-		"CREATE INDEX symbol_kind IF NOT EXISTS FOR (s:Symbol) ON (s.kind)",
-		"CREATE INDEX symbol_name IF NOT EXISTS FOR (s:Symbol) ON (s.name)",
-		"CREATE INDEX module_repo IF NOT EXISTS FOR (m:Module) ON (m.repo)",
-	}
+	constraints, indexes := s.caps.schemaStatements()
 
 	// Create constraints
 	for _, constraint := range constraints {
-		_, err := session.Run(ctx, constraint, nil)
-		if err != nil {
+		if err := runSchemaStatement(ctx, session, constraint); err != nil {
 			return fmt.Errorf("failed to create constraint: %w", err)
 		}
 	}
 
 	// Create indexes
 	for _, index := range indexes {
-		_, err := session.Run(ctx, index, nil)
-		if err != nil {
+		if err := runSchemaStatement(ctx, session, index); err != nil {
 			return fmt.Errorf("failed to create index: %w", err)
 		}
 	}
@@ -155,6 +160,17 @@ func (s *Neo4jStore) EnsureSchema(ctx context.Context) error {
 	return nil
 }
 
+func runSchemaStatement(ctx context.Context, session neo4j.SessionWithContext, statement string) error {
+	result, err := session.Run(ctx, statement, nil)
+	if err == nil {
+		_, err = result.Consume(ctx)
+	}
+	if isAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
 // UpsertRepository creates or updates a repository node.
 func (s *Neo4jStore) UpsertRepository(ctx context.Context, repo Repository) error {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
@@ -162,15 +178,77 @@ func (s *Neo4jStore) UpsertRepository(ctx context.Context, repo Repository) erro
 
 	_, err := session.Run(ctx, `
 		MERGE (r:Repository {name: $name})
-		SET r.path = $path
+		SET r.path = $path,
+		    r.indexed_commit = $indexed_commit,
+		    r.indexed_branch = $indexed_branch,
+		    r.indexed_at = $indexed_at
 	`, map[string]interface{}{
-		"name": repo.Name,
-		"path": repo.Path,
+		"name":           repo.Name,
+		"path":           repo.Path,
+		"indexed_commit": repo.IndexedCommit,
+		"indexed_branch": repo.IndexedBranch,
+		"indexed_at":     unixOrZero(repo.IndexedAt),
 	})
 
 	return err
 }
 
+// ListRepositories returns all repository nodes ordered by name.
+func (s *Neo4jStore) ListRepositories(ctx context.Context) ([]Repository, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (r:Repository)
+		RETURN r.name, r.path, r.indexed_commit, r.indexed_branch, r.indexed_at
+		ORDER BY r.name
+	`, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []Repository
+	for result.Next(ctx) {
+		record := result.Record()
+		repos = append(repos, Repository{
+			Name:          getString(record, "r.name"),
+			Path:          getString(record, "r.path"),
+			IndexedCommit: getString(record, "r.indexed_commit"),
+			IndexedBranch: getString(record, "r.indexed_branch"),
+			IndexedAt:     timeOrZero(getInt64(record, "r.indexed_at")),
+		})
+	}
+
+	return repos, nil
+}
+
+// GetRepository returns a repository node, or nil if it does not exist.
+func (s *Neo4jStore) GetRepository(ctx context.Context, name string) (*Repository, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (r:Repository {name: $name})
+		RETURN r.path, r.indexed_commit, r.indexed_branch, r.indexed_at
+	`, map[string]interface{}{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Next(ctx) {
+		return nil, result.Err()
+	}
+
+	record := result.Record()
+	return &Repository{
+		Name:          name,
+		Path:          getString(record, "r.path"),
+		IndexedCommit: getString(record, "r.indexed_commit"),
+		IndexedBranch: getString(record, "r.indexed_branch"),
+		IndexedAt:     timeOrZero(getInt64(record, "r.indexed_at")),
+	}, nil
+}
+
 // UpsertModule creates or updates a module node.
 func (s *Neo4jStore) UpsertModule(ctx context.Context, module Module) error {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
@@ -201,13 +279,23 @@ func (s *Neo4jStore) UpsertFile(ctx context.Context, file File) error {
 		MERGE (f:File {repo: $repo, path: $path})
 		SET f.module_root = $module_root,
 		    f.hash = $hash,
-		    f.last_indexed = $last_indexed
+		    f.last_indexed = $last_indexed,
+		    f.last_author = $last_author,
+		    f.last_author_email = $last_author_email,
+		    f.last_commit = $last_commit,
+		    f.last_modified = $last_modified,
+		    f.owners = $owners
 	`, map[string]interface{}{
-		"repo":         file.Repo,
-		"path":         file.Path,
-		"module_root":  file.ModuleRoot,
-		"hash":         file.Hash,
-		"last_indexed": file.LastIndexed.Unix(),
+		"repo":              file.Repo,
+		"path":              file.Path,
+		"module_root":       file.ModuleRoot,
+		"hash":              file.Hash,
+		"last_indexed":      file.LastIndexed.Unix(),
+		"last_author":       file.LastAuthor,
+		"last_author_email": file.LastAuthorEmail,
+		"last_commit":       file.LastCommit,
+		"last_modified":     unixOrZero(file.LastModified),
+		"owners":            file.Owners,
 	})
 
 	return err
@@ -435,6 +523,33 @@ func (s *Neo4jStore) GetFileHash(ctx context.Context, repo, path string) (string
 	return "", nil
 }
 
+// FileModuleRoots returns the module root of each of paths the graph knows,
+// keyed by path.
+func (s *Neo4jStore) FileModuleRoots(ctx context.Context, repo string, paths []string) (map[string]string, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (f:File {repo: $repo})
+		WHERE f.path IN $paths
+		RETURN f.path, f.module_root
+	`, map[string]interface{}{
+		"repo":  repo,
+		"paths": paths,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make(map[string]string, len(paths))
+	for result.Next(ctx) {
+		record := result.Record()
+		roots[getString(record, "f.path")] = getString(record, "f.module_root")
+	}
+
+	return roots, result.Err()
+}
+
 // FindSymbolByName finds symbols matching a name.
 func (s *Neo4jStore) FindSymbolByName(ctx context.Context, repo, name string) ([]Symbol, error) {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
@@ -576,8 +691,251 @@ func (s *Neo4jStore) FindRelatedFiles(ctx context.Context, repo, filePath string
 	return files, nil
 }
 
+// FindFileOwners returns the file at path or the files under it as a
+// directory, including their last-commit ownership metadata, most recently
+// modified first. A non-nil modules keeps files whose module root is one of
+// them or nested in one ("billing" keeps "billing.tax"), before the limit.
+func (s *Neo4jStore) FindFileOwners(ctx context.Context, repo, path string, modules []string, limit int) ([]File, error) {
+	var moduleParam interface{} // Null, not an empty list, without a limit
+	if modules != nil {
+		moduleParam = modules
+	}
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (f:File {repo: $repo})
+		WHERE (f.path = $path OR f.path STARTS WITH $path + '/')
+		  AND ($modules IS NULL OR any(m IN $modules WHERE f.module_root = m
+		       OR f.module_root STARTS WITH m + '/' OR f.module_root STARTS WITH m + '.'))
+		RETURN f.path, f.module_root, f.hash, f.last_indexed,
+		       f.last_author, f.last_author_email, f.last_commit, f.last_modified, f.owners
+		ORDER BY f.last_modified DESC
+		LIMIT $limit
+	`, map[string]interface{}{
+		"repo":    repo,
+		"path":    strings.TrimSuffix(path, "/"),
+		"modules": moduleParam,
+		"limit":   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var files []File
+	for result.Next(ctx) {
+		files = append(files, recordToFile(result.Record(), repo))
+	}
+
+	return files, nil
+}
+
+// ModuleSummary is a module node with the number of files under its root.
+type ModuleSummary struct {
+	Module
+	FileCount int
+}
+
+// RankedSymbol is a symbol with the number of symbols that call it.
+type RankedSymbol struct {
+	Symbol
+	CallerCount int
+}
+
+// ListModules returns a repository's modules with file counts, ordered by path.
+func (s *Neo4jStore) ListModules(ctx context.Context, repo string) ([]ModuleSummary, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (m:Module {repo: $repo})
+		OPTIONAL MATCH (f:File {repo: $repo, module_root: m.path})
+		RETURN m.path, m.fs_path, m.description, count(f) AS files
+		ORDER BY m.path
+	`, map[string]interface{}{"repo": repo})
+	if err != nil {
+		return nil, err
+	}
+
+	var modules []ModuleSummary
+	for result.Next(ctx) {
+		record := result.Record()
+		modules = append(modules, ModuleSummary{
+			Module: Module{
+				Repo:        repo,
+				Path:        getString(record, "m.path"),
+				FSPath:      getString(record, "m.fs_path"),
+				Description: getString(record, "m.description"),
+			},
+			FileCount: getInt(record, "files"),
+		})
+	}
+
+	return modules, nil
+}
+
+// ListModuleFiles returns the paths of files under a module root.
+func (s *Neo4jStore) ListModuleFiles(ctx context.Context, repo, moduleRoot string) ([]string, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (f:File {repo: $repo, module_root: $module})
+		RETURN f.path
+		ORDER BY f.path
+	`, map[string]interface{}{
+		"repo":   repo,
+		"module": moduleRoot,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for result.Next(ctx) {
+		paths = append(paths, getString(result.Record(), "f.path"))
+	}
+
+	return paths, nil
+}
+
+// TopModuleSymbols returns the symbols in a module's files ranked by how many
+// symbols call them, so the module's most central API comes first.
+func (s *Neo4jStore) TopModuleSymbols(ctx context.Context, repo, moduleRoot string, limit int) ([]RankedSymbol, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (f:File {repo: $repo, module_root: $module})-[:CONTAINS]->(s:Symbol)
+		OPTIONAL MATCH (caller:Symbol)-[:CALLS]->(s)
+		WITH s, count(caller) AS callers
+		RETURN s.name, s.kind, s.file_path, s.start_line, s.end_line, s.signature, callers
+		ORDER BY callers DESC, s.file_path, s.start_line
+		LIMIT $limit
+	`, map[string]interface{}{
+		"repo":   repo,
+		"module": moduleRoot,
+		"limit":  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []RankedSymbol
+	for result.Next(ctx) {
+		record := result.Record()
+		symbols = append(symbols, RankedSymbol{
+			Symbol: Symbol{
+				Name:      getString(record, "s.name"),
+				Kind:      getString(record, "s.kind"),
+				Repo:      repo,
+				FilePath:  getString(record, "s.file_path"),
+				StartLine: getInt(record, "s.start_line"),
+				EndLine:   getInt(record, "s.end_line"),
+				Signature: getString(record, "s.signature"),
+			},
+			CallerCount: getInt(record, "callers"),
+		})
+	}
+
+	return symbols, nil
+}
+
+// ModuleDependency counts file imports between the queried module and another.
+type ModuleDependency struct {
+	Module  string
+	Imports int
+}
+
+// ModuleDependencies returns the modules a module imports from (outbound) and
+// the modules that import it (inbound), by number of file-level imports.
+func (s *Neo4jStore) ModuleDependencies(ctx context.Context, repo, moduleRoot string) (outbound, inbound []ModuleDependency, err error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	params := map[string]interface{}{
+		"repo":   repo,
+		"module": moduleRoot,
+	}
+
+	outbound, err = collectModuleDependencies(ctx, session, `
+		MATCH (:File {repo: $repo, module_root: $module})-[:IMPORTS]->(t:File {repo: $repo})
+		WHERE t.module_root <> $module
+		RETURN t.module_root AS module, count(*) AS imports
+		ORDER BY imports DESC, module
+	`, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("outbound dependencies: %w", err)
+	}
+
+	inbound, err = collectModuleDependencies(ctx, session, `
+		MATCH (s:File {repo: $repo})-[:IMPORTS]->(:File {repo: $repo, module_root: $module})
+		WHERE s.module_root <> $module
+		RETURN s.module_root AS module, count(*) AS imports
+		ORDER BY imports DESC, module
+	`, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("inbound dependencies: %w", err)
+	}
+
+	return outbound, inbound, nil
+}
+
+func collectModuleDependencies(ctx context.Context, session neo4j.SessionWithContext, query string, params map[string]interface{}) ([]ModuleDependency, error) {
+	result, err := session.Run(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []ModuleDependency
+	for result.Next(ctx) {
+		record := result.Record()
+		deps = append(deps, ModuleDependency{
+			Module:  getString(record, "module"),
+			Imports: getInt(record, "imports"),
+		})
+	}
+
+	return deps, nil
+}
+
+// recordToFile converts a record with f.* columns into a File.
+func recordToFile(record *neo4j.Record, repo string) File {
+	return File{
+		Path:            getString(record, "f.path"),
+		Repo:            repo,
+		ModuleRoot:      getString(record, "f.module_root"),
+		Hash:            getString(record, "f.hash"),
+		LastIndexed:     time.Unix(getInt64(record, "f.last_indexed"), 0),
+		LastAuthor:      getString(record, "f.last_author"),
+		LastAuthorEmail: getString(record, "f.last_author_email"),
+		LastCommit:      getString(record, "f.last_commit"),
+		LastModified:    timeOrZero(getInt64(record, "f.last_modified")),
+		Owners:          getStrings(record, "f.owners"),
+	}
+}
+
+// unixOrZero stores unset times as 0 rather than a large negative epoch.
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func timeOrZero(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
 // ExpandFromSymbols returns related symbols via graph traversal.
 func (s *Neo4jStore) ExpandFromSymbols(ctx context.Context, repo string, symbolNames []string, depth int, limit int) ([]Symbol, error) {
+	if !s.caps.APOC {
+		return s.expandFromSymbolsBasic(ctx, repo, symbolNames, limit)
+	}
+
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
 	defer session.Close(ctx)
 
@@ -664,7 +1022,9 @@ func (s *Neo4jStore) expandFromSymbolsBasic(ctx context.Context, repo string, sy
 	return symbols, nil
 }
 
-// DeleteRepository removes a repository and all its related nodes.
+// DeleteRepository removes a repository and all its related nodes. File,
+// Symbol, and Module nodes are matched by their repo property since files
+// are not linked to the Repository node.
 func (s *Neo4jStore) DeleteRepository(ctx context.Context, repoName string) error {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
 	defer session.Close(ctx)
@@ -676,6 +1036,28 @@ func (s *Neo4jStore) DeleteRepository(ctx context.Context, repoName string) erro
 	`, map[string]interface{}{
 		"name": repoName,
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, label := range []string{"Symbol", "File", "Module", "DocChunk"} {
+		_, err = session.Run(ctx, `
+			MATCH (n:`+label+` {repo: $name})
+			DETACH DELETE n
+		`, map[string]interface{}{
+			"name": repoName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = session.Run(ctx, `
+		MATCH (v:GraphVersion {repo: $name})
+		DELETE v
+	`, map[string]interface{}{
+		"name": repoName,
+	})
 
 	return err
 }
@@ -697,6 +1079,27 @@ func (s *Neo4jStore) DeleteFile(ctx context.Context, repo, path string) error {
 	return err
 }
 
+// RenameFile moves a file node and its symbols to a new path, keeping every
+// edge to and from them. Use it when a file moved without changing content.
+func (s *Neo4jStore) RenameFile(ctx context.Context, repo, oldPath, newPath string) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	_, err := session.Run(ctx, `
+		MATCH (f:File {repo: $repo, path: $old_path})
+		SET f.path = $new_path
+		WITH f
+		OPTIONAL MATCH (f)-[:CONTAINS]->(s:Symbol)
+		SET s.file_path = $new_path
+	`, map[string]interface{}{
+		"repo":     repo,
+		"old_path": oldPath,
+		"new_path": newPath,
+	})
+
+	return err
+}
+
 // GetAllFileHashes returns all file hashes for a repository.
 func (s *Neo4jStore) GetAllFileHashes(ctx context.Context, repo string) (map[string]string, error) {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
@@ -752,6 +1155,21 @@ func getInt(record *neo4j.Record, key string) int {
 	}
 }
 
+func getStrings(record *neo4j.Record, key string) []string {
+	val, ok := record.Get(key)
+	if !ok || val == nil {
+		return nil
+	}
+	list, _ := val.([]interface{})
+	var strs []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
 func getInt64(record *neo4j.Record, key string) int64 {
 	val, ok := record.Get(key)
 	if !ok || val == nil {
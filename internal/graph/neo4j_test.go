@@ -138,6 +138,20 @@ func TestNeo4jStore_Integration(t *testing.T) {
 		assert.Equal(t, "validateInput", callees[0].Name)
 	})
 
+	t.Run("FindCallPaths", func(t *testing.T) {
+		paths, err := store.FindCallPaths(ctx, "test-repo", []string{"processData"}, []string{"validateInput"}, 6, 5)
+		assert.NoError(t, err)
+		require.Len(t, paths, 1)
+		require.Len(t, paths[0], 2)
+		assert.Equal(t, "processData", paths[0][0].Name)
+		assert.Equal(t, "validateInput", paths[0][1].Name)
+
+		paths, err = store.FindCallPaths(ctx, "test-repo", nil, []string{"validateInput"}, 6, 5)
+		assert.NoError(t, err)
+		require.Len(t, paths, 1)
+		assert.Equal(t, "processData", paths[0][0].Name, "chains start at an entry point")
+	})
+
 	// Test related files
 	t.Run("FindRelatedFiles", func(t *testing.T) {
 		// Add another file that imports
@@ -158,6 +172,29 @@ func TestNeo4jStore_Integration(t *testing.T) {
 		assert.GreaterOrEqual(t, len(related), 1)
 	})
 
+	t.Run("ModuleDependencies", func(t *testing.T) {
+		err := store.UpsertFile(ctx, File{
+			Path:        "api/routes.py",
+			Repo:        "test-repo",
+			ModuleRoot:  "api",
+			Hash:        "ghi789",
+			LastIndexed: time.Now(),
+		})
+		require.NoError(t, err)
+
+		err = store.CreateImportRelationship(ctx, "test-repo", "api/routes.py", "core/utils/helpers.py")
+		require.NoError(t, err)
+
+		outbound, inbound, err := store.ModuleDependencies(ctx, "test-repo", "core")
+		assert.NoError(t, err)
+		assert.Empty(t, outbound, "imports within core are not dependencies")
+		assert.Equal(t, []ModuleDependency{{Module: "api", Imports: 1}}, inbound)
+
+		outbound, _, err = store.ModuleDependencies(ctx, "test-repo", "api")
+		assert.NoError(t, err)
+		assert.Equal(t, []ModuleDependency{{Module: "core", Imports: 1}}, outbound)
+	})
+
 	// Test GetAllFileHashes
 	t.Run("GetAllFileHashes", func(t *testing.T) {
 		hashes, err := store.GetAllFileHashes(ctx, "test-repo")
@@ -170,6 +207,10 @@ func TestNeo4jStore_Integration(t *testing.T) {
 	t.Run("DeleteRepository", func(t *testing.T) {
 		err := store.DeleteRepository(ctx, "test-repo")
 		assert.NoError(t, err)
+
+		hashes, err := store.GetAllFileHashes(ctx, "test-repo")
+		assert.NoError(t, err)
+		assert.Empty(t, hashes, "files are deleted with the repository")
 	})
 }
 
@@ -0,0 +1,256 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// RepoSnapshot is a portable copy of a repository's files, symbols, and edges.
+type RepoSnapshot struct {
+	Repo    string
+	Files   []File
+	Symbols []Symbol
+	Edges   []Edge
+}
+
+// Edge is a relationship between two nodes of the same repository.
+// File edges are keyed by path; symbol edges by file path, name, and start line.
+type Edge struct {
+	Type       string
+	SourceFile string
+	SourceName string
+	SourceLine int
+	TargetFile string
+	TargetName string
+	TargetLine int
+}
+
+// ExportRepository reads every File and Symbol node of a repository along with
+// the IMPORTS, CALLS, and EXTENDS edges between them.
+func (s *Neo4jStore) ExportRepository(ctx context.Context, repo string) (*RepoSnapshot, error) {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	snapshot := &RepoSnapshot{Repo: repo}
+	params := map[string]interface{}{"repo": repo}
+
+	result, err := session.Run(ctx, `
+		MATCH (f:File {repo: $repo})
+		RETURN f.path, f.module_root, f.hash, f.last_indexed,
+		       f.last_author, f.last_author_email, f.last_commit, f.last_modified, f.owners
+	`, params)
+	if err != nil {
+		return nil, fmt.Errorf("export files: %w", err)
+	}
+	for result.Next(ctx) {
+		snapshot.Files = append(snapshot.Files, recordToFile(result.Record(), repo))
+	}
+
+	result, err = session.Run(ctx, `
+		MATCH (s:Symbol {repo: $repo})
+		RETURN s.name, s.kind, s.file_path, s.start_line, s.end_line, s.signature
+	`, params)
+	if err != nil {
+		return nil, fmt.Errorf("export symbols: %w", err)
+	}
+	for result.Next(ctx) {
+		record := result.Record()
+		snapshot.Symbols = append(snapshot.Symbols, Symbol{
+			Name:      getString(record, "s.name"),
+			Kind:      getString(record, "s.kind"),
+			Repo:      repo,
+			FilePath:  getString(record, "s.file_path"),
+			StartLine: getInt(record, "s.start_line"),
+			EndLine:   getInt(record, "s.end_line"),
+			Signature: getString(record, "s.signature"),
+		})
+	}
+
+	edges, err := exportEdges(ctx, session, repo)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Edges = edges
+
+	return snapshot, nil
+}
+
+// exportEdges reads the IMPORTS, CALLS, and EXTENDS edges within a repository.
+func exportEdges(ctx context.Context, session neo4j.SessionWithContext, repo string) ([]Edge, error) {
+	params := map[string]interface{}{"repo": repo}
+	var edges []Edge
+
+	result, err := session.Run(ctx, `
+		MATCH (a:File {repo: $repo})-[:IMPORTS]->(b:File {repo: $repo})
+		RETURN a.path, b.path
+	`, params)
+	if err != nil {
+		return nil, fmt.Errorf("export imports: %w", err)
+	}
+	for result.Next(ctx) {
+		record := result.Record()
+		edges = append(edges, Edge{
+			Type:       RelImports,
+			SourceFile: getString(record, "a.path"),
+			TargetFile: getString(record, "b.path"),
+		})
+	}
+
+	result, err = session.Run(ctx, `
+		MATCH (a:Symbol {repo: $repo})-[r:CALLS|EXTENDS]->(b:Symbol {repo: $repo})
+		RETURN type(r) AS rel, a.file_path, a.name, a.start_line, b.file_path, b.name, b.start_line
+	`, params)
+	if err != nil {
+		return nil, fmt.Errorf("export symbol edges: %w", err)
+	}
+	for result.Next(ctx) {
+		record := result.Record()
+		edges = append(edges, Edge{
+			Type:       getString(record, "rel"),
+			SourceFile: getString(record, "a.file_path"),
+			SourceName: getString(record, "a.name"),
+			SourceLine: getInt(record, "a.start_line"),
+			TargetFile: getString(record, "b.file_path"),
+			TargetName: getString(record, "b.name"),
+			TargetLine: getInt(record, "b.start_line"),
+		})
+	}
+
+	return edges, nil
+}
+
+// ImportRepository replaces a repository's File and Symbol nodes with the
+// contents of snapshot. Existing nodes for the repository are removed first so
+// the result mirrors the source exactly. The wipe and the import run in one
+// write transaction: if anything fails, or ctx is cancelled, the previous
+// copy stays in place.
+func (s *Neo4jStore) ImportRepository(ctx context.Context, snapshot *RepoSnapshot) error {
+	edges := make(map[string][]map[string]interface{})
+	for _, e := range snapshot.Edges {
+		if e.Type != RelImports && e.Type != RelCalls && e.Type != RelExtends {
+			return fmt.Errorf("import %s edge: unsupported edge type", e.Type)
+		}
+		edges[e.Type] = append(edges[e.Type], edgeParams(e))
+	}
+	repo := map[string]interface{}{"repo": snapshot.Repo}
+
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		run := func(what, query string, rows []map[string]interface{}) error {
+			if len(rows) == 0 {
+				return nil
+			}
+			if _, err := tx.Run(ctx, query, map[string]interface{}{"repo": snapshot.Repo, "rows": rows}); err != nil {
+				return fmt.Errorf("import %s: %w", what, err)
+			}
+			return nil
+		}
+
+		if _, err := tx.Run(ctx, `
+			MATCH (n)
+			WHERE (n:File OR n:Symbol) AND n.repo = $repo
+			DETACH DELETE n
+		`, repo); err != nil {
+			return nil, fmt.Errorf("clear repo: %w", err)
+		}
+		if err := run("files", `
+			UNWIND $rows AS row
+			MERGE (f:File {repo: $repo, path: row.path})
+			SET f.module_root = row.module_root,
+			    f.hash = row.hash,
+			    f.last_indexed = row.last_indexed,
+			    f.last_author = row.last_author,
+			    f.last_author_email = row.last_author_email,
+			    f.last_commit = row.last_commit,
+			    f.last_modified = row.last_modified,
+			    f.owners = row.owners
+		`, fileParams(snapshot.Files)); err != nil {
+			return nil, err
+		}
+		if err := run("symbols", `
+			UNWIND $rows AS row
+			MERGE (s:Symbol {repo: $repo, file_path: row.file_path, name: row.name, start_line: row.start_line})
+			SET s.kind = row.kind,
+			    s.end_line = row.end_line,
+			    s.signature = row.signature
+			WITH s, row
+			MATCH (f:File {repo: $repo, path: row.file_path})
+			MERGE (f)-[:CONTAINS]->(s)
+		`, symbolParams(snapshot.Symbols)); err != nil {
+			return nil, err
+		}
+		if err := run(RelImports+" edges", `
+			UNWIND $rows AS row
+			MATCH (a:File {repo: $repo, path: row.source_file})
+			MATCH (b:File {repo: $repo, path: row.target_file})
+			MERGE (a)-[:IMPORTS]->(b)
+		`, edges[RelImports]); err != nil {
+			return nil, err
+		}
+		// Relationship types cannot be parameterized in Cypher; both are constants
+		for _, rel := range []string{RelCalls, RelExtends} {
+			if err := run(rel+" edges", `
+				UNWIND $rows AS row
+				MATCH (a:Symbol {repo: $repo, file_path: row.source_file, name: row.source_name, start_line: row.source_line})
+				MATCH (b:Symbol {repo: $repo, file_path: row.target_file, name: row.target_name, start_line: row.target_line})
+				MERGE (a)-[:`+rel+`]->(b)
+			`, edges[rel]); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// fileParams converts files to the rows ImportRepository unwinds, with the
+// properties UpsertFile sets.
+func fileParams(files []File) []map[string]interface{} {
+	rows := make([]map[string]interface{}, len(files))
+	for i, f := range files {
+		rows[i] = map[string]interface{}{
+			"path":              f.Path,
+			"module_root":       f.ModuleRoot,
+			"hash":              f.Hash,
+			"last_indexed":      f.LastIndexed.Unix(),
+			"last_author":       f.LastAuthor,
+			"last_author_email": f.LastAuthorEmail,
+			"last_commit":       f.LastCommit,
+			"last_modified":     unixOrZero(f.LastModified),
+			"owners":            f.Owners,
+		}
+	}
+	return rows
+}
+
+// symbolParams converts symbols to the rows ImportRepository unwinds, with
+// the properties UpsertSymbol sets.
+func symbolParams(symbols []Symbol) []map[string]interface{} {
+	rows := make([]map[string]interface{}, len(symbols))
+	for i, sym := range symbols {
+		rows[i] = map[string]interface{}{
+			"file_path":  sym.FilePath,
+			"name":       sym.Name,
+			"start_line": sym.StartLine,
+			"kind":       sym.Kind,
+			"end_line":   sym.EndLine,
+			"signature":  sym.Signature,
+		}
+	}
+	return rows
+}
+
+func edgeParams(e Edge) map[string]interface{} {
+	return map[string]interface{}{
+		"source_file": e.SourceFile,
+		"source_name": e.SourceName,
+		"source_line": e.SourceLine,
+		"target_file": e.TargetFile,
+		"target_name": e.TargetName,
+		"target_line": e.TargetLine,
+	}
+}
@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportParams(t *testing.T) {
+	indexed := time.Unix(1700000000, 0)
+	files := fileParams([]File{
+		{Path: "a.py", ModuleRoot: "pkg", Hash: "h1", LastIndexed: indexed, Owners: []string{"@team"}},
+	})
+	require.Len(t, files, 1)
+	assert.Equal(t, "a.py", files[0]["path"])
+	assert.Equal(t, indexed.Unix(), files[0]["last_indexed"])
+	assert.Equal(t, int64(0), files[0]["last_modified"], "unset times are stored as 0")
+	assert.Equal(t, []string{"@team"}, files[0]["owners"])
+
+	symbols := symbolParams([]Symbol{{FilePath: "a.py", Name: "run", Kind: "function", StartLine: 3, EndLine: 9}})
+	require.Len(t, symbols, 1)
+	assert.Equal(t, 3, symbols[0]["start_line"])
+	assert.Equal(t, "run", symbols[0]["name"])
+
+	edge := edgeParams(Edge{Type: RelCalls, SourceFile: "a.py", SourceName: "run", SourceLine: 3, TargetFile: "b.py", TargetName: "load", TargetLine: 1})
+	assert.Equal(t, "load", edge["target_name"])
+	assert.Equal(t, 1, edge["target_line"])
+}
@@ -0,0 +1,61 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/docs"
+)
+
+// inactiveADRWeight scales the weight of superseded, deprecated, and
+// rejected decisions, which still explain history but no longer hold.
+const inactiveADRWeight = 0.5
+
+// indexADRs indexes the repo's architecture decision records as one "adr"
+// doc chunk each, weighted by search.weights.adr.
+func (idx *Indexer) indexADRs(repoPath, repo string) []chunk.Chunk {
+	var allChunks []chunk.Chunk
+	err := filepath.WalkDir(repoPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip errors
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || name == "node_modules" || name == "venv" || name == "__pycache__" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(repoPath, path)
+		relPath = filepath.ToSlash(relPath)
+		if !docs.IsADR(relPath) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			idx.logger.Warn("failed to read ADR", "path", path, "error", err)
+			return nil
+		}
+
+		adr := docs.ParseADR(content, relPath, repo)
+		c := adr.ToChunk()
+		c.RetrievalWeight = float32(chunkWeights(idx.config).ADR)
+		if !adr.Active() {
+			c.RetrievalWeight *= inactiveADRWeight
+		}
+		allChunks = append(allChunks, c)
+		return nil
+	})
+	if err != nil {
+		idx.logger.Warn("error walking for ADRs", "error", err)
+	}
+
+	allChunks, skipped := idx.extractor.ApplySecretPolicy(allChunks)
+	if skipped > 0 {
+		idx.logger.Info("ADRs skipped for secrets", "count", skipped)
+	}
+	return allChunks
+}
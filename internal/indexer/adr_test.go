@@ -0,0 +1,38 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexADRs(t *testing.T) {
+	repo := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(repo, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("docs/adr/0001-use-kafka.md", "# 1. Use Kafka\n\n## Status\n\nAccepted\n\n## Decision\n\nPublish events.\n")
+	write("docs/adr/0002-drop-kafka.md", "# 2. Drop Kafka\n\n## Status\n\nRejected\n")
+	write("docs/adr/README.md", "# Decisions\n")
+
+	cfg := config.DefaultConfig()
+	cfg.Search.Weights.ADR = 2
+	idx := NewIndexerWithClients(cfg, nil, nil)
+	require.NoError(t, idx.configureExtractor(&config.RepoConfig{Name: "app"}))
+
+	chunks := idx.indexADRs(repo, "app")
+	require.Len(t, chunks, 2)
+	weights := map[string]float32{}
+	for _, c := range chunks {
+		assert.Equal(t, "adr", c.Kind)
+		weights[c.FilePath] = c.RetrievalWeight
+	}
+	assert.InDelta(t, 2.0, weights["docs/adr/0001-use-kafka.md"], 1e-6)
+	assert.InDelta(t, 1.0, weights["docs/adr/0002-drop-kafka.md"], 1e-6, "rejected decisions get half")
+}
@@ -0,0 +1,34 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/randalmurphal/code-indexer/internal/store"
+)
+
+// settleBranch tags every chunk of the repo with the run's branch once a
+// whole-repo run has left the index matching the checkout. A full run
+// rewrote every file, so chunks still tagged with another branch (or none)
+// belong to files that no longer exist after a checkout and are deleted.
+// An incremental run only rewrote changed files, so the unchanged ones are
+// retagged instead. Runs with failed or resumed files, and detached HEADs,
+// leave the tags for the next run.
+func (idx *Indexer) settleBranch(ctx context.Context, collection, repo string, incremental bool, result *IndexResult) {
+	if result.Branch == "" || len(result.FailedFiles) > 0 || result.FilesResumed > 0 {
+		return
+	}
+	stale := map[string]interface{}{
+		"repo":        repo,
+		store.MustNot: map[string]interface{}{"branch": result.Branch},
+	}
+	if incremental {
+		if err := idx.store.SetPayloadByFilter(ctx, collection, stale, map[string]interface{}{"branch": result.Branch}); err != nil {
+			result.addError(PhaseStore, fmt.Errorf("tag chunks with branch %s: %w", result.Branch, err))
+		}
+		return
+	}
+	if err := idx.store.DeleteByFilter(ctx, collection, stale); err != nil {
+		result.addError(PhasePrune, fmt.Errorf("delete chunks from other branches: %w", err))
+	}
+}
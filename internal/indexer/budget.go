@@ -0,0 +1,113 @@
+package indexer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Phase is the part of an index run an error happened in.
+type Phase string
+
+const (
+	PhaseParse Phase = "parse" // A file could not be read or chunked
+	PhaseEmbed Phase = "embed" // A batch failed to embed after retries
+	PhaseStore Phase = "store" // A batch failed to upsert after retries, or duplicates were not recorded
+	PhasePrune Phase = "prune" // A deleted or renamed file was not removed from the index
+)
+
+// addError records err under its phase and in Errors.
+func (r *IndexResult) addError(phase Phase, err error) {
+	r.Errors = append(r.Errors, err)
+	switch phase {
+	case PhaseParse:
+		r.ParseErrors = append(r.ParseErrors, err)
+	case PhaseEmbed:
+		r.EmbedErrors = append(r.EmbedErrors, err)
+	case PhaseStore:
+		r.StoreErrors = append(r.StoreErrors, err)
+	case PhasePrune:
+		r.PruneErrors = append(r.PruneErrors, err)
+	}
+}
+
+// ErrorCounts is the number of errors per phase, leaving out phases
+// without any.
+func (r *IndexResult) ErrorCounts() map[Phase]int {
+	counts := make(map[Phase]int)
+	for phase, errs := range map[Phase][]error{
+		PhaseParse: r.ParseErrors,
+		PhaseEmbed: r.EmbedErrors,
+		PhaseStore: r.StoreErrors,
+		PhasePrune: r.PruneErrors,
+	} {
+		if len(errs) > 0 {
+			counts[phase] = len(errs)
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	return counts
+}
+
+// errorBudget counts the distinct files a run failed to index, from the
+// parse side and both pipeline stages, and reports when they pass the limit.
+type errorBudget struct {
+	limit int // Files that may fail; negative for no limit
+	total int // Files walked
+
+	mu     sync.Mutex
+	failed map[string]bool
+}
+
+// newErrorBudget allows percent of total files to fail, and at least one
+// unless percent is zero. A negative percent never runs out.
+func newErrorBudget(percent, total int) *errorBudget {
+	limit := -1
+	switch {
+	case percent == 0:
+		limit = 0
+	case percent > 0:
+		limit = max(1, total*percent/100)
+	}
+	return &errorBudget{limit: limit, total: total, failed: make(map[string]bool)}
+}
+
+// spend marks paths as failed and returns an error once more files have
+// failed than the budget allows. Empty paths (repo-wide chunks) are free.
+func (b *errorBudget) spend(paths ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, path := range paths {
+		if path != "" {
+			b.failed[path] = true
+		}
+	}
+	if b.limit >= 0 && len(b.failed) > b.limit {
+		return fmt.Errorf("error budget exceeded: %d of %d files failed (limit %d)", len(b.failed), b.total, b.limit)
+	}
+	return nil
+}
+
+// files returns the failed paths, sorted.
+func (b *errorBudget) files() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.failed) == 0 {
+		return nil
+	}
+	files := make([]string, 0, len(b.failed))
+	for path := range b.failed {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// has reports whether path has failed.
+func (b *errorBudget) has(path string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failed[path]
+}
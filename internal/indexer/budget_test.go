@@ -0,0 +1,47 @@
+package indexer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorBudget(t *testing.T) {
+	b := newErrorBudget(5, 100)
+	for _, path := range []string{"a.py", "b.py", "c.py", "d.py", "e.py"} {
+		require.NoError(t, b.spend(path))
+	}
+	require.NoError(t, b.spend("a.py", ""), "repeat and repo-wide failures are free")
+
+	err := b.spend("f.py")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "6 of 100 files failed (limit 5)")
+	assert.True(t, b.has("f.py"))
+	assert.Equal(t, []string{"a.py", "b.py", "c.py", "d.py", "e.py", "f.py"}, b.files())
+}
+
+func TestErrorBudgetLimits(t *testing.T) {
+	assert.Equal(t, 1, newErrorBudget(5, 3).limit, "small repos tolerate one failure")
+	assert.Equal(t, 0, newErrorBudget(0, 100).limit)
+	assert.Error(t, newErrorBudget(0, 100).spend("a.py"))
+
+	unlimited := newErrorBudget(-1, 10)
+	for i := range 20 {
+		require.NoError(t, unlimited.spend(string(rune('a'+i))))
+	}
+	assert.Nil(t, newErrorBudget(5, 10).files())
+}
+
+func TestIndexResultAddError(t *testing.T) {
+	var r IndexResult
+	parseErr, storeErr := errors.New("bad file"), errors.New("upsert failed")
+	r.addError(PhaseParse, parseErr)
+	r.addError(PhaseStore, storeErr)
+
+	assert.Equal(t, []error{parseErr, storeErr}, r.Errors)
+	assert.Equal(t, []error{parseErr}, r.ParseErrors)
+	assert.Equal(t, []error{storeErr}, r.StoreErrors)
+	assert.Empty(t, r.EmbedErrors)
+}
@@ -0,0 +1,123 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/randalmurphal/code-indexer/internal/graph"
+)
+
+// Rename is a file that moved without its content changing.
+type Rename struct {
+	From string
+	To   string
+}
+
+// diffStoredFiles compares the file hashes stored by the last run with this
+// run's walk. A stored path that was not walked is deleted, unless a newly
+// added file has the same hash, in which case it was renamed. Each stored
+// path pairs with at most one added file, in path order.
+func diffStoredFiles(stored map[string]string, walked map[string]bool, added map[string]string) (deleted []string, renames []Rename) {
+	// Missing paths by hash, sorted so pairing is deterministic
+	var missing []string
+	for path := range stored {
+		if !walked[path] {
+			missing = append(missing, path)
+		}
+	}
+	sort.Strings(missing)
+	byHash := make(map[string][]string)
+	for _, path := range missing {
+		byHash[stored[path]] = append(byHash[stored[path]], path)
+	}
+
+	addedPaths := make([]string, 0, len(added))
+	for path := range added {
+		addedPaths = append(addedPaths, path)
+	}
+	sort.Strings(addedPaths)
+
+	renamed := make(map[string]bool)
+	for _, path := range addedPaths {
+		candidates := byHash[added[path]]
+		if len(candidates) == 0 {
+			continue
+		}
+		renames = append(renames, Rename{From: candidates[0], To: path})
+		renamed[candidates[0]] = true
+		byHash[added[path]] = candidates[1:]
+	}
+
+	for _, path := range missing {
+		if !renamed[path] {
+			deleted = append(deleted, path)
+		}
+	}
+	return deleted, renames
+}
+
+// pruneFiles removes chunks and graph nodes for deleted files and moves the
+// graph nodes of renamed ones. A renamed file's new path has already been
+// indexed like any new file; keeping its graph nodes preserves the call
+// edges from files this run skipped as unchanged. Failures are collected in
+// result.PruneErrors.
+func (idx *Indexer) pruneFiles(ctx context.Context, collection string, graphStore *graph.Neo4jStore, repo string, deleted []string, renames []Rename, result *IndexResult) {
+	for _, path := range deleted {
+		idx.logger.Info("removing deleted file", "path", path)
+		if err := idx.store.DeleteByFilter(ctx, collection, map[string]interface{}{"repo": repo, "file_path": path}); err != nil {
+			result.addError(PhasePrune, fmt.Errorf("delete chunks for %s: %w", path, err))
+			continue
+		}
+		if graphStore != nil {
+			if err := graphStore.DeleteFile(ctx, repo, path); err != nil {
+				result.addError(PhasePrune, fmt.Errorf("delete %s from graph: %w", path, err))
+				continue
+			}
+		}
+		result.FilesDeleted++
+	}
+
+	for _, r := range renames {
+		idx.logger.Info("file renamed", "from", r.From, "to", r.To)
+		if err := idx.store.DeleteByFilter(ctx, collection, map[string]interface{}{"repo": repo, "file_path": r.From}); err != nil {
+			result.addError(PhasePrune, fmt.Errorf("delete chunks for %s: %w", r.From, err))
+			continue
+		}
+		if graphStore != nil {
+			if err := graphStore.RenameFile(ctx, repo, r.From, r.To); err != nil {
+				result.addError(PhasePrune, fmt.Errorf("rename %s to %s in graph: %w", r.From, r.To, err))
+				continue
+			}
+		}
+		result.Renamed = append(result.Renamed, r)
+	}
+}
+
+// storedHashes returns the file hashes a run without a graph store leaves
+// for the next incremental run: the previous ones, minus deleted and
+// renamed files unless pruning failed (the next run retries it), plus the
+// files stored this run. Failed files keep their old hash so the next run
+// retries them; files skipped for secrets are dropped.
+func storedHashes(previous map[string]string, updated []graph.File, failed func(path string) bool, deleted []string, renames []Rename, result *IndexResult) map[string]string {
+	hashes := make(map[string]string, len(previous)+len(updated))
+	maps.Copy(hashes, previous)
+	if len(result.PruneErrors) == 0 {
+		for _, path := range deleted {
+			delete(hashes, path)
+		}
+		for _, r := range renames {
+			delete(hashes, r.From)
+		}
+	}
+	for _, f := range updated {
+		if !failed(f.Path) {
+			hashes[f.Path] = f.Hash
+		}
+	}
+	for _, path := range result.SecretFilesSkipped {
+		delete(hashes, path)
+	}
+	return hashes
+}
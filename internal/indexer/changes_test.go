@@ -0,0 +1,77 @@
+package indexer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffStoredFiles(t *testing.T) {
+	stored := map[string]string{
+		"app.py":          "h1",
+		"old/models.py":   "h2",
+		"removed.py":      "h3",
+		"dup_a.py":        "h4",
+		"dup_b.py":        "h4",
+		"edited_moved.py": "h5",
+	}
+	walked := map[string]bool{
+		"app.py":        true,
+		"new/models.py": true,
+		"fresh.py":      true,
+		"copy_1.py":     true,
+		"moved_edit.py": true,
+	}
+	added := map[string]string{
+		"new/models.py": "h2",
+		"fresh.py":      "h9",
+		"copy_1.py":     "h4",
+		"moved_edit.py": "h6", // Moved and edited: not a rename
+	}
+
+	deleted, renames := diffStoredFiles(stored, walked, added)
+
+	assert.Equal(t, []Rename{
+		{From: "dup_a.py", To: "copy_1.py"},
+		{From: "old/models.py", To: "new/models.py"},
+	}, renames)
+	assert.Equal(t, []string{"dup_b.py", "edited_moved.py", "removed.py"}, deleted)
+}
+
+func TestDiffStoredFilesNothingMissing(t *testing.T) {
+	stored := map[string]string{"a.py": "h1"}
+	walked := map[string]bool{"a.py": true, "b.py": true}
+
+	deleted, renames := diffStoredFiles(stored, walked, map[string]string{"b.py": "h1"})
+
+	assert.Empty(t, deleted)
+	assert.Empty(t, renames)
+}
+
+func TestStoredHashes(t *testing.T) {
+	previous := map[string]string{"keep.py": "h1", "gone.py": "h2", "old.py": "h3", "flaky.py": "h4", "leak.py": "h5"}
+	updated := []graph.File{
+		{Path: "new.py", Hash: "h3"},
+		{Path: "flaky.py", Hash: "h4-edited"},
+		{Path: "added.py", Hash: "h6"},
+	}
+	failed := func(path string) bool { return path == "flaky.py" }
+	deleted := []string{"gone.py"}
+	renames := []Rename{{From: "old.py", To: "new.py"}}
+
+	hashes := storedHashes(previous, updated, failed, deleted, renames, &IndexResult{SecretFilesSkipped: []string{"leak.py"}})
+	assert.Equal(t, map[string]string{
+		"keep.py":  "h1",
+		"new.py":   "h3",
+		"flaky.py": "h4", // Old hash: retried next run
+		"added.py": "h6",
+	}, hashes)
+	assert.Contains(t, previous, "gone.py", "the previous hashes are not modified")
+
+	pruneFailed := &IndexResult{}
+	pruneFailed.addError(PhasePrune, errors.New("qdrant down"))
+	hashes = storedHashes(previous, nil, failed, deleted, renames, pruneFailed)
+	assert.Contains(t, hashes, "gone.py", "deletions are retried when pruning failed")
+}
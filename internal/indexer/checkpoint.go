@@ -0,0 +1,101 @@
+package indexer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// checkpointEntry is one line of a checkpoint log: a file whose chunks were
+// all stored, and the content hash they were extracted from.
+type checkpointEntry struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+}
+
+// LoadCheckpoint reads the files recorded by an interrupted run, mapped to
+// their hashes. A missing log yields an empty map. A torn last line (the
+// run died mid-write) is ignored.
+func LoadCheckpoint(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	done := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry checkpointEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Path == "" {
+			continue
+		}
+		done[entry.Path] = entry.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	return done, nil
+}
+
+// checkpointLog appends completed files to a checkpoint as the pipeline
+// stores them. Each file is one write, so whatever was stored before a crash
+// is on disk.
+type checkpointLog struct {
+	mu  sync.Mutex
+	f   *os.File
+	err error // First write error; later records are dropped
+}
+
+// openCheckpointLog opens the log at path, keeping earlier entries when
+// resuming and starting it over otherwise.
+func openCheckpointLog(path string, resume bool) (*checkpointLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create checkpoint dir: %w", err)
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint: %w", err)
+	}
+	return &checkpointLog{f: f}, nil
+}
+
+// record appends a completed file. It is safe for concurrent use.
+func (l *checkpointLog) record(path, hash string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return
+	}
+	data, err := json.Marshal(checkpointEntry{Path: path, Hash: hash})
+	if err == nil {
+		_, err = l.f.Write(append(data, '\n'))
+	}
+	l.err = err
+}
+
+// close closes the log, removing it when the run completed so the next run
+// starts from scratch.
+func (l *checkpointLog) close(completed bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	if completed {
+		return os.Remove(l.f.Name())
+	}
+	return l.err
+}
@@ -0,0 +1,66 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointLogRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints", "demo.jsonl")
+
+	log, err := openCheckpointLog(path, false)
+	require.NoError(t, err)
+	log.record("a.py", "h1")
+	log.record("b.py", "h2")
+	require.NoError(t, log.close(false)) // Interrupted: the log stays
+
+	done, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a.py": "h1", "b.py": "h2"}, done)
+
+	// Resuming appends; a fresh run starts over
+	log, err = openCheckpointLog(path, true)
+	require.NoError(t, err)
+	log.record("c.py", "h3")
+	require.NoError(t, log.close(false))
+	done, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Len(t, done, 3)
+
+	log, err = openCheckpointLog(path, false)
+	require.NoError(t, err)
+	log.record("d.py", "h4")
+	require.NoError(t, log.close(false))
+	done, err = LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"d.py": "h4"}, done)
+}
+
+func TestCheckpointLogRemovedOnCompletion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo.jsonl")
+
+	log, err := openCheckpointLog(path, false)
+	require.NoError(t, err)
+	log.record("a.py", "h1")
+	require.NoError(t, log.close(true))
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	done, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Empty(t, done)
+}
+
+func TestLoadCheckpointIgnoresTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"path":"a.py","hash":"h1"}`+"\n"+`{"path":"b.py","ha`), 0644))
+
+	done, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a.py": "h1"}, done)
+}
@@ -0,0 +1,115 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// codeOwnersLocations are where GitHub looks for CODEOWNERS, in order.
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeOwners maps repo paths to their owners from a CODEOWNERS file.
+type codeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern   string // doublestar glob over repo-relative paths
+	dirOnly   bool   // Trailing slash: matches only files below a directory
+	recursive bool   // Matching a directory also matches everything below it
+	owners    []string
+}
+
+// loadCodeOwners reads the first CODEOWNERS file in repoPath, or returns nil
+// when there is none.
+func loadCodeOwners(repoPath string) *codeOwners {
+	for _, loc := range codeOwnersLocations {
+		data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(loc)))
+		if err == nil {
+			return parseCodeOwners(data)
+		}
+	}
+	return nil
+}
+
+// parseCodeOwners parses CODEOWNERS content with GitHub's rules: one
+// gitignore-style pattern per line followed by its owners, comments after
+// '#', and a pattern without owners marking paths as unowned. GitLab
+// section headers are skipped.
+func parseCodeOwners(data []byte) *codeOwners {
+	co := &codeOwners{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] != '\\') {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		co.rules = append(co.rules, newCodeOwnersRule(strings.ReplaceAll(fields[0], `\#`, "#"), fields[1:]))
+	}
+	return co
+}
+
+func newCodeOwnersRule(pattern string, owners []string) codeOwnersRule {
+	rule := codeOwnersRule{recursive: true}
+	if len(owners) > 0 {
+		rule.owners = owners
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	// A slash anywhere but the end anchors the pattern to the repo root
+	if strings.Contains(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else {
+		pattern = "**/" + pattern
+	}
+	// GitHub's "docs/*" owns the files directly in docs/, not deeper ones
+	if strings.HasSuffix(pattern, "/*") {
+		rule.recursive = false
+	}
+	rule.pattern = pattern
+	return rule
+}
+
+// Owners returns the owners of the repo-relative path. The last matching
+// rule wins, so it may be empty for paths explicitly left unowned.
+func (co *codeOwners) Owners(relPath string) []string {
+	if co == nil {
+		return nil
+	}
+	relPath = filepath.ToSlash(relPath)
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].matches(relPath) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
+
+func (r codeOwnersRule) matches(relPath string) bool {
+	if !r.dirOnly {
+		if ok, _ := doublestar.Match(r.pattern, relPath); ok {
+			return true
+		}
+	}
+	if !r.recursive {
+		return false
+	}
+	for dir := path.Dir(relPath); dir != "."; dir = path.Dir(dir) {
+		if ok, _ := doublestar.Match(r.pattern, dir); ok {
+			return true
+		}
+	}
+	return false
+}
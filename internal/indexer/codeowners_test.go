@@ -0,0 +1,67 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeOwners(t *testing.T) {
+	co := parseCodeOwners([]byte(`# Default owners
+*                   @acme/platform
+
+*.js                @acme/frontend   # inline comment
+/build/logs/        @acme/ops
+docs/*              docs@example.com
+apps/               @acme/apps
+/scripts            @acme/scripts @alice
+**/migrations       @acme/dba
+/vendor/
+[Section]
+`))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"main.py", []string{"@acme/platform"}},
+		{"web/app.js", []string{"@acme/frontend"}},
+		{"build/logs/today.log", []string{"@acme/ops"}},
+		{"src/build/logs/today.log", []string{"@acme/platform"}},
+		{"docs/index.md", []string{"docs@example.com"}},
+		{"docs/guides/setup.md", []string{"@acme/platform"}},
+		{"apps/web/main.py", []string{"@acme/apps"}},
+		{"services/apps/main.py", []string{"@acme/apps"}},
+		{"scripts/deploy.sh", []string{"@acme/scripts", "@alice"}},
+		{"src/scripts/deploy.sh", []string{"@acme/platform"}},
+		{"db/migrations/0001.py", []string{"@acme/dba"}},
+		{"vendor/lib.py", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, co.Owners(tt.path))
+		})
+	}
+}
+
+func TestCodeOwnersDirOnlyPattern(t *testing.T) {
+	co := parseCodeOwners([]byte("logs/ @acme/ops\n"))
+
+	assert.Equal(t, []string{"@acme/ops"}, co.Owners("logs/app.log"))
+	assert.Nil(t, co.Owners("logs"), "a trailing slash never matches a file")
+}
+
+func TestLoadCodeOwners(t *testing.T) {
+	repo := t.TempDir()
+	assert.Nil(t, loadCodeOwners(repo))
+	assert.Nil(t, loadCodeOwners(repo).Owners("main.py"), "nil owners match nothing")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "CODEOWNERS"), []byte("* @root\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".github"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".github", "CODEOWNERS"), []byte("* @github\n"), 0o644))
+
+	assert.Equal(t, []string{"@github"}, loadCodeOwners(repo).Owners("main.py"), ".github/CODEOWNERS takes precedence")
+}
@@ -0,0 +1,134 @@
+package indexer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/store"
+)
+
+// chunkDeduper drops chunks whose content already appeared in this run,
+// remembering where, so vendored and generated copies are embedded and
+// stored once. It is used from the parse emit callback only.
+type chunkDeduper struct {
+	canonical map[string]*dedupEntry // Content key -> first chunk seen
+	order     []*dedupEntry          // Entries with duplicates, in first-seen order
+	dropped   int
+}
+
+type dedupEntry struct {
+	id         string
+	duplicates []chunk.Location
+}
+
+func newChunkDeduper() *chunkDeduper {
+	return &chunkDeduper{canonical: make(map[string]*dedupEntry)}
+}
+
+// contentKey identifies a chunk's content regardless of where it is. The
+// context header is left out: it names the file.
+func contentKey(c chunk.Chunk) string {
+	h := sha256.New()
+	for _, part := range []string{string(c.Type), c.Kind, c.SymbolName, c.Signature, c.Docstring, c.Content} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// filter returns the chunks not seen before, recording the rest as
+// duplicates of the first chunk with the same content.
+func (d *chunkDeduper) filter(chunks []chunk.Chunk) []chunk.Chunk {
+	unique := chunks[:0:0]
+	for _, c := range chunks {
+		key := contentKey(c)
+		entry, seen := d.canonical[key]
+		if !seen {
+			d.canonical[key] = &dedupEntry{id: c.ID}
+			unique = append(unique, c)
+			continue
+		}
+		if len(entry.duplicates) == 0 {
+			d.order = append(d.order, entry)
+		}
+		entry.duplicates = append(entry.duplicates, chunk.Location{FilePath: c.FilePath, StartLine: c.StartLine, EndLine: c.EndLine})
+		d.dropped++
+	}
+	return unique
+}
+
+// recordDuplicates writes each canonical chunk's duplicate locations to its
+// payload. Canonical chunks may be stored before their copies are parsed,
+// so this runs once the pipeline has stored everything.
+func (idx *Indexer) recordDuplicates(ctx context.Context, collection string, d *chunkDeduper) error {
+	for _, entry := range d.order {
+		payload := map[string]interface{}{"duplicates": store.DuplicatesPayload(entry.duplicates)}
+		if err := idx.store.SetPayload(ctx, collection, entry.id, payload); err != nil {
+			return fmt.Errorf("record duplicates of %s: %w", entry.id, err)
+		}
+	}
+	return nil
+}
+
+// storedDuplicates maps each file holding a canonical chunk to the files its
+// duplicates were found in by the last run, read from the duplicates
+// payloads.
+func (idx *Indexer) storedDuplicates(ctx context.Context, collection, repo string) (map[string][]string, error) {
+	filter := map[string]interface{}{
+		"repo":        repo,
+		store.MustNot: map[string]interface{}{"duplicates": store.Empty{}},
+	}
+	copies := make(map[string][]string)
+	offset := ""
+	for {
+		chunks, next, err := idx.store.ScrollChunks(ctx, collection, filter, 256, offset)
+		if err != nil {
+			return nil, fmt.Errorf("scroll canonical chunks: %w", err)
+		}
+		for _, c := range chunks {
+			for _, loc := range c.Duplicates {
+				copies[c.FilePath] = append(copies[c.FilePath], loc.FilePath)
+			}
+		}
+		if next == "" {
+			return copies, nil
+		}
+		offset = next
+	}
+}
+
+// requeueDuplicates returns the files an incremental run must parse even
+// when unchanged: the copies of chunks whose canonical file was edited or
+// deleted. A copy exists only as a duplicates entry on its canonical chunk,
+// so it would drop out of the index with it; parsing it again stores it (or
+// records it on the new canonical chunk). Canonical files the last run did
+// not hash are out of this run's scope and left alone.
+func requeueDuplicates(repoPath string, existingHashes map[string]string, copies map[string][]string) map[string]bool {
+	requeued := make(map[string]bool)
+	for canonical, files := range copies {
+		oldHash, ok := existingHashes[canonical]
+		if !ok {
+			continue
+		}
+		source, err := os.ReadFile(filepath.Join(repoPath, canonical))
+		if err == nil && computeFileHash(source) == oldHash {
+			continue
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			continue // Unreadable: the parse records the failure and keeps the old chunks
+		}
+		for _, f := range files {
+			if f != canonical {
+				requeued[f] = true
+			}
+		}
+	}
+	return requeued
+}
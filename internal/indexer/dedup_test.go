@@ -0,0 +1,127 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkDeduper(t *testing.T) {
+	shared := func(id, path string, start int) chunk.Chunk {
+		return chunk.Chunk{
+			ID:            id,
+			FilePath:      path,
+			StartLine:     start,
+			EndLine:       start + 2,
+			Type:          chunk.ChunkTypeCode,
+			Kind:          "function",
+			SymbolName:    "helper",
+			Content:       "def helper():\n    return 1",
+			ContextHeader: "# File: " + path,
+		}
+	}
+
+	d := newChunkDeduper()
+	first := d.filter([]chunk.Chunk{
+		shared("a", "src/util.py", 1),
+		{ID: "b", FilePath: "src/util.py", Type: chunk.ChunkTypeCode, Content: "def other(): pass"},
+	})
+	second := d.filter([]chunk.Chunk{shared("c", "vendor/util.py", 10)})
+	third := d.filter([]chunk.Chunk{shared("d", "third_party/util.py", 5)})
+
+	assert.Len(t, first, 2)
+	assert.Empty(t, second)
+	assert.Empty(t, third)
+	assert.Equal(t, 2, d.dropped)
+
+	require.Len(t, d.order, 1)
+	assert.Equal(t, "a", d.order[0].id)
+	assert.Equal(t, []chunk.Location{
+		{FilePath: "vendor/util.py", StartLine: 10, EndLine: 12},
+		{FilePath: "third_party/util.py", StartLine: 5, EndLine: 7},
+	}, d.order[0].duplicates)
+}
+
+func TestChunkDeduperKeepsDifferentSymbols(t *testing.T) {
+	d := newChunkDeduper()
+	kept := d.filter([]chunk.Chunk{
+		{ID: "a", FilePath: "a.py", SymbolName: "one", Content: "pass"},
+		{ID: "b", FilePath: "b.py", SymbolName: "two", Content: "pass"},
+	})
+
+	assert.Len(t, kept, 2)
+	assert.Zero(t, d.dropped)
+	assert.Empty(t, d.order)
+}
+
+func TestDedupIncrementalKeepsCopiesOfEditedCanonical(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, src string) string {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+		return path
+	}
+	const helper = "def helper():\n    return 1\n"
+	paths := []string{write("src/util.py", helper), write("vendor/util.py", helper)}
+	repoCfg := &config.RepoConfig{Name: "demo", DedupChunks: true}
+	idx := newParseTestIndexer(dir, repoCfg)
+
+	// run parses like an index run, returning the chunks it would store and
+	// the file hashes it would record
+	run := func(job parseJob) ([]chunk.Chunk, *chunkDeduper, map[string]string) {
+		t.Helper()
+		d := newChunkDeduper()
+		var stored []chunk.Chunk
+		hashes := map[string]string{}
+		require.NoError(t, idx.parseFiles(context.Background(), job, paths, 2, func(p parsedFile) {
+			require.NoError(t, p.err)
+			if !p.skipped {
+				stored = append(stored, d.filter(p.chunks)...)
+				hashes[p.file.Path] = p.file.Hash
+			}
+		}))
+		return stored, d, hashes
+	}
+	storedIn := func(chunks []chunk.Chunk, path string) bool {
+		for _, c := range chunks {
+			if c.FilePath == path && c.SymbolName == "helper" {
+				return true
+			}
+		}
+		return false
+	}
+
+	stored, d, hashes := run(parseJob{repoPath: dir, repoCfg: repoCfg})
+	require.True(t, storedIn(stored, "src/util.py"))
+	require.False(t, storedIn(stored, "vendor/util.py"), "the copy is only a duplicates entry")
+	require.Len(t, d.order, 1)
+	copies := map[string][]string{"src/util.py": {d.order[0].duplicates[0].FilePath}}
+
+	// Editing the helper in the canonical file makes the copy its own chunk
+	write("src/util.py", "def helper():\n    return 2\n")
+	requeued := requeueDuplicates(dir, hashes, copies)
+	assert.Equal(t, map[string]bool{"vendor/util.py": true}, requeued)
+	stored, _, _ = run(parseJob{repoPath: dir, repoCfg: repoCfg, existingHashes: hashes, requeued: requeued})
+	assert.True(t, storedIn(stored, "vendor/util.py"), "the unchanged copy is stored once the canonical chunk changes")
+
+	// Editing elsewhere in the canonical file records the copy on it again
+	write("src/util.py", helper+"\n\ndef other():\n    return 3\n")
+	stored, d, _ = run(parseJob{repoPath: dir, repoCfg: repoCfg, existingHashes: hashes, requeued: requeueDuplicates(dir, hashes, copies)})
+	assert.False(t, storedIn(stored, "vendor/util.py"))
+	require.Len(t, d.order, 1, "the rewritten canonical chunk gets its duplicates back")
+	assert.Equal(t, "vendor/util.py", d.order[0].duplicates[0].FilePath)
+
+	// Deleting the canonical file requeues the copy too; unchanged canonicals don't
+	require.NoError(t, os.Remove(paths[0]))
+	assert.Equal(t, map[string]bool{"vendor/util.py": true}, requeueDuplicates(dir, hashes, copies))
+	write("src/util.py", helper)
+	assert.Empty(t, requeueDuplicates(dir, hashes, copies))
+}
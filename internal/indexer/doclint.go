@@ -0,0 +1,295 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+)
+
+// DocsLintReport lists where a repo's navigation docs (AGENTS.md,
+// CLAUDE.md) no longer match its index.
+type DocsLintReport struct {
+	Repo           string    `json:"repo"`
+	FilesIndexed   int       `json:"files_indexed"`
+	SymbolsIndexed int       `json:"symbols_indexed"`
+	Docs           []DocLint `json:"docs"`
+}
+
+// DocLint is what one navigation doc gets wrong.
+type DocLint struct {
+	Path          string   `json:"path"`
+	Dir           string   `json:"dir"`            // Directory the doc describes ("" for the repo root)
+	BrokenFiles   []string `json:"broken_files"`   // Mentioned paths that are neither on disk nor indexed
+	BrokenSymbols []string `json:"broken_symbols"` // Mentioned types and calls no indexed symbol is named
+	// Undocumented are the subdirectories and source files of Dir holding
+	// indexed code that the doc never mentions: structure added since it
+	// was written.
+	Undocumented []string `json:"undocumented"`
+}
+
+// Broken reports whether the doc references anything that does not exist.
+func (d *DocLint) Broken() bool {
+	return len(d.BrokenFiles) > 0 || len(d.BrokenSymbols) > 0
+}
+
+// Drifted reports whether the doc's directory has grown code it omits.
+func (d *DocLint) Drifted() bool {
+	return len(d.Undocumented) > 0
+}
+
+// Broken reports whether any doc references something that does not exist.
+// Drift alone is not broken: the doc is incomplete, not wrong.
+func (r *DocsLintReport) Broken() bool {
+	for i := range r.Docs {
+		if r.Docs[i].Broken() {
+			return true
+		}
+	}
+	return false
+}
+
+// docIndex is what the index holds for a repo: non-test code files and the
+// names of their symbols.
+type docIndex struct {
+	files   map[string]bool
+	symbols map[string]bool
+}
+
+// LintDocs checks every navigation doc of the repo against the indexed code
+// chunks: mentioned files and symbols must exist, and the doc's directory
+// should not hold indexed code the doc never mentions.
+func (idx *Indexer) LintDocs(ctx context.Context, repoPath string, repoCfg *config.RepoConfig) (*DocsLintReport, error) {
+	filter := map[string]interface{}{
+		"repo":    repoCfg.Name,
+		"type":    "code",
+		"is_test": false,
+	}
+	files, err := idx.store.CountByField(ctx, "chunks", "file_path", filter)
+	if err != nil {
+		return nil, fmt.Errorf("count indexed files: %w", err)
+	}
+	symbols, err := idx.store.CountByField(ctx, "chunks", "symbol_name", filter)
+	if err != nil {
+		return nil, fmt.Errorf("count indexed symbols: %w", err)
+	}
+
+	index := docIndex{files: make(map[string]bool, len(files)), symbols: make(map[string]bool, len(symbols))}
+	for f := range files {
+		index.files[f] = true
+	}
+	for s := range symbols {
+		if s != "" {
+			index.symbols[s] = true
+		}
+	}
+
+	report := &DocsLintReport{Repo: repoCfg.Name, FilesIndexed: len(index.files), SymbolsIndexed: len(index.symbols)}
+	for _, relPath := range findNavigationDocs(repoPath) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(filepath.Join(repoPath, relPath))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", relPath, err)
+		}
+		report.Docs = append(report.Docs, lintNavigationDoc(repoPath, relPath, content, index))
+	}
+	return report, nil
+}
+
+// findNavigationDocs returns the repo-relative paths of the repo's AGENTS.md
+// and CLAUDE.md files, skipping hidden and dependency directories.
+func findNavigationDocs(repoPath string) []string {
+	var paths []string
+	_ = filepath.WalkDir(repoPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip errors
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != repoPath && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "venv" || name == "__pycache__") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == "AGENTS.md" || d.Name() == "CLAUDE.md" {
+			relPath, _ := filepath.Rel(repoPath, path)
+			paths = append(paths, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+	return paths
+}
+
+var (
+	docInlineCodeRe = regexp.MustCompile("`([^`\n]+)`")
+	// A type (UserService, Config.Load) or a call (get_user(), idx.Run())
+	docTypeRe = regexp.MustCompile(`^[A-Z][a-z0-9]+[A-Za-z0-9]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+	docCallRe = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)(?:\.([A-Za-z_][A-Za-z0-9_]*))*\(.*\)$`)
+	// file.go:10-20 and file.py:42 point at lines
+	docLineSuffixRe = regexp.MustCompile(`:\d+(?:-\d+)?$`)
+)
+
+// lintNavigationDoc checks the doc at relPath. Files resolve against the
+// doc's directory, then the repo root, then as a path suffix of an indexed
+// file (docs often shorten internal/search/handler.go to search/handler.go).
+func lintNavigationDoc(repoPath, relPath string, content []byte, index docIndex) DocLint {
+	dir := path.Dir(relPath)
+	if dir == "." {
+		dir = ""
+	}
+	lint := DocLint{Path: relPath, Dir: dir}
+	text := string(content)
+
+	brokenFiles := map[string]bool{}
+	brokenSymbols := map[string]bool{}
+	for _, m := range docInlineCodeRe.FindAllStringSubmatch(text, -1) {
+		ref := strings.TrimSpace(m[1])
+		switch {
+		case isDocFileRef(ref):
+			file := docLineSuffixRe.ReplaceAllString(ref, "")
+			if !docFileExists(repoPath, dir, file, index) {
+				brokenFiles[file] = true
+			}
+		case docTypeRe.MatchString(ref) || docCallRe.MatchString(ref):
+			if name, ok := docSymbolExists(ref, index); !ok {
+				brokenSymbols[name] = true
+			}
+		}
+	}
+	lint.BrokenFiles = sortedKeys(brokenFiles)
+	lint.BrokenSymbols = sortedKeys(brokenSymbols)
+	lint.Undocumented = undocumented(dir, text, index)
+	return lint
+}
+
+// isDocFileRef reports whether inline code names a file or directory
+// rather than a glob, URL, command, or placeholder.
+func isDocFileRef(ref string) bool {
+	if strings.ContainsAny(ref, " *<>{}$|") || strings.Contains(ref, "://") || strings.HasPrefix(ref, "~") {
+		return false
+	}
+	if strings.HasPrefix(ref, "-") || strings.HasPrefix(ref, "@") {
+		return false
+	}
+	file := docLineSuffixRe.ReplaceAllString(ref, "")
+	if strings.HasSuffix(file, "/") {
+		return strings.Count(file, "/") >= 1
+	}
+	switch path.Ext(file) {
+	case ".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".md", ".yaml", ".yml", ".json", ".toml":
+		return true
+	}
+	return false
+}
+
+// docFileExists reports whether file is on disk next to the doc or from the
+// repo root, or is the tail of an indexed file's path.
+func docFileExists(repoPath, dir, file string, index docIndex) bool {
+	file = strings.TrimPrefix(file, "./")
+	for _, base := range []string{dir, ""} {
+		if _, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(path.Join(base, file)))); err == nil {
+			return true
+		}
+	}
+	trimmed := strings.TrimSuffix(file, "/")
+	for indexed := range index.files {
+		if indexed == trimmed || strings.HasSuffix(indexed, "/"+trimmed) {
+			return true
+		}
+		if strings.HasSuffix(file, "/") && (strings.HasPrefix(indexed, trimmed+"/") || strings.Contains(indexed, "/"+trimmed+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// docSymbolExists reports whether a type or call reference names an indexed
+// symbol. Qualified references (Type.Method, pkg.Func, idx.Run()) exist if
+// any component does, since methods of small types are indexed with the
+// type and receivers are variables.
+// It returns the name to report when none does.
+func docSymbolExists(ref string, index docIndex) (string, bool) {
+	name := ref
+	if open := strings.Index(name, "("); open >= 0 {
+		name = name[:open]
+	}
+	for _, p := range strings.Split(name, ".") {
+		if index.symbols[p] {
+			return name, true
+		}
+	}
+	return name, false
+}
+
+// undocumented returns the entries directly under dir (subdirectories with
+// "/" appended, and source files) holding indexed code that the doc text
+// never mentions by name.
+func undocumented(dir, text string, index docIndex) []string {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	entries := map[string]bool{}
+	for f := range index.files {
+		if !strings.HasPrefix(f, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(f, prefix)
+		if sub, _, nested := strings.Cut(rest, "/"); nested {
+			entries[sub+"/"] = true
+		} else {
+			entries[rest] = true
+		}
+	}
+
+	var missing []string
+	for entry := range entries {
+		name := strings.TrimSuffix(entry, "/")
+		if mentionsName(text, name) {
+			continue
+		}
+		missing = append(missing, entry)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// mentionsName reports whether text contains name as a whole word or path
+// component.
+func mentionsName(text, name string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], name)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(name)
+		if (start == 0 || !isNameByte(text[start-1])) && (end == len(text) || !isNameByte(text[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isNameByte(b byte) bool {
+	return b == '_' || b == '-' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintNavigationDoc(t *testing.T) {
+	repo := t.TempDir()
+	for _, rel := range []string{"AGENTS.md", "docs/plans/design.md", "internal/search/handler.go"} {
+		path := filepath.Join(repo, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+
+	index := docIndex{
+		files: map[string]bool{
+			"internal/search/handler.go":    true,
+			"internal/search/docs.go":       true,
+			"internal/search/assemble.go":   true,
+			"internal/search/rank/score.go": true,
+		},
+		symbols: map[string]bool{"Handler": true, "searchDocs": true, "NewHandler": true},
+	}
+	content := "# search package\n\n" +
+		"`Handler` in `handler.go:40-60` is built by `NewHandler()` and serves `h.searchDocs()` (`docs.go`).\n" +
+		"Design: `docs/plans/design.md`; see `AGENTS.md` and `search/handler.go`.\n" +
+		"Gone: `ranker.go`, `Ranker`, `Handler.Rank()`, `score_all()`, and `legacy/`.\n" +
+		"Not references: `JSON`, `search.hybrid`, `*.go`, `code-indexer index --all`, `true`.\n"
+
+	lint := lintNavigationDoc(repo, "internal/search/CLAUDE.md", []byte(content), index)
+	assert.Equal(t, "internal/search", lint.Dir)
+	assert.Equal(t, []string{"legacy/", "ranker.go"}, lint.BrokenFiles)
+	assert.Equal(t, []string{"Ranker", "score_all"}, lint.BrokenSymbols, "Handler.Rank resolves through Handler")
+	assert.Equal(t, []string{"assemble.go", "rank/"}, lint.Undocumented)
+	assert.True(t, lint.Broken())
+	assert.True(t, lint.Drifted())
+
+	report := &DocsLintReport{Docs: []DocLint{{Path: "AGENTS.md", Undocumented: []string{"cmd/"}}}}
+	assert.False(t, report.Broken(), "drift alone is not broken")
+	report.Docs = append(report.Docs, lint)
+	assert.True(t, report.Broken())
+}
+
+func TestFindNavigationDocs(t *testing.T) {
+	repo := t.TempDir()
+	for _, rel := range []string{"AGENTS.md", "internal/search/CLAUDE.md", ".git/CLAUDE.md", "node_modules/pkg/AGENTS.md", "README.md"} {
+		path := filepath.Join(repo, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+	}
+	assert.Equal(t, []string{"AGENTS.md", "internal/search/CLAUDE.md"}, findNavigationDocs(repo))
+}
@@ -0,0 +1,105 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/docs"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+)
+
+// indexDocSite indexes the pages of the repo's Sphinx or MkDocs site as
+// "site" doc chunks, each listing the code its section references. Repos
+// without a site return nil.
+func (idx *Indexer) indexDocSite(repoPath, repo string) []chunk.Chunk {
+	site := docs.DetectSite(repoPath)
+	if site == nil {
+		return nil
+	}
+	idx.logger.Info("indexing doc site", "generator", site.Generator, "dir", site.Dir)
+
+	var allChunks []chunk.Chunk
+	err := filepath.WalkDir(filepath.Join(repoPath, site.Dir), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip errors
+		}
+		if d.IsDir() {
+			// _build is Sphinx's output directory
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || name == "_build" || name == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Navigation docs are indexed on their own
+		if d.Name() == "AGENTS.md" || d.Name() == "CLAUDE.md" {
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(repoPath, path)
+		relPath = filepath.ToSlash(relPath)
+		// So are ADRs
+		if !site.IsPage(relPath) || docs.IsADR(relPath) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			idx.logger.Warn("failed to read doc page", "path", path, "error", err)
+			return nil
+		}
+
+		chunks := docs.ParseSitePage(content, relPath, repo).ToChunks()
+		for i := range chunks {
+			chunks[i].RetrievalWeight = float32(chunkWeights(idx.config).Site)
+		}
+		allChunks = append(allChunks, chunks...)
+		return nil
+	})
+	if err != nil {
+		idx.logger.Warn("error walking doc site", "error", err)
+	}
+
+	// Pages are prose, but they hold example credentials as often as code does
+	allChunks, skipped := idx.extractor.ApplySecretPolicy(allChunks)
+	if skipped > 0 {
+		idx.logger.Info("doc site sections skipped for secrets", "count", skipped)
+	}
+	return allChunks
+}
+
+// storeDocLinks replaces the repo's DocChunk nodes with the site chunks that
+// reference code, linking each to the symbols and files it describes.
+func (idx *Indexer) storeDocLinks(ctx context.Context, graphStore *graph.Neo4jStore, repo string, chunks []chunk.Chunk) {
+	links := make([]graph.DocChunk, 0, len(chunks))
+	for _, c := range chunks {
+		symbols, modules, files := docs.Describes(c)
+		if len(symbols) == 0 && len(modules) == 0 && len(files) == 0 {
+			continue
+		}
+		link := graph.DocChunk{
+			ID:          c.ID,
+			Repo:        repo,
+			FilePath:    c.FilePath,
+			HeadingPath: c.HeadingPath,
+			StartLine:   c.StartLine,
+			Files:       files,
+		}
+		for _, target := range symbols {
+			for _, t := range docs.SymbolTargets(target) {
+				link.Symbols = append(link.Symbols, graph.DocSymbol{Name: t.Name, Parent: t.Parent, Files: t.Files})
+			}
+		}
+		for _, target := range modules {
+			link.Files = append(link.Files, docs.ModuleFiles(target)...)
+		}
+		links = append(links, link)
+	}
+
+	idx.logger.Info("linking doc site to code", "sections", len(links))
+	if err := graphStore.ReplaceDocChunks(ctx, repo, links); err != nil {
+		idx.logger.Warn("failed to store doc links", "repo", repo, "error", err)
+	}
+}
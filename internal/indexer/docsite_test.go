@@ -0,0 +1,43 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/docs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexDocSite(t *testing.T) {
+	repo := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(repo, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write("mkdocs.yml", "site_name: App\n")
+	write("docs/api.md", "# API\n\n::: app.client.Client\n\nConnects to postgres://app:supersecret123456@db:5432/app\n")
+	write("docs/CLAUDE.md", "# Docs\n\nNavigation, indexed on its own.\n")
+	write("docs/_build/api.md", "# Built\n\nOutput.\n")
+	write("README.md", "# App\n\nNot part of the site.\n")
+
+	cfg := config.DefaultConfig()
+	cfg.Search.Weights.Site = 1.4
+	idx := NewIndexerWithClients(cfg, nil, nil)
+	require.NoError(t, idx.configureExtractor(&config.RepoConfig{Name: "app"}))
+
+	chunks := idx.indexDocSite(repo, "app")
+	require.Len(t, chunks, 1)
+	c := chunks[0]
+	assert.Equal(t, "docs/api.md", c.FilePath)
+	assert.Equal(t, "site", c.Kind)
+	assert.InDelta(t, 1.4, c.RetrievalWeight, 1e-6)
+	assert.Equal(t, "app.client.Client", c.Metadata[docs.MetaDescribesSymbols])
+	assert.True(t, c.HasSecrets)
+	assert.NotContains(t, c.Content, "supersecret123456")
+
+	assert.Nil(t, idx.indexDocSite(t.TempDir(), "app"), "repos without a site")
+}
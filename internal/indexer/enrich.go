@@ -0,0 +1,57 @@
+package indexer
+
+import (
+	"context"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+)
+
+// Enricher adds custom data to chunks before they are embedded, such as
+// tagging them with the service that owns them. Enrich may change any
+// field; keys the index does not know about belong in Metadata, which is
+// stored in the payload and returned with search results.
+type Enricher interface {
+	Enrich(ctx context.Context, c *chunk.Chunk) error
+}
+
+// RunEnricher is an Enricher with per-repo state, loaded at the start of
+// every run (e.g. the repo's CODEOWNERS file).
+type RunEnricher interface {
+	Enricher
+	StartRun(ctx context.Context, repoPath string, repoCfg *config.RepoConfig) error
+}
+
+// AddEnricher makes runs pass every chunk through e, after the enrichers
+// added before it.
+func (idx *Indexer) AddEnricher(e Enricher) {
+	idx.enrichers = append(idx.enrichers, e)
+}
+
+// startEnrichers returns the enrichers to use for a run on repoPath. One
+// whose StartRun fails is logged and left out of the run.
+func (idx *Indexer) startEnrichers(ctx context.Context, repoPath string, repoCfg *config.RepoConfig) []Enricher {
+	enrichers := make([]Enricher, 0, len(idx.enrichers))
+	for _, e := range idx.enrichers {
+		if re, ok := e.(RunEnricher); ok {
+			if err := re.StartRun(ctx, repoPath, repoCfg); err != nil {
+				idx.logger.Warn("enricher disabled for this run", "repo", repoCfg.Name, "error", err)
+				continue
+			}
+		}
+		enrichers = append(enrichers, e)
+	}
+	return enrichers
+}
+
+// enrichBatch runs the enrichers over batch. Enrichment is best effort: a
+// failure is logged and the chunk is indexed with whatever it has.
+func (idx *Indexer) enrichBatch(ctx context.Context, enrichers []Enricher, batch []chunk.Chunk) {
+	for i := range batch {
+		for _, e := range enrichers {
+			if err := e.Enrich(ctx, &batch[i]); err != nil && ctx.Err() == nil {
+				idx.logger.Warn("failed to enrich chunk", "path", batch[i].FilePath, "symbol", batch[i].SymbolName, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serviceEnricher tags chunks with the top-level directory of their file.
+type serviceEnricher struct{}
+
+func (serviceEnricher) Enrich(_ context.Context, c *chunk.Chunk) error {
+	service, _, ok := strings.Cut(c.FilePath, "/")
+	if !ok {
+		return errors.New("file outside any service")
+	}
+	if c.Metadata == nil {
+		c.Metadata = make(map[string]string)
+	}
+	c.Metadata["service"] = service
+	return nil
+}
+
+// repoEnricher records the repo it was started for, or refuses to start.
+type repoEnricher struct {
+	startErr error
+	repo     string
+}
+
+func (e *repoEnricher) StartRun(_ context.Context, _ string, repoCfg *config.RepoConfig) error {
+	e.repo = repoCfg.Name
+	return e.startErr
+}
+
+func (e *repoEnricher) Enrich(_ context.Context, c *chunk.Chunk) error {
+	if c.Metadata == nil {
+		c.Metadata = make(map[string]string)
+	}
+	c.Metadata["repo"] = e.repo
+	return nil
+}
+
+func TestEnrichBatch(t *testing.T) {
+	idx := &Indexer{logger: slog.Default()}
+	started := &repoEnricher{}
+	idx.AddEnricher(serviceEnricher{})
+	idx.AddEnricher(started)
+	idx.AddEnricher(&repoEnricher{startErr: errors.New("no CODEOWNERS")})
+
+	enrichers := idx.startEnrichers(context.Background(), "/repo", &config.RepoConfig{Name: "shop"})
+	require.Len(t, enrichers, 2, "an enricher that fails to start sits the run out")
+
+	batch := []chunk.Chunk{{FilePath: "billing/invoice.py"}, {FilePath: "setup.py"}}
+	idx.enrichBatch(context.Background(), enrichers, batch)
+
+	assert.Equal(t, map[string]string{"service": "billing", "repo": "shop"}, batch[0].Metadata)
+	assert.Equal(t, map[string]string{"repo": "shop"}, batch[1].Metadata, "a failed enricher does not stop the others")
+}
+
+func TestChunkPipelineEnrichesBeforeSummarizing(t *testing.T) {
+	var stored []chunk.Chunk
+	store := func(_ context.Context, batch []chunk.Chunk) error {
+		stored = append(stored, batch...)
+		return nil
+	}
+
+	p := newChunkPipeline(context.Background(), fakeEmbed, store, IndexOptions{}.report, nil)
+	p.enrich = func(_ context.Context, batch []chunk.Chunk) {
+		for i := range batch {
+			batch[i].Metadata = map[string]string{"service": "billing"}
+		}
+	}
+	p.summarize = func(_ context.Context, batch []chunk.Chunk) {
+		for i := range batch {
+			batch[i].Summary = "Owned by " + batch[i].Metadata["service"]
+		}
+	}
+	p.add(chunk.Chunk{ID: "a", Content: "def charge(): ..."})
+	_, err := p.close()
+	require.NoError(t, err)
+
+	require.Len(t, stored, 1)
+	assert.Equal(t, "billing", stored[0].Metadata["service"])
+	assert.Equal(t, "Owned by billing", stored[0].Summary)
+}
+
+func TestFork(t *testing.T) {
+	idx := NewIndexerWithClients(config.DefaultConfig(), nil, nil)
+	idx.AddEnricher(serviceEnricher{})
+
+	fork := idx.Fork()
+	assert.NotSame(t, idx.extractor, fork.extractor, "per-run extractor settings are not shared")
+	assert.NotSame(t, idx.patternDetector, fork.patternDetector)
+	assert.Equal(t, idx.enrichers, fork.enrichers)
+
+	fork.AddEnricher(serviceEnricher{})
+	assert.Len(t, idx.enrichers, 1, "enrichers added to a fork stay on it")
+}
@@ -0,0 +1,214 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/parser"
+)
+
+// ErrExcluded is returned by IndexFile for files a full index would skip.
+var ErrExcluded = errors.New("excluded from indexing")
+
+// IndexFile re-indexes a single repo-relative file: its existing chunks are
+// replaced with freshly extracted and embedded ones. A file that no longer
+// exists only has its chunks removed. Files the walker would skip (exclude
+// patterns, .gitignore, .indexignore, include roots, size and binary checks)
+// fail with ErrExcluded, so reindexing one never indexes what a full run
+// leaves out. Pattern detection needs the whole repo,
+// so a pattern tag already on the file's chunks is carried over instead.
+//
+// With a graph store the File node and its symbols are rebuilt; call and
+// inheritance edges are only resolved against symbols in the same file until
+// the next full index.
+func (idx *Indexer) IndexFile(ctx context.Context, repoPath string, repoCfg *config.RepoConfig, relPath string, graphStore *graph.Neo4jStore) (*IndexResult, error) {
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+	if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") || filepath.IsAbs(relPath) {
+		return nil, fmt.Errorf("path %q is outside the repository", relPath)
+	}
+	if !NewRepoWalker(repoCfg).Accepts(repoPath, relPath) {
+		return nil, fmt.Errorf("%s: %w", relPath, ErrExcluded)
+	}
+
+	started := time.Now()
+	result := &IndexResult{Commit: loadGitHead(ctx, repoPath), Branch: loadGitBranch(ctx, repoPath)}
+
+	collectionName := "chunks"
+	if err := idx.store.EnsureCollection(ctx, collectionName, idx.embedder.Dimension()); err != nil {
+		return nil, fmt.Errorf("failed to ensure collection: %w", err)
+	}
+
+	fileFilter := map[string]interface{}{
+		"repo":      repoCfg.Name,
+		"file_path": relPath,
+	}
+
+	var followsPattern string
+	if existing, err := idx.store.SearchByFilter(ctx, collectionName, fileFilter, 1); err == nil && len(existing) > 0 {
+		followsPattern = existing[0].FollowsPattern
+	}
+
+	source, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(relPath)))
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := idx.removeFile(ctx, collectionName, repoCfg.Name, relPath, graphStore); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", relPath, err)
+	}
+
+	resolver := NewModuleResolver(repoPath, repoCfg)
+	modulePath, moduleRoot, _ := resolver.Resolve(relPath)
+	if err := idx.configureExtractor(repoCfg); err != nil {
+		return nil, err
+	}
+
+	extractResult, err := idx.extractor.ExtractWithRelationships(source, relPath, repoCfg.Name, modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("extract %s: %w", relPath, err)
+	}
+	if extractResult.SecretsSkippedFile {
+		idx.logger.Info("skipping file with secrets", "path", relPath)
+		result.SecretFilesSkipped = []string{relPath}
+		idx.dropSecretFiles(ctx, collectionName, graphStore, repoCfg.Name, result)
+		return result, nil
+	}
+	result.SecretChunksSkipped = extractResult.SecretChunksSkipped
+
+	gitInfo := loadFileGitInfo(ctx, repoPath, relPath)
+	workspace := workspaceOf(loadDirtyFiles(ctx, repoPath, relPath), relPath)
+	owners := loadCodeOwners(repoPath).Owners(relPath)
+	license := newLicenseResolver(repoPath, repoCfg.License).License(relPath, source)
+	chunks := extractResult.Chunks
+	for i := range chunks {
+		chunks[i].License = license
+		chunks[i].Branch = result.Branch
+		chunks[i].Workspace = workspace
+		chunks[i].Owners = owners
+		chunks[i].LastAuthor = gitInfo.AuthorName
+		chunks[i].LastAuthorEmail = gitInfo.AuthorEmail
+		chunks[i].LastCommit = gitInfo.Commit
+		chunks[i].FollowsPattern = followsPattern
+	}
+
+	if len(chunks) > 0 {
+		idx.enrichBatch(ctx, idx.startEnrichers(ctx, repoPath, repoCfg), chunks)
+		if idx.summarizer != nil {
+			idx.summarizeBatch(ctx, chunks)
+		}
+		texts := make([]string, len(chunks))
+		for i, c := range chunks {
+			texts[i] = buildEmbeddingText(c)
+		}
+
+		vectors, tokens, err := idx.embedder.EmbedBatchedWithUsage(ctx, texts, 64)
+		result.EmbeddingTokens = tokens
+		idx.logSpend(repoCfg.Name, "file", started, result)
+		if err != nil {
+			return nil, fmt.Errorf("embedding failed: %w", err)
+		}
+		for i := range chunks {
+			chunks[i].Vector = vectors[i]
+		}
+	}
+
+	// Embed before deleting so a failed embedding leaves the old chunks searchable
+	if err := idx.store.DeleteByFilter(ctx, collectionName, fileFilter); err != nil {
+		return nil, fmt.Errorf("delete chunks: %w", err)
+	}
+
+	batchSize := 100
+	for i := 0; i < len(chunks); i += batchSize {
+		end := min(i+batchSize, len(chunks))
+		if err := idx.store.UpsertChunks(ctx, collectionName, chunks[i:end]); err != nil {
+			return nil, fmt.Errorf("upsert failed: %w", err)
+		}
+	}
+
+	result.FilesProcessed = 1
+	result.ChunksCreated = len(chunks)
+
+	if graphStore != nil {
+		idx.reindexFileGraph(ctx, graphStore, repoCfg.Name, graph.File{
+			Path:        relPath,
+			Repo:        repoCfg.Name,
+			ModuleRoot:  moduleRoot,
+			Hash:        computeFileHash(source),
+			LastIndexed: time.Now(),
+
+			LastAuthor:      gitInfo.AuthorName,
+			LastAuthorEmail: gitInfo.AuthorEmail,
+			LastCommit:      gitInfo.Commit,
+			LastModified:    gitInfo.Timestamp,
+			Owners:          owners,
+		}, source, extractResult.Relationships)
+	}
+
+	return result, nil
+}
+
+// reindexFileGraph replaces a file's node and symbols in the graph. Imports
+// resolve against every file the graph knows about.
+func (idx *Indexer) reindexFileGraph(ctx context.Context, graphStore *graph.Neo4jStore, repo string, file graph.File, source []byte, relationships []parser.Relationship) {
+	if err := graphStore.DeleteFile(ctx, repo, file.Path); err != nil {
+		idx.logger.Warn("failed to delete file from graph", "path", file.Path, "error", err)
+	}
+	if err := graphStore.UpsertFile(ctx, file); err != nil {
+		idx.logger.Warn("failed to update file hash", "path", file.Path, "error", err)
+		return
+	}
+
+	symbols := idx.extractSymbols(source, file.Path)
+	for _, sym := range symbols {
+		graphSym := graph.Symbol{
+			Name:      sym.Name,
+			Kind:      string(sym.Kind),
+			Repo:      repo,
+			FilePath:  sym.FilePath,
+			StartLine: sym.StartLine,
+			EndLine:   sym.EndLine,
+			Signature: sym.Signature,
+		}
+		if err := graphStore.UpsertSymbol(ctx, graphSym); err != nil {
+			idx.logger.Debug("failed to store symbol", "name", sym.Name, "error", err)
+		}
+	}
+
+	if len(relationships) == 0 {
+		return
+	}
+
+	hashes, err := graphStore.GetAllFileHashes(ctx, repo)
+	if err != nil {
+		idx.logger.Warn("failed to list files for import resolution", "repo", repo, "error", err)
+	}
+	files := make([]graph.File, 0, len(hashes))
+	for path := range hashes {
+		files = append(files, graph.File{Path: path})
+	}
+	idx.storeRelationships(ctx, graphStore, repo, relationships, symbols, idx.buildModulePathMap(files))
+}
+
+// removeFile deletes a file's chunks and, with a graph store, its File node.
+func (idx *Indexer) removeFile(ctx context.Context, collection, repo, relPath string, graphStore *graph.Neo4jStore) error {
+	filter := map[string]interface{}{"repo": repo, "file_path": relPath}
+	if err := idx.store.DeleteByFilter(ctx, collection, filter); err != nil {
+		return fmt.Errorf("delete chunks: %w", err)
+	}
+	if graphStore != nil {
+		if err := graphStore.DeleteFile(ctx, repo, relPath); err != nil {
+			idx.logger.Warn("failed to delete file from graph", "path", relPath, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexFileRejectsPathsOutsideRepo(t *testing.T) {
+	idx := &Indexer{}
+	repoCfg := &config.RepoConfig{Name: "demo"}
+
+	for _, p := range []string{"", ".", "..", "../other/file.py"} {
+		_, err := idx.IndexFile(context.Background(), t.TempDir(), repoCfg, p, nil)
+		assert.Error(t, err, "path %q", p)
+	}
+}
+
+func TestIndexFileRejectsExcludedPaths(t *testing.T) {
+	repoPath := t.TempDir()
+	files := map[string]string{
+		".gitignore":       "build/\n",
+		".indexignore":     "fixtures/\n",
+		"build/gen.py":     "x = 1\n",
+		"fixtures/data.py": "x = 1\n",
+		"vendor/lib.py":    "x = 1\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoPath, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	idx := &Indexer{}
+	repoCfg := &config.RepoConfig{Name: "demo", Exclude: []string{"vendor/**"}}
+
+	for _, p := range []string{"build/gen.py", "fixtures/data.py", "vendor/lib.py", "vendor/deleted.py"} {
+		_, err := idx.IndexFile(context.Background(), repoPath, repoCfg, p, nil)
+		assert.ErrorIs(t, err, ErrExcluded, "path %q", p)
+	}
+}
@@ -0,0 +1,168 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitFileInfo is the last commit that touched a file.
+type GitFileInfo struct {
+	Commit      string
+	AuthorName  string
+	AuthorEmail string
+	Timestamp   time.Time
+}
+
+// loadGitHistory returns the most recent commit for every tracked file in
+// repoPath, keyed by repo-relative path. It runs a single `git log` rather than
+// one `git log -1` per file. Non-git directories yield an empty map.
+func loadGitHistory(ctx context.Context, repoPath string) map[string]GitFileInfo {
+	cmd := exec.CommandContext(ctx, "git", "-C", repoPath, "log", "--name-only", "--no-renames",
+		"--format=%x1e%H%x00%an%x00%ae%x00%ct")
+	output, err := cmd.Output()
+	if err != nil {
+		return map[string]GitFileInfo{}
+	}
+	return parseGitLog(output)
+}
+
+// loadGitBranch returns the branch checked out in repoPath, or "" when HEAD
+// is detached or the directory is not a git checkout.
+func loadGitBranch(ctx context.Context, repoPath string) string {
+	output, err := exec.CommandContext(ctx, "git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	branch := strings.TrimSpace(string(output))
+	if branch == "HEAD" {
+		return ""
+	}
+	return branch
+}
+
+// loadGitHead returns the commit checked out in repoPath, or "" when the
+// directory is not a git checkout.
+func loadGitHead(ctx context.Context, repoPath string) string {
+	output, err := exec.CommandContext(ctx, "git", "-C", repoPath, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// DirtyFiles returns the repo-relative paths of repoPath (limited to paths
+// when given) that differ from HEAD: modified, added, deleted, and untracked
+// files, and both sides of renames (copies only count as added). Ignored files and submodules are left
+// out. It fails outside a git checkout.
+func DirtyFiles(ctx context.Context, repoPath string, paths ...string) ([]string, error) {
+	args := []string{"-C", repoPath, "status", "--porcelain", "-z", "--untracked-files=all", "--ignore-submodules=all"}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	output, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", err)
+	}
+	return parseGitStatus(output), nil
+}
+
+// loadDirtyFiles returns DirtyFiles as a set; nil when it failed, so
+// callers can tell an unknown state from a clean tree.
+func loadDirtyFiles(ctx context.Context, repoPath string, paths ...string) map[string]bool {
+	files, err := DirtyFiles(ctx, repoPath, paths...)
+	if err != nil {
+		return nil
+	}
+	dirty := make(map[string]bool, len(files))
+	for _, path := range files {
+		dirty[path] = true
+	}
+	return dirty
+}
+
+// parseGitStatus parses `git status --porcelain -z` output: "XY path"
+// entries, where renames and copies are followed by their original path.
+// Paths are returned sorted and deduplicated.
+func parseGitStatus(output []byte) []string {
+	var paths []string
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		paths = append(paths, entry[3:])
+		if strings.ContainsAny(entry[:2], "RC") {
+			// The original path: gone after a rename, unchanged after a copy
+			if i+1 < len(entries) && entries[i+1] != "" && strings.Contains(entry[:2], "R") {
+				paths = append(paths, entries[i+1])
+			}
+			i++
+		}
+	}
+	slices.Sort(paths)
+	return slices.Compact(paths)
+}
+
+// parseGitLog parses `git log --name-only` output produced with the record
+// format used by loadGitHistory. Commits are newest first, so the first
+// occurrence of a path wins.
+func parseGitLog(output []byte) map[string]GitFileInfo {
+	history := make(map[string]GitFileInfo)
+
+	for _, record := range bytes.Split(output, []byte{0x1e}) {
+		if len(bytes.TrimSpace(record)) == 0 {
+			continue
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(record))
+		if !scanner.Scan() {
+			continue
+		}
+
+		header := strings.Split(scanner.Text(), "\x00")
+		if len(header) != 4 {
+			continue
+		}
+		unix, _ := strconv.ParseInt(header[3], 10, 64)
+		info := GitFileInfo{
+			Commit:      header[0],
+			AuthorName:  header[1],
+			AuthorEmail: header[2],
+			Timestamp:   time.Unix(unix, 0).UTC(),
+		}
+
+		for scanner.Scan() {
+			path := strings.TrimSpace(scanner.Text())
+			if path == "" {
+				continue
+			}
+			if _, seen := history[path]; !seen {
+				history[path] = info
+			}
+		}
+	}
+
+	return history
+}
+
+// loadFileGitInfo returns the last commit that touched a single file, or the
+// zero value when the file is untracked or repoPath is not a git checkout.
+func loadFileGitInfo(ctx context.Context, repoPath, relPath string) GitFileInfo {
+	relPath = filepath.ToSlash(relPath)
+	cmd := exec.CommandContext(ctx, "git", "-C", repoPath, "log", "-1", "--name-only", "--no-renames",
+		"--format=%x1e%H%x00%an%x00%ae%x00%ct", "--", relPath)
+	output, err := cmd.Output()
+	if err != nil {
+		return GitFileInfo{}
+	}
+	return parseGitLog(output)[relPath]
+}
@@ -0,0 +1,100 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitLog(t *testing.T) {
+	output := []byte("\x1eaaa\x00Alice\x00alice@example.com\x001700000200\n\nsrc/a.py\n" +
+		"\x1ebbb\x00Bob\x00bob@example.com\x001700000100\n\nsrc/a.py\nsrc/b.py\n")
+
+	history := parseGitLog(output)
+
+	require.Len(t, history, 2)
+	assert.Equal(t, "aaa", history["src/a.py"].Commit, "newest commit wins")
+	assert.Equal(t, "Alice", history["src/a.py"].AuthorName)
+	assert.Equal(t, "bob@example.com", history["src/b.py"].AuthorEmail)
+	assert.Equal(t, int64(1700000100), history["src/b.py"].Timestamp.Unix())
+}
+
+func TestLoadGitHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		require.NoError(t, cmd.Run())
+	}
+
+	run("init")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.py"), []byte("x = 1\n"), 0644))
+	run("add", ".")
+	run("commit", "-m", "initial")
+
+	history := loadGitHistory(context.Background(), tmpDir)
+
+	require.Contains(t, history, "main.py")
+	assert.Equal(t, "Test", history["main.py"].AuthorName)
+	assert.Len(t, history["main.py"].Commit, 40)
+	assert.Equal(t, history["main.py"].Commit, loadGitHead(context.Background(), tmpDir))
+
+	info := loadFileGitInfo(context.Background(), tmpDir, "main.py")
+	assert.Equal(t, history["main.py"], info)
+	assert.Empty(t, loadFileGitInfo(context.Background(), tmpDir, "missing.py").Commit)
+}
+
+func TestLoadGitHistoryNotARepo(t *testing.T) {
+	assert.Empty(t, loadGitHistory(context.Background(), t.TempDir()))
+	assert.Empty(t, loadGitHead(context.Background(), t.TempDir()))
+}
+
+func TestParseGitStatus(t *testing.T) {
+	output := []byte(" M src/a.py\x00R  new.py\x00old.py\x00C  copy.py\x00orig.py\x00?? notes/todo.md\x00 D gone.py\x00")
+
+	assert.Equal(t, []string{"copy.py", "gone.py", "new.py", "notes/todo.md", "old.py", "src/a.py"}, parseGitStatus(output))
+	assert.Empty(t, parseGitStatus(nil))
+}
+
+func TestDirtyFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		require.NoError(t, cmd.Run())
+	}
+	run("init")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.py"), []byte("x = 1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "util.py"), []byte("y = 1\n"), 0644))
+	run("add", ".")
+	run("commit", "-m", "initial")
+
+	dirty, err := DirtyFiles(context.Background(), tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, dirty)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.py"), []byte("x = 2\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "pkg", "new.py"), []byte("z = 1\n"), 0644))
+	dirty, err = DirtyFiles(context.Background(), tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.py", "pkg/new.py"}, dirty, "untracked directories are listed file by file")
+
+	dirty, err = DirtyFiles(context.Background(), tmpDir, "util.py")
+	require.NoError(t, err)
+	assert.Empty(t, dirty)
+
+	_, err = DirtyFiles(context.Background(), t.TempDir())
+	assert.Error(t, err)
+	assert.Nil(t, loadDirtyFiles(context.Background(), t.TempDir()))
+}
@@ -0,0 +1,140 @@
+package indexer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RunRecord is one index run in the history log.
+type RunRecord struct {
+	Repo       string    `json:"repo"`
+	Path       string    `json:"path"`
+	Trigger    string    `json:"trigger,omitempty"` // What started the run, e.g. "cli" or "watch"
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+
+	Incremental bool   `json:"incremental"`
+	Resume      bool   `json:"resume,omitempty"`
+	Module      string `json:"module,omitempty"`
+	Workers     int    `json:"workers,omitempty"`
+
+	FilesProcessed   int           `json:"files_processed"`
+	FilesSkipped     int           `json:"files_skipped"`
+	FilesDeleted     int           `json:"files_deleted"`
+	FilesRenamed     int           `json:"files_renamed"`
+	FilesResumed     int           `json:"files_resumed"`
+	Filtered         WalkStats     `json:"filtered"`
+	ChunksCreated    int           `json:"chunks_created"`
+	ChunksDeduped    int           `json:"chunks_deduped,omitempty"`
+	ChunksSummarized int           `json:"chunks_summarized,omitempty"`
+	SecretFiles      []string      `json:"secret_files_skipped,omitempty"`  // Left out for containing secrets
+	SecretChunks     int           `json:"secret_chunks_skipped,omitempty"` // Left out for containing secrets
+	EmbeddingTokens  int           `json:"embedding_tokens"`
+	Commit           string        `json:"commit,omitempty"`
+	Errors           []string      `json:"errors"`
+	ErrorPhases      map[Phase]int `json:"error_phases,omitempty"` // Errors per phase
+	FailedFiles      []string      `json:"failed_files,omitempty"`
+	Failed           string        `json:"failed,omitempty"` // Why the run stopped; empty when it completed
+}
+
+// Duration is how long the run took.
+func (r RunRecord) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// newRunRecord summarizes a finished run. result may be nil when the run
+// failed before producing one.
+func newRunRecord(repoPath, repo string, opts IndexOptions, started time.Time, result *IndexResult, err error) RunRecord {
+	rec := RunRecord{
+		Repo:        repo,
+		Path:        repoPath,
+		Trigger:     opts.Trigger,
+		StartedAt:   started.UTC(),
+		FinishedAt:  time.Now().UTC(),
+		Incremental: opts.Incremental,
+		Resume:      opts.Resume,
+		Module:      opts.Module,
+		Workers:     opts.Workers,
+		Errors:      []string{},
+	}
+	if err != nil {
+		rec.Failed = err.Error()
+	}
+	if result == nil {
+		return rec
+	}
+	rec.FilesProcessed = result.FilesProcessed
+	rec.FilesSkipped = result.FilesSkipped
+	rec.FilesDeleted = result.FilesDeleted
+	rec.FilesRenamed = len(result.Renamed)
+	rec.FilesResumed = result.FilesResumed
+	rec.Filtered = result.Filtered
+	rec.ChunksCreated = result.ChunksCreated
+	rec.ChunksDeduped = result.ChunksDeduped
+	rec.ChunksSummarized = result.ChunksSummarized
+	rec.SecretFiles = result.SecretFilesSkipped
+	rec.SecretChunks = result.SecretChunksSkipped
+	rec.EmbeddingTokens = result.EmbeddingTokens
+	rec.Commit = result.Commit
+	for _, e := range result.Errors {
+		rec.Errors = append(rec.Errors, e.Error())
+	}
+	rec.ErrorPhases = result.ErrorCounts()
+	rec.FailedFiles = result.FailedFiles
+	return rec
+}
+
+// AppendRun adds a run to the history log at path, creating it if needed.
+// Each record is a single write, so concurrent runs do not interleave.
+func AppendRun(path string, rec RunRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create history dir: %w", err)
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode run: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	return nil
+}
+
+// LoadRuns reads the history log, oldest run first. A missing log yields no
+// runs; unreadable lines are skipped.
+func LoadRuns(path string) ([]RunRecord, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	defer f.Close()
+
+	var runs []RunRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // Error lists can be long
+	for scanner.Scan() {
+		var rec RunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil || rec.Repo == "" {
+			continue
+		}
+		runs = append(runs, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,61 @@
+package indexer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "code-index", "history.jsonl")
+
+	runs, err := LoadRuns(path)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	started := time.Now().Add(-time.Minute)
+	result := &IndexResult{
+		FilesProcessed:  10,
+		FilesSkipped:    2,
+		Renamed:         []Rename{{From: "a.py", To: "b.py"}},
+		ChunksCreated:   40,
+		EmbeddingTokens: 1234,
+		Commit:          "abc123",
+		Errors:          []error{errors.New("parse c.py: bad syntax")},
+	}
+	opts := IndexOptions{Incremental: true, Module: "fisio.imports", Trigger: "cli"}
+	require.NoError(t, AppendRun(path, newRunRecord("/repos/demo", "demo", opts, started, result, nil)))
+	require.NoError(t, AppendRun(path, newRunRecord("/repos/demo", "demo", IndexOptions{}, started, nil, errors.New("qdrant down"))))
+
+	// A torn line from a crashed writer is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"repo":"dem`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	runs, err = LoadRuns(path)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+
+	first := runs[0]
+	assert.Equal(t, "demo", first.Repo)
+	assert.Equal(t, "cli", first.Trigger)
+	assert.True(t, first.Incremental)
+	assert.Equal(t, "fisio.imports", first.Module)
+	assert.Equal(t, 10, first.FilesProcessed)
+	assert.Equal(t, 1, first.FilesRenamed)
+	assert.Equal(t, 1234, first.EmbeddingTokens)
+	assert.Equal(t, []string{"parse c.py: bad syntax"}, first.Errors)
+	assert.Empty(t, first.Failed)
+	assert.InDelta(t, time.Minute.Seconds(), first.Duration().Seconds(), 1)
+
+	assert.Equal(t, "qdrant down", runs[1].Failed)
+	assert.Zero(t, runs[1].ChunksCreated)
+	assert.Empty(t, runs[1].Errors)
+}
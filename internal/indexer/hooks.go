@@ -0,0 +1,77 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultHookTimeout bounds each hook command unless the repo sets its own.
+const defaultHookTimeout = 5 * time.Minute
+
+// Hook stages, passed to commands as CODE_INDEX_HOOK.
+const (
+	HookPreIndex  = "pre_index"
+	HookPostIndex = "post_index"
+)
+
+// runHooks runs commands in order with sh -c in repoPath, feeding each the
+// record as JSON on stdin. It stops at the first failure. Commands see
+// CODE_INDEX_HOOK (the stage) and CODE_INDEX_REPO in their environment.
+func (idx *Indexer) runHooks(ctx context.Context, stage, repoPath string, commands []string, timeoutSeconds int, rec RunRecord) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	input, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode %s hook input: %w", stage, err)
+	}
+	timeout := defaultHookTimeout
+	if timeoutSeconds > 0 {
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	for _, command := range commands {
+		idx.logger.Info("running hook", "stage", stage, "command", command)
+		if err := runHook(ctx, stage, repoPath, command, timeout, input, rec.Repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runHook(ctx context.Context, stage, repoPath, command string, timeout time.Duration, input []byte, repo string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = repoPath
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(), "CODE_INDEX_HOOK="+stage, "CODE_INDEX_REPO="+repo)
+	cmd.WaitDelay = time.Second // Don't wait on pipes held by orphaned children
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if tail := lastLines(string(output), 5); tail != "" {
+		return fmt.Errorf("%s hook %q: %w: %s", stage, command, err, tail)
+	}
+	return fmt.Errorf("%s hook %q: %w", stage, command, err)
+}
+
+// lastLines returns the last n lines of output, joined by "; ".
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "; "))
+}
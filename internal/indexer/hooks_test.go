@@ -0,0 +1,60 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHooks(t *testing.T) {
+	repoPath := t.TempDir()
+	idx := &Indexer{logger: slog.Default()}
+	rec := RunRecord{Repo: "demo", ChunksCreated: 42, Errors: []string{}}
+
+	commands := []string{
+		"cat > summary.json",
+		`echo "$CODE_INDEX_HOOK $CODE_INDEX_REPO" > env.txt`,
+	}
+	require.NoError(t, idx.runHooks(context.Background(), HookPostIndex, repoPath, commands, 0, rec))
+
+	data, err := os.ReadFile(filepath.Join(repoPath, "summary.json"))
+	require.NoError(t, err)
+	var got RunRecord
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "demo", got.Repo)
+	assert.Equal(t, 42, got.ChunksCreated)
+
+	env, err := os.ReadFile(filepath.Join(repoPath, "env.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "post_index demo\n", string(env))
+}
+
+func TestRunHooksStopsAtFailure(t *testing.T) {
+	repoPath := t.TempDir()
+	idx := &Indexer{logger: slog.Default()}
+
+	commands := []string{"echo generating; echo protoc: not found >&2; exit 3", "touch ran"}
+	err := idx.runHooks(context.Background(), HookPreIndex, repoPath, commands, 0, RunRecord{Repo: "demo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `pre_index hook "echo generating`)
+	assert.Contains(t, err.Error(), "exit status 3")
+	assert.Contains(t, err.Error(), "protoc: not found")
+	assert.NoFileExists(t, filepath.Join(repoPath, "ran"))
+}
+
+func TestRunHooksTimeout(t *testing.T) {
+	idx := &Indexer{logger: slog.Default()}
+
+	started := time.Now()
+	err := idx.runHooks(context.Background(), HookPreIndex, t.TempDir(), []string{"sleep 30"}, 1, RunRecord{Repo: "demo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 1s")
+	assert.Less(t, time.Since(started), 10*time.Second)
+}
@@ -0,0 +1,121 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// IgnoreFiles are read from every directory the walker enters, in this
+// order, so .indexignore rules override .gitignore ones (including "!"
+// re-includes of what git ignores).
+var IgnoreFiles = []string{".gitignore", ".indexignore"}
+
+// ignoreRule is one pattern line of an ignore file.
+type ignoreRule struct {
+	base     string // Directory of the ignore file: repo-relative, "/"-terminated, "" at the root
+	pattern  string
+	negate   bool // "!pattern" re-includes
+	dirOnly  bool // "pattern/" matches directories only
+	anchored bool // Contains a "/": matched against the path below base, not just the name
+}
+
+// ignoreMatcher applies gitignore rules; the last matching rule wins.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// parseIgnore parses gitignore syntax. base is the repo-relative directory
+// holding the file.
+func parseIgnore(data []byte, base string) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = trimIgnoreSpace(line)
+		if line == "" {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" || !doublestar.ValidatePattern(line) {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// trimIgnoreSpace drops trailing spaces unless escaped with a backslash.
+func trimIgnoreSpace(line string) string {
+	trimmed := strings.TrimRight(line, " \t\r")
+	if strings.HasSuffix(trimmed, `\`) && len(trimmed) < len(line) {
+		return trimmed[:len(trimmed)-1] + " "
+	}
+	return trimmed
+}
+
+// load adds the rules of dir's ignore files. relDir is repo-relative with
+// forward slashes, "" for the root.
+func (m *ignoreMatcher) load(root, relDir string) {
+	base := ""
+	if relDir != "" {
+		base = relDir + "/"
+	}
+	for _, name := range IgnoreFiles {
+		m.loadFile(filepath.Join(root, filepath.FromSlash(base), name), base)
+	}
+}
+
+// loadFile adds the rules of one ignore file, if it exists, relative to base.
+func (m *ignoreMatcher) loadFile(file, base string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	m.rules = append(m.rules, parseIgnore(data, base)...)
+}
+
+// ignored reports whether the repo-relative path is ignored.
+func (m *ignoreMatcher) ignored(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.negate != ignored || (rule.dirOnly && !isDir) {
+			continue // Can't change the outcome
+		}
+		if !strings.HasPrefix(relPath, rule.base) {
+			continue
+		}
+		target := relPath[len(rule.base):]
+		if !rule.anchored {
+			target = path.Base(target)
+		}
+		if matched, _ := doublestar.Match(rule.pattern, target); matched {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
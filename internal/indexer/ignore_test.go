@@ -0,0 +1,117 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	m := &ignoreMatcher{rules: parseIgnore([]byte(`
+# Comment
+*.gen.go
+/generated
+fixtures/
+docs/*.py
+!keep.gen.go
+secret\ 
+\#literal.py
+`), "")}
+	m.rules = append(m.rules, parseIgnore([]byte("local.py\n/anchored.py\n"), "pkg/")...)
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"api.gen.go", false, true},
+		{"deep/nested/api.gen.go", false, true},
+		{"keep.gen.go", false, false},
+		{"generated", true, true},
+		{"sub/generated", true, false}, // Anchored to the root
+		{"fixtures", true, true},
+		{"sub/fixtures", true, true},
+		{"fixtures", false, false}, // Directory-only rule
+		{"docs/conf.py", false, true},
+		{"docs/api/conf.py", false, false},
+		{"secret ", false, true},
+		{"#literal.py", false, true},
+		{"pkg/local.py", false, true},
+		{"pkg/sub/local.py", false, true},
+		{"local.py", false, false}, // Rule lives in pkg/
+		{"pkg/anchored.py", false, true},
+		{"pkg/sub/anchored.py", false, false},
+		{"app.py", false, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, m.ignored(tt.path, tt.isDir), "%q (dir=%v)", tt.path, tt.isDir)
+	}
+}
+
+func TestWalkerHonorsIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	write(".gitignore", "vendor/\n*_pb2.py\n")
+	write(".indexignore", "testdata/\n!vendor/\n")
+	write(".git/info/exclude", "scratch.py\n")
+	write("app.py", "")
+	write("api_pb2.py", "")
+	write("scratch.py", "")
+	write("vendor/lib.py", "") // Ignored by git, re-included by .indexignore
+	write("testdata/big.py", "")
+	write("pkg/.gitignore", "*.py\n!keep.py\n")
+	write("pkg/keep.py", "")
+	write("pkg/drop.py", "")
+	write("other/drop.py", "") // pkg/.gitignore doesn't reach here
+
+	var files []string
+	err := NewWalker([]string{"**/*.py"}, nil).Walk(root, func(path string) error {
+		rel, _ := filepath.Rel(root, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(files)
+
+	assert.Equal(t, []string{"app.py", "other/drop.py", "pkg/keep.py", "vendor/lib.py"}, files)
+}
+
+func TestWalkerAccepts(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	write(".gitignore", "vendor/\n")
+	write("pkg/.gitignore", "gen_*.py\n")
+	write("app.py", "print(1)\n")
+	write("pkg/gen_api.py", "")
+	write("vendor/lib.py", "")
+	write("node_modules/x/index.js", "")
+	write("notes.txt", "")
+	write("blob.py", "\x00\x01")
+
+	w := NewWalker([]string{"**/*.py", "**/*.js"}, nil)
+	assert.True(t, w.Accepts(root, "app.py"))
+	assert.True(t, w.Accepts(root, "pkg/deleted.py"), "missing files are judged by path")
+	assert.False(t, w.Accepts(root, "pkg/gen_api.py"), "nested ignore files apply")
+	assert.False(t, w.Accepts(root, "vendor/lib.py"))
+	assert.False(t, w.Accepts(root, "node_modules/x/index.js"), "default excludes apply")
+	assert.False(t, w.Accepts(root, "notes.txt"))
+	assert.False(t, w.Accepts(root, "blob.py"), "content checks apply")
+
+	assert.True(t, w.ExcludesDir("node_modules"))
+	assert.True(t, w.ExcludesDir(".git"))
+	assert.False(t, w.ExcludesDir("pkg"))
+}
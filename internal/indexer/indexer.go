@@ -6,8 +6,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,9 +18,11 @@ import (
 	"github.com/randalmurphal/code-indexer/internal/docs"
 	"github.com/randalmurphal/code-indexer/internal/embedding"
 	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/metrics"
 	"github.com/randalmurphal/code-indexer/internal/parser"
 	"github.com/randalmurphal/code-indexer/internal/pattern"
 	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/randalmurphal/code-indexer/internal/summarize"
 )
 
 // Indexer coordinates the indexing pipeline: file discovery, parsing,
@@ -31,6 +35,12 @@ type Indexer struct {
 	patternDetector *pattern.Detector
 	moduleResolver  *ModuleResolver // Initialized per-repo during Index
 	logger          *slog.Logger
+
+	summarizer         Summarizer // Optional; see SetSummarizer
+	summaryConcurrency int
+	enrichers          []Enricher      // See AddEnricher
+	cache              QueryCache      // Optional; see SetCache
+	usage              *metrics.Logger // Optional; see SetMetrics
 }
 
 // NewIndexer creates a new indexer with the given configuration.
@@ -42,37 +52,130 @@ func NewIndexer(cfg *config.Config, voyageKey string) (*Indexer, error) {
 		return nil, fmt.Errorf("failed to connect to Qdrant: %w", err)
 	}
 
-	patternDetector := pattern.NewDetector(pattern.DetectorConfig{
-		MinClusterSize:      5,
-		SimilarityThreshold: 0.8,
-	})
-
-	// Create extractor with hierarchical chunking enabled
-	extractor := chunk.NewExtractor()
-	extractor.SetHierarchicalChunking(true)
+	return NewIndexerWithClients(cfg, embedder, qdrantStore), nil
+}
 
-	return &Indexer{
+// NewIndexerWithClients creates an indexer that shares existing embedding and
+// storage clients, e.g. the MCP server's, instead of opening new connections.
+func NewIndexerWithClients(cfg *config.Config, embedder *embedding.VoyageClient, qdrantStore *store.QdrantStore) *Indexer {
+	idx := &Indexer{
 		config:          cfg,
-		extractor:       extractor,
+		extractor:       newExtractor(cfg),
 		embedder:        embedder,
 		store:           qdrantStore,
-		patternDetector: patternDetector,
+		patternDetector: newPatternDetector(),
 		logger:          slog.Default(),
-	}, nil
+	}
+	if cfg != nil && cfg.Summaries.Enabled {
+		summarizer, err := summarize.NewClient(cfg.Summaries)
+		if err != nil {
+			idx.logger.Warn("symbol summaries disabled", "error", err)
+		} else {
+			idx.SetSummarizer(summarizer, cfg.Summaries.MaxConcurrent)
+		}
+	}
+	return idx
+}
+
+// Fork returns an indexer sharing idx's clients, summarizer, enrichers,
+// cache, and metrics log but with its own per-run state (module resolver,
+// extractor settings), so the two can index different repos at once. A single
+// Indexer runs one index at a time. RunEnrichers are shared too, so ones
+// keeping per-repo state must not be used with concurrent forks.
+func (idx *Indexer) Fork() *Indexer {
+	return &Indexer{
+		config:             idx.config,
+		extractor:          newExtractor(idx.config),
+		embedder:           idx.embedder,
+		store:              idx.store,
+		patternDetector:    newPatternDetector(),
+		logger:             idx.logger,
+		summarizer:         idx.summarizer,
+		summaryConcurrency: idx.summaryConcurrency,
+		enrichers:          slices.Clone(idx.enrichers),
+		cache:              idx.cache,
+		usage:              idx.usage,
+	}
+}
+
+// newExtractor creates an extractor with hierarchical chunking enabled.
+func newExtractor(cfg *config.Config) *chunk.Extractor {
+	extractor := chunk.NewExtractor()
+	extractor.SetHierarchicalChunking(true)
+	extractor.SetFileSummaries(true)
+	weights := chunkWeights(cfg)
+	extractor.SetWeights(float32(weights.Test), float32(weights.FileSummary))
+	return extractor
+}
+
+func newPatternDetector() *pattern.Detector {
+	return pattern.NewDetector(pattern.DetectorConfig{
+		MinClusterSize:      5,
+		SimilarityThreshold: 0.8,
+	})
 }
 
 // IndexResult contains statistics from an indexing run.
 type IndexResult struct {
-	FilesProcessed int
-	FilesSkipped   int // For incremental: files unchanged
-	ChunksCreated  int
-	Errors         []error
+	FilesProcessed   int
+	FilesSkipped     int       // For incremental: files unchanged
+	FilesDeleted     int       // For incremental: files gone since the last run, removed from the index
+	FilesResumed     int       // Included in FilesProcessed: stored by the interrupted run, not re-embedded
+	Filtered         WalkStats // Included files not indexed: too large, binary, minified, or symlinks
+	Renamed          []Rename  // For incremental: files moved without changes
+	ChunksCreated    int
+	ChunksDeduped    int    // Identical to a chunk stored this run: recorded on it, not embedded
+	ChunksSummarized int    // Given an LLM summary before embedding
+	EmbeddingTokens  int    // Billed by the embedding API for this run
+	Commit           string // HEAD at index time; empty outside git
+	Branch           string // Branch checked out at index time; empty when detached or outside git
+
+	SecretFilesSkipped  []string // Left out whole for containing secrets (secrets policy skip_file); earlier chunks removed
+	SecretChunksSkipped int      // Left out for containing secrets (secrets policy skip_chunk)
+
+	// FileHashes are the content hashes of the repo's stored files after a
+	// whole-repo run without a GraphStore, for the next run's
+	// IndexOptions.FileHashes.
+	FileHashes map[string]string
+
+	// Errors holds every error of the run, in order; the phase lists split
+	// them up. None of them stopped the run.
+	Errors      []error
+	ParseErrors []error
+	EmbedErrors []error
+	StoreErrors []error
+	PruneErrors []error
+	FailedFiles []string // Failed in any phase; left for the next run to retry
 }
 
 // IndexOptions configures the indexing behavior.
 type IndexOptions struct {
 	Incremental bool              // Only index changed files
 	GraphStore  *graph.Neo4jStore // For incremental: store/retrieve file hashes
+	FileHashes  map[string]string // For incremental without a GraphStore: the previous run's IndexResult.FileHashes
+	Progress    ProgressFunc      // Optional per-stage progress callback
+	Workers     int               // Files read and parsed concurrently (default: one per CPU)
+
+	// Module restricts the run to one module subtree by dotted path
+	// ("fisio.imports"): only its files are walked, deleted, and updated in
+	// the graph. Repo-wide steps (patterns, navigation docs, the indexed
+	// commit) are left to full runs.
+	Module string
+
+	// CheckpointPath logs each file once its chunks are stored. The log is
+	// removed when the run completes and kept if it fails; empty disables.
+	CheckpointPath string
+	// Resume skips embedding files the checkpoint lists with an unchanged
+	// hash. They are still parsed so patterns and the graph see them.
+	Resume bool
+
+	// HistoryPath appends a RunRecord for the run, failed or not, to this
+	// JSONL log; empty disables. Trigger is recorded with it.
+	HistoryPath string
+	Trigger     string
+
+	// SkipHooks ignores the repo's pre_index and post_index hooks.
+	SkipHooks bool
 }
 
 // Index processes a repository, extracting code chunks, generating embeddings,
@@ -83,10 +186,46 @@ func (idx *Indexer) Index(ctx context.Context, repoPath string, repoCfg *config.
 
 // IndexWithOptions processes a repository with configurable options.
 func (idx *Indexer) IndexWithOptions(ctx context.Context, repoPath string, repoCfg *config.RepoConfig, opts IndexOptions) (*IndexResult, error) {
+	started := time.Now()
+	hooks := repoCfg.Hooks
+	if opts.SkipHooks {
+		hooks = config.HooksConfig{}
+	}
+
+	// Pre-index hooks see the options and start time, with zero counts
+	err := idx.runHooks(ctx, HookPreIndex, repoPath, hooks.PreIndex, hooks.TimeoutSeconds,
+		newRunRecord(repoPath, repoCfg.Name, opts, started, nil, nil))
+	var result *IndexResult
+	if err == nil {
+		result, err = idx.index(ctx, repoPath, repoCfg, opts)
+		idx.invalidateCache(ctx, repoCfg.Name, result)
+	}
+
+	rec := newRunRecord(repoPath, repoCfg.Name, opts, started, result, err)
+	idx.logSpend(repoCfg.Name, opts.Trigger, started, result)
+	if opts.HistoryPath != "" {
+		if histErr := AppendRun(opts.HistoryPath, rec); histErr != nil {
+			idx.logger.Warn("failed to record index run", "path", opts.HistoryPath, "error", histErr)
+		}
+	}
+	if hookErr := idx.runHooks(ctx, HookPostIndex, repoPath, hooks.PostIndex, hooks.TimeoutSeconds, rec); hookErr != nil {
+		idx.logger.Warn("post-index hook failed", "error", hookErr)
+	}
+	return result, err
+}
+
+func (idx *Indexer) index(ctx context.Context, repoPath string, repoCfg *config.RepoConfig, opts IndexOptions) (*IndexResult, error) {
 	result := &IndexResult{}
 
+	result.Commit = loadGitHead(ctx, repoPath)
+	result.Branch = loadGitBranch(ctx, repoPath)
+	dirty := loadDirtyFiles(ctx, repoPath)
+
 	// Initialize module resolver for this repo
 	idx.moduleResolver = NewModuleResolver(repoPath, repoCfg)
+	if err := idx.configureExtractor(repoCfg); err != nil {
+		return nil, err
+	}
 
 	// Ensure collection exists
 	collectionName := "chunks"
@@ -103,144 +242,291 @@ func (idx *Indexer) IndexWithOptions(ctx context.Context, repoPath string, repoC
 			idx.logger.Warn("failed to get existing hashes, falling back to full index", "error", err)
 			existingHashes = nil
 		}
+	} else if opts.Incremental && opts.FileHashes != nil {
+		existingHashes = maps.Clone(opts.FileHashes)
 	}
-
-	// Walk files and extract chunks, collecting symbols for pattern detection
-	walker := NewWalker(repoCfg.Include, repoCfg.Exclude)
-	var allChunks []chunk.Chunk
-	var allSymbols []parser.Symbol
-	var allRelationships []parser.Relationship
-
-	// Track files to update in graph store
-	var filesToUpdate []graph.File
-
-	err := walker.Walk(repoPath, func(path string) error {
-		source, err := os.ReadFile(path)
-		if err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("read %s: %w", path, err))
-			return nil // Continue with other files
-		}
-
-		relPath, _ := filepath.Rel(repoPath, path)
-
-		// Check if file has changed (incremental mode)
-		currentHash := computeFileHash(source)
-		if opts.Incremental && existingHashes != nil {
-			if oldHash, exists := existingHashes[relPath]; exists && oldHash == currentHash {
-				// File unchanged, skip indexing
-				idx.logger.Debug("skipping unchanged file", "path", relPath)
-				result.FilesSkipped++
-				return nil
+	if existingHashes != nil && opts.Module != "" {
+		// Files outside the module are neither walked nor deleted
+		for path := range existingHashes {
+			if !inModule(path, opts.Module, idx.moduleResolver.roots) {
+				delete(existingHashes, path)
 			}
 		}
+	}
 
-		idx.logger.Info("processing file", "path", relPath)
-
-		modulePath, moduleRoot, _ := idx.moduleResolver.Resolve(relPath)
-
-		extractResult, err := idx.extractor.ExtractWithRelationships(source, relPath, repoCfg.Name, modulePath)
+	// Copies of chunks whose canonical file changed are parsed again, or
+	// they would vanish with the canonical chunk's duplicates payload
+	var requeued map[string]bool
+	if existingHashes != nil && repoCfg.DedupChunks {
+		copies, err := idx.storedDuplicates(ctx, collectionName, repoCfg.Name)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("extract %s: %w", path, err))
-			return nil
+			idx.logger.Warn("failed to read duplicate locations, falling back to full index", "error", err)
+			existingHashes = nil
+		} else {
+			requeued = requeueDuplicates(repoPath, existingHashes, copies)
 		}
+	}
 
-		// Collect symbols for pattern detection
-		symbols := idx.extractSymbols(source, relPath)
-		allSymbols = append(allSymbols, symbols...)
+	// Chunks stream to embedding and storage as files are parsed; only the
+	// symbols, relationships, and file nodes needed once parsing ends are kept
+	var allSymbols []parser.Symbol
+	var allRelationships []parser.Relationship
 
-		allChunks = append(allChunks, extractResult.Chunks...)
-		allRelationships = append(allRelationships, extractResult.Relationships...)
-		result.FilesProcessed++
+	// Track files to update in graph store
+	var filesToUpdate []graph.File
 
-		// Track file for graph update
-		if opts.GraphStore != nil {
-			filesToUpdate = append(filesToUpdate, graph.File{
-				Path:        relPath,
-				Repo:        repoCfg.Name,
-				ModuleRoot:  moduleRoot,
-				Hash:        currentHash,
-				LastIndexed: time.Now(),
-			})
-		}
+	// Last-commit ownership per file; empty when the repo is not a git checkout
+	gitHistory := loadGitHistory(ctx, repoPath)
+	owners := loadCodeOwners(repoPath)
 
+	// Collect paths up front so parse progress has a total
+	walker := NewRepoWalker(repoCfg)
+	walker.SetModule(opts.Module)
+	var paths []string
+	err := walker.Walk(repoPath, func(path string) error {
+		paths = append(paths, path)
 		return nil
 	})
-
 	if err != nil {
 		return result, fmt.Errorf("walk failed: %w", err)
 	}
-
-	if len(allChunks) == 0 {
-		return result, nil
+	if opts.Module != "" && len(paths) == 0 {
+		return result, fmt.Errorf("module %q matched no files", opts.Module)
+	}
+	result.Filtered = walker.Stats()
+	if result.Filtered.Total() > 0 {
+		idx.logger.Info("skipped files", "too_large", result.Filtered.TooLarge,
+			"binary", result.Filtered.Binary, "minified", result.Filtered.Minified, "symlinks", result.Filtered.Symlinks)
 	}
 
-	// Detect patterns and mark chunks
-	idx.logger.Info("detecting patterns", "symbols", len(allSymbols))
-	patterns := idx.patternDetector.Detect(allSymbols)
-	idx.logger.Info("patterns detected", "count", len(patterns))
+	job := parseJob{
+		repoPath:       repoPath,
+		repoCfg:        repoCfg,
+		existingHashes: existingHashes,
+		requeued:       requeued,
+		gitHistory:     gitHistory,
+		codeOwners:     owners,
+		licenses:       newLicenseResolver(repoPath, repoCfg.License),
+	}
 
-	// Build file->pattern mapping
-	filePatterns := make(map[string]string)
-	for _, p := range patterns {
-		for _, member := range p.Members {
-			filePatterns[member] = p.Name
+	// Files an interrupted run already stored, and the log for this one
+	resumed := map[string]string{}
+	var checkpoint *checkpointLog
+	var onFile func(path, hash string)
+	if opts.CheckpointPath != "" {
+		if opts.Resume {
+			if resumed, err = LoadCheckpoint(opts.CheckpointPath); err != nil {
+				return result, err
+			}
+			idx.logger.Info("resuming from checkpoint", "files", len(resumed))
 		}
+		if checkpoint, err = openCheckpointLog(opts.CheckpointPath, opts.Resume); err != nil {
+			return result, err
+		}
+		onFile = checkpoint.record
 	}
-
-	// Mark chunks with their pattern
-	for i := range allChunks {
-		if patternName, ok := filePatterns[allChunks[i].FilePath]; ok {
-			allChunks[i].FollowsPattern = patternName
+	completed := false
+	defer func() {
+		if checkpoint == nil {
+			return
+		}
+		if err := checkpoint.close(completed); err != nil {
+			idx.logger.Warn("failed to write checkpoint", "path", opts.CheckpointPath, "error", err)
 		}
+	}()
+
+	// Only the embed goroutine counts; every return below closes the
+	// pipeline, which waits for it, before this runs
+	tokens := 0
+	defer func() { result.EmbeddingTokens = tokens }()
+	embed := func(ctx context.Context, texts []string) ([][]float32, error) {
+		vectors, used, err := idx.embedder.EmbedWithUsage(ctx, texts)
+		tokens += used
+		return vectors, err
 	}
 
-	// Create pattern chunks
-	patternChunks := idx.createPatternChunks(patterns, repoCfg.Name)
-	allChunks = append(allChunks, patternChunks...)
-
-	// Index AGENTS.md and CLAUDE.md files for navigation
-	docChunks := idx.indexNavigationDocs(repoPath, repoCfg.Name)
-	idx.logger.Info("navigation docs indexed", "chunks", len(docChunks))
-	allChunks = append(allChunks, docChunks...)
+	var budget *errorBudget
+	var deduper *chunkDeduper
+	if repoCfg.DedupChunks {
+		deduper = newChunkDeduper()
+	}
 
-	// Generate embeddings
-	idx.logger.Info("generating embeddings", "chunks", len(allChunks))
+	// Like tokens, counted by the embed goroutine only
+	summarized := 0
+	defer func() { result.ChunksSummarized = summarized }()
 
-	texts := make([]string, len(allChunks))
-	for i, c := range allChunks {
-		texts[i] = buildEmbeddingText(c)
+	opts.Progress = opts.Progress.serialized()
+	pipeline := newChunkPipeline(ctx, embed,
+		func(ctx context.Context, chunks []chunk.Chunk) error {
+			for i := range chunks {
+				chunks[i].Branch = result.Branch
+				chunks[i].Workspace = workspaceOf(dirty, chunks[i].FilePath)
+			}
+			return idx.store.UpsertChunks(ctx, collectionName, chunks)
+		},
+		opts.report, onFile)
+	pipeline.retries, budget = idx.failureLimits(len(paths))
+	pipeline.budget = budget
+	if enrichers := idx.startEnrichers(ctx, repoPath, repoCfg); len(enrichers) > 0 {
+		pipeline.enrich = func(ctx context.Context, batch []chunk.Chunk) {
+			idx.enrichBatch(ctx, enrichers, batch)
+		}
 	}
+	if idx.summarizer != nil {
+		pipeline.summarize = func(ctx context.Context, batch []chunk.Chunk) {
+			summarized += idx.summarizeBatch(ctx, batch)
+		}
+	}
+
+	done := 0
+	opts.report(StageParse, 0, len(paths))
+	err = idx.parseFiles(pipeline.ctx, job, paths, opts.Workers, func(parsed parsedFile) {
+		switch {
+		case parsed.err != nil:
+			result.addError(PhaseParse, parsed.err) // Continue with other files
+			relPath, _ := filepath.Rel(repoPath, paths[parsed.index])
+			if err := budget.spend(relPath); err != nil {
+				pipeline.cancel(fmt.Errorf("%w; last failure: %w", err, parsed.err))
+			}
+		case parsed.skipped:
+			result.FilesSkipped++
+		case parsed.secretsSkipped:
+			idx.logger.Info("skipping file with secrets", "path", parsed.file.Path)
+			result.SecretFilesSkipped = append(result.SecretFilesSkipped, parsed.file.Path)
+		default:
+			result.SecretChunksSkipped += parsed.secretChunksSkipped
+			if hash, ok := resumed[parsed.file.Path]; ok && hash == parsed.file.Hash {
+				result.FilesResumed++
+			} else {
+				chunks := parsed.chunks
+				if deduper != nil {
+					chunks = deduper.filter(chunks)
+				}
+				pipeline.add(chunks...)
+				pipeline.fileDone(parsed.file.Path, parsed.file.Hash)
+			}
+			allSymbols = append(allSymbols, parsed.symbols...)
+			allRelationships = append(allRelationships, parsed.relationships...)
+			result.FilesProcessed++
 
-	vectors, err := idx.embedder.EmbedBatched(ctx, texts, 64)
+			// Track file for graph update
+			if opts.GraphStore != nil {
+				filesToUpdate = append(filesToUpdate, parsed.file)
+			}
+		}
+		done++
+		opts.report(StageParse, done, len(paths))
+	})
 	if err != nil {
-		return result, fmt.Errorf("embedding failed: %w", err)
+		pipeline.close()
+		idx.recordFailures(result, pipeline)
+		if cause := pipeline.err(); cause != nil {
+			return result, cause // Embedding or upsert failure stopped parsing
+		}
+		return result, err
 	}
 
-	for i := range allChunks {
-		allChunks[i].Vector = vectors[i]
+	// Deletions and renames, known once every new file's hash is. They are
+	// applied only after the new paths are stored, so a failed run leaves the
+	// old ones in place for the next run to retry.
+	var deleted []string
+	var renames []Rename
+	if existingHashes != nil {
+		walked := make(map[string]bool, len(paths))
+		for _, path := range paths {
+			relPath, _ := filepath.Rel(repoPath, path)
+			walked[relPath] = true
+		}
+		// A failed file is no rename target: moving the old path's graph node
+		// would record a hash for chunks that were never stored
+		added := make(map[string]string)
+		for _, f := range filesToUpdate {
+			if _, exists := existingHashes[f.Path]; !exists && !budget.has(f.Path) {
+				added[f.Path] = f.Hash
+			}
+		}
+		deleted, renames = diffStoredFiles(existingHashes, walked, added)
 	}
 
-	// Store in Qdrant with batched upserts
-	idx.logger.Info("storing chunks", "count", len(allChunks))
+	if pipeline.queued == 0 && result.FilesResumed == 0 {
+		_, err := pipeline.close()
+		idx.recordFailures(result, pipeline)
+		if err != nil {
+			return result, err // Parse errors exhausted the budget
+		}
+		idx.pruneFiles(ctx, collectionName, opts.GraphStore, repoCfg.Name, deleted, renames, result)
+		idx.dropSecretFiles(ctx, collectionName, opts.GraphStore, repoCfg.Name, result)
+		if opts.Module == "" {
+			idx.settleBranch(ctx, collectionName, repoCfg.Name, existingHashes != nil, result)
+			idx.settleWorkspace(ctx, collectionName, repoCfg.Name, dirty, result)
+			idx.recordIndexState(ctx, opts.GraphStore, repoPath, repoCfg.Name, result)
+			if opts.GraphStore == nil {
+				result.FileHashes = storedHashes(existingHashes, filesToUpdate, budget.has, deleted, renames, result)
+			}
+		}
+		completed = true
+		return result, nil
+	}
 
-	batchSize := 100
-	for i := 0; i < len(allChunks); i += batchSize {
-		end := i + batchSize
-		if end > len(allChunks) {
-			end = len(allChunks)
+	// Patterns and navigation docs span the repo; a module's symbols alone
+	// would replace them with partial ones
+	var patterns []pattern.Pattern
+	var siteChunks []chunk.Chunk
+	if opts.Module == "" {
+		// Detect patterns; their chunks go through the pipeline like any other
+		idx.logger.Info("detecting patterns", "symbols", len(allSymbols))
+		patterns = idx.patternDetector.Detect(allSymbols)
+		idx.logger.Info("patterns detected", "count", len(patterns))
+		pipeline.add(idx.createPatternChunks(patterns, repoCfg.Name)...)
+
+		// Index AGENTS.md and CLAUDE.md files for navigation
+		docChunks := idx.indexNavigationDocs(repoPath, repoCfg.Name)
+		idx.logger.Info("navigation docs indexed", "chunks", len(docChunks))
+		pipeline.add(docChunks...)
+
+		// Architecture decision records, for "why" questions
+		adrChunks := idx.indexADRs(repoPath, repoCfg.Name)
+		if len(adrChunks) > 0 {
+			idx.logger.Info("ADRs indexed", "chunks", len(adrChunks))
+			pipeline.add(adrChunks...)
 		}
 
-		if err := idx.store.UpsertChunks(ctx, collectionName, allChunks[i:end]); err != nil {
-			return result, fmt.Errorf("upsert failed: %w", err)
+		// Sphinx and MkDocs pages, with the code they reference
+		siteChunks = idx.indexDocSite(repoPath, repoCfg.Name)
+		if len(siteChunks) > 0 {
+			idx.logger.Info("doc site indexed", "chunks", len(siteChunks))
+			pipeline.add(siteChunks...)
 		}
 	}
 
-	result.ChunksCreated = len(allChunks)
+	result.ChunksCreated, err = pipeline.close()
+	idx.recordFailures(result, pipeline)
+	if err != nil {
+		return result, err
+	}
+	idx.logger.Info("chunks stored", "count", result.ChunksCreated)
+	if deduper != nil {
+		result.ChunksDeduped = deduper.dropped
+		if err := idx.recordDuplicates(ctx, collectionName, deduper); err != nil {
+			result.addError(PhaseStore, err)
+		}
+	}
+	idx.pruneFiles(ctx, collectionName, opts.GraphStore, repoCfg.Name, deleted, renames, result)
+	idx.dropSecretFiles(ctx, collectionName, opts.GraphStore, repoCfg.Name, result)
+
+	// Member files were stored before detection ran, so tag them in place
+	if err := idx.tagPatternMembers(ctx, collectionName, repoCfg.Name, patterns); err != nil {
+		idx.logger.Warn("failed to tag pattern members", "error", err)
+	}
 
-	// Update graph store with file hashes (for incremental indexing)
+	// Update graph store with file hashes (for incremental indexing). Files
+	// whose chunks failed keep their old hash so the next run retries them.
 	if opts.GraphStore != nil && len(filesToUpdate) > 0 {
 		idx.logger.Info("updating file hashes in graph", "files", len(filesToUpdate))
 		for _, file := range filesToUpdate {
+			if budget.has(file.Path) {
+				continue
+			}
 			if err := opts.GraphStore.UpsertFile(ctx, file); err != nil {
 				idx.logger.Warn("failed to update file hash", "path", file.Path, "error", err)
 			}
@@ -274,9 +560,124 @@ func (idx *Indexer) IndexWithOptions(ctx context.Context, repoPath string, repoC
 		idx.storeRelationships(ctx, opts.GraphStore, repoCfg.Name, allRelationships, allSymbols, moduleToFile)
 	}
 
+	// DESCRIBES edges need the symbols and files above
+	if opts.GraphStore != nil && opts.Module == "" {
+		idx.storeDocLinks(ctx, opts.GraphStore, repoCfg.Name, siteChunks)
+	}
+
+	// The rest of the repo was not indexed at this commit, so a module run
+	// leaves the indexed commit and graph versions alone
+	if opts.Module == "" {
+		idx.settleBranch(ctx, collectionName, repoCfg.Name, existingHashes != nil, result)
+		idx.settleWorkspace(ctx, collectionName, repoCfg.Name, dirty, result)
+		idx.recordIndexState(ctx, opts.GraphStore, repoPath, repoCfg.Name, result)
+		if opts.GraphStore == nil {
+			result.FileHashes = storedHashes(existingHashes, filesToUpdate, budget.has, deleted, renames, result)
+		}
+	}
+
+	// Module nodes hang off the Repository node, so store them after it
+	if opts.GraphStore != nil {
+		idx.storeModules(ctx, opts.GraphStore, repoCfg, filesToUpdate)
+	}
+	if opts.GraphStore != nil && opts.Module == "" {
+		keep := 0
+		if idx.config != nil {
+			keep = idx.config.Graph.HistoryVersions
+		}
+		if err := opts.GraphStore.RecordVersion(ctx, repoCfg.Name, result.Commit, time.Now(), keep); err != nil {
+			idx.logger.Warn("failed to record graph version", "repo", repoCfg.Name, "error", err)
+		}
+	}
+
+	completed = true
 	return result, nil
 }
 
+// failureLimits returns the retries per pipeline batch and the error budget
+// for a run over total files, from the global config or its defaults.
+func (idx *Indexer) failureLimits(total int) (int, *errorBudget) {
+	limits := config.DefaultConfig().Indexing
+	if idx.config != nil {
+		limits = idx.config.Indexing
+	}
+	return max(0, limits.BatchRetries), newErrorBudget(limits.ErrorBudgetPercent, total)
+}
+
+// recordFailures adds the batches the closed pipeline gave up on to result.
+func (idx *Indexer) recordFailures(result *IndexResult, p *chunkPipeline) {
+	for _, f := range p.failures {
+		idx.logger.Warn("batch failed", "phase", f.phase, "files", len(f.files), "error", f.err)
+		result.addError(f.phase, f.err)
+	}
+	result.FailedFiles = p.budget.files()
+}
+
+// tagPatternMembers sets follows_pattern on the chunks of every file that
+// belongs to a detected pattern.
+func (idx *Indexer) tagPatternMembers(ctx context.Context, collection, repo string, patterns []pattern.Pattern) error {
+	for _, p := range patterns {
+		for _, member := range p.Members {
+			filter := map[string]interface{}{"repo": repo, "file_path": member}
+			payload := map[string]interface{}{"follows_pattern": p.Name}
+			if err := idx.store.SetPayloadByFilter(ctx, collection, filter, payload); err != nil {
+				return fmt.Errorf("tag %s: %w", member, err)
+			}
+		}
+	}
+	return nil
+}
+
+// storeModules upserts a Module node for every module root seen in files,
+// taking descriptions from the repo config.
+func (idx *Indexer) storeModules(ctx context.Context, graphStore *graph.Neo4jStore, repoCfg *config.RepoConfig, files []graph.File) {
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if f.ModuleRoot == "" || seen[f.ModuleRoot] {
+			continue
+		}
+		seen[f.ModuleRoot] = true
+
+		module := graph.Module{
+			Repo:        repoCfg.Name,
+			Path:        f.ModuleRoot,
+			FSPath:      moduleFSPath(f.Path, idx.moduleResolver.roots),
+			Description: repoCfg.Modules[f.ModuleRoot].Description,
+		}
+		if err := graphStore.UpsertModule(ctx, module); err != nil {
+			idx.logger.Warn("failed to store module", "module", module.Path, "error", err)
+		}
+	}
+}
+
+// recordIndexState stores the indexed commit and branch on the Repository
+// node so search responses can report which index generation produced them.
+func (idx *Indexer) recordIndexState(ctx context.Context, graphStore *graph.Neo4jStore, repoPath, repo string, result *IndexResult) {
+	if graphStore == nil {
+		return
+	}
+	err := graphStore.UpsertRepository(ctx, graph.Repository{
+		Name:          repo,
+		Path:          repoPath,
+		IndexedCommit: result.Commit,
+		IndexedBranch: result.Branch,
+		IndexedAt:     time.Now(),
+	})
+	if err != nil {
+		idx.logger.Warn("failed to record index state", "repo", repo, "error", err)
+	}
+}
+
+// topLevelDir returns the first directory of a repo-relative path with a
+// trailing slash, or "" for files at the repo root.
+func topLevelDir(relPath string) string {
+	dir, _, found := strings.Cut(filepath.ToSlash(relPath), "/")
+	if !found {
+		return ""
+	}
+	return dir + "/"
+}
+
 // buildEmbeddingText combines chunk content with context for better embeddings.
 func buildEmbeddingText(c chunk.Chunk) string {
 	var parts []string
@@ -287,6 +688,9 @@ func buildEmbeddingText(c chunk.Chunk) string {
 	if c.Docstring != "" {
 		parts = append(parts, c.Docstring)
 	}
+	if c.Summary != "" {
+		parts = append(parts, c.Summary)
+	}
 	parts = append(parts, c.Content)
 
 	return strings.Join(parts, "\n\n")
@@ -352,7 +756,7 @@ func (idx *Indexer) createPatternChunks(patterns []pattern.Pattern, repo string)
 			Kind:            "pattern",
 			SymbolName:      p.Name,
 			Content:         content,
-			RetrievalWeight: 1.5, // Boost pattern chunks
+			RetrievalWeight: float32(chunkWeights(idx.config).Pattern), // Boost pattern chunks
 		}
 
 		chunks = append(chunks, c)
@@ -365,67 +769,41 @@ func (idx *Indexer) createPatternChunks(patterns []pattern.Pattern, repo string)
 func (idx *Indexer) indexNavigationDocs(repoPath, repo string) []chunk.Chunk {
 	var allChunks []chunk.Chunk
 
-	// Files to look for
-	navFiles := []string{"AGENTS.md", "CLAUDE.md"}
-
-	// Walk directory tree looking for navigation docs
-	err := filepath.WalkDir(repoPath, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil // Skip errors
-		}
-
-		// Skip hidden and common excluded directories
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".") || name == "node_modules" || name == "venv" || name == "__pycache__" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Check if this is a navigation doc
-		fileName := d.Name()
-		isNavDoc := false
-		for _, navFile := range navFiles {
-			if fileName == navFile {
-				isNavDoc = true
-				break
-			}
-		}
-
-		if !isNavDoc {
-			return nil
-		}
-
-		// Read and parse the file
+	for _, relPath := range findNavigationDocs(repoPath) {
+		path := filepath.Join(repoPath, filepath.FromSlash(relPath))
 		content, err := os.ReadFile(path)
 		if err != nil {
 			idx.logger.Warn("failed to read nav doc", "path", path, "error", err)
-			return nil
+			continue
 		}
 
-		relPath, _ := filepath.Rel(repoPath, path)
 		idx.logger.Info("indexing navigation doc", "path", relPath)
 
 		doc, err := docs.ParseAgentsMD(content, relPath, repo)
 		if err != nil {
 			idx.logger.Warn("failed to parse nav doc", "path", path, "error", err)
-			return nil
+			continue
 		}
 
 		chunks := doc.ToChunks()
+		for i := range chunks {
+			chunks[i].RetrievalWeight = float32(chunkWeights(idx.config).Navigation)
+		}
 		allChunks = append(allChunks, chunks...)
-
-		return nil
-	})
-
-	if err != nil {
-		idx.logger.Warn("error walking for nav docs", "error", err)
 	}
 
 	return allChunks
 }
 
+// chunkWeights returns the configured retrieval weights, or the defaults
+// without a config.
+func chunkWeights(cfg *config.Config) config.WeightsConfig {
+	if cfg == nil {
+		return config.DefaultConfig().Search.Weights
+	}
+	return cfg.Search.Weights
+}
+
 // computeFileHash returns a SHA-256 hash of the file content.
 func computeFileHash(content []byte) string {
 	hash := sha256.Sum256(content)
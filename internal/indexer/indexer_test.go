@@ -3,8 +3,11 @@ package indexer
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -189,3 +192,98 @@ func TestComputeFileHash(t *testing.T) {
 			"hash should be lowercase hex")
 	}
 }
+
+func TestTopLevelDir(t *testing.T) {
+	require.Equal(t, "fisio/", topLevelDir("fisio/fisio/imports/base.py"))
+	require.Equal(t, "src/", topLevelDir("src/main.py"))
+	require.Equal(t, "", topLevelDir("setup.py"))
+}
+
+func TestWalkerContentLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name string, data []byte) {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), data, 0644))
+	}
+
+	write("app.js", []byte("function hello() {\n  return 'hi';\n}\n"))
+	write("bundle.js", []byte(strings.Repeat("var a=1;", 2000))) // One long line
+	write("blob.js", []byte("var x = 1;\x00\x01\x02"))
+	write("huge.js", []byte(strings.Repeat("var a = 1;\n", 10000))) // ~110KB
+
+	walk := func(w *Walker) []string {
+		var files []string
+		require.NoError(t, w.Walk(tmpDir, func(path string) error {
+			files = append(files, filepath.Base(path))
+			return nil
+		}))
+		return files
+	}
+
+	w := NewWalker([]string{"**/*.js"}, nil)
+	w.SetLimits(100, 0)
+	assert.Equal(t, []string{"app.js"}, walk(w))
+	assert.Equal(t, WalkStats{TooLarge: 1, Binary: 1, Minified: 1}, w.Stats())
+
+	// Negative limits disable the size and minified checks; binary always applies
+	w.SetLimits(-1, -1)
+	assert.ElementsMatch(t, []string{"app.js", "bundle.js", "huge.js"}, walk(w))
+	assert.Equal(t, WalkStats{Binary: 1}, w.Stats())
+}
+
+func TestNewRepoWalkerLimits(t *testing.T) {
+	w := NewRepoWalker(&config.RepoConfig{MaxFileKB: 64})
+	assert.Equal(t, int64(64*1024), w.maxFileBytes)
+	assert.Equal(t, DefaultMaxAvgLineLength, w.maxAvgLineLength)
+}
+
+func TestIsMinified(t *testing.T) {
+	assert.False(t, isMinified([]byte("short"), 300))
+	assert.False(t, isMinified([]byte(strings.Repeat("x = 1\n", 500)), 300))
+	assert.True(t, isMinified([]byte(strings.Repeat("x", 1000)), 300))
+}
+
+func TestWalkerSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	shared := filepath.Join(tmpDir, "shared") // Outside the repo
+	writeFiles(t, repo, "lib/util.py", "app/main.py")
+	writeFiles(t, shared, "common.py")
+
+	links := map[string]string{
+		"app/lib":        "../lib",      // Second route to lib/
+		"app/loop":       "..",          // Cycle back to the repo root
+		"vendored":       shared,        // Directory outside the repo
+		"app/alias.py":   "main.py",     // Second route to a file
+		"app/broken.py":  "missing.py",  // Dangling
+		"app/shared_too": shared + "/.", // Second route to the outside directory
+	}
+	for link, target := range links {
+		require.NoError(t, os.Symlink(target, filepath.Join(repo, link)))
+	}
+
+	walk := func(w *Walker) []string {
+		var files []string
+		require.NoError(t, w.Walk(repo, func(path string) error {
+			rel, _ := filepath.Rel(repo, path)
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		}))
+		return files
+	}
+
+	w := NewWalker(nil, nil)
+	assert.ElementsMatch(t, []string{"lib/util.py", "app/main.py"}, walk(w))
+	assert.Equal(t, len(links), w.Stats().Symlinks)
+
+	w.SetFollowSymlinks(true)
+	files := walk(w)
+	// Links follow the real tree, in walk order: app/shared_too claims shared/
+	assert.Equal(t, []string{"app/main.py", "lib/util.py", "app/shared_too/common.py"}, files)
+	// app/lib, app/loop, app/alias.py, app/broken.py, and one route to shared/
+	assert.Equal(t, 5, w.Stats().Symlinks)
+}
+
+func TestNewRepoWalkerFollowSymlinks(t *testing.T) {
+	assert.False(t, NewRepoWalker(&config.RepoConfig{}).followSymlinks)
+	assert.True(t, NewRepoWalker(&config.RepoConfig{FollowSymlinks: true}).followSymlinks)
+}
@@ -0,0 +1,35 @@
+package indexer
+
+import "context"
+
+// QueryCache holds search results that a run makes stale
+// (cache.RedisCache).
+type QueryCache interface {
+	InvalidateRepo(ctx context.Context, repo string) (int64, error)
+}
+
+// SetCache makes runs that change the index invalidate the repo's cached
+// search results. Nil disables invalidation.
+func (idx *Indexer) SetCache(c QueryCache) {
+	idx.cache = c
+}
+
+// invalidateCache bumps repo's index version and drops its cached queries
+// when result changed the index, even if the run later failed. A failure is
+// logged: cached results then live out their TTL.
+func (idx *Indexer) invalidateCache(ctx context.Context, repo string, result *IndexResult) {
+	if idx.cache == nil || !result.ChangedIndex() {
+		return
+	}
+	version, err := idx.cache.InvalidateRepo(ctx, repo)
+	if err != nil {
+		idx.logger.Warn("failed to invalidate query cache", "repo", repo, "error", err)
+		return
+	}
+	idx.logger.Debug("invalidated query cache", "repo", repo, "version", version)
+}
+
+// ChangedIndex reports whether the run stored, removed, or moved any chunks.
+func (r *IndexResult) ChangedIndex() bool {
+	return r != nil && (r.FilesProcessed > 0 || r.FilesDeleted > 0 || len(r.Renamed) > 0)
+}
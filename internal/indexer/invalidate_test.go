@@ -0,0 +1,32 @@
+package indexer
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeQueryCache struct {
+	invalidated []string
+}
+
+func (f *fakeQueryCache) InvalidateRepo(_ context.Context, repo string) (int64, error) {
+	f.invalidated = append(f.invalidated, repo)
+	return int64(len(f.invalidated)), nil
+}
+
+func TestInvalidateCache(t *testing.T) {
+	idx := &Indexer{logger: slog.Default()}
+	c := &fakeQueryCache{}
+	idx.SetCache(c)
+
+	idx.invalidateCache(context.Background(), "r3", &IndexResult{FilesSkipped: 40})
+	idx.invalidateCache(context.Background(), "r3", nil)
+	assert.Empty(t, c.invalidated, "runs that changed nothing keep the cache")
+
+	idx.invalidateCache(context.Background(), "r3", &IndexResult{FilesDeleted: 1})
+	idx.invalidateCache(context.Background(), "r4", &IndexResult{Renamed: []Rename{{From: "a.py", To: "b.py"}}})
+	assert.Equal(t, []string{"r3", "r4"}, c.invalidated)
+}
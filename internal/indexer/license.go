@@ -0,0 +1,178 @@
+package indexer
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// licenseProprietary tags code declared proprietary or confidential.
+	licenseProprietary = "proprietary"
+	// licenseUnknown tags code under a LICENSE file of no recognized license.
+	licenseUnknown = "unknown"
+
+	licenseHeaderLines = 30        // Leading lines searched for a license header
+	licenseFileBytes   = 64 * 1024 // Leading bytes of a LICENSE file identified
+)
+
+// spdxIdentifier matches an SPDX-License-Identifier tag, stopping before
+// comment closers.
+var spdxIdentifier = regexp.MustCompile(`SPDX-License-Identifier:\s*([A-Za-z0-9.+\-:() ]*[A-Za-z0-9.+)])`)
+
+// licenseText is a license recognized by a phrase of its text or headers.
+type licenseText struct {
+	phrase string // Lowercase, single spaces
+	id     func(text string) string
+}
+
+// namedLicense always identifies as id.
+func namedLicense(id string) func(string) string {
+	return func(string) string { return id }
+}
+
+// versioned picks id3 when the text names version 3, else id2.
+func versioned(id2, id3 string) func(string) string {
+	return func(text string) string {
+		if strings.Contains(text, "version 3") {
+			return id3
+		}
+		return id2
+	}
+}
+
+// licenseTexts are tried together; the phrase appearing first in the text
+// wins, since license texts name other licenses further down (the GPL's
+// mentions the LGPL and AGPL).
+var licenseTexts = []licenseText{
+	{"gnu affero general public license", namedLicense("AGPL-3.0")},
+	{"gnu lesser general public license", versioned("LGPL-2.1", "LGPL-3.0")},
+	{"gnu library general public license", namedLicense("LGPL-2.0")},
+	{"gnu general public license", versioned("GPL-2.0", "GPL-3.0")},
+	{"apache license", namedLicense("Apache-2.0")},
+	{"mozilla public license", namedLicense("MPL-2.0")},
+	{"eclipse public license", namedLicense("EPL-2.0")},
+	{"permission is hereby granted, free of charge", namedLicense("MIT")},
+	{"redistribution and use in source and binary forms", func(text string) string {
+		if strings.Contains(text, "neither the name") || strings.Contains(text, "names of its contributors") {
+			return "BSD-3-Clause"
+		}
+		return "BSD-2-Clause"
+	}},
+	{"permission to use, copy, modify, and/or distribute this software", namedLicense("ISC")},
+	{"permission to use, copy, modify, and distribute this software", namedLicense("ISC")},
+	{"this is free and unencumbered software released into the public domain", namedLicense("Unlicense")},
+	{"proprietary and confidential", namedLicense(licenseProprietary)},
+	{"confidential and proprietary", namedLicense(licenseProprietary)},
+}
+
+// identifyLicense returns the license whose phrase comes first in text, or
+// "" when there is none.
+func identifyLicense(text string) string {
+	text = strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	best, bestAt := "", len(text)
+	for _, l := range licenseTexts {
+		if at := strings.Index(text, l.phrase); at >= 0 && at < bestAt {
+			best, bestAt = l.id(text[at:]), at
+		}
+	}
+	return best
+}
+
+// headerLicense reads a file's license from an SPDX-License-Identifier or a
+// recognizable license header in its leading lines.
+func headerLicense(source []byte) string {
+	head := source
+	for i, lines := 0, 0; i < len(head); i++ {
+		if head[i] == '\n' {
+			if lines++; lines == licenseHeaderLines {
+				head = head[:i]
+				break
+			}
+		}
+	}
+	if m := spdxIdentifier.FindSubmatch(head); m != nil {
+		return strings.TrimSpace(string(m[1]))
+	}
+	return identifyLicense(string(head))
+}
+
+// licenseResolver tags files with their license: the file's own header,
+// else the nearest LICENSE or COPYING file up to the repo root, else the
+// repo's configured default. It is safe for concurrent use.
+type licenseResolver struct {
+	repoPath string
+	fallback string
+
+	mu   sync.Mutex
+	dirs map[string]string // Repo-relative dir → its LICENSE file's license; "" without one
+}
+
+func newLicenseResolver(repoPath, fallback string) *licenseResolver {
+	return &licenseResolver{repoPath: repoPath, fallback: fallback, dirs: make(map[string]string)}
+}
+
+// License returns the license of a repo-relative file with content source,
+// or "" when nothing says. A nil resolver only reads the file's header.
+func (r *licenseResolver) License(relPath string, source []byte) string {
+	if license := headerLicense(source); license != "" || r == nil {
+		return license
+	}
+	for dir := path.Dir(filepath.ToSlash(relPath)); ; dir = path.Dir(dir) {
+		if license := r.dirLicense(dir); license != "" {
+			return license
+		}
+		if dir == "." || dir == "/" {
+			return r.fallback
+		}
+	}
+}
+
+// dirLicense identifies the LICENSE file directly in dir, caching the answer.
+func (r *licenseResolver) dirLicense(dir string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if license, ok := r.dirs[dir]; ok {
+		return license
+	}
+	license := readDirLicense(filepath.Join(r.repoPath, filepath.FromSlash(dir)))
+	r.dirs[dir] = license
+	return license
+}
+
+// readDirLicense identifies the first license file in dir: "" without one,
+// licenseUnknown when none of its text is recognized.
+func readDirLicense(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	found := false
+	for _, e := range entries {
+		name := strings.ToLower(e.Name())
+		if e.IsDir() || !(strings.HasPrefix(name, "license") || strings.HasPrefix(name, "licence") || strings.HasPrefix(name, "copying")) {
+			continue
+		}
+		found = true
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		text, err := io.ReadAll(io.LimitReader(f, licenseFileBytes))
+		f.Close()
+		if err != nil {
+			continue
+		}
+		if license := identifyLicense(string(text)); license != "" {
+			return license
+		}
+	}
+	if found {
+		return licenseUnknown
+	}
+	return ""
+}
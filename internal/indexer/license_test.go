@@ -0,0 +1,59 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderLicense(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"spdx in c comment", "/* SPDX-License-Identifier: MIT */\nint main() {}", "MIT"},
+		{"spdx expression", "# SPDX-License-Identifier: Apache-2.0 OR MIT\nimport os", "Apache-2.0 OR MIT"},
+		{"spdx in html comment", "<!-- SPDX-License-Identifier: GPL-2.0-or-later -->", "GPL-2.0-or-later"},
+		{"apache header", "# Licensed under the Apache License, Version 2.0 (the \"License\");\n# you may not use this file", "Apache-2.0"},
+		{"gpl header", "# This program is free software: you can redistribute it under the terms of the\n# GNU General Public License as published by the Free Software Foundation, either version 3", "GPL-3.0"},
+		{"proprietary", "// Copyright 2026 Acme Corp. All rights reserved.\n// Proprietary and confidential.", "proprietary"},
+		{"no header", "def main():\n    pass\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, headerLicense([]byte(tt.source)))
+		})
+	}
+}
+
+func TestIdentifyLicenseFirstPhraseWins(t *testing.T) {
+	// The GPL's text mentions the LGPL near its end
+	gpl := "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n...\nconsider it more useful to permit linking proprietary applications with the library. If this is what you want to do, use the GNU Lesser General Public License instead"
+	assert.Equal(t, "GPL-3.0", identifyLicense(gpl))
+	assert.Equal(t, "BSD-3-Clause", identifyLicense("Redistribution and use in source and binary forms, with or without\nmodification... Neither the name of the copyright holder"))
+	assert.Equal(t, "", identifyLicense("just some readme text"))
+}
+
+func TestLicenseResolver(t *testing.T) {
+	repo := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(repo, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("vendor/six/LICENSE", "Copyright (c) 2010-2024 Benjamin Peterson\n\nPermission is hereby granted, free of charge, to any person obtaining a copy")
+	write("vendor/odd/COPYING.txt", "Do what you like.")
+
+	r := newLicenseResolver(repo, "proprietary")
+	assert.Equal(t, "MIT", r.License("vendor/six/six.py", []byte("import sys\n")))
+	assert.Equal(t, "MIT", r.License("vendor/six/tests/test_six.py", []byte("import six\n")))
+	assert.Equal(t, "Apache-2.0", r.License("vendor/six/extra.py", []byte("# SPDX-License-Identifier: Apache-2.0\n")))
+	assert.Equal(t, "unknown", r.License("vendor/odd/odd.py", []byte("x = 1\n")))
+	assert.Equal(t, "proprietary", r.License("app/main.py", []byte("x = 1\n")))
+	assert.Equal(t, "", newLicenseResolver(repo, "").License("main.py", []byte("x = 1\n")))
+}
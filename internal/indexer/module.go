@@ -2,17 +2,23 @@ package indexer
 
 import (
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/randalmurphal/code-indexer/internal/config"
 )
 
-// ModuleResolver resolves file paths to module paths.
+// ModuleResolver resolves file paths to module paths. It is safe for
+// concurrent use by parse workers.
 type ModuleResolver struct {
 	repoPath string
 	config   *config.RepoConfig
-	cache    map[string]moduleInfo
+	roots    []walkRoot
+
+	mu    sync.Mutex
+	cache map[string]moduleInfo
 }
 
 type moduleInfo struct {
@@ -26,13 +32,17 @@ func NewModuleResolver(repoPath string, cfg *config.RepoConfig) *ModuleResolver
 	return &ModuleResolver{
 		repoPath: repoPath,
 		config:   cfg,
+		roots:    newWalkRoots(cfg),
 		cache:    make(map[string]moduleInfo),
 	}
 }
 
 // Resolve converts a file path to module path components.
 func (r *ModuleResolver) Resolve(filePath string) (modulePath, moduleRoot, submodule string) {
-	if cached, ok := r.cache[filePath]; ok {
+	r.mu.Lock()
+	cached, ok := r.cache[filePath]
+	r.mu.Unlock()
+	if ok {
 		return cached.modulePath, cached.moduleRoot, cached.submodule
 	}
 
@@ -45,15 +55,10 @@ func (r *ModuleResolver) Resolve(filePath string) (modulePath, moduleRoot, submo
 	// Remove file extension
 	relPath = strings.TrimSuffix(relPath, filepath.Ext(relPath))
 
-	// Convert path separators to dots
-	modulePath = strings.ReplaceAll(relPath, string(filepath.Separator), ".")
-
-	// Handle duplicate prefixes (e.g., fisio/fisio -> fisio)
+	// Convert path separators to dots, folding duplicate prefixes (e.g.,
+	// fisio/fisio -> fisio) or applying the file's root's module prefix
+	modulePath = moduleName(filepath.ToSlash(relPath), r.roots)
 	parts := strings.Split(modulePath, ".")
-	if len(parts) >= 2 && parts[0] == parts[1] {
-		parts = parts[1:]
-		modulePath = strings.Join(parts, ".")
-	}
 
 	// Extract root and submodule
 	if len(parts) > 0 {
@@ -64,15 +69,48 @@ func (r *ModuleResolver) Resolve(filePath string) (modulePath, moduleRoot, submo
 	}
 
 	// Cache result
+	r.mu.Lock()
 	r.cache[filePath] = moduleInfo{
 		modulePath: modulePath,
 		moduleRoot: moduleRoot,
 		submodule:  submodule,
 	}
+	r.mu.Unlock()
 
 	return modulePath, moduleRoot, submodule
 }
 
+// dottedPath converts a repo-relative slash path to a dotted module path the
+// way Resolve does, without stripping an extension: "fisio/fisio/imports"
+// becomes "fisio.imports".
+func dottedPath(relPath string) string {
+	parts := strings.Split(relPath, "/")
+	if len(parts) >= 2 && parts[0] == parts[1] {
+		parts = parts[1:]
+	}
+	return strings.Join(parts, ".")
+}
+
+// inModule reports whether the repo-relative file belongs to module: its
+// module path is module itself (a single-file module) or below it.
+func inModule(relPath, module string, roots []walkRoot) bool {
+	modulePath := moduleName(strings.TrimSuffix(relPath, path.Ext(relPath)), roots)
+	return modulePath == module || strings.HasPrefix(modulePath, module+".")
+}
+
+// dirOverlapsModule reports whether the repo-relative directory can contain
+// files of module: it is inside the module or one of its ancestors. Every
+// directory above a root qualifies, since a root may rename its modules.
+func dirOverlapsModule(relDir, module string, roots []walkRoot) bool {
+	if aboveRoot(roots, relDir) {
+		return true
+	}
+	dirPath := moduleName(relDir, roots)
+	return dirPath == module ||
+		strings.HasPrefix(dirPath, module+".") ||
+		strings.HasPrefix(module, dirPath+".")
+}
+
 // DetectModules auto-detects module structure from filesystem.
 func DetectModules(repoPath string) map[string]config.Module {
 	modules := make(map[string]config.Module)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/randalmurphal/code-indexer/internal/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestModuleResolver(t *testing.T) {
@@ -146,3 +147,39 @@ func TestDetectSubmodules(t *testing.T) {
 	assert.Contains(t, submodules, "utils")
 	assert.NotContains(t, submodules, "_private")
 }
+
+func TestInModule(t *testing.T) {
+	assert.True(t, inModule("fisio/fisio/imports/aws.py", "fisio.imports", nil))
+	assert.True(t, inModule("fisio/imports/aws.py", "fisio.imports", nil))
+	assert.True(t, inModule("fisio/imports.py", "fisio.imports", nil))
+	assert.False(t, inModule("fisio/fisio/importers/aws.py", "fisio.imports", nil))
+	assert.False(t, inModule("fisio/fisio/common/utils.py", "fisio.imports", nil))
+
+	assert.True(t, dirOverlapsModule("fisio", "fisio.imports", nil))
+	assert.True(t, dirOverlapsModule("fisio/fisio", "fisio.imports", nil))
+	assert.True(t, dirOverlapsModule("fisio/fisio/imports/aws", "fisio.imports", nil))
+	assert.False(t, dirOverlapsModule("fisio/fisio/common", "fisio.imports", nil))
+	assert.False(t, dirOverlapsModule("other", "fisio.imports", nil))
+}
+
+func TestWalkerModule(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir,
+		"fisio/fisio/__init__.py",
+		"fisio/fisio/imports/aws.py",
+		"fisio/fisio/imports/gcp/client.py",
+		"fisio/fisio/common/utils.py",
+		"tools/run.py",
+	)
+
+	w := NewWalker(nil, nil)
+	w.SetModule("fisio.imports")
+	var files []string
+	err := w.Walk(tmpDir, func(path string) error {
+		rel, _ := filepath.Rel(tmpDir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"fisio/fisio/imports/aws.py", "fisio/fisio/imports/gcp/client.py"}, files)
+}
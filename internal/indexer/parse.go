@@ -0,0 +1,206 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/parser"
+)
+
+// parsedFile is what a parse worker produces for one file.
+type parsedFile struct {
+	index         int // Position in the walk order
+	chunks        []chunk.Chunk
+	symbols       []parser.Symbol
+	relationships []parser.Relationship
+	file          graph.File // Graph node with the content hash
+	skipped       bool       // Unchanged since the last incremental run
+	err           error      // Read or extract failure; the run continues
+
+	secretsSkipped      bool // Left out for containing secrets; file holds only the path
+	secretChunksSkipped int
+}
+
+// parseJob holds the read-only state parse workers share for one run.
+type parseJob struct {
+	repoPath       string
+	repoCfg        *config.RepoConfig
+	existingHashes map[string]string // Incremental runs only
+	requeued       map[string]bool   // Parsed even when unchanged; see requeueDuplicates
+	gitHistory     map[string]GitFileInfo
+	codeOwners     *codeOwners // Nil without a CODEOWNERS file
+	licenses       *licenseResolver
+}
+
+// workerCount resolves IndexOptions.Workers: zero or less means one worker
+// per CPU, and there is never more than one worker per file.
+func workerCount(workers, files int) int {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return max(1, min(workers, files))
+}
+
+// reorderWindow is how many files parseFiles lets workers take ahead of the
+// next one to emit: results waiting for a slow file are held in memory, so
+// a file that takes long to parse must not let the rest of the repo pile up
+// behind it.
+func reorderWindow(workers int) int {
+	return workers * 4
+}
+
+// parseFiles reads, hashes, and extracts paths on a pool of workers. Results
+// pass through a bounded channel and are handed to emit in walk order, on
+// the calling goroutine, so the run stays deterministic regardless of which
+// worker finishes first. Files are handed out at most reorderWindow ahead of
+// the next to emit, which bounds the results held back. It returns
+// ctx.Err() if cancelled before every file was emitted.
+func (idx *Indexer) parseFiles(ctx context.Context, job parseJob, paths []string, workers int, emit func(parsedFile)) error {
+	workers = workerCount(workers, len(paths))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan parsedFile, workers*2)
+	// A slot per file handed out and not yet emitted
+	slots := make(chan struct{}, reorderWindow(workers))
+
+	go func() {
+		defer close(jobs)
+		for i := range paths {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				parsed := idx.parseFile(job, paths[i])
+				parsed.index = i
+				select {
+				case results <- parsed:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Hold early finishers until the files before them arrive
+	pending := make(map[int]parsedFile)
+	next := 0
+	for parsed := range results {
+		if ctx.Err() != nil {
+			break // Workers stop on the same signal
+		}
+		pending[parsed.index] = parsed
+		for {
+			p, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			emit(p)
+			<-slots
+			next++
+		}
+	}
+
+	if next < len(paths) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// parseFile reads and extracts one file. It only reads shared state, so
+// workers can call it concurrently.
+func (idx *Indexer) parseFile(job parseJob, path string) parsedFile {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return parsedFile{err: fmt.Errorf("read %s: %w", path, err)}
+	}
+
+	relPath, _ := filepath.Rel(job.repoPath, path)
+
+	// Check if file has changed (incremental mode)
+	currentHash := computeFileHash(source)
+	if oldHash, exists := job.existingHashes[relPath]; exists && oldHash == currentHash && !job.requeued[relPath] {
+		idx.logger.Debug("skipping unchanged file", "path", relPath)
+		return parsedFile{skipped: true}
+	}
+
+	idx.logger.Debug("processing file", "path", relPath)
+
+	modulePath, moduleRoot, _ := idx.moduleResolver.Resolve(relPath)
+
+	extractResult, err := idx.extractor.ExtractWithRelationships(source, relPath, job.repoCfg.Name, modulePath)
+	if err != nil {
+		return parsedFile{err: fmt.Errorf("extract %s: %w", path, err)}
+	}
+	if extractResult.SecretsSkippedFile {
+		return parsedFile{secretsSkipped: true, file: graph.File{Path: relPath, Repo: job.repoCfg.Name}}
+	}
+
+	gitInfo := job.gitHistory[filepath.ToSlash(relPath)]
+	owners := job.codeOwners.Owners(relPath)
+	license := job.licenses.License(relPath, source)
+	for i := range extractResult.Chunks {
+		extractResult.Chunks[i].License = license
+		extractResult.Chunks[i].LastAuthor = gitInfo.AuthorName
+		extractResult.Chunks[i].LastAuthorEmail = gitInfo.AuthorEmail
+		extractResult.Chunks[i].LastCommit = gitInfo.Commit
+		extractResult.Chunks[i].Owners = owners
+	}
+
+	// Symbols are held until the run ends, and neither pattern detection nor
+	// the graph needs their bodies
+	symbols := idx.extractSymbols(source, relPath)
+	for i := range symbols {
+		symbols[i].Content = ""
+	}
+
+	return parsedFile{
+		secretChunksSkipped: extractResult.SecretChunksSkipped,
+		chunks:              extractResult.Chunks,
+		symbols:             symbols,
+		relationships:       extractResult.Relationships,
+		file: graph.File{
+			Path:        relPath,
+			Repo:        job.repoCfg.Name,
+			ModuleRoot:  moduleRoot,
+			Hash:        currentHash,
+			LastIndexed: time.Now(),
+
+			LastAuthor:      gitInfo.AuthorName,
+			LastAuthorEmail: gitInfo.AuthorEmail,
+			LastCommit:      gitInfo.Commit,
+			LastModified:    gitInfo.Timestamp,
+			Owners:          owners,
+		},
+	}
+}
@@ -0,0 +1,119 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newParseTestIndexer(repoPath string, repoCfg *config.RepoConfig) *Indexer {
+	return &Indexer{
+		extractor:      chunk.NewExtractor(),
+		moduleResolver: NewModuleResolver(repoPath, repoCfg),
+		logger:         slog.Default(),
+	}
+}
+
+func writeParseFixtures(t *testing.T, n int) (string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for i := range n {
+		path := filepath.Join(dir, "pkg", fmt.Sprintf("mod%03d.py", i))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		src := fmt.Sprintf("def func_%03d():\n    return %d\n", i, i)
+		require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+		paths = append(paths, path)
+	}
+	return dir, paths
+}
+
+func TestParseFilesEmitsInWalkOrder(t *testing.T) {
+	dir, paths := writeParseFixtures(t, 40)
+	paths = append(paths, filepath.Join(dir, "missing.py"))
+	repoCfg := &config.RepoConfig{Name: "demo"}
+	job := parseJob{repoPath: dir, repoCfg: repoCfg}
+
+	collect := func(workers int) ([]string, int) {
+		idx := newParseTestIndexer(dir, repoCfg)
+		var files []string
+		errs := 0
+		err := idx.parseFiles(context.Background(), job, paths, workers, func(p parsedFile) {
+			if p.err != nil {
+				errs++
+				return
+			}
+			files = append(files, p.file.Path)
+		})
+		require.NoError(t, err)
+		return files, errs
+	}
+
+	serial, serialErrs := collect(1)
+	parallel, parallelErrs := collect(8)
+
+	require.Len(t, serial, 40)
+	assert.Equal(t, serial, parallel)
+	assert.Equal(t, 1, serialErrs)
+	assert.Equal(t, 1, parallelErrs)
+	assert.Equal(t, filepath.Join("pkg", "mod000.py"), serial[0])
+}
+
+func TestParseFilesSkipsUnchanged(t *testing.T) {
+	dir, paths := writeParseFixtures(t, 3)
+	repoCfg := &config.RepoConfig{Name: "demo"}
+	source, err := os.ReadFile(paths[1])
+	require.NoError(t, err)
+
+	job := parseJob{
+		repoPath:       dir,
+		repoCfg:        repoCfg,
+		existingHashes: map[string]string{filepath.Join("pkg", "mod001.py"): computeFileHash(source)},
+	}
+
+	var skipped, parsed int
+	err = newParseTestIndexer(dir, repoCfg).parseFiles(context.Background(), job, paths, 2, func(p parsedFile) {
+		if p.skipped {
+			skipped++
+			return
+		}
+		parsed++
+		assert.NotEmpty(t, p.chunks)
+		assert.NotEmpty(t, p.file.Hash)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, skipped)
+	assert.Equal(t, 2, parsed)
+}
+
+func TestParseFilesCancelled(t *testing.T) {
+	dir, paths := writeParseFixtures(t, 20)
+	repoCfg := &config.RepoConfig{Name: "demo"}
+	job := parseJob{repoPath: dir, repoCfg: repoCfg}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	emitted := 0
+	err := newParseTestIndexer(dir, repoCfg).parseFiles(ctx, job, paths, 4, func(parsedFile) {
+		emitted++
+		if emitted == 5 {
+			cancel()
+		}
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, emitted, len(paths))
+}
+
+func TestWorkerCount(t *testing.T) {
+	assert.Equal(t, 4, workerCount(4, 100))
+	assert.Equal(t, 3, workerCount(8, 3))
+	assert.Equal(t, 1, workerCount(4, 0))
+	assert.GreaterOrEqual(t, workerCount(0, 1000), 1)
+}
@@ -0,0 +1,72 @@
+//go:build unix
+
+package indexer
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lineCounter counts log lines containing a message.
+type lineCounter struct {
+	mu      sync.Mutex
+	message string
+	count   int
+}
+
+func (c *lineCounter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count += strings.Count(string(p), c.message)
+	return len(p), nil
+}
+
+func (c *lineCounter) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+func TestParseFilesBoundsReorderBuffer(t *testing.T) {
+	dir, paths := writeParseFixtures(t, 40)
+	// Reading a FIFO blocks until it is written, stalling the first file
+	require.NoError(t, os.Remove(paths[0]))
+	require.NoError(t, syscall.Mkfifo(paths[0], 0o644))
+
+	repoCfg := &config.RepoConfig{Name: "demo"}
+	idx := newParseTestIndexer(dir, repoCfg)
+	processed := &lineCounter{message: "processing file"}
+	idx.logger = slog.New(slog.NewTextHandler(processed, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	const workers = 2
+	emitted := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- idx.parseFiles(context.Background(), parseJob{repoPath: dir, repoCfg: repoCfg}, paths, workers, func(parsedFile) { emitted++ })
+	}()
+
+	window := reorderWindow(workers)
+	require.Eventually(t, func() bool { return processed.Count() == window-1 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, window-1, processed.Count(), "workers stop taking files a window past the stalled one")
+
+	fifo, err := os.OpenFile(paths[0], os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = fifo.WriteString("def func_000():\n    return 0\n")
+	require.NoError(t, err)
+	require.NoError(t, fifo.Close())
+
+	require.NoError(t, <-done)
+	assert.Equal(t, 40, emitted)
+	assert.Equal(t, 40, processed.Count())
+}
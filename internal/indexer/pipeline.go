@@ -0,0 +1,303 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+)
+
+const (
+	embedBatchSize  = 64 // Chunks per embedding request and per upsert
+	queueDepth      = 4  // Batches buffered between pipeline stages
+	batchRetryDelay = 2 * time.Second
+)
+
+type embedFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+type storeFunc func(ctx context.Context, chunks []chunk.Chunk) error
+
+// chunkPipeline embeds and stores chunks while parsing is still producing
+// them. The producer hands chunks to add; an embed goroutine and a store
+// goroutine drain bounded queues of whole batches, so at most about
+// (2*queueDepth+3)*embedBatchSize chunks are held at once however large the
+// repo. A full queue blocks add, which throttles parsing to the speed of
+// the embedding API.
+//
+// A batch that still fails after its retries is skipped rather than failing
+// the run: its files are recorded as failed, and never reported to onFile,
+// so the next run picks them up again. The run is cancelled only when the
+// failures exhaust the error budget.
+type chunkPipeline struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	embed  embedFunc
+	store  storeFunc
+	report func(stage Stage, done, total int)
+	onFile func(path, hash string) // Optional; see fileDone
+
+	// Set before the first add
+	retries    int                                            // Extra attempts per batch and stage
+	retryDelay time.Duration                                  // Before the first retry, doubling after
+	budget     *errorBudget                                   // Optional; exhausting it cancels the run
+	enrich     func(ctx context.Context, batch []chunk.Chunk) // Optional; runs enrichers before embedding
+	summarize  func(ctx context.Context, batch []chunk.Chunk) // Optional; fills in summaries before embedding
+
+	pending []chunk.Chunk // Producer side: not yet a full batch
+	queued  int           // Producer side: chunks passed to add
+
+	toEmbed chan []chunk.Chunk
+	toStore chan storeBatch
+	stopped chan struct{} // Closed when the store goroutine exits
+
+	embedded int // Embed goroutine only
+	embedPos int // Embed goroutine only: chunks taken off the queue
+	batches  int // Store goroutine only
+
+	mu          sync.Mutex
+	stored      int             // Chunks stored
+	done        int             // Chunks stored or given up on, always a prefix of those queued
+	marks       []fileMark      // Files waiting for their last chunk to be done
+	failures    []batchFailure  // Batches given up on, in order
+	failedFiles map[string]bool // Files with a chunk in a failed batch
+}
+
+// storeBatch is an embedded batch, or one that failed to embed and only
+// passes through the store stage to keep file completion in order.
+type storeBatch struct {
+	chunks []chunk.Chunk
+	failed bool
+}
+
+// batchFailure is a batch the pipeline gave up on.
+type batchFailure struct {
+	phase Phase
+	err   error
+	files []string
+}
+
+// fileMark is a file whose chunks end at queue position end.
+type fileMark struct {
+	end  int
+	path string
+	hash string
+}
+
+// newChunkPipeline starts the embed and store stages. report receives
+// StageEmbed and StageStore updates with a zero Total until close. onFile,
+// if set, is called from either side of the pipeline as files complete.
+func newChunkPipeline(ctx context.Context, embed embedFunc, store storeFunc, report func(Stage, int, int), onFile func(path, hash string)) *chunkPipeline {
+	ctx, cancel := context.WithCancelCause(ctx)
+	p := &chunkPipeline{
+		ctx:     ctx,
+		cancel:  cancel,
+		embed:   embed,
+		store:   store,
+		report:  report,
+		onFile:  onFile,
+		toEmbed: make(chan []chunk.Chunk, queueDepth),
+		toStore: make(chan storeBatch, queueDepth),
+		stopped: make(chan struct{}),
+
+		retryDelay:  batchRetryDelay,
+		failedFiles: make(map[string]bool),
+	}
+	go p.embedLoop()
+	go p.storeLoop()
+	return p
+}
+
+// add queues chunks, sending full batches downstream. It blocks while the
+// queues are full and drops chunks once the pipeline is cancelled; close
+// reports the cause.
+func (p *chunkPipeline) add(chunks ...chunk.Chunk) {
+	p.pending = append(p.pending, chunks...)
+	p.queued += len(chunks)
+	for len(p.pending) >= embedBatchSize {
+		p.send(slices.Clone(p.pending[:embedBatchSize]))
+		p.pending = append(p.pending[:0], p.pending[embedBatchSize:]...)
+	}
+}
+
+// fileDone marks the end of a file's chunks: once every chunk added so far
+// is done, onFile is called with path and hash unless one of its batches
+// failed. Batches are finished in order, so that is exactly when the file
+// is fully searchable.
+func (p *chunkPipeline) fileDone(path, hash string) {
+	if p.onFile == nil {
+		return
+	}
+	p.mu.Lock()
+	if p.queued > p.done {
+		p.marks = append(p.marks, fileMark{end: p.queued, path: path, hash: hash})
+		p.mu.Unlock()
+		return
+	}
+	failed := p.failedFiles[path]
+	p.mu.Unlock()
+	if !failed {
+		p.onFile(path, hash)
+	}
+}
+
+// err is the cause of a failed or cancelled pipeline.
+func (p *chunkPipeline) err() error {
+	return context.Cause(p.ctx)
+}
+
+func (p *chunkPipeline) send(batch []chunk.Chunk) {
+	select {
+	case p.toEmbed <- batch:
+	case <-p.ctx.Done():
+	}
+}
+
+// close flushes the last partial batch, waits for every batch to be stored
+// or given up on, and returns the number of chunks stored. The error is the
+// cause of a cancelled run; failed batches are in failures.
+func (p *chunkPipeline) close() (int, error) {
+	defer p.cancel(nil)
+	if len(p.pending) > 0 {
+		p.send(p.pending)
+		p.pending = nil
+	}
+	close(p.toEmbed)
+	<-p.stopped
+
+	if err := p.err(); err != nil {
+		return p.stored, err
+	}
+	p.report(StageEmbed, p.embedded, p.queued)
+	p.report(StageStore, p.batches, p.batches)
+	return p.stored, nil
+}
+
+// attempt runs fn, retrying failures with doubling delays until retries
+// run out or the pipeline is cancelled.
+func (p *chunkPipeline) attempt(fn func() error) error {
+	delay := p.retryDelay
+	err := fn()
+	for i := 0; i < p.retries && err != nil; i++ {
+		select {
+		case <-time.After(delay):
+		case <-p.ctx.Done():
+			return err
+		}
+		delay *= 2
+		err = fn()
+	}
+	return err
+}
+
+// fail records a batch given up on and spends its files from the budget,
+// cancelling the run once it is exhausted. Failures caused by cancellation
+// are not recorded; close reports the cause instead.
+func (p *chunkPipeline) fail(phase Phase, batch []chunk.Chunk, err error) {
+	if p.ctx.Err() != nil {
+		return
+	}
+	var files []string
+	for _, c := range batch {
+		if c.FilePath != "" && !slices.Contains(files, c.FilePath) {
+			files = append(files, c.FilePath)
+		}
+	}
+
+	p.mu.Lock()
+	p.failures = append(p.failures, batchFailure{phase: phase, err: err, files: files})
+	for _, f := range files {
+		p.failedFiles[f] = true
+	}
+	p.mu.Unlock()
+
+	if p.budget != nil {
+		if budgetErr := p.budget.spend(files...); budgetErr != nil {
+			p.cancel(fmt.Errorf("%w; last failure: %w", budgetErr, err))
+		}
+	}
+}
+
+func (p *chunkPipeline) embedLoop() {
+	defer close(p.toStore)
+	for batch := range p.toEmbed {
+		start := p.embedPos
+		p.embedPos += len(batch)
+		if p.ctx.Err() != nil {
+			continue // Let the producer finish sending
+		}
+		if p.enrich != nil {
+			p.enrich(p.ctx, batch)
+		}
+		if p.summarize != nil {
+			p.summarize(p.ctx, batch)
+		}
+		texts := make([]string, len(batch))
+		for i, c := range batch {
+			texts[i] = buildEmbeddingText(c)
+		}
+		var vectors [][]float32
+		err := p.attempt(func() error {
+			var err error
+			vectors, err = p.embed(p.ctx, texts)
+			return err
+		})
+		if err != nil {
+			p.fail(PhaseEmbed, batch, fmt.Errorf("embedding failed: batch %d-%d: %w", start, start+len(batch), err))
+		} else {
+			for i, vec := range vectors {
+				batch[i].Vector = vec
+			}
+			p.embedded += len(batch)
+			p.report(StageEmbed, p.embedded, 0)
+		}
+
+		select {
+		case p.toStore <- storeBatch{chunks: batch, failed: err != nil}:
+		case <-p.ctx.Done():
+		}
+	}
+}
+
+func (p *chunkPipeline) storeLoop() {
+	defer close(p.stopped)
+	for batch := range p.toStore {
+		if p.ctx.Err() != nil {
+			continue
+		}
+		stored := false
+		if !batch.failed {
+			if err := p.attempt(func() error { return p.store(p.ctx, batch.chunks) }); err != nil {
+				p.fail(PhaseStore, batch.chunks, fmt.Errorf("upsert failed: %w", err))
+			} else {
+				stored = true
+				p.batches++
+				p.report(StageStore, p.batches, 0)
+			}
+		}
+
+		p.mu.Lock()
+		if stored {
+			p.stored += len(batch.chunks)
+		}
+		p.done += len(batch.chunks)
+		n := 0
+		for n < len(p.marks) && p.marks[n].end <= p.done {
+			n++
+		}
+		var completed []fileMark
+		for _, m := range p.marks[:n] {
+			if !p.failedFiles[m.path] {
+				completed = append(completed, m)
+			}
+		}
+		p.marks = p.marks[n:]
+		p.mu.Unlock()
+
+		for _, m := range completed {
+			p.onFile(m.path, m.hash)
+		}
+	}
+}
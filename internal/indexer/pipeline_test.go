@@ -0,0 +1,235 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeEmbed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{float32(len(texts[i]))}
+	}
+	return vectors, nil
+}
+
+func testChunks(n int) []chunk.Chunk {
+	chunks := make([]chunk.Chunk, n)
+	for i := range chunks {
+		chunks[i] = chunk.Chunk{ID: fmt.Sprintf("c%d", i), Content: fmt.Sprintf("chunk %d", i)}
+	}
+	return chunks
+}
+
+func TestChunkPipelineStoresEverything(t *testing.T) {
+	var mu sync.Mutex
+	var stored []chunk.Chunk
+	var batchSizes []int
+	store := func(_ context.Context, chunks []chunk.Chunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stored = append(stored, chunks...)
+		batchSizes = append(batchSizes, len(chunks))
+		return nil
+	}
+
+	var reports []Progress
+	opts := IndexOptions{Progress: ProgressFunc(func(p Progress) { reports = append(reports, p) }).serialized()}
+
+	p := newChunkPipeline(context.Background(), fakeEmbed, store, opts.report, nil)
+	chunks := testChunks(200)
+	for i := 0; i < len(chunks); i += 7 {
+		p.add(chunks[i:min(i+7, len(chunks))]...)
+	}
+	n, err := p.close()
+	require.NoError(t, err)
+
+	assert.Equal(t, 200, n)
+	require.Len(t, stored, 200)
+	for _, c := range stored {
+		assert.NotEmpty(t, c.Vector, c.ID)
+	}
+	assert.Equal(t, []int{64, 64, 64, 8}, batchSizes)
+
+	// Totals are only known once the pipeline is closed
+	require.GreaterOrEqual(t, len(reports), 2)
+	assert.Equal(t, Progress{Stage: StageEmbed, Done: 200, Total: 200}, reports[len(reports)-2])
+	assert.Equal(t, Progress{Stage: StageStore, Done: 4, Total: 4}, reports[len(reports)-1])
+	for _, r := range reports[:len(reports)-2] {
+		assert.Zero(t, r.Total)
+	}
+}
+
+func TestChunkPipelineEmbedFailure(t *testing.T) {
+	calls := 0
+	embed := func(ctx context.Context, texts []string) ([][]float32, error) {
+		calls++
+		if calls == 2 {
+			return nil, errors.New("rate limited")
+		}
+		return fakeEmbed(ctx, texts)
+	}
+	var mu sync.Mutex
+	var stored []chunk.Chunk
+	store := func(_ context.Context, chunks []chunk.Chunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stored = append(stored, chunks...)
+		return nil
+	}
+
+	p := newChunkPipeline(context.Background(), embed, store, IndexOptions{}.report, nil)
+	p.add(testChunks(200)...)
+	n, err := p.close()
+	require.NoError(t, err, "a failed batch does not fail the run")
+
+	assert.Equal(t, 136, n)
+	assert.Len(t, stored, 136)
+	require.Len(t, p.failures, 1)
+	assert.Equal(t, PhaseEmbed, p.failures[0].phase)
+	assert.Contains(t, p.failures[0].err.Error(), "embedding failed: batch 64-128")
+	assert.Contains(t, p.failures[0].err.Error(), "rate limited")
+}
+
+func TestChunkPipelineStoreFailure(t *testing.T) {
+	store := func(context.Context, []chunk.Chunk) error { return errors.New("qdrant down") }
+
+	p := newChunkPipeline(context.Background(), fakeEmbed, store, IndexOptions{}.report, nil)
+	p.add(testChunks(100)...)
+	n, err := p.close()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	require.Len(t, p.failures, 2)
+	for _, f := range p.failures {
+		assert.Equal(t, PhaseStore, f.phase)
+		assert.Contains(t, f.err.Error(), "upsert failed: qdrant down")
+	}
+}
+
+func TestChunkPipelineRetries(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	store := func(context.Context, []chunk.Chunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls <= 2 {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+
+	p := newChunkPipeline(context.Background(), fakeEmbed, store, IndexOptions{}.report, nil)
+	p.retries = 2
+	p.retryDelay = time.Millisecond
+	p.add(testChunks(100)...)
+	n, err := p.close()
+	require.NoError(t, err)
+
+	assert.Equal(t, 100, n)
+	assert.Empty(t, p.failures)
+	assert.Equal(t, 4, calls)
+}
+
+func TestChunkPipelineErrorBudget(t *testing.T) {
+	store := func(context.Context, []chunk.Chunk) error { return errors.New("qdrant down") }
+
+	chunks := testChunks(1000)
+	for i := range chunks {
+		chunks[i].FilePath = fmt.Sprintf("file%d.py", i/10)
+	}
+	p := newChunkPipeline(context.Background(), fakeEmbed, store, IndexOptions{}.report, nil)
+	p.budget = newErrorBudget(5, 100)
+	for _, c := range chunks {
+		p.add(c)
+	}
+	_, err := p.close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error budget exceeded")
+	assert.Contains(t, err.Error(), "qdrant down")
+	assert.Len(t, p.failures, 1, "the first batch's seven files exhaust it; nothing after is attempted")
+}
+
+func TestChunkPipelineFailedFileNotDone(t *testing.T) {
+	calls := 0
+	embed := func(ctx context.Context, texts []string) ([][]float32, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("rate limited")
+		}
+		return fakeEmbed(ctx, texts)
+	}
+	store := func(context.Context, []chunk.Chunk) error { return nil }
+	var completed []string
+	onFile := func(path, _ string) { completed = append(completed, path) }
+
+	chunks := testChunks(128)
+	for i := range chunks {
+		chunks[i].FilePath = "a.py"
+		if i >= 64 {
+			chunks[i].FilePath = "b.py"
+		}
+	}
+	p := newChunkPipeline(context.Background(), embed, store, IndexOptions{}.report, onFile)
+	p.add(chunks[:64]...)
+	p.fileDone("a.py", "h1")
+	p.add(chunks[64:]...)
+	p.fileDone("b.py", "h2")
+	_, err := p.close()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"b.py"}, completed)
+	assert.Equal(t, map[string]bool{"a.py": true}, p.failedFiles)
+}
+
+func TestChunkPipelineCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	store := func(context.Context, []chunk.Chunk) error { return nil }
+	p := newChunkPipeline(ctx, fakeEmbed, store, IndexOptions{}.report, nil)
+	p.add(testChunks(1000)...)
+	_, err := p.close()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestChunkPipelineFileDone(t *testing.T) {
+	var mu sync.Mutex
+	var stored int
+	var completed []string
+	storedWhenDone := map[string]int{}
+	store := func(_ context.Context, chunks []chunk.Chunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stored += len(chunks)
+		return nil
+	}
+	onFile := func(path, hash string) {
+		mu.Lock()
+		defer mu.Unlock()
+		completed = append(completed, path+"@"+hash)
+		storedWhenDone[path] = stored
+	}
+
+	p := newChunkPipeline(context.Background(), fakeEmbed, store, IndexOptions{}.report, onFile)
+	chunks := testChunks(150)
+	p.add(chunks[:40]...)
+	p.fileDone("a.py", "h1")
+	p.fileDone("empty.py", "h2") // No chunks of its own: done once a.py is
+	p.add(chunks[40:150]...)
+	p.fileDone("b.py", "h3")
+	_, err := p.close()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a.py@h1", "empty.py@h2", "b.py@h3"}, completed)
+	assert.GreaterOrEqual(t, storedWhenDone["a.py"], 40)
+	assert.Equal(t, 150, storedWhenDone["b.py"])
+}
@@ -0,0 +1,59 @@
+package indexer
+
+import (
+	"sync"
+	"time"
+)
+
+// Stage names a phase of an indexing run that reports progress.
+type Stage string
+
+const (
+	StageParse Stage = "parse" // Files read, hashed, and chunked
+	StageEmbed Stage = "embed" // Chunks embedded
+	StageStore Stage = "store" // Upsert batches written to Qdrant
+)
+
+// Progress is a snapshot of one stage. Done counts files for StageParse,
+// chunks for StageEmbed, and batches for StageStore. Total is 0 while still
+// unknown: embedding and storing start before parsing ends, so their totals
+// are only reported once the last chunk is stored.
+type Progress struct {
+	Stage Stage
+	Done  int
+	Total int
+}
+
+// ProgressFunc receives progress updates. Stages run concurrently, so
+// updates for different stages interleave and may come from different
+// goroutines, but calls never overlap. It should return quickly, since it
+// holds up the stage that reported.
+type ProgressFunc func(Progress)
+
+// ETA estimates the time left in the stage from how long it has taken so far,
+// assuming a steady rate. It returns 0 until there is something to go on.
+func (p Progress) ETA(elapsed time.Duration) time.Duration {
+	if p.Done <= 0 || p.Total <= p.Done {
+		return 0
+	}
+	return time.Duration(float64(elapsed) / float64(p.Done) * float64(p.Total-p.Done))
+}
+
+// serialized wraps f so that concurrent stages never call it at once.
+func (f ProgressFunc) serialized() ProgressFunc {
+	if f == nil {
+		return nil
+	}
+	var mu sync.Mutex
+	return func(p Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		f(p)
+	}
+}
+
+func (opts IndexOptions) report(stage Stage, done, total int) {
+	if opts.Progress != nil {
+		opts.Progress(Progress{Stage: stage, Done: done, Total: total})
+	}
+}
@@ -0,0 +1,39 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressETA(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress Progress
+		elapsed  time.Duration
+		want     time.Duration
+	}{
+		{"halfway", Progress{Done: 50, Total: 100}, 10 * time.Second, 10 * time.Second},
+		{"quarter", Progress{Done: 25, Total: 100}, 5 * time.Second, 15 * time.Second},
+		{"not started", Progress{Done: 0, Total: 100}, time.Second, 0},
+		{"finished", Progress{Done: 100, Total: 100}, time.Minute, 0},
+		{"unknown total", Progress{Done: 10}, time.Second, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.progress.ETA(tt.elapsed))
+		})
+	}
+}
+
+func TestIndexOptionsReport(t *testing.T) {
+	var got []Progress
+	opts := IndexOptions{Progress: func(p Progress) { got = append(got, p) }}
+
+	opts.report(StageEmbed, 64, 200)
+	IndexOptions{}.report(StageEmbed, 1, 1) // nil Progress is a no-op
+
+	assert.Equal(t, []Progress{{Stage: StageEmbed, Done: 64, Total: 200}}, got)
+}
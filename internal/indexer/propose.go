@@ -0,0 +1,121 @@
+package indexer
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/parser"
+)
+
+// proposedExcludeDirs are directories that usually hold vendored, generated,
+// or build output code. The walker's defaults don't skip them, so init
+// proposes an exclude for each one present.
+var proposedExcludeDirs = []string{
+	"vendor",
+	"third_party",
+	"generated",
+	"coverage",
+	"target",
+	"out",
+	".next",
+	".tox",
+	".mypy_cache",
+	"site-packages",
+}
+
+// proposedExcludeFiles are generated-file patterns proposed when they match.
+var proposedExcludeFiles = []string{
+	"**/*_pb2.py",
+	"**/*_pb2_grpc.py",
+	"**/*.d.ts",
+}
+
+// ConfigProposal is a generated repo config plus what the scan found.
+type ConfigProposal struct {
+	Config     config.RepoConfig
+	Extensions map[string]int // Indexable source files per extension
+	Excluded   map[string]int // Files matched by each proposed exclude
+}
+
+// ProposeRepoConfig scans a repo and proposes its .ai-devtools.yaml: the
+// name from the directory, modules from DetectModules, an include glob per
+// indexable extension found, and excludes for vendored or generated code.
+// Directories the walker always skips are not scanned.
+func ProposeRepoConfig(repoPath, defaultBranch string) (*ConfigProposal, error) {
+	proposal := &ConfigProposal{
+		Config: config.RepoConfig{
+			Name:          filepath.Base(repoPath),
+			DefaultBranch: defaultBranch,
+			Modules:       DetectModules(repoPath),
+		},
+		Extensions: make(map[string]int),
+		Excluded:   make(map[string]int),
+	}
+
+	walker := NewWalker(nil, nil)
+	err := filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if d.IsDir() {
+			if relPath != "." && walker.shouldExcludeDir(relPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if walker.isExcluded(relPath) {
+			return nil
+		}
+		if _, ok := parser.DetectLanguage(relPath); !ok {
+			return nil
+		}
+
+		if pattern := proposedExclude(relPath); pattern != "" {
+			proposal.Excluded[pattern]++
+			return nil
+		}
+		proposal.Extensions[filepath.Ext(relPath)]++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for ext := range proposal.Extensions {
+		proposal.Config.Include = append(proposal.Config.Include, "**/*"+ext)
+	}
+	sort.Strings(proposal.Config.Include)
+	for pattern := range proposal.Excluded {
+		proposal.Config.Exclude = append(proposal.Config.Exclude, pattern)
+	}
+	sort.Strings(proposal.Config.Exclude)
+
+	return proposal, nil
+}
+
+// proposedExclude returns the exclude pattern covering a file, if any.
+func proposedExclude(relPath string) string {
+	parts := strings.Split(relPath, "/")
+	for _, dir := range parts[:len(parts)-1] {
+		for _, name := range proposedExcludeDirs {
+			if dir == name {
+				return "**/" + name + "/**"
+			}
+		}
+	}
+	for _, pattern := range proposedExcludeFiles {
+		if strings.HasSuffix(relPath, strings.TrimPrefix(pattern, "**/*")) {
+			return pattern
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(""), 0644))
+	}
+}
+
+func TestProposeRepoConfig(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "shop")
+	writeFiles(t, root,
+		"app/__init__.py",
+		"app/models.py",
+		"app/api_pb2.py",
+		"web/package.json",
+		"web/src/index.ts",
+		"web/src/types.d.ts",
+		"web/src/App.tsx",
+		"vendor/lib/util.py",
+		"node_modules/react/index.js",
+		"README.md",
+	)
+
+	proposal, err := ProposeRepoConfig(root, "develop")
+	require.NoError(t, err)
+
+	cfg := proposal.Config
+	assert.Equal(t, "shop", cfg.Name)
+	assert.Equal(t, "develop", cfg.DefaultBranch)
+	assert.Contains(t, cfg.Modules, "app")
+	assert.Contains(t, cfg.Modules, "web")
+
+	assert.Equal(t, []string{"**/*.py", "**/*.ts", "**/*.tsx"}, cfg.Include)
+	assert.Equal(t, []string{"**/*.d.ts", "**/*_pb2.py", "**/vendor/**"}, cfg.Exclude)
+	assert.Equal(t, map[string]int{".py": 2, ".ts": 1, ".tsx": 1}, proposal.Extensions)
+	assert.Equal(t, 1, proposal.Excluded["**/vendor/**"])
+	assert.NotContains(t, proposal.Extensions, ".js", "node_modules is never scanned")
+}
+
+func TestProposeRepoConfigRoundTrips(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, "pkg/__init__.py", "pkg/core.py")
+
+	proposal, err := ProposeRepoConfig(root, "main")
+	require.NoError(t, err)
+
+	path, err := config.SaveRepoConfig(root, &proposal.Config)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".ai-devtools.yaml"), path)
+
+	loaded, err := config.LoadRepoConfig(root)
+	require.NoError(t, err)
+	assert.Equal(t, proposal.Config.Name, loaded.Name)
+	assert.Equal(t, proposal.Config.Include, loaded.Include)
+	assert.Equal(t, proposal.Config.Modules["pkg"].Description, loaded.Modules["pkg"].Description)
+}
@@ -0,0 +1,121 @@
+package indexer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/parser"
+)
+
+// walkRoot is a monorepo root prepared for matching repo-relative paths.
+type walkRoot struct {
+	path         string // Repo-relative, no trailing slash
+	includes     []string
+	excludes     []string
+	languages    map[parser.Language]bool // nil allows any
+	modulePrefix string
+}
+
+// newWalkRoots prepares the repo's roots, deepest first so a nested root
+// claims its files before its parent. Roots without include patterns use
+// the repo's, or the walker defaults.
+func newWalkRoots(cfg *config.RepoConfig) []walkRoot {
+	if cfg == nil || len(cfg.Roots) == 0 {
+		return nil
+	}
+	roots := make([]walkRoot, 0, len(cfg.Roots))
+	for _, rc := range cfg.Roots {
+		root := walkRoot{
+			path:         strings.Trim(rc.Path, "/"),
+			includes:     rc.Include,
+			excludes:     rc.Exclude,
+			modulePrefix: strings.Trim(rc.ModulePrefix, "."),
+		}
+		if len(root.includes) == 0 {
+			root.includes = cfg.Include
+		}
+		if len(root.includes) == 0 {
+			root.includes = defaultIncludes
+		}
+		if len(rc.Languages) > 0 {
+			root.languages = make(map[parser.Language]bool, len(rc.Languages))
+			for _, lang := range rc.Languages {
+				root.languages[parser.Language(strings.ToLower(lang))] = true
+			}
+		}
+		roots = append(roots, root)
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		return strings.Count(roots[i].path, "/") > strings.Count(roots[j].path, "/")
+	})
+	return roots
+}
+
+// findRoot returns the root containing the repo-relative path (or the root
+// directory itself), or nil.
+func findRoot(roots []walkRoot, relPath string) *walkRoot {
+	for i := range roots {
+		r := &roots[i]
+		if r.path == "" || relPath == r.path || strings.HasPrefix(relPath, r.path+"/") {
+			return r
+		}
+	}
+	return nil
+}
+
+// aboveRoot reports whether the repo-relative directory is an ancestor of
+// some root, so it must be entered to reach it.
+func aboveRoot(roots []walkRoot, relDir string) bool {
+	for _, r := range roots {
+		if strings.HasPrefix(r.path, relDir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// below returns relPath relative to the root: "" for the root itself.
+func (r *walkRoot) below(relPath string) string {
+	if r.path == "" {
+		return relPath
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(relPath, r.path), "/")
+}
+
+// accepts applies the root's patterns and languages to a file inside it.
+func (r *walkRoot) accepts(relPath string) bool {
+	rel := r.below(relPath)
+	if matchesAny(r.excludes, rel) || !matchesAny(r.includes, rel) {
+		return false
+	}
+	if r.languages != nil {
+		lang, ok := parser.DetectLanguage(rel)
+		return ok && r.languages[lang]
+	}
+	return true
+}
+
+// moduleName is the dotted module path of a repo-relative slash path
+// (without extension). Under a root with a module prefix it is the prefix
+// plus the path below the root; otherwise it is derived from the whole path.
+func moduleName(relPath string, roots []walkRoot) string {
+	if r := findRoot(roots, relPath); r != nil && r.modulePrefix != "" {
+		rel := r.below(relPath)
+		if rel == "" {
+			return r.modulePrefix
+		}
+		return r.modulePrefix + "." + dottedPath(rel)
+	}
+	return dottedPath(relPath)
+}
+
+// moduleFSPath is the directory a file's module root lives in, with a
+// trailing slash: its root's directory when the root names its modules,
+// otherwise the file's top-level directory.
+func moduleFSPath(relPath string, roots []walkRoot) string {
+	if r := findRoot(roots, relPath); r != nil && r.modulePrefix != "" && r.path != "" {
+		return r.path + "/"
+	}
+	return topLevelDir(relPath)
+}
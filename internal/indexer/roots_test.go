@@ -0,0 +1,85 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func monorepoConfig() *config.RepoConfig {
+	return &config.RepoConfig{
+		Name:    "mono",
+		Include: []string{"**/*.py", "**/*.ts"},
+		Exclude: []string{"**/generated/**"},
+		Roots: []config.RootConfig{
+			{Path: "frontend", Languages: []string{"TypeScript"}, ModulePrefix: "web"},
+			{Path: "backend", Exclude: []string{"migrations/**"}},
+			{Path: "services/billing", Include: []string{"src/**/*.py"}, ModulePrefix: "billing"},
+		},
+	}
+}
+
+func TestWalkerRoots(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir,
+		"frontend/src/app.ts",
+		"frontend/scripts/build.py", // Not a TypeScript file
+		"frontend/generated/api.ts", // Repo-wide exclude
+		"backend/api/views.py",
+		"backend/migrations/0001_initial.py",
+		"services/billing/src/invoice.py",
+		"services/billing/tests/test_invoice.py", // Outside the root's include
+		"services/other/main.py",                 // Not under a root
+		"tools/release.py",
+	)
+
+	w := NewRepoWalker(monorepoConfig())
+	var files []string
+	err := w.Walk(tmpDir, func(path string) error {
+		rel, _ := filepath.Rel(tmpDir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"frontend/src/app.ts",
+		"backend/api/views.py",
+		"services/billing/src/invoice.py",
+	}, files)
+}
+
+func TestModuleResolverRoots(t *testing.T) {
+	resolver := NewModuleResolver("/repo", monorepoConfig())
+
+	modulePath, moduleRoot, submodule := resolver.Resolve("frontend/src/app.ts")
+	assert.Equal(t, "web.src.app", modulePath)
+	assert.Equal(t, "web", moduleRoot)
+	assert.Equal(t, "src", submodule)
+
+	// No prefix: named from the repo root as before
+	modulePath, moduleRoot, _ = resolver.Resolve("backend/api/views.py")
+	assert.Equal(t, "backend.api.views", modulePath)
+	assert.Equal(t, "backend", moduleRoot)
+
+	// The nested root wins over any shallower one
+	modulePath, _, _ = resolver.Resolve("services/billing/src/invoice.py")
+	assert.Equal(t, "billing.src.invoice", modulePath)
+}
+
+func TestModuleScopeWithRoots(t *testing.T) {
+	roots := newWalkRoots(monorepoConfig())
+
+	assert.True(t, inModule("frontend/src/app.ts", "web", roots))
+	assert.True(t, inModule("frontend/src/app.ts", "web.src", roots))
+	assert.False(t, inModule("frontend/src/app.ts", "frontend", roots))
+
+	assert.True(t, dirOverlapsModule("services", "billing.src", roots)) // Above a root
+	assert.True(t, dirOverlapsModule("services/billing/src", "billing.src", roots))
+	assert.False(t, dirOverlapsModule("backend", "billing", roots))
+
+	assert.Equal(t, "services/billing/", moduleFSPath("services/billing/src/invoice.py", roots))
+	assert.Equal(t, "backend/", moduleFSPath("backend/api/views.py", roots))
+}
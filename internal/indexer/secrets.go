@@ -0,0 +1,83 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/security"
+)
+
+// configureExtractor applies repoCfg's test patterns and secrets policy and
+// allowlist to the extractor, with the global secret rules.
+func (idx *Indexer) configureExtractor(repoCfg *config.RepoConfig) error {
+	idx.extractor.SetTestMatcher(chunk.NewTestMatcher(repoCfg.Tests.Patterns, repoCfg.Tests.Dirs))
+
+	policy, err := chunk.ParseSecretPolicy(repoCfg.Secrets.Policy)
+	if err != nil {
+		return fmt.Errorf("repo secrets: %w", err)
+	}
+	idx.extractor.SetSecretPolicy(policy)
+
+	var rules config.SecretsConfig
+	if idx.config != nil {
+		rules = idx.config.Secrets
+	}
+	detector, err := secretDetector(rules, repoCfg.Secrets)
+	if err != nil {
+		return err
+	}
+	idx.extractor.SetSecretDetector(detector)
+	return nil
+}
+
+// secretDetector builds a detector of the built-in patterns, the configured
+// rules and gitleaks file, and the repo's allowlist.
+func secretDetector(cfg config.SecretsConfig, repo config.RepoSecretsConfig) (*security.SecretDetector, error) {
+	detector := security.NewSecretDetector()
+
+	rules := make([]security.Rule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		rules = append(rules, security.Rule{Name: r.Name, Pattern: r.Pattern, Redact: r.Redact})
+	}
+	if err := detector.AddRules(rules); err != nil {
+		return nil, fmt.Errorf("secrets.rules: %w", err)
+	}
+
+	if cfg.GitleaksFile != "" {
+		gitleaksRules, allow, err := security.LoadGitleaks(cfg.GitleaksFile)
+		if err != nil {
+			return nil, fmt.Errorf("secrets.gitleaks_file: %w", err)
+		}
+		if err := detector.AddRules(gitleaksRules); err != nil {
+			return nil, fmt.Errorf("secrets.gitleaks_file: %w", err)
+		}
+		if err := detector.AddAllowlist(allow); err != nil {
+			return nil, fmt.Errorf("secrets.gitleaks_file: %w", err)
+		}
+	}
+
+	if err := detector.AddAllowlist(security.Allowlist{Values: repo.Allowlist, Paths: repo.AllowlistPaths}); err != nil {
+		return nil, fmt.Errorf("repo secrets: %w", err)
+	}
+	return detector, nil
+}
+
+// dropSecretFiles removes what earlier runs stored of files this run left
+// out for containing secrets.
+func (idx *Indexer) dropSecretFiles(ctx context.Context, collection string, graphStore *graph.Neo4jStore, repo string, result *IndexResult) {
+	for _, path := range result.SecretFilesSkipped {
+		if err := idx.store.DeleteByFilter(ctx, collection, map[string]interface{}{"repo": repo, "file_path": path}); err != nil {
+			result.addError(PhasePrune, fmt.Errorf("delete chunks for %s: %w", path, err))
+			continue
+		}
+		if graphStore == nil {
+			continue
+		}
+		if err := graphStore.DeleteFile(ctx, repo, path); err != nil {
+			result.addError(PhasePrune, fmt.Errorf("delete %s from graph: %w", path, err))
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretDetector(t *testing.T) {
+	gitleaks := filepath.Join(t.TempDir(), "gitleaks.toml")
+	require.NoError(t, os.WriteFile(gitleaks, []byte(`[[rules]]
+id = "acme-pat"
+regex = '''acmepat_[A-Za-z0-9]{20}'''
+`), 0644))
+
+	detector, err := secretDetector(config.SecretsConfig{
+		Rules:        []config.SecretRule{{Name: "acme_token", Pattern: `acme_[a-z0-9]{16}`}},
+		GitleaksFile: gitleaks,
+	}, config.RepoSecretsConfig{
+		Allowlist:      []string{`acme_f{16}`},
+		AllowlistPaths: []string{"tests/fixtures/**"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, detector.HasSecrets(`TOKEN = acme_0123456789abcdef`))
+	assert.True(t, detector.HasSecrets(`PAT = acmepat_abcdefghij0123456789`))
+	assert.False(t, detector.HasSecrets(`TOKEN = acme_ffffffffffffffff`))
+	assert.True(t, detector.PathAllowed("tests/fixtures/tokens.py"))
+
+	_, err = secretDetector(config.SecretsConfig{Rules: []config.SecretRule{{Name: "bad", Pattern: `(`}}}, config.RepoSecretsConfig{})
+	assert.ErrorContains(t, err, "secrets.rules")
+	_, err = secretDetector(config.SecretsConfig{GitleaksFile: filepath.Join(t.TempDir(), "missing.toml")}, config.RepoSecretsConfig{})
+	assert.ErrorContains(t, err, "secrets.gitleaks_file")
+}
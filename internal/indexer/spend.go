@@ -0,0 +1,31 @@
+package indexer
+
+import (
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/metrics"
+)
+
+// SetMetrics logs the embedding tokens of every run and single-file
+// reindex to the usage metrics log, for `code-indexer metrics --cost`.
+// Nil disables.
+func (idx *Indexer) SetMetrics(usage *metrics.Logger) {
+	idx.usage = usage
+}
+
+// logSpend logs what a run started at started embedded, failed or not.
+// Runs that embedded nothing cost nothing and are not logged.
+func (idx *Indexer) logSpend(repo, trigger string, started time.Time, result *IndexResult) {
+	if idx.usage == nil || result == nil || result.EmbeddingTokens == 0 {
+		return
+	}
+	idx.usage.LogIndexRun(metrics.IndexRunEvent{
+		Repo:    repo,
+		Run:     started,
+		Trigger: trigger,
+		Model:   idx.embedder.Model(),
+		Tokens:  result.EmbeddingTokens,
+		Files:   result.FilesProcessed,
+		Chunks:  result.ChunksCreated,
+	})
+}
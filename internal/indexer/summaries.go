@@ -0,0 +1,69 @@
+package indexer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+)
+
+// defaultSummaryConcurrency bounds summary requests per batch when the
+// config does not.
+const defaultSummaryConcurrency = 4
+
+// Summarizer writes natural-language summaries of chunks at index time
+// (summarize.Client, when summaries are enabled).
+type Summarizer interface {
+	Wants(c chunk.Chunk) bool
+	Summarize(ctx context.Context, c chunk.Chunk) (string, error)
+}
+
+// SetSummarizer makes runs summarize the chunks s wants before embedding
+// them, with up to maxConcurrent requests at once. Nil disables summaries.
+func (idx *Indexer) SetSummarizer(s Summarizer, maxConcurrent int) {
+	idx.summarizer = s
+	idx.summaryConcurrency = maxConcurrent
+}
+
+// summarizeBatch fills in Summary for the chunks the summarizer wants and
+// returns how many it summarized. Summaries are an extra: a failed request
+// is logged and the chunk is embedded without one.
+func (idx *Indexer) summarizeBatch(ctx context.Context, batch []chunk.Chunk) int {
+	limit := idx.summaryConcurrency
+	if limit <= 0 {
+		limit = defaultSummaryConcurrency
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	summarized := 0
+	for i := range batch {
+		if batch[i].Summary != "" || !idx.summarizer.Wants(batch[i]) {
+			continue
+		}
+		wg.Add(1)
+		go func(c *chunk.Chunk) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			summary, err := idx.summarizer.Summarize(ctx, *c)
+			if err != nil {
+				if ctx.Err() == nil {
+					idx.logger.Warn("failed to summarize chunk", "path", c.FilePath, "symbol", c.SymbolName, "error", err)
+				}
+				return
+			}
+			c.Summary = summary
+			mu.Lock()
+			summarized++
+			mu.Unlock()
+		}(&batch[i])
+	}
+	wg.Wait()
+	return summarized
+}
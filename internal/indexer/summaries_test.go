@@ -0,0 +1,89 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSummarizer struct {
+	inFlight, peak atomic.Int32
+	mu             sync.Mutex
+	calls          []string
+}
+
+func (f *fakeSummarizer) Wants(c chunk.Chunk) bool { return c.Kind == "function" }
+
+func (f *fakeSummarizer) Summarize(_ context.Context, c chunk.Chunk) (string, error) {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		peak := f.peak.Load()
+		if n <= peak || f.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	f.mu.Lock()
+	f.calls = append(f.calls, c.SymbolName)
+	f.mu.Unlock()
+	if c.SymbolName == "broken" {
+		return "", errors.New("overloaded")
+	}
+	return "summary of " + c.SymbolName, nil
+}
+
+func TestSummarizeBatch(t *testing.T) {
+	summarizer := &fakeSummarizer{}
+	idx := &Indexer{logger: slog.Default()}
+	idx.SetSummarizer(summarizer, 2)
+
+	batch := []chunk.Chunk{
+		{Kind: "function", SymbolName: "upload"},
+		{Kind: "class_summary", SymbolName: "Uploader"},
+		{Kind: "function", SymbolName: "broken"},
+		{Kind: "function", SymbolName: "kept", Summary: "already summarized"},
+	}
+	for i := range 8 {
+		batch = append(batch, chunk.Chunk{Kind: "function", SymbolName: string(rune('a' + i))})
+	}
+
+	n := idx.summarizeBatch(context.Background(), batch)
+
+	assert.Equal(t, 9, n, "every wanted chunk but the failed one")
+	assert.Equal(t, "summary of upload", batch[0].Summary)
+	assert.Empty(t, batch[1].Summary, "not wanted")
+	assert.Empty(t, batch[2].Summary, "failures leave the chunk unsummarized")
+	assert.Equal(t, "already summarized", batch[3].Summary)
+	assert.NotContains(t, summarizer.calls, "kept")
+	assert.LessOrEqual(t, summarizer.peak.Load(), int32(2))
+}
+
+func TestChunkPipelineSummarizesBeforeEmbedding(t *testing.T) {
+	var texts []string
+	embed := func(ctx context.Context, batch []string) ([][]float32, error) {
+		texts = append(texts, batch...)
+		return fakeEmbed(ctx, batch)
+	}
+	store := func(context.Context, []chunk.Chunk) error { return nil }
+
+	p := newChunkPipeline(context.Background(), embed, store, IndexOptions{}.report, nil)
+	p.summarize = func(_ context.Context, batch []chunk.Chunk) {
+		for i := range batch {
+			batch[i].Summary = "Uploads files to S3."
+		}
+	}
+	p.add(chunk.Chunk{ID: "a", Content: "def upload(): ..."})
+	_, err := p.close()
+	require.NoError(t, err)
+
+	require.Len(t, texts, 1)
+	assert.Contains(t, texts[0], "Uploads files to S3.")
+	assert.Contains(t, texts[0], "def upload(): ...")
+}
@@ -0,0 +1,215 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+)
+
+// ErrDimensionMismatch means the chunks collection was created for a
+// different embedding model. Every repo shares the collection, so it cannot
+// be repaired one file at a time.
+var ErrDimensionMismatch = errors.New("collection vector size does not match the embedding model")
+
+// VerifyReport lists where a repo's index has drifted from its working tree.
+type VerifyReport struct {
+	Repo         string   `json:"repo"`
+	FilesOnDisk  int      `json:"files_on_disk"`
+	FilesIndexed int      `json:"files_indexed"`
+	Missing      []string `json:"missing"` // On disk, never indexed
+	Stale        []string `json:"stale"`   // Indexed, no longer on disk
+	Changed      []string `json:"changed"` // Content differs from the stored hash
+	// HashesChecked is false without a graph store, which holds the hashes;
+	// Changed is then always empty.
+	HashesChecked bool `json:"hashes_checked"`
+	CollectionDim int  `json:"collection_dim"` // 0 when the collection doesn't exist
+	EmbeddingDim  int  `json:"embedding_dim"`
+}
+
+// DimensionMismatch reports whether the collection's vectors are a different
+// size than the embedder produces.
+func (r *VerifyReport) DimensionMismatch() bool {
+	return r.CollectionDim != 0 && r.CollectionDim != r.EmbeddingDim
+}
+
+// Consistent reports whether the index matches the working tree.
+func (r *VerifyReport) Consistent() bool {
+	return len(r.Missing) == 0 && len(r.Stale) == 0 && len(r.Changed) == 0 && !r.DimensionMismatch()
+}
+
+// Verify compares a repo's indexed files against its working tree. Code
+// chunks in Qdrant give the set of indexed files; with a graph store the
+// File nodes add content hashes and the nodes left behind by deleted files.
+func (idx *Indexer) Verify(ctx context.Context, repoPath string, repoCfg *config.RepoConfig, graphStore *graph.Neo4jStore) (*VerifyReport, error) {
+	report := &VerifyReport{
+		Repo:         repoCfg.Name,
+		EmbeddingDim: idx.embedder.Dimension(),
+	}
+
+	collectionName := "chunks"
+	indexed := make(map[string]int)
+	exists, err := idx.store.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("check collection: %w", err)
+	}
+	if exists {
+		info, err := idx.store.CollectionInfo(ctx, collectionName)
+		if err != nil {
+			return nil, fmt.Errorf("collection info: %w", err)
+		}
+		report.CollectionDim = info.VectorSize
+
+		// Doc and pattern chunks aren't tied to walked source files
+		indexed, err = idx.store.CountByField(ctx, collectionName, "file_path", map[string]interface{}{
+			"repo": repoCfg.Name,
+			"type": "code",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("count indexed files: %w", err)
+		}
+	}
+
+	var stored map[string]string
+	if graphStore != nil {
+		stored, err = graphStore.GetAllFileHashes(ctx, repoCfg.Name)
+		if err != nil {
+			return nil, fmt.Errorf("get file hashes: %w", err)
+		}
+		report.HashesChecked = true
+	}
+
+	onDisk := make(map[string]string)
+	walker := NewRepoWalker(repoCfg)
+	err = walker.Walk(repoPath, func(path string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		relPath, _ := filepath.Rel(repoPath, path)
+		relPath = filepath.ToSlash(relPath)
+		onDisk[relPath] = ""
+		if stored != nil {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", relPath, err)
+			}
+			onDisk[relPath] = computeFileHash(source)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk failed: %w", err)
+	}
+
+	report.FilesOnDisk = len(onDisk)
+	report.FilesIndexed = len(indexed)
+	report.Missing, report.Stale, report.Changed = compareIndex(onDisk, indexed, stored)
+
+	// Files that extract to no chunks (empty __init__.py, ...) never reach
+	// Qdrant, so they are only missing if parsing them now finds something
+	missing := report.Missing[:0]
+	for _, relPath := range report.Missing {
+		if idx.yieldsChunks(repoPath, repoCfg, relPath) {
+			missing = append(missing, relPath)
+		}
+	}
+	report.Missing = missing
+
+	return report, nil
+}
+
+// Repair re-indexes the missing and changed files in the report with
+// IndexFile and drops the chunks and File nodes of stale ones, which the
+// walk no longer passes (deleted, or now excluded). A
+// dimension mismatch is returned as ErrDimensionMismatch before anything is
+// written. Per-file failures are collected rather than aborting the repair.
+func (idx *Indexer) Repair(ctx context.Context, repoPath string, repoCfg *config.RepoConfig, report *VerifyReport, graphStore *graph.Neo4jStore) (int, []error) {
+	if report.DimensionMismatch() {
+		return 0, []error{fmt.Errorf("%w (collection %d, model %d)", ErrDimensionMismatch, report.CollectionDim, report.EmbeddingDim)}
+	}
+
+	repaired := 0
+	var errs []error
+	for _, relPath := range report.Stale {
+		if err := ctx.Err(); err != nil {
+			return repaired, append(errs, err)
+		}
+		if err := idx.removeFile(ctx, "chunks", repoCfg.Name, relPath, graphStore); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", relPath, err))
+			continue
+		}
+		repaired++
+	}
+
+	paths := make([]string, 0, len(report.Missing)+len(report.Changed))
+	paths = append(paths, report.Missing...)
+	paths = append(paths, report.Changed...)
+	for _, relPath := range paths {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if _, err := idx.IndexFile(ctx, repoPath, repoCfg, relPath, graphStore); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", relPath, err))
+			continue
+		}
+		repaired++
+	}
+	return repaired, errs
+}
+
+// yieldsChunks reports whether a file would produce any chunks. Files that
+// fail to read or parse are reported as missing so they surface.
+func (idx *Indexer) yieldsChunks(repoPath string, repoCfg *config.RepoConfig, relPath string) bool {
+	source, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(relPath)))
+	if err != nil {
+		return true
+	}
+	result, err := idx.extractor.ExtractWithRelationships(source, relPath, repoCfg.Name, "")
+	if err != nil {
+		return true
+	}
+	return len(result.Chunks) > 0
+}
+
+// compareIndex diffs the working tree (path → hash, empty when unhashed)
+// against Qdrant's per-file chunk counts and the graph's stored hashes (nil
+// without a graph). Each result is sorted.
+func compareIndex(onDisk map[string]string, indexed map[string]int, stored map[string]string) (missing, stale, changed []string) {
+	missing, stale, changed = []string{}, []string{}, []string{}
+
+	for path, hash := range onDisk {
+		if _, ok := indexed[path]; !ok {
+			missing = append(missing, path)
+			continue
+		}
+		if storedHash, inGraph := stored[path]; inGraph && hash != "" && storedHash != hash {
+			changed = append(changed, path)
+		}
+	}
+
+	gone := make(map[string]bool)
+	for path := range indexed {
+		if _, ok := onDisk[path]; !ok {
+			gone[path] = true
+		}
+	}
+	for path := range stored {
+		if _, ok := onDisk[path]; !ok {
+			gone[path] = true
+		}
+	}
+	for path := range gone {
+		stale = append(stale, path)
+	}
+
+	sort.Strings(missing)
+	sort.Strings(stale)
+	sort.Strings(changed)
+	return missing, stale, changed
+}
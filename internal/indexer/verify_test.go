@@ -0,0 +1,60 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareIndex(t *testing.T) {
+	onDisk := map[string]string{
+		"app.py":     "h1",
+		"models.py":  "h2-new",
+		"new.py":     "h3",
+		"helpers.py": "h4",
+	}
+	indexed := map[string]int{
+		"app.py":     3,
+		"models.py":  5,
+		"helpers.py": 1,
+		"deleted.py": 2,
+	}
+	stored := map[string]string{
+		"app.py":       "h1",
+		"models.py":    "h2-old",
+		"graphonly.py": "h5",
+	}
+
+	missing, stale, changed := compareIndex(onDisk, indexed, stored)
+
+	assert.Equal(t, []string{"new.py"}, missing)
+	assert.Equal(t, []string{"deleted.py", "graphonly.py"}, stale)
+	assert.Equal(t, []string{"models.py"}, changed, "helpers.py has no stored hash to compare")
+}
+
+func TestCompareIndexWithoutGraph(t *testing.T) {
+	onDisk := map[string]string{"app.py": "", "new.py": ""}
+	indexed := map[string]int{"app.py": 3, "gone.py": 1}
+
+	missing, stale, changed := compareIndex(onDisk, indexed, nil)
+
+	assert.Equal(t, []string{"new.py"}, missing)
+	assert.Equal(t, []string{"gone.py"}, stale)
+	assert.Empty(t, changed)
+}
+
+func TestVerifyReportConsistent(t *testing.T) {
+	report := &VerifyReport{Missing: []string{}, Stale: []string{}, Changed: []string{}, CollectionDim: 1024, EmbeddingDim: 1024}
+	assert.True(t, report.Consistent())
+
+	report.CollectionDim = 0 // Not created yet
+	assert.True(t, report.Consistent())
+
+	report.CollectionDim = 512
+	assert.True(t, report.DimensionMismatch())
+	assert.False(t, report.Consistent())
+
+	report.CollectionDim = 1024
+	report.Stale = []string{"gone.py"}
+	assert.False(t, report.Consistent())
+}
@@ -2,30 +2,67 @@
 package indexer
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
+	"github.com/randalmurphal/code-indexer/internal/config"
+)
+
+// Default content limits; RepoConfig can override them.
+const (
+	DefaultMaxFileKB        = 512
+	DefaultMaxAvgLineLength = 300
+
+	sniffBytes = 8000 // Read for binary and minified detection, as git does for binary
 )
 
 // Walker traverses directories respecting include/exclude patterns.
 type Walker struct {
 	includes []string
 	excludes []string
+
+	maxFileBytes     int64      // 0 = no limit
+	maxAvgLineLength int        // 0 = no minified check
+	module           string     // Dotted module path to restrict to; "" = whole repo
+	followSymlinks   bool       // Enter symlinked directories; off skips links
+	roots            []walkRoot // Monorepo roots; when set, only files under one are walked
+	stats            WalkStats
+}
+
+// WalkStats counts included files the last Walk skipped for their content.
+type WalkStats struct {
+	TooLarge int `json:"too_large"`
+	Binary   int `json:"binary"`
+	Minified int `json:"minified"`
+	Symlinks int `json:"symlinks"` // Not followed, broken, or leading to a path already walked
+}
+
+// Total is the number of files skipped.
+func (s WalkStats) Total() int {
+	return s.TooLarge + s.Binary + s.Minified + s.Symlinks
+}
+
+// defaultIncludes are walked when the config has no include patterns.
+var defaultIncludes = []string{
+	"**/*.py",
+	"**/*.js",
+	"**/*.ts",
+	"**/*.tsx",
+	"**/*.jsx",
+	"**/*.go",
 }
 
 // NewWalker creates a new file walker with the given include and exclude patterns.
 // If no includes are specified, defaults to common code file extensions.
 func NewWalker(includes, excludes []string) *Walker {
 	if len(includes) == 0 {
-		includes = []string{
-			"**/*.py",
-			"**/*.js",
-			"**/*.ts",
-			"**/*.tsx",
-			"**/*.jsx",
-			"**/*.go",
-		}
+		includes = defaultIncludes
 	}
 
 	// Default excludes for common non-source directories
@@ -46,53 +83,345 @@ func NewWalker(includes, excludes []string) *Walker {
 	excludes = append(defaultExcludes, excludes...)
 
 	return &Walker{
-		includes: includes,
-		excludes: excludes,
+		includes:         includes,
+		excludes:         excludes,
+		maxFileBytes:     DefaultMaxFileKB * 1024,
+		maxAvgLineLength: DefaultMaxAvgLineLength,
+	}
+}
+
+// NewRepoWalker creates a walker with the repo's patterns, roots, and
+// content limits.
+func NewRepoWalker(repoCfg *config.RepoConfig) *Walker {
+	w := NewWalker(repoCfg.Include, repoCfg.Exclude)
+	w.SetLimits(repoCfg.MaxFileKB, repoCfg.MaxAvgLineLength)
+	w.roots = newWalkRoots(repoCfg)
+	w.SetFollowSymlinks(repoCfg.FollowSymlinks)
+	return w
+}
+
+// SetLimits sets the largest file size in KB and the average line length
+// above which a file counts as minified. Zero keeps the default and a
+// negative value disables the check. Files containing a NUL byte in their
+// first 8000 bytes are always skipped as binary.
+func (w *Walker) SetLimits(maxFileKB, maxAvgLineLength int) {
+	switch {
+	case maxFileKB < 0:
+		w.maxFileBytes = 0
+	case maxFileKB > 0:
+		w.maxFileBytes = int64(maxFileKB) * 1024
 	}
+	switch {
+	case maxAvgLineLength < 0:
+		w.maxAvgLineLength = 0
+	case maxAvgLineLength > 0:
+		w.maxAvgLineLength = maxAvgLineLength
+	}
+}
+
+// SetFollowSymlinks makes Walk enter symlinked directories and pass
+// symlinked files, under the link's path. Off by default: links are skipped.
+func (w *Walker) SetFollowSymlinks(follow bool) {
+	w.followSymlinks = follow
+}
+
+// SetModule restricts walking to one module subtree, named by its dotted
+// module path ("fisio.imports"). Directories outside it are not entered.
+func (w *Walker) SetModule(module string) {
+	w.module = module
+}
+
+// Stats returns what the last Walk skipped.
+func (w *Walker) Stats() WalkStats {
+	return w.stats
 }
 
 // Walk traverses the directory tree rooted at root, calling fn for each file
 // that matches the include patterns and does not match the exclude patterns.
+// Files and directories ignored by a .gitignore or .indexignore (at the root
+// or in any directory above them) are skipped too.
+//
+// Symlinks are skipped unless SetFollowSymlinks is on. Followed links are
+// resolved after the rest of the tree, so a file reachable without a link is
+// always passed under that path, and a link to an already walked directory
+// or file (a cycle, or a second route to it) is skipped.
 func (w *Walker) Walk(root string, fn func(path string) error) error {
-	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+	w.stats = WalkStats{}
+	s := &walkState{root: root, ignores: &ignoreMatcher{}, fn: fn}
+	s.ignores.loadFile(filepath.Join(root, ".git", "info", "exclude"), "") // Excludes local to this clone
+
+	realRoot := root
+	if w.followSymlinks {
+		s.dirs = make(map[string]bool)
+		s.files = make(map[string]bool)
+		var err error
+		if realRoot, err = filepath.EvalSymlinks(root); err != nil {
+			return err
+		}
+	}
+	if err := w.walkTree(s, root, realRoot, ""); err != nil {
+		return err
+	}
+	for i := 0; i < len(s.links); i++ { // Following a link can queue more
+		if err := w.followLink(s, s.links[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkState is the bookkeeping of one Walk.
+type walkState struct {
+	root    string
+	ignores *ignoreMatcher
+	fn      func(path string) error
+	links   []string        // Repo-relative symlinks waiting to be followed
+	dirs    map[string]bool // Real paths of walked directories; nil when not following
+	files   map[string]bool // Real paths of files passed to fn; nil when not following
+}
+
+// walkTree walks the real directory dir, whose files appear under the
+// repo-relative relBase ("" for the repo itself). realDir is dir with
+// symlinks resolved.
+func (w *Walker) walkTree(s *walkState, dir, realDir, relBase string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		relPath, err := filepath.Rel(root, path)
+		rel, err := filepath.Rel(dir, path)
 		if err != nil {
 			return err
 		}
 
 		// Normalize to forward slashes for pattern matching
-		relPath = filepath.ToSlash(relPath)
+		relPath := filepath.ToSlash(rel)
+		if relBase != "" {
+			relPath = relBase + "/" + relPath
+			if rel == "." {
+				relPath = relBase
+			}
+		}
 
 		if d.IsDir() {
-			// Check if directory should be excluded
-			if w.shouldExcludeDir(relPath) {
+			// The top was checked by Walk or followLink
+			if rel != "." && w.skipDir(s, relPath) {
 				return filepath.SkipDir
 			}
+			if relPath == "." {
+				s.ignores.load(s.root, "")
+			} else {
+				s.ignores.load(s.root, relPath)
+			}
+			if s.dirs != nil {
+				s.dirs[filepath.Join(realDir, rel)] = true
+			}
 			return nil
 		}
 
-		// Check excludes first
-		if w.isExcluded(relPath) {
+		if d.Type()&fs.ModeSymlink != 0 {
+			if w.isExcluded(relPath) || s.ignores.ignored(relPath, false) {
+				return nil
+			}
+			if !w.followSymlinks {
+				w.stats.Symlinks++
+				return nil
+			}
+			s.links = append(s.links, relPath)
 			return nil
 		}
+		return w.visitFile(s, relPath, filepath.Join(realDir, rel), d)
+	})
+}
 
-		// Check includes
-		if w.isIncluded(relPath) {
-			return fn(path)
-		}
+// followLink walks a symlinked directory or visits a symlinked file under
+// the link's path. Broken links and links to walked paths count as skipped.
+func (w *Walker) followLink(s *walkState, relPath string) error {
+	target, err := filepath.EvalSymlinks(filepath.Join(s.root, filepath.FromSlash(relPath)))
+	if err != nil {
+		w.stats.Symlinks++
+		return nil
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		w.stats.Symlinks++
+		return nil
+	}
 
+	if !info.IsDir() {
+		return w.visitFile(s, relPath, target, fs.FileInfoToDirEntry(info))
+	}
+	if s.dirs[target] {
+		w.stats.Symlinks++
 		return nil
-	})
+	}
+	if w.skipDir(s, relPath) {
+		return nil
+	}
+	return w.walkTree(s, target, target, relPath)
+}
+
+// skipDir applies the exclude, ignore, root, and module checks to a
+// directory.
+func (w *Walker) skipDir(s *walkState, relPath string) bool {
+	// Check if directory should be excluded
+	if w.shouldExcludeDir(relPath) || s.ignores.ignored(relPath, true) {
+		return true
+	}
+	if len(w.roots) > 0 && !w.rootAllowsDir(relPath) {
+		return true
+	}
+	return w.module != "" && !dirOverlapsModule(relPath, w.module, w.roots)
+}
+
+// visitFile passes a file to fn if it passes every check. realPath is the
+// file with symlinks resolved, used to skip files already passed.
+func (w *Walker) visitFile(s *walkState, relPath, realPath string, d os.DirEntry) error {
+	if !w.acceptPath(s, relPath) {
+		return nil
+	}
+
+	if s.files != nil {
+		if s.files[realPath] {
+			w.stats.Symlinks++
+			return nil
+		}
+		s.files[realPath] = true
+	}
+	path := filepath.Join(s.root, filepath.FromSlash(relPath))
+	if !w.acceptContent(path, d) {
+		return nil
+	}
+	return s.fn(path)
+}
+
+// acceptPath applies the exclude, ignore, include, root, and module checks
+// to a file's path.
+func (w *Walker) acceptPath(s *walkState, relPath string) bool {
+	// Check excludes first
+	if w.isExcluded(relPath) || s.ignores.ignored(relPath, false) {
+		return false
+	}
+
+	// Check includes, against the file's root in a monorepo
+	if len(w.roots) > 0 {
+		root := findRoot(w.roots, relPath)
+		if root == nil || !root.accepts(relPath) {
+			return false
+		}
+	} else if !w.isIncluded(relPath) {
+		return false
+	}
+	return w.module == "" || inModule(relPath, w.module, w.roots)
+}
+
+// Accepts reports whether Walk from root would pass the repo-relative file
+// relPath, for reindexing single files as they change. A file that no
+// longer exists is judged by its path alone, so its chunks can be removed.
+// Links are judged by their target, without duplicate detection.
+func (w *Walker) Accepts(root, relPath string) bool {
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+	s := &walkState{root: root, ignores: &ignoreMatcher{}}
+	s.ignores.loadFile(filepath.Join(root, ".git", "info", "exclude"), "")
+	s.ignores.load(root, "")
+	parts := strings.Split(relPath, "/")
+	for i := 1; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		if w.skipDir(s, dir) {
+			return false
+		}
+		s.ignores.load(root, dir)
+	}
+	if !w.acceptPath(s, relPath) {
+		return false
+	}
+
+	path := filepath.Join(root, filepath.FromSlash(relPath))
+	info, err := os.Lstat(path)
+	if err != nil {
+		return errors.Is(err, fs.ErrNotExist)
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if !w.followSymlinks {
+			return false
+		}
+		if info, err = os.Stat(path); err != nil {
+			return false
+		}
+	}
+	return info.Mode().IsRegular() && w.acceptContent(path, fs.FileInfoToDirEntry(info))
+}
+
+// ExcludesDir reports whether Walk skips the repo-relative directory by
+// its exclude patterns (not by ignore files or roots).
+func (w *Walker) ExcludesDir(relPath string) bool {
+	return w.shouldExcludeDir(filepath.ToSlash(relPath))
+}
+
+// acceptContent applies the size, binary, and minified checks, counting
+// what it rejects. Unreadable files are passed through so indexing reports
+// the error.
+func (w *Walker) acceptContent(path string, d os.DirEntry) bool {
+	if w.maxFileBytes > 0 {
+		if info, err := d.Info(); err == nil && info.Size() > w.maxFileBytes {
+			w.stats.TooLarge++
+			return false
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+	head := make([]byte, sniffBytes)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+
+	if bytes.IndexByte(head, 0) >= 0 {
+		w.stats.Binary++
+		return false
+	}
+	if w.maxAvgLineLength > 0 && isMinified(head, w.maxAvgLineLength) {
+		w.stats.Minified++
+		return false
+	}
+	return true
+}
+
+// isMinified reports whether the sample's average line is longer than
+// limit. Samples shorter than one such line are too small to judge.
+func isMinified(sample []byte, limit int) bool {
+	if len(sample) <= limit {
+		return false
+	}
+	lines := bytes.Count(sample, []byte("\n")) + 1
+	return len(sample)/lines > limit
+}
+
+// rootAllowsDir reports whether a directory leads to or lies in a root and
+// is not excluded by it.
+func (w *Walker) rootAllowsDir(relPath string) bool {
+	if aboveRoot(w.roots, relPath) {
+		return true
+	}
+	root := findRoot(w.roots, relPath)
+	if root == nil {
+		return false
+	}
+	rel := root.below(relPath)
+	return rel == "" || !excludesDir(root.excludes, rel)
 }
 
 func (w *Walker) shouldExcludeDir(relPath string) bool {
+	return excludesDir(w.excludes, relPath)
+}
+
+// excludesDir reports whether a directory matches an exclude pattern.
+func excludesDir(excludes []string, relPath string) bool {
 	// Check directory exclusion patterns (with trailing slash)
 	dirPath := relPath + "/"
-	for _, pattern := range w.excludes {
+	for _, pattern := range excludes {
 		matched, _ := doublestar.Match(pattern, dirPath)
 		if matched {
 			return true
@@ -107,17 +436,15 @@ func (w *Walker) shouldExcludeDir(relPath string) bool {
 }
 
 func (w *Walker) isExcluded(relPath string) bool {
-	for _, pattern := range w.excludes {
-		matched, _ := doublestar.Match(pattern, relPath)
-		if matched {
-			return true
-		}
-	}
-	return false
+	return matchesAny(w.excludes, relPath)
 }
 
 func (w *Walker) isIncluded(relPath string) bool {
-	for _, pattern := range w.includes {
+	return matchesAny(w.includes, relPath)
+}
+
+func matchesAny(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
 		matched, _ := doublestar.Match(pattern, relPath)
 		if matched {
 			return true
@@ -0,0 +1,40 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/store"
+)
+
+// settleWorkspace clears the dirty tag of the repo's chunks whose files
+// were clean during a whole-repo run. Incremental runs skip files whose
+// content matches the stored hash, so a dirty file committed unchanged (or
+// reverted) keeps its chunks, tagged dirty, until settled here. A nil dirty
+// set (outside git) leaves the tags alone.
+func (idx *Indexer) settleWorkspace(ctx context.Context, collection, repo string, dirty map[string]bool, result *IndexResult) {
+	if dirty == nil {
+		return
+	}
+	stale := map[string]interface{}{
+		"repo":      repo,
+		"workspace": chunk.WorkspaceDirty,
+	}
+	if len(dirty) > 0 {
+		stale[store.MustNot] = map[string]interface{}{"file_path": slices.Sorted(maps.Keys(dirty))}
+	}
+	if err := idx.store.SetPayloadByFilter(ctx, collection, stale, map[string]interface{}{"workspace": ""}); err != nil {
+		result.addError(PhaseStore, fmt.Errorf("clear workspace of committed files: %w", err))
+	}
+}
+
+// workspaceOf is the Workspace of chunks of path given the run's dirty set.
+func workspaceOf(dirty map[string]bool, path string) string {
+	if dirty[path] {
+		return chunk.WorkspaceDirty
+	}
+	return ""
+}
@@ -0,0 +1,265 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Audit outcomes.
+const (
+	AuditOK        = "ok"         // The tool returned a result
+	AuditToolError = "tool_error" // The tool returned an error result
+	AuditRejected  = "rejected"   // The server refused or cancelled the call (JSON-RPC error)
+)
+
+// AuditEntry records one tools/call: who called which tool, and how it went.
+// Arguments are never logged, only their keyed hash and names, as queries
+// and snippets may hold personal data or secrets.
+type AuditEntry struct {
+	Time          time.Time `json:"ts"`
+	Session       string    `json:"session,omitempty"` // SHA-256 prefix of the session ID, as in the metrics log
+	Subject       string    `json:"subject,omitempty"` // Bearer token's caller; empty on stdio
+	Client        string    `json:"client,omitempty"`  // clientInfo sent in initialize
+	ClientVersion string    `json:"client_version,omitempty"`
+	Tool          string    `json:"tool"`
+	Repo          string    `json:"repo,omitempty"` // The repo argument, if any
+	ArgsHash      string    `json:"args_hmac"`      // HMAC-SHA256 of the arguments' JSON, keys sorted
+	ArgKeys       []string  `json:"arg_keys"`       // Argument names, sorted
+	Outcome       string    `json:"outcome"`        // AuditOK, AuditToolError, or AuditRejected
+	ErrorCode     int       `json:"error_code,omitempty"`
+	ResultBytes   int       `json:"result_bytes"` // Of the result's JSON
+	DurationMs    int64     `json:"duration_ms"`
+}
+
+// AuditLog appends an AuditEntry per tool call to a JSONL file, dropping
+// entries older than its retention once an hour.
+type AuditLog struct {
+	path      string
+	retention time.Duration // 0 keeps every entry
+	key       []byte        // HMAC key of ArgsHash
+
+	mu     sync.Mutex
+	file   *os.File
+	pruned time.Time // Last retention pass
+}
+
+// NewAuditLog opens the audit log at path, creating it readable by the
+// owner only, and drops entries older than retention (0 keeps them all).
+// Arguments are hashed with the key in path + ".key", generated on first
+// use: a plain hash of a short query could be reversed by guessing.
+func NewAuditLog(path string, retention time.Duration) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create audit dir: %w", err)
+	}
+	key, err := loadAuditKey(path + ".key")
+	if err != nil {
+		return nil, err
+	}
+	a := &AuditLog{path: path, retention: retention, key: key}
+	if err := a.prune(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// loadAuditKey reads the hex HMAC key at path, creating a random one
+// readable by the owner only if there is none.
+func loadAuditKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generate audit key: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+			return nil, fmt.Errorf("write audit key: %w", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read audit key: %w", err)
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("audit key %s is not hex", path)
+	}
+	return key, nil
+}
+
+// argsHash returns the hex HMAC-SHA256 of args under the log's key.
+func (a *AuditLog) argsHash(args []byte) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write(args)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (a *AuditLog) open() error {
+	file, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	a.file = file
+	return nil
+}
+
+// Close closes the log file.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
+
+// Record appends e, first applying the retention if an hour passed since
+// it last was.
+func (a *AuditLog) Record(e AuditEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal audit entry: %w", err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.retention > 0 && time.Since(a.pruned) >= time.Hour {
+		if err := a.file.Close(); err != nil {
+			return fmt.Errorf("close audit log: %w", err)
+		}
+		pruneErr := a.prune(time.Now())
+		if err := a.open(); err != nil {
+			return err
+		}
+		if pruneErr != nil {
+			return pruneErr
+		}
+	}
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write audit log: %w", err)
+	}
+	return nil
+}
+
+// prune rewrites the log without entries older than the retention. Lines
+// without a readable time are kept. The log must not be open for writing.
+func (a *AuditLog) prune(now time.Time) error {
+	a.pruned = now
+	if a.retention <= 0 {
+		return nil
+	}
+	data, err := os.ReadFile(a.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+
+	cutoff := now.Add(-a.retention)
+	var kept bytes.Buffer
+	dropped := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry struct {
+			Time time.Time `json:"ts"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil && !entry.Time.IsZero() && entry.Time.Before(cutoff) {
+			dropped++
+			continue
+		}
+		kept.Write(scanner.Bytes())
+		kept.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+	if dropped == 0 {
+		return nil
+	}
+
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, kept.Bytes(), 0600); err != nil {
+		return fmt.Errorf("prune audit log: %w", err)
+	}
+	if err := os.Rename(tmp, a.path); err != nil {
+		return fmt.Errorf("prune audit log: %w", err)
+	}
+	return nil
+}
+
+// SetAudit records every tools/call, including rejected ones, in log.
+func (s *Server) SetAudit(log *AuditLog) {
+	s.audit = log
+}
+
+// auditCall records a tools/call request and its response, which started
+// at started. Other requests are not audited.
+func (s *Server) auditCall(ctx context.Context, req *Request, resp *Response, started time.Time) {
+	if s.audit == nil || req.Method != "tools/call" {
+		return
+	}
+	var params CallToolParams
+	_ = json.Unmarshal(req.Params, &params)
+
+	entry := AuditEntry{
+		Time:       started.UTC(),
+		Tool:       params.Name,
+		ArgKeys:    make([]string, 0, len(params.Arguments)),
+		Outcome:    AuditOK,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	// encoding/json sorts map keys, so equal arguments hash equally
+	args, _ := json.Marshal(params.Arguments)
+	entry.ArgsHash = s.audit.argsHash(args)
+	for key := range params.Arguments {
+		entry.ArgKeys = append(entry.ArgKeys, key)
+	}
+	sort.Strings(entry.ArgKeys)
+	entry.Repo, _ = params.Arguments["repo"].(string)
+
+	if session := SessionFromContext(ctx); session != nil {
+		sum := sha256.Sum256([]byte(session.ID))
+		entry.Session = hex.EncodeToString(sum[:8])
+		entry.Client, entry.ClientVersion = session.ClientInfo()
+	}
+	if info := TokenInfoFromContext(ctx); info != nil {
+		entry.Subject = info.Subject
+	}
+
+	if resp != nil {
+		switch result := resp.Result.(type) {
+		case *CallToolResult:
+			if result != nil && result.IsError {
+				entry.Outcome = AuditToolError
+			}
+		case CallToolResult:
+			if result.IsError {
+				entry.Outcome = AuditToolError
+			}
+		}
+		if resp.Error != nil {
+			entry.Outcome = AuditRejected
+			entry.ErrorCode = resp.Error.Code
+		} else if data, err := json.Marshal(resp.Result); err == nil {
+			entry.ResultBytes = len(data)
+		}
+	}
+
+	if err := s.audit.Record(entry); err != nil {
+		s.logger.Error("failed to write audit entry", "tool", entry.Tool, "error", err)
+	}
+}
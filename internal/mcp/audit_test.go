@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAudit(t *testing.T, path string) []AuditEntry {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entries []AuditEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var e AuditEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestServerAuditsToolCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	audit, err := NewAuditLog(path, 0)
+	require.NoError(t, err)
+	defer audit.Close()
+
+	server := NewServer("test", "0.0.0", &blockingHandler{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	server.SetLimits(Limits{MaxArgumentBytes: 200})
+	server.SetAudit(audit)
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"claude-code","version":"1.2.3"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_code","arguments":{"query":"jane.doe@example.com","repo":"r3"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"search_code","arguments":{"query":"` + strings.Repeat("x", 300) + `"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"ping"}`,
+	}, "\n") + "\n"
+	var out bytes.Buffer
+	require.NoError(t, server.Run(context.Background(), strings.NewReader(input), &out))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "jane.doe", "arguments are hashed, never logged")
+
+	entries := readAudit(t, path)
+	require.Len(t, entries, 2, "only tool calls are audited")
+	// The rejected call is audited as it is refused, the other once it returns
+	ok, rejected := entries[0], entries[1]
+	if ok.Outcome == AuditRejected {
+		ok, rejected = rejected, ok
+	}
+	assert.Equal(t, "search_code", ok.Tool)
+	assert.Equal(t, "r3", ok.Repo)
+	assert.Equal(t, []string{"query", "repo"}, ok.ArgKeys)
+	assert.Len(t, ok.ArgsHash, 64)
+	assert.Equal(t, AuditOK, ok.Outcome)
+	assert.Equal(t, "claude-code", ok.Client)
+	assert.Equal(t, "1.2.3", ok.ClientVersion)
+	assert.Len(t, ok.Session, 16)
+	assert.Positive(t, ok.ResultBytes)
+
+	assert.Equal(t, AuditRejected, rejected.Outcome)
+	assert.Equal(t, ErrCodeInvalidParams, rejected.ErrorCode)
+	assert.NotEqual(t, ok.ArgsHash, rejected.ArgsHash)
+	assert.Equal(t, ok.Session, rejected.Session)
+
+	keyInfo, err := os.Stat(path + ".key")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), keyInfo.Mode().Perm())
+	plain := sha256.Sum256([]byte(`{"query":"jane.doe@example.com","repo":"r3"}`))
+	assert.NotEqual(t, hex.EncodeToString(plain[:]), ok.ArgsHash, "arguments are hashed with a key")
+}
+
+func TestAuditKey(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewAuditLog(filepath.Join(dir, "audit.jsonl"), 0)
+	require.NoError(t, err)
+	defer first.Close()
+	reopened, err := NewAuditLog(filepath.Join(dir, "audit.jsonl"), 0)
+	require.NoError(t, err)
+	defer reopened.Close()
+	other, err := NewAuditLog(filepath.Join(dir, "other.jsonl"), 0)
+	require.NoError(t, err)
+	defer other.Close()
+
+	args := []byte(`{"query":"retry"}`)
+	assert.Equal(t, first.argsHash(args), reopened.argsHash(args), "the key is kept across restarts")
+	assert.NotEqual(t, first.argsHash(args), other.argsHash(args), "each log generates its own key")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.jsonl.key"), []byte("not hex"), 0600))
+	_, err = NewAuditLog(filepath.Join(dir, "bad.jsonl"), 0)
+	assert.Error(t, err)
+}
+
+func TestAuditLogRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	old := AuditEntry{Time: time.Now().Add(-48 * time.Hour), Tool: "old"}
+	recent := AuditEntry{Time: time.Now().Add(-time.Hour), Tool: "recent"}
+	var data []byte
+	for _, e := range []AuditEntry{old, recent} {
+		line, err := json.Marshal(e)
+		require.NoError(t, err)
+		data = append(append(data, line...), '\n')
+	}
+	data = append(data, "not json\n"...)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	audit, err := NewAuditLog(path, 24*time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, audit.Record(AuditEntry{Time: time.Now(), Tool: "new"}))
+	require.NoError(t, audit.Close())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content := string(raw)
+	assert.NotContains(t, content, `"tool":"old"`)
+	assert.Contains(t, content, `"tool":"recent"`)
+	assert.Contains(t, content, `"tool":"new"`)
+	assert.Contains(t, content, "not json", "unreadable lines are kept")
+}
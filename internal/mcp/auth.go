@@ -0,0 +1,360 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+)
+
+// ErrInvalidToken is returned by verifiers for unknown, expired, or revoked
+// tokens.
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenInfo identifies the caller behind a verified bearer token.
+type TokenInfo struct {
+	Subject string
+	Repos   []string            // Repos the token may read; nil allows every repo
+	Modules map[string][]string // Repo → the only top-level modules readable in it; absent repos are readable whole
+}
+
+// AllowsRepo reports whether the token may read repo. Restricted tokens
+// cannot search across all repos at once, so "" and "all" are refused.
+func (t *TokenInfo) AllowsRepo(repo string) bool {
+	if t == nil || t.Repos == nil {
+		return true
+	}
+	return slices.Contains(t.Repos, repo)
+}
+
+// AllowedModules returns the top-level modules (module_root) the token may
+// read in repo, or nil when the whole repo is readable.
+func (t *TokenInfo) AllowedModules(repo string) []string {
+	if t == nil {
+		return nil
+	}
+	return t.Modules[repo]
+}
+
+// AllowsModule reports whether the token may read module in repo. Module
+// is a top-level module or one of its submodules ("fisio/imports" or
+// "fisio.imports" under "fisio").
+func (t *TokenInfo) AllowsModule(repo, module string) bool {
+	allowed := t.AllowedModules(repo)
+	if allowed == nil {
+		return true
+	}
+	for _, m := range allowed {
+		if module == m || strings.HasPrefix(module, m+"/") || strings.HasPrefix(module, m+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenVerifier checks a bearer token.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*TokenInfo, error)
+}
+
+// HashToken returns the hex SHA-256 of a token, the form static tokens are
+// configured in so the secrets themselves stay out of config files.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// StaticTokenVerifier accepts a fixed set of tokens, keyed by HashToken.
+type StaticTokenVerifier struct {
+	tokens map[string]TokenInfo
+}
+
+// NewStaticTokenVerifier creates a verifier for tokens keyed by their hex
+// SHA-256 hash.
+func NewStaticTokenVerifier(tokens map[string]TokenInfo) *StaticTokenVerifier {
+	normalized := make(map[string]TokenInfo, len(tokens))
+	for hash, info := range tokens {
+		normalized[strings.ToLower(hash)] = info
+	}
+	return &StaticTokenVerifier{tokens: normalized}
+}
+
+// Verify looks the token up by hash, so lookups never compare the secret.
+func (v *StaticTokenVerifier) Verify(ctx context.Context, token string) (*TokenInfo, error) {
+	info, ok := v.tokens[HashToken(token)]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return &info, nil
+}
+
+const (
+	// repoScopePrefix marks OAuth scopes granting a repo, e.g. "repo:fisio".
+	// "repo:*" grants every repo.
+	repoScopePrefix = "repo:"
+	// moduleScopePrefix marks OAuth scopes granting one top-level module of
+	// a repo, e.g. "module:fisio/imports". A repo scope for the same repo
+	// grants it whole.
+	moduleScopePrefix = "module:"
+
+	// maxIntrospectionCache bounds how long an introspection result is reused.
+	maxIntrospectionCache = 5 * time.Minute
+)
+
+// IntrospectionVerifier checks OAuth access tokens (e.g. issued through the
+// client-credentials grant) against an RFC 7662 introspection endpoint.
+// Repos come from "repo:<name>" scopes; tokens without one are rejected.
+type IntrospectionVerifier struct {
+	endpoint     string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedToken // Keyed by HashToken
+}
+
+type cachedToken struct {
+	info    TokenInfo
+	expires time.Time
+}
+
+// NewIntrospectionVerifier creates a verifier that authenticates to the
+// introspection endpoint with the given client credentials.
+func NewIntrospectionVerifier(endpoint, clientID, clientSecret string) *IntrospectionVerifier {
+	return &IntrospectionVerifier{
+		endpoint:     endpoint,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		cache:        make(map[string]cachedToken),
+	}
+}
+
+// Verify introspects the token, reusing results until the token expires or
+// maxIntrospectionCache passes.
+func (v *IntrospectionVerifier) Verify(ctx context.Context, token string) (*TokenInfo, error) {
+	key := HashToken(token)
+	now := time.Now()
+
+	v.mu.Lock()
+	cached, ok := v.cache[key]
+	v.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		info := cached.info
+		return &info, nil
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(v.clientID, v.clientSecret)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspect token: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Active   bool   `json:"active"`
+		Subject  string `json:"sub"`
+		ClientID string `json:"client_id"`
+		Scope    string `json:"scope"`
+		Exp      int64  `json:"exp"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode introspection response: %w", err)
+	}
+	if !result.Active {
+		return nil, ErrInvalidToken
+	}
+
+	info := TokenInfo{Subject: result.Subject}
+	if info.Subject == "" {
+		info.Subject = result.ClientID
+	}
+	var granted, all bool
+	whole := make(map[string]bool)
+	for _, scope := range strings.Fields(result.Scope) {
+		if grant, ok := strings.CutPrefix(scope, moduleScopePrefix); ok {
+			repo, module, ok := strings.Cut(grant, "/")
+			if !ok || repo == "" || module == "" {
+				continue
+			}
+			granted = true
+			if info.Modules == nil {
+				info.Modules = make(map[string][]string)
+			}
+			if !slices.Contains(info.Repos, repo) {
+				info.Repos = append(info.Repos, repo)
+			}
+			info.Modules[repo] = append(info.Modules[repo], module)
+			continue
+		}
+		repo, ok := strings.CutPrefix(scope, repoScopePrefix)
+		if !ok {
+			continue
+		}
+		granted = true
+		if repo == "*" {
+			all = true
+		} else {
+			whole[repo] = true
+			if !slices.Contains(info.Repos, repo) {
+				info.Repos = append(info.Repos, repo)
+			}
+		}
+	}
+	if !granted {
+		return nil, ErrInvalidToken
+	}
+	for repo := range whole {
+		delete(info.Modules, repo)
+	}
+	if all {
+		info.Repos = nil
+	}
+
+	expires := now.Add(maxIntrospectionCache)
+	if result.Exp > 0 && time.Unix(result.Exp, 0).Before(expires) {
+		expires = time.Unix(result.Exp, 0)
+	}
+	v.mu.Lock()
+	for k, c := range v.cache {
+		if now.After(c.expires) {
+			delete(v.cache, k)
+		}
+	}
+	v.cache[key] = cachedToken{info: info, expires: expires}
+	v.mu.Unlock()
+
+	return &info, nil
+}
+
+// ChainVerifier accepts a token if any verifier does, trying them in order.
+type ChainVerifier []TokenVerifier
+
+// Verify returns the first verifier's success. A backend failure is only
+// reported if no other verifier accepts the token.
+func (c ChainVerifier) Verify(ctx context.Context, token string) (*TokenInfo, error) {
+	var lastErr error = ErrInvalidToken
+	for _, v := range c {
+		info, err := v.Verify(ctx, token)
+		if err == nil {
+			return info, nil
+		}
+		if !errors.Is(err, ErrInvalidToken) {
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+// ErrNoAuthConfigured is returned by VerifierFromConfig when neither static
+// tokens nor an introspection endpoint are configured.
+var ErrNoAuthConfigured = errors.New("no tokens or introspection endpoint configured")
+
+// VerifierFromConfig chains the configured static tokens and introspection
+// endpoint. The introspection client secret is read from the environment.
+func VerifierFromConfig(cfg config.MCPHTTPConfig) (TokenVerifier, error) {
+	var chain ChainVerifier
+
+	if len(cfg.Tokens) > 0 {
+		tokens := make(map[string]TokenInfo, len(cfg.Tokens))
+		for _, t := range cfg.Tokens {
+			if len(t.SHA256) != 64 {
+				return nil, fmt.Errorf("token %q: sha256 must be a 64-character hex digest", t.Name)
+			}
+			info := TokenInfo{Subject: t.Name}
+			if len(t.Repos) > 0 {
+				info.Repos = t.Repos
+			}
+			if len(t.Modules) > 0 {
+				info.Modules = t.Modules
+			}
+			tokens[strings.ToLower(t.SHA256)] = info
+		}
+		chain = append(chain, NewStaticTokenVerifier(tokens))
+	}
+
+	if in := cfg.Introspection; in.URL != "" {
+		secret := os.Getenv(in.ClientSecretEnv)
+		if in.ClientID == "" || secret == "" {
+			return nil, fmt.Errorf("introspection needs client_id and a client secret in $%s", in.ClientSecretEnv)
+		}
+		chain = append(chain, NewIntrospectionVerifier(in.URL, in.ClientID, secret))
+	}
+
+	if len(chain) == 0 {
+		return nil, ErrNoAuthConfigured
+	}
+	return chain, nil
+}
+
+// RequireBearer rejects requests without a valid "Authorization: Bearer"
+// token and passes the verified TokenInfo to next through the context.
+func RequireBearer(verifier TokenVerifier, logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		token = strings.TrimSpace(token)
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="code-index-mcp"`)
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		info, err := verifier.Verify(r.Context(), token)
+		if errors.Is(err, ErrInvalidToken) {
+			logger.Warn("rejected bearer token", "remote", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="code-index-mcp", error="invalid_token"`)
+			http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			logger.Error("token verification failed", "error", err)
+			http.Error(w, "token verification unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithTokenInfo(r.Context(), info)))
+	})
+}
+
+type tokenInfoKey struct{}
+
+// WithTokenInfo returns a context carrying the caller's verified token.
+func WithTokenInfo(ctx context.Context, info *TokenInfo) context.Context {
+	return context.WithValue(ctx, tokenInfoKey{}, info)
+}
+
+// TokenInfoFromContext returns the caller's token, or nil for
+// unauthenticated transports such as stdio.
+func TokenInfoFromContext(ctx context.Context) *TokenInfo {
+	info, _ := ctx.Value(tokenInfoKey{}).(*TokenInfo)
+	return info
+}
+
+// RepoAllowed reports whether the request's caller may read repo. Callers
+// without a token (stdio) may read everything.
+func RepoAllowed(ctx context.Context, repo string) bool {
+	return TokenInfoFromContext(ctx).AllowsRepo(repo)
+}
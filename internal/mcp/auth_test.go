@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenInfoAllowsRepo(t *testing.T) {
+	var none *TokenInfo
+	assert.True(t, none.AllowsRepo("anything"), "no token is unrestricted")
+	assert.True(t, (&TokenInfo{}).AllowsRepo("all"), "nil repos allow everything")
+
+	scoped := &TokenInfo{Repos: []string{"fisio"}}
+	assert.True(t, scoped.AllowsRepo("fisio"))
+	assert.False(t, scoped.AllowsRepo("other"))
+	assert.False(t, scoped.AllowsRepo(""))
+	assert.False(t, scoped.AllowsRepo("all"))
+}
+
+func TestTokenInfoAllowsModule(t *testing.T) {
+	var none *TokenInfo
+	assert.True(t, none.AllowsModule("fisio", "core"))
+
+	scoped := &TokenInfo{Modules: map[string][]string{"fisio": {"imports"}}}
+	assert.True(t, scoped.AllowsModule("fisio", "imports"))
+	assert.True(t, scoped.AllowsModule("fisio", "imports/csv"), "submodules are included")
+	assert.True(t, scoped.AllowsModule("fisio", "imports.csv"))
+	assert.False(t, scoped.AllowsModule("fisio", "importsx"))
+	assert.False(t, scoped.AllowsModule("fisio", "core"))
+	assert.True(t, scoped.AllowsModule("docs", "anything"), "unlisted repos are readable whole")
+	assert.Nil(t, scoped.AllowedModules("docs"))
+}
+
+func TestStaticTokenVerifier(t *testing.T) {
+	v := NewStaticTokenVerifier(map[string]TokenInfo{
+		HashToken("secret"): {Subject: "ci", Repos: []string{"fisio"}},
+	})
+
+	info, err := v.Verify(context.Background(), "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", info.Subject)
+	assert.Equal(t, []string{"fisio"}, info.Repos)
+
+	_, err = v.Verify(context.Background(), "wrong")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestIntrospectionVerifier(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "indexer" || pass != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostForm.Get("token") {
+		case "scoped":
+			io.WriteString(w, `{"active":true,"client_id":"ci","scope":"read repo:fisio repo:docs"}`)
+		case "modules":
+			io.WriteString(w, `{"active":true,"sub":"contractor","scope":"module:fisio/imports module:fisio/export repo:docs module:docs/api"}`)
+		case "admin":
+			io.WriteString(w, `{"active":true,"sub":"ops","scope":"repo:*"}`)
+		case "unscoped":
+			io.WriteString(w, `{"active":true,"sub":"ops","scope":"read"}`)
+		default:
+			io.WriteString(w, `{"active":false}`)
+		}
+	}))
+	defer srv.Close()
+
+	v := NewIntrospectionVerifier(srv.URL, "indexer", "client-secret")
+	ctx := context.Background()
+
+	info, err := v.Verify(ctx, "scoped")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", info.Subject, "client_id when sub is absent")
+	assert.Equal(t, []string{"fisio", "docs"}, info.Repos)
+
+	_, err = v.Verify(ctx, "scoped")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "active tokens are cached")
+
+	info, err = v.Verify(ctx, "modules")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fisio", "docs"}, info.Repos, "module scopes grant their repo")
+	assert.Equal(t, map[string][]string{"fisio": {"imports", "export"}}, info.Modules, "repo scopes grant the whole repo")
+
+	info, err = v.Verify(ctx, "admin")
+	require.NoError(t, err)
+	assert.Nil(t, info.Repos, "repo:* allows every repo")
+
+	_, err = v.Verify(ctx, "unscoped")
+	assert.ErrorIs(t, err, ErrInvalidToken, "tokens need a repo scope")
+
+	_, err = v.Verify(ctx, "revoked")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	bad := NewIntrospectionVerifier(srv.URL, "indexer", "wrong")
+	_, err = bad.Verify(ctx, "scoped")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidToken), "endpoint failures are not invalid tokens")
+}
+
+func TestIntrospectionVerifierHonoursExpiry(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, `{"active":true,"sub":"ci","scope":"repo:*","exp":1}`)
+	}))
+	defer srv.Close()
+
+	v := NewIntrospectionVerifier(srv.URL, "indexer", "client-secret")
+	for i := 0; i < 2; i++ {
+		_, err := v.Verify(context.Background(), "expired")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls, "expired results are not reused")
+}
+
+type failingVerifier struct{}
+
+func (failingVerifier) Verify(ctx context.Context, token string) (*TokenInfo, error) {
+	return nil, errors.New("introspection endpoint down")
+}
+
+func TestChainVerifier(t *testing.T) {
+	static := NewStaticTokenVerifier(map[string]TokenInfo{HashToken("secret"): {Subject: "ci"}})
+
+	info, err := ChainVerifier{failingVerifier{}, static}.Verify(context.Background(), "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", info.Subject)
+
+	_, err = ChainVerifier{static}.Verify(context.Background(), "wrong")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = ChainVerifier{static, failingVerifier{}}.Verify(context.Background(), "wrong")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidToken), "backend failures surface")
+}
+
+func TestVerifierFromConfig(t *testing.T) {
+	_, err := VerifierFromConfig(config.MCPHTTPConfig{})
+	assert.ErrorIs(t, err, ErrNoAuthConfigured)
+
+	_, err = VerifierFromConfig(config.MCPHTTPConfig{Tokens: []config.MCPToken{{Name: "bad", SHA256: "abc"}}})
+	assert.ErrorContains(t, err, "64-character")
+
+	_, err = VerifierFromConfig(config.MCPHTTPConfig{Introspection: config.MCPIntrospectionConfig{
+		URL: "https://idp.example.com/introspect", ClientID: "indexer", ClientSecretEnv: "CODE_INDEX_TEST_UNSET_SECRET",
+	}})
+	assert.ErrorContains(t, err, "CODE_INDEX_TEST_UNSET_SECRET")
+
+	verifier, err := VerifierFromConfig(config.MCPHTTPConfig{Tokens: []config.MCPToken{
+		{Name: "dashboard", SHA256: HashToken("dash-token"), Repos: []string{"r3"}, Modules: map[string][]string{"r3": {"web"}}},
+		{Name: "admin", SHA256: HashToken("admin-token")},
+	}})
+	require.NoError(t, err)
+
+	info, err := verifier.Verify(context.Background(), "dash-token")
+	require.NoError(t, err)
+	assert.Equal(t, "dashboard", info.Subject)
+	assert.Equal(t, []string{"r3"}, info.Repos)
+	assert.Equal(t, map[string][]string{"r3": {"web"}}, info.Modules)
+
+	info, err = verifier.Verify(context.Background(), "admin-token")
+	require.NoError(t, err)
+	assert.Nil(t, info.Repos, "no repos configured means unrestricted")
+}
+
+func TestRequireBearer(t *testing.T) {
+	static := NewStaticTokenVerifier(map[string]TokenInfo{HashToken("secret"): {Subject: "ci", Repos: []string{"fisio"}}})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var seen *TokenInfo
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = TokenInfoFromContext(r.Context())
+		assert.True(t, RepoAllowed(r.Context(), "fisio"))
+		assert.False(t, RepoAllowed(r.Context(), "other"))
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"wrong scheme", "Basic c2VjcmV0", http.StatusUnauthorized},
+		{"invalid", "Bearer wrong", http.StatusUnauthorized},
+		{"valid", "Bearer secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			RequireBearer(static, logger, next).ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+	require.NotNil(t, seen)
+	assert.Equal(t, "ci", seen.Subject)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	RequireBearer(failingVerifier{}, logger, next).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
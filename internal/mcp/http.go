@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// SessionHeader carries the session ID issued by initialize.
+	SessionHeader = "Mcp-Session-Id"
+
+	// maxHTTPMessageBytes matches the stdio scanner's line limit.
+	maxHTTPMessageBytes = 1024 * 1024
+
+	// sessionIdleTimeout drops HTTP sessions a client stopped using.
+	sessionIdleTimeout = time.Hour
+)
+
+// httpTransport serves MCP over HTTP: each POST carries one JSON-RPC message
+// and gets its response as the body. Sessions start at initialize, which
+// returns their ID in the Mcp-Session-Id header, and are bound to the token
+// subject that created them.
+type httpTransport struct {
+	server *Server
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+type httpSession struct {
+	session  *Session
+	subject  string
+	lastUsed time.Time
+}
+
+// HTTPHandler returns an http.Handler serving the MCP protocol. Wrap it in
+// RequireBearer to authenticate callers and scope them to repos.
+func (s *Server) HTTPHandler() http.Handler {
+	return &httpTransport{
+		server:   s,
+		sessions: make(map[string]*httpSession),
+	}
+}
+
+func (t *httpTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r)
+	case http.MethodDelete:
+		if _, ok := t.lookupSession(w, r); ok {
+			t.mu.Lock()
+			delete(t.sessions, r.Header.Get(SessionHeader))
+			t.mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (t *httpTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	s := t.server
+
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHTTPMessageBytes)).Decode(&req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJSON(w, status, &Response{
+			JSONRPC: "2.0",
+			Error:   &Error{Code: ErrCodeParse, Message: "Parse error", Data: err.Error()},
+		})
+		return
+	}
+
+	if req.Method == "initialize" {
+		session := s.newSession()
+		response := s.handleRequest(WithSession(r.Context(), session), &req)
+		if response != nil && response.Error == nil {
+			t.addSession(session, subjectOf(r))
+			w.Header().Set(SessionHeader, session.ID)
+		}
+		writeJSON(w, http.StatusOK, response)
+		return
+	}
+
+	session, ok := t.lookupSession(w, r)
+	if !ok {
+		return
+	}
+	ctx := WithSession(r.Context(), session)
+
+	if req.ID == nil {
+		s.handleRequest(ctx, &req)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	started := time.Now()
+	release, rejected := s.admit(session, &req)
+	if rejected != nil {
+		s.auditCall(ctx, &req, rejected, started)
+		if data, ok := rejected.Error.Data.(map[string]interface{}); ok {
+			if ms, ok := data["retry_after_ms"].(int64); ok {
+				w.Header().Set("Retry-After", strconv.FormatInt((ms+999)/1000, 10))
+			}
+		}
+		writeJSON(w, http.StatusOK, rejected)
+		return
+	}
+
+	response, answer := s.execute(ctx, &req, release)
+	s.auditCall(ctx, &req, response, started)
+	if !answer {
+		w.WriteHeader(http.StatusAccepted) // Cancelled by notifications/cancelled; no JSON-RPC response
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// addSession registers a new session, dropping idle ones.
+func (t *httpTransport) addSession(session *Session, subject string) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, hs := range t.sessions {
+		if now.Sub(hs.lastUsed) > sessionIdleTimeout {
+			delete(t.sessions, id)
+		}
+	}
+	t.sessions[session.ID] = &httpSession{session: session, subject: subject, lastUsed: now}
+}
+
+// lookupSession finds the request's session, writing an error response if it
+// is missing, unknown, or belongs to another token subject.
+func (t *httpTransport) lookupSession(w http.ResponseWriter, r *http.Request) (*Session, bool) {
+	id := r.Header.Get(SessionHeader)
+	if id == "" {
+		http.Error(w, "missing "+SessionHeader+" header; call initialize first", http.StatusBadRequest)
+		return nil, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	hs, ok := t.sessions[id]
+	if !ok {
+		http.Error(w, "unknown or expired session", http.StatusNotFound)
+		return nil, false
+	}
+	if hs.subject != subjectOf(r) {
+		http.Error(w, "session belongs to another caller", http.StatusForbidden)
+		return nil, false
+	}
+	hs.lastUsed = time.Now()
+	return hs.session, true
+}
+
+func subjectOf(r *http.Request) string {
+	if info := TokenInfoFromContext(r.Context()); info != nil {
+		return info.Subject
+	}
+	return ""
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type httpHarness struct {
+	t   *testing.T
+	url string
+}
+
+func startHTTPServer(t *testing.T, handler Handler, limits Limits) *httpHarness {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer("test", "0.0.0", handler, logger)
+	server.SetLimits(limits)
+
+	verifier := NewStaticTokenVerifier(map[string]TokenInfo{
+		HashToken("alice-token"): {Subject: "alice"},
+		HashToken("bob-token"):   {Subject: "bob", Repos: []string{"demo"}},
+	})
+	srv := httptest.NewServer(RequireBearer(verifier, logger, server.HTTPHandler()))
+	t.Cleanup(srv.Close)
+	return &httpHarness{t: t, url: srv.URL}
+}
+
+func (h *httpHarness) post(token, session string, msg map[string]interface{}) (*http.Response, map[string]interface{}) {
+	h.t.Helper()
+	msg["jsonrpc"] = "2.0"
+	data, err := json.Marshal(msg)
+	require.NoError(h.t, err)
+
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(data))
+	require.NoError(h.t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if session != "" {
+		req.Header.Set(SessionHeader, session)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(h.t, err)
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	if resp.Header.Get("Content-Type") == "application/json" {
+		require.NoError(h.t, json.NewDecoder(resp.Body).Decode(&body))
+	}
+	return resp, body
+}
+
+func (h *httpHarness) initialize(token string) string {
+	h.t.Helper()
+	resp, body := h.post(token, "", map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{"protocolVersion": LatestProtocolVersion}})
+	require.Equal(h.t, http.StatusOK, resp.StatusCode)
+	require.Nil(h.t, body["error"])
+	session := resp.Header.Get(SessionHeader)
+	require.NotEmpty(h.t, session)
+	return session
+}
+
+func TestHTTPTransportSession(t *testing.T) {
+	h := startHTTPServer(t, &sessionHandler{}, Limits{})
+	session := h.initialize("alice-token")
+
+	resp, body := h.post("alice-token", session, map[string]interface{}{"id": 2, "method": "tools/call", "params": map[string]interface{}{"name": "whoami"}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	text := body["result"].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	assert.Contains(t, text, session, "request runs in its session")
+
+	resp, _ = h.post("alice-token", "", map[string]interface{}{"id": 3, "method": "ping"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "session header required after initialize")
+
+	resp, _ = h.post("alice-token", "unknown", map[string]interface{}{"id": 4, "method": "ping"})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = h.post("bob-token", session, map[string]interface{}{"id": 5, "method": "ping"})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "sessions are bound to their token subject")
+
+	resp, _ = h.post("alice-token", session, map[string]interface{}{"method": "notifications/initialized"})
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodDelete, h.url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer alice-token")
+	req.Header.Set(SessionHeader, session)
+	del, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	del.Body.Close()
+	assert.Equal(t, http.StatusNoContent, del.StatusCode)
+
+	resp, _ = h.post("alice-token", session, map[string]interface{}{"id": 6, "method": "ping"})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "deleted sessions are gone")
+}
+
+func TestHTTPTransportRequiresToken(t *testing.T) {
+	h := startHTTPServer(t, &sessionHandler{}, Limits{})
+
+	resp, _ := h.post("nope", "", map[string]interface{}{"id": 1, "method": "initialize"})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestHTTPTransportRateLimit(t *testing.T) {
+	h := startHTTPServer(t, &sessionHandler{}, Limits{RequestsPerMinute: 1, Burst: 1})
+	session := h.initialize("alice-token")
+
+	_, body := h.post("alice-token", session, map[string]interface{}{"id": 2, "method": "tools/call", "params": map[string]interface{}{"name": "a"}})
+	assert.Nil(t, body["error"])
+
+	resp, body := h.post("alice-token", session, map[string]interface{}{"id": 3, "method": "tools/call", "params": map[string]interface{}{"name": "a"}})
+	assert.Equal(t, float64(ErrCodeServerOverloaded), errorCode(t, body))
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	other := h.initialize("alice-token")
+	_, body = h.post("alice-token", other, map[string]interface{}{"id": 4, "method": "tools/call", "params": map[string]interface{}{"name": "a"}})
+	assert.Nil(t, body["error"], "limits are per session")
+}
@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limits guards the server against clients that flood it with expensive
+// requests. Zero values disable the corresponding check.
+type Limits struct {
+	RequestsPerMinute int // Sustained tools/call + resources/read rate per session
+	Burst             int // Requests allowed at once above the rate (default: RequestsPerMinute)
+	MaxArgumentBytes  int // Largest accepted params payload for limited methods
+	MaxConcurrent     int // Limited requests running at once across all sessions
+}
+
+// limitedMethods are the requests that can reach embedding or storage
+// backends. Cheap protocol traffic (ping, tools/list, ...) is never limited.
+var limitedMethods = map[string]bool{
+	"tools/call":     true,
+	"resources/read": true,
+}
+
+// tokenBucket is a rate limiter refilled continuously at rate tokens/second.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(perMinute, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = perMinute
+	}
+	return &tokenBucket{
+		rate:   float64(perMinute) / 60,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// take consumes a token, or reports how long until one is available.
+func (b *tokenBucket) take() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	return false, wait
+}
+
+// admit applies the server's limits to a request before it is dispatched.
+// It returns the error response for a rejected request, or a release func
+// to call once an admitted request finishes.
+func (s *Server) admit(session *Session, req *Request) (release func(), rejected *Response) {
+	release = func() {}
+	if !limitedMethods[req.Method] {
+		return release, nil
+	}
+
+	if limit := s.limits.MaxArgumentBytes; limit > 0 && len(req.Params) > limit {
+		s.logger.Warn("request params too large", "method", req.Method, "size", len(req.Params), "max", limit)
+		return release, &Response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &Error{
+				Code:    ErrCodeInvalidParams,
+				Message: fmt.Sprintf("params too large: %d bytes (max %d)", len(req.Params), limit),
+				Data: map[string]interface{}{
+					"size": len(req.Params),
+					"max":  limit,
+				},
+			},
+		}
+	}
+
+	if session.limiter != nil {
+		if ok, wait := session.limiter.take(); !ok {
+			s.logger.Warn("rate limit exceeded", "session", session.ID, "method", req.Method)
+			return release, overloaded(req.ID, "rate_limit", wait)
+		}
+	}
+
+	if s.slots != nil {
+		select {
+		case s.slots <- struct{}{}:
+			return func() { <-s.slots }, nil
+		default:
+			s.logger.Warn("too many concurrent requests", "method", req.Method, "max", cap(s.slots))
+			return release, overloaded(req.ID, "concurrency", 0)
+		}
+	}
+
+	return release, nil
+}
+
+func overloaded(id interface{}, reason string, retryAfter time.Duration) *Response {
+	data := map[string]interface{}{"reason": reason}
+	if retryAfter > 0 {
+		data["retry_after_ms"] = retryAfter.Milliseconds() + 1
+	}
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error: &Error{
+			Code:    ErrCodeServerOverloaded,
+			Message: "Server overloaded, retry later",
+			Data:    data,
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(60, 2)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	ok, _ := b.take()
+	assert.True(t, ok)
+	ok, _ = b.take()
+	assert.True(t, ok)
+
+	ok, wait := b.take()
+	assert.False(t, ok, "burst exhausted")
+	assert.Equal(t, time.Second, wait)
+
+	now = now.Add(time.Second)
+	ok, _ = b.take()
+	assert.True(t, ok, "refilled at one token per second")
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		ok, _ = b.take()
+		assert.True(t, ok)
+	}
+	ok, _ = b.take()
+	assert.False(t, ok, "refill is capped at burst")
+}
+
+func errorCode(t *testing.T, resp map[string]interface{}) float64 {
+	t.Helper()
+	errObj, ok := resp["error"].(map[string]interface{})
+	require.True(t, ok, "expected an error response, got %v", resp)
+	return errObj["code"].(float64)
+}
+
+func TestServerRateLimit(t *testing.T) {
+	h := startServerWithLimits(t, &blockingHandler{}, Limits{RequestsPerMinute: 1, Burst: 1})
+
+	h.send(map[string]interface{}{"id": 1, "method": "tools/call", "params": map[string]interface{}{"name": "a"}})
+	assert.Nil(t, h.read()["error"])
+
+	h.send(map[string]interface{}{"id": 2, "method": "tools/call", "params": map[string]interface{}{"name": "a"}})
+	resp := h.read()
+	assert.Equal(t, float64(ErrCodeServerOverloaded), errorCode(t, resp))
+	data := resp["error"].(map[string]interface{})["data"].(map[string]interface{})
+	assert.Equal(t, "rate_limit", data["reason"])
+	assert.Greater(t, data["retry_after_ms"], float64(0))
+
+	h.send(map[string]interface{}{"id": 3, "method": "ping"})
+	assert.Nil(t, h.read()["error"], "cheap methods are not limited")
+}
+
+func TestServerMaxArgumentBytes(t *testing.T) {
+	h := startServerWithLimits(t, &blockingHandler{}, Limits{MaxArgumentBytes: 100})
+
+	h.send(map[string]interface{}{"id": 1, "method": "tools/call", "params": map[string]interface{}{
+		"name":      "a",
+		"arguments": map[string]interface{}{"query": strings.Repeat("x", 200)},
+	}})
+	assert.Equal(t, float64(ErrCodeInvalidParams), errorCode(t, h.read()))
+
+	h.send(map[string]interface{}{"id": 2, "method": "tools/call", "params": map[string]interface{}{"name": "a"}})
+	assert.Nil(t, h.read()["error"])
+}
+
+func TestServerMaxConcurrent(t *testing.T) {
+	handler := &blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+	h := startServerWithLimits(t, handler, Limits{MaxConcurrent: 1})
+
+	h.send(map[string]interface{}{"id": 1, "method": "tools/call", "params": map[string]interface{}{"name": "block"}})
+	<-handler.started
+
+	h.send(map[string]interface{}{"id": 2, "method": "tools/call", "params": map[string]interface{}{"name": "a"}})
+	resp := h.read()
+	assert.Equal(t, float64(2), resp["id"])
+	assert.Equal(t, float64(ErrCodeServerOverloaded), errorCode(t, resp))
+
+	close(handler.release)
+	assert.Equal(t, float64(1), h.read()["id"])
+
+	h.send(map[string]interface{}{"id": 3, "method": "tools/call", "params": map[string]interface{}{"name": "a"}})
+	assert.Nil(t, h.read()["error"], "slot released after the call finished")
+}
@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Handler defines the interface for MCP request handlers.
@@ -35,23 +38,55 @@ type Server struct {
 
 	reader io.Reader
 	writer io.Writer
-	mu     sync.Mutex
+	mu     sync.Mutex // Serializes response writes
+
+	inflightMu sync.Mutex
+	inflight   map[string]context.CancelCauseFunc // Cancels running requests by ID
+	wg         sync.WaitGroup
+
+	limits Limits
+	slots  chan struct{} // Concurrency slots for limited requests, nil when unlimited
+	audit  *AuditLog     // Tool call audit trail, nil when disabled
 }
 
 // NewServer creates a new MCP server.
 func NewServer(name, version string, handler Handler, logger *slog.Logger) *Server {
 	return &Server{
-		name:    name,
-		version: version,
-		handler: handler,
-		logger:  logger,
+		name:     name,
+		version:  version,
+		handler:  handler,
+		logger:   logger,
+		inflight: make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// SetLimits configures rate, size, and concurrency limits. Call it before Run.
+func (s *Server) SetLimits(limits Limits) {
+	s.limits = limits
+	s.slots = nil
+	if limits.MaxConcurrent > 0 {
+		s.slots = make(chan struct{}, limits.MaxConcurrent)
 	}
 }
 
-// Run starts the server, reading from stdin and writing to stdout.
+// newSession creates a client session subject to the server's limits.
+func (s *Server) newSession() *Session {
+	session := NewSession()
+	if s.limits.RequestsPerMinute > 0 {
+		session.limiter = newTokenBucket(s.limits.RequestsPerMinute, s.limits.Burst)
+	}
+	return session
+}
+
+// Run starts the server, reading from stdin and writing to stdout. Requests
+// other than initialize and notifications run concurrently, so a slow tool
+// call does not block pings or other calls. Run waits for in-flight requests
+// before returning.
 func (s *Server) Run(ctx context.Context, reader io.Reader, writer io.Writer) error {
 	s.reader = reader
 	s.writer = writer
+	ctx = WithSession(ctx, s.newSession())
+	defer s.wg.Wait()
 
 	scanner := bufio.NewScanner(reader)
 	// Increase buffer size for large messages
@@ -81,10 +116,7 @@ func (s *Server) Run(ctx context.Context, reader io.Reader, writer io.Writer) er
 			continue
 		}
 
-		response := s.handleRequest(ctx, &req)
-		if response != nil {
-			s.sendResponse(response)
-		}
+		s.dispatch(ctx, &req)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -95,20 +127,137 @@ func (s *Server) Run(ctx context.Context, reader io.Reader, writer io.Writer) er
 	return nil
 }
 
+// dispatch handles a request. Notifications and initialize are handled
+// inline since later requests depend on them; everything else is checked
+// against the server's limits and runs in its own goroutine with a context
+// that $/cancelRequest can cancel.
+func (s *Server) dispatch(ctx context.Context, req *Request) {
+	if req.ID == nil || req.Method == "initialize" {
+		if response := s.handleRequest(ctx, req); response != nil && req.ID != nil {
+			s.sendResponse(response)
+		}
+		return
+	}
+
+	started := time.Now()
+	release, rejected := s.admit(SessionFromContext(ctx), req)
+	if rejected != nil {
+		s.auditCall(ctx, req, rejected, started)
+		s.sendResponse(rejected)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		response, answer := s.execute(ctx, req, release)
+		s.auditCall(ctx, req, response, started)
+		if response != nil && answer {
+			s.sendResponse(response)
+		}
+	}()
+}
+
+// errCancelNotified is the cancel cause of requests cancelled by MCP's
+// notifications/cancelled, which must not be answered.
+var errCancelNotified = errors.New("cancelled by notifications/cancelled")
+
+// execute runs an admitted request with a context that $/cancelRequest can
+// cancel, calling release once the handler returns. A request cancelled by
+// the client gets error -32800, which answer is false for when MCP's
+// notifications/cancelled cancelled it: the client expects no response, and
+// the error is only audited.
+func (s *Server) execute(ctx context.Context, req *Request, release func()) (response *Response, answer bool) {
+	key := requestKey(SessionFromContext(ctx), req.ID)
+	reqCtx, cancel := context.WithCancelCause(ctx)
+	s.inflightMu.Lock()
+	s.inflight[key] = cancel
+	s.inflightMu.Unlock()
+
+	defer func() {
+		s.inflightMu.Lock()
+		delete(s.inflight, key)
+		s.inflightMu.Unlock()
+		cancel(nil)
+	}()
+
+	response = s.handleRequest(reqCtx, req)
+	release() // Before responding, so the client's next call finds the slot free
+	if reqCtx.Err() != nil && ctx.Err() == nil {
+		// Cancelled by the client; the result is no longer wanted
+		response = &Response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &Error{
+				Code:    ErrCodeRequestCancelled,
+				Message: "Request cancelled",
+			},
+		}
+		return response, !errors.Is(context.Cause(reqCtx), errCancelNotified)
+	}
+	return response, true
+}
+
+// cancelRequest cancels an in-flight request. Unknown or finished IDs are
+// ignored, as cancellation races with completion.
+func (s *Server) cancelRequest(ctx context.Context, req *Request) {
+	var params CancelParams
+	if req.Params != nil {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.logger.Warn("failed to parse cancel params", "error", err)
+			return
+		}
+	}
+	id := params.ID
+	if id == nil {
+		id = params.RequestID
+	}
+	if id == nil {
+		return
+	}
+
+	s.inflightMu.Lock()
+	cancel, ok := s.inflight[requestKey(SessionFromContext(ctx), id)]
+	s.inflightMu.Unlock()
+
+	if ok {
+		s.logger.Info("cancelling request", "id", id, "reason", params.Reason)
+		var cause error
+		if req.Method == "notifications/cancelled" {
+			cause = errCancelNotified
+		}
+		cancel(cause)
+	}
+}
+
+// requestKey normalizes a JSON-RPC ID (string or number) for map lookup.
+// IDs are only unique per client, so the key includes the session.
+func requestKey(session *Session, id interface{}) string {
+	var sessionID string
+	if session != nil {
+		sessionID = session.ID
+	}
+	return fmt.Sprintf("%s/%T:%v", sessionID, id, id)
+}
+
 func (s *Server) handleRequest(ctx context.Context, req *Request) *Response {
 	s.logger.Debug("handling request", "method", req.Method, "id", req.ID)
 
 	switch req.Method {
 	case "initialize":
-		return s.handleInitialize(req)
+		return s.handleInitialize(ctx, req)
 
-	case "initialized":
+	case "initialized", "notifications/initialized":
 		// Notification, no response needed
 		s.logger.Info("client initialized")
 		return nil
 
+	case "$/cancelRequest", "notifications/cancelled":
+		s.cancelRequest(ctx, req)
+		return nil
+
 	case "tools/list":
-		return s.handleListTools(req)
+		return s.handleListTools(ctx, req)
 
 	case "tools/call":
 		return s.handleCallTool(ctx, req)
@@ -139,7 +288,7 @@ func (s *Server) handleRequest(ctx context.Context, req *Request) *Response {
 	}
 }
 
-func (s *Server) handleInitialize(req *Request) *Response {
+func (s *Server) handleInitialize(ctx context.Context, req *Request) *Response {
 	var params InitializeParams
 	if req.Params != nil {
 		if err := json.Unmarshal(req.Params, &params); err != nil {
@@ -150,10 +299,36 @@ func (s *Server) handleInitialize(req *Request) *Response {
 	s.logger.Info("initializing",
 		"client", params.ClientInfo.Name,
 		"clientVersion", params.ClientInfo.Version,
-		"protocolVersion", params.ProtocolVersion)
+		"protocolVersion", params.ProtocolVersion,
+		"rootUri", params.RootURI)
+
+	version, err := negotiateProtocolVersion(params.ProtocolVersion)
+	if err != nil {
+		s.logger.Error("protocol negotiation failed", "requested", params.ProtocolVersion)
+		return &Response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &Error{
+				Code:    ErrCodeInvalidParams,
+				Message: err.Error(),
+				Data: map[string]interface{}{
+					"requested": params.ProtocolVersion,
+					"supported": SupportedProtocolVersions,
+				},
+			},
+		}
+	}
+
+	if session := SessionFromContext(ctx); session != nil {
+		session.setProtocolVersion(version)
+		session.setClientInfo(params.ClientInfo)
+		if params.RootURI != "" {
+			session.setRootURI(params.RootURI)
+		}
+	}
 
 	result := InitializeResult{
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: version,
 		Capabilities: ServerCapabilities{
 			Tools:     &ToolsCapability{},
 			Resources: &ResourcesCapability{},
@@ -171,8 +346,45 @@ func (s *Server) handleInitialize(req *Request) *Response {
 	}
 }
 
-func (s *Server) handleListTools(req *Request) *Response {
-	tools := s.handler.ListTools()
+// negotiateProtocolVersion accepts any supported revision as requested.
+// Clients that omit the version predate negotiation and get the oldest.
+func negotiateProtocolVersion(requested string) (string, error) {
+	if requested == "" {
+		return ProtocolVersion20241105, nil
+	}
+	for _, v := range SupportedProtocolVersions {
+		if v == requested {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported protocol version %q (supported: %s)", requested, strings.Join(SupportedProtocolVersions, ", "))
+}
+
+// negotiatedVersion is the protocol revision agreed with the request's client.
+func negotiatedVersion(ctx context.Context) string {
+	if session := SessionFromContext(ctx); session != nil {
+		return session.ProtocolVersion()
+	}
+	return ProtocolVersion20241105
+}
+
+// toolsForVersion strips tool fields the negotiated revision does not define.
+func toolsForVersion(tools []Tool, version string) []Tool {
+	adapted := make([]Tool, len(tools))
+	for i, t := range tools {
+		if version < ProtocolVersion20250326 {
+			t.Annotations = nil
+		}
+		if version < ProtocolVersion20250618 {
+			t.OutputSchema = nil
+		}
+		adapted[i] = t
+	}
+	return adapted
+}
+
+func (s *Server) handleListTools(ctx context.Context, req *Request) *Response {
+	tools := toolsForVersion(s.handler.ListTools(), negotiatedVersion(ctx))
 
 	return &Response{
 		JSONRPC: "2.0",
@@ -210,6 +422,10 @@ func (s *Server) handleCallTool(ctx context.Context, req *Request) *Response {
 		}
 	}
 
+	if result != nil && negotiatedVersion(ctx) < ProtocolVersion20250618 {
+		result.StructuredContent = nil
+	}
+
 	return &Response{
 		JSONRPC: "2.0",
 		ID:      req.ID,
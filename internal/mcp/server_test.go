@@ -0,0 +1,324 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingHandler's "block" tool waits until its context is cancelled or
+// release is closed.
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) ListTools() []Tool { return nil }
+
+func (h *blockingHandler) CallTool(ctx context.Context, name string, args map[string]interface{}) (*CallToolResult, error) {
+	if name != "block" {
+		return &CallToolResult{Content: []Content{{Type: "text", Text: name}}}, nil
+	}
+	close(h.started)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-h.release:
+		return &CallToolResult{Content: []Content{{Type: "text", Text: "done"}}}, nil
+	}
+}
+
+func (h *blockingHandler) ListResources() []Resource { return nil }
+
+func (h *blockingHandler) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	return nil, nil
+}
+
+// lineWriter delivers each written line to a channel.
+type lineWriter struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	lines chan string
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		w.lines <- strings.TrimSpace(line)
+	}
+}
+
+type serverHarness struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *lineWriter
+	done   chan error
+	cancel context.CancelFunc
+}
+
+func startServer(t *testing.T, handler Handler) *serverHarness {
+	t.Helper()
+	return startServerWithLimits(t, handler, Limits{})
+}
+
+func startServerWithLimits(t *testing.T, handler Handler, limits Limits) *serverHarness {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, writer := io.Pipe()
+	h := &serverHarness{
+		t:      t,
+		in:     writer,
+		out:    &lineWriter{lines: make(chan string, 16)},
+		done:   make(chan error, 1),
+		cancel: cancel,
+	}
+
+	server := NewServer("test", "0.0.0", handler, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	server.SetLimits(limits)
+	go func() { h.done <- server.Run(ctx, reader, h.out) }()
+
+	t.Cleanup(func() {
+		writer.Close()
+		cancel()
+	})
+	return h
+}
+
+func (h *serverHarness) send(msg map[string]interface{}) {
+	h.t.Helper()
+	msg["jsonrpc"] = "2.0"
+	data, err := json.Marshal(msg)
+	require.NoError(h.t, err)
+	_, err = h.in.Write(append(data, '\n'))
+	require.NoError(h.t, err)
+}
+
+func (h *serverHarness) read() map[string]interface{} {
+	h.t.Helper()
+	select {
+	case line := <-h.out.lines:
+		var resp map[string]interface{}
+		require.NoError(h.t, json.Unmarshal([]byte(line), &resp))
+		return resp
+	case <-time.After(5 * time.Second):
+		h.t.Fatal("timed out waiting for response")
+		return nil
+	}
+}
+
+func TestServerSlowCallDoesNotBlockPing(t *testing.T) {
+	handler := &blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+	h := startServer(t, handler)
+
+	h.send(map[string]interface{}{"id": 1, "method": "tools/call", "params": map[string]interface{}{"name": "block"}})
+	<-handler.started
+
+	h.send(map[string]interface{}{"id": 2, "method": "ping"})
+	resp := h.read()
+	assert.Equal(t, float64(2), resp["id"], "ping answered while tool call is running")
+
+	close(handler.release)
+	resp = h.read()
+	assert.Equal(t, float64(1), resp["id"])
+	assert.Nil(t, resp["error"])
+}
+
+func TestServerCancelRequest(t *testing.T) {
+	for _, method := range []string{"$/cancelRequest", "notifications/cancelled"} {
+		t.Run(method, func(t *testing.T) {
+			handler := &blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+			h := startServer(t, handler)
+
+			h.send(map[string]interface{}{"id": "req-1", "method": "tools/call", "params": map[string]interface{}{"name": "block"}})
+			<-handler.started
+
+			h.send(map[string]interface{}{"method": method, "params": map[string]interface{}{"id": "req-1", "requestId": "req-1"}})
+			h.send(map[string]interface{}{"id": "ping-1", "method": "ping"})
+
+			// The ping is answered; the cancelled call, which returns as soon as
+			// it is cancelled, is not
+			if method == "notifications/cancelled" {
+				resp := h.read()
+				assert.Equal(t, "ping-1", resp["id"])
+				select {
+				case line := <-h.out.lines:
+					t.Fatalf("response sent for a request cancelled by notifications/cancelled: %s", line)
+				case <-time.After(100 * time.Millisecond):
+				}
+				return
+			}
+
+			resps := map[interface{}]map[string]interface{}{}
+			for range 2 {
+				resp := h.read()
+				resps[resp["id"]] = resp
+			}
+			errObj, ok := resps["req-1"]["error"].(map[string]interface{})
+			require.True(t, ok, "cancelled request returns an error")
+			assert.Equal(t, float64(ErrCodeRequestCancelled), errObj["code"])
+		})
+	}
+}
+
+func TestServerNotificationsGetNoResponse(t *testing.T) {
+	h := startServer(t, &blockingHandler{})
+
+	h.send(map[string]interface{}{"method": "notifications/unknown"})
+	h.send(map[string]interface{}{"method": "$/cancelRequest", "params": map[string]interface{}{"id": 99}})
+	h.send(map[string]interface{}{"id": 3, "method": "ping"})
+
+	resp := h.read()
+	assert.Equal(t, float64(3), resp["id"], "only the ping is answered")
+}
+
+func TestServerWaitsForInflightRequests(t *testing.T) {
+	handler := &blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+	h := startServer(t, handler)
+
+	h.send(map[string]interface{}{"id": 1, "method": "tools/call", "params": map[string]interface{}{"name": "block"}})
+	<-handler.started
+	h.in.Close()
+
+	select {
+	case <-h.done:
+		t.Fatal("Run returned before the in-flight request finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(handler.release)
+	resp := h.read()
+	assert.Equal(t, float64(1), resp["id"])
+	require.NoError(t, <-h.done)
+}
+
+// annotatedHandler lists one tool with every version-gated field set.
+type annotatedHandler struct {
+	blockingHandler
+}
+
+func (h *annotatedHandler) ListTools() []Tool {
+	return []Tool{{
+		Name:         "search",
+		InputSchema:  InputSchema{Type: "object"},
+		OutputSchema: map[string]interface{}{"type": "object"},
+		Annotations:  &ToolAnnotations{ReadOnlyHint: true},
+	}}
+}
+
+func (h *annotatedHandler) CallTool(ctx context.Context, name string, args map[string]interface{}) (*CallToolResult, error) {
+	return &CallToolResult{
+		Content:           []Content{{Type: "text", Text: `{"ok":true}`}},
+		StructuredContent: map[string]interface{}{"ok": true},
+	}, nil
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	for _, v := range SupportedProtocolVersions {
+		got, err := negotiateProtocolVersion(v)
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	}
+
+	got, err := negotiateProtocolVersion("")
+	require.NoError(t, err)
+	assert.Equal(t, ProtocolVersion20241105, got)
+
+	_, err = negotiateProtocolVersion("2023-01-01")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), LatestProtocolVersion)
+}
+
+func TestServerInitializeRejectsUnsupportedVersion(t *testing.T) {
+	h := startServer(t, &blockingHandler{})
+
+	h.send(map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{"protocolVersion": "1999-01-01"}})
+
+	resp := h.read()
+	errObj, ok := resp["error"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, float64(ErrCodeInvalidParams), errObj["code"])
+	assert.Contains(t, errObj["message"], "unsupported protocol version")
+}
+
+func TestServerVersionGatedFields(t *testing.T) {
+	tests := []struct {
+		version        string
+		wantAnnotation bool
+		wantStructured bool
+	}{
+		{ProtocolVersion20241105, false, false},
+		{ProtocolVersion20250326, true, false},
+		{ProtocolVersion20250618, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			h := startServer(t, &annotatedHandler{})
+
+			h.send(map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{"protocolVersion": tt.version}})
+			resp := h.read()
+			result := resp["result"].(map[string]interface{})
+			assert.Equal(t, tt.version, result["protocolVersion"])
+
+			h.send(map[string]interface{}{"id": 2, "method": "tools/list"})
+			resp = h.read()
+			tool := resp["result"].(map[string]interface{})["tools"].([]interface{})[0].(map[string]interface{})
+			assert.Equal(t, tt.wantAnnotation, tool["annotations"] != nil)
+			assert.Equal(t, tt.wantStructured, tool["outputSchema"] != nil)
+
+			h.send(map[string]interface{}{"id": 3, "method": "tools/call", "params": map[string]interface{}{"name": "search"}})
+			resp = h.read()
+			call := resp["result"].(map[string]interface{})
+			assert.Equal(t, tt.wantStructured, call["structuredContent"] != nil)
+		})
+	}
+}
+
+// sessionHandler reports the calling session's ID and root URI.
+type sessionHandler struct {
+	blockingHandler
+}
+
+func (h *sessionHandler) CallTool(ctx context.Context, name string, args map[string]interface{}) (*CallToolResult, error) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return &CallToolResult{Content: []Content{{Type: "text", Text: "no session"}}, IsError: true}, nil
+	}
+	return &CallToolResult{Content: []Content{{Type: "text", Text: session.ID + " " + session.RootURI()}}}, nil
+}
+
+func TestServerSessionCarriesRootURI(t *testing.T) {
+	h := startServer(t, &sessionHandler{})
+
+	h.send(map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{"rootUri": "file:///home/dev/repos/demo"}})
+	h.read()
+
+	var ids []string
+	for id := 2; id <= 3; id++ {
+		h.send(map[string]interface{}{"id": id, "method": "tools/call", "params": map[string]interface{}{"name": "whoami"}})
+		resp := h.read()
+		text := resp["result"].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+		parts := strings.SplitN(text, " ", 2)
+		require.Len(t, parts, 2, text)
+		assert.Equal(t, "file:///home/dev/repos/demo", parts[1])
+		ids = append(ids, parts[0])
+	}
+	assert.NotEmpty(t, ids[0])
+	assert.Equal(t, ids[0], ids[1], "calls share the connection's session")
+}
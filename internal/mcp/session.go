@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// Session holds per-client state for one connection. Handlers reach it
+// through SessionFromContext to keep settings such as a pinned repo separate
+// per client. The stdio transport serves exactly one session per Run; the
+// HTTP transport creates one per initialize.
+type Session struct {
+	ID string
+
+	limiter *tokenBucket // Per-session request rate, nil when unlimited
+
+	mu              sync.RWMutex
+	protocolVersion string // Negotiated in initialize
+	rootURI         string
+	clientInfo      ClientInfo // Sent in initialize
+	values          map[string]interface{}
+}
+
+// NewSession creates a session with a random ID.
+func NewSession() *Session {
+	// IDs double as HTTP session handles, so they must be unguessable
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return &Session{
+		ID:              hex.EncodeToString(b),
+		protocolVersion: ProtocolVersion20241105,
+		values:          make(map[string]interface{}),
+	}
+}
+
+// ProtocolVersion returns the protocol revision negotiated in initialize.
+func (s *Session) ProtocolVersion() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.protocolVersion
+}
+
+func (s *Session) setProtocolVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.protocolVersion = version
+}
+
+// RootURI returns the workspace root the client sent in initialize, if any.
+func (s *Session) RootURI() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rootURI
+}
+
+func (s *Session) setRootURI(uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rootURI = uri
+}
+
+// ClientInfo returns the client name and version sent in initialize.
+func (s *Session) ClientInfo() (name, version string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clientInfo.Name, s.clientInfo.Version
+}
+
+func (s *Session) setClientInfo(info ClientInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clientInfo = info
+}
+
+// Get returns a value stored on the session.
+func (s *Session) Get(key string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set stores a value on the session.
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Delete removes a value from the session.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+type sessionKey struct{}
+
+// WithSession returns a context carrying the session.
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+// SessionFromContext returns the request's session, or nil outside a server.
+func SessionFromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionKey{}).(*Session)
+	return s
+}
@@ -37,6 +37,23 @@ type Notification struct {
 
 // MCP-specific types
 
+// Protocol revisions the server implements, oldest first. Revision strings
+// are dates, so they order lexically.
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26" // Adds tool annotations
+	ProtocolVersion20250618 = "2025-06-18" // Adds structured tool output
+
+	LatestProtocolVersion = ProtocolVersion20250618
+)
+
+// SupportedProtocolVersions lists every revision accepted in initialize.
+var SupportedProtocolVersions = []string{
+	ProtocolVersion20241105,
+	ProtocolVersion20250326,
+	ProtocolVersion20250618,
+}
+
 // ServerInfo contains server identification.
 type ServerInfo struct {
 	Name    string `json:"name"`
@@ -48,6 +65,9 @@ type InitializeParams struct {
 	ProtocolVersion string                 `json:"protocolVersion"`
 	Capabilities    map[string]interface{} `json:"capabilities"`
 	ClientInfo      ClientInfo             `json:"clientInfo"`
+	// RootURI is the client's workspace root (file:// URI). Not part of the
+	// MCP spec, but sent by some clients; it scopes repo inference.
+	RootURI string `json:"rootUri,omitempty"`
 }
 
 // ClientInfo contains client identification.
@@ -82,11 +102,29 @@ type ResourcesCapability struct {
 
 // Tool definitions
 
-// Tool describes an available tool.
+// Tool describes an available tool. Annotations and OutputSchema are only
+// sent to clients that negotiated a revision supporting them.
 type Tool struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	InputSchema InputSchema `json:"inputSchema"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  InputSchema            `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations       `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are behaviour hints for clients. Unset pointer hints take
+// the protocol defaults (destructive and open-world both true).
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  bool   `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
+}
+
+// Bool returns a pointer to v, for optional annotation hints.
+func Bool(v bool) *bool {
+	return &v
 }
 
 // InputSchema describes the JSON schema for tool input.
@@ -114,10 +152,12 @@ type CallToolParams struct {
 	Arguments map[string]interface{} `json:"arguments"`
 }
 
-// CallToolResult contains the result of a tool call.
+// CallToolResult contains the result of a tool call. StructuredContent is
+// only sent to clients that negotiated structured tool output.
 type CallToolResult struct {
-	Content []Content `json:"content"`
-	IsError bool      `json:"isError,omitempty"`
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
 }
 
 // Content represents content in a tool result.
@@ -126,6 +166,14 @@ type Content struct {
 	Text string `json:"text,omitempty"`
 }
 
+// CancelParams identifies a request to cancel. $/cancelRequest sends id;
+// MCP's notifications/cancelled sends requestId.
+type CancelParams struct {
+	ID        interface{} `json:"id,omitempty"`
+	RequestID interface{} `json:"requestId,omitempty"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
 // Resource definitions
 
 // Resource describes an available resource.
@@ -165,4 +213,11 @@ const (
 	ErrCodeMethodNotFound = -32601
 	ErrCodeInvalidParams  = -32602
 	ErrCodeInternal       = -32603
+
+	// ErrCodeServerOverloaded is returned when a request is rejected by the
+	// server's rate or concurrency limits. The client may retry later.
+	ErrCodeServerOverloaded = -32001
+
+	// ErrCodeRequestCancelled is returned for requests cancelled by the client.
+	ErrCodeRequestCancelled = -32800
 )
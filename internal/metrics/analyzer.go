@@ -4,13 +4,17 @@ import (
 	"bufio"
 	"encoding/json"
 	"os"
+	"slices"
 	"sort"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // Analyzer processes metrics logs.
 type Analyzer struct {
 	logPath string
+	repo    string
 }
 
 // NewAnalyzer creates a new analyzer.
@@ -18,6 +22,22 @@ func NewAnalyzer(logPath string) *Analyzer {
 	return &Analyzer{logPath: logPath}
 }
 
+// SetRepo limits Analyze, GetZeroResultQueries, Rescues, CacheStats,
+// Modules, Freshness, Latencies, Experiment, Usage and Spend to the events of repo. Searches across every repo belong to none.
+// Empty (the default) analyzes every repo.
+func (a *Analyzer) SetRepo(repo string) {
+	a.repo = repo
+}
+
+// otherRepo reports whether event is outside the repo set by SetRepo.
+func (a *Analyzer) otherRepo(event map[string]interface{}) bool {
+	if a.repo == "" {
+		return false
+	}
+	repo, _ := event["repo"].(string)
+	return repo != a.repo
+}
+
 // Summary contains aggregated metrics.
 type Summary struct {
 	Period          string         `json:"period"`
@@ -27,6 +47,30 @@ type Summary struct {
 	ZeroResultCount int            `json:"zero_result_count"`
 	CacheHits       int            `json:"cache_hits"`
 	TopQueries      []QueryCount   `json:"top_queries"`
+	FeedbackUseful  int            `json:"feedback_useful"`
+	FeedbackNot     int            `json:"feedback_not_useful"`
+}
+
+// CacheStats summarizes query cache lookups.
+type CacheStats struct {
+	Period        string                  `json:"period"`
+	Lookups       int                     `json:"lookups"`
+	Hits          int                     `json:"hits"`
+	Misses        int                     `json:"misses"`
+	HitRate       float64                 `json:"hit_rate"` // 0-1
+	AvgLatencyMs  float64                 `json:"avg_latency_ms"`
+	P95LatencyMs  float64                 `json:"p95_latency_ms"`
+	MedianHitAgeS int64                   `json:"median_hit_age_s"` // Age of the cached entry at a hit
+	P90HitAgeS    int64                   `json:"p90_hit_age_s"`
+	SemanticTried int                     `json:"semantic_lookups"` // Exact misses compared to similar queries
+	SemanticHits  int                     `json:"semantic_hits"`    // Not counted in Lookups or Hits
+	ByRepo        map[string]*CacheCounts `json:"by_repo"`
+}
+
+// CacheCounts are one repo's cache lookups.
+type CacheCounts struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
 }
 
 // QueryCount represents a query with its count.
@@ -70,6 +114,10 @@ func (a *Analyzer) Analyze(since time.Duration) (*Summary, error) {
 			continue
 		}
 
+		if a.otherRepo(event) {
+			continue
+		}
+
 		// Process by event type
 		eventType, _ := event["event"].(string)
 		switch eventType {
@@ -96,6 +144,13 @@ func (a *Analyzer) Analyze(since time.Duration) (*Summary, error) {
 			if query, ok := event["query"].(string); ok {
 				queryCounts[query]++
 			}
+
+		case "feedback":
+			if useful, _ := event["useful"].(bool); useful {
+				summary.FeedbackUseful++
+			} else {
+				summary.FeedbackNot++
+			}
 		}
 	}
 
@@ -155,7 +210,7 @@ func (a *Analyzer) GetZeroResultQueries(since time.Duration) ([]QueryCount, erro
 		}
 
 		eventType, _ := event["event"].(string)
-		if eventType != "search" {
+		if eventType != "search" || a.otherRepo(event) {
 			continue
 		}
 
@@ -177,3 +232,1033 @@ func (a *Analyzer) GetZeroResultQueries(since time.Duration) ([]QueryCount, erro
 
 	return result, nil
 }
+
+// TopQueries returns up to n of repo's most frequent searches of a time
+// period that found results, most frequent first. An empty repo counts
+// searches of every repo. A missing log has none.
+func (a *Analyzer) TopQueries(since time.Duration, repo string, n int) ([]QueryCount, error) {
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cutoff := time.Now().Add(-since)
+	queryCounts := make(map[string]int)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if eventType, _ := event["event"].(string); eventType != "search" {
+			continue
+		}
+		tsStr, _ := event["ts"].(string)
+		ts, err := time.Parse(time.RFC3339, tsStr)
+		if err != nil || ts.Before(cutoff) {
+			continue
+		}
+		if eventRepo, _ := event["repo"].(string); repo != "" && eventRepo != repo {
+			continue
+		}
+		// Cache hits log -1 results; only searches that found nothing are skipped.
+		query, _ := event["query"].(string)
+		if results, _ := event["results"].(float64); results == 0 || query == "" {
+			continue
+		}
+		queryCounts[query]++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]QueryCount, 0, len(queryCounts))
+	for q, c := range queryCounts {
+		result = append(result, QueryCount{Query: q, Count: c})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Query < result[j].Query
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result, nil
+}
+
+// ChunkFeedback returns the net search_feedback votes (useful minus not
+// useful) of every chunk ever voted on. A missing log has none.
+func (a *Analyzer) ChunkFeedback() (map[string]int, error) {
+	votes := make(map[string]int)
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return votes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if eventType, _ := event["event"].(string); eventType != "feedback" {
+			continue
+		}
+		id, _ := event["chunk_id"].(string)
+		if id == "" {
+			continue
+		}
+		if useful, _ := event["useful"].(bool); useful {
+			votes[id]++
+		} else {
+			votes[id]--
+		}
+	}
+	return votes, scanner.Err()
+}
+
+// CacheStats summarizes the cache lookups of a time period. A missing log
+// has none.
+func (a *Analyzer) CacheStats(since time.Duration) (*CacheStats, error) {
+	stats := &CacheStats{Period: since.String(), ByRepo: make(map[string]*CacheCounts)}
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cutoff := time.Now().Add(-since)
+	var latencies []float64
+	var ages []int64
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if eventType, _ := event["event"].(string); eventType != "cache" || a.otherRepo(event) {
+			continue
+		}
+		tsStr, _ := event["ts"].(string)
+		ts, err := time.Parse(time.RFC3339, tsStr)
+		if err != nil || ts.Before(cutoff) {
+			continue
+		}
+
+		if semantic, _ := event["semantic"].(bool); semantic {
+			stats.SemanticTried++
+			if hit, _ := event["hit"].(bool); hit {
+				stats.SemanticHits++
+			}
+			continue
+		}
+
+		repo, _ := event["repo"].(string)
+		counts := stats.ByRepo[repo]
+		if counts == nil {
+			counts = &CacheCounts{}
+			stats.ByRepo[repo] = counts
+		}
+		stats.Lookups++
+		if hit, _ := event["hit"].(bool); hit {
+			stats.Hits++
+			counts.Hits++
+			if age, ok := event["age_s"].(float64); ok {
+				ages = append(ages, int64(age))
+			}
+		} else {
+			stats.Misses++
+			counts.Misses++
+		}
+		if latency, ok := event["latency_ms"].(float64); ok {
+			latencies = append(latencies, latency)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if stats.Lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(stats.Lookups)
+	}
+	if len(latencies) > 0 {
+		total := 0.0
+		for _, l := range latencies {
+			total += l
+		}
+		stats.AvgLatencyMs = total / float64(len(latencies))
+		sort.Float64s(latencies)
+		stats.P95LatencyMs = latencies[percentileIndex(len(latencies), 95)]
+	}
+	if len(ages) > 0 {
+		sort.Slice(ages, func(i, j int) bool { return ages[i] < ages[j] })
+		stats.MedianHitAgeS = ages[percentileIndex(len(ages), 50)]
+		stats.P90HitAgeS = ages[percentileIndex(len(ages), 90)]
+	}
+	return stats, nil
+}
+
+// percentileIndex is the nearest-rank index of the p-th percentile in a
+// sorted slice of n > 0 values.
+func percentileIndex(n, p int) int {
+	return max(0, (n*p+99)/100-1)
+}
+
+// ModuleStats is the search volume of one module of a repo.
+type ModuleStats struct {
+	Repo           string  `json:"repo"`   // "all" or empty for searches across repos
+	Module         string  `json:"module"` // Empty for searches of the whole repo
+	Searches       int     `json:"searches"`
+	ZeroResults    int     `json:"zero_results"`
+	ZeroResultRate float64 `json:"zero_result_rate"` // ZeroResults / Searches (0-1)
+}
+
+// Modules counts the searches of a time period and those that found
+// nothing per searched repo and module, most searched first. Cache hits
+// count as searches that found results. A missing log has none.
+func (a *Analyzer) Modules(since time.Duration) ([]ModuleStats, error) {
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	type key struct{ repo, module string }
+	counts := make(map[key]*ModuleStats)
+	cutoff := time.Now().Add(-since)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if eventType, _ := event["event"].(string); eventType != "search" || a.otherRepo(event) {
+			continue
+		}
+		tsStr, _ := event["ts"].(string)
+		ts, err := time.Parse(time.RFC3339, tsStr)
+		if err != nil || ts.Before(cutoff) {
+			continue
+		}
+		repo, _ := event["repo"].(string)
+		module, _ := event["module"].(string)
+		k := key{repo, module}
+		stats := counts[k]
+		if stats == nil {
+			stats = &ModuleStats{Repo: repo, Module: module}
+			counts[k] = stats
+		}
+		stats.Searches++
+		if results, ok := event["results"].(float64); ok && results == 0 {
+			stats.ZeroResults++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]ModuleStats, 0, len(counts))
+	for _, stats := range counts {
+		stats.ZeroResultRate = float64(stats.ZeroResults) / float64(stats.Searches)
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Searches != result[j].Searches {
+			return result[i].Searches > result[j].Searches
+		}
+		if result[i].Repo != result[j].Repo {
+			return result[i].Repo < result[j].Repo
+		}
+		return result[i].Module < result[j].Module
+	})
+	return result, nil
+}
+
+// RepoFreshness is how long after their commits a repo's syncs brought its
+// index up to date.
+type RepoFreshness struct {
+	Repo     string    `json:"repo"`
+	Syncs    int       `json:"syncs"`
+	LastSync time.Time `json:"last_sync"`
+	LastLagS float64   `json:"last_lag_s"` // Of the last sync
+	P50LagS  float64   `json:"p50_lag_s"`
+	P95LagS  float64   `json:"p95_lag_s"`
+	MaxLagS  float64   `json:"max_lag_s"`
+}
+
+// Freshness summarizes the freshness events of a time period per repo, by
+// repo name. A missing log has none.
+func (a *Analyzer) Freshness(since time.Duration) ([]RepoFreshness, error) {
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	repos := make(map[string]*RepoFreshness)
+	lags := make(map[string][]float64)
+	cutoff := time.Now().Add(-since)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if eventType, _ := event["event"].(string); eventType != "freshness" || a.otherRepo(event) {
+			continue
+		}
+		tsStr, _ := event["ts"].(string)
+		ts, err := time.Parse(time.RFC3339, tsStr)
+		if err != nil || ts.Before(cutoff) {
+			continue
+		}
+		lag, ok := event["lag_s"].(float64)
+		if !ok {
+			continue
+		}
+		repo, _ := event["repo"].(string)
+		f := repos[repo]
+		if f == nil {
+			f = &RepoFreshness{Repo: repo}
+			repos[repo] = f
+		}
+		f.Syncs++
+		f.LastSync = ts
+		f.LastLagS = lag
+		lags[repo] = append(lags[repo], lag)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]RepoFreshness, 0, len(repos))
+	for repo, f := range repos {
+		sorted := lags[repo]
+		sort.Float64s(sorted)
+		f.P50LagS = sorted[percentileIndex(len(sorted), 50)]
+		f.P95LagS = sorted[percentileIndex(len(sorted), 95)]
+		f.MaxLagS = sorted[len(sorted)-1]
+		result = append(result, *f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Repo < result[j].Repo })
+	return result, nil
+}
+
+// ExperimentReport compares the arms of a search experiment.
+type ExperimentReport struct {
+	Name   string     `json:"name"`
+	Period string     `json:"period"`
+	Arms   []ArmStats `json:"arms"` // Control first, then by variant
+}
+
+// ArmStats is the quality and latency of one arm of an experiment.
+type ArmStats struct {
+	Variant        string      `json:"variant"`
+	Searches       int         `json:"searches"`
+	CacheHits      int         `json:"cache_hits"`
+	ZeroResults    int         `json:"zero_results"`
+	ZeroResultRate float64     `json:"zero_result_rate"` // Of searches that missed the cache (0-1)
+	AvgResults     float64     `json:"avg_results"`      // Per search that missed the cache
+	Latency        Percentiles `json:"latency"`          // Of searches that missed the cache
+	Useful         int         `json:"useful"`           // search_feedback votes on the arm's queries
+	NotUseful      int         `json:"not_useful"`
+	UsefulRate     float64     `json:"useful_rate"` // Useful / votes (0-1)
+}
+
+// Experiment compares the arms of the named experiment over a time period;
+// an empty name reports the experiment of the latest tagged search. Votes
+// count toward the arm of their query, which experiments keep per query. A
+// missing log has no experiment.
+func (a *Analyzer) Experiment(since time.Duration, name string) (*ExperimentReport, error) {
+	report := &ExperimentReport{Name: name, Period: since.String()}
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	type arm struct {
+		stats     ArmStats
+		results   int
+		latencies []float64
+	}
+	type experimentData struct {
+		arms    map[string]*arm
+		queries map[string]string // Query -> variant
+	}
+	experiments := make(map[string]*experimentData)
+	type vote struct {
+		query  string
+		useful bool
+	}
+	var votes []vote
+	cutoff := time.Now().Add(-since)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if a.otherRepo(event) {
+			continue
+		}
+		tsStr, _ := event["ts"].(string)
+		ts, err := time.Parse(time.RFC3339, tsStr)
+		if err != nil || ts.Before(cutoff) {
+			continue
+		}
+		query, _ := event["query"].(string)
+		switch eventType, _ := event["event"].(string); eventType {
+		case "search":
+			experiment, _ := event["experiment"].(string)
+			variant, _ := event["variant"].(string)
+			if experiment == "" || variant == "" {
+				continue
+			}
+			if name == "" {
+				report.Name = experiment
+			}
+			data := experiments[experiment]
+			if data == nil {
+				data = &experimentData{arms: make(map[string]*arm), queries: make(map[string]string)}
+				experiments[experiment] = data
+			}
+			data.queries[query] = variant
+			ar := data.arms[variant]
+			if ar == nil {
+				ar = &arm{stats: ArmStats{Variant: variant}}
+				data.arms[variant] = ar
+			}
+			ar.stats.Searches++
+			if hit, _ := event["cache_hit"].(bool); hit {
+				ar.stats.CacheHits++
+				continue
+			}
+			results, _ := event["results"].(float64)
+			ar.results += int(results)
+			if results == 0 {
+				ar.stats.ZeroResults++
+			}
+			if latency, ok := event["latency_ms"].(float64); ok {
+				ar.latencies = append(ar.latencies, latency)
+			}
+		case "feedback":
+			useful, _ := event["useful"].(bool)
+			votes = append(votes, vote{query: query, useful: useful})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	data := experiments[report.Name]
+	if data == nil {
+		return report, nil
+	}
+	for _, v := range votes {
+		ar := data.arms[data.queries[v.query]]
+		if ar == nil {
+			continue
+		}
+		if v.useful {
+			ar.stats.Useful++
+		} else {
+			ar.stats.NotUseful++
+		}
+	}
+	for _, ar := range data.arms {
+		if missed := ar.stats.Searches - ar.stats.CacheHits; missed > 0 {
+			ar.stats.ZeroResultRate = float64(ar.stats.ZeroResults) / float64(missed)
+			ar.stats.AvgResults = float64(ar.results) / float64(missed)
+		}
+		if votes := ar.stats.Useful + ar.stats.NotUseful; votes > 0 {
+			ar.stats.UsefulRate = float64(ar.stats.Useful) / float64(votes)
+		}
+		ar.stats.Latency = percentiles(ar.latencies)
+		report.Arms = append(report.Arms, ar.stats)
+	}
+	sort.Slice(report.Arms, func(i, j int) bool {
+		if (report.Arms[i].Variant == "control") != (report.Arms[j].Variant == "control") {
+			return report.Arms[i].Variant == "control"
+		}
+		return report.Arms[i].Variant < report.Arms[j].Variant
+	})
+	return report, nil
+}
+
+// Percentiles summarizes latencies in milliseconds.
+type Percentiles struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50_ms"`
+	P95   float64 `json:"p95_ms"`
+	P99   float64 `json:"p99_ms"`
+	Max   float64 `json:"max_ms"`
+}
+
+// LatencyStats are the search latency percentiles of a time period, in
+// total and per stage.
+type LatencyStats struct {
+	Period   string                 `json:"period"`
+	Total    Percentiles            `json:"total"`    // Every search, cache hits included
+	Uncached Percentiles            `json:"uncached"` // Searches that missed the cache
+	Stages   map[string]Percentiles `json:"stages"`   // Searches that ran the stage
+}
+
+// stageOrder is the order the search pipeline runs its stages in.
+var stageOrder = []string{"embed", "store", "graph", "format"}
+
+// Latencies computes the latency percentiles of the searches of a time
+// period. A missing log has none.
+func (a *Analyzer) Latencies(since time.Duration) (*LatencyStats, error) {
+	stats := &LatencyStats{Period: since.String(), Stages: make(map[string]Percentiles)}
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var total, uncached []float64
+	stages := make(map[string][]float64)
+	cutoff := time.Now().Add(-since)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if eventType, _ := event["event"].(string); eventType != "search" || a.otherRepo(event) {
+			continue
+		}
+		tsStr, _ := event["ts"].(string)
+		ts, err := time.Parse(time.RFC3339, tsStr)
+		if err != nil || ts.Before(cutoff) {
+			continue
+		}
+		if latency, ok := event["latency_ms"].(float64); ok {
+			total = append(total, latency)
+			if hit, _ := event["cache_hit"].(bool); !hit {
+				uncached = append(uncached, latency)
+			}
+		}
+		timings, _ := event["stages"].(map[string]interface{})
+		for stage, v := range timings {
+			if ms, ok := v.(float64); ok {
+				stages[stage] = append(stages[stage], ms)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	stats.Total = percentiles(total)
+	stats.Uncached = percentiles(uncached)
+	for stage, values := range stages {
+		stats.Stages[stage] = percentiles(values)
+	}
+	return stats, nil
+}
+
+// StageNames returns the stages of s in pipeline order, unknown ones last
+// by name.
+func (s *LatencyStats) StageNames() []string {
+	names := make([]string, 0, len(s.Stages))
+	for _, stage := range stageOrder {
+		if _, ok := s.Stages[stage]; ok {
+			names = append(names, stage)
+		}
+	}
+	var others []string
+	for stage := range s.Stages {
+		if !slices.Contains(stageOrder, stage) {
+			others = append(others, stage)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+// percentiles sorts values and reads their percentiles.
+func percentiles(values []float64) Percentiles {
+	if len(values) == 0 {
+		return Percentiles{}
+	}
+	sort.Float64s(values)
+	return Percentiles{
+		Count: len(values),
+		P50:   values[percentileIndex(len(values), 50)],
+		P95:   values[percentileIndex(len(values), 95)],
+		P99:   values[percentileIndex(len(values), 99)],
+		Max:   values[len(values)-1],
+	}
+}
+
+// RescueWindow is how long after a zero-result search a successful search
+// in the same session counts as rescuing it.
+const RescueWindow = 10 * time.Minute
+
+// RescueStats follows up the zero-result searches of client sessions: was
+// a later search of the session within RescueWindow successful (rescued),
+// and did it use a term suggested with the empty result?
+type RescueStats struct {
+	Period         string       `json:"period"`
+	ZeroResults    int          `json:"zero_results"`      // In sessions
+	Rescued        int          `json:"rescued"`           // A successful search followed
+	ViaSuggestion  int          `json:"via_suggestion"`    // Of Rescued: the search used a suggested term
+	Abandoned      int          `json:"abandoned"`         // No successful search followed
+	Untracked      int          `json:"untracked"`         // Outside sessions (CLI searches), not followed up
+	RescueRate     float64      `json:"rescue_rate"`       // Rescued / ZeroResults (0-1)
+	SuggestionRate float64      `json:"suggestion_rate"`   // ViaSuggestion / ZeroResults (0-1)
+	Abandons       []QueryCount `json:"abandoned_queries"` // Most abandoned queries, at most 10
+	Rescues        []Rescue     `json:"rescues"`           // Most recent rescues, at most 10
+}
+
+// Rescue is a zero-result query and the search that rescued it.
+type Rescue struct {
+	Query         string `json:"query"`
+	RescuedBy     string `json:"rescued_by"`
+	ViaSuggestion bool   `json:"via_suggestion"`
+}
+
+// Rescues correlates the zero-result searches of a time period with the
+// searches that followed them in the same session. Cache hits, whose
+// result counts are not logged, neither rescue nor end a search's window.
+// A missing log has none.
+func (a *Analyzer) Rescues(since time.Duration) (*RescueStats, error) {
+	stats := &RescueStats{Period: since.String()}
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	type failure struct {
+		query       string
+		at          time.Time
+		suggestions []string
+	}
+	open := make(map[string][]failure) // session -> zero-result searches awaiting a rescue
+	abandoned := make(map[string]int)
+	abandon := func(f failure) {
+		stats.Abandoned++
+		abandoned[f.query]++
+	}
+	cutoff := time.Now().Add(-since)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if eventType, _ := event["event"].(string); eventType != "search" {
+			continue
+		}
+		tsStr, _ := event["ts"].(string)
+		ts, err := time.Parse(time.RFC3339, tsStr)
+		if err != nil || ts.Before(cutoff) {
+			continue
+		}
+		results, ok := event["results"].(float64)
+		if !ok || results < 0 || a.otherRepo(event) {
+			continue
+		}
+		query, _ := event["query"].(string)
+		session, _ := event["session"].(string)
+		if session == "" {
+			if results == 0 {
+				stats.Untracked++
+			}
+			continue
+		}
+
+		// Searches past their window are abandoned
+		pending := open[session][:0]
+		for _, f := range open[session] {
+			if ts.Sub(f.at) > RescueWindow {
+				abandon(f)
+			} else {
+				pending = append(pending, f)
+			}
+		}
+
+		if results > 0 {
+			for _, f := range pending {
+				rescue := Rescue{Query: f.query, RescuedBy: query, ViaSuggestion: usesSuggestion(query, f.suggestions)}
+				stats.Rescued++
+				if rescue.ViaSuggestion {
+					stats.ViaSuggestion++
+				}
+				stats.Rescues = append(stats.Rescues, rescue)
+			}
+			delete(open, session)
+			continue
+		}
+
+		stats.ZeroResults++
+		open[session] = append(pending, failure{query: query, at: ts, suggestions: listValues(event["suggestions"])})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// Searches still within their window when the log ends are not
+	// abandoned yet, but nothing rescued them either
+	for _, failures := range open {
+		for _, f := range failures {
+			abandon(f)
+		}
+	}
+
+	if stats.ZeroResults > 0 {
+		stats.RescueRate = float64(stats.Rescued) / float64(stats.ZeroResults)
+		stats.SuggestionRate = float64(stats.ViaSuggestion) / float64(stats.ZeroResults)
+	}
+	if len(stats.Rescues) > 10 {
+		stats.Rescues = stats.Rescues[len(stats.Rescues)-10:]
+	}
+	for query, count := range abandoned {
+		stats.Abandons = append(stats.Abandons, QueryCount{Query: query, Count: count})
+	}
+	sort.Slice(stats.Abandons, func(i, j int) bool {
+		if stats.Abandons[i].Count != stats.Abandons[j].Count {
+			return stats.Abandons[i].Count > stats.Abandons[j].Count
+		}
+		return stats.Abandons[i].Query < stats.Abandons[j].Query
+	})
+	if len(stats.Abandons) > 10 {
+		stats.Abandons = stats.Abandons[:10]
+	}
+	return stats, nil
+}
+
+// usesSuggestion reports whether query contains one of the suggested
+// terms as a word, ignoring case.
+func usesSuggestion(query string, suggestions []string) bool {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	for _, s := range suggestions {
+		if slices.Contains(words, strings.ToLower(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// listValues reads a JSON string array.
+func listValues(v interface{}) []string {
+	items, _ := v.([]interface{})
+	var values []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// UsageWindow is how long after a search a mark_used call of its session
+// can be attributed to it.
+const UsageWindow = 30 * time.Minute
+
+// UsageStats relates the results clients opened (mark_used) to the
+// searches that returned them, per retrieval strategy.
+type UsageStats struct {
+	Period       string          `json:"period"`
+	Used         int             `json:"used"`         // mark_used calls
+	Unattributed int             `json:"unattributed"` // With no search of their session in UsageWindow before
+	Strategies   []StrategyUsage `json:"strategies"`   // Most searches first
+}
+
+// StrategyUsage is how often one strategy's results were opened. Strategy
+// is the retrieval the caller or an experiment chose, or "auto:" and the
+// query type for classified searches (routed by search.strategies).
+type StrategyUsage struct {
+	Strategy     string  `json:"strategy"`
+	Searches     int     `json:"searches"`
+	SearchesUsed int     `json:"searches_used"` // With at least one result opened
+	UseRate      float64 `json:"use_rate"`      // SearchesUsed / Searches (0-1)
+	Results      int     `json:"results"`       // Returned by searches that were not cache hits
+	Opened       int     `json:"opened"`        // Distinct files opened from those searches
+	Precision    float64 `json:"precision"`     // Opened / Results (0-1): a proxy, as results are chunks and opens are files
+}
+
+// Usage attributes the mark_used calls of a time period to searches: to
+// the latest search of the same session in UsageWindow before it with the
+// call's query, or without a query to the session's latest search. Calls
+// outside sessions match searches outside sessions. A missing log has none.
+func (a *Analyzer) Usage(since time.Duration) (*UsageStats, error) {
+	stats := &UsageStats{Period: since.String()}
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	type search struct {
+		query    string
+		strategy string
+		at       time.Time
+		results  int // -1 on a cache hit
+		opened   map[string]bool
+	}
+	var searches []*search
+	recent := make(map[string][]*search) // session -> its latest searches, oldest first
+	cutoff := time.Now().Add(-since)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		eventType, _ := event["event"].(string)
+		if eventType != "search" && eventType != "used" {
+			continue
+		}
+		tsStr, _ := event["ts"].(string)
+		ts, err := time.Parse(time.RFC3339, tsStr)
+		if err != nil || ts.Before(cutoff) || a.otherRepo(event) {
+			continue
+		}
+		query, _ := event["query"].(string)
+		session, _ := event["session"].(string)
+
+		if eventType == "search" {
+			results, _ := event["results"].(float64)
+			s := &search{query: normalizeQuery(query), strategy: searchStrategy(event), at: ts, results: int(results), opened: map[string]bool{}}
+			searches = append(searches, s)
+			if len(recent[session]) == 20 {
+				recent[session] = recent[session][1:]
+			}
+			recent[session] = append(recent[session], s)
+			continue
+		}
+
+		stats.Used++
+		query = normalizeQuery(query)
+		var match *search
+		for i := len(recent[session]) - 1; i >= 0; i-- {
+			s := recent[session][i]
+			if ts.Sub(s.at) > UsageWindow {
+				break
+			}
+			if query == "" || s.query == query {
+				match = s
+				break
+			}
+		}
+		if match == nil {
+			stats.Unattributed++
+			continue
+		}
+		path, _ := event["file_path"].(string)
+		match.opened[path] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	byStrategy := make(map[string]*StrategyUsage)
+	for _, s := range searches {
+		u, ok := byStrategy[s.strategy]
+		if !ok {
+			u = &StrategyUsage{Strategy: s.strategy}
+			byStrategy[s.strategy] = u
+		}
+		u.Searches++
+		if len(s.opened) > 0 {
+			u.SearchesUsed++
+		}
+		if s.results >= 0 {
+			u.Results += s.results
+			u.Opened += len(s.opened)
+		}
+	}
+	for _, u := range byStrategy {
+		u.UseRate = float64(u.SearchesUsed) / float64(u.Searches)
+		if u.Results > 0 {
+			u.Precision = min(1, float64(u.Opened)/float64(u.Results))
+		}
+		stats.Strategies = append(stats.Strategies, *u)
+	}
+	sort.Slice(stats.Strategies, func(i, j int) bool {
+		if stats.Strategies[i].Searches != stats.Strategies[j].Searches {
+			return stats.Strategies[i].Searches > stats.Strategies[j].Searches
+		}
+		return stats.Strategies[i].Strategy < stats.Strategies[j].Strategy
+	})
+	return stats, nil
+}
+
+// searchStrategy is the strategy a search event was retrieved with.
+func searchStrategy(event map[string]interface{}) string {
+	if strategy, _ := event["strategy"].(string); strategy != "" {
+		return strategy
+	}
+	queryType, _ := event["query_type"].(string)
+	return "auto:" + queryType
+}
+
+// normalizeQuery compares queries ignoring case and surrounding space.
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.TrimSpace(query))
+}
+
+// SpendReport totals the embedding tokens of index runs and prices them.
+// Costs are in the currency of the prices given; tokens of models without
+// a price are counted but not costed.
+type SpendReport struct {
+	Period   string      `json:"period"`
+	Tokens   int         `json:"tokens"`
+	Cost     float64     `json:"cost"`
+	Unpriced []string    `json:"unpriced_models,omitempty"`
+	Repos    []RepoSpend `json:"repos"` // Most tokens first
+	Runs     []RunSpend  `json:"runs"`  // Most recent first; single-file reindexes are only in Repos
+}
+
+// RepoSpend is one repo's embedding spend.
+type RepoSpend struct {
+	Repo       string  `json:"repo"`
+	Runs       int     `json:"runs"`
+	FileTokens int     `json:"file_tokens"` // Of Tokens: single-file reindexes (watch --files, reindex_file)
+	Tokens     int     `json:"tokens"`
+	Cost       float64 `json:"cost"`
+}
+
+// RunSpend is one index run's embedding spend.
+type RunSpend struct {
+	Repo    string    `json:"repo"`
+	Run     time.Time `json:"run"` // When it started
+	Trigger string    `json:"trigger,omitempty"`
+	Model   string    `json:"model"`
+	Files   int       `json:"files"`
+	Chunks  int       `json:"chunks"`
+	Tokens  int       `json:"tokens"`
+	Cost    float64   `json:"cost"`
+}
+
+// Spend totals the embedding tokens of the index runs of a time period per
+// repo and per run, costing them at pricing (price per million tokens by
+// model). A missing log has none.
+func (a *Analyzer) Spend(since time.Duration, pricing map[string]float64) (*SpendReport, error) {
+	report := &SpendReport{Period: since.String()}
+	file, err := os.Open(a.logPath)
+	if os.IsNotExist(err) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	repos := make(map[string]*RepoSpend)
+	unpriced := make(map[string]bool)
+	cutoff := time.Now().Add(-since)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if eventType, _ := event["event"].(string); eventType != "index_run" {
+			continue
+		}
+		tsStr, _ := event["ts"].(string)
+		ts, err := time.Parse(time.RFC3339, tsStr)
+		if err != nil || ts.Before(cutoff) || a.otherRepo(event) {
+			continue
+		}
+
+		repo, _ := event["repo"].(string)
+		model, _ := event["model"].(string)
+		trigger, _ := event["trigger"].(string)
+		tokens, _ := event["tokens"].(float64)
+		price, ok := pricing[model]
+		if !ok {
+			unpriced[model] = true
+		}
+		cost := tokens / 1e6 * price
+
+		r, ok := repos[repo]
+		if !ok {
+			r = &RepoSpend{Repo: repo}
+			repos[repo] = r
+		}
+		r.Tokens += int(tokens)
+		r.Cost += cost
+		report.Tokens += int(tokens)
+		report.Cost += cost
+		if trigger == "file" {
+			r.FileTokens += int(tokens)
+			continue
+		}
+		r.Runs++
+
+		run := RunSpend{Repo: repo, Trigger: trigger, Model: model, Tokens: int(tokens), Cost: cost}
+		if runStr, _ := event["run"].(string); runStr != "" {
+			run.Run, _ = time.Parse(time.RFC3339, runStr)
+		}
+		files, _ := event["files"].(float64)
+		chunks, _ := event["chunks"].(float64)
+		run.Files, run.Chunks = int(files), int(chunks)
+		report.Runs = append(report.Runs, run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, r := range repos {
+		report.Repos = append(report.Repos, *r)
+	}
+	sort.Slice(report.Repos, func(i, j int) bool {
+		if report.Repos[i].Tokens != report.Repos[j].Tokens {
+			return report.Repos[i].Tokens > report.Repos[j].Tokens
+		}
+		return report.Repos[i].Repo < report.Repos[j].Repo
+	})
+	slices.Reverse(report.Runs)
+	for model := range unpriced {
+		report.Unpriced = append(report.Unpriced, model)
+	}
+	sort.Strings(report.Unpriced)
+	return report, nil
+}
@@ -70,6 +70,37 @@ func TestAnalyzerGetZeroResultQueries(t *testing.T) {
 	assert.Equal(t, 1, zeroResults[1].Count)
 }
 
+func TestAnalyzerTopQueries(t *testing.T) {
+	tmpDir := t.TempDir()
+	logPath := filepath.Join(tmpDir, "metrics.jsonl")
+
+	now := time.Now().UTC()
+	recentTS := now.Add(-1 * time.Hour).Format(time.RFC3339)
+	oldTS := now.Add(-48 * time.Hour).Format(time.RFC3339)
+
+	logData := `{"ts":"` + recentTS + `","event":"search","query":"auth flow","repo":"r3","results":5}
+{"ts":"` + recentTS + `","event":"search","query":"auth flow","repo":"r3","results":-1,"cache_hit":true}
+{"ts":"` + recentTS + `","event":"search","query":"session store","repo":"r3","results":2}
+{"ts":"` + recentTS + `","event":"search","query":"missing thing","repo":"r3","results":0}
+{"ts":"` + recentTS + `","event":"search","query":"billing","repo":"m32rimm","results":4}
+{"ts":"` + oldTS + `","event":"search","query":"old query","repo":"r3","results":3}
+`
+	require.NoError(t, os.WriteFile(logPath, []byte(logData), 0644))
+
+	analyzer := NewAnalyzer(logPath)
+	top, err := analyzer.TopQueries(24*time.Hour, "r3", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []QueryCount{{Query: "auth flow", Count: 2}, {Query: "session store", Count: 1}}, top)
+
+	top, err = analyzer.TopQueries(24*time.Hour, "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []QueryCount{{Query: "auth flow", Count: 2}, {Query: "billing", Count: 1}}, top)
+
+	top, err = NewAnalyzer(filepath.Join(tmpDir, "missing.jsonl")).TopQueries(24*time.Hour, "r3", 10)
+	require.NoError(t, err)
+	assert.Empty(t, top)
+}
+
 func TestAnalyzerEmptyFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	logPath := filepath.Join(tmpDir, "empty.jsonl")
@@ -87,3 +118,318 @@ func TestAnalyzerFileNotFound(t *testing.T) {
 	_, err := analyzer.Analyze(24 * time.Hour)
 	assert.Error(t, err)
 }
+
+func TestAnalyzerChunkFeedback(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	ts := time.Now().UTC().Format(time.RFC3339)
+	logData := `{"ts":"` + ts + `","event":"feedback","chunk_id":"a","useful":true}
+{"ts":"` + ts + `","event":"feedback","chunk_id":"a","useful":true}
+{"ts":"` + ts + `","event":"feedback","chunk_id":"b","useful":false}
+{"ts":"` + ts + `","event":"search","query":"q","results":1}
+`
+	require.NoError(t, os.WriteFile(logPath, []byte(logData), 0644))
+
+	analyzer := NewAnalyzer(logPath)
+	votes, err := analyzer.ChunkFeedback()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 2, "b": -1}, votes)
+
+	summary, err := analyzer.Analyze(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.FeedbackUseful)
+	assert.Equal(t, 1, summary.FeedbackNot)
+
+	votes, err = NewAnalyzer(filepath.Join(t.TempDir(), "missing.jsonl")).ChunkFeedback()
+	require.NoError(t, err)
+	assert.Empty(t, votes)
+}
+
+func TestAnalyzerCacheStats(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	logger, err := NewLogger(logPath)
+	require.NoError(t, err)
+	logger.LogCache("r3", true, 2*time.Millisecond, 4*time.Minute)
+	logger.LogCache("r3", true, 1*time.Millisecond, 9*time.Minute)
+	logger.LogCache("r3", false, 3*time.Millisecond, 0)
+	logger.LogCache("all", false, 6*time.Millisecond, 0)
+	logger.LogSearch("auth", "concept", "r3", 3, 120, false)
+	logger.LogSemanticCache("r3", true, 0.97, 40*time.Millisecond)
+	logger.LogSemanticCache("r3", false, 0.6, 40*time.Millisecond)
+	require.NoError(t, logger.Close())
+
+	stats, err := NewAnalyzer(logPath).CacheStats(24 * time.Hour)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, stats.Lookups)
+	assert.Equal(t, 2, stats.Hits)
+	assert.Equal(t, 2, stats.Misses)
+	assert.InDelta(t, 0.5, stats.HitRate, 1e-9)
+	assert.InDelta(t, 3.0, stats.AvgLatencyMs, 1e-9)
+	assert.InDelta(t, 6.0, stats.P95LatencyMs, 1e-9)
+	assert.Equal(t, int64(240), stats.MedianHitAgeS)
+	assert.Equal(t, int64(540), stats.P90HitAgeS)
+	assert.Equal(t, &CacheCounts{Hits: 2, Misses: 1}, stats.ByRepo["r3"])
+	assert.Equal(t, &CacheCounts{Misses: 1}, stats.ByRepo["all"])
+	assert.Equal(t, 2, stats.SemanticTried)
+	assert.Equal(t, 1, stats.SemanticHits)
+
+	empty, err := NewAnalyzer(filepath.Join(t.TempDir(), "missing.jsonl")).CacheStats(time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, empty.Lookups)
+}
+
+func TestAnalyzerRescues(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	now := time.Now().UTC()
+	ts := func(ago time.Duration) string { return now.Add(-ago).Format(time.RFC3339) }
+
+	logData := `{"ts":"` + ts(50*time.Minute) + `","event":"search","query":"athentication","results":0,"session":"s1","suggestions":["authentication"]}
+{"ts":"` + ts(49*time.Minute) + `","event":"search","query":"authentication flow","results":4,"session":"s1"}
+{"ts":"` + ts(45*time.Minute) + `","event":"search","query":"db pool","results":0,"session":"s2","suggestions":["database"]}
+{"ts":"` + ts(44*time.Minute) + `","event":"search","query":"db pool","results":-1,"cache_hit":true,"session":"s2"}
+{"ts":"` + ts(43*time.Minute) + `","event":"search","query":"connection pool","results":2,"session":"s2"}
+{"ts":"` + ts(40*time.Minute) + `","event":"search","query":"kafka lag","results":0,"session":"s3"}
+{"ts":"` + ts(20*time.Minute) + `","event":"search","query":"consumer lag","results":3,"session":"s3"}
+{"ts":"` + ts(10*time.Minute) + `","event":"search","query":"kafka lag","results":0,"session":"s1"}
+{"ts":"` + ts(5*time.Minute) + `","event":"search","query":"from the cli","results":0}
+`
+	require.NoError(t, os.WriteFile(logPath, []byte(logData), 0644))
+
+	stats, err := NewAnalyzer(logPath).Rescues(24 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.ZeroResults)
+	assert.Equal(t, 2, stats.Rescued)
+	assert.Equal(t, 1, stats.ViaSuggestion)
+	assert.Equal(t, 2, stats.Abandoned, "a success after the window and the last search of a session rescue nothing")
+	assert.Equal(t, 1, stats.Untracked)
+	assert.InDelta(t, 0.5, stats.RescueRate, 0.001)
+	assert.InDelta(t, 0.25, stats.SuggestionRate, 0.001)
+	assert.Equal(t, []QueryCount{{Query: "kafka lag", Count: 2}}, stats.Abandons)
+	assert.Equal(t, []Rescue{
+		{Query: "athentication", RescuedBy: "authentication flow", ViaSuggestion: true},
+		{Query: "db pool", RescuedBy: "connection pool"},
+	}, stats.Rescues)
+
+	missing, err := NewAnalyzer(filepath.Join(t.TempDir(), "none.jsonl")).Rescues(time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, missing.ZeroResults)
+}
+
+func TestAnalyzerModules(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	ts := time.Now().UTC().Format(time.RFC3339)
+
+	logData := `{"ts":"` + ts + `","event":"search","query":"a","repo":"r3","module":"billing","results":0}
+{"ts":"` + ts + `","event":"search","query":"b","repo":"r3","module":"billing","results":2}
+{"ts":"` + ts + `","event":"search","query":"c","repo":"r3","module":"billing","results":-1,"cache_hit":true}
+{"ts":"` + ts + `","event":"search","query":"d","repo":"r3","results":0}
+{"ts":"` + ts + `","event":"search","query":"e","repo":"other","module":"core","results":0}
+{"ts":"` + ts + `","event":"feedback","query":"b","repo":"other","useful":true}
+`
+	require.NoError(t, os.WriteFile(logPath, []byte(logData), 0644))
+
+	analyzer := NewAnalyzer(logPath)
+	modules, err := analyzer.Modules(time.Hour)
+	require.NoError(t, err)
+	require.Len(t, modules, 3)
+	assert.Equal(t, ModuleStats{Repo: "r3", Module: "billing", Searches: 3, ZeroResults: 1, ZeroResultRate: 1.0 / 3}, modules[0])
+	assert.Equal(t, ModuleStats{Repo: "other", Module: "core", Searches: 1, ZeroResults: 1, ZeroResultRate: 1}, modules[1])
+	assert.Equal(t, ModuleStats{Repo: "r3", Searches: 1, ZeroResults: 1, ZeroResultRate: 1}, modules[2])
+
+	analyzer.SetRepo("r3")
+	modules, err = analyzer.Modules(time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, modules, 2)
+
+	summary, err := analyzer.Analyze(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 4, summary.TotalSearches)
+	assert.Equal(t, 2, summary.ZeroResultCount)
+	assert.Zero(t, summary.FeedbackUseful, "feedback on other repos is left out")
+
+	zero, err := analyzer.GetZeroResultQueries(time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, zero, 2)
+
+	missing, err := NewAnalyzer(filepath.Join(t.TempDir(), "none.jsonl")).Modules(time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestAnalyzerFreshness(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	logger, err := NewLogger(logPath)
+	require.NoError(t, err)
+	now := time.Now()
+	for _, lag := range []time.Duration{30 * time.Second, 10 * time.Minute, 2 * time.Minute} {
+		logger.LogFreshness("r3", now.Add(-lag), now)
+	}
+	logger.LogFreshness("other", now.Add(time.Minute), now)
+	require.NoError(t, logger.Close())
+
+	analyzer := NewAnalyzer(logPath)
+	freshness, err := analyzer.Freshness(time.Hour)
+	require.NoError(t, err)
+	require.Len(t, freshness, 2)
+	assert.Equal(t, "other", freshness[0].Repo)
+	assert.Zero(t, freshness[0].MaxLagS, "commits after the sync never lag negatively")
+	r3 := freshness[1]
+	assert.Equal(t, 3, r3.Syncs)
+	assert.InDelta(t, 120, r3.LastLagS, 1)
+	assert.InDelta(t, 120, r3.P50LagS, 1)
+	assert.InDelta(t, 600, r3.P95LagS, 1)
+	assert.InDelta(t, 600, r3.MaxLagS, 1)
+
+	analyzer.SetRepo("r3")
+	freshness, err = analyzer.Freshness(time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, freshness, 1)
+}
+
+func TestAnalyzerLatencies(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	logger, err := NewLogger(logPath)
+	require.NoError(t, err)
+	for i := 1; i <= 100; i++ {
+		logger.LogSearchEvent(SearchEvent{Query: "q", Repo: "r3", Results: 1, LatencyMs: int64(i * 10), Stages: map[string]float64{"embed": float64(i), "store": float64(2 * i), "rewrite": 1}})
+	}
+	logger.LogSearchEvent(SearchEvent{Query: "q", Repo: "r3", Results: -1, LatencyMs: 1, CacheHit: true})
+	logger.LogSearchEvent(SearchEvent{Query: "q", Repo: "other", Results: 1, LatencyMs: 5000})
+	require.NoError(t, logger.Close())
+
+	analyzer := NewAnalyzer(logPath)
+	analyzer.SetRepo("r3")
+	stats, err := analyzer.Latencies(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 101, stats.Total.Count)
+	assert.Equal(t, 100, stats.Uncached.Count)
+	assert.Equal(t, Percentiles{Count: 100, P50: 500, P95: 950, P99: 990, Max: 1000}, stats.Uncached)
+	assert.Equal(t, Percentiles{Count: 100, P50: 50, P95: 95, P99: 99, Max: 100}, stats.Stages["embed"])
+	assert.Equal(t, 190.0, stats.Stages["store"].P95)
+	assert.Equal(t, []string{"embed", "store", "rewrite"}, stats.StageNames())
+
+	missing, err := NewAnalyzer(filepath.Join(t.TempDir(), "none.jsonl")).Latencies(time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, missing.Total.Count)
+}
+
+func TestAnalyzerExperiment(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	logger, err := NewLogger(logPath)
+	require.NoError(t, err)
+	logger.LogSearchEvent(SearchEvent{Query: "old", Results: 1, LatencyMs: 10, Experiment: "old", Variant: "control"})
+	for _, q := range []string{"a", "b"} {
+		logger.LogSearchEvent(SearchEvent{Query: q, Results: 4, LatencyMs: 100, Experiment: "dense", Variant: "control"})
+	}
+	logger.LogSearchEvent(SearchEvent{Query: "a", Results: -1, LatencyMs: 1, CacheHit: true, Experiment: "dense", Variant: "control"})
+	logger.LogSearchEvent(SearchEvent{Query: "c", Results: 0, LatencyMs: 50, Experiment: "dense", Variant: "semantic"})
+	logger.LogSearchEvent(SearchEvent{Query: "d", Results: 2, LatencyMs: 70, Experiment: "dense", Variant: "semantic"})
+	logger.LogSearchEvent(SearchEvent{Query: "untagged", Results: 3, LatencyMs: 5})
+	logger.LogFeedback("a", "chunk-1", "r3", "auth.py", true)
+	logger.LogFeedback("d", "chunk-2", "r3", "db.py", false)
+	logger.LogFeedback("untagged", "chunk-3", "r3", "x.py", true)
+	require.NoError(t, logger.Close())
+
+	report, err := NewAnalyzer(logPath).Experiment(time.Hour, "")
+	require.NoError(t, err)
+	assert.Equal(t, "dense", report.Name, "the latest experiment by default")
+	require.Len(t, report.Arms, 2)
+
+	control, variant := report.Arms[0], report.Arms[1]
+	assert.Equal(t, "control", control.Variant)
+	assert.Equal(t, 3, control.Searches)
+	assert.Equal(t, 1, control.CacheHits)
+	assert.Equal(t, 4.0, control.AvgResults)
+	assert.Equal(t, 100.0, control.Latency.P50)
+	assert.Equal(t, 1, control.Useful)
+	assert.Equal(t, 1.0, control.UsefulRate)
+
+	assert.Equal(t, "semantic", variant.Variant)
+	assert.Equal(t, 0.5, variant.ZeroResultRate)
+	assert.Equal(t, 1, variant.NotUseful)
+
+	old, err := NewAnalyzer(logPath).Experiment(time.Hour, "old")
+	require.NoError(t, err)
+	require.Len(t, old.Arms, 1)
+	assert.Equal(t, 1, old.Arms[0].Searches)
+
+	none, err := NewAnalyzer(logPath).Experiment(time.Hour, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, none.Arms)
+}
+
+func TestAnalyzerUsage(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	logger, err := NewLogger(logPath)
+	require.NoError(t, err)
+	logger.LogSearchEvent(SearchEvent{Query: "auth flow", QueryType: "concept", Results: 4, Session: "s1"})
+	logger.LogSearchEvent(SearchEvent{Query: "LoginView", QueryType: "symbol", Results: 2, Session: "s1", Strategy: "symbol"})
+	logger.LogUsed("Auth Flow", "s1", "r3", "auth.py", "")
+	logger.LogUsed("auth flow", "s1", "r3", "auth.py", "chunk-1") // Same file again
+	logger.LogUsed("auth flow", "s1", "r3", "session.py", "")
+	logger.LogUsed("", "s1", "r3", "views.py", "") // The session's latest search
+	logger.LogSearchEvent(SearchEvent{Query: "db pool", QueryType: "concept", Results: -1, CacheHit: true, Session: "s2"})
+	logger.LogUsed("", "s2", "r3", "pool.py", "")
+	logger.LogUsed("", "s3", "r3", "x.py", "") // No search in s3
+	require.NoError(t, logger.Close())
+
+	stats, err := NewAnalyzer(logPath).Usage(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 6, stats.Used)
+	assert.Equal(t, 1, stats.Unattributed)
+	require.Len(t, stats.Strategies, 2)
+
+	auto := stats.Strategies[0]
+	assert.Equal(t, "auto:concept", auto.Strategy)
+	assert.Equal(t, 2, auto.Searches)
+	assert.Equal(t, 2, auto.SearchesUsed)
+	assert.Equal(t, 1.0, auto.UseRate)
+	assert.Equal(t, 4, auto.Results, "cache hits have no result count")
+	assert.Equal(t, 2, auto.Opened)
+	assert.Equal(t, 0.5, auto.Precision)
+
+	symbol := stats.Strategies[1]
+	assert.Equal(t, "symbol", symbol.Strategy)
+	assert.Equal(t, 1, symbol.Opened)
+	assert.Equal(t, 0.5, symbol.Precision)
+}
+
+func TestAnalyzerSpend(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	logger, err := NewLogger(logPath)
+	require.NoError(t, err)
+	started := time.Now().Add(-10 * time.Minute)
+	logger.LogIndexRun(IndexRunEvent{Repo: "r3", Run: started, Trigger: "cli", Model: "voyage-4-large", Tokens: 2_000_000, Files: 40, Chunks: 300})
+	logger.LogIndexRun(IndexRunEvent{Repo: "m32rimm", Run: started.Add(time.Minute), Trigger: "watch", Model: "voyage-4-large", Tokens: 500_000, Files: 3, Chunks: 20})
+	logger.LogIndexRun(IndexRunEvent{Repo: "r3", Run: started.Add(2 * time.Minute), Trigger: "file", Model: "voyage-4-large", Tokens: 1_000})
+	logger.LogIndexRun(IndexRunEvent{Repo: "m32rimm", Run: started.Add(3 * time.Minute), Trigger: "cli", Model: "voyage-code-3", Tokens: 100_000})
+	require.NoError(t, logger.Close())
+
+	report, err := NewAnalyzer(logPath).Spend(time.Hour, map[string]float64{"voyage-4-large": 0.12})
+	require.NoError(t, err)
+	assert.Equal(t, 2_601_000, report.Tokens)
+	assert.InDelta(t, 0.30012, report.Cost, 1e-9)
+	assert.Equal(t, []string{"voyage-code-3"}, report.Unpriced)
+
+	require.Len(t, report.Repos, 2)
+	assert.Equal(t, "r3", report.Repos[0].Repo)
+	assert.Equal(t, 1, report.Repos[0].Runs, "file reindexes are not runs")
+	assert.Equal(t, 1_000, report.Repos[0].FileTokens)
+	assert.Equal(t, 2_001_000, report.Repos[0].Tokens)
+	assert.Equal(t, 2, report.Repos[1].Runs)
+	assert.InDelta(t, 0.06, report.Repos[1].Cost, 1e-9)
+
+	require.Len(t, report.Runs, 3)
+	assert.Equal(t, "voyage-code-3", report.Runs[0].Model, "most recent first")
+	assert.Zero(t, report.Runs[0].Cost)
+	assert.Equal(t, "r3", report.Runs[2].Repo)
+	assert.Equal(t, 40, report.Runs[2].Files)
+	assert.Equal(t, started.Unix(), report.Runs[2].Run.Unix())
+
+	scoped := NewAnalyzer(logPath)
+	scoped.SetRepo("m32rimm")
+	report, err = scoped.Spend(time.Hour, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 600_000, report.Tokens)
+	assert.Len(t, report.Unpriced, 2)
+}
@@ -2,6 +2,8 @@
 package metrics
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"os"
 	"sync"
@@ -46,17 +48,136 @@ func (l *Logger) log(event string, data map[string]interface{}) {
 	l.file.Write([]byte("\n"))
 }
 
-// LogSearch logs a search query event.
-func (l *Logger) LogSearch(query, queryType string, results int, latencyMs int64, cacheHit bool) {
-	l.log("search", map[string]interface{}{
-		"query":      query,
-		"query_type": queryType,
-		"results":    results,
-		"latency_ms": latencyMs,
-		"cache_hit":  cacheHit,
+// LogSearch logs a search query event. repo is the repo searched, "all" or
+// empty across repos.
+func (l *Logger) LogSearch(query, queryType, repo string, results int, latencyMs int64, cacheHit bool) {
+	l.LogSearchEvent(SearchEvent{Query: query, QueryType: queryType, Repo: repo, Results: results, LatencyMs: latencyMs, CacheHit: cacheHit})
+}
+
+// SearchEvent is a search logged by LogSearchEvent.
+type SearchEvent struct {
+	Query       string
+	QueryType   string
+	Repo        string // "all" or empty across repos
+	Module      string // Module filter of the search; empty for the whole repo
+	Results     int    // -1 on a cache hit
+	LatencyMs   int64
+	CacheHit    bool
+	Session     string             // Client session the search was made in; empty outside one
+	Suggestions []string           // Terms suggested with zero results
+	Stages      map[string]float64 // Milliseconds per search stage (embed, store, graph, format)
+	Experiment  string             // Experiment the search was part of; empty outside one
+	Variant     string             // Its arm: "control" or the strategy tried
+	Strategy    string             // Retrieval the caller chose or the experiment routed to; empty when classified
+}
+
+// LogSearchEvent logs a search like LogSearch, plus its session and the
+// suggestions offered, so Analyzer.Rescues can follow up zero-result
+// searches. The session is logged hashed: MCP session IDs double as HTTP
+// session handles.
+func (l *Logger) LogSearchEvent(e SearchEvent) {
+	data := map[string]interface{}{
+		"query":      e.Query,
+		"query_type": e.QueryType,
+		"repo":       e.Repo,
+		"results":    e.Results,
+		"latency_ms": e.LatencyMs,
+		"cache_hit":  e.CacheHit,
+	}
+	if e.Module != "" {
+		data["module"] = e.Module
+	}
+	if e.Session != "" {
+		data["session"] = hashSession(e.Session)
+	}
+	if len(e.Suggestions) > 0 {
+		data["suggestions"] = e.Suggestions
+	}
+	if len(e.Stages) > 0 {
+		data["stages"] = e.Stages
+	}
+	if e.Experiment != "" {
+		data["experiment"] = e.Experiment
+		data["variant"] = e.Variant
+	}
+	if e.Strategy != "" {
+		data["strategy"] = e.Strategy
+	}
+	l.log("search", data)
+}
+
+// hashSession is the logged form of a session ID.
+func hashSession(session string) string {
+	sum := sha256.Sum256([]byte(session))
+	return hex.EncodeToString(sum[:8])
+}
+
+// LogCache logs a query cache lookup: whether it hit, how long the lookup
+// took, and for hits how long ago the entry was cached (0 when unknown).
+func (l *Logger) LogCache(repo string, hit bool, latency, age time.Duration) {
+	data := map[string]interface{}{
+		"repo":       repo,
+		"hit":        hit,
+		"latency_ms": float64(latency.Microseconds()) / 1000,
+	}
+	if hit && age > 0 {
+		data["age_s"] = int64(age.Seconds())
+	}
+	l.log("cache", data)
+}
+
+// LogSemanticCache logs a semantic cache lookup after an exact miss:
+// whether a similar enough query's response was served, the best
+// similarity found, and how long embedding and comparing took.
+func (l *Logger) LogSemanticCache(repo string, hit bool, similarity float64, latency time.Duration) {
+	l.log("cache", map[string]interface{}{
+		"repo":       repo,
+		"hit":        hit,
+		"semantic":   true,
+		"similarity": similarity,
+		"latency_ms": float64(latency.Microseconds()) / 1000,
 	})
 }
 
+// LogFreshness logs a completed sync of repo: the commit time of the HEAD
+// indexed and how long after it the index was up to date.
+func (l *Logger) LogFreshness(repo string, commitTime, indexedAt time.Time) {
+	l.log("freshness", map[string]interface{}{
+		"repo":      repo,
+		"commit_ts": commitTime.UTC().Format(time.RFC3339),
+		"lag_s":     max(0, indexedAt.Sub(commitTime).Seconds()),
+	})
+}
+
+// LogFeedback logs a search_feedback vote on a result chunk.
+func (l *Logger) LogFeedback(query, chunkID, repo, filePath string, useful bool) {
+	l.log("feedback", map[string]interface{}{
+		"query":     query,
+		"chunk_id":  chunkID,
+		"repo":      repo,
+		"file_path": filePath,
+		"useful":    useful,
+	})
+}
+
+// LogUsed logs a mark_used call: the client opened filePath, returned by
+// query (empty when not given) in session. Analyzer.Usage attributes it to
+// the session's search, hashing the session as LogSearchEvent does.
+func (l *Logger) LogUsed(query, session, repo, filePath, chunkID string) {
+	data := map[string]interface{}{
+		"query":     query,
+		"repo":      repo,
+		"file_path": filePath,
+	}
+	if session != "" {
+		data["session"] = hashSession(session)
+	}
+	if chunkID != "" {
+		data["chunk_id"] = chunkID
+	}
+	l.log("used", data)
+}
+
 // LogContextInject logs a context injection event.
 func (l *Logger) LogContextInject(file string, suggestions int, confidence float64) {
 	l.log("context_inject", map[string]interface{}{
@@ -74,6 +195,32 @@ func (l *Logger) LogFileRead(file string, wasSuggested bool) {
 	})
 }
 
+// IndexRunEvent is the embedding spend of an index run, logged by
+// LogIndexRun.
+type IndexRunEvent struct {
+	Repo    string
+	Run     time.Time // When the run started; identifies it
+	Trigger string    // What started it ("cli", "watch", ...); "file" for a single-file reindex
+	Model   string    // Embedding model
+	Tokens  int       // Billed by the embedding API
+	Files   int
+	Chunks  int
+}
+
+// LogIndexRun logs the embedding tokens an index run used, so
+// Analyzer.Spend can total them per repo and run.
+func (l *Logger) LogIndexRun(e IndexRunEvent) {
+	l.log("index_run", map[string]interface{}{
+		"repo":    e.Repo,
+		"run":     e.Run.UTC().Format(time.RFC3339),
+		"trigger": e.Trigger,
+		"model":   e.Model,
+		"tokens":  e.Tokens,
+		"files":   e.Files,
+		"chunks":  e.Chunks,
+	})
+}
+
 // LogIndexUpdate logs an index update event.
 func (l *Logger) LogIndexUpdate(repo string, filesChanged, chunksUpdated int) {
 	l.log("index_update", map[string]interface{}{
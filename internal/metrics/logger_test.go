@@ -19,7 +19,7 @@ func TestMetricsLogger(t *testing.T) {
 	defer logger.Close()
 
 	// Log a search event
-	logger.LogSearch("auth timeout", "concept", 5, 120, false)
+	logger.LogSearch("auth timeout", "concept", "r3", 5, 120, false)
 
 	// Log a context inject event
 	logger.LogContextInject("auth.js", 3, 0.82)
@@ -73,7 +73,7 @@ func TestMetricsLoggerConcurrent(t *testing.T) {
 	done := make(chan bool, 10)
 	for i := 0; i < 10; i++ {
 		go func(n int) {
-			logger.LogSearch("query", "concept", n, int64(n*10), false)
+			logger.LogSearch("query", "concept", "", n, int64(n*10), false)
 			done <- true
 		}(i)
 	}
@@ -90,3 +90,33 @@ func TestMetricsLoggerConcurrent(t *testing.T) {
 	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
 	assert.Len(t, lines, 10)
 }
+
+func TestLogSearchEventHashesSession(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	logger, err := NewLogger(logPath)
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.LogSearchEvent(SearchEvent{
+		Query:       "athentication",
+		Repo:        "r3",
+		Module:      "billing",
+		Results:     0,
+		Session:     "mcp-session-secret",
+		Suggestions: []string{"authentication"},
+	})
+	logger.LogSearch("no session", "", "", 1, 5, false)
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	content := string(data)
+	assert.NotContains(t, content, "mcp-session-secret", "session IDs are HTTP handles and must not be logged raw")
+	assert.Contains(t, content, `"session":"`)
+	assert.Contains(t, content, `"suggestions":["authentication"]`)
+	assert.Contains(t, content, `"module":"billing"`)
+
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	require.Len(t, lines, 2)
+	assert.NotContains(t, lines[1], `"session"`)
+	assert.NotContains(t, lines[1], `"module"`, "whole-repo searches log no module")
+}
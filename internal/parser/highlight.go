@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// TokenClass categorizes a span of source for syntax highlighting.
+type TokenClass string
+
+const (
+	TokenKeyword  TokenClass = "keyword"
+	TokenString   TokenClass = "string"
+	TokenComment  TokenClass = "comment"
+	TokenNumber   TokenClass = "number"
+	TokenConstant TokenClass = "constant" // true, None, null, ...
+	TokenFunction TokenClass = "function" // Defined or called
+	TokenType     TokenClass = "type"     // Class names
+)
+
+// Token is a highlighted byte range of the source.
+type Token struct {
+	Start int
+	End   int
+	Class TokenClass
+}
+
+// Highlight parses source and returns its highlighted spans in source order.
+// Text between tokens is plain. Syntax errors still yield tokens for the
+// parts tree-sitter recovered.
+func (p *Parser) Highlight(source []byte) ([]Token, error) {
+	tree, err := p.parser.ParseCtx(context.Background(), nil, source)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+	defer tree.Close()
+
+	var tokens []Token
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		if class, ok := classifyNode(node); ok {
+			if node.EndByte() > node.StartByte() {
+				tokens = append(tokens, Token{Start: int(node.StartByte()), End: int(node.EndByte()), Class: class})
+			}
+			return
+		}
+		for i := 0; i < int(node.ChildCount()); i++ {
+			walk(node.Child(i))
+		}
+	}
+	walk(tree.RootNode())
+
+	return tokens, nil
+}
+
+// classifyNode returns the class of a node that is highlighted as a whole.
+// Strings and comments are not descended into.
+func classifyNode(node *sitter.Node) (TokenClass, bool) {
+	switch node.Type() {
+	case "comment":
+		return TokenComment, true
+	case "string", "template_string":
+		return TokenString, true
+	case "integer", "float", "number":
+		return TokenNumber, true
+	case "true", "false", "none", "null", "undefined":
+		return TokenConstant, true
+	case "identifier", "property_identifier", "type_identifier":
+		return classifyName(node)
+	}
+
+	// Anonymous word nodes are the grammar's keywords (def, return, const, ...)
+	if !node.IsNamed() && isWord(node.Type()) {
+		return TokenKeyword, true
+	}
+	return "", false
+}
+
+// classifyName highlights the names of definitions and call targets.
+func classifyName(node *sitter.Node) (TokenClass, bool) {
+	parent := node.Parent()
+	if parent == nil {
+		return "", false
+	}
+
+	switch parent.Type() {
+	case "function_definition", "function_declaration", "generator_function_declaration", "method_definition":
+		if isField(parent, "name", node) {
+			return TokenFunction, true
+		}
+	case "class_definition", "class_declaration", "class":
+		if isField(parent, "name", node) {
+			return TokenType, true
+		}
+	case "call", "call_expression":
+		if isField(parent, "function", node) {
+			return TokenFunction, true
+		}
+	case "attribute", "member_expression":
+		// obj.method() highlights method
+		grandparent := parent.Parent()
+		if grandparent != nil && isField(grandparent, "function", parent) &&
+			(isField(parent, "attribute", node) || isField(parent, "property", node)) {
+			return TokenFunction, true
+		}
+	}
+	return "", false
+}
+
+func isField(parent *sitter.Node, field string, node *sitter.Node) bool {
+	child := parent.ChildByFieldName(field)
+	return child != nil && child.StartByte() == node.StartByte() && child.EndByte() == node.EndByte()
+}
+
+func isWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// highlighted maps each token's text to its class.
+func highlighted(t *testing.T, lang Language, code string) map[string]TokenClass {
+	t.Helper()
+	p, err := NewParser(lang)
+	require.NoError(t, err)
+
+	tokens, err := p.Highlight([]byte(code))
+	require.NoError(t, err)
+
+	got := make(map[string]TokenClass)
+	prevEnd := 0
+	for _, tok := range tokens {
+		require.GreaterOrEqual(t, tok.Start, prevEnd, "tokens are ordered and don't overlap")
+		prevEnd = tok.End
+		got[code[tok.Start:tok.End]] = tok.Class
+	}
+	return got
+}
+
+func TestHighlightPython(t *testing.T) {
+	code := `class Cart(Base):
+    def total(self, items):
+        # Sum prices
+        if not items:
+            return None
+        return sum(i.price for i in items) * 1.2 + len("abc")
+`
+	got := highlighted(t, LanguagePython, code)
+
+	assert.Equal(t, TokenKeyword, got["class"])
+	assert.Equal(t, TokenType, got["Cart"])
+	assert.Equal(t, TokenKeyword, got["def"])
+	assert.Equal(t, TokenFunction, got["total"])
+	assert.Equal(t, TokenComment, got["# Sum prices"])
+	assert.Equal(t, TokenKeyword, got["not"])
+	assert.Equal(t, TokenConstant, got["None"])
+	assert.Equal(t, TokenFunction, got["sum"])
+	assert.Equal(t, TokenNumber, got["1.2"])
+	assert.Equal(t, TokenString, got[`"abc"`])
+	assert.NotContains(t, got, "items", "plain identifiers aren't highlighted")
+	assert.NotContains(t, got, "abc", "strings are highlighted whole")
+}
+
+func TestHighlightJavaScript(t *testing.T) {
+	code := "// helper\nfunction load(id) {\n  const user = api.fetch(`/users/${id}`);\n  return user ?? null;\n}\n"
+	got := highlighted(t, LanguageJavaScript, code)
+
+	assert.Equal(t, TokenComment, got["// helper"])
+	assert.Equal(t, TokenKeyword, got["function"])
+	assert.Equal(t, TokenFunction, got["load"])
+	assert.Equal(t, TokenKeyword, got["const"])
+	assert.Equal(t, TokenFunction, got["fetch"])
+	assert.Equal(t, TokenString, got["`/users/${id}`"])
+	assert.Equal(t, TokenConstant, got["null"])
+	assert.NotContains(t, got, "api")
+}
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/javascript"
 )
@@ -76,6 +78,7 @@ func extractJSFunction(node *sitter.Node, source []byte, filePath string) Symbol
 		StartLine: int(node.StartPoint().Row) + 1,
 		EndLine:   int(node.EndPoint().Row) + 1,
 		Content:   nodeContent(node, source),
+		Docstring: jsDocstring(node, source),
 	}
 }
 
@@ -92,6 +95,7 @@ func extractJSClass(node *sitter.Node, source []byte, filePath string) Symbol {
 		StartLine: int(node.StartPoint().Row) + 1,
 		EndLine:   int(node.EndPoint().Row) + 1,
 		Content:   nodeContent(node, source),
+		Docstring: jsDocstring(node, source),
 	}
 }
 
@@ -112,6 +116,43 @@ func extractJSMethod(
 		StartLine: int(node.StartPoint().Row) + 1,
 		EndLine:   int(node.EndPoint().Row) + 1,
 		Content:   nodeContent(node, source),
+		Docstring: jsDocstring(node, source),
 		Parent:    parent,
 	}
 }
+
+// javaScriptModuleDoc returns the comments at the top of the file, before
+// any code, without comment markers. A JSDoc block directly above the first
+// declaration documents it instead.
+func javaScriptModuleDoc(root *sitter.Node, source []byte) string {
+	var lines []string
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		node := root.NamedChild(i)
+		if node.Type() == "hash_bang_line" {
+			continue
+		}
+		if node.Type() != "comment" {
+			break
+		}
+		if next := root.NamedChild(i + 1); next != nil && isJSDeclaration(next) && documents(node, next, source) {
+			break // The first declaration's JSDoc
+		}
+		lines = append(lines, commentText(nodeContent(node, source))...)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// commentText strips the markers from a // or /* */ comment.
+func commentText(comment string) []string {
+	if text, ok := strings.CutPrefix(comment, "//"); ok {
+		return []string{strings.TrimSpace(text)}
+	}
+	comment = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(comment, "/*"), "*"), "*/")
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// jsDocstring returns the JSDoc/TSDoc block documenting a declaration: a
+// /** */ comment ending on the line before it, or before the export
+// statement wrapping it. Its tags are rendered by formatJSDoc.
+func jsDocstring(node *sitter.Node, source []byte) string {
+	if parent := node.Parent(); parent != nil && parent.Type() == "export_statement" {
+		node = parent
+	}
+	comment := node.PrevSibling()
+	if comment == nil || !documents(comment, node, source) {
+		return ""
+	}
+	return formatJSDoc(nodeContent(comment, source))
+}
+
+// documents reports whether comment is a /** */ block directly above node,
+// with no blank line between them.
+func documents(comment, node *sitter.Node, source []byte) bool {
+	return comment.Type() == "comment" &&
+		strings.HasPrefix(nodeContent(comment, source), "/**") &&
+		node.StartPoint().Row-comment.EndPoint().Row <= 1
+}
+
+// isJSDeclaration reports whether node declares a symbol jsDocstring
+// documents: a function or class, exported or not.
+func isJSDeclaration(node *sitter.Node) bool {
+	if node.Type() == "export_statement" {
+		return findChild(node, "function_declaration") != nil || findChild(node, "class_declaration") != nil
+	}
+	return node.Type() == "function_declaration" || node.Type() == "class_declaration"
+}
+
+// jsDocTag is a block tag of a JSDoc comment, e.g. @param {string} name - desc.
+type jsDocTag struct {
+	name string
+	text string
+}
+
+// formatJSDoc renders a /** */ comment as a docstring: the description,
+// then its parameters, return value, and other block tags, one per line:
+//
+//	Looks up a user.
+//
+//	Parameters:
+//	  id (string): The user's ID
+//	  opts (object, optional): Lookup options
+//	Returns (Promise<User>): The user
+//	@throws {NotFoundError} When there is none
+func formatJSDoc(comment string) string {
+	var description []string
+	var tags []jsDocTag
+	for _, line := range commentText(comment) {
+		if strings.HasPrefix(line, "@") {
+			name, text, _ := strings.Cut(line, " ")
+			tags = append(tags, jsDocTag{name: name, text: strings.TrimSpace(text)})
+			continue
+		}
+		if len(tags) > 0 {
+			// Continues the previous tag
+			if line != "" {
+				tags[len(tags)-1].text = strings.TrimSpace(tags[len(tags)-1].text + " " + line)
+			}
+			continue
+		}
+		description = append(description, line)
+	}
+
+	var params, returns, other []string
+	for _, tag := range tags {
+		switch tag.name {
+		case "@param", "@arg", "@argument":
+			params = append(params, "  "+formatJSDocParam(tag.text))
+		case "@returns", "@return":
+			typ, desc := jsDocType(tag.text)
+			line := "Returns"
+			if typ != "" {
+				line += " (" + typ + ")"
+			}
+			if desc = strings.TrimSpace(strings.TrimPrefix(desc, "-")); desc != "" {
+				line += ": " + desc
+			}
+			returns = append(returns, line)
+		default:
+			other = append(other, strings.TrimSpace(tag.name+" "+tag.text))
+		}
+	}
+
+	doc := strings.TrimSpace(strings.Join(description, "\n"))
+	var sections []string
+	if len(params) > 0 {
+		sections = append(sections, "Parameters:\n"+strings.Join(params, "\n"))
+	}
+	sections = append(sections, returns...)
+	sections = append(sections, other...)
+	if len(sections) > 0 {
+		if doc != "" {
+			doc += "\n\n"
+		}
+		doc += strings.Join(sections, "\n")
+	}
+	return doc
+}
+
+// formatJSDocParam renders the text of a @param tag, "{type} name - desc"
+// in JSDoc or "name - desc" in TSDoc, as "name (type): desc". Bracketed
+// names ([name] or [name=default]) are optional.
+func formatJSDocParam(text string) string {
+	typ, rest := jsDocType(text)
+	name, desc, _ := strings.Cut(rest, " ")
+	desc = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(desc), "-"))
+
+	var attrs []string
+	if typ != "" {
+		attrs = append(attrs, typ)
+	}
+	if inner, ok := strings.CutPrefix(name, "["); ok {
+		inner = strings.TrimSuffix(inner, "]")
+		name, _, _ = strings.Cut(inner, "=")
+		attrs = append(attrs, "optional")
+	}
+	if len(attrs) > 0 {
+		name += " (" + strings.Join(attrs, ", ") + ")"
+	}
+	if desc == "" {
+		return name
+	}
+	return name + ": " + desc
+}
+
+// jsDocType splits a leading {type} off a tag's text. Types may nest
+// braces, e.g. {{id: string}}.
+func jsDocType(text string) (typ, rest string) {
+	if !strings.HasPrefix(text, "{") {
+		return "", text
+	}
+	depth := 0
+	for i, r := range text {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return text[1:i], strings.TrimSpace(text[i+1:])
+			}
+		}
+	}
+	return "", text
+}
@@ -163,3 +163,77 @@ func TestUnsupportedLanguage(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unsupported language")
 }
+
+func TestParseJavaScriptJSDoc(t *testing.T) {
+	code := `/**
+ * User lookups.
+ */
+
+/**
+ * Looks up a user
+ * by ID.
+ *
+ * @param {string} id - The user's ID
+ * @param {{cache: boolean}} [opts={}] Lookup options,
+ *   cached by default
+ * @returns {Promise<User>} The user
+ * @throws {NotFoundError} When there is none
+ */
+export async function findUser(id, opts) {
+    return db.get(id);
+}
+
+/** A user account. */
+class User {
+    /**
+     * Greets someone.
+     * @param name - Who to greet
+     * @returns The greeting
+     */
+    greet(name) {
+        return "Hello, " + name;
+    }
+
+    // Not JSDoc
+    leave() {}
+}
+
+/** Detached. */
+
+function undocumented() {}
+`
+	p, err := NewParser(LanguageTypeScript)
+	require.NoError(t, err)
+
+	result, err := p.ParseWithRelationships([]byte(code), "users.ts")
+	require.NoError(t, err)
+	assert.Equal(t, "User lookups.", result.ModuleDoc)
+
+	docs := map[string]string{}
+	for _, sym := range result.Symbols {
+		docs[sym.Name] = sym.Docstring
+	}
+	assert.Equal(t, `Looks up a user
+by ID.
+
+Parameters:
+  id (string): The user's ID
+  opts ({cache: boolean}, optional): Lookup options, cached by default
+Returns (Promise<User>): The user
+@throws {NotFoundError} When there is none`, docs["findUser"])
+	assert.Equal(t, "A user account.", docs["User"])
+	assert.Equal(t, "Greets someone.\n\nParameters:\n  name: Who to greet\nReturns: The greeting", docs["greet"])
+	assert.Empty(t, docs["leave"])
+	assert.Empty(t, docs["undocumented"], "a blank line detaches the comment")
+}
+
+func TestJavaScriptModuleDocSkipsDeclarationJSDoc(t *testing.T) {
+	p, err := NewParser(LanguageJavaScript)
+	require.NoError(t, err)
+
+	result, err := p.ParseWithRelationships([]byte("/** Adds. */\nfunction add(a, b) { return a + b; }\n"), "add.js")
+	require.NoError(t, err)
+	assert.Empty(t, result.ModuleDoc)
+	require.Len(t, result.Symbols, 1)
+	assert.Equal(t, "Adds.", result.Symbols[0].Docstring)
+}
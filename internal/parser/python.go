@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/python"
 )
@@ -164,6 +166,25 @@ func nodeContent(node *sitter.Node, source []byte) string {
 	return string(source[node.StartByte():node.EndByte()])
 }
 
+// pythonModuleDoc returns the module docstring: a string as the first
+// statement of the file, after any comments.
+func pythonModuleDoc(root *sitter.Node, source []byte) string {
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		stmt := root.NamedChild(i)
+		if stmt.Type() == "comment" {
+			continue
+		}
+		if stmt.Type() != "expression_statement" {
+			return ""
+		}
+		if str := findChild(stmt, "string"); str != nil {
+			return strings.TrimSpace(cleanDocstring(nodeContent(str, source)))
+		}
+		return ""
+	}
+	return ""
+}
+
 func cleanDocstring(s string) string {
 	// Remove triple quotes
 	if len(s) >= 6 && (s[:3] == `"""` || s[:3] == `'''`) {
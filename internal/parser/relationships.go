@@ -30,6 +30,7 @@ type Relationship struct {
 type ParseResult struct {
 	Symbols       []Symbol
 	Relationships []Relationship
+	ModuleDoc     string // Module docstring (Python) or leading comment (JS/TS)
 }
 
 // ParseWithRelationships parses source and extracts both symbols and relationships.
@@ -42,19 +43,23 @@ func (p *Parser) ParseWithRelationships(source []byte, filePath string) (*ParseR
 
 	var symbols []Symbol
 	var relationships []Relationship
+	var moduleDoc string
 
 	switch p.language {
 	case LanguagePython:
 		symbols, _ = extractPythonSymbols(tree.RootNode(), source, filePath)
 		relationships = extractPythonRelationships(tree.RootNode(), source, filePath)
+		moduleDoc = pythonModuleDoc(tree.RootNode(), source)
 	case LanguageJavaScript, LanguageTypeScript:
 		symbols, _ = extractJavaScriptSymbols(tree.RootNode(), source, filePath)
 		relationships = extractJavaScriptRelationships(tree.RootNode(), source, filePath)
+		moduleDoc = javaScriptModuleDoc(tree.RootNode(), source)
 	}
 
 	return &ParseResult{
 		Symbols:       symbols,
 		Relationships: relationships,
+		ModuleDoc:     moduleDoc,
 	}, nil
 }
 
@@ -203,3 +203,27 @@ func extractTargetPaths(rels []Relationship) []string {
 	}
 	return paths
 }
+
+func TestParseModuleDoc(t *testing.T) {
+	tests := []struct {
+		name   string
+		lang   Language
+		source string
+		want   string
+	}{
+		{"python docstring", LanguagePython, "# -*- coding: utf-8 -*-\n\"\"\"AWS import helpers.\n\nWraps boto3.\"\"\"\nimport boto3\n", "AWS import helpers.\n\nWraps boto3."},
+		{"python without docstring", LanguagePython, "import os\n\"\"\"Not a module docstring.\"\"\"\n", ""},
+		{"javascript block comment", LanguageJavaScript, "/**\n * API client.\n * Retries on 429.\n */\nimport x from 'x';\n", "API client.\nRetries on 429."},
+		{"javascript line comments", LanguageTypeScript, "#!/usr/bin/env node\n// CLI entry point.\n// Parses flags.\nmain();\n", "CLI entry point.\nParses flags."},
+		{"javascript trailing comment", LanguageJavaScript, "main();\n// Not a header.\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewParser(tt.lang)
+			require.NoError(t, err)
+			result, err := p.ParseWithRelationships([]byte(tt.source), "file")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.ModuleDoc)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package replication mirrors an index deployment to a warm standby.
+package replication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrOwnershipConflict is returned when a repo is already owned by another source.
+var ErrOwnershipConflict = errors.New("repo owned by another source")
+
+// Checkpoint records replication progress for one repository.
+type Checkpoint struct {
+	Owner        string    `json:"owner"`
+	Offset       string    `json:"offset,omitempty"` // Next scroll offset; empty when no run is in progress
+	Generation   int64     `json:"generation"`       // Primary index version last fully replicated
+	PointsCopied int       `json:"points_copied"`
+	CompletedAt  time.Time `json:"completed_at,omitzero"`
+}
+
+// CheckpointStore persists checkpoints to a JSON file.
+type CheckpointStore struct {
+	path  string
+	mu    sync.Mutex
+	repos map[string]Checkpoint
+}
+
+// LoadCheckpoints reads checkpoints from path. A missing file yields an empty store.
+func LoadCheckpoints(path string) (*CheckpointStore, error) {
+	s := &CheckpointStore{
+		path:  path,
+		repos: make(map[string]Checkpoint),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("read checkpoints: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.repos); err != nil {
+		return nil, fmt.Errorf("parse checkpoints: %w", err)
+	}
+
+	return s, nil
+}
+
+// Get returns the checkpoint for repo (zero value if none).
+func (s *CheckpointStore) Get(repo string) Checkpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repos[repo]
+}
+
+// Claim records owner as the source for repo. It fails with
+// ErrOwnershipConflict if a different owner already replicates the repo.
+func (s *CheckpointStore) Claim(repo, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp := s.repos[repo]
+	if err := checkOwner(repo, cp.Owner, owner); err != nil {
+		return err
+	}
+	if cp.Owner == owner {
+		return nil
+	}
+
+	cp.Owner = owner
+	s.repos[repo] = cp
+	return s.save()
+}
+
+// checkOwner fails with ErrOwnershipConflict if recorded names an owner of
+// repo other than owner.
+func checkOwner(repo, recorded, owner string) error {
+	if recorded != "" && recorded != owner {
+		return fmt.Errorf("%w: %s is owned by %s", ErrOwnershipConflict, repo, recorded)
+	}
+	return nil
+}
+
+// Update applies fn to the checkpoint for repo and persists the result.
+func (s *CheckpointStore) Update(repo string, fn func(cp *Checkpoint)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp := s.repos[repo]
+	fn(&cp)
+	s.repos[repo] = cp
+	return s.save()
+}
+
+// save writes checkpoints atomically via a temp file rename. Caller holds mu.
+func (s *CheckpointStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("create checkpoint dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.repos, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoints: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write checkpoints: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,83 @@
+package replication
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replication.json")
+
+	s, err := LoadCheckpoints(path)
+	require.NoError(t, err)
+	assert.Equal(t, Checkpoint{}, s.Get("r3"))
+
+	require.NoError(t, s.Claim("r3", "primary"))
+	require.NoError(t, s.Update("r3", func(cp *Checkpoint) {
+		cp.Offset = "abc"
+		cp.PointsCopied = 200
+	}))
+
+	reloaded, err := LoadCheckpoints(path)
+	require.NoError(t, err)
+
+	cp := reloaded.Get("r3")
+	assert.Equal(t, "primary", cp.Owner)
+	assert.Equal(t, "abc", cp.Offset)
+	assert.Equal(t, 200, cp.PointsCopied)
+}
+
+func TestCheckpointStoreOwnershipConflict(t *testing.T) {
+	s, err := LoadCheckpoints(filepath.Join(t.TempDir(), "replication.json"))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Claim("r3", "team-server"))
+	require.NoError(t, s.Claim("r3", "team-server"), "re-claiming by the same owner is allowed")
+
+	err = s.Claim("r3", "laptop")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrOwnershipConflict))
+
+	// Other repos are unaffected
+	assert.NoError(t, s.Claim("m32rimm", "laptop"))
+}
+
+func TestOwnerMarker(t *testing.T) {
+	marker := ownerMarker("r3", "team-server")
+	assert.Equal(t, ownerMarker("r3", "laptop").ID, marker.ID, "one marker per repo")
+	assert.NotEqual(t, ownerMarker("m32rimm", "team-server").ID, marker.ID)
+	assert.Equal(t, "team-server", markerOwner(&marker))
+	assert.Empty(t, markerOwner(nil))
+
+	assert.NoError(t, checkOwner("r3", markerOwner(nil), "laptop"), "an unclaimed standby can be claimed")
+	assert.NoError(t, checkOwner("r3", markerOwner(&marker), "team-server"))
+	assert.ErrorIs(t, checkOwner("r3", markerOwner(&marker), "laptop"), ErrOwnershipConflict)
+}
+
+func TestCheckpointCompletion(t *testing.T) {
+	s, err := LoadCheckpoints(filepath.Join(t.TempDir(), "replication.json"))
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	require.NoError(t, s.Update("r3", func(cp *Checkpoint) {
+		cp.Offset = ""
+		cp.Generation = 7
+		cp.CompletedAt = now
+	}))
+
+	cp := s.Get("r3")
+	assert.Equal(t, int64(7), cp.Generation)
+	assert.True(t, cp.CompletedAt.Equal(now))
+}
+
+func TestStaleIDs(t *testing.T) {
+	stale := staleIDs([]string{"a", "b"}, []string{"a", "b", "c", "d"})
+	assert.Equal(t, []string{"c", "d"}, stale)
+
+	assert.Empty(t, staleIDs([]string{"a"}, []string{"a"}))
+}
@@ -0,0 +1,302 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/cache"
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/store"
+)
+
+const (
+	collectionName = "chunks"
+
+	// ownersCollection holds one owner marker point per repo on the standby
+	ownersCollection = "replication_owners"
+)
+
+// Replicator copies chunks and graph data for owned repos from a primary
+// deployment to a standby.
+type Replicator struct {
+	source       string
+	primary      *store.QdrantStore
+	standby      *store.QdrantStore
+	primaryGraph *graph.Neo4jStore // Optional
+	standbyGraph *graph.Neo4jStore // Optional
+	versions     *cache.RedisCache // Optional: primary index versions
+	checkpoints  *CheckpointStore
+	batchSize    int
+	logger       *slog.Logger
+}
+
+// Options configures a Replicator.
+type Options struct {
+	Source       string // Name of the primary, recorded as repo owner
+	Primary      *store.QdrantStore
+	Standby      *store.QdrantStore
+	PrimaryGraph *graph.Neo4jStore
+	StandbyGraph *graph.Neo4jStore
+	Versions     *cache.RedisCache
+	Checkpoints  *CheckpointStore
+	BatchSize    int // Default: 100
+	Logger       *slog.Logger
+}
+
+// NewReplicator creates a replicator from options.
+func NewReplicator(opts Options) *Replicator {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &Replicator{
+		source:       opts.Source,
+		primary:      opts.Primary,
+		standby:      opts.Standby,
+		primaryGraph: opts.PrimaryGraph,
+		standbyGraph: opts.StandbyGraph,
+		versions:     opts.Versions,
+		checkpoints:  opts.Checkpoints,
+		batchSize:    opts.BatchSize,
+		logger:       opts.Logger,
+	}
+}
+
+// Result summarizes one repo replication.
+type Result struct {
+	Repo          string
+	Skipped       bool // Standby already at the primary's generation
+	PointsCopied  int
+	PointsDeleted int
+	GraphCopied   bool
+}
+
+// ReplicateRepo mirrors one repo to the standby, resuming from its checkpoint.
+// Without Redis index versions there is no change cursor, so every call
+// re-scrolls the whole repo.
+func (r *Replicator) ReplicateRepo(ctx context.Context, repo string) (*Result, error) {
+	result := &Result{Repo: repo}
+
+	if err := r.checkpoints.Claim(repo, r.source); err != nil {
+		return nil, err
+	}
+	if err := r.claimStandby(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	generation := r.primaryGeneration(ctx, repo)
+	cp := r.checkpoints.Get(repo)
+	if r.versions != nil && cp.Offset == "" && !cp.CompletedAt.IsZero() && cp.Generation == generation {
+		result.Skipped = true
+		return result, nil
+	}
+
+	filter := map[string]interface{}{"repo": repo}
+	offset := cp.Offset
+	copied := 0
+	if offset != "" {
+		copied = cp.PointsCopied
+		r.logger.Info("resuming replication", "repo", repo, "offset", offset, "copied", copied)
+	}
+
+	ensured := false
+	for {
+		chunks, next, err := r.primary.ScrollChunks(ctx, collectionName, filter, r.batchSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("scroll primary: %w", err)
+		}
+
+		if len(chunks) > 0 {
+			if !ensured {
+				if err := r.standby.EnsureCollection(ctx, collectionName, len(chunks[0].Vector)); err != nil {
+					return nil, fmt.Errorf("ensure standby collection: %w", err)
+				}
+				ensured = true
+			}
+			if err := r.standby.UpsertChunks(ctx, collectionName, chunks); err != nil {
+				return nil, fmt.Errorf("upsert standby: %w", err)
+			}
+			copied += len(chunks)
+		}
+
+		offset = next
+		if err := r.checkpoints.Update(repo, func(cp *Checkpoint) {
+			cp.Offset = next
+			cp.PointsCopied = copied
+		}); err != nil {
+			return nil, err
+		}
+
+		if next == "" {
+			break
+		}
+	}
+	result.PointsCopied = copied
+
+	deleted, err := r.prune(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result.PointsDeleted = deleted
+
+	if r.primaryGraph != nil && r.standbyGraph != nil {
+		snapshot, err := r.primaryGraph.ExportRepository(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("export graph: %w", err)
+		}
+		if err := r.standbyGraph.ImportRepository(ctx, snapshot); err != nil {
+			return nil, fmt.Errorf("import graph: %w", err)
+		}
+		result.GraphCopied = true
+	}
+
+	if err := r.checkpoints.Update(repo, func(cp *Checkpoint) {
+		cp.Offset = ""
+		cp.Generation = generation
+		cp.CompletedAt = time.Now().UTC()
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Run replicates repos immediately and then every interval until ctx is done.
+func (r *Replicator) Run(ctx context.Context, repos []string, interval time.Duration) error {
+	r.logger.Info("starting replication", "source", r.source, "repos", len(repos), "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	r.replicateAll(ctx, repos)
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("replication shutting down")
+			return ctx.Err()
+		case <-ticker.C:
+			r.replicateAll(ctx, repos)
+		}
+	}
+}
+
+func (r *Replicator) replicateAll(ctx context.Context, repos []string) {
+	for _, repo := range repos {
+		result, err := r.ReplicateRepo(ctx, repo)
+		if err != nil {
+			r.logger.Error("replication failed", "repo", repo, "error", err)
+			continue
+		}
+		if result.Skipped {
+			r.logger.Debug("standby up to date", "repo", repo)
+			continue
+		}
+		r.logger.Info("replication complete",
+			"repo", repo,
+			"copied", result.PointsCopied,
+			"deleted", result.PointsDeleted,
+			"graph", result.GraphCopied,
+		)
+	}
+}
+
+// claimStandby records the source as the repo's owner on the standby, failing
+// with ErrOwnershipConflict if another primary is recorded there. The local
+// checkpoint file only knows this host's claims; the marker stops primaries
+// on other hosts from replicating the same repo into one standby. The marker
+// is read back after writing, so of two primaries claiming at once the one
+// overwritten gives up.
+func (r *Replicator) claimStandby(ctx context.Context, repo string) error {
+	if err := r.standby.EnsureCollection(ctx, ownersCollection, 1); err != nil {
+		return fmt.Errorf("ensure standby owners collection: %w", err)
+	}
+	marker := ownerMarker(repo, r.source)
+	for _, write := range []bool{true, false} {
+		stored, err := r.standby.GetChunk(ctx, ownersCollection, marker.ID)
+		if err != nil {
+			return fmt.Errorf("read standby owner: %w", err)
+		}
+		if err := checkOwner(repo, markerOwner(stored), r.source); err != nil {
+			return err
+		}
+		if stored != nil || !write {
+			return nil
+		}
+		if err := r.standby.UpsertChunks(ctx, ownersCollection, []chunk.Chunk{marker}); err != nil {
+			return fmt.Errorf("record standby owner: %w", err)
+		}
+	}
+	return nil
+}
+
+// ownerMarker is the point recording owner as the source of repo. Its ID is
+// derived from the repo, so each repo has one.
+func ownerMarker(repo, owner string) chunk.Chunk {
+	return chunk.Chunk{
+		ID:       chunk.GenerateID(repo, ownersCollection, "", 0),
+		Repo:     repo,
+		Kind:     "replication_owner",
+		Vector:   []float32{1},
+		Metadata: map[string]string{"owner": owner},
+	}
+}
+
+// markerOwner returns the owner a stored marker records, or "" without one.
+func markerOwner(marker *chunk.Chunk) string {
+	if marker == nil {
+		return ""
+	}
+	return marker.Metadata["owner"]
+}
+
+// prune deletes standby points that no longer exist on the primary.
+func (r *Replicator) prune(ctx context.Context, filter map[string]interface{}) (int, error) {
+	primaryIDs, err := r.primary.ListPointIDs(ctx, collectionName, filter)
+	if err != nil {
+		return 0, fmt.Errorf("list primary points: %w", err)
+	}
+	standbyIDs, err := r.standby.ListPointIDs(ctx, collectionName, filter)
+	if err != nil {
+		return 0, fmt.Errorf("list standby points: %w", err)
+	}
+
+	stale := staleIDs(primaryIDs, standbyIDs)
+	if err := r.standby.DeletePoints(ctx, collectionName, stale); err != nil {
+		return 0, fmt.Errorf("delete stale points: %w", err)
+	}
+	return len(stale), nil
+}
+
+func (r *Replicator) primaryGeneration(ctx context.Context, repo string) int64 {
+	if r.versions == nil {
+		return 0
+	}
+	version, err := r.versions.GetIndexVersion(ctx, repo)
+	if err != nil {
+		r.logger.Warn("failed to read index version", "repo", repo, "error", err)
+		return 0
+	}
+	return version
+}
+
+// staleIDs returns IDs present on the standby but missing from the primary.
+func staleIDs(primary, standby []string) []string {
+	keep := make(map[string]bool, len(primary))
+	for _, id := range primary {
+		keep[id] = true
+	}
+
+	var stale []string
+	for _, id := range standby {
+		if !keep[id] {
+			stale = append(stale, id)
+		}
+	}
+	return stale
+}
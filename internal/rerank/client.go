@@ -0,0 +1,183 @@
+// Package rerank scores search candidates against a query with a reranking
+// model (a cross-encoder), for a second ranking stage after retrieval.
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/security"
+)
+
+// Providers are the supported rerank APIs.
+const (
+	ProviderVoyage = "voyage"
+	ProviderCohere = "cohere"
+	ProviderLocal  = "local" // Text Embeddings Inference /rerank and compatible servers
+)
+
+var providerDefaults = map[string]struct{ url, model, apiKeyEnv string }{
+	ProviderVoyage: {"https://api.voyageai.com/v1/rerank", "rerank-2.5", "VOYAGE_API_KEY"},
+	ProviderCohere: {"https://api.cohere.com/v2/rerank", "rerank-v3.5", "COHERE_API_KEY"},
+	ProviderLocal:  {},
+}
+
+// Client reranks documents with a Voyage, Cohere, or local cross-encoder
+// rerank endpoint.
+type Client struct {
+	provider string
+	url      string
+	model    string
+	apiKey   string
+	client   *http.Client
+	secrets  *security.SecretDetector
+}
+
+// NewClient creates a client from the rerank config, reading the API key
+// from cfg.APIKeyEnv. Unset fields fall back to the provider's defaults.
+func NewClient(cfg config.RerankConfig) (*Client, error) {
+	if cfg.Provider == "" {
+		cfg.Provider = config.DefaultConfig().Search.Rerank.Provider
+	}
+	defaults, ok := providerDefaults[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown rerank provider %q (supported: voyage, cohere, local)", cfg.Provider)
+	}
+	if cfg.URL == "" {
+		cfg.URL = defaults.url
+	}
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("search.rerank.url is required for the %s provider", cfg.Provider)
+	}
+	if cfg.Model == "" {
+		cfg.Model = defaults.model
+	}
+	if cfg.APIKeyEnv == "" {
+		cfg.APIKeyEnv = defaults.apiKeyEnv
+	}
+	if cfg.TimeoutSeconds <= 0 {
+		cfg.TimeoutSeconds = config.DefaultConfig().Search.Rerank.TimeoutSeconds
+	}
+	var apiKey string
+	if cfg.APIKeyEnv != "" {
+		apiKey = os.Getenv(cfg.APIKeyEnv)
+	}
+	return &Client{
+		provider: cfg.Provider,
+		url:      cfg.URL,
+		model:    cfg.Model,
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		secrets:  security.NewSecretDetector(),
+	}, nil
+}
+
+// rerankResult is one scored document; Voyage and Cohere call the list
+// "data" and "results", TEI returns it bare.
+type rerankResult struct {
+	Index          int      `json:"index"`
+	RelevanceScore *float64 `json:"relevance_score"`
+	Score          *float64 `json:"score"`
+}
+
+// Rerank returns a relevance score for each document, in document order;
+// higher is more relevant. Secrets are redacted before the documents leave
+// the process.
+func (c *Client) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	docs := make([]string, len(documents))
+	for i, doc := range documents {
+		if found := c.secrets.Detect(doc); len(found) > 0 {
+			doc = c.secrets.Redact(doc, found)
+		}
+		docs[i] = doc
+	}
+
+	var payload interface{}
+	switch c.provider {
+	case ProviderLocal:
+		payload = map[string]interface{}{"query": query, "texts": docs, "truncate": true}
+	case ProviderCohere:
+		payload = map[string]interface{}{"model": c.model, "query": query, "documents": docs, "top_n": len(docs)}
+	default:
+		payload = map[string]interface{}{"model": c.model, "query": query, "documents": docs, "truncation": true}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rerank API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var results []rerankResult
+	switch c.provider {
+	case ProviderLocal:
+		err = json.Unmarshal(respBody, &results)
+	case ProviderCohere:
+		var wrapped struct {
+			Results []rerankResult `json:"results"`
+		}
+		err = json.Unmarshal(respBody, &wrapped)
+		results = wrapped.Results
+	default:
+		var wrapped struct {
+			Data []rerankResult `json:"data"`
+		}
+		err = json.Unmarshal(respBody, &wrapped)
+		results = wrapped.Data
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	scores := make([]float64, len(docs))
+	scored := make([]bool, len(docs))
+	for _, r := range results {
+		if r.Index < 0 || r.Index >= len(docs) {
+			return nil, fmt.Errorf("rerank API returned index %d for %d documents", r.Index, len(docs))
+		}
+		switch {
+		case r.RelevanceScore != nil:
+			scores[r.Index] = *r.RelevanceScore
+		case r.Score != nil:
+			scores[r.Index] = *r.Score
+		}
+		scored[r.Index] = true
+	}
+	for i, ok := range scored {
+		if !ok {
+			return nil, fmt.Errorf("rerank API returned no score for document %d", i)
+		}
+	}
+	return scores, nil
+}
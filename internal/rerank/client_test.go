@@ -0,0 +1,102 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRerankProviders(t *testing.T) {
+	tests := []struct {
+		provider string
+		response string
+	}{
+		{ProviderVoyage, `{"data":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.2}]}`},
+		{ProviderCohere, `{"results":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.2}]}`},
+		{ProviderLocal, `[{"index":1,"score":0.9},{"index":0,"score":0.2}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var got map[string]interface{}
+			var auth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				auth = r.Header.Get("Authorization")
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			t.Setenv("TEST_RERANK_KEY", "rk-test")
+			client, err := NewClient(config.RerankConfig{Provider: tt.provider, URL: server.URL, APIKeyEnv: "TEST_RERANK_KEY"})
+			require.NoError(t, err)
+
+			scores, err := client.Rerank(context.Background(), "retry uploads", []string{"def parse(): ...", "def upload_with_retry(): ..."})
+			require.NoError(t, err)
+			assert.Equal(t, []float64{0.2, 0.9}, scores)
+			assert.Equal(t, "Bearer rk-test", auth)
+			assert.Equal(t, "retry uploads", got["query"])
+		})
+	}
+}
+
+func TestClientRerankRedactsSecrets(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Documents []string `json:"documents"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		body = req.Documents[0]
+		w.Write([]byte(`{"data":[{"index":0,"relevance_score":0.5}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(config.RerankConfig{URL: server.URL})
+	require.NoError(t, err)
+	_, err = client.Rerank(context.Background(), "q", []string{"api_key = \"sk9f8e7d6c5b4a3f2e1d0c9b8a\""})
+	require.NoError(t, err)
+	assert.NotContains(t, body, "sk9f8e7d6c5b4a3f2e1d0c9b8a")
+}
+
+func TestClientRerankErrors(t *testing.T) {
+	status := http.StatusOK
+	response := `{"data":[{"index":0,"relevance_score":0.5}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(config.RerankConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	_, err = client.Rerank(context.Background(), "q", []string{"a", "b"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no score for document 1")
+
+	status, response = http.StatusTooManyRequests, `{"detail":"rate limited"}`
+	_, err = client.Rerank(context.Background(), "q", []string{"a"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 429")
+}
+
+func TestNewClientValidatesProvider(t *testing.T) {
+	_, err := NewClient(config.RerankConfig{Provider: "acme"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown rerank provider")
+
+	_, err = NewClient(config.RerankConfig{Provider: ProviderLocal})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "search.rerank.url is required")
+
+	client, err := NewClient(config.RerankConfig{Provider: ProviderCohere})
+	require.NoError(t, err)
+	assert.Equal(t, "rerank-v3.5", client.model)
+	assert.Equal(t, "https://api.cohere.com/v2/rerank", client.url)
+}
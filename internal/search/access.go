@@ -0,0 +1,310 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/randalmurphal/code-indexer/internal/store"
+)
+
+// authorize rejects tool calls that would read a repo or module outside
+// the caller's token scope. Callers without a token (stdio) are never
+// restricted. list_repos filters its own output, and set_context and
+// reindex_file check the repo (and reindex_file the module) they resolve
+// from a path. Chunks of other modules are filtered out of every store query
+// by chunkStore, and graph results by the tools reading them.
+func (h *Handler) authorize(ctx context.Context, name string, args map[string]interface{}) *mcp.CallToolResult {
+	token := mcp.TokenInfoFromContext(ctx)
+	if token == nil || (token.Repos == nil && token.Modules == nil) {
+		return nil
+	}
+	switch name {
+	case "list_repos", "set_context":
+		return nil
+	}
+
+	repo, module := h.scope(ctx, args)
+	if !token.AllowsRepo(repo) {
+		return repoDenied(token, repo)
+	}
+	if module != "" && !token.AllowsModule(repo, module) {
+		return moduleDenied(token, repo, module)
+	}
+	return nil
+}
+
+func moduleDenied(token *mcp.TokenInfo, repo, module string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("module %q of %s is not permitted for this token (allowed: %s)",
+			module, repo, strings.Join(token.AllowedModules(repo), ", "))}},
+		IsError: true,
+	}
+}
+
+// Graph reads are not narrowed by chunkStore; the tools reading files,
+// modules, or symbols from Neo4j drop those of modules the caller may not
+// read with the helpers below.
+
+// readableFiles returns whether the caller may read each of paths, files of
+// repo, by the module root the graph records for them; nil when the caller
+// reads the whole repo. Files the graph doesn't know are not readable.
+func (h *Handler) readableFiles(ctx context.Context, repo string, paths []string) (func(filePath string) bool, error) {
+	token := mcp.TokenInfoFromContext(ctx)
+	if token.AllowedModules(repo) == nil {
+		return nil, nil
+	}
+	roots, err := h.graphStore.FileModuleRoots(ctx, repo, paths)
+	if err != nil {
+		return nil, fmt.Errorf("resolve modules: %w", err)
+	}
+	return func(filePath string) bool {
+		root, ok := roots[filePath]
+		return ok && token.AllowsModule(repo, root)
+	}, nil
+}
+
+// filterModuleSummaries drops modules the caller may not read.
+func filterModuleSummaries(ctx context.Context, repo string, modules []graph.ModuleSummary) []graph.ModuleSummary {
+	token := mcp.TokenInfoFromContext(ctx)
+	if token.AllowedModules(repo) == nil {
+		return modules
+	}
+	var allowed []graph.ModuleSummary
+	for _, m := range modules {
+		if token.AllowsModule(repo, m.Path) {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed
+}
+
+// pruneHierarchies drops the hierarchies of classes in files allowed
+// rejects, and cuts every ancestor or descendant tree at such a class.
+func pruneHierarchies(hierarchies []graph.ClassHierarchy, allowed func(filePath string) bool) []graph.ClassHierarchy {
+	var pruned []graph.ClassHierarchy
+	for _, h := range hierarchies {
+		if !allowed(h.Class.FilePath) {
+			continue
+		}
+		h.Ancestors = pruneHierarchyNodes(h.Ancestors, allowed)
+		h.Descendants = pruneHierarchyNodes(h.Descendants, allowed)
+		pruned = append(pruned, h)
+	}
+	return pruned
+}
+
+func pruneHierarchyNodes(nodes []*graph.HierarchyNode, allowed func(filePath string) bool) []*graph.HierarchyNode {
+	var pruned []*graph.HierarchyNode
+	for _, n := range nodes {
+		if !allowed(n.FilePath) {
+			continue
+		}
+		copied := *n
+		copied.Children = pruneHierarchyNodes(n.Children, allowed)
+		pruned = append(pruned, &copied)
+	}
+	return pruned
+}
+
+// hierarchyPaths returns every file path in hierarchies.
+func hierarchyPaths(hierarchies []graph.ClassHierarchy) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	var walk func(nodes []*graph.HierarchyNode)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	walk = func(nodes []*graph.HierarchyNode) {
+		for _, n := range nodes {
+			add(n.FilePath)
+			walk(n.Children)
+		}
+	}
+	for _, h := range hierarchies {
+		add(h.Class.FilePath)
+		walk(h.Ancestors)
+		walk(h.Descendants)
+	}
+	return paths
+}
+
+func repoDenied(token *mcp.TokenInfo, repo string) *mcp.CallToolResult {
+	text := fmt.Sprintf("repo %q is not permitted for this token", repo)
+	if repo == "" || repo == "all" {
+		text = "this token is limited to specific repos; pass the repo parameter"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("%s (allowed: %s)", text, strings.Join(token.Repos, ", "))}},
+		IsError: true,
+	}
+}
+
+// chunkStore is the Qdrant store as the handler reads it: every read is
+// narrowed to the modules the caller's token may read, so no tool can
+// return chunks of other modules. Writes pass through.
+type chunkStore struct {
+	*store.QdrantStore
+	withholdSecrets bool // search.withhold_secret_bodies; see withholdSecretBodies
+}
+
+// moduleFilter adds the caller's module restrictions to filter, returning
+// a copy, or false when the filter can match nothing readable. A filter
+// pinned to one repo keeps to the token's modules there; any other filter
+// leaves out the repos the token only reads in part.
+func moduleFilter(ctx context.Context, filter map[string]interface{}) (map[string]interface{}, bool) {
+	token := mcp.TokenInfoFromContext(ctx)
+	if token == nil || len(token.Modules) == 0 {
+		return filter, true
+	}
+	scoped := make(map[string]interface{}, len(filter)+1)
+	for k, v := range filter {
+		scoped[k] = v
+	}
+
+	repo, _ := filter["repo"].(string)
+	if repo == "" {
+		partial := slices.Sorted(maps.Keys(token.Modules))
+		mustNot := make(map[string]interface{})
+		if existing, ok := filter[store.MustNot].(map[string]interface{}); ok {
+			for k, v := range existing {
+				mustNot[k] = v
+			}
+		}
+		switch excluded := mustNot["repo"].(type) {
+		case string:
+			partial = append(partial, excluded)
+		case []string:
+			partial = append(partial, excluded...)
+		}
+		mustNot["repo"] = partial
+		scoped[store.MustNot] = mustNot
+		return scoped, true
+	}
+
+	allowed := token.AllowedModules(repo)
+	if allowed == nil {
+		return filter, true
+	}
+	var requested []string
+	switch v := filter["module_root"].(type) {
+	case string:
+		requested = []string{v}
+	case []string:
+		requested = v
+	default:
+		scoped["module_root"] = allowed
+		return scoped, true
+	}
+	var permitted []string
+	for _, m := range requested {
+		if slices.Contains(allowed, m) {
+			permitted = append(permitted, m)
+		}
+	}
+	if len(permitted) == 0 {
+		return nil, false
+	}
+	scoped["module_root"] = permitted
+	return scoped, true
+}
+
+// moduleScopeKey identifies the caller's module restrictions in cache keys,
+// so restricted callers never share cached results with others; empty
+// without any.
+func moduleScopeKey(ctx context.Context) string {
+	token := mcp.TokenInfoFromContext(ctx)
+	if token == nil || len(token.Modules) == 0 {
+		return ""
+	}
+	var key strings.Builder
+	for _, repo := range slices.Sorted(maps.Keys(token.Modules)) {
+		fmt.Fprintf(&key, "\x00modules:%s=%s", repo, strings.Join(token.Modules[repo], ","))
+	}
+	return key.String()
+}
+
+func (s *chunkStore) Search(ctx context.Context, collection string, vector []float32, limit int, filter map[string]interface{}) ([]chunk.Chunk, error) {
+	filter, ok := moduleFilter(ctx, filter)
+	if !ok {
+		return nil, nil
+	}
+	done := timeStage(ctx, stageStore)
+	chunks, err := s.QdrantStore.Search(ctx, collection, vector, limit, filter)
+	done()
+	return s.withholdSecretBodies(ctx, chunks), err
+}
+
+func (s *chunkStore) SearchByFilter(ctx context.Context, collection string, filter map[string]interface{}, limit int) ([]chunk.Chunk, error) {
+	filter, ok := moduleFilter(ctx, filter)
+	if !ok {
+		return nil, nil
+	}
+	done := timeStage(ctx, stageStore)
+	chunks, err := s.QdrantStore.SearchByFilter(ctx, collection, filter, limit)
+	done()
+	return s.withholdSecretBodies(ctx, chunks), err
+}
+
+func (s *chunkStore) ScrollChunks(ctx context.Context, collection string, filter map[string]interface{}, limit int, offset string) ([]chunk.Chunk, string, error) {
+	filter, ok := moduleFilter(ctx, filter)
+	if !ok {
+		return nil, "", nil
+	}
+	done := timeStage(ctx, stageStore)
+	chunks, next, err := s.QdrantStore.ScrollChunks(ctx, collection, filter, limit, offset)
+	done()
+	return s.withholdSecretBodies(ctx, chunks), next, err
+}
+
+func (s *chunkStore) ScrollByText(ctx context.Context, collection, field, text string, filter map[string]interface{}, limit int, offset string) ([]chunk.Chunk, string, error) {
+	filter, ok := moduleFilter(ctx, filter)
+	if !ok {
+		return nil, "", nil
+	}
+	done := timeStage(ctx, stageStore)
+	chunks, next, err := s.QdrantStore.ScrollByText(ctx, collection, field, text, filter, limit, offset)
+	done()
+	return s.withholdSecretBodies(ctx, chunks), next, err
+}
+
+func (s *chunkStore) ListPointIDs(ctx context.Context, collection string, filter map[string]interface{}) ([]string, error) {
+	filter, ok := moduleFilter(ctx, filter)
+	if !ok {
+		return nil, nil
+	}
+	return s.QdrantStore.ListPointIDs(ctx, collection, filter)
+}
+
+// CountByField counts repos unrestricted: per-repo totals reveal no code,
+// and list_repos and federation need every readable repo.
+func (s *chunkStore) CountByField(ctx context.Context, collection, field string, filter map[string]interface{}) (map[string]int, error) {
+	if field != "repo" {
+		var ok bool
+		if filter, ok = moduleFilter(ctx, filter); !ok {
+			return map[string]int{}, nil
+		}
+	}
+	return s.QdrantStore.CountByField(ctx, collection, field, filter)
+}
+
+// GetChunk returns nil, like a missing chunk, for chunks of modules the
+// caller may not read.
+func (s *chunkStore) GetChunk(ctx context.Context, collection, id string) (*chunk.Chunk, error) {
+	c, err := s.QdrantStore.GetChunk(ctx, collection, id)
+	if err != nil || c == nil {
+		return c, err
+	}
+	if !mcp.TokenInfoFromContext(ctx).AllowsModule(c.Repo, c.ModuleRoot) {
+		return nil, nil
+	}
+	return &s.withholdSecretBodies(ctx, []chunk.Chunk{*c})[0], nil
+}
@@ -0,0 +1,208 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeScopedToken(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{Subject: "ci", Repos: []string{"demo", "docs"}})
+
+	assert.Nil(t, handler.authorize(ctx, "search_code", map[string]interface{}{"repo": "demo"}))
+
+	denied := handler.authorize(ctx, "search_code", map[string]interface{}{"repo": "secret"})
+	require.NotNil(t, denied)
+	assert.True(t, denied.IsError)
+	assert.Contains(t, denied.Content[0].Text, `"secret" is not permitted`)
+
+	denied = handler.authorize(ctx, "grep_code", map[string]interface{}{"repo": "all"})
+	require.NotNil(t, denied, "scoped tokens cannot search every repo")
+	assert.Contains(t, denied.Content[0].Text, "pass the repo parameter")
+
+	assert.Nil(t, handler.authorize(ctx, "list_repos", map[string]interface{}{}), "list_repos filters its own output")
+
+	result, err := handler.CallTool(ctx, "get_symbol", map[string]interface{}{"name": "X", "repo": "secret"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "CallTool enforces the scope before running the tool")
+}
+
+func TestAuthorizeUnrestricted(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	assert.Nil(t, handler.authorize(context.Background(), "search_code", map[string]interface{}{"repo": "anything"}), "stdio has no token")
+
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{Subject: "admin"})
+	assert.Nil(t, handler.authorize(ctx, "search_code", map[string]interface{}{"repo": "all"}))
+}
+
+func TestInferRepoFromSingleRepoToken(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{Subject: "ci", Repos: []string{"demo"}})
+
+	assert.Equal(t, "demo", handler.inferRepo(ctx))
+	assert.Nil(t, handler.authorize(ctx, "search_code", map[string]interface{}{"query": "x"}))
+}
+
+func TestSetContextRespectsTokenScope(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	ctx := mcp.WithSession(context.Background(), mcp.NewSession())
+	ctx = mcp.WithTokenInfo(ctx, &mcp.TokenInfo{Subject: "ci", Repos: []string{"demo"}})
+
+	result, err := handler.CallTool(ctx, "set_context", map[string]interface{}{"repo": "secret"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "demo", handler.inferRepo(ctx), "rejected pin is not stored")
+}
+
+func TestCallersRequiresGraph(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	_, err := handler.Callers(context.Background(), "demo", "validate")
+	assert.ErrorIs(t, err, ErrGraphUnavailable)
+
+	_, err = handler.Callees(context.Background(), "demo", "validate")
+	assert.ErrorIs(t, err, ErrGraphUnavailable)
+}
+
+func TestAuthorizeModuleScopedToken(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{
+		Subject: "contractor",
+		Modules: map[string][]string{"demo": {"billing"}},
+	})
+
+	assert.Nil(t, handler.authorize(ctx, "search_code", map[string]interface{}{"repo": "demo"}))
+	assert.Nil(t, handler.authorize(ctx, "module_contents", map[string]interface{}{"repo": "demo", "module": "billing"}))
+	assert.Nil(t, handler.authorize(ctx, "explain_module", map[string]interface{}{"repo": "other", "module": "core"}), "other repos are unrestricted")
+
+	denied := handler.authorize(ctx, "module_contents", map[string]interface{}{"repo": "demo", "module": "core"})
+	require.NotNil(t, denied)
+	assert.Contains(t, denied.Content[0].Text, `module "core" of demo is not permitted`)
+}
+
+func TestModuleFilter(t *testing.T) {
+	filter := map[string]interface{}{"repo": "demo", "kind": "function"}
+
+	got, ok := moduleFilter(context.Background(), filter)
+	assert.True(t, ok)
+	assert.Equal(t, filter, got, "no token leaves the filter alone")
+
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{
+		Subject: "contractor",
+		Modules: map[string][]string{"demo": {"billing", "shared"}, "web": {"ui"}},
+	})
+
+	got, ok = moduleFilter(ctx, filter)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"billing", "shared"}, got["module_root"])
+	assert.NotContains(t, filter, "module_root", "the caller's filter is not modified")
+
+	got, ok = moduleFilter(ctx, map[string]interface{}{"repo": "demo", "module_root": []string{"core", "shared"}})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"shared"}, got["module_root"], "requested modules are intersected")
+
+	_, ok = moduleFilter(ctx, map[string]interface{}{"repo": "demo", "module_root": "core"})
+	assert.False(t, ok, "a module outside the scope matches nothing")
+
+	got, ok = moduleFilter(ctx, map[string]interface{}{"repo": "docs"})
+	assert.True(t, ok)
+	assert.NotContains(t, got, "module_root", "repos without module limits are readable whole")
+
+	got, ok = moduleFilter(ctx, map[string]interface{}{
+		store.MustNot: map[string]interface{}{"repo": []string{"big"}, "license": []string{"GPL-3.0"}},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, map[string]interface{}{
+		"repo":    []string{"demo", "web", "big"},
+		"license": []string{"GPL-3.0"},
+	}, got[store.MustNot], "searches across repos leave out partly readable ones")
+}
+
+func TestModuleScopeKey(t *testing.T) {
+	assert.Empty(t, moduleScopeKey(context.Background()))
+	assert.Empty(t, moduleScopeKey(mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{Repos: []string{"demo"}})))
+
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{Modules: map[string][]string{"web": {"ui"}, "demo": {"billing", "shared"}}})
+	assert.Equal(t, "\x00modules:demo=billing,shared\x00modules:web=ui", moduleScopeKey(ctx))
+}
+
+func TestGraphResultsRespectModuleScope(t *testing.T) {
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{
+		Subject: "contractor",
+		Modules: map[string][]string{"demo": {"billing"}},
+	})
+
+	modules := []graph.ModuleSummary{
+		{Module: graph.Module{Path: "billing"}},
+		{Module: graph.Module{Path: "billing.tax"}},
+		{Module: graph.Module{Path: "core"}},
+	}
+	assert.Equal(t, modules[:2], filterModuleSummaries(ctx, "demo", modules))
+}
+
+func TestReadableFilesUnrestricted(t *testing.T) {
+	// Callers reading the whole repo never need the graph's module roots
+	handler := &Handler{}
+	allowed, err := handler.readableFiles(context.Background(), "demo", []string{"core/auth.py"})
+	require.NoError(t, err)
+	assert.Nil(t, allowed)
+
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{Modules: map[string][]string{"other": {"billing"}}})
+	allowed, err = handler.readableFiles(ctx, "demo", []string{"core/auth.py"})
+	require.NoError(t, err)
+	assert.Nil(t, allowed, "modules are limited in another repo only")
+}
+
+func TestPruneHierarchies(t *testing.T) {
+	hierarchies := []graph.ClassHierarchy{
+		{
+			Class: graph.HierarchyNode{Name: "Invoice", FilePath: "billing/invoice.py"},
+			Ancestors: []*graph.HierarchyNode{{
+				Name: "Document", FilePath: "billing/base.py",
+				Children: []*graph.HierarchyNode{{Name: "Model", FilePath: "core/model.py"}},
+			}},
+			Descendants: []*graph.HierarchyNode{{Name: "AdminInvoice", FilePath: "admin/invoice.py"}},
+		},
+		{Class: graph.HierarchyNode{Name: "Invoice", FilePath: "core/invoice.py"}},
+	}
+	assert.ElementsMatch(t, []string{"billing/invoice.py", "billing/base.py", "core/model.py", "admin/invoice.py", "core/invoice.py"},
+		hierarchyPaths(hierarchies))
+
+	allowed := func(filePath string) bool { return strings.HasPrefix(filePath, "billing/") }
+	pruned := pruneHierarchies(hierarchies, allowed)
+	require.Len(t, pruned, 1, "classes outside the scope are dropped")
+	require.Len(t, pruned[0].Ancestors, 1)
+	assert.Equal(t, "Document", pruned[0].Ancestors[0].Name)
+	assert.Empty(t, pruned[0].Ancestors[0].Children, "the tree is cut at other modules")
+	assert.Empty(t, pruned[0].Descendants)
+	assert.Len(t, hierarchies[0].Ancestors[0].Children, 1, "the input is not modified")
+}
+
+func TestReindexFileRespectsModuleScope(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".ai-devtools.yaml"), []byte("code-index:\n  name: demo\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "core"), 0o755))
+	path := filepath.Join(repo, "core", "auth.py")
+	require.NoError(t, os.WriteFile(path, []byte("def login(): pass\n"), 0o644))
+
+	handler := &Handler{config: config.DefaultConfig()}
+	ctx := mcp.WithTokenInfo(context.Background(), &mcp.TokenInfo{
+		Subject: "contractor",
+		Modules: map[string][]string{"demo": {"billing"}},
+	})
+	result, err := handler.CallTool(ctx, "reindex_file", map[string]interface{}{"path": path})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, `module "core" of demo is not permitted`)
+}
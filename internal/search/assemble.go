@@ -0,0 +1,242 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/randalmurphal/code-indexer/internal/parser"
+)
+
+// relevantURI is the contextual code resource. Read bare, it suggests code
+// near the workspace; with a query, it is an assembled context bundle.
+const relevantURI = "codeindex://relevant"
+
+// contextHeaderTokens approximates the tokens of a section's header and
+// code fence.
+const contextHeaderTokens = 20
+
+// contextRequest is what a codeindex://relevant read with a query asks for.
+type contextRequest struct {
+	query     string
+	repo      string
+	module    string
+	maxTokens int
+}
+
+// parseContextURI reads the parameters of codeindex://relevant?query=...:
+// query (or task), repo, module, and tokens, the bundle's budget.
+func parseContextURI(uri string) (contextRequest, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return contextRequest{}, fmt.Errorf("parse %s: %w", uri, err)
+	}
+	params := u.Query()
+	req := contextRequest{
+		query:  params.Get("query"),
+		repo:   params.Get("repo"),
+		module: params.Get("module"),
+	}
+	if req.query == "" {
+		req.query = params.Get("task")
+	}
+	if tokens := params.Get("tokens"); tokens != "" {
+		n, err := strconv.Atoi(tokens)
+		if err != nil || n <= 0 {
+			return contextRequest{}, fmt.Errorf("tokens must be a positive integer, got %q", tokens)
+		}
+		req.maxTokens = n
+	}
+	return req, nil
+}
+
+// assembleContext answers codeindex://relevant?query=...: it retrieves the
+// chunks best matching the query, drops repeats and chunks overlapping a
+// better one, packs as many as fit the token budget in rank order, and
+// orders them callee before caller, as a markdown bundle with a header per
+// chunk.
+func (h *Handler) assembleContext(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	req, err := parseContextURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if req.query == "" {
+		return h.getRelevantContext(ctx)
+	}
+
+	cfg := config.DefaultConfig().Search.Context
+	if h.config != nil {
+		cfg = h.config.Search.Context
+	}
+	budget := req.maxTokens
+	if budget <= 0 {
+		budget = cfg.MaxTokens
+	}
+
+	repo, module := h.scope(ctx, map[string]interface{}{"repo": req.repo, "module": req.module})
+	if !mcp.RepoAllowed(ctx, repo) {
+		return h.emptyRelevantContext(), nil
+	}
+	filter := make(map[string]interface{})
+	if repo != "" && repo != "all" {
+		filter["repo"] = repo
+	}
+	if module != "" {
+		filter["module_path"] = module
+	}
+
+	candidates, err := h.searchSemantic(ctx, req.query, filter, max(cfg.Candidates, 1))
+	if err != nil {
+		return nil, fmt.Errorf("assemble context: %w", err)
+	}
+	packed, omitted := packContext(candidates, budget)
+	if len(packed) == 0 {
+		return h.emptyRelevantContext(), nil
+	}
+	ordered := orderByDependency(packed, h.contextCalls(ctx, repo, packed))
+
+	return &mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContent{{
+			URI:      uri,
+			MimeType: "text/markdown",
+			Text:     renderContext(req.query, repo, ordered, budget, omitted),
+		}},
+	}, nil
+}
+
+// packContext picks, in rank order, the chunks that fit budget tokens,
+// leaving out file summaries, repeats, and chunks overlapping a picked chunk
+// of the same file. It returns the picked chunks and how many relevant
+// chunks did not fit.
+func packContext(candidates []chunk.Chunk, budget int) ([]chunk.Chunk, int) {
+	var packed []chunk.Chunk
+	used, omitted := 0, 0
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if c.Kind == chunk.KindFileSummary || seen[c.ID] || overlapsAny(c, packed) {
+			continue
+		}
+		seen[c.ID] = true
+		cost := c.TokenEstimate() + contextHeaderTokens
+		if used+cost > budget {
+			omitted++
+			continue
+		}
+		used += cost
+		packed = append(packed, c)
+	}
+	return packed, omitted
+}
+
+// overlapsAny reports whether c shares lines with a chunk of its file.
+func overlapsAny(c chunk.Chunk, chunks []chunk.Chunk) bool {
+	for _, o := range chunks {
+		if o.Repo == c.Repo && o.FilePath == c.FilePath && c.StartLine <= o.EndLine && o.StartLine <= c.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+// contextCalls returns, per packed chunk, the indexes of the packed chunks
+// it calls: from the graph's CALLS edges when the repo has a graph, else
+// from the chunk's code mentioning name( of another chunk's symbol.
+func (h *Handler) contextCalls(ctx context.Context, repo string, chunks []chunk.Chunk) [][]int {
+	bySymbol := make(map[string][]int)
+	for i, c := range chunks {
+		if c.SymbolName != "" {
+			bySymbol[c.SymbolName] = append(bySymbol[c.SymbolName], i)
+		}
+	}
+	calls := make([][]int, len(chunks))
+	link := func(i int, name string) {
+		for _, j := range bySymbol[name] {
+			if j != i {
+				calls[i] = append(calls[i], j)
+			}
+		}
+	}
+
+	if graphRepo, err := h.graphRepo(ctx, repo); err == nil {
+		for i, c := range chunks {
+			if c.SymbolName == "" {
+				continue
+			}
+			callees, err := h.graphStore.FindCallees(ctx, graphRepo, c.SymbolName)
+			if err != nil {
+				continue
+			}
+			for _, callee := range callees {
+				link(i, callee.Name)
+			}
+		}
+		return calls
+	}
+
+	for i, c := range chunks {
+		for j, callee := range chunks {
+			if j != i && callee.SymbolName != "" && strings.Contains(c.Content, callee.SymbolName+"(") {
+				calls[i] = append(calls[i], j)
+			}
+		}
+	}
+	return calls
+}
+
+// orderByDependency orders chunks so each comes after the chunks it calls,
+// otherwise keeping rank order; call cycles are broken at the better-ranked
+// chunk.
+func orderByDependency(chunks []chunk.Chunk, calls [][]int) []chunk.Chunk {
+	ordered := make([]chunk.Chunk, 0, len(chunks))
+	visited := make([]bool, len(chunks))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, j := range calls[i] {
+			visit(j)
+		}
+		ordered = append(ordered, chunks[i])
+	}
+	for i := range chunks {
+		visit(i)
+	}
+	return ordered
+}
+
+// renderContext formats a context bundle as markdown.
+func renderContext(query, repo string, chunks []chunk.Chunk, budget, omitted int) string {
+	tokens := 0
+	for _, c := range chunks {
+		tokens += c.TokenEstimate() + contextHeaderTokens
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Context: %s\n\n", query)
+	if repo != "" && repo != "all" {
+		fmt.Fprintf(&b, "Repo `%s`. ", repo)
+	}
+	fmt.Fprintf(&b, "%d chunks, ~%d of %d tokens, callees before callers.\n", len(chunks), tokens, budget)
+	for _, c := range chunks {
+		fmt.Fprintf(&b, "\n## `%s:%d-%d`", c.FilePath, c.StartLine, c.EndLine)
+		if c.SymbolName != "" {
+			fmt.Fprintf(&b, " `%s`", c.SymbolName)
+		}
+		if c.Kind != "" {
+			fmt.Fprintf(&b, " (%s)", c.Kind)
+		}
+		lang, _ := parser.DetectLanguage(c.FilePath)
+		fmt.Fprintf(&b, "\n\n```%s\n%s\n```\n", lang, strings.TrimRight(c.Content, "\n"))
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "\n*%d more matches did not fit the budget; raise tokens or use `search_code`.*\n", omitted)
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseContextURI(t *testing.T) {
+	req, err := parseContextURI("codeindex://relevant?query=add+retry+to+upload&tokens=2000&repo=r3&module=fisio.imports")
+	require.NoError(t, err)
+	assert.Equal(t, contextRequest{query: "add retry to upload", repo: "r3", module: "fisio.imports", maxTokens: 2000}, req)
+
+	req, err = parseContextURI("codeindex://relevant?task=fix%20login")
+	require.NoError(t, err)
+	assert.Equal(t, "fix login", req.query)
+	assert.Zero(t, req.maxTokens)
+
+	_, err = parseContextURI("codeindex://relevant?query=x&tokens=-5")
+	assert.Error(t, err)
+}
+
+func TestPackContext(t *testing.T) {
+	body := strings.Repeat("x", 400) // ~100 tokens
+	candidates := []chunk.Chunk{
+		{ID: "summary", FilePath: "a.py", Kind: chunk.KindFileSummary, StartLine: 1, EndLine: 90, Content: "File: a.py"},
+		{ID: "class", FilePath: "a.py", Kind: "class", StartLine: 10, EndLine: 60, Content: body},
+		{ID: "method", FilePath: "a.py", Kind: "method", StartLine: 20, EndLine: 30, Content: body},
+		{ID: "class", FilePath: "a.py", Kind: "class", StartLine: 10, EndLine: 60, Content: body},
+		{ID: "big", FilePath: "b.py", StartLine: 1, EndLine: 400, Content: strings.Repeat("x", 4000)},
+		{ID: "other", FilePath: "b.py", StartLine: 500, EndLine: 510, Content: body},
+	}
+
+	packed, omitted := packContext(candidates, 300)
+	ids := make([]string, len(packed))
+	for i, c := range packed {
+		ids[i] = c.ID
+	}
+	assert.Equal(t, []string{"class", "other"}, ids)
+	assert.Equal(t, 1, omitted, "only the chunk too big for the budget counts as left out")
+}
+
+func TestOrderByDependency(t *testing.T) {
+	handler := &Handler{}
+	chunks := []chunk.Chunk{
+		{ID: "upload", SymbolName: "upload", Content: "def upload(path):\n    return put(read_file(path))"},
+		{ID: "put", SymbolName: "put", Content: "def put(data):\n    return client.send(data)"},
+		{ID: "unrelated", SymbolName: "log_event", Content: "def log_event(e):\n    pass"},
+		{ID: "read", SymbolName: "read_file", Content: "def read_file(path):\n    return open(path).read()"},
+	}
+	calls := handler.contextCalls(context.Background(), "r3", chunks)
+	assert.Equal(t, []int{1, 3}, calls[0])
+
+	ordered := orderByDependency(chunks, calls)
+	ids := make([]string, len(ordered))
+	for i, c := range ordered {
+		ids[i] = c.ID
+	}
+	assert.Equal(t, []string{"put", "read", "upload", "unrelated"}, ids)
+}
+
+func TestOrderByDependencyBreaksCycles(t *testing.T) {
+	chunks := []chunk.Chunk{{ID: "a"}, {ID: "b"}}
+	ordered := orderByDependency(chunks, [][]int{{1}, {0}})
+	assert.Equal(t, "b", ordered[0].ID)
+	assert.Equal(t, "a", ordered[1].ID)
+}
+
+func TestRenderContext(t *testing.T) {
+	text := renderContext("add retry", "r3", []chunk.Chunk{
+		{FilePath: "upload.py", StartLine: 3, EndLine: 4, SymbolName: "upload", Kind: "function", Content: "def upload():\n    pass\n"},
+	}, 1000, 2)
+
+	assert.Contains(t, text, "# Context: add retry\n")
+	assert.Contains(t, text, "Repo `r3`. 1 chunks")
+	assert.Contains(t, text, "## `upload.py:3-4` `upload` (function)\n\n```python\ndef upload():\n    pass\n```\n")
+	assert.Contains(t, text, "2 more matches did not fit the budget")
+}
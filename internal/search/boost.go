@@ -0,0 +1,51 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+)
+
+// adjustBoosts scales the retrieval weight of chunks matching
+// search.boosts, so applyWeights and keyword ranking favor or demote them.
+func (h *Handler) adjustBoosts(chunks []chunk.Chunk) {
+	if h.config == nil || len(h.config.Search.Boosts) == 0 {
+		return
+	}
+	for i := range chunks {
+		chunks[i].RetrievalWeight *= boostFactor(h.config.Search.Boosts, chunks[i])
+	}
+}
+
+// boostFactor is the product of the weights of the boosts c matches.
+func boostFactor(boosts []config.BoostConfig, c chunk.Chunk) float32 {
+	factor := 1.0
+	for _, b := range boosts {
+		if b.Weight > 0 && boostMatches(b, c) {
+			factor *= b.Weight
+		}
+	}
+	return float32(factor)
+}
+
+// boostMatches reports whether c is in the boost's repo, module, and path;
+// a boost without any of them matches nothing.
+func boostMatches(b config.BoostConfig, c chunk.Chunk) bool {
+	if b.Repo == "" && b.Module == "" && b.Path == "" {
+		return false
+	}
+	if b.Repo != "" && b.Repo != c.Repo {
+		return false
+	}
+	if b.Module != "" && c.ModulePath != b.Module && !strings.HasPrefix(c.ModulePath, b.Module+".") {
+		return false
+	}
+	if b.Path != "" {
+		dir := strings.TrimSuffix(strings.TrimPrefix(b.Path, "./"), "/")
+		if c.FilePath != dir && !strings.HasPrefix(c.FilePath, dir+"/") {
+			return false
+		}
+	}
+	return true
+}
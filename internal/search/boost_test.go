@@ -0,0 +1,51 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjustBoosts(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Search.Boosts = []config.BoostConfig{
+		{Repo: "r3", Weight: 1.5},
+		{Module: "fisio.core", Weight: 2},
+		{Path: "legacy/", Weight: 0.5},
+		{Repo: "m32rimm", Path: "./vendor", Weight: 0.1},
+		{Repo: "r3", Weight: 0}, // Ignored
+		{Weight: 3},             // Matches nothing
+	}
+	handler := &Handler{config: cfg}
+
+	chunks := []chunk.Chunk{
+		{ID: "core", Repo: "r3", ModulePath: "fisio.core.users", FilePath: "fisio/core/users.py", RetrievalWeight: 1},
+		{ID: "corelib", Repo: "m32rimm", ModulePath: "fisio.corelib", FilePath: "fisio/corelib.py", RetrievalWeight: 1},
+		{ID: "legacy", Repo: "m32rimm", FilePath: "legacy/old.py", RetrievalWeight: 0.5},
+		{ID: "vendor", Repo: "m32rimm", FilePath: "vendor/lib.py", RetrievalWeight: 1},
+		{ID: "vendored", Repo: "m32rimm", FilePath: "vendored/lib.py", RetrievalWeight: 1},
+	}
+	handler.adjustBoosts(chunks)
+
+	assert.InDelta(t, 3.0, chunks[0].RetrievalWeight, 1e-6, "repo and module boosts multiply")
+	assert.InDelta(t, 1.0, chunks[1].RetrievalWeight, 1e-6, "module prefixes match whole segments")
+	assert.InDelta(t, 0.25, chunks[2].RetrievalWeight, 1e-6)
+	assert.InDelta(t, 0.1, chunks[3].RetrievalWeight, 1e-6)
+	assert.InDelta(t, 1.0, chunks[4].RetrievalWeight, 1e-6, "path prefixes match whole directories")
+}
+
+func TestApplyWeightsRanksBoostedResults(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Search.Boosts = []config.BoostConfig{{Path: "core/", Weight: 2}}
+	handler := &Handler{config: cfg}
+
+	chunks := []chunk.Chunk{
+		{ID: "legacy", FilePath: "legacy/a.py", Score: 0.8, RetrievalWeight: 1},
+		{ID: "core", FilePath: "core/a.py", Score: 0.5, RetrievalWeight: 1},
+	}
+	handler.adjustBoosts(chunks)
+	ranked := handler.applyWeights(chunks, 2)
+	assert.Equal(t, "core", ranked[0].ID)
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+var (
+	// ErrGraphUnavailable means the query needs Neo4j and none is connected.
+	ErrGraphUnavailable = errors.New("graph store not configured")
+	// ErrRepoNotPermitted means the caller's token does not cover the repo.
+	ErrRepoNotPermitted = errors.New("repo not permitted for this token")
+	// ErrRepoRequired means no repo was given and none could be inferred.
+	ErrRepoRequired = errors.New("repo is required")
+)
+
+// SymbolRef locates a symbol found through the graph.
+type SymbolRef struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// CallersResponse lists the symbols that call a symbol.
+type CallersResponse struct {
+	Symbol  string      `json:"symbol"`
+	Repo    string      `json:"repo"`
+	Callers []SymbolRef `json:"callers"`
+}
+
+// CalleesResponse lists the symbols a symbol calls.
+type CalleesResponse struct {
+	Symbol  string      `json:"symbol"`
+	Repo    string      `json:"repo"`
+	Callees []SymbolRef `json:"callees"`
+}
+
+// Callers returns the symbols with a CALLS edge to symbol. An empty repo is
+// inferred the same way the tools infer it.
+func (h *Handler) Callers(ctx context.Context, repo, symbol string) (*CallersResponse, error) {
+	repo, refs, err := h.callGraph(ctx, repo, symbol, (*graph.Neo4jStore).FindCallers)
+	if err != nil {
+		return nil, fmt.Errorf("find callers: %w", err)
+	}
+	return &CallersResponse{Symbol: symbol, Repo: repo, Callers: refs}, nil
+}
+
+// Callees returns the symbols symbol has a CALLS edge to, with the same repo
+// handling as Callers.
+func (h *Handler) Callees(ctx context.Context, repo, symbol string) (*CalleesResponse, error) {
+	repo, refs, err := h.callGraph(ctx, repo, symbol, (*graph.Neo4jStore).FindCallees)
+	if err != nil {
+		return nil, fmt.Errorf("find callees: %w", err)
+	}
+	return &CalleesResponse{Symbol: symbol, Repo: repo, Callees: refs}, nil
+}
+
+// callGraph resolves and authorizes repo, then runs a CALLS-edge query,
+// dropping symbols of modules the caller may not read.
+func (h *Handler) callGraph(ctx context.Context, repo, symbol string, find func(*graph.Neo4jStore, context.Context, string, string) ([]graph.Symbol, error)) (string, []SymbolRef, error) {
+	repo, err := h.graphRepo(ctx, repo)
+	if err != nil {
+		return "", nil, err
+	}
+
+	done := timeStage(ctx, stageGraph)
+	symbols, err := find(h.graphStore, ctx, repo, symbol)
+	done()
+	if err != nil {
+		return "", nil, err
+	}
+	paths := make([]string, len(symbols))
+	for i, s := range symbols {
+		paths[i] = s.FilePath
+	}
+	allowed, err := h.readableFiles(ctx, repo, paths)
+	if err != nil {
+		return "", nil, err
+	}
+
+	refs := make([]SymbolRef, 0, len(symbols))
+	for _, s := range symbols {
+		if allowed != nil && !allowed(s.FilePath) {
+			continue // In a module the caller may not read
+		}
+		refs = append(refs, SymbolRef{
+			Name:      s.Name,
+			Kind:      s.Kind,
+			FilePath:  s.FilePath,
+			StartLine: s.StartLine,
+			EndLine:   s.EndLine,
+			Signature: s.Signature,
+		})
+	}
+	return repo, refs, nil
+}
+
+// graphRepo resolves and authorizes the repo of a graph query; an empty repo
+// is inferred the same way the tools infer it.
+func (h *Handler) graphRepo(ctx context.Context, repo string) (string, error) {
+	if h.graphStore == nil {
+		return "", ErrGraphUnavailable
+	}
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	if repo == "" || repo == "all" {
+		return "", ErrRepoRequired
+	}
+	if !mcp.RepoAllowed(ctx, repo) {
+		return "", ErrRepoNotPermitted
+	}
+	return repo, nil
+}
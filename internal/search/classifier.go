@@ -4,6 +4,8 @@ package search
 import (
 	"regexp"
 	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
 )
 
 // QueryType represents the type of search query.
@@ -25,6 +27,7 @@ type Classifier struct {
 	flowWords         []string
 	patternWords      []string
 	patternRegexes    []*regexp.Regexp
+	strategies        config.StrategiesConfig
 }
 
 // NewClassifier creates a new query classifier.
@@ -58,6 +61,7 @@ func NewClassifier() *Classifier {
 			"structure of",
 			"example of",
 		},
+		strategies: config.DefaultConfig().Search.Strategies,
 	}
 
 	// Compile pattern regexes
@@ -73,7 +77,12 @@ func NewClassifier() *Classifier {
 func (c *Classifier) Classify(query string) QueryType {
 	lower := strings.ToLower(query)
 
-	// Check for quoted terms (explicit symbol lookup) - highest priority
+	// Relationship phrasings name their target, quoted or not
+	if _, ok := parseRelationship(query); ok {
+		return QueryTypeRelationship
+	}
+
+	// Check for quoted terms (explicit symbol lookup)
 	if c.quotedTermRe.MatchString(query) {
 		return QueryTypeSymbol
 	}
@@ -144,48 +153,42 @@ func containsWord(text, word string) bool {
 	return true
 }
 
+// SetStrategies replaces the default routing, e.g. with search.strategies
+// from the config file.
+func (c *Classifier) SetStrategies(strategies config.StrategiesConfig) {
+	c.strategies = strategies
+}
+
 // Route returns the retrieval strategy for a query type.
 func (c *Classifier) Route(qt QueryType) RetrievalStrategy {
+	var sc config.StrategyConfig
 	switch qt {
 	case QueryTypeSymbol:
-		return RetrievalStrategy{
-			UseSemanticSearch: false,
-			UseSymbolIndex:    true,
-			UseGraphExpansion: false,
-			MaxResults:        10,
-		}
+		sc = c.strategies.Symbol
 	case QueryTypeRelationship:
-		return RetrievalStrategy{
-			UseSemanticSearch: false,
-			UseSymbolIndex:    true,
-			UseGraphExpansion: true,
-			GraphDepth:        1,
-			MaxResults:        20,
-		}
+		sc = c.strategies.Relationship
 	case QueryTypeFlow:
-		return RetrievalStrategy{
-			UseSemanticSearch: true,
-			UseSymbolIndex:    false,
-			UseGraphExpansion: true,
-			GraphDepth:        3,
-			MaxResults:        15,
-		}
+		sc = c.strategies.Flow
 	case QueryTypePattern:
-		return RetrievalStrategy{
-			UseSemanticSearch: false,
-			UsePatternIndex:   true,
-			UseGraphExpansion: false,
-			MaxResults:        5,
-		}
+		sc = c.strategies.Pattern
 	default: // Concept
-		return RetrievalStrategy{
-			UseSemanticSearch: true,
-			UseSymbolIndex:    false,
-			UseGraphExpansion: true,
-			GraphDepth:        1,
-			MaxResults:        10,
-		}
+		sc = c.strategies.Concept
+	}
+
+	strategy := RetrievalStrategy{
+		UseGraphExpansion: sc.GraphExpansion,
+		GraphDepth:        sc.GraphDepth,
+		MaxResults:        sc.MaxResults,
+	}
+	switch sc.Retrieval {
+	case "symbol":
+		strategy.UseSymbolIndex = true
+	case "pattern":
+		strategy.UsePatternIndex = true
+	default:
+		strategy.UseSemanticSearch = true
 	}
+	return strategy
 }
 
 // RetrievalStrategy defines how to execute a search.
@@ -196,6 +199,7 @@ type RetrievalStrategy struct {
 	UseGraphExpansion bool
 	GraphDepth        int
 	MaxResults        int
+	Hybrid            *bool // Forces keyword fusion into semantic search on or off; nil follows search.hybrid
 }
 
 // extractSymbolName extracts a symbol name from a query.
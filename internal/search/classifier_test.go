@@ -3,6 +3,7 @@ package search
 import (
 	"testing"
 
+	"github.com/randalmurphal/code-indexer/internal/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,6 +29,9 @@ func TestClassifyQuery(t *testing.T) {
 		{`who imports auth module`, QueryTypeRelationship},
 		{`what depends on database`, QueryTypeRelationship},
 		{`show references to config`, QueryTypeRelationship},
+		{`what calls "validateToken"`, QueryTypeRelationship},
+		{`where is process_payment used`, QueryTypeRelationship},
+		{`callers of getUserById`, QueryTypeRelationship},
 
 		// Flow queries
 		{`data flow from API to database`, QueryTypeFlow},
@@ -105,3 +109,21 @@ func TestRouteStrategy(t *testing.T) {
 	strategy = classifier.Route(QueryTypePattern)
 	assert.True(t, strategy.UsePatternIndex)
 }
+
+func TestRouteConfiguredStrategy(t *testing.T) {
+	strategies := config.DefaultConfig().Search.Strategies
+	strategies.Symbol = config.StrategyConfig{Retrieval: "semantic", GraphExpansion: true, GraphDepth: 2, MaxResults: 30}
+	strategies.Pattern.Retrieval = "bogus"
+
+	classifier := NewClassifier()
+	classifier.SetStrategies(strategies)
+
+	assert.Equal(t, RetrievalStrategy{
+		UseSemanticSearch: true,
+		UseGraphExpansion: true,
+		GraphDepth:        2,
+		MaxResults:        30,
+	}, classifier.Route(QueryTypeSymbol))
+	assert.True(t, classifier.Route(QueryTypePattern).UseSemanticSearch, "unknown retrieval falls back to semantic")
+	assert.Equal(t, 3, classifier.Route(QueryTypeFlow).GraphDepth, "unset types keep their defaults")
+}
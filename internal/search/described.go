@@ -0,0 +1,101 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/docs"
+)
+
+// adjustDescribed scales the retrieval weight of code that a doc site
+// section among chunks describes by search.described_boost, so conceptual
+// docs matching a query lift the code they document.
+func (h *Handler) adjustDescribed(chunks []chunk.Chunk) {
+	boost := config.DefaultConfig().Search.DescribedBoost
+	if h.config != nil {
+		boost = h.config.Search.DescribedBoost
+	}
+	if boost <= 0 || boost == 1 {
+		return
+	}
+	described := describedCode(chunks)
+	if len(described) == 0 {
+		return
+	}
+	for i := range chunks {
+		if chunks[i].Type != chunk.ChunkTypeDoc && isDescribed(described, chunks[i]) {
+			chunks[i].RetrievalWeight *= float32(boost)
+		}
+	}
+}
+
+// describedRef is a symbol or a file path (or path suffix) that a doc site
+// section of a repo describes. A symbol is a name, optionally narrowed to
+// files ending in one of files and to methods of the class parent.
+type describedRef struct {
+	repo   string
+	name   string
+	parent string
+	files  []string
+	suffix string
+}
+
+// describedCode collects what the site sections among chunks describe.
+func describedCode(chunks []chunk.Chunk) []describedRef {
+	var refs []describedRef
+	for _, c := range chunks {
+		if c.Kind != "site" {
+			continue
+		}
+		symbols, modules, files := docs.Describes(c)
+		for _, target := range symbols {
+			for _, t := range docs.SymbolTargets(target) {
+				refs = append(refs, describedRef{repo: c.Repo, name: t.Name, parent: t.Parent, files: t.Files})
+			}
+		}
+		for _, target := range modules {
+			for _, f := range docs.ModuleFiles(target) {
+				refs = append(refs, describedRef{repo: c.Repo, suffix: f})
+			}
+		}
+		for _, f := range files {
+			refs = append(refs, describedRef{repo: c.Repo, suffix: f})
+		}
+	}
+	return refs
+}
+
+// isDescribed reports whether c is a symbol or file one of refs names.
+func isDescribed(refs []describedRef, c chunk.Chunk) bool {
+	for _, r := range refs {
+		if r.repo != c.Repo {
+			continue
+		}
+		if r.name != "" && r.name == c.SymbolName && inFiles(c.FilePath, r.files) &&
+			(r.parent == "" || strings.Contains(c.ContextHeader, "# Class: "+r.parent+"\n")) {
+			return true
+		}
+		if r.suffix != "" && hasPathSuffix(c.FilePath, r.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// inFiles reports whether path ends in one of suffixes, or suffixes is empty.
+func inFiles(path string, suffixes []string) bool {
+	if len(suffixes) == 0 {
+		return true
+	}
+	for _, s := range suffixes {
+		if hasPathSuffix(path, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPathSuffix(path, suffix string) bool {
+	return path == suffix || strings.HasSuffix(path, "/"+suffix)
+}
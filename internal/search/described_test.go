@@ -0,0 +1,44 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/docs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjustDescribed(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	chunks := []chunk.Chunk{
+		{ID: "site", Repo: "app", Type: chunk.ChunkTypeDoc, Kind: "site", FilePath: "docs/api.md", RetrievalWeight: 1.2, Metadata: map[string]string{
+			docs.MetaDescribesSymbols: "app.client.Client,app.http.Client.get",
+			docs.MetaDescribesModules: "app.scheduler",
+			docs.MetaDescribesFiles:   "app/errors.py",
+		}},
+		{ID: "symbol", Repo: "app", Type: chunk.ChunkTypeCode, SymbolName: "Client", FilePath: "app/client.py", RetrievalWeight: 1},
+		{ID: "module", Repo: "app", Type: chunk.ChunkTypeCode, SymbolName: "schedule", FilePath: "src/app/scheduler.py", RetrievalWeight: 1},
+		{ID: "file", Repo: "app", Type: chunk.ChunkTypeCode, SymbolName: "ClientError", FilePath: "app/errors.py", RetrievalWeight: 1},
+		{ID: "other", Repo: "app", Type: chunk.ChunkTypeCode, SymbolName: "retry", FilePath: "app/client.py", RetrievalWeight: 1},
+		{ID: "other-repo", Repo: "lib", Type: chunk.ChunkTypeCode, SymbolName: "Client", FilePath: "lib/client.py", RetrievalWeight: 1},
+		{ID: "method", Repo: "app", Type: chunk.ChunkTypeCode, SymbolName: "get", FilePath: "app/http.py", ContextHeader: "# File: app/http.py\n# Class: Client\n", RetrievalWeight: 1},
+		{ID: "other-class", Repo: "app", Type: chunk.ChunkTypeCode, SymbolName: "get", FilePath: "app/http.py", ContextHeader: "# File: app/http.py\n# Class: Session\n", RetrievalWeight: 1},
+		{ID: "other-module", Repo: "app", Type: chunk.ChunkTypeCode, SymbolName: "get", FilePath: "app/cache.py", RetrievalWeight: 1},
+	}
+	handler.adjustDescribed(chunks)
+
+	assert.InDelta(t, 1.2, chunks[0].RetrievalWeight, 1e-6, "docs are not boosted")
+	assert.InDelta(t, 1.3, chunks[1].RetrievalWeight, 1e-6, "described symbol")
+	assert.InDelta(t, 1.3, chunks[2].RetrievalWeight, 1e-6, "file of a described module")
+	assert.InDelta(t, 1.3, chunks[3].RetrievalWeight, 1e-6, "linked file")
+	assert.InDelta(t, 1.0, chunks[4].RetrievalWeight, 1e-6)
+	assert.InDelta(t, 1.0, chunks[5].RetrievalWeight, 1e-6, "descriptions stay within their repo")
+	assert.InDelta(t, 1.3, chunks[6].RetrievalWeight, 1e-6, "described method")
+	assert.InDelta(t, 1.0, chunks[7].RetrievalWeight, 1e-6, "same name in another class")
+	assert.InDelta(t, 1.0, chunks[8].RetrievalWeight, 1e-6, "same name in another module")
+
+	handler.config.Search.DescribedBoost = 0
+	handler.adjustDescribed(chunks)
+	assert.InDelta(t, 1.3, chunks[1].RetrievalWeight, 1e-6, "0 disables")
+}
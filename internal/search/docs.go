@@ -0,0 +1,111 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/docs"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// DocResult is one documentation section returned by search_docs.
+type DocResult struct {
+	Repo        string  `json:"repo"`
+	FilePath    string  `json:"file_path"`
+	HeadingPath string  `json:"heading_path,omitempty"`
+	Kind        string  `json:"kind"`             // navigation | site | adr | pattern
+	Status      string  `json:"status,omitempty"` // ADRs: accepted, superseded, ...
+	StartLine   int     `json:"start_line,omitempty"`
+	EndLine     int     `json:"end_line,omitempty"`
+	Score       float32 `json:"score"`
+	Content     string  `json:"content"`
+}
+
+func (h *Handler) searchDocs(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	query, _ := args["query"].(string)
+	if query == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "query parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+	if h.store == nil || h.embedder == nil {
+		return nil, fmt.Errorf("vector store not configured")
+	}
+
+	repo, module := h.scope(ctx, args)
+	kind, _ := args["kind"].(string)
+
+	limit := 5
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	filter := map[string]interface{}{
+		"type": string(chunk.ChunkTypeDoc),
+	}
+	if repo != "" && repo != "all" {
+		filter["repo"] = repo
+	}
+	if module != "" {
+		filter["module_root"] = module
+	}
+	if kind != "" {
+		filter["kind"] = kind
+	}
+
+	vectors, err := h.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+
+	chunks, err := h.store.Search(ctx, "chunks", vectors[0], limit, filter)
+	if err != nil {
+		return nil, fmt.Errorf("search_docs failed: %w", err)
+	}
+
+	if len(chunks) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("No documentation matches %q. Try search_code for code and docs together.", query)}},
+		}, nil
+	}
+
+	results := make([]DocResult, len(chunks))
+	for i, c := range chunks {
+		results[i] = toDocResult(c)
+	}
+
+	data, _ := json.MarshalIndent(map[string]interface{}{
+		"query":   query,
+		"results": results,
+	}, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+// toDocResult converts a doc chunk, labelling the section with its file when
+// it has no heading (pattern chunks are named by their pattern instead).
+func toDocResult(c chunk.Chunk) DocResult {
+	heading := c.HeadingPath
+	switch {
+	case heading == "" && c.Kind == "pattern":
+		heading = c.SymbolName + " pattern"
+	case heading == "":
+		heading = c.FilePath
+	}
+
+	return DocResult{
+		Repo:        c.Repo,
+		FilePath:    c.FilePath,
+		HeadingPath: heading,
+		Kind:        c.Kind,
+		Status:      c.Metadata[docs.MetaADRStatus],
+		StartLine:   c.StartLine,
+		EndLine:     c.EndLine,
+		Score:       c.Score,
+		Content:     c.Content,
+	}
+}
@@ -0,0 +1,39 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToDocResult(t *testing.T) {
+	nav := toDocResult(chunk.Chunk{
+		Repo: "r3", FilePath: "imports/AGENTS.md", Kind: "navigation",
+		HeadingPath: "Imports > Retries", StartLine: 12, EndLine: 20, Score: 0.8, Content: "Retry 3 times.",
+	})
+	assert.Equal(t, "Imports > Retries", nav.HeadingPath)
+	assert.Equal(t, 12, nav.StartLine)
+
+	pattern := toDocResult(chunk.Chunk{Repo: "r3", FilePath: "imports/aws.py", Kind: "pattern", SymbolName: "Importer"})
+	assert.Equal(t, "Importer pattern", pattern.HeadingPath)
+
+	bare := toDocResult(chunk.Chunk{Repo: "r3", FilePath: "AGENTS.md", Kind: "navigation"})
+	assert.Equal(t, "AGENTS.md", bare.HeadingPath)
+	assert.Empty(t, bare.Status)
+
+	adr := toDocResult(chunk.Chunk{Repo: "r3", FilePath: "docs/adr/0007-use-kafka.md", Kind: "adr", HeadingPath: "ADR 0007: Use Kafka", Metadata: map[string]string{"adr_status": "superseded"}})
+	assert.Equal(t, "superseded", adr.Status)
+}
+
+func TestSearchDocsRequiresQuery(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "search_docs", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "query parameter is required")
+}
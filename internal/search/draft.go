@@ -0,0 +1,91 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+// classKinds are the symbol kinds a draft lists as key classes rather than
+// entry points.
+var classKinds = map[string]bool{
+	"class":         true,
+	"class_summary": true,
+	"interface":     true,
+	"struct":        true,
+}
+
+// RenderAgentsDraft formats an overview as a draft AGENTS.md for a module
+// without navigation docs: entry points (its most-called functions), key
+// classes, patterns, and dependencies, with TODOs where only a person
+// knows the answer.
+func RenderAgentsDraft(o ModuleOverview) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", o.Module)
+	b.WriteString("<!-- Draft generated from the index by `code-indexer docs generate`. Check every line, fill in the TODOs, and delete this comment. -->\n\n")
+	if o.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", o.Description)
+	} else {
+		b.WriteString("TODO: What this module is for, in one or two sentences.\n\n")
+	}
+
+	var entryPoints, classes []ModuleSymbol
+	for _, s := range o.Symbols {
+		if classKinds[s.Kind] {
+			classes = append(classes, s)
+		} else {
+			entryPoints = append(entryPoints, s)
+		}
+	}
+
+	b.WriteString("## Entry Points\n\n")
+	if !o.HasGraph {
+		b.WriteString("<!-- Without the Neo4j graph store these are ranked by retrieval weight, not by callers. -->\n")
+	}
+	if len(entryPoints) == 0 {
+		b.WriteString("TODO: Where to start reading.\n")
+	}
+	for _, s := range entryPoints {
+		fmt.Fprintf(&b, "- `%s` (`%s:%d`)", s.Name, s.FilePath, s.StartLine)
+		if s.Callers > 0 {
+			fmt.Fprintf(&b, ", called from %d places", s.Callers)
+		}
+		b.WriteString(": TODO\n")
+	}
+	b.WriteString("\n")
+
+	if len(classes) > 0 {
+		b.WriteString("## Key Classes\n\n")
+		b.WriteString("| Class | Location | Purpose |\n")
+		b.WriteString("|-------|----------|---------|\n")
+		for _, s := range classes {
+			fmt.Fprintf(&b, "| `%s` | `%s:%d` | TODO |\n", s.Name, s.FilePath, s.StartLine)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(o.Patterns) > 0 {
+		b.WriteString("## Patterns\n\n")
+		for _, p := range o.Patterns {
+			fmt.Fprintf(&b, "- **%s** (%d chunks)", p.Name, p.Chunks)
+			if p.Canonical != "" {
+				fmt.Fprintf(&b, ": follow `%s`", p.Canonical)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Dependencies\n\n")
+	if o.HasGraph {
+		fmt.Fprintf(&b, "- %s\n", dependencySummary("Depends on", o.DependsOn))
+		fmt.Fprintf(&b, "- %s\n", dependencySummary("Used by", o.UsedBy))
+	} else {
+		b.WriteString("TODO: Modules this one imports and is imported by (the Neo4j graph store can list them).\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("## Gotchas\n\n")
+	b.WriteString("- TODO\n")
+	return b.String()
+}
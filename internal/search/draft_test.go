@@ -0,0 +1,46 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderAgentsDraft(t *testing.T) {
+	draft := RenderAgentsDraft(ModuleOverview{
+		Repo:   "r3",
+		Module: "imports",
+		Symbols: []ModuleSymbol{
+			{Name: "run_import", Kind: "function", FilePath: "imports/run.py", StartLine: 12, Callers: 9},
+			{Name: "BaseImporter", Kind: "class", FilePath: "imports/base.py", StartLine: 5, Callers: 4},
+		},
+		Patterns:  []PatternUsage{{Name: "Importer", Chunks: 6, Canonical: "imports/aws.py"}},
+		DependsOn: []graph.ModuleDependency{{Module: "common", Imports: 14}},
+		HasGraph:  true,
+	})
+
+	assert.Contains(t, draft, "# imports\n")
+	assert.Contains(t, draft, "TODO: What this module is for")
+	assert.Contains(t, draft, "## Entry Points\n\n- `run_import` (`imports/run.py:12`), called from 9 places: TODO\n")
+	assert.Contains(t, draft, "| `BaseImporter` | `imports/base.py:5` | TODO |")
+	assert.NotContains(t, draft, "- `BaseImporter`", "classes are not entry points")
+	assert.Contains(t, draft, "- **Importer** (6 chunks): follow `imports/aws.py`")
+	assert.Contains(t, draft, "- Depends on: common (14 imports)\n- Used by: none\n")
+	assert.Contains(t, draft, "## Gotchas")
+}
+
+func TestRenderAgentsDraftWithoutGraph(t *testing.T) {
+	draft := RenderAgentsDraft(ModuleOverview{
+		Repo:        "r3",
+		Module:      "imports",
+		Description: "Pulls vendor data into the warehouse.",
+	})
+
+	assert.Contains(t, draft, "Pulls vendor data into the warehouse.")
+	assert.Contains(t, draft, "ranked by retrieval weight")
+	assert.Contains(t, draft, "TODO: Where to start reading.")
+	assert.NotContains(t, draft, "## Key Classes")
+	assert.NotContains(t, draft, "## Patterns")
+	assert.Contains(t, draft, "TODO: Modules this one imports")
+}
@@ -0,0 +1,243 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+)
+
+// Embedder embeds texts; embedding.VoyageClient is one.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// classifierTemperature sharpens the softmax over centroid similarities;
+// embeddings of short queries sit close together, so raw similarities
+// differ by little.
+const classifierTemperature = 0.05
+
+// classifierExamples are example queries of each type. A query is
+// classified by the type whose examples' centroid it is nearest.
+var classifierExamples = map[QueryType][]string{
+	QueryTypeSymbol: {
+		"UserService class",
+		"validateToken function",
+		"where is parse_config defined",
+		"get_user_by_email",
+		"RetryPolicy",
+		"the handleRequest method",
+		"definition of SessionStore",
+		"find the Config struct",
+	},
+	QueryTypeConcept: {
+		"authentication logic",
+		"how are passwords hashed",
+		"error handling for network timeouts",
+		"uses of the cache",
+		"rate limiting",
+		"code that sends email notifications",
+		"database connection pooling",
+		"where do we validate user input",
+	},
+	QueryTypeRelationship: {
+		"what calls validateToken",
+		"callers of save_user",
+		"what does login call",
+		"what imports auth/tokens.py",
+		"which functions use SessionStore",
+		"files that depend on the config module",
+		"who references parse_header",
+		"dependencies of the billing module",
+	},
+	QueryTypeFlow: {
+		"how does a request get to the database",
+		"flow from upload to storage",
+		"path from the API handler to the queue",
+		"how does a message travel through the pipeline",
+		"trace an order from checkout to fulfillment",
+		"what happens when a user logs in",
+		"call chain from main to the indexer",
+		"how does data flow from the parser to the store",
+	},
+	QueryTypePattern: {
+		"importer pattern",
+		"how do importers work",
+		"typical structure of a service",
+		"convention for writing migrations",
+		"example of a REST handler",
+		"standard way to add a CLI command",
+		"how are plugins usually implemented",
+		"common structure of test fixtures",
+	},
+}
+
+// EmbeddingClassifier classifies queries by the nearest centroid of embedded
+// example queries, with a confidence from how clearly that centroid wins.
+type EmbeddingClassifier struct {
+	embedder Embedder
+
+	mu        sync.Mutex
+	centroids map[QueryType][]float32 // Embedded on first use
+}
+
+// NewEmbeddingClassifier creates a classifier that embeds queries and its
+// examples with embedder.
+func NewEmbeddingClassifier(embedder Embedder) *EmbeddingClassifier {
+	return &EmbeddingClassifier{embedder: embedder}
+}
+
+// Classify returns the query's type, the confidence in it (0-1), and the
+// query's embedding for reuse by the search.
+func (c *EmbeddingClassifier) Classify(ctx context.Context, query string) (QueryType, float64, []float32, error) {
+	centroids, err := c.loadCentroids(ctx)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	done := timeStage(ctx, stageEmbed)
+	vectors, err := c.embedder.Embed(ctx, []string{query})
+	done()
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return "", 0, nil, fmt.Errorf("embed query: got %d vectors", len(vectors))
+	}
+	qt, confidence := nearestCentroid(vectors[0], centroids)
+	return qt, confidence, vectors[0], nil
+}
+
+// loadCentroids embeds the examples once; a failure is retried next call.
+func (c *EmbeddingClassifier) loadCentroids(ctx context.Context) (map[QueryType][]float32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.centroids != nil {
+		return c.centroids, nil
+	}
+
+	var texts []string
+	var types []QueryType
+	for qt, examples := range classifierExamples {
+		for _, ex := range examples {
+			texts = append(texts, ex)
+			types = append(types, qt)
+		}
+	}
+	vectors, err := c.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embed classifier examples: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embed classifier examples: got %d vectors for %d examples", len(vectors), len(texts))
+	}
+
+	centroids := make(map[QueryType][]float32)
+	for i, v := range vectors {
+		sum := centroids[types[i]]
+		if sum == nil {
+			sum = make([]float32, len(v))
+			centroids[types[i]] = sum
+		}
+		for j := range min(len(sum), len(v)) {
+			sum[j] += v[j]
+		}
+	}
+	c.centroids = centroids
+	return centroids, nil
+}
+
+// nearestCentroid returns the type with the most similar centroid and its
+// softmax share of the similarities.
+func nearestCentroid(v []float32, centroids map[QueryType][]float32) (QueryType, float64) {
+	best, bestSim := QueryTypeConcept, math.Inf(-1)
+	sims := make(map[QueryType]float64, len(centroids))
+	for qt, centroid := range centroids {
+		sim := cosine(v, centroid)
+		sims[qt] = sim
+		if sim > bestSim || (sim == bestSim && qt < best) {
+			best, bestSim = qt, sim
+		}
+	}
+	if len(sims) == 0 {
+		return QueryTypeConcept, 0
+	}
+	var total float64
+	for _, sim := range sims {
+		total += math.Exp((sim - bestSim) / classifierTemperature)
+	}
+	return best, 1 / total
+}
+
+// cosine is the cosine similarity of two vectors; 0 when either is zero.
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range min(len(a), len(b)) {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// Classification is the embedding classifier's decision on a query.
+type Classification struct {
+	Method     string  `json:"method"`             // embedding
+	Predicted  string  `json:"predicted"`          // Query type of the nearest centroid
+	Confidence float64 `json:"confidence"`         // Its softmax share of the centroid similarities (0-1)
+	Fallback   bool    `json:"fallback,omitempty"` // Below search.classifier.min_confidence, so searched as concept
+}
+
+// classify returns the query's type, by the embedding classifier when one
+// is set (else, or when it fails, by the rules), with its decision. The
+// returned context carries the query's embedding for searchSemantic.
+func (h *Handler) classify(ctx context.Context, query string) (context.Context, QueryType, *Classification) {
+	if h.embedClass == nil {
+		return ctx, h.classifier.Classify(query), nil
+	}
+	predicted, confidence, vector, err := h.embedClass.Classify(ctx, query)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Warn("embedding classification failed, using rules", "query", query, "error", err)
+		}
+		return ctx, h.classifier.Classify(query), nil
+	}
+
+	decision := &Classification{Method: "embedding", Predicted: string(predicted), Confidence: confidence}
+	queryType := predicted
+	minConfidence := config.DefaultConfig().Search.Classifier.MinConfidence
+	if h.config != nil {
+		minConfidence = h.config.Search.Classifier.MinConfidence
+	}
+	if confidence < minConfidence && predicted != QueryTypeConcept {
+		decision.Fallback = true
+		queryType = QueryTypeConcept
+	}
+	return withQueryVector(ctx, query, vector), queryType, decision
+}
+
+// queryVectorKey carries an already embedded query through the context.
+type queryVectorKey struct{}
+
+type queryVector struct {
+	query  string
+	vector []float32
+}
+
+// withQueryVector lets searchSemantic reuse the embedding of query.
+func withQueryVector(ctx context.Context, query string, vector []float32) context.Context {
+	return context.WithValue(ctx, queryVectorKey{}, queryVector{query: query, vector: vector})
+}
+
+// queryVectorFromContext returns the embedding of query carried by ctx, if
+// any.
+func queryVectorFromContext(ctx context.Context, query string) []float32 {
+	if qv, ok := ctx.Value(queryVectorKey{}).(queryVector); ok && qv.query == query {
+		return qv.vector
+	}
+	return nil
+}
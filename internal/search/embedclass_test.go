@@ -0,0 +1,100 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var classifierAxes = []QueryType{QueryTypeSymbol, QueryTypeConcept, QueryTypeRelationship, QueryTypeFlow, QueryTypePattern}
+
+// fakeEmbedder embeds each example query on its type's axis and other texts
+// as listed in queries.
+type fakeEmbedder struct {
+	queries map[string][]float32
+	err     error
+	calls   int
+}
+
+func (f *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if v, ok := f.queries[text]; ok {
+			vectors[i] = v
+			continue
+		}
+		vectors[i] = make([]float32, len(classifierAxes))
+		for axis, qt := range classifierAxes {
+			for _, ex := range classifierExamples[qt] {
+				if ex == text {
+					vectors[i][axis] = 1
+				}
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func TestEmbeddingClassifier(t *testing.T) {
+	embedder := &fakeEmbedder{queries: map[string][]float32{
+		"SessionStore":       {1, 0, 0, 0, 0},
+		"uses of the config": {0, 1, 1, 0, 0},
+	}}
+	classifier := NewEmbeddingClassifier(embedder)
+
+	qt, confidence, vector, err := classifier.Classify(context.Background(), "SessionStore")
+	require.NoError(t, err)
+	assert.Equal(t, QueryTypeSymbol, qt)
+	assert.Greater(t, confidence, 0.99)
+	assert.Equal(t, []float32{1, 0, 0, 0, 0}, vector)
+
+	qt, confidence, _, err = classifier.Classify(context.Background(), "uses of the config")
+	require.NoError(t, err)
+	assert.Contains(t, []QueryType{QueryTypeConcept, QueryTypeRelationship}, qt)
+	assert.InDelta(t, 0.5, confidence, 0.01, "an even split between two types")
+
+	assert.Equal(t, 3, embedder.calls, "examples are embedded once")
+}
+
+func TestHandlerClassifyFallsBackOnLowConfidence(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Search.Classifier.MinConfidence = 0.6
+	embedder := &fakeEmbedder{queries: map[string][]float32{
+		"uses of the config": {0, 0, 1, 1, 0},
+		"UserService class":  {1, 0, 0, 0, 0},
+	}}
+	handler := &Handler{config: cfg, classifier: NewClassifier(), embedClass: NewEmbeddingClassifier(embedder)}
+
+	ctx, qt, decision := handler.classify(context.Background(), "uses of the config")
+	assert.Equal(t, QueryTypeConcept, qt)
+	require.NotNil(t, decision)
+	assert.Equal(t, "embedding", decision.Method)
+	assert.True(t, decision.Fallback)
+	assert.NotEqual(t, string(QueryTypeConcept), decision.Predicted)
+	assert.Equal(t, []float32{0, 0, 1, 1, 0}, queryVectorFromContext(ctx, "uses of the config"))
+	assert.Nil(t, queryVectorFromContext(ctx, "another query"))
+
+	_, qt, decision = handler.classify(context.Background(), "UserService class")
+	assert.Equal(t, QueryTypeSymbol, qt)
+	assert.False(t, decision.Fallback)
+}
+
+func TestHandlerClassifyUsesRulesWhenEmbeddingFails(t *testing.T) {
+	handler := &Handler{
+		config:     config.DefaultConfig(),
+		classifier: NewClassifier(),
+		embedClass: NewEmbeddingClassifier(&fakeEmbedder{err: errors.New("rate limited")}),
+	}
+	ctx, qt, decision := handler.classify(context.Background(), "what calls validateToken")
+	assert.Equal(t, QueryTypeRelationship, qt)
+	assert.Nil(t, decision)
+	assert.Nil(t, queryVectorFromContext(ctx, "what calls validateToken"))
+}
@@ -0,0 +1,50 @@
+package search
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+)
+
+// ExperimentControl is the arm of an experiment's queries routed as usual.
+const ExperimentControl = "control"
+
+// SetExperiment routes exp.Percent of search_code queries that leave the
+// strategy to the classifier through exp.Strategy instead, tagging their
+// metrics events with the experiment and arm. An experiment without a name
+// disables it; an invalid one is refused and leaves none running.
+func (h *Handler) SetExperiment(exp config.ExperimentConfig) error {
+	h.experiment = config.ExperimentConfig{}
+	if exp.Name == "" {
+		return nil
+	}
+	if exp.Strategy == StrategyAuto || !slices.Contains(searchStrategies, exp.Strategy) {
+		return fmt.Errorf("experiment %q: unsupported strategy %q (supported: %s, %s, %s, %s)", exp.Name, exp.Strategy, StrategySemantic, StrategyHybrid, StrategySymbol, StrategyPattern)
+	}
+	if exp.Percent < 0 || exp.Percent > 100 {
+		return fmt.Errorf("experiment %q: percent %d is not between 0 and 100", exp.Name, exp.Percent)
+	}
+	h.experiment = exp
+	return nil
+}
+
+// experimentArm assigns query to an arm of the running experiment: the
+// experiment's strategy or ExperimentControl. The arm hashes the experiment
+// name and the query, so a query keeps its arm across sessions and cached
+// responses, and feedback on it can be attributed. Queries with a strategy
+// chosen by the caller are not in the experiment (empty name).
+func (h *Handler) experimentArm(query, strategyArg string) (name, arm string) {
+	exp := h.experiment
+	if exp.Name == "" || (strategyArg != "" && strategyArg != StrategyAuto) {
+		return "", ""
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(exp.Name + "\x00" + strings.ToLower(strings.TrimSpace(query))))
+	if int(hash.Sum32()%100) < exp.Percent {
+		return exp.Name, exp.Strategy
+	}
+	return exp.Name, ExperimentControl
+}
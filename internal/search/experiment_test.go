@@ -0,0 +1,48 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetExperiment(t *testing.T) {
+	h := &Handler{}
+	assert.Error(t, h.SetExperiment(config.ExperimentConfig{Name: "x", Percent: 10, Strategy: "auto"}))
+	assert.Error(t, h.SetExperiment(config.ExperimentConfig{Name: "x", Percent: 10, Strategy: "dense"}))
+	assert.Error(t, h.SetExperiment(config.ExperimentConfig{Name: "x", Percent: 101, Strategy: StrategyHybrid}))
+	name, _ := h.experimentArm("auth flow", "")
+	assert.Empty(t, name, "a refused experiment does not run")
+
+	require.NoError(t, h.SetExperiment(config.ExperimentConfig{}))
+	name, _ = h.experimentArm("auth flow", "")
+	assert.Empty(t, name)
+}
+
+func TestExperimentArm(t *testing.T) {
+	h := &Handler{}
+	require.NoError(t, h.SetExperiment(config.ExperimentConfig{Name: "hybrid-vs-dense", Percent: 30, Strategy: StrategySemantic}))
+
+	variant := 0
+	for i := range 1000 {
+		name, arm := h.experimentArm(fmt.Sprintf("query %d", i), "")
+		assert.Equal(t, "hybrid-vs-dense", name)
+		if arm == StrategySemantic {
+			variant++
+		} else {
+			assert.Equal(t, ExperimentControl, arm)
+		}
+	}
+	assert.InDelta(t, 300, variant, 60)
+
+	_, first := h.experimentArm("Auth Flow ", "")
+	_, again := h.experimentArm("auth flow", "auto")
+	assert.Equal(t, first, again, "a query keeps its arm")
+
+	name, arm := h.experimentArm("auth flow", StrategySymbol)
+	assert.Empty(t, name, "callers choosing a strategy are left out")
+	assert.Empty(t, arm)
+}
@@ -0,0 +1,271 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+const (
+	// maxOverviewDocSections bounds how many navigation doc sections an
+	// overview quotes.
+	maxOverviewDocSections = 8
+	// maxOverviewSymbols bounds the overview's key symbol list.
+	maxOverviewSymbols = 15
+)
+
+// PatternUsage is a detected pattern followed by code in a module.
+type PatternUsage struct {
+	Name      string
+	Chunks    int
+	Canonical string // File that best exemplifies the pattern
+}
+
+// ModuleOverview collects everything explain_module renders for a module.
+type ModuleOverview struct {
+	Repo        string
+	Module      string
+	Description string
+	Files       int
+	Docs        []chunk.Chunk
+	Symbols     []ModuleSymbol
+	Patterns    []PatternUsage
+	DependsOn   []graph.ModuleDependency
+	UsedBy      []graph.ModuleDependency
+	HasGraph    bool // Dependencies are only known with Neo4j
+}
+
+func (h *Handler) explainModule(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	module, _ := args["module"].(string)
+	if module == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "module parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+
+	repo, _ := args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	if repo == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "repo parameter is required (could not infer from cwd)"}},
+			IsError: true,
+		}, nil
+	}
+
+	overview, err := h.LoadModuleOverview(ctx, repo, module)
+	if err != nil {
+		return nil, err
+	}
+	if overview.Empty() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("Module %q not found in %s. Use list_modules to see available modules.", module, repo)}},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: renderModuleOverview(*overview)}},
+	}, nil
+}
+
+// LoadModuleOverview collects a module's navigation docs, key symbols (by
+// caller count with Neo4j, else by retrieval weight), patterns, and, with
+// Neo4j, dependencies. A module the index doesn't know comes back Empty.
+func (h *Handler) LoadModuleOverview(ctx context.Context, repo, module string) (*ModuleOverview, error) {
+	if h.store == nil {
+		return nil, fmt.Errorf("vector store not configured")
+	}
+
+	overview := &ModuleOverview{Repo: repo, Module: module, HasGraph: h.graphStore != nil}
+	moduleFilter := map[string]interface{}{
+		"repo":        repo,
+		"module_root": module,
+	}
+
+	docs, err := h.store.SearchByFilter(ctx, "chunks", map[string]interface{}{
+		"repo":        repo,
+		"module_root": module,
+		"kind":        "navigation",
+	}, maxOverviewDocSections)
+	if err != nil {
+		return nil, fmt.Errorf("load module docs: %w", err)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].FilePath != docs[j].FilePath {
+			return docs[i].FilePath < docs[j].FilePath
+		}
+		return docs[i].StartLine < docs[j].StartLine
+	})
+	overview.Docs = docs
+
+	if h.graphStore != nil {
+		if err := h.loadModuleGraph(ctx, overview); err != nil {
+			return nil, err
+		}
+	} else {
+		chunks, err := h.store.SearchByFilter(ctx, "chunks", moduleFilter, maxModuleChunks)
+		if err != nil {
+			return nil, fmt.Errorf("list module chunks: %w", err)
+		}
+		var files []string
+		files, overview.Symbols = summarizeModuleChunks(chunks, maxOverviewSymbols)
+		overview.Files = len(files)
+	}
+
+	patternCounts, err := h.store.CountByField(ctx, "chunks", "follows_pattern", moduleFilter)
+	if err != nil {
+		return nil, fmt.Errorf("count module patterns: %w", err)
+	}
+	overview.Patterns = h.patternUsages(ctx, repo, patternCounts)
+
+	return overview, nil
+}
+
+// Empty reports whether the index knows nothing of the module.
+func (o *ModuleOverview) Empty() bool {
+	return o.Files == 0 && len(o.Docs) == 0 && len(o.Symbols) == 0
+}
+
+// loadModuleGraph fills the overview's description, file count, symbols, and
+// dependencies from Neo4j.
+func (h *Handler) loadModuleGraph(ctx context.Context, overview *ModuleOverview) error {
+	modules, err := h.graphStore.ListModules(ctx, overview.Repo)
+	if err != nil {
+		return fmt.Errorf("list modules: %w", err)
+	}
+	for _, m := range modules {
+		if m.Path == overview.Module {
+			overview.Description = m.Description
+			overview.Files = m.FileCount
+		}
+	}
+
+	symbols, err := h.graphStore.TopModuleSymbols(ctx, overview.Repo, overview.Module, maxOverviewSymbols)
+	if err != nil {
+		return fmt.Errorf("list module symbols: %w", err)
+	}
+	for _, s := range symbols {
+		overview.Symbols = append(overview.Symbols, ModuleSymbol{
+			Name:      s.Name,
+			Kind:      s.Kind,
+			FilePath:  s.FilePath,
+			StartLine: s.StartLine,
+			Callers:   s.CallerCount,
+		})
+	}
+
+	overview.DependsOn, overview.UsedBy, err = h.graphStore.ModuleDependencies(ctx, overview.Repo, overview.Module)
+	if err != nil {
+		return fmt.Errorf("module dependencies: %w", err)
+	}
+
+	return nil
+}
+
+// patternUsages orders a module's patterns by usage and looks up each
+// pattern's canonical file. Lookup failures only drop the canonical file.
+func (h *Handler) patternUsages(ctx context.Context, repo string, counts map[string]int) []PatternUsage {
+	usages := make([]PatternUsage, 0, len(counts))
+	for name, n := range counts {
+		usage := PatternUsage{Name: name, Chunks: n}
+		found, err := h.store.SearchByFilter(ctx, "chunks", map[string]interface{}{
+			"repo":        repo,
+			"kind":        "pattern",
+			"symbol_name": name,
+		}, 1)
+		if err == nil && len(found) > 0 {
+			usage.Canonical = found[0].FilePath
+		}
+		usages = append(usages, usage)
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Chunks != usages[j].Chunks {
+			return usages[i].Chunks > usages[j].Chunks
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	return usages
+}
+
+// renderModuleOverview formats an overview as markdown.
+func renderModuleOverview(o ModuleOverview) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Module %s (%s)\n\n", o.Module, o.Repo)
+	if o.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", o.Description)
+	}
+	if o.Files > 0 {
+		fmt.Fprintf(&b, "%d files indexed.\n\n", o.Files)
+	}
+
+	if len(o.Docs) > 0 {
+		b.WriteString("## Documentation\n\n")
+		for _, d := range o.Docs {
+			heading := d.HeadingPath
+			if heading == "" {
+				heading = d.FilePath
+			}
+			fmt.Fprintf(&b, "### %s\n_%s:%d_\n\n%s\n\n", heading, d.FilePath, d.StartLine, strings.TrimSpace(d.Content))
+		}
+	}
+
+	if len(o.Symbols) > 0 {
+		b.WriteString("## Key Symbols\n\n")
+		for _, s := range o.Symbols {
+			fmt.Fprintf(&b, "- `%s` (%s) %s:%d", s.Name, s.Kind, s.FilePath, s.StartLine)
+			if s.Callers > 0 {
+				fmt.Fprintf(&b, ", %d callers", s.Callers)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(o.Patterns) > 0 {
+		b.WriteString("## Patterns\n\n")
+		for _, p := range o.Patterns {
+			fmt.Fprintf(&b, "- %s: %d chunks", p.Name, p.Chunks)
+			if p.Canonical != "" {
+				fmt.Fprintf(&b, " (canonical example: %s)", p.Canonical)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Dependencies\n\n")
+	if !o.HasGraph {
+		b.WriteString("Unavailable: requires the Neo4j graph store.\n")
+		return b.String()
+	}
+	writeDependencies(&b, "Depends on", o.DependsOn)
+	writeDependencies(&b, "Used by", o.UsedBy)
+
+	return b.String()
+}
+
+func writeDependencies(b *strings.Builder, label string, deps []graph.ModuleDependency) {
+	fmt.Fprintf(b, "%s\n", dependencySummary(label, deps))
+}
+
+// dependencySummary is "<label>: a (3 imports), b (1 imports)", or
+// "<label>: none".
+func dependencySummary(label string, deps []graph.ModuleDependency) string {
+	if len(deps) == 0 {
+		return label + ": none"
+	}
+	parts := make([]string, len(deps))
+	for i, d := range deps {
+		parts[i] = fmt.Sprintf("%s (%d imports)", d.Module, d.Imports)
+	}
+	return label + ": " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderModuleOverview(t *testing.T) {
+	overview := ModuleOverview{
+		Repo:        "r3",
+		Module:      "imports",
+		Description: "Vendor data importers",
+		Files:       12,
+		Docs: []chunk.Chunk{
+			{FilePath: "imports/AGENTS.md", StartLine: 3, HeadingPath: "Imports > Adding a vendor", Content: "Subclass BaseImporter.\n"},
+		},
+		Symbols: []ModuleSymbol{
+			{Name: "BaseImporter", Kind: "class", FilePath: "imports/base.py", StartLine: 10, Callers: 7},
+			{Name: "parse_row", Kind: "function", FilePath: "imports/util.py", StartLine: 4},
+		},
+		Patterns:  []PatternUsage{{Name: "Importer", Chunks: 9, Canonical: "imports/aws.py"}},
+		DependsOn: []graph.ModuleDependency{{Module: "common", Imports: 14}},
+		HasGraph:  true,
+	}
+
+	md := renderModuleOverview(overview)
+
+	assert.Contains(t, md, "# Module imports (r3)")
+	assert.Contains(t, md, "Vendor data importers")
+	assert.Contains(t, md, "12 files indexed.")
+	assert.Contains(t, md, "### Imports > Adding a vendor\n_imports/AGENTS.md:3_\n\nSubclass BaseImporter.")
+	assert.Contains(t, md, "- `BaseImporter` (class) imports/base.py:10, 7 callers")
+	assert.Contains(t, md, "- `parse_row` (function) imports/util.py:4\n")
+	assert.Contains(t, md, "- Importer: 9 chunks (canonical example: imports/aws.py)")
+	assert.Contains(t, md, "Depends on: common (14 imports)")
+	assert.Contains(t, md, "Used by: none")
+}
+
+func TestRenderModuleOverviewWithoutGraph(t *testing.T) {
+	md := renderModuleOverview(ModuleOverview{Repo: "r3", Module: "api", Files: 2})
+
+	assert.Contains(t, md, "requires the Neo4j graph store")
+	assert.NotContains(t, md, "## Key Symbols")
+	assert.NotContains(t, md, "Depends on")
+}
+
+func TestExplainModuleRequiresModule(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "explain_module", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "module parameter is required")
+}
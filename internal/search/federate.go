@@ -0,0 +1,203 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/randalmurphal/code-indexer/internal/store"
+)
+
+// repoListTTL is how long federation reuses the list of indexed repos,
+// which takes a scroll of the whole collection to build.
+const repoListTTL = 5 * time.Minute
+
+// repoList caches the indexed repos, largest first.
+type repoList struct {
+	mu    sync.Mutex
+	repos []string
+	at    time.Time
+}
+
+// retrieveFunc runs one retrieval within filter.
+type retrieveFunc func(ctx context.Context, filter map[string]interface{}, limit int) ([]chunk.Chunk, error)
+
+// indexedRepos returns the repos with chunks, largest first.
+func (h *Handler) indexedRepos(ctx context.Context) ([]string, error) {
+	h.repos.mu.Lock()
+	defer h.repos.mu.Unlock()
+	if h.repos.repos != nil && time.Since(h.repos.at) < repoListTTL {
+		return h.repos.repos, nil
+	}
+	counts, err := h.store.CountByField(ctx, "chunks", "repo", nil)
+	if err != nil {
+		return nil, err
+	}
+	repos := make([]string, 0, len(counts))
+	for repo := range counts {
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if counts[repos[i]] != counts[repos[j]] {
+			return counts[repos[i]] > counts[repos[j]]
+		}
+		return repos[i] < repos[j]
+	})
+	h.repos.repos, h.repos.at = repos, time.Now()
+	return repos, nil
+}
+
+// federate runs retrieve once per repo when a search spans every repo
+// (repo "all" or none inferred), so each repo's best matches compete on
+// rank rather than raw score, then fuses the lists (see fuseFederated).
+// Repos past search.federation.max_repos share one search. Otherwise, and
+// with fewer than two repos, retrieve runs once.
+func (h *Handler) federate(ctx context.Context, query, repo string, filter map[string]interface{}, limit int, retrieve retrieveFunc) ([]chunk.Chunk, error) {
+	if (repo != "" && repo != "all") || h.store == nil || h.config == nil || !h.config.Search.Federation.Enabled {
+		return retrieve(ctx, filter, limit)
+	}
+	cfg := h.config.Search.Federation
+	all, err := h.indexedRepos(ctx)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Warn("federated search unavailable, searching all repos at once", "error", err)
+		}
+		return retrieve(ctx, filter, limit)
+	}
+	var repos []string
+	for _, r := range all {
+		if mcp.RepoAllowed(ctx, r) {
+			repos = append(repos, r)
+		}
+	}
+	if len(repos) < 2 {
+		return retrieve(ctx, filter, limit)
+	}
+
+	partitions := federatedPartitions(filter, repos, cfg.MaxRepos)
+	quota := cfg.Quota
+	if quota <= 0 {
+		quota = (limit + len(partitions) - 1) / len(partitions)
+	}
+
+	// Embed the query once for every partition
+	if queryVectorFromContext(ctx, query) == nil && h.embedder != nil {
+		done := timeStage(ctx, stageEmbed)
+		vectors, err := h.embedder.Embed(ctx, []string{query})
+		done()
+		if err == nil && len(vectors) == 1 {
+			ctx = withQueryVector(ctx, query, vectors[0])
+		}
+	}
+
+	lists := make([][]chunk.Chunk, len(partitions))
+	errs := make([]error, len(partitions))
+	var wg sync.WaitGroup
+	for i, partition := range partitions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lists[i], errs[i] = retrieve(ctx, partition, limit)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			if h.logger != nil {
+				h.logger.Warn("federated search of a repo failed", "repo", partitions[i]["repo"], "error", err)
+			}
+		}
+	}
+	if failed == len(partitions) {
+		return nil, errs[0]
+	}
+	return fuseFederated(lists, quota, limit), nil
+}
+
+// federatedPartitions returns filter scoped to each of the first maxRepos
+// repos, plus one partition excluding them when more repos remain.
+func federatedPartitions(filter map[string]interface{}, repos []string, maxRepos int) []map[string]interface{} {
+	if maxRepos <= 0 || maxRepos >= len(repos) {
+		maxRepos = len(repos)
+	}
+	scoped := func(repo interface{}) map[string]interface{} {
+		f := make(map[string]interface{}, len(filter)+1)
+		for k, v := range filter {
+			f[k] = v
+		}
+		if repo != nil {
+			f["repo"] = repo
+		}
+		return f
+	}
+
+	partitions := make([]map[string]interface{}, 0, maxRepos+1)
+	for _, repo := range repos[:maxRepos] {
+		partitions = append(partitions, scoped(repo))
+	}
+	if maxRepos < len(repos) {
+		rest := scoped(nil)
+		exclude := make(map[string]interface{})
+		if existing, ok := filter[store.MustNot].(map[string]interface{}); ok {
+			for k, v := range existing {
+				exclude[k] = v
+			}
+		}
+		exclude["repo"] = append([]string(nil), repos[:maxRepos]...)
+		rest[store.MustNot] = exclude
+		partitions = append(partitions, rest)
+	}
+	return partitions
+}
+
+// fuseFederated merges ranked per-repo lists by rank, best weighted score
+// first among equal ranks, taking at most quota from each list; leftovers
+// then fill the remaining places in the same order, up to limit.
+func fuseFederated(lists [][]chunk.Chunk, quota, limit int) []chunk.Chunk {
+	type candidate struct {
+		c    chunk.Chunk
+		list int
+		rank int
+	}
+	var candidates []candidate
+	for i, list := range lists {
+		for rank, c := range list {
+			candidates = append(candidates, candidate{c: c, list: i, rank: rank})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].rank != candidates[j].rank {
+			return candidates[i].rank < candidates[j].rank
+		}
+		return candidates[i].c.Score*candidates[i].c.RetrievalWeight > candidates[j].c.Score*candidates[j].c.RetrievalWeight
+	})
+
+	fused := make([]chunk.Chunk, 0, min(limit, len(candidates)))
+	taken := make([]bool, len(candidates))
+	perList := make([]int, len(lists))
+	for i, cand := range candidates {
+		if len(fused) == limit {
+			return fused
+		}
+		if perList[cand.list] < quota {
+			perList[cand.list]++
+			taken[i] = true
+			fused = append(fused, cand.c)
+		}
+	}
+	for i, cand := range candidates {
+		if len(fused) == limit {
+			break
+		}
+		if !taken[i] {
+			fused = append(fused, cand.c)
+		}
+	}
+	return fused
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFederatedPartitions(t *testing.T) {
+	filter := map[string]interface{}{
+		"type":        "code",
+		store.MustNot: map[string]interface{}{"is_test": true},
+	}
+
+	partitions := federatedPartitions(filter, []string{"big", "mid", "small", "tiny"}, 2)
+	require.Len(t, partitions, 3)
+	assert.Equal(t, "big", partitions[0]["repo"])
+	assert.Equal(t, "code", partitions[0]["type"])
+	assert.Equal(t, "mid", partitions[1]["repo"])
+
+	rest := partitions[2]
+	assert.NotContains(t, rest, "repo")
+	assert.Equal(t, map[string]interface{}{
+		"is_test": true,
+		"repo":    []string{"big", "mid"},
+	}, rest[store.MustNot])
+	assert.Equal(t, map[string]interface{}{"is_test": true}, filter[store.MustNot], "the caller's filter is not modified")
+
+	assert.Len(t, federatedPartitions(filter, []string{"a", "b"}, 0), 2, "0 searches every repo on its own")
+}
+
+func TestFuseFederatedQuota(t *testing.T) {
+	list := func(repo string, scores ...float32) []chunk.Chunk {
+		chunks := make([]chunk.Chunk, len(scores))
+		for i, s := range scores {
+			chunks[i] = chunk.Chunk{ID: repo + string(rune('0'+i)), Repo: repo, Score: s, RetrievalWeight: 1}
+		}
+		return chunks
+	}
+	lists := [][]chunk.Chunk{
+		list("big", 0.9, 0.88, 0.87, 0.86),
+		list("small", 0.5, 0.4),
+	}
+
+	ids := func(chunks []chunk.Chunk) []string {
+		out := make([]string, len(chunks))
+		for i, c := range chunks {
+			out[i] = c.ID
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"big0", "small0", "big1", "small1"}, ids(fuseFederated(lists, 2, 4)),
+		"equal ranks interleave, best score first")
+	assert.Equal(t, []string{"big0", "small0", "big1", "small1", "big2"}, ids(fuseFederated(lists, 1, 5)),
+		"leftovers fill the page in rank order once every repo had its quota")
+	assert.Equal(t, []string{"big0", "big1", "big2"}, ids(fuseFederated(lists[:1], 1, 3)))
+	assert.Empty(t, fuseFederated(nil, 1, 5))
+}
@@ -0,0 +1,123 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// feedbackWeights holds the net search_feedback votes (useful minus not
+// useful) per chunk ID, loaded from the metrics log at startup.
+type feedbackWeights struct {
+	mu    sync.RWMutex
+	votes map[string]int
+}
+
+func newFeedbackWeights(votes map[string]int) *feedbackWeights {
+	if votes == nil {
+		votes = make(map[string]int)
+	}
+	return &feedbackWeights{votes: votes}
+}
+
+// vote records a vote and returns the chunk's new net votes.
+func (f *feedbackWeights) vote(id string, useful bool) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if useful {
+		f.votes[id]++
+	} else {
+		f.votes[id]--
+	}
+	return f.votes[id]
+}
+
+func (f *feedbackWeights) net(id string) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.votes[id]
+}
+
+// feedbackFactor is the retrieval weight multiplier for net votes:
+// 1 + step * net, clamped to search.feedback's bounds.
+func (h *Handler) feedbackFactor(net int) float32 {
+	if h.config == nil || h.config.Search.Feedback.Step == 0 || net == 0 {
+		return 1
+	}
+	cfg := h.config.Search.Feedback
+	factor := 1 + cfg.Step*float64(net)
+	if cfg.MinWeight > 0 {
+		factor = max(factor, cfg.MinWeight)
+	}
+	if cfg.MaxWeight > 0 {
+		factor = min(factor, cfg.MaxWeight)
+	}
+	return float32(factor)
+}
+
+// adjustFeedback scales the retrieval weight of chunks with feedback.
+func (h *Handler) adjustFeedback(chunks []chunk.Chunk) {
+	if h.feedback == nil {
+		return
+	}
+	for i := range chunks {
+		chunks[i].RetrievalWeight *= h.feedbackFactor(h.feedback.net(chunks[i].ID))
+	}
+}
+
+// FeedbackResponse confirms a search_feedback vote.
+type FeedbackResponse struct {
+	ID         string  `json:"id"`
+	FilePath   string  `json:"file_path"`
+	SymbolName string  `json:"symbol_name,omitempty"`
+	Useful     bool    `json:"useful"`
+	NetVotes   int     `json:"net_votes"`
+	Weight     float32 `json:"weight"` // Feedback multiplier on the chunk's retrieval weight
+}
+
+func (h *Handler) searchFeedback(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	id, _ := args["id"].(string)
+	useful, ok := args["useful"].(bool)
+	if id == "" || !ok {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "id and useful (true or false) are required"}},
+			IsError: true,
+		}, nil
+	}
+	query, _ := args["query"].(string)
+
+	c, err := h.store.GetChunk(ctx, "chunks", id)
+	if err != nil {
+		return nil, fmt.Errorf("look up result %s: %w", id, err)
+	}
+	if c == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("no indexed chunk with id %q; it may have been reindexed away", id)}},
+			IsError: true,
+		}, nil
+	}
+	if !mcp.RepoAllowed(ctx, c.Repo) {
+		return repoDenied(mcp.TokenInfoFromContext(ctx), c.Repo), nil
+	}
+
+	net := h.feedback.vote(id, useful)
+	if h.metrics != nil {
+		h.metrics.LogFeedback(query, id, c.Repo, c.FilePath, useful)
+	}
+
+	data, _ := json.MarshalIndent(FeedbackResponse{
+		ID:         id,
+		FilePath:   c.FilePath,
+		SymbolName: c.SymbolName,
+		Useful:     useful,
+		NetVotes:   net,
+		Weight:     h.feedbackFactor(net),
+	}, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
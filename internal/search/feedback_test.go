@@ -0,0 +1,51 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedbackAdjustsRetrievalWeight(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig(), feedback: newFeedbackWeights(map[string]int{"liked": 3, "disliked": -20})}
+	handler.feedback.vote("liked", true)
+
+	chunks := []chunk.Chunk{
+		{ID: "liked", RetrievalWeight: 1.0},
+		{ID: "disliked", RetrievalWeight: 1.0},
+		{ID: "test", RetrievalWeight: 0.5},
+	}
+	handler.adjustFeedback(chunks)
+	assert.InDelta(t, 1.4, chunks[0].RetrievalWeight, 1e-6, "4 net votes * 0.1")
+	assert.InDelta(t, 0.5, chunks[1].RetrievalWeight, 1e-6, "clamped to min_weight")
+	assert.InDelta(t, 0.5, chunks[2].RetrievalWeight, 1e-6, "no votes, unchanged")
+
+	handler.config.Search.Feedback.Step = 0
+	chunks[0].RetrievalWeight = 1
+	handler.adjustFeedback(chunks[:1])
+	assert.Equal(t, float32(1), chunks[0].RetrievalWeight, "step 0 disables")
+}
+
+func TestFeedbackFactorClamps(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	assert.Equal(t, float32(2.0), handler.feedbackFactor(50))
+	assert.Equal(t, float32(0.5), handler.feedbackFactor(-50))
+	assert.Equal(t, float32(1), handler.feedbackFactor(0))
+}
+
+func TestSearchFeedbackRequiresArguments(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig(), feedback: newFeedbackWeights(nil)}
+	for _, args := range []map[string]interface{}{
+		{"useful": true},
+		{"id": "abc"},
+		{"id": "abc", "useful": "yes"},
+	} {
+		result, err := handler.CallTool(context.Background(), "search_feedback", args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, args)
+	}
+}
@@ -0,0 +1,284 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/parser"
+	"github.com/randalmurphal/code-indexer/internal/store"
+)
+
+// searchKinds are the kind argument values of search_code.
+var searchKinds = []string{"function", "class", "method", "doc", "pattern", "file", "adr"}
+
+// searchLanguages are the language argument values of search_code.
+var searchLanguages = []string{
+	string(parser.LanguagePython),
+	string(parser.LanguageJavaScript),
+	string(parser.LanguageTypeScript),
+}
+
+// scopeFilters are the optional path, language, kind, branch, dirty, and
+// exclusion arguments of search_code, plus the query's negated terms.
+type scopeFilters struct {
+	pathGlob string
+	language string
+	kind     string
+	branch   string // Branch the chunks were indexed from
+	dirty    string // Chunks of uncommitted changes: include, exclude, or only
+
+	excludeModules  []string // Also excludes their submodules
+	excludePaths    []string // Normalized globs; see excludeGlob
+	negated         []string // Lowercased "-term"s
+	excludeLicenses []string // From search.exclude_licenses, not an argument
+}
+
+// parseScopeFilters reads and validates the scope arguments.
+func parseScopeFilters(args map[string]interface{}) (scopeFilters, error) {
+	var f scopeFilters
+	f.pathGlob, _ = args["path_glob"].(string)
+	f.pathGlob = strings.TrimPrefix(f.pathGlob, "./")
+	if strings.HasSuffix(f.pathGlob, "/") {
+		f.pathGlob += "**" // A directory means everything under it
+	}
+	f.language, _ = args["language"].(string)
+	f.kind, _ = args["kind"].(string)
+	f.branch, _ = args["branch"].(string)
+	f.branch = strings.TrimSpace(f.branch)
+	f.dirty, _ = args["dirty"].(string)
+	if f.dirty == "include" {
+		f.dirty = ""
+	}
+
+	if f.pathGlob != "" && !doublestar.ValidatePattern(f.pathGlob) {
+		return f, fmt.Errorf("invalid path_glob %q", f.pathGlob)
+	}
+	if f.language != "" && !slices.Contains(searchLanguages, f.language) {
+		return f, fmt.Errorf("unsupported language %q (supported: %s)", f.language, strings.Join(searchLanguages, ", "))
+	}
+	if f.kind != "" && !slices.Contains(searchKinds, f.kind) {
+		return f, fmt.Errorf("unknown kind %q (expected one of: %s)", f.kind, strings.Join(searchKinds, ", "))
+	}
+	if f.dirty != "" && f.dirty != "exclude" && f.dirty != "only" {
+		return f, fmt.Errorf("unsupported dirty %q (expected one of: include, exclude, only)", f.dirty)
+	}
+
+	f.excludeModules = listArg(args["exclude_modules"])
+	for _, p := range listArg(args["exclude_paths"]) {
+		glob := excludeGlob(p)
+		if !doublestar.ValidatePattern(glob) {
+			return f, fmt.Errorf("invalid exclude_paths entry %q", p)
+		}
+		f.excludePaths = append(f.excludePaths, glob)
+	}
+	return f, nil
+}
+
+// listArg reads a list argument given as a JSON array or a comma-separated
+// string.
+func listArg(v interface{}) []string {
+	var items []string
+	switch v := v.(type) {
+	case string:
+		items = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+	}
+	var list []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// excludeGlob normalizes an exclude_paths entry the way .gitignore reads
+// it: a trailing slash means everything under the directory, and an entry
+// without any other slash matches at any depth ("migrations/" excludes
+// app/migrations/0001.py). A leading slash anchors it to the repo root.
+func excludeGlob(entry string) string {
+	entry = strings.TrimPrefix(entry, "./")
+	if strings.HasSuffix(entry, "/") {
+		entry += "**"
+	}
+	if anchored, ok := strings.CutPrefix(entry, "/"); ok {
+		return anchored
+	}
+	if !strings.Contains(strings.TrimSuffix(entry, "/**"), "/") {
+		return "**/" + entry
+	}
+	return entry
+}
+
+// exclude drops the negated query terms into f.
+func (f *scopeFilters) exclude(negated []string) {
+	for _, term := range negated {
+		f.negated = append(f.negated, strings.ToLower(term))
+	}
+}
+
+// apply adds the payload conditions for f to filter. A path glob narrows
+// the search to its literal leading directory; keep checks the rest.
+func (f scopeFilters) apply(filter map[string]interface{}) {
+	if dir := globBaseDir(f.pathGlob); dir != "" {
+		filter["dirs"] = dir
+	}
+	if f.language != "" {
+		filter["language"] = f.language
+	}
+	if f.branch != "" {
+		filter["branch"] = f.branch
+	}
+	if f.dirty == "only" {
+		filter["workspace"] = chunk.WorkspaceDirty
+	}
+	f.applyExclusions(filter)
+	switch f.kind {
+	case "":
+	case "doc":
+		filter["type"] = string(chunk.ChunkTypeDoc)
+	case "class":
+		// Large classes are stored as a summary plus their methods
+		filter["kind"] = []string{"class", "class_summary"}
+	case "file":
+		filter["kind"] = chunk.KindFileSummary
+	default:
+		filter["kind"] = f.kind
+	}
+}
+
+// applyExclusions adds the exclusions Qdrant can evaluate: excluded modules
+// by exact path, root-anchored directories, and negated terms by keyword.
+// keep handles submodules, other globs, and terms in paths.
+func (f scopeFilters) applyExclusions(filter map[string]interface{}) {
+	mustNot := make(map[string]interface{})
+	if len(f.excludeModules) > 0 {
+		mustNot["module_path"] = f.excludeModules
+	}
+	var dirs []string
+	for _, glob := range f.excludePaths {
+		if dir := globBaseDir(glob); dir != "" && glob == dir+"/**" {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) > 0 {
+		mustNot["dirs"] = dirs
+	}
+	var terms store.TextTerms
+	for _, term := range f.negated {
+		// The whole identifier only; its parts would exclude far more
+		if tokens := chunk.LexicalTokens(term); len(tokens) > 0 {
+			terms = append(terms, tokens[0])
+		}
+	}
+	if len(terms) > 0 {
+		mustNot[store.LexicalField] = terms
+	}
+	if len(f.excludeLicenses) > 0 {
+		mustNot["license"] = f.excludeLicenses
+	}
+	if f.dirty == "exclude" {
+		mustNot["workspace"] = chunk.WorkspaceDirty
+	}
+	if len(mustNot) > 0 {
+		filter[store.MustNot] = mustNot
+	}
+}
+
+// needsLocalCheck reports whether keep can drop results the payload filter
+// let through, so searches should fetch extra candidates.
+func (f scopeFilters) needsLocalCheck() bool {
+	pathCheck := f.pathGlob != "" && f.pathGlob != globBaseDir(f.pathGlob)+"/**"
+	return pathCheck || f.excludes()
+}
+
+// keep drops chunks outside the path glob, branch, or dirty setting, in an
+// excluded module or path, under an excluded license, or whose path
+// contains a negated term.
+func (f scopeFilters) keep(chunks []chunk.Chunk) []chunk.Chunk {
+	if f.pathGlob == "" && f.branch == "" && f.dirty == "" && !f.excludes() && len(f.excludeLicenses) == 0 {
+		return chunks
+	}
+	kept := chunks[:0]
+	for _, c := range chunks {
+		if f.allows(c) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func (f scopeFilters) allows(c chunk.Chunk) bool {
+	if f.pathGlob != "" {
+		if ok, _ := doublestar.Match(f.pathGlob, c.FilePath); !ok {
+			return false
+		}
+	}
+	if f.branch != "" && c.Branch != f.branch {
+		return false
+	}
+	if dirty := c.Workspace == chunk.WorkspaceDirty; (f.dirty == "exclude" && dirty) || (f.dirty == "only" && !dirty) {
+		return false
+	}
+	if c.License != "" && slices.Contains(f.excludeLicenses, c.License) {
+		return false
+	}
+	for _, module := range f.excludeModules {
+		if c.ModulePath == module || strings.HasPrefix(c.ModulePath, module+".") {
+			return false
+		}
+	}
+	for _, glob := range f.excludePaths {
+		if ok, _ := doublestar.Match(glob, c.FilePath); ok {
+			return false
+		}
+	}
+	path := strings.ToLower(c.FilePath)
+	for _, term := range f.negated {
+		if strings.Contains(path, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// key identifies f in cache keys; empty without scope arguments or
+// excluded licenses.
+func (f scopeFilters) key() string {
+	var key string
+	if f.pathGlob != "" || f.language != "" || f.kind != "" || f.branch != "" || f.dirty != "" || f.excludes() {
+		key = "\x00path:" + f.pathGlob + "\x00language:" + f.language + "\x00kind:" + f.kind + "\x00branch:" + f.branch + "\x00dirty:" + f.dirty +
+			"\x00exclude_modules:" + strings.Join(f.excludeModules, ",") + "\x00exclude_paths:" + strings.Join(f.excludePaths, ",") +
+			"\x00not:" + strings.Join(f.negated, ",")
+	}
+	if len(f.excludeLicenses) > 0 {
+		key += "\x00exclude_licenses:" + strings.Join(f.excludeLicenses, ",")
+	}
+	return key
+}
+
+func (f scopeFilters) excludes() bool {
+	return len(f.excludeModules) > 0 || len(f.excludePaths) > 0 || len(f.negated) > 0
+}
+
+// globBaseDir is the directory a glob is confined to: its path segments
+// before the first one with a glob character ("api/v*/handlers" → "api").
+func globBaseDir(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	var base []string
+	for _, seg := range segments[:len(segments)-1] {
+		if strings.ContainsAny(seg, "*?[{\\") {
+			break
+		}
+		base = append(base, seg)
+	}
+	return strings.Join(base, "/")
+}
@@ -0,0 +1,216 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScopeFilters(t *testing.T) {
+	f, err := parseScopeFilters(map[string]interface{}{
+		"path_glob": "./api/",
+		"language":  "python",
+		"kind":      "class",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, scopeFilters{pathGlob: "api/**", language: "python", kind: "class"}, f)
+
+	_, err = parseScopeFilters(map[string]interface{}{"language": "cobol"})
+	assert.ErrorContains(t, err, "unsupported language")
+	_, err = parseScopeFilters(map[string]interface{}{"kind": "variable"})
+	assert.ErrorContains(t, err, "unknown kind")
+	_, err = parseScopeFilters(map[string]interface{}{"path_glob": "api/[handlers"})
+	assert.ErrorContains(t, err, "invalid path_glob")
+}
+
+func TestScopeFiltersApply(t *testing.T) {
+	tests := []struct {
+		scope scopeFilters
+		want  map[string]interface{}
+	}{
+		{scopeFilters{}, map[string]interface{}{}},
+		{scopeFilters{kind: "function"}, map[string]interface{}{"kind": "function"}},
+		{scopeFilters{kind: "class"}, map[string]interface{}{"kind": []string{"class", "class_summary"}}},
+		{scopeFilters{kind: "doc"}, map[string]interface{}{"type": "doc"}},
+		{scopeFilters{kind: "file"}, map[string]interface{}{"kind": chunk.KindFileSummary}},
+		{scopeFilters{kind: "adr"}, map[string]interface{}{"kind": "adr"}},
+		{scopeFilters{language: "typescript"}, map[string]interface{}{"language": "typescript"}},
+		{scopeFilters{pathGlob: "api/v1/**/*_handler.py"}, map[string]interface{}{"dirs": "api/v1"}},
+		{scopeFilters{pathGlob: "**/*.py"}, map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		filter := map[string]interface{}{}
+		tt.scope.apply(filter)
+		assert.Equal(t, tt.want, filter, "%+v", tt.scope)
+	}
+}
+
+func TestScopeFiltersKeepPathGlob(t *testing.T) {
+	f := scopeFilters{pathGlob: "api/**/*_handler.py"}
+	chunks := []chunk.Chunk{
+		{FilePath: "api/users/user_handler.py"},
+		{FilePath: "api/users/models.py"},
+		{FilePath: "api/auth_handler.py"},
+	}
+
+	kept := f.keep(chunks)
+
+	require.Len(t, kept, 2)
+	assert.Equal(t, "api/users/user_handler.py", kept[0].FilePath)
+	assert.Equal(t, "api/auth_handler.py", kept[1].FilePath)
+	assert.True(t, f.needsLocalCheck())
+	assert.False(t, scopeFilters{pathGlob: "api/**"}.needsLocalCheck(), "the dirs filter alone is exact")
+}
+
+func TestHandlerSearchCodeInvalidScope(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "search_code", map[string]interface{}{
+		"query": "upload handler",
+		"kind":  "module",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "unknown kind")
+}
+
+func TestParseScopeFiltersExclusions(t *testing.T) {
+	f, err := parseScopeFilters(map[string]interface{}{
+		"exclude_modules": "fisio.legacy, fisio.tmp",
+		"exclude_paths":   []interface{}{"generated/", "/build/", "api/v1/*.py", "*.pb.go"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"fisio.legacy", "fisio.tmp"}, f.excludeModules)
+	assert.Equal(t, []string{"**/generated/**", "build/**", "api/v1/*.py", "**/*.pb.go"}, f.excludePaths)
+
+	_, err = parseScopeFilters(map[string]interface{}{"exclude_paths": "api/[v1"})
+	assert.ErrorContains(t, err, "invalid exclude_paths entry")
+}
+
+func TestScopeFiltersApplyExclusions(t *testing.T) {
+	f := scopeFilters{
+		excludeModules: []string{"fisio.legacy"},
+		excludePaths:   []string{"**/generated/**", "build/**", "api/v1/*.py"},
+	}
+	f.exclude([]string{"Deprecated", "oldApi"})
+
+	filter := map[string]interface{}{}
+	f.apply(filter)
+
+	assert.Equal(t, map[string]interface{}{
+		store.MustNot: map[string]interface{}{
+			"module_path":      []string{"fisio.legacy"},
+			"dirs":             []string{"build"},
+			store.LexicalField: store.TextTerms{"deprecated", "oldapi"},
+		},
+	}, filter)
+}
+
+func TestScopeFiltersKeepExclusions(t *testing.T) {
+	f := scopeFilters{
+		excludeModules: []string{"fisio.legacy"},
+		excludePaths:   []string{"**/generated/**"},
+	}
+	f.exclude([]string{"Legacy"})
+	chunks := []chunk.Chunk{
+		{FilePath: "fisio/upload.py", ModulePath: "fisio"},
+		{FilePath: "fisio/legacy/upload.py", ModulePath: "fisio.legacy"},
+		{FilePath: "fisio/legacy/v2/upload.py", ModulePath: "fisio.legacy.v2"},
+		{FilePath: "fisio/legacyish.py", ModulePath: "fisio.legacyish"},
+		{FilePath: "api/generated/client.py", ModulePath: "api.generated"},
+		{FilePath: "fisio/legacy_upload.py", ModulePath: "fisio"},
+	}
+
+	kept := f.keep(chunks)
+
+	require.Len(t, kept, 1)
+	assert.Equal(t, "fisio/upload.py", kept[0].FilePath)
+	assert.True(t, f.needsLocalCheck())
+}
+
+func TestScopeFiltersKeyIncludesExclusions(t *testing.T) {
+	assert.Empty(t, scopeFilters{}.key())
+	negated := scopeFilters{}
+	negated.exclude([]string{"legacy"})
+	assert.NotEqual(t, scopeFilters{}.key(), negated.key())
+	assert.NotEqual(t, negated.key(), scopeFilters{excludeModules: []string{"legacy"}}.key())
+}
+
+func TestScopeFiltersExcludeLicenses(t *testing.T) {
+	f := scopeFilters{excludeLicenses: []string{"GPL-3.0", "AGPL-3.0"}}
+
+	filter := map[string]interface{}{}
+	f.apply(filter)
+	assert.Equal(t, map[string]interface{}{
+		store.MustNot: map[string]interface{}{"license": []string{"GPL-3.0", "AGPL-3.0"}},
+	}, filter)
+
+	kept := f.keep([]chunk.Chunk{
+		{FilePath: "vendor/readline/edit.py", License: "GPL-3.0"},
+		{FilePath: "vendor/six.py", License: "MIT"},
+		{FilePath: "app/main.py"},
+	})
+	require.Len(t, kept, 2)
+	assert.Equal(t, "vendor/six.py", kept[0].FilePath)
+	assert.False(t, f.needsLocalCheck(), "Qdrant evaluates license exclusions")
+	assert.NotEmpty(t, f.key())
+}
+
+func TestScopeFiltersBranch(t *testing.T) {
+	f, err := parseScopeFilters(map[string]interface{}{"branch": " release/2.0 "})
+	require.NoError(t, err)
+	assert.Equal(t, "release/2.0", f.branch)
+
+	filter := map[string]interface{}{}
+	f.apply(filter)
+	assert.Equal(t, map[string]interface{}{"branch": "release/2.0"}, filter)
+
+	kept := f.keep([]chunk.Chunk{
+		{FilePath: "a.py", Branch: "release/2.0"},
+		{FilePath: "b.py", Branch: "main"},
+		{FilePath: "c.py"},
+	})
+	require.Len(t, kept, 1, "graph-expanded chunks from other branches are dropped too")
+	assert.Equal(t, "a.py", kept[0].FilePath)
+	assert.NotEqual(t, scopeFilters{}.key(), f.key())
+}
+
+func TestScopeFiltersDirty(t *testing.T) {
+	chunks := func() []chunk.Chunk {
+		return []chunk.Chunk{{FilePath: "a.py", Workspace: chunk.WorkspaceDirty}, {FilePath: "b.py"}}
+	}
+
+	f, err := parseScopeFilters(map[string]interface{}{"dirty": "exclude"})
+	require.NoError(t, err)
+	filter := map[string]interface{}{}
+	f.apply(filter)
+	assert.Equal(t, map[string]interface{}{store.MustNot: map[string]interface{}{"workspace": "dirty"}}, filter)
+	kept := f.keep(chunks())
+	require.Len(t, kept, 1)
+	assert.Equal(t, "b.py", kept[0].FilePath)
+
+	f, err = parseScopeFilters(map[string]interface{}{"dirty": "only"})
+	require.NoError(t, err)
+	filter = map[string]interface{}{}
+	f.apply(filter)
+	assert.Equal(t, map[string]interface{}{"workspace": "dirty"}, filter)
+	kept = f.keep(chunks())
+	require.Len(t, kept, 1)
+	assert.Equal(t, "a.py", kept[0].FilePath)
+	assert.NotEqual(t, scopeFilters{}.key(), f.key())
+
+	f, err = parseScopeFilters(map[string]interface{}{"dirty": "include"})
+	require.NoError(t, err)
+	assert.Equal(t, scopeFilters{}.key(), f.key())
+	assert.Len(t, f.keep(chunks()), 2)
+
+	_, err = parseScopeFilters(map[string]interface{}{"dirty": "maybe"})
+	assert.Error(t, err)
+}
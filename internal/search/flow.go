@@ -0,0 +1,168 @@
+package search
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+)
+
+const (
+	// flowMaxDepth bounds the CALLS hops of an assembled flow.
+	flowMaxDepth = 6
+	// flowEndpointCandidates is how many symbols each end of a flow query
+	// resolves to.
+	flowEndpointCandidates = 5
+	// flowPathCandidates is how many call chains are compared per query.
+	flowPathCandidates = 10
+)
+
+// flowPatterns read the two ends of a flow query; a query they don't match
+// is all destination.
+var flowPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bfrom\s+(.+?)\s+(?:to|into)\s+(.+)`),
+	regexp.MustCompile(`(?i)^\s*(?:how\s+(?:does|do|is|are|can)\s+)?(.+?)\s+(?:gets?|go|goes|flows?|travels?|reach(?:es)?)\s+(?:to\s+|into\s+)?(.+)`),
+	regexp.MustCompile(`(?i)\b(?:path|route|chain|way)\s+to\s+()(.+)`),
+}
+
+var leadingArticle = regexp.MustCompile(`(?i)^(?:the|a|an)\s+`)
+
+// parseFlow splits a flow query into where the flow starts and where it
+// ends ("how does a request get to the DB" → "request", "DB"). An empty
+// from means the flow starts at an entry point.
+func parseFlow(query string) (from, to string) {
+	clean := func(s string) string {
+		s = strings.TrimRight(strings.TrimSpace(s), "?.!")
+		return leadingArticle.ReplaceAllString(s, "")
+	}
+	for _, re := range flowPatterns {
+		if m := re.FindStringSubmatch(query); m != nil && clean(m[2]) != "" {
+			return clean(m[1]), clean(m[2])
+		}
+	}
+	return "", clean(query)
+}
+
+// FlowAnswer is the call chain assembled for a flow query; the response's
+// results hold the code of its hops in the same order.
+type FlowAnswer struct {
+	From string      `json:"from,omitempty"` // Empty when the chain starts at an entry point
+	To   string      `json:"to"`
+	Repo string      `json:"repo"`
+	Hops []SymbolRef `json:"hops"` // Entry point or start first, target last
+}
+
+// assembleFlow answers a flow query with a call chain from the graph: each
+// end of the query is resolved to its best-matching symbols by semantic
+// search, and the chain joining the best-ranked pair is returned with the
+// chunks of its hops that pass filter. The answer is nil when no chain is
+// found, so the query is searched as usual.
+func (h *Handler) assembleFlow(ctx context.Context, query, repo string, filter map[string]interface{}) ([]chunk.Chunk, *FlowAnswer) {
+	repo, err := h.graphRepo(ctx, repo)
+	if err != nil {
+		return nil, nil
+	}
+	fromPhrase, toPhrase := parseFlow(query)
+	to := h.flowEndpoints(ctx, toPhrase, repo, filter)
+	if len(to) == 0 {
+		return nil, nil
+	}
+	var from []string
+	if fromPhrase != "" {
+		from = h.flowEndpoints(ctx, fromPhrase, repo, filter)
+	}
+	if fromPhrase != "" && len(from) == 0 {
+		fromPhrase = "" // Start at an entry point instead
+	}
+
+	done := timeStage(ctx, stageGraph)
+	paths, err := h.graphStore.FindCallPaths(ctx, repo, from, to, flowMaxDepth, flowPathCandidates)
+	done()
+	if err != nil || len(paths) == 0 {
+		if h.logger != nil {
+			h.logger.Debug("no call chain for flow query", "query", query, "error", err)
+		}
+		return nil, nil
+	}
+	path := bestFlowPath(paths, from, to)
+	hopPaths := make([]string, len(path))
+	for i, sym := range path {
+		hopPaths[i] = sym.FilePath
+	}
+	allowed, err := h.readableFiles(ctx, repo, hopPaths)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Debug("failed to scope flow hops", "query", query, "error", err)
+		}
+		return nil, nil
+	}
+
+	answer := &FlowAnswer{From: fromPhrase, To: toPhrase, Repo: repo}
+	var results []chunk.Chunk
+	for _, sym := range path {
+		if allowed != nil && !allowed(sym.FilePath) {
+			continue // Hops in modules the caller may not read are left out
+		}
+		answer.Hops = append(answer.Hops, SymbolRef{
+			Name:      sym.Name,
+			Kind:      sym.Kind,
+			FilePath:  sym.FilePath,
+			StartLine: sym.StartLine,
+			EndLine:   sym.EndLine,
+			Signature: sym.Signature,
+		})
+		if c, ok := h.lookupChunk(ctx, filter, repo, sym.FilePath, sym.Name); ok {
+			results = append(results, c)
+		}
+	}
+	return results, answer
+}
+
+// flowEndpoints returns the names of the symbols best matching phrase.
+func (h *Handler) flowEndpoints(ctx context.Context, phrase, repo string, filter map[string]interface{}) []string {
+	scoped := make(map[string]interface{}, len(filter)+1)
+	for k, v := range filter {
+		scoped[k] = v
+	}
+	scoped["repo"] = repo
+	chunks, err := h.searchSemantic(ctx, phrase, scoped, flowEndpointCandidates*2)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, c := range chunks {
+		if c.SymbolName != "" && !slices.Contains(names, c.SymbolName) {
+			names = append(names, c.SymbolName)
+		}
+		if len(names) == flowEndpointCandidates {
+			break
+		}
+	}
+	return names
+}
+
+// bestFlowPath picks the chain whose ends ranked best for the query, the
+// shortest on ties; paths arrive shortest first. Without from, only the
+// destination's rank counts.
+func bestFlowPath(paths [][]graph.Symbol, from, to []string) []graph.Symbol {
+	rank := func(names []string, name string) int {
+		if i := slices.Index(names, name); i >= 0 {
+			return i
+		}
+		return len(names)
+	}
+	best, bestRank := 0, -1
+	for i, path := range paths {
+		r := rank(to, path[len(path)-1].Name)
+		if len(from) > 0 {
+			r += rank(from, path[0].Name)
+		}
+		if bestRank < 0 || r < bestRank {
+			best, bestRank = i, r
+		}
+	}
+	return paths[best]
+}
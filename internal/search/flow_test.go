@@ -0,0 +1,50 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlow(t *testing.T) {
+	tests := []struct {
+		query    string
+		from, to string
+	}{
+		{"how does a request get to the DB?", "request", "DB"},
+		{"data flow from API to database", "API", "database"},
+		{"path from login to session", "login", "session"},
+		{"how does request get to handler", "request", "handler"},
+		{"route to the payment gateway", "", "payment gateway"},
+		{"pipeline for data processing", "", "pipeline for data processing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			from, to := parseFlow(tt.query)
+			assert.Equal(t, tt.from, from)
+			assert.Equal(t, tt.to, to)
+		})
+	}
+}
+
+func TestBestFlowPath(t *testing.T) {
+	path := func(names ...string) []graph.Symbol {
+		syms := make([]graph.Symbol, len(names))
+		for i, n := range names {
+			syms[i] = graph.Symbol{Name: n}
+		}
+		return syms
+	}
+	paths := [][]graph.Symbol{
+		path("handle", "save"),
+		path("main", "handle", "query", "execute"),
+		path("handle", "validate", "execute"),
+	}
+
+	best := bestFlowPath(paths, []string{"handle"}, []string{"execute", "save"})
+	assert.Equal(t, path("handle", "validate", "execute"), best, "best-ranked ends win over length")
+
+	best = bestFlowPath(paths, nil, []string{"execute", "save"})
+	assert.Equal(t, path("main", "handle", "query", "execute"), best, "shortest among equally ranked")
+}
@@ -0,0 +1,245 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/randalmurphal/code-indexer/internal/store"
+)
+
+const (
+	// grepPageSize is how many chunks each Qdrant scroll fetches.
+	grepPageSize = 500
+	// maxGrepScan bounds how many chunks one grep_code call inspects, so a
+	// pattern with no usable literal cannot scroll an entire large index.
+	maxGrepScan = 20000
+)
+
+// GrepMatch is one matching line returned by grep_code.
+type GrepMatch struct {
+	Repo     string `json:"repo"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Text     string `json:"text"`
+	Symbol   string `json:"symbol,omitempty"`
+	Module   string `json:"module,omitempty"`
+}
+
+// GrepResponse is the grep_code tool result.
+type GrepResponse struct {
+	Pattern   string      `json:"pattern"`
+	Regex     bool        `json:"regex"`
+	Matches   []GrepMatch `json:"matches"`
+	Scanned   int         `json:"scanned"`
+	Truncated bool        `json:"truncated,omitempty"` // More matches may exist beyond limit or the scan bound
+}
+
+func (h *Handler) grepCode(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	pattern, _ := args["pattern"].(string)
+	if pattern == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "pattern parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+
+	isRegex, _ := args["regex"].(bool)
+	caseSensitive := true
+	if cs, ok := args["case_sensitive"].(bool); ok {
+		caseSensitive = cs
+	}
+
+	matcher, prefilter, err := compileGrepPattern(pattern, isRegex, caseSensitive)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("invalid regex: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+
+	if h.store == nil {
+		return nil, fmt.Errorf("vector store not configured")
+	}
+
+	repo, module := h.scope(ctx, args)
+	includeTests, _ := args["include_tests"].(string)
+
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	filter := grepFilter(repo, module, includeTests)
+	response := GrepResponse{Pattern: pattern, Regex: isRegex}
+	seen := make(map[string]bool)
+	offset := ""
+
+	for {
+		chunks, next, err := h.store.ScrollByText(ctx, "chunks", "content", prefilter, filter, grepPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("grep_code failed: %w", err)
+		}
+		response.Scanned += len(chunks)
+
+		for _, c := range chunks {
+			response.Matches = appendGrepMatches(response.Matches, c, matcher, seen)
+		}
+
+		if len(response.Matches) > limit {
+			response.Truncated = true
+			break
+		}
+		if next == "" {
+			break
+		}
+		if response.Scanned >= maxGrepScan {
+			response.Truncated = true
+			break
+		}
+		offset = next
+	}
+
+	if len(response.Matches) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("No indexed code matches %q.", pattern)}},
+		}, nil
+	}
+
+	sortGrepMatches(response.Matches)
+	if len(response.Matches) > limit {
+		response.Matches = response.Matches[:limit]
+	}
+
+	data, _ := json.MarshalIndent(response, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+// compileGrepPattern returns a line matcher for pattern and the literal, if
+// any, that every match must contain. The literal narrows the Qdrant scroll;
+// it is empty when matching is case-insensitive since Qdrant's substring
+// match is case-sensitive.
+func compileGrepPattern(pattern string, isRegex, caseSensitive bool) (func(string) bool, string, error) {
+	if !isRegex {
+		if caseSensitive {
+			return func(line string) bool { return strings.Contains(line, pattern) }, pattern, nil
+		}
+		lower := strings.ToLower(pattern)
+		return func(line string) bool { return strings.Contains(strings.ToLower(line), lower) }, "", nil
+	}
+
+	expr := pattern
+	if !caseSensitive {
+		expr = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prefilter := ""
+	if caseSensitive {
+		prefilter = requiredLiteral(pattern)
+	}
+	return re.MatchString, prefilter, nil
+}
+
+// requiredLiteral returns the longest literal that every match of a regex
+// must contain, or "" if none can be determined.
+func requiredLiteral(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	re = re.Simplify()
+
+	var parts []*syntax.Regexp
+	switch re.Op {
+	case syntax.OpLiteral:
+		parts = []*syntax.Regexp{re}
+	case syntax.OpConcat, syntax.OpCapture:
+		parts = re.Sub
+	}
+
+	longest := ""
+	for _, sub := range parts {
+		if sub.Op == syntax.OpCapture && len(sub.Sub) == 1 {
+			sub = sub.Sub[0]
+		}
+		if sub.Op != syntax.OpLiteral || sub.Flags&syntax.FoldCase != 0 {
+			continue
+		}
+		if lit := string(sub.Rune); len(lit) > len(longest) {
+			longest = lit
+		}
+	}
+	return longest
+}
+
+// appendGrepMatches adds the lines of c accepted by matcher. Hierarchical
+// chunking stores some lines in more than one chunk, so lines already in seen
+// are skipped.
+func appendGrepMatches(matches []GrepMatch, c chunk.Chunk, matcher func(string) bool, seen map[string]bool) []GrepMatch {
+	for i, line := range strings.Split(c.Content, "\n") {
+		if !matcher(line) {
+			continue
+		}
+		lineNo := c.StartLine + i
+		key := fmt.Sprintf("%s:%s:%d", c.Repo, c.FilePath, lineNo)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		matches = append(matches, GrepMatch{
+			Repo:     c.Repo,
+			FilePath: c.FilePath,
+			Line:     lineNo,
+			Text:     strings.TrimRight(line, "\r"),
+			Symbol:   c.SymbolName,
+			Module:   c.ModulePath,
+		})
+	}
+	return matches
+}
+
+func sortGrepMatches(matches []GrepMatch) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Repo != matches[j].Repo {
+			return matches[i].Repo < matches[j].Repo
+		}
+		if matches[i].FilePath != matches[j].FilePath {
+			return matches[i].FilePath < matches[j].FilePath
+		}
+		return matches[i].Line < matches[j].Line
+	})
+}
+
+// grepFilter narrows grep_code to source chunks. File summaries and pattern
+// chunks are generated text whose lines and line numbers are not the file's,
+// so they are left out.
+func grepFilter(repo, module, includeTests string) map[string]interface{} {
+	filter := map[string]interface{}{
+		store.MustNot: map[string]interface{}{"kind": []string{chunk.KindFileSummary, "pattern"}},
+	}
+	if repo != "" && repo != "all" {
+		filter["repo"] = repo
+	}
+	if module != "" {
+		filter["module_path"] = module
+	}
+	switch includeTests {
+	case "exclude":
+		filter["is_test"] = false
+	case "only":
+		filter["is_test"] = true
+	}
+	return filter
+}
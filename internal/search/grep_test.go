@@ -0,0 +1,104 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileGrepPattern(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		regex         bool
+		caseSensitive bool
+		line          string
+		wantMatch     bool
+		wantPrefilter string
+	}{
+		{"literal", "VOYAGE_API_KEY", false, true, `key := os.Getenv("VOYAGE_API_KEY")`, true, "VOYAGE_API_KEY"},
+		{"literal case mismatch", "voyage_api_key", false, true, `os.Getenv("VOYAGE_API_KEY")`, false, "voyage_api_key"},
+		{"literal ignore case", "voyage_api_key", false, false, `os.Getenv("VOYAGE_API_KEY")`, true, ""},
+		{"literal regex chars", "a.b(", false, true, "x := a.b(1)", true, "a.b("},
+		{"regex", `failed to \w+ Qdrant`, true, true, `"failed to reach Qdrant"`, true, "failed to "},
+		{"regex ignore case", `NEO4J_\w+`, true, false, "neo4j_user", true, ""},
+		{"regex short literal", `\d+ms`, true, true, "took 15ms", true, "ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, prefilter, err := compileGrepPattern(tt.pattern, tt.regex, tt.caseSensitive)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMatch, matcher(tt.line))
+			assert.Equal(t, tt.wantPrefilter, prefilter)
+		})
+	}
+
+	_, _, err := compileGrepPattern("(unclosed", true, true)
+	assert.Error(t, err)
+}
+
+func TestRequiredLiteral(t *testing.T) {
+	assert.Equal(t, "connection refused", requiredLiteral("connection refused"))
+	assert.Equal(t, "_TIMEOUT", requiredLiteral(`[A-Z]+_TIMEOUT`))
+	assert.Equal(t, "retry", requiredLiteral(`(retry)\s+\d`))
+	assert.Equal(t, "", requiredLiteral(`foo|bar`), "alternation has no required literal")
+	assert.Equal(t, "", requiredLiteral(`(?i)token`), "case-folded literals cannot prefilter")
+}
+
+func TestGrepFilter(t *testing.T) {
+	filter := grepFilter("app", "app.core", "exclude")
+	assert.Equal(t, "app", filter["repo"])
+	assert.Equal(t, "app.core", filter["module_path"])
+	assert.Equal(t, false, filter["is_test"])
+	assert.Equal(t, map[string]interface{}{"kind": []string{chunk.KindFileSummary, "pattern"}}, filter[store.MustNot],
+		"synthetic summary and pattern text is never grepped")
+
+	filter = grepFilter("all", "", "")
+	assert.NotContains(t, filter, "repo")
+	assert.NotContains(t, filter, "is_test")
+}
+
+func TestAppendGrepMatches(t *testing.T) {
+	class := chunk.Chunk{
+		Repo: "r3", FilePath: "auth.py", StartLine: 10, SymbolName: "Auth",
+		Content: "class Auth:\n    def check(self):\n        raise AuthError(\"token expired\")",
+	}
+	method := chunk.Chunk{
+		Repo: "r3", FilePath: "auth.py", StartLine: 11, SymbolName: "check",
+		Content: "    def check(self):\n        raise AuthError(\"token expired\")",
+	}
+	matcher, _, err := compileGrepPattern("token expired", false, true)
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	matches := appendGrepMatches(nil, method, matcher, seen)
+	matches = appendGrepMatches(matches, class, matcher, seen)
+
+	require.Len(t, matches, 1, "overlapping chunks report each line once")
+	assert.Equal(t, 12, matches[0].Line)
+	assert.Equal(t, "check", matches[0].Symbol)
+	assert.Contains(t, matches[0].Text, "token expired")
+}
+
+func TestGrepCodeValidation(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "grep_code", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "pattern parameter is required")
+
+	result, err = handler.CallTool(context.Background(), "grep_code", map[string]interface{}{
+		"pattern": "([",
+		"regex":   true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "invalid regex")
+}
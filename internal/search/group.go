@@ -0,0 +1,89 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+)
+
+// toSearchResult converts a chunk into a search_code result.
+func toSearchResult(c chunk.Chunk) SearchResult {
+	return SearchResult{
+		ID:         c.ID,
+		Repo:       c.Repo,
+		FilePath:   c.FilePath,
+		Module:     c.ModulePath,
+		SymbolName: c.SymbolName,
+		Kind:       c.Kind,
+		StartLine:  c.StartLine,
+		EndLine:    c.EndLine,
+		Content:    c.Content,
+		Docstring:  c.Docstring,
+		Summary:    c.Summary,
+		IsTest:     c.IsTest,
+		Owner:      c.LastAuthor,
+		Owners:     c.Owners,
+		License:    c.License,
+		Branch:     c.Branch,
+		Dirty:      c.Workspace == chunk.WorkspaceDirty,
+		AlsoAt:     alsoAt(c.Duplicates),
+		Metadata:   c.Metadata,
+	}
+}
+
+// groupByFile collapses ranked chunks into one result per file, ordered by
+// each file's best chunk. A file's result is its best chunk plus that
+// chunk's score, every matched symbol, and the merged matched line ranges.
+func groupByFile(chunks []chunk.Chunk) []SearchResult {
+	type fileGroup struct {
+		result SearchResult
+		seen   map[string]bool
+		spans  []chunk.Location
+	}
+	var order []string
+	groups := make(map[string]*fileGroup)
+	for _, c := range chunks {
+		key := c.Repo + "\x00" + c.FilePath
+		g, ok := groups[key]
+		if !ok {
+			g = &fileGroup{result: toSearchResult(c), seen: make(map[string]bool)}
+			g.result.Score = c.Score
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.result.Matches++
+		if c.SymbolName != "" && !g.seen[c.SymbolName] {
+			g.seen[c.SymbolName] = true
+			g.result.Symbols = append(g.result.Symbols, c.SymbolName)
+		}
+		g.spans = append(g.spans, chunk.Location{StartLine: c.StartLine, EndLine: c.EndLine})
+	}
+
+	results := make([]SearchResult, len(order))
+	for i, key := range order {
+		g := groups[key]
+		g.result.Ranges = mergeRanges(g.spans)
+		results[i] = g.result
+	}
+	return results
+}
+
+// mergeRanges joins overlapping and adjacent line spans, formatted as
+// "start-end" in line order.
+func mergeRanges(spans []chunk.Location) []string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].StartLine < spans[j].StartLine })
+	var merged []chunk.Location
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span.StartLine <= merged[n-1].EndLine+1 {
+			merged[n-1].EndLine = max(merged[n-1].EndLine, span.EndLine)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	ranges := make([]string, len(merged))
+	for i, span := range merged {
+		ranges[i] = fmt.Sprintf("%d-%d", span.StartLine, span.EndLine)
+	}
+	return ranges
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupByFile(t *testing.T) {
+	chunks := []chunk.Chunk{
+		{Repo: "shop", FilePath: "billing.py", SymbolName: "charge", StartLine: 10, EndLine: 30, Content: "def charge(): ...", Score: 0.9},
+		{Repo: "shop", FilePath: "cart.py", SymbolName: "total", StartLine: 5, EndLine: 9, Score: 0.8},
+		{Repo: "shop", FilePath: "billing.py", SymbolName: "refund", StartLine: 31, EndLine: 40, Score: 0.7},
+		{Repo: "shop", FilePath: "billing.py", SymbolName: "charge", StartLine: 60, EndLine: 70, Score: 0.6},
+		{Repo: "admin", FilePath: "billing.py", SymbolName: "report", StartLine: 1, EndLine: 3, Score: 0.5},
+	}
+
+	results := groupByFile(chunks)
+
+	require.Len(t, results, 3, "same path in another repo is another file")
+	billing := results[0]
+	assert.Equal(t, "billing.py", billing.FilePath)
+	assert.Equal(t, "charge", billing.SymbolName, "the best chunk is the snippet")
+	assert.Equal(t, "def charge(): ...", billing.Content)
+	assert.Equal(t, float32(0.9), billing.Score)
+	assert.Equal(t, 3, billing.Matches)
+	assert.Equal(t, []string{"charge", "refund"}, billing.Symbols)
+	assert.Equal(t, []string{"10-40", "60-70"}, billing.Ranges, "adjacent spans merge")
+
+	assert.Equal(t, "cart.py", results[1].FilePath)
+	assert.Equal(t, []string{"5-9"}, results[1].Ranges)
+	assert.Equal(t, []string{"report"}, results[2].Symbols)
+}
+
+func TestMergeRanges(t *testing.T) {
+	spans := []chunk.Location{{StartLine: 50, EndLine: 60}, {StartLine: 1, EndLine: 20}, {StartLine: 5, EndLine: 10}, {StartLine: 22, EndLine: 30}}
+
+	assert.Equal(t, []string{"1-20", "22-30", "50-60"}, mergeRanges(spans))
+}
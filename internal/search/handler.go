@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"github.com/randalmurphal/code-indexer/internal/graph"
 	"github.com/randalmurphal/code-indexer/internal/mcp"
 	"github.com/randalmurphal/code-indexer/internal/metrics"
+	"github.com/randalmurphal/code-indexer/internal/rerank"
 	"github.com/randalmurphal/code-indexer/internal/store"
 )
 
@@ -26,12 +28,18 @@ import (
 type Handler struct {
 	config        *config.Config
 	embedder      *embedding.VoyageClient
-	store         *store.QdrantStore
+	store         *chunkStore
 	graphStore    *graph.Neo4jStore
 	cache         *cache.RedisCache
 	metrics       *metrics.Logger
+	metricsPath   string
 	classifier    *Classifier
 	suggestionGen *SuggestionGenerator
+	reranker      Reranker
+	embedClass    *EmbeddingClassifier
+	feedback      *feedbackWeights
+	experiment    config.ExperimentConfig // Validated search.experiment; empty Name when none runs
+	repos         repoList                // For federated search
 	logger        *slog.Logger
 }
 
@@ -49,8 +57,8 @@ func NewHandler(cfg *config.Config, voyageKey string, logger *slog.Logger) (*Han
 	}
 
 	var queryCache *cache.RedisCache
-	if cfg.Storage.RedisURL != "" {
-		queryCache, err = cache.NewRedisCache(cfg.Storage.RedisURL)
+	if cfg.Storage.RedisEnabled() {
+		queryCache, err = cache.NewRedisCacheFromConfig(cfg.Storage)
 		if err != nil {
 			logger.Warn("Redis cache unavailable, continuing without cache", "error", err)
 		}
@@ -63,6 +71,10 @@ func NewHandler(cfg *config.Config, voyageKey string, logger *slog.Logger) (*Han
 	if err := os.MkdirAll(filepath.Dir(metricsPath), 0755); err == nil {
 		metricsLogger, _ = metrics.NewLogger(metricsPath)
 	}
+	votes, err := metrics.NewAnalyzer(metricsPath).ChunkFeedback()
+	if err != nil {
+		logger.Warn("failed to load search feedback", "error", err)
+	}
 
 	// Initialize Neo4j graph store if configured
 	var graphStore *graph.Neo4jStore
@@ -84,17 +96,35 @@ func NewHandler(cfg *config.Config, voyageKey string, logger *slog.Logger) (*Han
 		}
 	}
 
-	return &Handler{
+	h := &Handler{
 		config:        cfg,
 		embedder:      embedder,
-		store:         qdrantStore,
+		store:         &chunkStore{QdrantStore: qdrantStore, withholdSecrets: cfg.Search.WithholdSecretBodies},
 		graphStore:    graphStore,
 		cache:         queryCache,
 		metrics:       metricsLogger,
+		metricsPath:   metricsPath,
 		classifier:    NewClassifier(),
 		suggestionGen: NewSuggestionGenerator(),
+		feedback:      newFeedbackWeights(votes),
 		logger:        logger,
-	}, nil
+	}
+	h.classifier.SetStrategies(cfg.Search.Strategies)
+	if err := h.SetExperiment(cfg.Search.Experiment); err != nil {
+		logger.Warn("search experiment disabled", "error", err)
+	}
+	if cfg.Search.Classifier.Mode == "embedding" {
+		h.embedClass = NewEmbeddingClassifier(embedder)
+	}
+	if cfg.Search.Rerank.Enabled {
+		reranker, err := rerank.NewClient(cfg.Search.Rerank)
+		if err != nil {
+			logger.Warn("reranking disabled", "error", err)
+		} else {
+			h.SetReranker(reranker)
+		}
+	}
+	return h, nil
 }
 
 // Close releases resources held by the handler.
@@ -114,9 +144,18 @@ func (h *Handler) Close() error {
 	return nil
 }
 
+// DisableMetrics stops logging searches to the usage metrics file, so
+// synthetic traffic like code-indexer eval doesn't skew the analytics.
+func (h *Handler) DisableMetrics() {
+	if h.metrics != nil {
+		h.metrics.Close()
+		h.metrics = nil
+	}
+}
+
 // ListTools returns available tools (implements mcp.Handler).
 func (h *Handler) ListTools() []mcp.Tool {
-	return []mcp.Tool{
+	tools := []mcp.Tool{
 		{
 			Name:        "search_code",
 			Description: "Find code by concept using semantic search. Use when you don't know exact symbol names but know what you're looking for.",
@@ -125,7 +164,7 @@ func (h *Handler) ListTools() []mcp.Tool {
 				Properties: map[string]mcp.Property{
 					"query": {
 						Type:        "string",
-						Description: "Describe what you're looking for in natural language",
+						Description: "Describe what you're looking for in natural language. Prefix a word with - to leave out code containing it (e.g., 'upload handler -legacy')",
 					},
 					"repo": {
 						Type:        "string",
@@ -148,18 +187,501 @@ func (h *Handler) ListTools() []mcp.Tool {
 						Type:        "string",
 						Description: "Pagination cursor from previous response (for fetching next page)",
 					},
+					"owner": {
+						Type:        "string",
+						Description: "Only return code last modified by this author (name or email), or owned by a CODEOWNERS user or team (@user, @org/team)",
+					},
+					"branch": {
+						Type:        "string",
+						Description: "Only return code indexed from this git branch (each repo holds the branch checked out when it was last indexed)",
+					},
+					"dirty": {
+						Type:        "string",
+						Description: "Code of files with uncommitted changes, indexed in place of their committed version: include (default), exclude, or only",
+						Enum:        []string{"include", "exclude", "only"},
+					},
+					"path_glob": {
+						Type:        "string",
+						Description: "Only return code whose repo-relative path matches this glob (e.g., 'api/**/*.py'; a trailing / means the whole directory)",
+					},
+					"language": {
+						Type:        "string",
+						Description: "Only return code in this language",
+						Enum:        searchLanguages,
+					},
+					"kind": {
+						Type:        "string",
+						Description: "Only return this kind of chunk: function, class, method, doc (documentation and patterns), pattern, file (file summaries), or adr (architecture decision records)",
+						Enum:        searchKinds,
+					},
+					"group_by": {
+						Type:        "string",
+						Description: "Collapse results per file: one entry with the best snippet, its score, every matched symbol, and the merged line ranges",
+						Enum:        []string{"file"},
+					},
+					"strategy": {
+						Type:        "string",
+						Description: "Retrieval to use instead of classifying the query: semantic (embeddings only), hybrid (embeddings plus keyword matches), symbol (symbol name lookup), pattern (detected patterns), or auto (default)",
+						Enum:        searchStrategies,
+					},
+					"search_in": {
+						Type:        "string",
+						Description: "What to match: all (default), code (code chunks only, no docs), docstrings, or signatures (symbols' docstrings or signatures only, for API discovery)",
+						Enum:        searchInFields,
+					},
+					"include_seen": {
+						Type:        "boolean",
+						Description: "With session memory on, also return chunks earlier searches in this session already returned (default: omit them)",
+					},
+					"exclude_modules": {
+						Type:        "string",
+						Description: "Comma-separated modules to leave out, with their submodules (e.g., 'fisio.legacy')",
+					},
+					"exclude_paths": {
+						Type:        "string",
+						Description: "Comma-separated paths or globs to leave out; 'generated/' or 'migrations/' match at any depth, '/build/' only at the root",
+					},
+					"include_sensitive": {
+						Type:        "boolean",
+						Description: "Return the full body of code containing secrets, which is otherwise cut down to its signature and docstring when the server withholds it",
+					},
 				},
 				Required: []string{"query"},
 			},
+			OutputSchema: searchCodeOutputSchema(),
 		},
+		{
+			Name:        "who_owns",
+			Description: "Find who last changed a file or directory, from git history. Use to direct questions to the right person or team.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"path": {
+						Type:        "string",
+						Description: "File path or directory prefix relative to the repo root",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository name (default: inferred from cwd)",
+					},
+				},
+				Required: []string{"path"},
+			},
+		},
+		{
+			Name:        "list_modules",
+			Description: "List a repository's top-level modules with descriptions and sizes. Use to get oriented in an unfamiliar codebase.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"repo": {
+						Type:        "string",
+						Description: "Repository name (default: inferred from cwd)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "module_contents",
+			Description: "List a module's files and its most-called symbols. Use after list_modules to browse structurally instead of guessing search terms.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"module": {
+						Type:        "string",
+						Description: "Module name as returned by list_modules",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository name (default: inferred from cwd)",
+					},
+					"limit": {
+						Type:        "number",
+						Description: "Maximum symbols to return (default: 20)",
+					},
+				},
+				Required: []string{"module"},
+			},
+		},
+		{
+			Name:        "class_hierarchy",
+			Description: "Show a class's base classes and all subclasses with file locations. Use for questions like 'all subclasses of BaseImporter'.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"class": {
+						Type:        "string",
+						Description: "Exact class name",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository name (default: inferred from cwd)",
+					},
+					"depth": {
+						Type:        "number",
+						Description: "Maximum levels to traverse in each direction (default: 10)",
+					},
+				},
+				Required: []string{"class"},
+			},
+		},
+		{
+			Name:        "get_symbol",
+			Description: "Get the definition of a function, class, or method by exact name (content, signature, docstring, location). Falls back to similar names when there is no exact match.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"name": {
+						Type:        "string",
+						Description: "Symbol name, optionally qualified as Class.method",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository name or 'all' (default: inferred from cwd)",
+					},
+					"kind": {
+						Type:        "string",
+						Description: "Restrict to a symbol kind",
+						Enum:        []string{"function", "class", "method"},
+					},
+					"limit": {
+						Type:        "number",
+						Description: "Maximum definitions to return (default: 5)",
+					},
+					"include_sensitive": {
+						Type:        "boolean",
+						Description: "Return the full body of code containing secrets, which is otherwise cut down to its signature and docstring when the server withholds it",
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		{
+			Name:        "list_repos",
+			Description: "List indexed repositories with their modules, chunk counts, and when they were last indexed. Use to discover what can be searched.",
+			InputSchema: mcp.InputSchema{
+				Type:       "object",
+				Properties: map[string]mcp.Property{},
+			},
+		},
+		{
+			Name:        "index_status",
+			Description: "Report index health: vector collection size, embedding model, graph and cache availability, and a repo's last indexed commit.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"repo": {
+						Type:        "string",
+						Description: "Repository to report on (default: inferred from cwd)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "reindex_file",
+			Description: "Re-index one file now so search reflects your edits without waiting for the sync daemon. Removes the file's chunks if it was deleted.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"path": {
+						Type:        "string",
+						Description: "Absolute file path, or a path relative to the repo root",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository name for relative paths (default: inferred from cwd)",
+					},
+				},
+				Required: []string{"path"},
+			},
+		},
+		{
+			Name:        "grep_code",
+			Description: "Find exact text or a regex in indexed code, e.g. error messages, env var names, or config keys. Use instead of search_code when you know the literal string.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"pattern": {
+						Type:        "string",
+						Description: "Literal text to find, or a Go regular expression when regex is true",
+					},
+					"regex": {
+						Type:        "boolean",
+						Description: "Treat pattern as a regular expression (default: false)",
+					},
+					"case_sensitive": {
+						Type:        "boolean",
+						Description: "Match case exactly (default: true)",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository to search, or all (default: inferred from cwd)",
+					},
+					"module": {
+						Type:        "string",
+						Description: "Filter to specific module (e.g., 'fisio.imports')",
+					},
+					"include_tests": {
+						Type:        "string",
+						Description: "Test file handling: include (default), exclude, or only",
+						Enum:        []string{"include", "exclude", "only"},
+					},
+					"limit": {
+						Type:        "number",
+						Description: "Maximum matching lines to return (default: 20)",
+					},
+					"include_sensitive": {
+						Type:        "boolean",
+						Description: "Return the full body of code containing secrets, which is otherwise cut down to its signature and docstring when the server withholds it",
+					},
+				},
+				Required: []string{"pattern"},
+			},
+		},
+		{
+			Name:        "similar_code",
+			Description: "Find indexed code similar to a snippet. Use to check whether something has already been written or to spot near-duplicates.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"snippet": {
+						Type:        "string",
+						Description: "Code to compare against the index",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository to search, or all (default: inferred from cwd)",
+					},
+					"threshold": {
+						Type:        "number",
+						Description: "Minimum cosine similarity from 0 to 1 (default: 0.8)",
+					},
+					"include_tests": {
+						Type:        "string",
+						Description: "Test file handling: include (default), exclude, or only",
+						Enum:        []string{"include", "exclude", "only"},
+					},
+					"limit": {
+						Type:        "number",
+						Description: "Maximum matches to return (default: 10)",
+					},
+					"include_sensitive": {
+						Type:        "boolean",
+						Description: "Return the full body of code containing secrets, which is otherwise cut down to its signature and docstring when the server withholds it",
+					},
+				},
+				Required: []string{"snippet"},
+			},
+		},
+		{
+			Name:        "explain_module",
+			Description: "Get a markdown overview of a module: its AGENTS.md/CLAUDE.md docs, key symbols, patterns, and which modules it depends on and is used by. Use before working in an unfamiliar module.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"module": {
+						Type:        "string",
+						Description: "Module name as returned by list_modules",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository name (default: inferred from cwd)",
+					},
+				},
+				Required: []string{"module"},
+			},
+		},
+		{
+			Name:        "search_docs",
+			Description: "Search documentation only (AGENTS.md/CLAUDE.md sections and detected patterns), e.g. 'what does the AGENTS doc say about retries'. Results show each section's heading path.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"query": {
+						Type:        "string",
+						Description: "Describe what you're looking for in natural language",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository to search, or all (default: inferred from cwd)",
+					},
+					"module": {
+						Type:        "string",
+						Description: "Filter to docs of a module root as returned by list_modules",
+					},
+					"kind": {
+						Type:        "string",
+						Description: "Restrict to navigation docs (AGENTS.md, CLAUDE.md), doc site pages, architecture decision records, or pattern descriptions",
+						Enum:        []string{"navigation", "site", "adr", "pattern"},
+					},
+					"limit": {
+						Type:        "number",
+						Description: "Maximum sections to return (default: 5)",
+					},
+					"include_sensitive": {
+						Type:        "boolean",
+						Description: "Return the full body of code containing secrets, which is otherwise cut down to its signature and docstring when the server withholds it",
+					},
+				},
+				Required: []string{"query"},
+			},
+		},
+		{
+			Name:        "set_context",
+			Description: "Pin the repo and module you are working in for this session. Later calls that omit repo/module (and the codeindex://relevant resource) use them instead of guessing from the server's cwd. Returns the pinned and effective context; call with no arguments to see it.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"path": {
+						Type:        "string",
+						Description: "Absolute path of your workspace directory; pins the repo containing it",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository name to pin (empty string unpins)",
+					},
+					"module": {
+						Type:        "string",
+						Description: "Module root to pin within the repo, as returned by list_modules (empty string unpins)",
+					},
+					"clear": {
+						Type:        "boolean",
+						Description: "Unpin everything and forget this session's search memory before applying the other arguments",
+					},
+				},
+			},
+		},
+		{
+			Name:        "search_feedback",
+			Description: "Mark a search_code result as useful or not, by its id. Votes are kept and shift how highly that code ranks in later searches.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"id": {
+						Type:        "string",
+						Description: "The result's id from search_code",
+					},
+					"useful": {
+						Type:        "boolean",
+						Description: "true if the result helped, false if it was irrelevant",
+					},
+					"query": {
+						Type:        "string",
+						Description: "The query the result was returned for, recorded with the vote",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository of the result",
+					},
+				},
+				Required: []string{"id", "useful"},
+			},
+		},
+		{
+			Name:        "mark_used",
+			Description: "Notify that you opened or used a file from search_code results. Call it once per file you actually read; it changes nothing, but tells which searches return useful code.",
+			InputSchema: mcp.InputSchema{
+				Type: "object",
+				Properties: map[string]mcp.Property{
+					"file_path": {
+						Type:        "string",
+						Description: "The result's file_path",
+					},
+					"query": {
+						Type:        "string",
+						Description: "The search_code query that returned it (default: this session's last search)",
+					},
+					"id": {
+						Type:        "string",
+						Description: "The result's id, if a specific result was used",
+					},
+					"repo": {
+						Type:        "string",
+						Description: "Repository of the result",
+					},
+				},
+				Required: []string{"file_path"},
+			},
+		},
+	}
+
+	for i := range tools {
+		tools[i].Annotations = toolAnnotations(tools[i].Name)
+	}
+	return tools
+}
+
+// toolAnnotations describes a tool's side effects. All tools only query the
+// index except reindex_file, which rewrites one file's chunks in place,
+// set_context, which changes the session's defaults, and search_feedback
+// and mark_used, which add a vote or usage record each call.
+func toolAnnotations(name string) *mcp.ToolAnnotations {
+	if name == "search_feedback" || name == "mark_used" {
+		return &mcp.ToolAnnotations{
+			DestructiveHint: mcp.Bool(false),
+			OpenWorldHint:   mcp.Bool(false),
+		}
+	}
+	if name == "reindex_file" || name == "set_context" {
+		return &mcp.ToolAnnotations{
+			DestructiveHint: mcp.Bool(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   mcp.Bool(false),
+		}
+	}
+	return &mcp.ToolAnnotations{
+		ReadOnlyHint:  true,
+		OpenWorldHint: mcp.Bool(false),
 	}
 }
 
 // CallTool processes a tool invocation (implements mcp.Handler).
 func (h *Handler) CallTool(ctx context.Context, name string, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	if denied := h.authorize(ctx, name, args); denied != nil {
+		return denied, nil
+	}
+	if sensitive, _ := args["include_sensitive"].(bool); sensitive {
+		var caller string
+		if token := mcp.TokenInfoFromContext(ctx); token != nil {
+			caller = token.Subject
+		}
+		h.logger.Info("full bodies of chunks with secrets requested", "tool", name, "caller", caller)
+		ctx = withSensitive(ctx)
+	}
+
 	switch name {
 	case "search_code":
 		return h.searchCode(ctx, args)
+	case "who_owns":
+		return h.whoOwns(ctx, args)
+	case "list_modules":
+		return h.listModules(ctx, args)
+	case "module_contents":
+		return h.moduleContents(ctx, args)
+	case "class_hierarchy":
+		return h.classHierarchy(ctx, args)
+	case "get_symbol":
+		return h.getSymbol(ctx, args)
+	case "list_repos":
+		return h.listRepos(ctx, args)
+	case "index_status":
+		return h.indexStatus(ctx, args)
+	case "reindex_file":
+		return h.reindexFile(ctx, args)
+	case "grep_code":
+		return h.grepCode(ctx, args)
+	case "similar_code":
+		return h.similarCode(ctx, args)
+	case "explain_module":
+		return h.explainModule(ctx, args)
+	case "search_docs":
+		return h.searchDocs(ctx, args)
+	case "set_context":
+		return h.setContext(ctx, args)
+	case "search_feedback":
+		return h.searchFeedback(ctx, args)
+	case "mark_used":
+		return h.markUsed(ctx, args)
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
@@ -169,9 +691,9 @@ func (h *Handler) CallTool(ctx context.Context, name string, args map[string]int
 func (h *Handler) ListResources() []mcp.Resource {
 	return []mcp.Resource{
 		{
-			URI:         "codeindex://relevant",
+			URI:         relevantURI,
 			Name:        "Contextually relevant code",
-			Description: "Auto-retrieved code based on conversation context",
+			Description: "Auto-retrieved code based on conversation context. Add ?query=<task>&tokens=<budget> (optional repo, module) for a token-bounded bundle of the best-matching code, callees before callers",
 			MimeType:    "text/markdown",
 		},
 	}
@@ -179,9 +701,11 @@ func (h *Handler) ListResources() []mcp.Resource {
 
 // ReadResource processes a resource read (implements mcp.Handler).
 func (h *Handler) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
-	switch uri {
-	case "codeindex://relevant":
+	switch {
+	case uri == relevantURI:
 		return h.getRelevantContext(ctx)
+	case strings.HasPrefix(uri, relevantURI+"?"):
+		return h.assembleContext(ctx, uri)
 	default:
 		return nil, fmt.Errorf("unknown resource: %s", uri)
 	}
@@ -189,6 +713,7 @@ func (h *Handler) ReadResource(ctx context.Context, uri string) (*mcp.ReadResour
 
 func (h *Handler) searchCode(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
 	startTime := time.Now()
+	ctx, stages := withStageTimes(ctx)
 
 	// Parse arguments
 	query, _ := args["query"].(string)
@@ -199,16 +724,56 @@ func (h *Handler) searchCode(ctx context.Context, args map[string]interface{}) (
 		}, nil
 	}
 
-	repo, _ := args["repo"].(string)
-	if repo == "" {
-		repo = h.inferRepo()
-	}
+	repo, module := h.scope(ctx, args)
 
-	module, _ := args["module"].(string)
+	owner, _ := args["owner"].(string)
+	scope, err := parseScopeFilters(args)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+	query, negated := parseQuery(query)
+	if query == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "query needs at least one term that is not negated"}},
+			IsError: true,
+		}, nil
+	}
+	scope.exclude(negated)
+	if h.config != nil {
+		scope.excludeLicenses = h.config.Search.ExcludeLicenses
+	}
+	groupBy, _ := args["group_by"].(string)
+	if groupBy != "" && groupBy != "file" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("unsupported group_by %q (supported: file)", groupBy)}},
+			IsError: true,
+		}, nil
+	}
 	includeTests, _ := args["include_tests"].(string)
 	if includeTests == "" {
 		includeTests = "include"
 	}
+	searchIn, _ := args["search_in"].(string)
+	if searchIn == "" {
+		searchIn = SearchInAll
+	}
+	if !slices.Contains(searchInFields, searchIn) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("unsupported search_in %q (supported: %s)", searchIn, strings.Join(searchInFields, ", "))}},
+			IsError: true,
+		}, nil
+	}
+	fieldSearch := searchIn == SearchInDocstrings || searchIn == SearchInSignatures
+	strategyArg, _ := args["strategy"].(string)
+	if strategyArg != "" && !slices.Contains(searchStrategies, strategyArg) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("unsupported strategy %q (supported: %s)", strategyArg, strings.Join(searchStrategies, ", "))}},
+			IsError: true,
+		}, nil
+	}
 
 	limit := 10
 	if l, ok := args["limit"].(float64); ok {
@@ -228,9 +793,23 @@ func (h *Handler) searchCode(ctx context.Context, args map[string]interface{}) (
 		offset = cursor.Offset
 	}
 
-	// Classify query to determine search strategy
-	queryType := h.classifier.Classify(query)
-	strategy := h.classifier.Route(queryType)
+	// Queries in an experiment's variant arm are searched as if the caller
+	// had chosen its strategy, which also keys their cached responses apart
+	experiment, arm := h.experimentArm(query, strategyArg)
+	if experiment != "" && arm != ExperimentControl {
+		strategyArg = arm
+	}
+
+	// Classify query to determine search strategy, unless the caller chose one
+	queryType, strategy, forced := h.classifier.Force(strategyArg)
+	var classification *Classification
+	var chosen string // Logged, so result usage can be compared per strategy
+	if forced {
+		chosen = strategyArg
+	} else {
+		ctx, queryType, classification = h.classify(ctx, query)
+		strategy = h.classifier.Route(queryType)
+	}
 
 	// Override limit if strategy specifies
 	if strategy.MaxResults > 0 && strategy.MaxResults < limit {
@@ -243,26 +822,63 @@ func (h *Handler) searchCode(ctx context.Context, args map[string]interface{}) (
 			"query_type", string(queryType),
 			"repo", repo,
 			"module", module,
+			"owner", owner,
 			"limit", limit,
+			"experiment", experiment,
+			"variant", arm,
 		)
 	}
 
 	// Check cache if available
 	var cacheKey string
+	var version int64
+	// Session memory makes results depend on the session, so it bypasses the cache
+	memory := h.sessionMemory(ctx)
+	queryHash := HashQuery(query, repo, module)
 	if h.cache != nil {
-		version, _ := h.cache.GetIndexVersion(ctx, repo)
-		cacheKey = cache.QueryCacheKey(repo, query, version)
+		version, _ = h.cache.GetIndexVersion(ctx, repo)
+	}
+	// Similar queries share cached responses only with the same options and
+	// query type, and only first pages
+	var semanticVariant string
+	if h.cache != nil && memory == nil {
+		options := searchOptions{module: module, owner: owner, includeTests: includeTests, groupBy: groupBy, searchIn: searchIn, limit: limit, offset: offset, scope: scope}
+		if forced {
+			options.strategy = strategyArg
+		}
+		variant := options.cacheVariant(ctx)
+		cacheKey = cache.QueryCacheKey(repo, query+variant, version)
+		if offset == 0 {
+			semanticVariant = variant + "\x00type:" + string(queryType)
+		}
 
-		if cached, err := h.cache.Get(ctx, cacheKey); err == nil && cached != "" {
+		lookupStart := time.Now()
+		cached, ttlLeft, err := h.cache.GetWithTTL(ctx, cacheKey)
+		hit := err == nil && cached != ""
+		if usage := h.usageMetrics(ctx); usage != nil {
+			var age time.Duration
+			if ttl := h.queryTTL(); hit && ttlLeft > 0 && ttl > ttlLeft {
+				age = ttl - ttlLeft
+			}
+			usage.LogCache(repo, hit, time.Since(lookupStart), age)
+		}
+		if hit {
 			if h.logger != nil {
 				h.logger.Debug("cache hit", "query", query, "repo", repo)
 			}
-			if h.metrics != nil {
-				h.metrics.LogSearch(query, string(queryType), -1, time.Since(startTime).Milliseconds(), true)
+			if usage := h.usageMetrics(ctx); usage != nil {
+				usage.LogSearchEvent(metrics.SearchEvent{Query: query, QueryType: string(queryType), Repo: repo, Module: module, Results: -1, LatencyMs: time.Since(startTime).Milliseconds(), CacheHit: true, Session: sessionID(ctx), Stages: stages.snapshot(), Experiment: experiment, Variant: arm, Strategy: chosen})
+			}
+			return structuredResult(markCacheHit(cached)), nil
+		}
+		if semanticVariant != "" {
+			var cachedQuery string
+			if ctx, cached, cachedQuery = h.semanticLookup(ctx, query, repo, semanticVariant, version); cached != "" {
+				if usage := h.usageMetrics(ctx); usage != nil {
+					usage.LogSearchEvent(metrics.SearchEvent{Query: query, QueryType: string(queryType), Repo: repo, Module: module, Results: -1, LatencyMs: time.Since(startTime).Milliseconds(), CacheHit: true, Session: sessionID(ctx), Stages: stages.snapshot(), Experiment: experiment, Variant: arm, Strategy: chosen})
+				}
+				return structuredResult(markCacheHitFor(cached, cachedQuery)), nil
 			}
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{{Type: "text", Text: cached}},
-			}, nil
 		}
 	}
 
@@ -274,6 +890,13 @@ func (h *Handler) searchCode(ctx context.Context, args map[string]interface{}) (
 	if module != "" {
 		filter["module_path"] = module
 	}
+	if owner != "" {
+		filter[ownerFilterKey(owner)] = owner
+	}
+	scope.apply(filter)
+	if searchIn == SearchInCode {
+		filter["type"] = string(chunk.ChunkTypeCode)
+	}
 	switch includeTests {
 	case "exclude":
 		filter["is_test"] = false
@@ -284,16 +907,45 @@ func (h *Handler) searchCode(ctx context.Context, args map[string]interface{}) (
 	// Route to appropriate search based on strategy
 	// Fetch more results than needed for pagination
 	fetchLimit := offset + limit + 1
+	searchLimit := fetchLimit
+	if scope.needsLocalCheck() {
+		// Some candidates will fail the checks Qdrant cannot do
+		searchLimit *= 3
+	}
+	if groupBy == "file" {
+		// Enough chunks to fill a page after several share a file
+		searchLimit *= 3
+	}
 	var results []chunk.Chunk
-	var err error
-
+	var relationship *RelationshipAnswer
+	var flow *FlowAnswer
 	switch {
-	case strategy.UseSymbolIndex:
-		results, err = h.searchBySymbol(ctx, query, filter, fetchLimit)
-	case strategy.UsePatternIndex:
-		results, err = h.searchByPattern(ctx, query, filter, fetchLimit)
-	default:
-		results, err = h.searchSemantic(ctx, query, filter, fetchLimit)
+	case fieldSearch:
+	case queryType == QueryTypeRelationship:
+		if rel, ok := parseRelationship(query); ok {
+			results, relationship = h.answerRelationship(ctx, rel, repo, filter, searchLimit)
+		}
+	case queryType == QueryTypeFlow:
+		results, flow = h.assembleFlow(ctx, query, repo, filter)
+	}
+	answered := relationship != nil || flow != nil
+
+	retrieve := func(ctx context.Context, filter map[string]interface{}, limit int) ([]chunk.Chunk, error) {
+		switch {
+		case fieldSearch:
+			return h.searchField(ctx, query, filter, limit, searchIn)
+		case strategy.UseSymbolIndex:
+			return h.searchBySymbol(ctx, query, filter, limit)
+		case strategy.UsePatternIndex:
+			return h.searchByPattern(ctx, query, filter, limit)
+		case strategy.Hybrid != nil:
+			return h.searchSemanticWith(ctx, query, filter, limit, *strategy.Hybrid)
+		default:
+			return h.searchSemantic(ctx, query, filter, limit)
+		}
+	}
+	if !answered {
+		results, err = h.federate(ctx, query, repo, filter, searchLimit, retrieve)
 	}
 
 	if err != nil {
@@ -301,58 +953,89 @@ func (h *Handler) searchCode(ctx context.Context, args map[string]interface{}) (
 	}
 
 	// Apply graph expansion if enabled and graph store is available
-	if strategy.UseGraphExpansion && h.graphStore != nil && len(results) > 0 {
-		results = h.expandWithGraph(ctx, results, repo, strategy.GraphDepth, fetchLimit)
+	if strategy.UseGraphExpansion && !answered && !fieldSearch && h.graphStore != nil && len(results) > 0 {
+		results = h.expandWithGraph(ctx, results, repo, strategy.GraphDepth, searchLimit)
+	}
+	results = scope.keep(results)
+	if !answered && h.wantsRerank(queryType) {
+		results = h.rerank(ctx, query, results)
+	}
+	var seenOmitted int
+	if memory != nil && !answered {
+		includeSeen, _ := args["include_seen"].(bool)
+		results, seenOmitted = memory.apply(results, queryHash, h.config.Search.SessionMemory.Boost, includeSeen)
+	}
+	if len(results) > fetchLimit && groupBy != "file" {
+		results = results[:fetchLimit]
 	}
 
 	// Convert chunks to search results for pagination
-	searchResults := make([]SearchResult, len(results))
-	for i, c := range results {
-		searchResults[i] = SearchResult{
-			FilePath:   c.FilePath,
-			Module:     c.ModulePath,
-			SymbolName: c.SymbolName,
-			Kind:       c.Kind,
-			StartLine:  c.StartLine,
-			EndLine:    c.EndLine,
-			Content:    c.Content,
-			Docstring:  c.Docstring,
-			IsTest:     c.IsTest,
+	formatDone := timeStage(ctx, stageFormat)
+	var searchResults []SearchResult
+	if groupBy == "file" {
+		searchResults = groupByFile(results)
+	} else {
+		searchResults = make([]SearchResult, len(results))
+		for i, c := range results {
+			searchResults[i] = toSearchResult(c)
 		}
 	}
 
 	// Apply pagination
-	queryHash := HashQuery(query, repo, module)
 	paginated := Paginate(searchResults, offset, limit, queryHash, string(queryType))
+	highlightResults(query, paginated.Results)
+	paginated.IndexMeta = h.indexMeta(ctx, repo, version)
+	paginated.Relationship = relationship
+	paginated.Flow = flow
+	paginated.SeenOmitted = seenOmitted
+	paginated.Classification = classification
+	if memory != nil {
+		memory.record(queryHash, query, shownChunks(results, paginated.Results, offset, groupBy == "file"))
+	}
 
 	// Format response
 	var response string
+	var suggested []string
 	if len(paginated.Results) == 0 && offset == 0 {
-		response = h.formatEmptyResponse(query, repo)
+		response, suggested = h.formatEmptyResponse(ctx, query, repo, paginated.IndexMeta)
 	} else {
 		data, _ := json.MarshalIndent(paginated, "", "  ")
 		response = string(data)
 	}
+	formatDone()
 
 	// Cache result
 	if h.cache != nil && cacheKey != "" {
-		ttl := time.Duration(h.config.Cache.QueryTTLMinutes) * time.Minute
-		if err := h.cache.Set(ctx, cacheKey, response, ttl); err != nil {
+		if err := h.cache.Set(ctx, cacheKey, response, h.queryTTL()); err != nil {
 			h.logger.Warn("failed to cache result", "error", err)
+		} else if semanticVariant != "" && len(paginated.Results) > 0 {
+			h.rememberQuery(ctx, query, repo, semanticVariant, version, cacheKey)
 		}
 	}
 
 	// Log metrics
-	if h.metrics != nil {
-		h.metrics.LogSearch(query, string(queryType), len(paginated.Results), time.Since(startTime).Milliseconds(), false)
+	if usage := h.usageMetrics(ctx); usage != nil {
+		usage.LogSearchEvent(metrics.SearchEvent{
+			Query:       query,
+			QueryType:   string(queryType),
+			Repo:        repo,
+			Module:      module,
+			Results:     len(paginated.Results),
+			LatencyMs:   time.Since(startTime).Milliseconds(),
+			Session:     sessionID(ctx),
+			Suggestions: suggested,
+			Stages:      stages.snapshot(),
+			Experiment:  experiment,
+			Variant:     arm,
+			Strategy:    chosen,
+		})
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{{Type: "text", Text: response}},
-	}, nil
+	return structuredResult(response), nil
 }
 
 // applyWeights re-ranks results by score * retrieval_weight, then truncates.
+// Feedback and search.boosts are already folded into the weight.
 func (h *Handler) applyWeights(chunks []chunk.Chunk, limit int) []chunk.Chunk {
 	// Sort by effective score (score * retrieval_weight) descending
 	sort.Slice(chunks, func(i, j int) bool {
@@ -367,11 +1050,25 @@ func (h *Handler) applyWeights(chunks []chunk.Chunk, limit int) []chunk.Chunk {
 	return chunks
 }
 
-// searchSemantic performs vector similarity search.
+// searchSemantic performs vector similarity search. With search.hybrid set,
+// keyword matches are fused in by reciprocal rank, which catches exact
+// identifiers and error strings the embedding ranks too low.
 func (h *Handler) searchSemantic(ctx context.Context, query string, filter map[string]interface{}, limit int) ([]chunk.Chunk, error) {
-	vectors, err := h.embedder.Embed(ctx, []string{query})
-	if err != nil {
-		return nil, fmt.Errorf("embedding failed: %w", err)
+	return h.searchSemanticWith(ctx, query, filter, limit, h.config != nil && h.config.Search.Hybrid)
+}
+
+// searchSemanticWith is searchSemantic with keyword fusion on or off
+// regardless of search.hybrid.
+func (h *Handler) searchSemanticWith(ctx context.Context, query string, filter map[string]interface{}, limit int, hybrid bool) ([]chunk.Chunk, error) {
+	vectors := [][]float32{queryVectorFromContext(ctx, query)}
+	if vectors[0] == nil {
+		var err error
+		done := timeStage(ctx, stageEmbed)
+		vectors, err = h.embedder.Embed(ctx, []string{query})
+		done()
+		if err != nil {
+			return nil, fmt.Errorf("embedding failed: %w", err)
+		}
 	}
 
 	// Get extra results for weighting adjustment
@@ -379,8 +1076,37 @@ func (h *Handler) searchSemantic(ctx context.Context, query string, filter map[s
 	if err != nil {
 		return nil, err
 	}
+	h.adjustFeedback(results)
+	h.adjustBoosts(results)
+	h.adjustDescribed(results)
+
+	if !hybrid {
+		return h.applyWeights(results, limit), nil
+	}
+	candidates := config.DefaultConfig().Search.LexicalCandidates
+	if h.config != nil {
+		candidates = h.config.Search.LexicalCandidates
+	}
+	lexical, err := h.searchLexical(ctx, query, filter, max(candidates, limit))
+	if err != nil {
+		// Keyword search is an extra; semantic results still stand
+		if h.logger != nil {
+			h.logger.Warn("keyword search failed", "query", query, "error", err)
+		}
+	}
+	if len(lexical) == 0 {
+		return h.applyWeights(results, limit), nil
+	}
 
-	return h.applyWeights(results, limit), nil
+	k := config.DefaultConfig().Search.RRFK
+	if h.config != nil && h.config.Search.RRFK > 0 {
+		k = h.config.Search.RRFK
+	}
+	fused := fuseRankings(k, h.applyWeights(results, len(results)), lexical)
+	if len(fused) > limit {
+		fused = fused[:limit]
+	}
+	return fused, nil
 }
 
 // searchBySymbol searches for exact or fuzzy symbol name matches.
@@ -435,25 +1161,38 @@ func (h *Handler) searchByPattern(ctx context.Context, query string, filter map[
 	return h.searchSemantic(ctx, query, filter, limit)
 }
 
-func (h *Handler) formatEmptyResponse(query, repo string) string {
-	// Generate suggestions based on query
-	suggestions := h.suggestionGen.Generate(query)
+// queryTTL is how long search responses stay cached.
+func (h *Handler) queryTTL() time.Duration {
+	return time.Duration(h.config.Cache.QueryTTLMinutes) * time.Minute
+}
+
+func (h *Handler) formatEmptyResponse(ctx context.Context, query, repo string, meta *IndexMeta) (string, []string) {
+	// Generate suggestions based on query and the repo's vocabulary
+	h.loadVocabulary(ctx, repo)
+	suggestions := h.suggestionGen.GenerateFor(query, repo)
 	response := h.suggestionGen.FormatEmptyResponse(query, repo, suggestions)
+	response["index_meta"] = meta
 
+	terms := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		terms[i] = s.Term
+	}
 	data, _ := json.MarshalIndent(response, "", "  ")
-	return string(data)
+	return string(data), terms
 }
 
 func (h *Handler) getRelevantContext(ctx context.Context) (*mcp.ReadResourceResult, error) {
-	// Get context from current working directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		return h.emptyRelevantContext(), nil
+	// Get context from the session's workspace, else the current working directory
+	cwd := sessionRoot(ctx)
+	if cwd == "" {
+		var err error
+		if cwd, err = os.Getwd(); err != nil {
+			return h.emptyRelevantContext(), nil
+		}
 	}
 
-	// Infer repo from cwd
-	repo := h.inferRepo()
-	if repo == "" {
+	repo, module := h.scope(ctx, map[string]interface{}{})
+	if repo == "" || !mcp.RepoAllowed(ctx, repo) {
 		return h.emptyRelevantContext(), nil
 	}
 
@@ -463,8 +1202,11 @@ func (h *Handler) getRelevantContext(ctx context.Context) (*mcp.ReadResourceResu
 	// Try to use graph to find related files based on cwd
 	if h.graphStore != nil {
 		// Get relative path within repo
-		homeDir, _ := os.UserHomeDir()
-		repoPath := filepath.Join(homeDir, "repos", repo)
+		repoPath := findRepoRoot(cwd)
+		if repoPath == "" {
+			homeDir, _ := os.UserHomeDir()
+			repoPath = filepath.Join(homeDir, "repos", repo)
+		}
 		relCwd, _ := filepath.Rel(repoPath, cwd)
 
 		// Find files in or near current directory
@@ -476,11 +1218,17 @@ func (h *Handler) getRelevantContext(ctx context.Context) (*mcp.ReadResourceResu
 		}
 	}
 
-	// If no graph results, use semantic search based on directory name
+	// If no graph results, use semantic search based on the pinned module or
+	// directory name
 	if len(suggestions) == 0 {
 		dirName := filepath.Base(cwd)
+		filter := map[string]interface{}{"repo": repo}
+		if module != "" {
+			dirName = module
+			filter["module_root"] = module
+		}
 		if dirName != "." && dirName != repo {
-			results, err := h.searchSemantic(ctx, dirName, map[string]interface{}{"repo": repo}, 5)
+			results, err := h.searchSemantic(ctx, dirName, filter, 5)
 			if err == nil {
 				for _, c := range results {
 					suggestions = append(suggestions, fmt.Sprintf("- `%s:%d-%d` %s (%s)",
@@ -531,29 +1279,6 @@ func (h *Handler) emptyRelevantContext() *mcp.ReadResourceResult {
 	}
 }
 
-func (h *Handler) inferRepo() string {
-	cwd, err := os.Getwd()
-	if err != nil {
-		return ""
-	}
-
-	homeDir, _ := os.UserHomeDir()
-	reposDir := filepath.Join(homeDir, "repos")
-
-	if rel, err := filepath.Rel(reposDir, cwd); err == nil {
-		// Check we're actually under reposDir (rel doesn't start with ..)
-		if !strings.HasPrefix(rel, "..") {
-			// First path component is the repo name
-			parts := strings.SplitN(rel, string(filepath.Separator), 2)
-			if len(parts) > 0 && parts[0] != "." {
-				return parts[0]
-			}
-		}
-	}
-
-	return ""
-}
-
 // expandWithGraph expands search results using graph relationships.
 // For each result, it finds related symbols via CALLS, EXTENDS, and IMPORTS
 // relationships and adds them to the result set.
@@ -577,7 +1302,9 @@ func (h *Handler) expandWithGraph(ctx context.Context, results []chunk.Chunk, re
 	}
 
 	// Expand from the found symbols
+	done := timeStage(ctx, stageGraph)
 	expandedSymbols, err := h.graphStore.ExpandFromSymbols(ctx, repo, symbolNames, depth, limit)
+	done()
 	if err != nil {
 		h.logger.Warn("graph expansion failed", "error", err)
 		return results
@@ -639,13 +1366,90 @@ type SearchResponse struct {
 
 // SearchResult is a single search result.
 type SearchResult struct {
-	FilePath   string `json:"file_path"`
-	Module     string `json:"module"`
-	SymbolName string `json:"symbol_name,omitempty"`
-	Kind       string `json:"kind,omitempty"`
-	StartLine  int    `json:"start_line"`
-	EndLine    int    `json:"end_line"`
-	Content    string `json:"content"`
-	Docstring  string `json:"docstring,omitempty"`
-	IsTest     bool   `json:"is_test"`
+	ID         string            `json:"id"` // For search_feedback
+	Repo       string            `json:"repo,omitempty"`
+	FilePath   string            `json:"file_path"`
+	Module     string            `json:"module"`
+	SymbolName string            `json:"symbol_name,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+	StartLine  int               `json:"start_line"`
+	EndLine    int               `json:"end_line"`
+	Content    string            `json:"content"`
+	Docstring  string            `json:"docstring,omitempty"`
+	Summary    string            `json:"summary,omitempty"`
+	IsTest     bool              `json:"is_test"`
+	Owner      string            `json:"owner,omitempty"`
+	Owners     []string          `json:"owners,omitempty"`     // From CODEOWNERS
+	License    string            `json:"license,omitempty"`    // SPDX identifier, "proprietary", or "unknown"
+	Branch     string            `json:"branch,omitempty"`     // Branch the chunk was indexed from
+	Dirty      bool              `json:"dirty,omitempty"`      // Indexed from uncommitted changes
+	AlsoAt     []string          `json:"also_at,omitempty"`    // Identical copies elsewhere, as "path:start-end"
+	Metadata   map[string]string `json:"metadata,omitempty"`   // Set by indexer enrichers
+	Highlights []Highlight       `json:"highlights,omitempty"` // Lines with the most query terms, in line order
+
+	// With group_by file: the best chunk's score, matched chunks and
+	// symbols, and the merged line ranges ("start-end") they cover
+	Score   float32  `json:"score,omitempty"`
+	Matches int      `json:"matches,omitempty"`
+	Symbols []string `json:"symbols,omitempty"`
+	Ranges  []string `json:"ranges,omitempty"`
+}
+
+// alsoAt formats a chunk's duplicate locations for results.
+func alsoAt(locs []chunk.Location) []string {
+	if len(locs) == 0 {
+		return nil
+	}
+	out := make([]string, len(locs))
+	for i, l := range locs {
+		out[i] = fmt.Sprintf("%s:%d-%d", l.FilePath, l.StartLine, l.EndLine)
+	}
+	return out
+}
+
+// searchOptions are the search_code arguments besides the query that shape
+// its results.
+type searchOptions struct {
+	module       string
+	owner        string
+	includeTests string
+	groupBy      string
+	strategy     string // Set only when the caller chose it
+	searchIn     string
+	limit        int
+	offset       int // Cursor position; each page is cached on its own
+	scope        scopeFilters
+}
+
+// cacheVariant keys cached responses (exact and semantic) by the options and
+// page, so searches with different options, or other pages of one search,
+// never share them.
+func (o searchOptions) cacheVariant(ctx context.Context) string {
+	variant := fmt.Sprintf("\x00limit:%d", o.limit)
+	if o.offset > 0 {
+		variant += fmt.Sprintf("\x00offset:%d", o.offset)
+	}
+	if o.module != "" {
+		variant += "\x00module:" + o.module
+	}
+	if o.includeTests != "" {
+		variant += "\x00include_tests:" + o.includeTests
+	}
+	if o.owner != "" {
+		variant += "\x00owner:" + o.owner
+	}
+	variant += o.scope.key() + moduleScopeKey(ctx)
+	if includeSensitive(ctx) {
+		variant += "\x00include_sensitive"
+	}
+	if o.groupBy != "" {
+		variant += "\x00group_by:" + o.groupBy
+	}
+	if o.strategy != "" {
+		variant += "\x00strategy:" + o.strategy
+	}
+	if o.searchIn != SearchInAll {
+		variant += "\x00search_in:" + o.searchIn
+	}
+	return variant
 }
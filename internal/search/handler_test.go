@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,12 +19,53 @@ func TestHandlerGetTools(t *testing.T) {
 
 	tools := handler.ListTools()
 
-	require.Len(t, tools, 1)
-	assert.Equal(t, "search_code", tools[0].Name)
-	assert.Contains(t, tools[0].Description, "semantic")
+	require.Len(t, tools, 16)
+	assert.Equal(t, "search_code", tools[0].Name, "search_code stays the first tool")
 
-	// Verify required params
-	assert.Contains(t, tools[0].InputSchema.Required, "query")
+	byName := make(map[string]mcp.Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	require.Len(t, byName, len(tools), "tool names are unique")
+
+	assert.Contains(t, byName["search_code"].Description, "semantic")
+	assert.Contains(t, byName["search_code"].InputSchema.Properties, "owner")
+
+	// Required params per tool
+	required := map[string][]string{
+		"search_code":     {"query"},
+		"who_owns":        {"path"},
+		"list_modules":    nil,
+		"module_contents": {"module"},
+		"class_hierarchy": {"class"},
+		"get_symbol":      {"name"},
+		"list_repos":      nil,
+		"index_status":    nil,
+		"reindex_file":    {"path"},
+		"grep_code":       {"pattern"},
+		"similar_code":    {"snippet"},
+		"explain_module":  {"module"},
+		"search_docs":     {"query"},
+		"set_context":     nil,
+		"search_feedback": {"id", "useful"},
+		"mark_used":       {"file_path"},
+	}
+	for name, params := range required {
+		tool, ok := byName[name]
+		require.True(t, ok, "missing tool %s", name)
+		for _, p := range params {
+			assert.Contains(t, tool.InputSchema.Required, p, name)
+		}
+	}
+	assert.Empty(t, byName["set_context"].InputSchema.Required)
+	assert.ElementsMatch(t, []string{"id", "useful"}, byName["search_feedback"].InputSchema.Required)
+	assert.Equal(t, []string{"file_path"}, byName["mark_used"].InputSchema.Required)
+
+	for _, tool := range tools {
+		require.NotNil(t, tool.Annotations, tool.Name)
+		readOnly := tool.Name != "reindex_file" && tool.Name != "set_context" && tool.Name != "search_feedback" && tool.Name != "mark_used"
+		assert.Equal(t, readOnly, tool.Annotations.ReadOnlyHint, tool.Name)
+	}
 }
 
 func TestHandlerListResources(t *testing.T) {
@@ -65,7 +107,7 @@ func TestHandlerInferRepo(t *testing.T) {
 
 	// Test that inferRepo returns empty string when not in a repo dir
 	// (since we don't want to test with actual filesystem state)
-	repo := handler.inferRepo()
+	repo := handler.inferRepo(context.Background())
 	// Result depends on current working directory, so just verify it doesn't panic
 	_ = repo
 }
@@ -99,9 +141,23 @@ func TestFormatEmptyResponse(t *testing.T) {
 		suggestionGen: NewSuggestionGenerator(),
 	}
 
-	response := handler.formatEmptyResponse("test query", "my-repo")
+	response, _ := handler.formatEmptyResponse(context.Background(), "test query", "my-repo", handler.indexMeta(context.Background(), "my-repo", 3))
 
 	assert.Contains(t, response, "No direct matches")
 	assert.Contains(t, response, "test query")
 	assert.Contains(t, response, "my-repo")
+	assert.Contains(t, response, `"generation": 3`)
+}
+
+func TestHandlerClassHierarchyRequiresGraph(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "class_hierarchy", map[string]interface{}{
+		"class": "BaseImporter",
+		"repo":  "my-repo",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "requires Neo4j")
 }
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+func (h *Handler) classHierarchy(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	className, _ := args["class"].(string)
+	if className == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "class parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+
+	repo, _ := args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	if repo == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "repo parameter is required (could not infer from cwd)"}},
+			IsError: true,
+		}, nil
+	}
+
+	if h.graphStore == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "class_hierarchy requires Neo4j (set NEO4J_PASSWORD and re-index)"}},
+			IsError: true,
+		}, nil
+	}
+
+	depth := graph.DefaultHierarchyDepth
+	if d, ok := args["depth"].(float64); ok && d > 0 {
+		depth = int(d)
+	}
+
+	hierarchies, err := h.graphStore.FindClassHierarchy(ctx, repo, className, depth)
+	if err != nil {
+		return nil, fmt.Errorf("class_hierarchy failed: %w", err)
+	}
+	if hierarchies, err = h.scopeHierarchies(ctx, repo, hierarchies); err != nil {
+		return nil, fmt.Errorf("class_hierarchy failed: %w", err)
+	}
+	if len(hierarchies) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("No class named %q found in %s.", className, repo)}},
+		}, nil
+	}
+
+	data, _ := json.MarshalIndent(map[string]interface{}{
+		"repo":        repo,
+		"hierarchies": hierarchies,
+	}, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+// scopeHierarchies drops the classes of modules the caller may not read,
+// looking up the module of every file in the hierarchies.
+func (h *Handler) scopeHierarchies(ctx context.Context, repo string, hierarchies []graph.ClassHierarchy) ([]graph.ClassHierarchy, error) {
+	if len(hierarchies) == 0 {
+		return hierarchies, nil
+	}
+	allowed, err := h.readableFiles(ctx, repo, hierarchyPaths(hierarchies))
+	if err != nil || allowed == nil {
+		return hierarchies, err
+	}
+	return pruneHierarchies(hierarchies, allowed), nil
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// maxHighlights is how many lines a result highlights.
+const maxHighlights = 3
+
+// Highlight is a salient line of a result.
+type Highlight struct {
+	Line  int        `json:"line"` // Line number in the file
+	Text  string     `json:"text"`
+	Spans []TermSpan `json:"spans,omitempty"` // Where query terms occur in Text
+}
+
+// TermSpan is a query term match in a highlighted line, as byte offsets
+// into its text (end exclusive).
+type TermSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// highlightResults sets the highlights of results for query.
+func highlightResults(query string, results []SearchResult) {
+	terms := lexicalQueryTerms(query)
+	for i := range results {
+		r := &results[i]
+		r.Highlights = highlightLines(terms, r.Content, r.StartLine, r.SymbolName)
+	}
+}
+
+// highlightLines picks up to maxHighlights lines of content (starting at
+// line startLine) that contain the most distinct query terms, then the most
+// matches, and returns them in line order. When no line has a term, the
+// line defining symbol stands in, so semantic matches still point at
+// something.
+func highlightLines(terms []string, content string, startLine int, symbol string) []Highlight {
+	type scored struct {
+		highlight Highlight
+		score     float64
+	}
+	var candidates []scored
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		spans, distinct := termSpans(terms, line)
+		if distinct == 0 {
+			continue
+		}
+		candidates = append(candidates, scored{
+			highlight: Highlight{Line: startLine + i, Text: line, Spans: spans},
+			score:     float64(distinct) + math.Log1p(float64(len(spans)))/10,
+		})
+	}
+
+	if len(candidates) == 0 {
+		if symbol == "" {
+			return nil
+		}
+		for i, line := range lines {
+			if strings.Contains(line, symbol) {
+				return []Highlight{{Line: startLine + i, Text: line}}
+			}
+		}
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	candidates = candidates[:min(len(candidates), maxHighlights)]
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].highlight.Line < candidates[j].highlight.Line })
+	highlights := make([]Highlight, len(candidates))
+	for i, c := range candidates {
+		highlights[i] = c.highlight
+	}
+	return highlights
+}
+
+// termSpans finds the case-insensitive occurrences of terms in line, with
+// overlapping ones merged, and how many distinct terms occur.
+func termSpans(terms []string, line string) ([]TermSpan, int) {
+	lower := strings.ToLower(line)
+	if len(lower) != len(line) {
+		// Lowercasing changed byte widths, so offsets would not line up
+		return nil, 0
+	}
+	var spans []TermSpan
+	distinct := 0
+	for _, term := range terms {
+		found := false
+		for from := 0; ; {
+			idx := strings.Index(lower[from:], term)
+			if idx < 0 {
+				break
+			}
+			start := from + idx
+			spans = append(spans, TermSpan{Start: start, End: start + len(term)})
+			from = start + len(term)
+			found = true
+		}
+		if found {
+			distinct++
+		}
+	}
+	if len(spans) == 0 {
+		return nil, 0
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.Start <= last.End {
+			last.End = max(last.End, s.End)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged, distinct
+}
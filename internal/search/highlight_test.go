@@ -0,0 +1,41 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightLines(t *testing.T) {
+	content := `def upload(path):
+    data = read(path)
+    for attempt in range(3):
+        try:
+            return client.put(data)
+        except Timeout:
+            log.warning("upload retry %d", attempt)
+    raise UploadError(path)`
+
+	highlights := highlightLines(lexicalQueryTerms("upload retry"), content, 40, "upload")
+	require.Len(t, highlights, 3)
+	assert.Equal(t, 40, highlights[0].Line)
+	assert.Equal(t, 46, highlights[1].Line)
+	assert.Equal(t, `            log.warning("upload retry %d", attempt)`, highlights[1].Text)
+	assert.Equal(t, []TermSpan{{Start: 25, End: 31}, {Start: 32, End: 37}}, highlights[1].Spans)
+	assert.Equal(t, 47, highlights[2].Line, "UploadError matches case-insensitively")
+}
+
+func TestHighlightLinesWithoutTermsUsesDefinition(t *testing.T) {
+	content := "@retry\ndef fetch_rows(cursor):\n    return cursor.fetchall()"
+	highlights := highlightLines(lexicalQueryTerms("database reads"), content, 10, "fetch_rows")
+	assert.Equal(t, []Highlight{{Line: 11, Text: "def fetch_rows(cursor):"}}, highlights)
+
+	assert.Nil(t, highlightLines(nil, content, 10, ""))
+}
+
+func TestTermSpansMergesOverlaps(t *testing.T) {
+	spans, distinct := termSpans([]string{"parsehttpconfig", "parse", "config"}, "cfg = parseHTTPConfig(raw)")
+	assert.Equal(t, 3, distinct)
+	assert.Equal(t, []TermSpan{{Start: 6, End: 21}}, spans)
+}
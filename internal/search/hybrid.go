@@ -0,0 +1,109 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/store"
+)
+
+// stopWords are left out of keyword queries, where every remaining term
+// must appear in a chunk for it to match.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"can": true, "do": true, "does": true, "for": true, "from": true, "how": true, "in": true,
+	"is": true, "it": true, "of": true, "on": true, "or": true, "the": true, "that": true,
+	"this": true, "to": true, "we": true, "what": true, "when": true, "where": true, "which": true,
+	"who": true, "why": true, "with": true,
+}
+
+// lexicalQueryTerms returns the keyword terms of query, tokenized like
+// chunk.LexicalTerms, without stop words.
+func lexicalQueryTerms(query string) []string {
+	var terms []string
+	for _, token := range chunk.LexicalTokens(query) {
+		if !stopWords[token] {
+			terms = append(terms, token)
+		}
+	}
+	return terms
+}
+
+// searchLexical returns up to limit chunks containing every keyword term of
+// query, best first. Chunks indexed before lexical terms were stored never
+// match.
+func (h *Handler) searchLexical(ctx context.Context, query string, filter map[string]interface{}, limit int) ([]chunk.Chunk, error) {
+	terms := lexicalQueryTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	chunks, _, err := h.store.ScrollByText(ctx, "chunks", store.LexicalField, strings.Join(terms, " "), filter, limit, "")
+	if err != nil {
+		return nil, err
+	}
+	h.adjustFeedback(chunks)
+	h.adjustBoosts(chunks)
+	h.adjustDescribed(chunks)
+	rankLexical(query, terms, chunks)
+	return chunks, nil
+}
+
+// rankLexical sorts keyword matches by lexicalScore times retrieval weight.
+func rankLexical(query string, terms []string, chunks []chunk.Chunk) {
+	for i := range chunks {
+		chunks[i].Score = float32(lexicalScore(query, terms, chunks[i]))
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].Score*chunks[i].RetrievalWeight > chunks[j].Score*chunks[j].RetrievalWeight
+	})
+}
+
+// lexicalScore rates a keyword match: how often the terms occur in the
+// code, with bonuses when the chunk is the symbol asked for or contains the
+// query verbatim (an error message, say).
+func lexicalScore(query string, terms []string, c chunk.Chunk) float64 {
+	content := strings.ToLower(c.Content)
+	var score float64
+	for _, term := range terms {
+		score += math.Log1p(float64(strings.Count(content, term)))
+	}
+	if symbol := chunk.LexicalTokens(c.SymbolName); len(symbol) > 0 {
+		for _, term := range terms {
+			if term == symbol[0] {
+				score += 2
+				break
+			}
+		}
+	}
+	if phrase := strings.ToLower(strings.TrimSpace(query)); strings.Contains(phrase, " ") && strings.Contains(content, phrase) {
+		score += 3
+	}
+	return score
+}
+
+// fuseRankings merges ranked result lists with reciprocal rank fusion:
+// a chunk scores the sum of 1/(k+rank) over the lists it appears in, so
+// agreement between lists beats a high rank in just one. Score is set to
+// the fused score.
+func fuseRankings(k int, lists ...[]chunk.Chunk) []chunk.Chunk {
+	scores := make(map[string]float64)
+	var fused []chunk.Chunk
+	for _, list := range lists {
+		for rank, c := range list {
+			if _, ok := scores[c.ID]; !ok {
+				fused = append(fused, c)
+			}
+			scores[c.ID] += 1 / float64(k+rank+1)
+		}
+	}
+	for i := range fused {
+		fused[i].Score = float32(scores[fused[i].ID])
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score > fused[j].Score
+	})
+	return fused
+}
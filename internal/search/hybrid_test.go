@@ -0,0 +1,53 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLexicalQueryTerms(t *testing.T) {
+	assert.Equal(t, []string{"parseconfig", "parse", "config"}, lexicalQueryTerms("where is parseConfig"))
+	assert.Equal(t, []string{"connection", "refused"}, lexicalQueryTerms("connection refused"))
+	assert.Empty(t, lexicalQueryTerms("where is it"), "only stop words")
+}
+
+func TestRankLexical(t *testing.T) {
+	query := "bucket not found"
+	chunks := []chunk.Chunk{
+		{ID: "mention", Content: "# bucket lookups: not all found", RetrievalWeight: 1},
+		{ID: "verbatim", Content: `raise UploadError("bucket not found")`, RetrievalWeight: 1},
+		{ID: "test", Content: `assert err == "bucket not found"`, RetrievalWeight: 0.5},
+	}
+
+	rankLexical(query, lexicalQueryTerms(query), chunks)
+
+	assert.Equal(t, "verbatim", chunks[0].ID, "the verbatim phrase wins")
+	assert.Equal(t, "test", chunks[1].ID, "test files are down-weighted, not dropped")
+	assert.Equal(t, "mention", chunks[2].ID)
+}
+
+func TestLexicalScoreSymbolBonus(t *testing.T) {
+	terms := lexicalQueryTerms("parseConfig")
+	definition := chunk.Chunk{SymbolName: "parseConfig", Content: "func parseConfig() {}"}
+	caller := chunk.Chunk{SymbolName: "main", Content: "func main() { parseConfig() }"}
+
+	assert.Greater(t, lexicalScore("parseConfig", terms, definition), lexicalScore("parseConfig", terms, caller))
+}
+
+func TestFuseRankings(t *testing.T) {
+	semantic := []chunk.Chunk{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	lexical := []chunk.Chunk{{ID: "c"}, {ID: "d"}}
+
+	fused := fuseRankings(60, semantic, lexical)
+
+	require.Len(t, fused, 4)
+	ids := make([]string, len(fused))
+	for i, c := range fused {
+		ids[i] = c.ID
+	}
+	assert.Equal(t, []string{"c", "a", "b", "d"}, ids, "found by both lists beats first in one")
+	assert.InDelta(t, 1.0/63+1.0/61, fused[0].Score, 1e-6)
+}
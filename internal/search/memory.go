@@ -0,0 +1,203 @@
+package search
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// sessionMemoryKey stores the session's *sessionMemory on the mcp.Session.
+const sessionMemoryKey = "search.memory"
+
+// memoryInit serializes creating a session's memory, so concurrent first
+// searches share one.
+var memoryInit sync.Mutex
+
+// memoryEntry is what one search_code query returned, across its pages.
+type memoryEntry struct {
+	hash    string // HashQuery of the query; its own pages are never deduplicated
+	query   string
+	files   []string // repo + "\x00" + path
+	modules []string
+	chunks  []string // IDs
+}
+
+// sessionMemory remembers the results of a session's recent queries.
+type sessionMemory struct {
+	mu      sync.Mutex
+	size    int
+	entries []memoryEntry // Oldest first
+}
+
+// MemorySnapshot is what a session's search memory holds, as shown by
+// set_context.
+type MemorySnapshot struct {
+	RecentQueries []string `json:"recent_queries"`
+	RecentFiles   []string `json:"recent_files"`
+	ChunksShown   int      `json:"chunks_shown"`
+}
+
+// sessionMemory returns the search memory of the request's session; nil
+// when search.session_memory is off or the call has no session.
+func (h *Handler) sessionMemory(ctx context.Context) *sessionMemory {
+	if h.config == nil || !h.config.Search.SessionMemory.Enabled {
+		return nil
+	}
+	session := mcp.SessionFromContext(ctx)
+	if session == nil {
+		return nil
+	}
+	memoryInit.Lock()
+	defer memoryInit.Unlock()
+	if v, ok := session.Get(sessionMemoryKey); ok {
+		if m, ok := v.(*sessionMemory); ok {
+			return m
+		}
+	}
+	size := h.config.Search.SessionMemory.MaxQueries
+	if size <= 0 {
+		size = config.DefaultConfig().Search.SessionMemory.MaxQueries
+	}
+	m := &sessionMemory{size: size}
+	session.Set(sessionMemoryKey, m)
+	return m
+}
+
+// apply drops the chunks other recent queries already returned, unless
+// includeSeen, and boosts the rest by their file (boost) or module (half of
+// it) having been returned recently. It returns the kept chunks and how
+// many were dropped.
+func (m *sessionMemory) apply(results []chunk.Chunk, hash string, boost float64, includeSeen bool) ([]chunk.Chunk, int) {
+	m.mu.Lock()
+	files := make(map[string]bool)
+	modules := make(map[string]bool)
+	shown := make(map[string]bool)
+	for _, e := range m.entries {
+		for _, f := range e.files {
+			files[f] = true
+		}
+		for _, mod := range e.modules {
+			modules[mod] = true
+		}
+		if e.hash != hash {
+			for _, id := range e.chunks {
+				shown[id] = true
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	omitted := 0
+	if !includeSeen && len(shown) > 0 {
+		kept := results[:0]
+		for _, c := range results {
+			if shown[c.ID] {
+				omitted++
+				continue
+			}
+			kept = append(kept, c)
+		}
+		results = kept
+	}
+
+	if boost <= 0 || (len(files) == 0 && len(modules) == 0) {
+		return results, omitted
+	}
+	factor := func(c chunk.Chunk) float32 {
+		switch {
+		case files[c.Repo+"\x00"+c.FilePath]:
+			return float32(1 + boost)
+		case c.ModulePath != "" && modules[c.ModulePath]:
+			return float32(1 + boost/2)
+		}
+		return 1
+	}
+	for i := range results {
+		results[i].Score *= factor(results[i])
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results, omitted
+}
+
+// record remembers the chunks a query returned. Further pages of the same
+// query add to its entry; a new query evicts the oldest beyond the size.
+func (m *sessionMemory) record(hash, query string, shown []chunk.Chunk) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n := len(m.entries); n == 0 || m.entries[n-1].hash != hash {
+		m.entries = append(m.entries, memoryEntry{hash: hash, query: query})
+		if len(m.entries) > m.size {
+			m.entries = slices.Delete(m.entries, 0, len(m.entries)-m.size)
+		}
+	}
+	e := &m.entries[len(m.entries)-1]
+	for _, c := range shown {
+		if file := c.Repo + "\x00" + c.FilePath; !slices.Contains(e.files, file) {
+			e.files = append(e.files, file)
+		}
+		if c.ModulePath != "" && !slices.Contains(e.modules, c.ModulePath) {
+			e.modules = append(e.modules, c.ModulePath)
+		}
+		if !slices.Contains(e.chunks, c.ID) {
+			e.chunks = append(e.chunks, c.ID)
+		}
+	}
+}
+
+// snapshot returns the remembered queries, newest first, and their files.
+func (m *sessionMemory) snapshot() MemorySnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap := MemorySnapshot{RecentQueries: []string{}, RecentFiles: []string{}}
+	seen := make(map[string]bool)
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		e := m.entries[i]
+		snap.RecentQueries = append(snap.RecentQueries, e.query)
+		snap.ChunksShown += len(e.chunks)
+		for _, f := range e.files {
+			if !seen[f] {
+				seen[f] = true
+				_, path, _ := strings.Cut(f, "\x00")
+				snap.RecentFiles = append(snap.RecentFiles, path)
+			}
+		}
+	}
+	return snap
+}
+
+// reset forgets everything.
+func (m *sessionMemory) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = nil
+}
+
+// shownChunks returns the chunks behind a page of results: the page's slice
+// of results, or with grouping, every chunk of the page's files.
+func shownChunks(results []chunk.Chunk, page []SearchResult, offset int, grouped bool) []chunk.Chunk {
+	if !grouped {
+		if offset >= len(results) {
+			return nil
+		}
+		return results[offset:min(offset+len(page), len(results))]
+	}
+	paths := make(map[string]bool, len(page))
+	for _, r := range page {
+		paths[r.FilePath] = true
+	}
+	var shown []chunk.Chunk
+	for _, c := range results {
+		if paths[c.FilePath] {
+			shown = append(shown, c)
+		}
+	}
+	return shown
+}
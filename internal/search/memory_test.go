@@ -0,0 +1,104 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func memoryHandler() *Handler {
+	cfg := config.DefaultConfig()
+	cfg.Search.SessionMemory.Enabled = true
+	return &Handler{config: cfg}
+}
+
+func TestSessionMemoryPerSession(t *testing.T) {
+	assert.Nil(t, (&Handler{config: config.DefaultConfig()}).sessionMemory(mcp.WithSession(context.Background(), mcp.NewSession())), "off by default")
+
+	handler := memoryHandler()
+	assert.Nil(t, handler.sessionMemory(context.Background()), "no session, no memory")
+
+	first := mcp.WithSession(context.Background(), mcp.NewSession())
+	second := mcp.WithSession(context.Background(), mcp.NewSession())
+	require.NotNil(t, handler.sessionMemory(first))
+	assert.Same(t, handler.sessionMemory(first), handler.sessionMemory(first))
+	assert.NotSame(t, handler.sessionMemory(first), handler.sessionMemory(second))
+}
+
+func TestSessionMemoryDeduplicatesAndBoosts(t *testing.T) {
+	m := &sessionMemory{size: 20}
+	m.record("q1", "token refresh", []chunk.Chunk{
+		{ID: "a", Repo: "r", FilePath: "auth/tokens.py", ModulePath: "auth"},
+	})
+
+	results := []chunk.Chunk{
+		{ID: "x", Repo: "r", FilePath: "billing/invoice.py", ModulePath: "billing", Score: 1.0},
+		{ID: "a", Repo: "r", FilePath: "auth/tokens.py", ModulePath: "auth", Score: 0.95},
+		{ID: "b", Repo: "r", FilePath: "auth/tokens.py", ModulePath: "auth", Score: 0.9},
+		{ID: "c", Repo: "r", FilePath: "auth/session.py", ModulePath: "auth", Score: 0.85},
+	}
+	kept, omitted := m.apply(append([]chunk.Chunk(nil), results...), "q2", 0.2, false)
+	assert.Equal(t, 1, omitted)
+	require.Len(t, kept, 3)
+	assert.Equal(t, "b", kept[0].ID, "same file: 0.9 * 1.2")
+	assert.Equal(t, "x", kept[1].ID)
+	assert.Equal(t, "c", kept[2].ID, "same module: 0.85 * 1.1")
+
+	kept, omitted = m.apply(append([]chunk.Chunk(nil), results...), "q2", 0.2, true)
+	assert.Zero(t, omitted)
+	assert.Len(t, kept, 4)
+
+	kept, omitted = m.apply(append([]chunk.Chunk(nil), results...), "q1", 0, false)
+	assert.Zero(t, omitted, "a query's own pages are not deduplicated")
+	assert.Equal(t, "x", kept[0].ID)
+}
+
+func TestSessionMemoryRecordEvicts(t *testing.T) {
+	m := &sessionMemory{size: 2}
+	m.record("q1", "first", []chunk.Chunk{{ID: "a", Repo: "r", FilePath: "a.py"}})
+	m.record("q1", "first", []chunk.Chunk{{ID: "b", Repo: "r", FilePath: "b.py"}})
+	m.record("q2", "second", []chunk.Chunk{{ID: "c", Repo: "r", FilePath: "c.py"}})
+	m.record("q3", "third", nil)
+
+	snap := m.snapshot()
+	assert.Equal(t, []string{"third", "second"}, snap.RecentQueries)
+	assert.Equal(t, []string{"c.py"}, snap.RecentFiles)
+	assert.Equal(t, 1, snap.ChunksShown)
+
+	m.reset()
+	assert.Empty(t, m.snapshot().RecentQueries)
+}
+
+func TestShownChunks(t *testing.T) {
+	results := []chunk.Chunk{{ID: "a", FilePath: "a.py"}, {ID: "b", FilePath: "b.py"}, {ID: "c", FilePath: "a.py"}}
+	shown := shownChunks(results, make([]SearchResult, 1), 1, false)
+	require.Len(t, shown, 1)
+	assert.Equal(t, "b", shown[0].ID)
+
+	shown = shownChunks(results, []SearchResult{{FilePath: "a.py"}}, 0, true)
+	assert.Len(t, shown, 2)
+}
+
+func TestSetContextClearsMemory(t *testing.T) {
+	handler := memoryHandler()
+	ctx := mcp.WithSession(context.Background(), mcp.NewSession())
+	handler.sessionMemory(ctx).record("q1", "retry logic", []chunk.Chunk{{ID: "a", Repo: "r", FilePath: "a.py"}})
+
+	result, err := handler.setContext(ctx, map[string]interface{}{"repo": "r"})
+	require.NoError(t, err)
+	var out struct {
+		Memory MemorySnapshot `json:"memory"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &out))
+	assert.Equal(t, []string{"retry logic"}, out.Memory.RecentQueries)
+
+	_, err = handler.setContext(ctx, map[string]interface{}{"clear": true})
+	require.NoError(t, err)
+	assert.Empty(t, handler.sessionMemory(ctx).snapshot().RecentQueries)
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// IndexMeta describes the index state that produced a search response, so
+// surprising results can be traced back to a stale or partial index.
+type IndexMeta struct {
+	Generation     int64     `json:"generation"`
+	Commit         string    `json:"commit,omitempty"`
+	IndexedAt      time.Time `json:"indexed_at,omitzero"`
+	EmbeddingModel string    `json:"embedding_model"`
+	GraphAvailable bool      `json:"graph_available"`
+	CacheHit       bool      `json:"cache_hit"`
+	CachedQuery    string    `json:"cached_query,omitempty"` // Similar query whose cached response this is (semantic cache)
+}
+
+// indexMeta gathers provenance for repo. generation is the cache index version
+// (0 without Redis); commit and timestamp come from the graph when available.
+func (h *Handler) indexMeta(ctx context.Context, repo string, generation int64) *IndexMeta {
+	meta := &IndexMeta{
+		Generation:     generation,
+		GraphAvailable: h.graphStore != nil,
+	}
+	if h.config != nil {
+		meta.EmbeddingModel = h.config.Embedding.Model
+	}
+
+	if h.graphStore != nil && repo != "" && repo != "all" {
+		r, err := h.graphStore.GetRepository(ctx, repo)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.Debug("failed to load index state", "repo", repo, "error", err)
+			}
+		} else if r != nil {
+			meta.Commit = r.IndexedCommit
+			meta.IndexedAt = r.IndexedAt
+		}
+	}
+
+	return meta
+}
+
+// markCacheHit sets index_meta.cache_hit on a cached response. Paginated
+// responses keep their field order; other objects are re-encoded as maps.
+func markCacheHit(cached string) string {
+	return markCacheHitFor(cached, "")
+}
+
+// markCacheHitFor is markCacheHit for a response cached for another,
+// similar query, recorded as index_meta.cached_query.
+func markCacheHitFor(cached, cachedQuery string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(cached), &fields); err != nil {
+		return cached
+	}
+	raw, ok := fields["index_meta"]
+	if !ok {
+		return cached
+	}
+
+	if _, paginated := fields["total_count"]; paginated {
+		var resp PaginatedResponse
+		if err := json.Unmarshal([]byte(cached), &resp); err != nil || resp.IndexMeta == nil {
+			return cached
+		}
+		resp.IndexMeta.CacheHit = true
+		resp.IndexMeta.CachedQuery = cachedQuery
+		data, _ := json.MarshalIndent(resp, "", "  ")
+		return string(data)
+	}
+
+	var meta IndexMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return cached
+	}
+	meta.CacheHit = true
+	meta.CachedQuery = cachedQuery
+	fields["index_meta"], _ = json.Marshal(meta)
+	data, _ := json.MarshalIndent(fields, "", "  ")
+	return string(data)
+}
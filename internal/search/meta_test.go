@@ -0,0 +1,59 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexMetaWithoutServices(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	meta := handler.indexMeta(context.Background(), "my-repo", 7)
+
+	assert.Equal(t, int64(7), meta.Generation)
+	assert.Equal(t, "voyage-4-large", meta.EmbeddingModel)
+	assert.False(t, meta.GraphAvailable)
+	assert.False(t, meta.CacheHit)
+	assert.Empty(t, meta.Commit)
+}
+
+func TestMarkCacheHitPaginated(t *testing.T) {
+	resp := Paginate([]SearchResult{{FilePath: "a.py"}}, 0, 10, "hash", "concept")
+	resp.IndexMeta = &IndexMeta{Generation: 2, Commit: "abc"}
+	data, err := json.MarshalIndent(resp, "", "  ")
+	require.NoError(t, err)
+
+	var got PaginatedResponse
+	require.NoError(t, json.Unmarshal([]byte(markCacheHit(string(data))), &got))
+
+	require.NotNil(t, got.IndexMeta)
+	assert.True(t, got.IndexMeta.CacheHit)
+	assert.Equal(t, "abc", got.IndexMeta.Commit)
+	assert.Len(t, got.Results, 1)
+}
+
+func TestMarkCacheHitEmptyResponse(t *testing.T) {
+	data, err := json.Marshal(map[string]interface{}{
+		"message":    "No direct matches",
+		"index_meta": IndexMeta{Generation: 1},
+	})
+	require.NoError(t, err)
+
+	var got map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(markCacheHit(string(data))), &got))
+
+	var meta IndexMeta
+	require.NoError(t, json.Unmarshal(got["index_meta"], &meta))
+	assert.True(t, meta.CacheHit)
+	assert.Contains(t, string(got["message"]), "No direct matches")
+}
+
+func TestMarkCacheHitWithoutMeta(t *testing.T) {
+	assert.Equal(t, "not json", markCacheHit("not json"))
+	assert.Equal(t, `{"results":[]}`, markCacheHit(`{"results":[]}`))
+}
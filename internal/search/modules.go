@@ -0,0 +1,233 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// maxModuleChunks bounds the chunk scan used when Neo4j is unavailable.
+const maxModuleChunks = 2000
+
+// ModuleListing is one entry of the list_modules tool result.
+type ModuleListing struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Files       int    `json:"files,omitempty"`
+	Chunks      int    `json:"chunks"`
+}
+
+// ModuleSymbol is a symbol entry of the module_contents tool result.
+type ModuleSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	Callers   int    `json:"callers,omitempty"`
+}
+
+// ModuleContentsResponse is the module_contents tool result.
+type ModuleContentsResponse struct {
+	Repo       string         `json:"repo"`
+	Module     string         `json:"module"`
+	Files      []string       `json:"files"`
+	TopSymbols []ModuleSymbol `json:"top_symbols"`
+}
+
+// mergeModules combines graph module nodes with per-module chunk counts.
+// Modules known to only one source are still listed.
+func mergeModules(modules []graph.ModuleSummary, chunkCounts map[string]int) []ModuleListing {
+	byName := make(map[string]*ModuleListing)
+	for _, m := range modules {
+		byName[m.Path] = &ModuleListing{
+			Name:        m.Path,
+			Description: m.Description,
+			Files:       m.FileCount,
+		}
+	}
+	for name, count := range chunkCounts {
+		if l, ok := byName[name]; ok {
+			l.Chunks = count
+			continue
+		}
+		byName[name] = &ModuleListing{Name: name, Chunks: count}
+	}
+
+	listings := make([]ModuleListing, 0, len(byName))
+	for _, l := range byName {
+		listings = append(listings, *l)
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		return listings[i].Name < listings[j].Name
+	})
+
+	return listings
+}
+
+// summarizeModuleChunks derives a module's files and top symbols from its
+// chunks, ranking symbols by retrieval weight. Used without Neo4j.
+func summarizeModuleChunks(chunks []chunk.Chunk, limit int) ([]string, []ModuleSymbol) {
+	seenFiles := make(map[string]bool)
+	var files []string
+	var ranked []chunk.Chunk
+
+	for _, c := range chunks {
+		if c.FilePath != "" && !seenFiles[c.FilePath] {
+			seenFiles[c.FilePath] = true
+			files = append(files, c.FilePath)
+		}
+		if c.SymbolName != "" {
+			ranked = append(ranked, c)
+		}
+	}
+	sort.Strings(files)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].RetrievalWeight != ranked[j].RetrievalWeight {
+			return ranked[i].RetrievalWeight > ranked[j].RetrievalWeight
+		}
+		if ranked[i].FilePath != ranked[j].FilePath {
+			return ranked[i].FilePath < ranked[j].FilePath
+		}
+		return ranked[i].StartLine < ranked[j].StartLine
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	symbols := make([]ModuleSymbol, len(ranked))
+	for i, c := range ranked {
+		symbols[i] = ModuleSymbol{
+			Name:      c.SymbolName,
+			Kind:      c.Kind,
+			FilePath:  c.FilePath,
+			StartLine: c.StartLine,
+		}
+	}
+
+	return files, symbols
+}
+
+func (h *Handler) listModules(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	repo, _ := args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	if repo == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "repo parameter is required (could not infer from cwd)"}},
+			IsError: true,
+		}, nil
+	}
+
+	var chunkCounts map[string]int
+	if h.store != nil {
+		var err error
+		chunkCounts, err = h.store.CountByField(ctx, "chunks", "module_root", map[string]interface{}{"repo": repo})
+		if err != nil {
+			return nil, fmt.Errorf("count module chunks: %w", err)
+		}
+	}
+
+	var modules []graph.ModuleSummary
+	if h.graphStore != nil {
+		var err error
+		modules, err = h.graphStore.ListModules(ctx, repo)
+		if err != nil && h.logger != nil {
+			h.logger.Warn("failed to list modules from graph", "repo", repo, "error", err)
+		}
+		modules = filterModuleSummaries(ctx, repo, modules)
+	}
+
+	listings := mergeModules(modules, chunkCounts)
+	if len(listings) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("No modules indexed for %s.", repo)}},
+		}, nil
+	}
+
+	data, _ := json.MarshalIndent(map[string]interface{}{
+		"repo":    repo,
+		"modules": listings,
+	}, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+func (h *Handler) moduleContents(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	module, _ := args["module"].(string)
+	if module == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "module parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+
+	repo, _ := args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	if repo == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "repo parameter is required (could not infer from cwd)"}},
+			IsError: true,
+		}, nil
+	}
+
+	limit := 20
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	response := ModuleContentsResponse{Repo: repo, Module: module}
+
+	switch {
+	case h.graphStore != nil:
+		files, err := h.graphStore.ListModuleFiles(ctx, repo, module)
+		if err != nil {
+			return nil, fmt.Errorf("list module files: %w", err)
+		}
+		symbols, err := h.graphStore.TopModuleSymbols(ctx, repo, module, limit)
+		if err != nil {
+			return nil, fmt.Errorf("list module symbols: %w", err)
+		}
+		response.Files = files
+		for _, s := range symbols {
+			response.TopSymbols = append(response.TopSymbols, ModuleSymbol{
+				Name:      s.Name,
+				Kind:      s.Kind,
+				FilePath:  s.FilePath,
+				StartLine: s.StartLine,
+				Callers:   s.CallerCount,
+			})
+		}
+	case h.store != nil:
+		chunks, err := h.store.SearchByFilter(ctx, "chunks", map[string]interface{}{
+			"repo":        repo,
+			"module_root": module,
+		}, maxModuleChunks)
+		if err != nil {
+			return nil, fmt.Errorf("list module chunks: %w", err)
+		}
+		response.Files, response.TopSymbols = summarizeModuleChunks(chunks, limit)
+	default:
+		return nil, fmt.Errorf("no graph or vector store configured")
+	}
+
+	if len(response.Files) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("Module %q not found in %s. Use list_modules to see available modules.", module, repo)}},
+		}, nil
+	}
+
+	data, _ := json.MarshalIndent(response, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
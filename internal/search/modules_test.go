@@ -0,0 +1,53 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeModules(t *testing.T) {
+	modules := []graph.ModuleSummary{
+		{Module: graph.Module{Path: "fisio", Description: "Core app"}, FileCount: 12},
+		{Module: graph.Module{Path: "scripts"}, FileCount: 2},
+	}
+	counts := map[string]int{"fisio": 340, "tools": 5}
+
+	listings := mergeModules(modules, counts)
+
+	require.Len(t, listings, 3)
+	assert.Equal(t, ModuleListing{Name: "fisio", Description: "Core app", Files: 12, Chunks: 340}, listings[0])
+	assert.Equal(t, ModuleListing{Name: "scripts", Files: 2}, listings[1])
+	assert.Equal(t, ModuleListing{Name: "tools", Chunks: 5}, listings[2])
+}
+
+func TestSummarizeModuleChunks(t *testing.T) {
+	chunks := []chunk.Chunk{
+		{FilePath: "fisio/b.py", SymbolName: "helper", Kind: "function", StartLine: 5, RetrievalWeight: 0.5},
+		{FilePath: "fisio/a.py", SymbolName: "Importer", Kind: "class", StartLine: 1, RetrievalWeight: 1.0},
+		{FilePath: "fisio/a.py", Kind: "module", RetrievalWeight: 1.0},
+		{FilePath: "fisio/c.py", SymbolName: "run", Kind: "function", StartLine: 9, RetrievalWeight: 1.0},
+	}
+
+	files, symbols := summarizeModuleChunks(chunks, 2)
+
+	assert.Equal(t, []string{"fisio/a.py", "fisio/b.py", "fisio/c.py"}, files)
+	require.Len(t, symbols, 2)
+	assert.Equal(t, "Importer", symbols[0].Name)
+	assert.Equal(t, "run", symbols[1].Name)
+}
+
+func TestHandlerModuleContentsMissingModule(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "module_contents", map[string]interface{}{})
+
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "module parameter is required")
+}
@@ -0,0 +1,196 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// maxOwnerFiles bounds how many files a who_owns directory lookup inspects.
+const maxOwnerFiles = 500
+
+// Owner aggregates the files under a path last modified by one author.
+type Owner struct {
+	Name         string    `json:"name"`
+	Email        string    `json:"email,omitempty"`
+	Files        int       `json:"files"`
+	LastCommit   string    `json:"last_commit,omitempty"`
+	LastModified time.Time `json:"last_modified,omitzero"`
+}
+
+// OwnersResponse is the who_owns tool result.
+type OwnersResponse struct {
+	Repo   string  `json:"repo"`
+	Path   string  `json:"path"`
+	Files  int     `json:"files"`
+	Owners []Owner `json:"owners"`
+
+	// Only the maxOwnerFiles most recently modified files were counted
+	Truncated bool `json:"truncated,omitempty"`
+
+	// CODEOWNERS entries covering the files, most files first
+	CodeOwners []string `json:"code_owners,omitempty"`
+}
+
+// ownerFilterKey picks the chunk payload field an owner argument matches:
+// CODEOWNERS users and teams ("@acme/payments") filter on owners, anything
+// else that looks like an email on the last author's email, otherwise on
+// their name.
+func ownerFilterKey(owner string) string {
+	if strings.HasPrefix(owner, "@") {
+		return "owners"
+	}
+	if strings.Contains(owner, "@") {
+		return "last_author_email"
+	}
+	return "last_author"
+}
+
+// summarizeOwners groups files by last author, most files first.
+func summarizeOwners(files []graph.File) []Owner {
+	byAuthor := make(map[string]*Owner)
+	var order []string
+
+	for _, f := range files {
+		if f.LastAuthor == "" && f.LastAuthorEmail == "" {
+			continue
+		}
+		key := f.LastAuthorEmail
+		if key == "" {
+			key = f.LastAuthor
+		}
+		o, ok := byAuthor[key]
+		if !ok {
+			o = &Owner{Name: f.LastAuthor, Email: f.LastAuthorEmail}
+			byAuthor[key] = o
+			order = append(order, key)
+		}
+		o.Files++
+		if f.LastModified.After(o.LastModified) {
+			o.LastModified = f.LastModified
+			o.LastCommit = f.LastCommit
+		}
+	}
+
+	owners := make([]Owner, 0, len(order))
+	for _, key := range order {
+		owners = append(owners, *byAuthor[key])
+	}
+	sort.SliceStable(owners, func(i, j int) bool {
+		if owners[i].Files != owners[j].Files {
+			return owners[i].Files > owners[j].Files
+		}
+		return owners[i].LastModified.After(owners[j].LastModified)
+	})
+
+	return owners
+}
+
+// summarizeCodeOwners lists the CODEOWNERS owners of files, by how many
+// files each owns.
+func summarizeCodeOwners(files []graph.File) []string {
+	counts := make(map[string]int)
+	var owners []string
+	for _, f := range files {
+		for _, owner := range f.Owners {
+			if counts[owner] == 0 {
+				owners = append(owners, owner)
+			}
+			counts[owner]++
+		}
+	}
+	sort.SliceStable(owners, func(i, j int) bool {
+		return counts[owners[i]] > counts[owners[j]]
+	})
+	return owners
+}
+
+func (h *Handler) whoOwns(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	path, _ := args["path"].(string)
+	path = strings.TrimPrefix(path, "./")
+	if path == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "path parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+
+	repo, _ := args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	if repo == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "repo parameter is required (could not infer from cwd)"}},
+			IsError: true,
+		}, nil
+	}
+
+	files, err := h.findOwnedFiles(ctx, repo, path)
+	if err != nil {
+		return nil, fmt.Errorf("who_owns failed: %w", err)
+	}
+
+	response := OwnersResponse{
+		Repo:   repo,
+		Path:   path,
+		Files:  len(files),
+		Owners: summarizeOwners(files),
+
+		Truncated: len(files) >= maxOwnerFiles,
+
+		CodeOwners: summarizeCodeOwners(files),
+	}
+	if len(response.Owners) == 0 && len(response.CodeOwners) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("No ownership data for %q in %s. The path may not be indexed, or the repo is not a git checkout.", path, repo)}},
+		}, nil
+	}
+
+	data, _ := json.MarshalIndent(response, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+// findOwnedFiles returns files at or under path in modules the caller may
+// read. Neo4j supports directories, and filters modules before applying
+// maxOwnerFiles; without it, only an exact file match against chunk
+// payloads (which chunkStore scopes) works.
+func (h *Handler) findOwnedFiles(ctx context.Context, repo, path string) ([]graph.File, error) {
+	if h.graphStore != nil {
+		modules := mcp.TokenInfoFromContext(ctx).AllowedModules(repo)
+		return h.graphStore.FindFileOwners(ctx, repo, path, modules, maxOwnerFiles)
+	}
+
+	if h.store == nil {
+		return nil, fmt.Errorf("no graph or vector store configured")
+	}
+
+	chunks, err := h.store.SearchByFilter(ctx, "chunks", map[string]interface{}{
+		"repo":      repo,
+		"file_path": path,
+	}, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	c := chunks[0]
+	return []graph.File{{
+		Path:            c.FilePath,
+		Repo:            repo,
+		LastAuthor:      c.LastAuthor,
+		LastAuthorEmail: c.LastAuthorEmail,
+		LastCommit:      c.LastCommit,
+		Owners:          c.Owners,
+	}}, nil
+}
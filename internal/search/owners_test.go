@@ -0,0 +1,64 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnerFilterKey(t *testing.T) {
+	assert.Equal(t, "last_author_email", ownerFilterKey("alice@example.com"))
+	assert.Equal(t, "last_author", ownerFilterKey("Alice Smith"))
+	assert.Equal(t, "owners", ownerFilterKey("@acme/payments"))
+	assert.Equal(t, "owners", ownerFilterKey("@alice"))
+}
+
+func TestSummarizeCodeOwners(t *testing.T) {
+	files := []graph.File{
+		{Path: "billing/a.py", Owners: []string{"@acme/billing"}},
+		{Path: "shared/b.py", Owners: []string{"@acme/platform", "@acme/billing"}},
+		{Path: "shared/c.py", Owners: []string{"@acme/platform"}},
+		{Path: "shared/d.py", Owners: []string{"@acme/platform"}},
+		{Path: "unowned.py"},
+	}
+
+	assert.Equal(t, []string{"@acme/platform", "@acme/billing"}, summarizeCodeOwners(files))
+	assert.Nil(t, summarizeCodeOwners(files[4:]))
+}
+
+func TestSummarizeOwners(t *testing.T) {
+	older := time.Unix(1700000000, 0)
+	newer := time.Unix(1700000500, 0)
+
+	files := []graph.File{
+		{Path: "a.py", LastAuthor: "Bob", LastAuthorEmail: "bob@example.com", LastCommit: "b1", LastModified: older},
+		{Path: "b.py", LastAuthor: "Alice", LastAuthorEmail: "alice@example.com", LastCommit: "a1", LastModified: older},
+		{Path: "c.py", LastAuthor: "Alice", LastAuthorEmail: "alice@example.com", LastCommit: "a2", LastModified: newer},
+		{Path: "untracked.py"},
+	}
+
+	owners := summarizeOwners(files)
+
+	require.Len(t, owners, 2)
+	assert.Equal(t, "Alice", owners[0].Name)
+	assert.Equal(t, 2, owners[0].Files)
+	assert.Equal(t, "a2", owners[0].LastCommit)
+	assert.Equal(t, newer, owners[0].LastModified)
+	assert.Equal(t, "Bob", owners[1].Name)
+	assert.Equal(t, 1, owners[1].Files)
+}
+
+func TestHandlerWhoOwnsMissingPath(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "who_owns", map[string]interface{}{})
+
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "path parameter is required")
+}
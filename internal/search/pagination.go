@@ -55,6 +55,20 @@ type PaginatedResponse struct {
 	TotalCount int            `json:"total_count"`
 	HasMore    bool           `json:"has_more"`
 	Cursor     string         `json:"cursor,omitempty"`
+	IndexMeta  *IndexMeta     `json:"index_meta,omitempty"`
+
+	// Relationship is the graph answer to a relationship query, whose
+	// results are then the code of the symbols or files it lists.
+	Relationship *RelationshipAnswer `json:"relationship,omitempty"`
+	// Flow is the call chain assembled for a flow query, whose results are
+	// then the code of its hops in chain order.
+	Flow *FlowAnswer `json:"flow,omitempty"`
+	// SeenOmitted counts results left out because earlier searches in the
+	// session already returned them (see include_seen).
+	SeenOmitted int `json:"seen_omitted,omitempty"`
+	// Classification is the embedding classifier's decision, when
+	// search.classifier.mode is embedding.
+	Classification *Classification `json:"classification,omitempty"`
 }
 
 // Paginate applies pagination to results.
@@ -0,0 +1,34 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// parseQuery splits negated terms ("-legacy") off a search query and
+// returns the rest. A dash only negates when it starts a word and is
+// followed by a letter or underscore, so "non-blocking", "-1", and a lone
+// "-" stay part of the query.
+func parseQuery(query string) (text string, negated []string) {
+	var kept []string
+	for _, word := range strings.Fields(query) {
+		if term, ok := negatedTerm(word); ok {
+			negated = append(negated, term)
+			continue
+		}
+		kept = append(kept, word)
+	}
+	return strings.Join(kept, " "), negated
+}
+
+func negatedTerm(word string) (string, bool) {
+	term, ok := strings.CutPrefix(word, "-")
+	if !ok || term == "" {
+		return "", false
+	}
+	first := []rune(term)[0]
+	if first != '_' && !unicode.IsLetter(first) {
+		return "", false
+	}
+	return term, true
+}
@@ -0,0 +1,29 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		text    string
+		negated []string
+	}{
+		{"upload handler -legacy", "upload handler", []string{"legacy"}},
+		{"-deprecated retry  -_old backoff", "retry backoff", []string{"deprecated", "_old"}},
+		{"non-blocking io", "non-blocking io", nil},
+		{"offset -1 handling", "offset -1 handling", nil},
+		{"a - b", "a - b", nil},
+		{"-legacy", "", []string{"legacy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			text, negated := parseQuery(tt.query)
+			assert.Equal(t, tt.text, text)
+			assert.Equal(t, tt.negated, negated)
+		})
+	}
+}
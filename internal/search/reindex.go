@@ -0,0 +1,156 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// repoConfigFile marks the root of an indexed repository.
+const repoConfigFile = ".ai-devtools.yaml"
+
+// ReindexResult is the reindex_file tool result.
+type ReindexResult struct {
+	Repo       string `json:"repo"`
+	Path       string `json:"path"`
+	Chunks     int    `json:"chunks"`
+	Deleted    bool   `json:"deleted,omitempty"` // File no longer exists; its chunks were removed
+	Commit     string `json:"commit,omitempty"`
+	Generation int64  `json:"generation,omitempty"`
+}
+
+func (h *Handler) reindexFile(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	path, _ := args["path"].(string)
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "path parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+
+	repo, _ := args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+
+	repoRoot, relPath, err := resolveRepoFile(path, repo)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+
+	repoCfg, err := config.LoadRepoConfig(repoRoot)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("no %s in %s. Run 'code-indexer init' first.", repoConfigFile, repoRoot)}},
+			IsError: true,
+		}, nil
+	}
+	if !mcp.RepoAllowed(ctx, repoCfg.Name) {
+		return repoDenied(mcp.TokenInfoFromContext(ctx), repoCfg.Name), nil
+	}
+	if token := mcp.TokenInfoFromContext(ctx); token.AllowedModules(repoCfg.Name) != nil {
+		_, moduleRoot, _ := indexer.NewModuleResolver(repoRoot, repoCfg).Resolve(filepath.ToSlash(relPath))
+		if !token.AllowsModule(repoCfg.Name, moduleRoot) {
+			return moduleDenied(token, repoCfg.Name, moduleRoot), nil
+		}
+	}
+
+	if h.store == nil || h.embedder == nil {
+		return nil, fmt.Errorf("vector store not configured")
+	}
+
+	idx := indexer.NewIndexerWithClients(h.config, h.embedder, h.store.QdrantStore)
+	idx.SetMetrics(h.usageMetrics(ctx))
+	result, err := idx.IndexFile(ctx, repoRoot, repoCfg, relPath, h.graphStore)
+	if errors.Is(err, indexer.ErrExcluded) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("%s is excluded from indexing (exclude patterns, ignore files, size, binary or minified content, or symlink settings)", filepath.ToSlash(relPath))}},
+			IsError: true,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reindex %s: %w", relPath, err)
+	}
+
+	response := ReindexResult{
+		Repo:    repoCfg.Name,
+		Path:    filepath.ToSlash(relPath),
+		Chunks:  result.ChunksCreated,
+		Deleted: result.FilesProcessed == 0,
+		Commit:  result.Commit,
+	}
+
+	// Bumping the generation orphans every cached query for the repo
+	if h.cache != nil {
+		response.Generation, err = h.cache.IncrIndexVersion(ctx, repoCfg.Name)
+		if err != nil {
+			h.logger.Warn("failed to invalidate query cache", "repo", repoCfg.Name, "error", err)
+		}
+		staleKey := "stale:" + filepath.Join(repoRoot, relPath)
+		if err := h.cache.Delete(ctx, staleKey); err != nil {
+			h.logger.Warn("failed to clear stale marker", "key", staleKey, "error", err)
+		}
+	}
+
+	data, _ := json.MarshalIndent(response, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+// resolveRepoFile splits a tool path argument into the repository root and
+// the repo-relative file path. Absolute paths find their root by walking up
+// to the repo config file; relative paths are taken relative to
+// ~/repos/<repo>.
+func resolveRepoFile(path, repo string) (repoRoot, relPath string, err error) {
+	if filepath.IsAbs(path) {
+		repoRoot = findRepoRoot(filepath.Dir(path))
+		if repoRoot == "" {
+			return "", "", fmt.Errorf("%s is not inside an indexed repository (no %s found)", path, repoConfigFile)
+		}
+	} else {
+		if repo == "" || repo == "all" {
+			return "", "", fmt.Errorf("repo parameter is required for relative paths (could not infer from cwd)")
+		}
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", fmt.Errorf("resolve home directory: %w", err)
+		}
+		repoRoot = filepath.Join(homeDir, "repos", repo)
+		path = filepath.Join(repoRoot, path)
+	}
+
+	relPath, err = filepath.Rel(repoRoot, filepath.Clean(path))
+	if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", "", fmt.Errorf("%s is outside repository %s", path, repoRoot)
+	}
+
+	return repoRoot, relPath, nil
+}
+
+// findRepoRoot returns the nearest directory at or above dir containing the
+// repo config file, or "" if there is none.
+func findRepoRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, repoConfigFile)); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRepoRoot(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, repoConfigFile), []byte("code-index:\n  name: demo\n"), 0644))
+	nested := filepath.Join(root, "pkg", "sub")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	assert.Equal(t, root, findRepoRoot(nested))
+	assert.Equal(t, root, findRepoRoot(root))
+	assert.Equal(t, "", findRepoRoot(t.TempDir()))
+}
+
+func TestResolveRepoFile(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, repoConfigFile), []byte("code-index:\n  name: demo\n"), 0644))
+
+	repoRoot, relPath, err := resolveRepoFile(filepath.Join(root, "pkg", "auth.py"), "")
+	require.NoError(t, err)
+	assert.Equal(t, root, repoRoot)
+	assert.Equal(t, filepath.Join("pkg", "auth.py"), relPath)
+
+	_, _, err = resolveRepoFile(filepath.Join(t.TempDir(), "x.py"), "")
+	assert.Error(t, err, "absolute path outside any repo")
+
+	_, _, err = resolveRepoFile("pkg/auth.py", "")
+	assert.Error(t, err, "relative path needs a repo")
+
+	_, _, err = resolveRepoFile("../other/x.py", "demo")
+	assert.Error(t, err, "relative path escaping the repo")
+
+	homeDir, err := os.UserHomeDir()
+	require.NoError(t, err)
+	repoRoot, relPath, err = resolveRepoFile("pkg/auth.py", "demo")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(homeDir, "repos", "demo"), repoRoot)
+	assert.Equal(t, filepath.Join("pkg", "auth.py"), relPath)
+}
+
+func TestReindexFileRequiresPath(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "reindex_file", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "path parameter is required")
+}
@@ -0,0 +1,203 @@
+package search
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/parser"
+)
+
+// Directions of a relationship query.
+const (
+	RelationCallers      = "callers"
+	RelationCallees      = "callees"
+	RelationRelatedFiles = "related_files"
+)
+
+// relationshipQuery is what a relationship query asks: the callers or
+// callees of a symbol, or the files related to a file or module.
+type relationshipQuery struct {
+	direction string
+	target    string
+}
+
+// relTarget captures the symbol or path a relationship query is about,
+// skipping an article and quotes.
+const relTarget = `(?:(?:the|a|an)\s+)?[` + "`" + `"']?([A-Za-z_$][\w$./:-]*)`
+
+// relationshipPatterns are the recognized phrasings, in match order.
+var relationshipPatterns = []struct {
+	direction string
+	re        *regexp.Regexp
+}{
+	{RelationCallees, regexp.MustCompile(`(?i)\bwhat\s+(?:does|do)\s+` + relTarget + `\S*\s+(?:call|invoke|use)\b`)},
+	{RelationCallees, regexp.MustCompile(`(?i)\bcallees\s+of\s+` + relTarget)},
+	{RelationCallees, regexp.MustCompile(`(?i)\b(?:is|are|functions|methods)\s+called\s+(?:by|from)\s+` + relTarget)},
+	{RelationRelatedFiles, regexp.MustCompile(`(?i)\bwhat\s+does\s+` + relTarget + `\S*\s+(?:import|depend\s+on)\b`)},
+	{RelationRelatedFiles, regexp.MustCompile(`(?i)\b(?:what|who|which\s+files?)\s+(?:imports|depends\s+on)\s+` + relTarget)},
+	{RelationRelatedFiles, regexp.MustCompile(`(?i)\b(?:importers|dependents|dependencies)\s+of\s+` + relTarget)},
+	{RelationRelatedFiles, regexp.MustCompile(`(?i)\bfiles\s+related\s+to\s+` + relTarget)},
+	{RelationCallers, regexp.MustCompile(`(?i)\b(?:what|who|which\s+\w+)\s+(?:calls|invokes|uses|references)\s+` + relTarget)},
+	{RelationCallers, regexp.MustCompile(`(?i)\b(?:functions|methods|classes|code)\s+(?:that|which)\s+(?:calls?|invokes?|uses?|references?)\s+` + relTarget)},
+	{RelationCallers, regexp.MustCompile(`(?i)\b(?:callers|usages|references|invocations)\s+(?:of|to)\s+` + relTarget)},
+	{RelationCallers, regexp.MustCompile(`(?i)\bwhere\s+(?:is|are)\s+` + relTarget + `\S*\s+(?:called|used|invoked|referenced)\b`)},
+}
+
+// parseRelationship reads the direction and target of a relationship query
+// ("what calls validateToken", "what does login call", "what imports
+// auth/tokens.py"). Call questions about a file become related-file
+// questions, and "Class.method" targets the method.
+func parseRelationship(query string) (relationshipQuery, bool) {
+	for _, p := range relationshipPatterns {
+		m := p.re.FindStringSubmatch(query)
+		if m == nil {
+			continue
+		}
+		target := strings.TrimRight(m[1], ".?:")
+		if target == "" {
+			continue
+		}
+		rel := relationshipQuery{direction: p.direction, target: target}
+		if isFilePath(target) {
+			rel.direction = RelationRelatedFiles
+		} else if rel.direction != RelationRelatedFiles {
+			rel.target = target[strings.LastIndexAny(target, ".:")+1:]
+		}
+		return rel, true
+	}
+	return relationshipQuery{}, false
+}
+
+// isFilePath reports whether a relationship target names a source file.
+func isFilePath(target string) bool {
+	_, ok := parser.DetectLanguage(target)
+	return ok || strings.Contains(target, "/")
+}
+
+// RelationshipAnswer is the graph's answer to a relationship query; the
+// response's results hold the code of what it lists.
+type RelationshipAnswer struct {
+	Direction string      `json:"direction"` // callers, callees, or related_files
+	Target    string      `json:"target"`
+	Repo      string      `json:"repo"`
+	Symbols   []SymbolRef `json:"symbols,omitempty"` // Callers or callees
+	Files     []string    `json:"files,omitempty"`   // Related files
+}
+
+// answerRelationship answers rel from the graph, returning up to limit
+// chunks for the listed symbols or files that pass filter. The answer is
+// nil when the graph has none (no graph, no repo, unknown target), so the
+// query is searched as usual.
+func (h *Handler) answerRelationship(ctx context.Context, rel relationshipQuery, repo string, filter map[string]interface{}, limit int) ([]chunk.Chunk, *RelationshipAnswer) {
+	var (
+		refs  []SymbolRef
+		files []graph.File
+		err   error
+	)
+	switch rel.direction {
+	case RelationCallers:
+		repo, refs, err = h.callGraph(ctx, repo, rel.target, (*graph.Neo4jStore).FindCallers)
+	case RelationCallees:
+		repo, refs, err = h.callGraph(ctx, repo, rel.target, (*graph.Neo4jStore).FindCallees)
+	default:
+		repo, files, err = h.relatedFiles(ctx, repo, rel.target, limit)
+	}
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Debug("relationship query fell back to search", "target", rel.target, "direction", rel.direction, "error", err)
+		}
+		return nil, nil
+	}
+	if len(refs) == 0 && len(files) == 0 {
+		return nil, nil
+	}
+
+	answer := &RelationshipAnswer{Direction: rel.direction, Target: rel.target, Repo: repo}
+	var results []chunk.Chunk
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if len(results) >= limit {
+			break
+		}
+		c, ok := h.lookupChunk(ctx, filter, repo, ref.FilePath, ref.Name)
+		if !ok || seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+		results = append(results, c)
+		answer.Symbols = append(answer.Symbols, ref)
+	}
+	for _, f := range files {
+		if len(results) >= limit {
+			break
+		}
+		c, ok := h.lookupChunk(ctx, filter, repo, f.Path, "")
+		if !ok || seen[c.ID] {
+			continue
+		}
+		seen[c.ID] = true
+		results = append(results, c)
+		answer.Files = append(answer.Files, f.Path)
+	}
+	return results, answer
+}
+
+// relatedFiles returns the files importing, imported by, calling into, or
+// called from target, which is a file path or else resolved to the file of
+// the module or symbol it names.
+func (h *Handler) relatedFiles(ctx context.Context, repo, target string, limit int) (string, []graph.File, error) {
+	repo, err := h.graphRepo(ctx, repo)
+	if err != nil {
+		return "", nil, err
+	}
+	path := strings.TrimPrefix(target, "./")
+	if !isFilePath(target) {
+		path = ""
+		for _, field := range []string{"module_path", "symbol_name"} {
+			chunks, err := h.store.SearchByFilter(ctx, "chunks", map[string]interface{}{"repo": repo, field: target}, 1)
+			if err == nil && len(chunks) > 0 {
+				path = chunks[0].FilePath
+				break
+			}
+		}
+		if path == "" {
+			return repo, nil, nil
+		}
+	}
+	done := timeStage(ctx, stageGraph)
+	files, err := h.graphStore.FindRelatedFiles(ctx, repo, path, limit)
+	done()
+	return repo, files, err
+}
+
+// lookupChunk finds the chunk of symbol in path (anywhere when the graph
+// lacks the path), or the file's summary chunk (else any of its chunks)
+// without a symbol, within filter.
+func (h *Handler) lookupChunk(ctx context.Context, filter map[string]interface{}, repo, path, symbol string) (chunk.Chunk, bool) {
+	lookup := make(map[string]interface{}, len(filter)+3)
+	for k, v := range filter {
+		lookup[k] = v
+	}
+	lookup["repo"] = repo
+	if path != "" {
+		lookup["file_path"] = path
+	}
+	n := 20
+	if symbol != "" {
+		lookup["symbol_name"] = symbol
+		n = 1
+	}
+	chunks, err := h.store.SearchByFilter(ctx, "chunks", lookup, n)
+	if err != nil || len(chunks) == 0 {
+		return chunk.Chunk{}, false
+	}
+	for _, c := range chunks {
+		if c.Kind == chunk.KindFileSummary {
+			return c, true
+		}
+	}
+	return chunks[0], true
+}
@@ -0,0 +1,42 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRelationship(t *testing.T) {
+	tests := []struct {
+		query     string
+		direction string
+		target    string
+	}{
+		{`what calls validateToken`, RelationCallers, "validateToken"},
+		{`who calls "validateToken"?`, RelationCallers, "validateToken"},
+		{`which function uses the parse_config`, RelationCallers, "parse_config"},
+		{`callers of AuthService.validate`, RelationCallers, "validate"},
+		{`where is process_payment() used`, RelationCallers, "process_payment"},
+		{`functions that use redis`, RelationCallers, "redis"},
+		{`what does login call`, RelationCallees, "login"},
+		{"what does `handleRequest` invoke", RelationCallees, "handleRequest"},
+		{`functions called by main`, RelationCallees, "main"},
+		{`what imports auth/tokens.py`, RelationRelatedFiles, "auth/tokens.py"},
+		{`who imports auth module`, RelationRelatedFiles, "auth"},
+		{`what does app.py import`, RelationRelatedFiles, "app.py"},
+		{`what calls utils.py`, RelationRelatedFiles, "utils.py"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rel, ok := parseRelationship(tt.query)
+			assert.True(t, ok)
+			assert.Equal(t, tt.direction, rel.direction)
+			assert.Equal(t, tt.target, rel.target)
+		})
+	}
+
+	for _, query := range []string{`retry logic`, `where is process_payment`, `how does login work`} {
+		_, ok := parseRelationship(query)
+		assert.False(t, ok, query)
+	}
+}
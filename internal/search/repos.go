@@ -0,0 +1,203 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// RepoListing is one entry of the list_repos tool result.
+type RepoListing struct {
+	Name          string          `json:"name"`
+	Path          string          `json:"path,omitempty"`
+	Chunks        int             `json:"chunks"`
+	IndexedCommit string          `json:"indexed_commit,omitempty"`
+	IndexedBranch string          `json:"indexed_branch,omitempty"`
+	IndexedAt     time.Time       `json:"indexed_at,omitzero"`
+	Modules       []ModuleListing `json:"modules,omitempty"`
+}
+
+// GraphStatus describes the graph backend in index_status.
+type GraphStatus struct {
+	Available bool   `json:"available"`
+	Server    string `json:"server,omitempty"`
+	Flavor    string `json:"flavor,omitempty"`
+	APOC      bool   `json:"apoc"`
+}
+
+// IndexStatus is the index_status tool result.
+type IndexStatus struct {
+	Collection     string       `json:"collection"`
+	Points         int64        `json:"points"`
+	VectorSize     int          `json:"vector_size"`
+	Status         string       `json:"status"`
+	EmbeddingModel string       `json:"embedding_model"`
+	Graph          GraphStatus  `json:"graph"`
+	CacheAvailable bool         `json:"cache_available"`
+	Repo           *RepoListing `json:"repo,omitempty"`
+	Generation     int64        `json:"generation,omitempty"`
+}
+
+// MergeRepos combines graph repository nodes with per-repo chunk counts.
+// Repos with chunks but no graph node (indexed without Neo4j) are included.
+func MergeRepos(repos []graph.Repository, chunkCounts map[string]int) []RepoListing {
+	byName := make(map[string]*RepoListing)
+	for _, r := range repos {
+		byName[r.Name] = &RepoListing{
+			Name:          r.Name,
+			Path:          r.Path,
+			IndexedCommit: r.IndexedCommit,
+			IndexedBranch: r.IndexedBranch,
+			IndexedAt:     r.IndexedAt,
+		}
+	}
+	for name, count := range chunkCounts {
+		if l, ok := byName[name]; ok {
+			l.Chunks = count
+			continue
+		}
+		byName[name] = &RepoListing{Name: name, Chunks: count}
+	}
+
+	listings := make([]RepoListing, 0, len(byName))
+	for _, l := range byName {
+		listings = append(listings, *l)
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		return listings[i].Name < listings[j].Name
+	})
+
+	return listings
+}
+
+func (h *Handler) listRepos(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	if h.store == nil {
+		return nil, fmt.Errorf("vector store not configured")
+	}
+
+	chunkCounts, err := h.store.CountByField(ctx, "chunks", "repo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("count repo chunks: %w", err)
+	}
+
+	var repos []graph.Repository
+	if h.graphStore != nil {
+		repos, err = h.graphStore.ListRepositories(ctx)
+		if err != nil && h.logger != nil {
+			h.logger.Warn("failed to list repositories from graph", "error", err)
+		}
+	}
+
+	listings := MergeRepos(repos, chunkCounts)
+	visible := listings[:0]
+	for _, l := range listings {
+		if mcp.RepoAllowed(ctx, l.Name) {
+			visible = append(visible, l)
+		}
+	}
+	listings = visible
+	if len(listings) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "No repositories indexed. Run 'code-indexer index <repo>' first."}},
+		}, nil
+	}
+
+	for i := range listings {
+		listings[i].Modules = h.repoModules(ctx, listings[i].Name)
+	}
+
+	data, _ := json.MarshalIndent(map[string]interface{}{
+		"repos": listings,
+	}, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+// repoModules returns a repo's module listing, logging rather than failing
+// so one bad repo does not hide the others.
+func (h *Handler) repoModules(ctx context.Context, repo string) []ModuleListing {
+	counts, err := h.store.CountByField(ctx, "chunks", "module_root", map[string]interface{}{"repo": repo})
+	if err != nil && h.logger != nil {
+		h.logger.Warn("failed to count module chunks", "repo", repo, "error", err)
+	}
+
+	var modules []graph.ModuleSummary
+	if h.graphStore != nil {
+		modules, err = h.graphStore.ListModules(ctx, repo)
+		if err != nil && h.logger != nil {
+			h.logger.Warn("failed to list modules from graph", "repo", repo, "error", err)
+		}
+		modules = filterModuleSummaries(ctx, repo, modules)
+	}
+
+	return mergeModules(modules, counts)
+}
+
+func (h *Handler) indexStatus(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	if h.store == nil {
+		return nil, fmt.Errorf("vector store not configured")
+	}
+
+	info, err := h.store.CollectionInfo(ctx, "chunks")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "No index found. Run 'code-indexer index <repo>' to create one."}},
+		}, nil
+	}
+
+	status := IndexStatus{
+		Collection:     "chunks",
+		Points:         info.PointsCount,
+		VectorSize:     info.VectorSize,
+		Status:         info.Status,
+		CacheAvailable: h.cache != nil,
+	}
+	if h.config != nil {
+		status.EmbeddingModel = h.config.Embedding.Model
+	}
+	if h.graphStore != nil {
+		caps := h.graphStore.Capabilities()
+		status.Graph = GraphStatus{
+			Available: true,
+			Server:    caps.Agent,
+			Flavor:    string(caps.Flavor),
+			APOC:      caps.APOC,
+		}
+	}
+
+	repo, _ := args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	if repo != "" && repo != "all" {
+		counts, err := h.store.CountByField(ctx, "chunks", "repo", map[string]interface{}{"repo": repo})
+		if err != nil {
+			return nil, fmt.Errorf("count repo chunks: %w", err)
+		}
+		listing := RepoListing{Name: repo, Chunks: counts[repo]}
+		if h.graphStore != nil {
+			if r, err := h.graphStore.GetRepository(ctx, repo); err == nil && r != nil {
+				listing.Path = r.Path
+				listing.IndexedCommit = r.IndexedCommit
+				listing.IndexedBranch = r.IndexedBranch
+				listing.IndexedAt = r.IndexedAt
+			}
+		}
+		status.Repo = &listing
+
+		if h.cache != nil {
+			status.Generation, _ = h.cache.GetIndexVersion(ctx, repo)
+		}
+	}
+
+	data, _ := json.MarshalIndent(status, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
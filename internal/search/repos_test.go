@@ -0,0 +1,30 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeRepos(t *testing.T) {
+	indexedAt := time.Unix(1700000000, 0)
+	repos := []graph.Repository{
+		{Name: "r3", Path: "/home/u/repos/r3", IndexedCommit: "abc", IndexedAt: indexedAt},
+		{Name: "empty", Path: "/home/u/repos/empty"},
+	}
+	counts := map[string]int{"r3": 1200, "m32rimm": 800}
+
+	listings := MergeRepos(repos, counts)
+
+	require.Len(t, listings, 3)
+	assert.Equal(t, "empty", listings[0].Name)
+	assert.Equal(t, 0, listings[0].Chunks)
+	assert.Equal(t, RepoListing{Name: "m32rimm", Chunks: 800}, listings[1])
+	assert.Equal(t, "r3", listings[2].Name)
+	assert.Equal(t, 1200, listings[2].Chunks)
+	assert.Equal(t, "abc", listings[2].IndexedCommit)
+	assert.Equal(t, indexedAt, listings[2].IndexedAt)
+}
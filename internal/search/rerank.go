@@ -0,0 +1,94 @@
+package search
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+)
+
+// Reranker scores documents against a query, typically with a cross-encoder
+// that reads both together (rerank.Client, when search.rerank is enabled).
+// Scores are returned in document order; higher is more relevant.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+}
+
+// maxRerankDocumentChars bounds the code sent per candidate; the start of a
+// chunk says what it is.
+const maxRerankDocumentChars = 4000
+
+// SetReranker reorders the top search.rerank.top_n candidates of the
+// search.rerank.query_types query types with r before pagination. A nil r
+// turns reranking off.
+func (h *Handler) SetReranker(r Reranker) {
+	h.reranker = r
+}
+
+// wantsRerank reports whether queries of type qt are reranked.
+func (h *Handler) wantsRerank(qt QueryType) bool {
+	if h.reranker == nil || h.config == nil {
+		return false
+	}
+	return slices.Contains(h.config.Search.Rerank.QueryTypes, string(qt))
+}
+
+// rerank reorders the top candidates by reranker score, which becomes their
+// Score; the rest keep their order below them. On failure the results stay
+// as retrieved.
+func (h *Handler) rerank(ctx context.Context, query string, results []chunk.Chunk) []chunk.Chunk {
+	n := h.config.Search.Rerank.TopN
+	if n <= 0 {
+		n = config.DefaultConfig().Search.Rerank.TopN
+	}
+	n = min(n, len(results))
+	if n < 2 {
+		return results
+	}
+
+	docs := make([]string, n)
+	for i, c := range results[:n] {
+		docs[i] = rerankDocument(c)
+	}
+	scores, err := h.reranker.Rerank(ctx, query, docs)
+	if err != nil || len(scores) != n {
+		if h.logger != nil {
+			h.logger.Warn("rerank failed, keeping retrieval order", "query", query, "error", err)
+		}
+		return results
+	}
+
+	top := results[:n]
+	for i := range top {
+		top[i].Score = float32(scores[i])
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].Score > top[j].Score
+	})
+	return results
+}
+
+// rerankDocument is the text the reranker reads for c: where it lives, its
+// signature and docstring, then its code.
+func rerankDocument(c chunk.Chunk) string {
+	var b strings.Builder
+	b.WriteString(c.FilePath)
+	if c.SymbolName != "" {
+		b.WriteString(" " + c.Kind + " " + c.SymbolName)
+	}
+	b.WriteByte('\n')
+	for _, text := range []string{c.ContextHeader, c.Docstring, c.Summary} {
+		if text != "" {
+			b.WriteString(text + "\n")
+		}
+	}
+	content := c.Content
+	if len(content) > maxRerankDocumentChars {
+		content = content[:maxRerankDocumentChars]
+	}
+	b.WriteString(content)
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeReranker struct {
+	docs []string
+	err  error
+}
+
+// Rerank scores documents by how often they mention "retry".
+func (f *fakeReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	f.docs = documents
+	if f.err != nil {
+		return nil, f.err
+	}
+	scores := make([]float64, len(documents))
+	for i, doc := range documents {
+		scores[i] = float64(strings.Count(doc, "retry"))
+	}
+	return scores, nil
+}
+
+func TestRerankReordersTopCandidates(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Search.Rerank.TopN = 3
+	reranker := &fakeReranker{}
+	handler := &Handler{config: cfg}
+	handler.SetReranker(reranker)
+
+	results := []chunk.Chunk{
+		{ID: "a", FilePath: "a.py", Content: "parse()"},
+		{ID: "b", FilePath: "b.py", Content: "retry()"},
+		{ID: "c", FilePath: "c.py", Content: "retry(); retry()"},
+		{ID: "d", FilePath: "d.py", Content: "retry(); retry(); retry()"},
+	}
+	results = handler.rerank(context.Background(), "retry logic", results)
+
+	ids := make([]string, len(results))
+	for i, c := range results {
+		ids[i] = c.ID
+	}
+	assert.Equal(t, []string{"c", "b", "a", "d"}, ids, "only the top 3 are reranked")
+	assert.Equal(t, float32(2), results[0].Score)
+	require.Len(t, reranker.docs, 3)
+}
+
+func TestRerankFailureKeepsOrder(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	handler.SetReranker(&fakeReranker{err: errors.New("timeout")})
+
+	results := []chunk.Chunk{{ID: "a", Score: 0.9}, {ID: "b", Content: "retry", Score: 0.5}}
+	results = handler.rerank(context.Background(), "q", results)
+	assert.Equal(t, "a", results[0].ID)
+	assert.Equal(t, float32(0.9), results[0].Score)
+}
+
+func TestWantsRerank(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	assert.False(t, handler.wantsRerank(QueryTypeConcept), "no reranker configured")
+
+	handler.SetReranker(&fakeReranker{})
+	assert.True(t, handler.wantsRerank(QueryTypeConcept))
+	assert.False(t, handler.wantsRerank(QueryTypeSymbol), "symbol lookups are not reranked by default")
+
+	handler.config.Search.Rerank.QueryTypes = []string{"symbol"}
+	assert.True(t, handler.wantsRerank(QueryTypeSymbol))
+}
+
+func TestRerankDocument(t *testing.T) {
+	doc := rerankDocument(chunk.Chunk{
+		FilePath:   "upload.py",
+		Kind:       "function",
+		SymbolName: "upload",
+		Docstring:  "Upload with retries.",
+		Content:    strings.Repeat("x", maxRerankDocumentChars+100),
+	})
+	assert.True(t, strings.HasPrefix(doc, "upload.py function upload\nUpload with retries.\n"))
+	assert.Len(t, doc, len("upload.py function upload\nUpload with retries.\n")+maxRerankDocumentChars)
+}
@@ -0,0 +1,150 @@
+package search
+
+import (
+	"encoding/json"
+
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// searchCodeOutputSchema is the JSON schema of search_code results, covering
+// both PaginatedResponse and the empty-result response with suggestions.
+func searchCodeOutputSchema() map[string]interface{} {
+	str := map[string]interface{}{"type": "string"}
+	integer := map[string]interface{}{"type": "integer"}
+	boolean := map[string]interface{}{"type": "boolean"}
+	strList := map[string]interface{}{"type": "array", "items": str}
+
+	highlight := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"line": integer,
+			"text": str,
+			"spans": map[string]interface{}{"type": "array", "items": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"start": integer, "end": integer},
+			}},
+		},
+	}
+
+	result := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":          str,
+			"repo":        str,
+			"file_path":   str,
+			"module":      str,
+			"symbol_name": str,
+			"kind":        str,
+			"start_line":  integer,
+			"end_line":    integer,
+			"content":     str,
+			"docstring":   str,
+			"summary":     str,
+			"is_test":     boolean,
+			"owner":       str,
+			"owners":      strList,
+			"license":     str,
+			"branch":      str,
+			"dirty":       boolean,
+			"also_at":     strList,
+			"metadata":    map[string]interface{}{"type": "object", "additionalProperties": str},
+			"score":       map[string]interface{}{"type": "number"},
+			"matches":     integer,
+			"symbols":     strList,
+			"ranges":      strList,
+			"highlights":  map[string]interface{}{"type": "array", "items": highlight},
+		},
+		"required": []string{"file_path", "start_line", "end_line", "content"},
+	}
+
+	indexMeta := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"generation":      integer,
+			"commit":          str,
+			"indexed_at":      map[string]interface{}{"type": "string", "format": "date-time"},
+			"embedding_model": str,
+			"graph_available": boolean,
+			"cache_hit":       boolean,
+			"cached_query":    str,
+		},
+	}
+
+	symbolRef := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name":       str,
+			"kind":       str,
+			"file_path":  str,
+			"start_line": integer,
+			"end_line":   integer,
+			"signature":  str,
+		},
+	}
+	relationship := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"direction": map[string]interface{}{"type": "string", "enum": []string{RelationCallers, RelationCallees, RelationRelatedFiles}},
+			"target":    str,
+			"repo":      str,
+			"symbols":   map[string]interface{}{"type": "array", "items": symbolRef},
+			"files":     strList,
+		},
+	}
+
+	flow := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"from": str,
+			"to":   str,
+			"repo": str,
+			"hops": map[string]interface{}{"type": "array", "items": symbolRef},
+		},
+	}
+
+	classification := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"method":     str,
+			"predicted":  str,
+			"confidence": map[string]interface{}{"type": "number"},
+			"fallback":   boolean,
+		},
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query_type":     str,
+			"results":        map[string]interface{}{"type": "array", "items": result},
+			"total_count":    integer,
+			"has_more":       boolean,
+			"cursor":         str,
+			"index_meta":     indexMeta,
+			"seen_omitted":   integer,
+			"relationship":   relationship,
+			"flow":           flow,
+			"classification": classification,
+			"message":        str,
+			"suggestions":    map[string]interface{}{"type": "array", "items": str},
+			"did_you_mean":   map[string]interface{}{"type": "array", "items": str},
+			"hint":           str,
+		},
+		"required": []string{"query_type", "results"},
+	}
+}
+
+// structuredResult returns a tool result carrying a JSON document both as
+// text, for clients on older protocol revisions, and as structured content.
+// Text that is not a JSON object is returned as text only.
+func structuredResult(text string) *mcp.CallToolResult {
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: text}},
+	}
+
+	var structured map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &structured); err == nil {
+		result.StructuredContent = structured
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertMatchesSchema checks that every key of doc is declared by schema,
+// recursing into objects and arrays of objects.
+func assertMatchesSchema(t *testing.T, schema map[string]interface{}, doc map[string]interface{}, path string) {
+	t.Helper()
+	props, _ := schema["properties"].(map[string]interface{})
+	if _, ok := schema["additionalProperties"]; ok && props == nil {
+		return // A free-form map
+	}
+	for key, value := range doc {
+		propSchema, ok := props[key].(map[string]interface{})
+		if !assert.True(t, ok, "%s.%s not declared in output schema", path, key) {
+			continue
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			assertMatchesSchema(t, propSchema, v, path+"."+key)
+		case []interface{}:
+			items, _ := propSchema["items"].(map[string]interface{})
+			for _, item := range v {
+				if obj, ok := item.(map[string]interface{}); ok {
+					assertMatchesSchema(t, items, obj, path+"."+key+"[]")
+				}
+			}
+		}
+	}
+	required, _ := schema["required"].([]string)
+	for _, key := range required {
+		assert.Contains(t, doc, key, "%s missing required %s", path, key)
+	}
+}
+
+func TestSearchCodeOutputSchemaCoversResponses(t *testing.T) {
+	schema := searchCodeOutputSchema()
+
+	paginated := PaginatedResponse{
+		QueryType: "concept",
+		Results: []SearchResult{{
+			ID: "7f3c", Repo: "r3", FilePath: "a.py", Module: "a", SymbolName: "f", Kind: "function",
+			StartLine: 1, EndLine: 2, Content: "def f(): pass", Docstring: "doc", IsTest: true, Owner: "Alice",
+			Summary: "Does f.", Owners: []string{"@acme/core"}, License: "MIT", Branch: "main", Dirty: true, AlsoAt: []string{"b.py:1-2"}, Metadata: map[string]string{"service": "core"},
+			Score: 0.9, Matches: 2, Symbols: []string{"f", "g"}, Ranges: []string{"1-2", "8-9"},
+			Highlights: []Highlight{{Line: 1, Text: "def f(): pass", Spans: []TermSpan{{Start: 4, End: 5}}}},
+		}},
+		TotalCount: 1,
+		HasMore:    true,
+		Cursor:     "abc",
+		IndexMeta:  &IndexMeta{Generation: 3, Commit: "deadbeef", IndexedAt: time.Now(), EmbeddingModel: "voyage-code-3", GraphAvailable: true, CacheHit: true},
+		Relationship: &RelationshipAnswer{
+			Direction: RelationCallers, Target: "f", Repo: "r3",
+			Symbols: []SymbolRef{{Name: "g", Kind: "function", FilePath: "a.py", StartLine: 8, EndLine: 9, Signature: "def g()"}},
+			Files:   []string{"b.py"},
+		},
+		SeenOmitted:    2,
+		Classification: &Classification{Method: "embedding", Predicted: "relationship", Confidence: 0.41, Fallback: true},
+		Flow: &FlowAnswer{
+			From: "request", To: "DB", Repo: "r3",
+			Hops: []SymbolRef{{Name: "handle", FilePath: "api.py"}, {Name: "save", FilePath: "db.py"}},
+		},
+	}
+	data, err := json.Marshal(paginated)
+	require.NoError(t, err)
+	result := structuredResult(string(data))
+	require.NotNil(t, result.StructuredContent)
+	assertMatchesSchema(t, schema, result.StructuredContent.(map[string]interface{}), "$")
+
+	handler := &Handler{suggestionGen: NewSuggestionGenerator()}
+	empty, _ := handler.formatEmptyResponse(context.Background(), "nothing", "r3", &IndexMeta{Generation: 1})
+	result = structuredResult(empty)
+	require.NotNil(t, result.StructuredContent)
+	assertMatchesSchema(t, schema, result.StructuredContent.(map[string]interface{}), "$")
+}
+
+func TestStructuredResult(t *testing.T) {
+	result := structuredResult(`{"results": [], "query_type": "symbol"}`)
+	assert.Equal(t, `{"results": [], "query_type": "symbol"}`, result.Content[0].Text)
+	assert.Equal(t, map[string]interface{}{"results": []interface{}{}, "query_type": "symbol"}, result.StructuredContent)
+
+	result = structuredResult("No results.")
+	assert.Equal(t, "No results.", result.Content[0].Text)
+	assert.Nil(t, result.StructuredContent)
+}
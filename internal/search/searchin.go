@@ -0,0 +1,93 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+)
+
+// Values of search_code's search_in argument.
+const (
+	SearchInAll        = "all"        // Whole chunks, as indexed (default)
+	SearchInCode       = "code"       // Code chunks only, no docs or patterns
+	SearchInDocstrings = "docstrings" // Symbols' docstrings
+	SearchInSignatures = "signatures" // Symbols' signatures
+)
+
+var searchInFields = []string{SearchInAll, SearchInCode, SearchInDocstrings, SearchInSignatures}
+
+// fieldCandidateFactor is how many semantic candidates per wanted result
+// are re-scored on a single field.
+const fieldCandidateFactor = 5
+
+// minFieldCandidates is the fewest candidates re-scored on a field.
+const minFieldCandidates = 100
+
+// chunkField returns the docstring or signature of c.
+func chunkField(c chunk.Chunk, field string) string {
+	if field == SearchInSignatures {
+		return c.Signature
+	}
+	return c.Docstring
+}
+
+// searchField searches one field of symbols: semantic candidates having
+// the field are re-scored by the similarity of the query to the field's
+// own embedding, computed at query time, so body text no longer counts.
+func (h *Handler) searchField(ctx context.Context, query string, filter map[string]interface{}, limit int, field string) ([]chunk.Chunk, error) {
+	candidates, err := h.searchSemanticWith(ctx, query, filter, max(limit*fieldCandidateFactor, minFieldCandidates), false)
+	if err != nil {
+		return nil, err
+	}
+	vector := queryVectorFromContext(ctx, query)
+	if vector == nil {
+		done := timeStage(ctx, stageEmbed)
+		vectors, err := h.embedder.Embed(ctx, []string{query})
+		done()
+		if err != nil {
+			return nil, fmt.Errorf("embedding failed: %w", err)
+		}
+		vector = vectors[0]
+	}
+	results, err := scoreField(ctx, h.embedder, vector, candidates, field)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// scoreField keeps the chunks with a non-empty field, scored by the cosine
+// similarity of its embedding to the query vector times retrieval weight,
+// best first.
+func scoreField(ctx context.Context, embedder Embedder, vector []float32, candidates []chunk.Chunk, field string) ([]chunk.Chunk, error) {
+	var kept []chunk.Chunk
+	var texts []string
+	for _, c := range candidates {
+		if text := chunkField(c, field); text != "" {
+			kept = append(kept, c)
+			texts = append(texts, text)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, nil
+	}
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embed %s: %w", field, err)
+	}
+	if len(vectors) != len(kept) {
+		return nil, fmt.Errorf("embed %s: got %d vectors for %d texts", field, len(vectors), len(kept))
+	}
+	for i := range kept {
+		kept[i].Score = float32(cosine(vector, vectors[i]))
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].Score*kept[i].RetrievalWeight > kept[j].Score*kept[j].RetrievalWeight
+	})
+	return kept, nil
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreFieldRanksByDocstring(t *testing.T) {
+	embedder := &fakeEmbedder{queries: map[string][]float32{
+		"Upload a file to the bucket.": {1, 0},
+		"Parse the config file.":       {0, 1},
+		"Retry an upload.":             {0.8, 0.6},
+	}}
+	candidates := []chunk.Chunk{
+		{ID: "parse", Docstring: "Parse the config file.", RetrievalWeight: 1},
+		{ID: "nodoc", Content: "upload upload upload", RetrievalWeight: 1},
+		{ID: "retry", Docstring: "Retry an upload.", RetrievalWeight: 1},
+		{ID: "upload", Docstring: "Upload a file to the bucket.", RetrievalWeight: 1},
+	}
+
+	results, err := scoreField(context.Background(), embedder, []float32{1, 0}, candidates, SearchInDocstrings)
+	require.NoError(t, err)
+	ids := make([]string, len(results))
+	for i, c := range results {
+		ids[i] = c.ID
+	}
+	assert.Equal(t, []string{"upload", "retry", "parse"}, ids, "chunks without a docstring are left out")
+	assert.InDelta(t, 1.0, results[0].Score, 1e-6)
+}
+
+func TestScoreFieldSignatures(t *testing.T) {
+	embedder := &fakeEmbedder{queries: map[string][]float32{"def put(data: bytes) -> None": {1}}}
+	candidates := []chunk.Chunk{
+		{ID: "doc", Docstring: "Put data.", RetrievalWeight: 1},
+		{ID: "sig", Signature: "def put(data: bytes) -> None", RetrievalWeight: 1},
+	}
+	results, err := scoreField(context.Background(), embedder, []float32{1}, candidates, SearchInSignatures)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "sig", results[0].ID)
+	assert.Equal(t, 1, embedder.calls, "the fields are embedded in one request")
+}
+
+func TestHandlerSearchCodeInvalidSearchIn(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "search_code", map[string]interface{}{
+		"query":     "upload handler",
+		"search_in": "comments",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "unsupported search_in")
+}
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"context"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/cache"
+	"github.com/randalmurphal/code-indexer/internal/config"
+)
+
+// semanticCache returns the semantic cache settings; ok is false when it is
+// disabled or lacks Redis or an embedder.
+func (h *Handler) semanticCache() (cfg config.SemanticCacheConfig, ok bool) {
+	if h.cache == nil || h.embedder == nil || h.config == nil || !h.config.Cache.Semantic.Enabled {
+		return cfg, false
+	}
+	return h.config.Cache.Semantic, true
+}
+
+// semanticLookup serves an exact cache miss from the cached response of the
+// most similar query searched with the same repo, options (variant), and
+// index version, when at least cache.semantic.threshold similar. It returns
+// ctx carrying the query's embedding, which retrieval then reuses, the
+// cached response ("" if none), and the query it was cached for.
+func (h *Handler) semanticLookup(ctx context.Context, query, repo, variant string, version int64) (context.Context, string, string) {
+	cfg, ok := h.semanticCache()
+	if !ok {
+		return ctx, "", ""
+	}
+	started := time.Now()
+	vector := queryVectorFromContext(ctx, query)
+	if vector == nil {
+		done := timeStage(ctx, stageEmbed)
+		vectors, err := h.embedder.Embed(ctx, []string{query})
+		done()
+		if err != nil || len(vectors) != 1 {
+			return ctx, "", ""
+		}
+		vector = vectors[0]
+		ctx = withQueryVector(ctx, query, vector)
+	}
+
+	entries, err := h.cache.SemanticEntries(ctx, cache.SemanticCacheKey(repo, variant, version))
+	if err != nil {
+		h.logger.Warn("semantic cache lookup failed", "repo", repo, "error", err)
+		return ctx, "", ""
+	}
+	entry, similarity, found := nearestEntry(vector, query, entries, cfg.Threshold)
+	var cached string
+	if found {
+		// The response may have expired before the entry list did
+		cached, _ = h.cache.Get(ctx, entry.Key)
+	}
+	if usage := h.usageMetrics(ctx); usage != nil {
+		usage.LogSemanticCache(repo, cached != "", similarity, time.Since(started))
+	}
+	if cached == "" {
+		return ctx, "", ""
+	}
+	h.logger.Debug("semantic cache hit", "query", query, "cached_query", entry.Query, "similarity", similarity)
+	return ctx, cached, entry.Query
+}
+
+// rememberQuery records the embedding of a query whose response was cached
+// under key, so similar queries can be served it.
+func (h *Handler) rememberQuery(ctx context.Context, query, repo, variant string, version int64, key string) {
+	cfg, ok := h.semanticCache()
+	vector := queryVectorFromContext(ctx, query)
+	if !ok || vector == nil {
+		return
+	}
+	entry := cache.SemanticEntry{Query: query, Vector: vector, Key: key}
+	if err := h.cache.AddSemantic(ctx, cache.SemanticCacheKey(repo, variant, version), entry, max(cfg.MaxEntries, 1), h.queryTTL()); err != nil {
+		h.logger.Warn("failed to remember query embedding", "repo", repo, "error", err)
+	}
+}
+
+// nearestEntry returns the entry of another query most similar to vector
+// and its similarity, if at least threshold. Entries embedded with another
+// dimension (an earlier model) are skipped.
+func nearestEntry(vector []float32, query string, entries []cache.SemanticEntry, threshold float64) (cache.SemanticEntry, float64, bool) {
+	var best cache.SemanticEntry
+	bestSim := -1.0
+	for _, e := range entries {
+		if e.Query == query || len(e.Vector) != len(vector) {
+			continue
+		}
+		if sim := cosine(vector, e.Vector); sim > bestSim {
+			best, bestSim = e, sim
+		}
+	}
+	if bestSim < threshold {
+		return cache.SemanticEntry{}, max(bestSim, 0), false
+	}
+	return best, bestSim, true
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNearestEntry(t *testing.T) {
+	entries := []cache.SemanticEntry{
+		{Query: "validate auth tokens", Vector: []float32{1, 0}, Key: "query:r3:a:1"},
+		{Query: "upload retry", Vector: []float32{0, 1}, Key: "query:r3:b:1"},
+		{Query: "old model", Vector: []float32{1, 0, 0}, Key: "query:r3:c:1"},
+	}
+
+	entry, sim, ok := nearestEntry([]float32{0.99, 0.1}, "auth token validation", entries, 0.95)
+	require.True(t, ok)
+	assert.Equal(t, "query:r3:a:1", entry.Key)
+	assert.Greater(t, sim, 0.95)
+
+	_, _, ok = nearestEntry([]float32{0.7, 0.7}, "auth upload", entries, 0.95)
+	assert.False(t, ok, "below the threshold")
+
+	_, _, ok = nearestEntry([]float32{1, 0}, "validate auth tokens", entries[:1], 0.5)
+	assert.False(t, ok, "a query's own entry is an exact hit, not a semantic one")
+}
+
+func TestMarkCacheHitFor(t *testing.T) {
+	resp := PaginatedResponse{QueryType: "concept", Results: []SearchResult{}, IndexMeta: &IndexMeta{Generation: 2}}
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+
+	var got PaginatedResponse
+	require.NoError(t, json.Unmarshal([]byte(markCacheHitFor(string(data), "validate auth tokens")), &got))
+	assert.True(t, got.IndexMeta.CacheHit)
+	assert.Equal(t, "validate auth tokens", got.IndexMeta.CachedQuery)
+}
+
+func TestSearchOptionsCacheVariant(t *testing.T) {
+	ctx := context.Background()
+	base := searchOptions{searchIn: SearchInAll, limit: 10}
+	variants := map[string]bool{base.cacheVariant(ctx): true}
+	for _, o := range []searchOptions{
+		{searchIn: SearchInAll, limit: 20},
+		{searchIn: SearchInAll, limit: 10, module: "app.core"},
+		{searchIn: SearchInAll, limit: 10, includeTests: "exclude"},
+		{searchIn: SearchInAll, limit: 10, includeTests: "only"},
+		{searchIn: SearchInAll, limit: 10, owner: "@org/team"},
+		{searchIn: SearchInAll, limit: 10, offset: 10},
+		{searchIn: SearchInAll, limit: 10, offset: 20},
+	} {
+		variant := o.cacheVariant(ctx)
+		assert.False(t, variants[variant], "%+v shares a cache variant", o)
+		variants[variant] = true
+	}
+	assert.Equal(t, base.cacheVariant(ctx), searchOptions{searchIn: SearchInAll, limit: 10}.cacheVariant(ctx))
+
+	// The second page of a cached query is looked up under its own key
+	firstPage := cache.QueryCacheKey("r3", "retry"+base.cacheVariant(ctx), 1)
+	secondPage := cache.QueryCacheKey("r3", "retry"+searchOptions{searchIn: SearchInAll, limit: 10, offset: 10}.cacheVariant(ctx), 1)
+	assert.NotEqual(t, firstPage, secondPage)
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+)
+
+// withheldNotice ends the content of chunks whose body is withheld.
+const withheldNotice = "[body withheld: this code contains secrets; call again with include_sensitive: true for the full body]"
+
+// sensitiveKey marks contexts of tool calls passing include_sensitive.
+type sensitiveKey struct{}
+
+// withSensitive lets the store return the full bodies of chunks with
+// secrets for the rest of the call.
+func withSensitive(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sensitiveKey{}, true)
+}
+
+func includeSensitive(ctx context.Context) bool {
+	sensitive, _ := ctx.Value(sensitiveKey{}).(bool)
+	return sensitive
+}
+
+// withholdSecretBodies cuts chunks flagged has_secrets down to their
+// signature and docstring, in place, when search.withhold_secret_bodies is
+// on and the call did not ask for include_sensitive. Redaction can miss
+// secrets, so the body is only returned on explicit request.
+func (s *chunkStore) withholdSecretBodies(ctx context.Context, chunks []chunk.Chunk) []chunk.Chunk {
+	if !s.withholdSecrets || includeSensitive(ctx) {
+		return chunks
+	}
+	for i := range chunks {
+		if chunks[i].HasSecrets {
+			withholdBody(&chunks[i])
+		}
+	}
+	return chunks
+}
+
+// withholdBody replaces c's content with its signature, docstring, and
+// withheldNotice, dropping the summary written from its body.
+func withholdBody(c *chunk.Chunk) {
+	var parts []string
+	if c.Signature != "" {
+		parts = append(parts, c.Signature)
+	} else if c.SymbolName != "" {
+		parts = append(parts, c.SymbolName)
+	}
+	if c.Docstring != "" {
+		parts = append(parts, c.Docstring)
+	}
+	c.Content = strings.Join(append(parts, withheldNotice), "\n")
+	c.Summary = ""
+}
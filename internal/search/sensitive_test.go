@@ -0,0 +1,42 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithholdSecretBodies(t *testing.T) {
+	chunks := func() []chunk.Chunk {
+		return []chunk.Chunk{
+			{
+				SymbolName: "connect",
+				Signature:  "def connect(host):",
+				Docstring:  "Open a database connection.",
+				Content:    "def connect(host):\n    password = \"[REDACTED_PASSWORD]\"\n",
+				Summary:    "Connects with a hardcoded password.",
+				HasSecrets: true,
+			},
+			{SymbolName: "close", Content: "def close(): pass", Summary: "Closes it."},
+		}
+	}
+
+	off := &chunkStore{}
+	assert.Equal(t, chunks(), off.withholdSecretBodies(context.Background(), chunks()), "off by default")
+
+	on := &chunkStore{withholdSecrets: true}
+	got := on.withholdSecretBodies(context.Background(), chunks())
+	assert.Equal(t, "def connect(host):\nOpen a database connection.\n"+withheldNotice, got[0].Content)
+	assert.Empty(t, got[0].Summary, "summaries are written from the body")
+	assert.Equal(t, chunks()[1], got[1], "chunks without secrets are untouched")
+
+	assert.Equal(t, chunks(), on.withholdSecretBodies(withSensitive(context.Background()), chunks()), "include_sensitive returns the body")
+}
+
+func TestWithholdBodyWithoutSignature(t *testing.T) {
+	c := chunk.Chunk{Content: "API_KEY = \"[REDACTED_API_KEY]\"", HasSecrets: true}
+	withholdBody(&c)
+	assert.Equal(t, withheldNotice, c.Content)
+}
@@ -0,0 +1,197 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// sessionContextKey stores the pinned SessionContext on the mcp.Session.
+const sessionContextKey = "search.context"
+
+// SessionContext is the working set pinned by set_context. It scopes searches
+// that don't name a repo or module for the rest of the session.
+type SessionContext struct {
+	Repo   string `json:"repo,omitempty"`
+	Module string `json:"module,omitempty"`
+	Root   string `json:"root,omitempty"` // Workspace directory, for codeindex://relevant
+}
+
+// sessionContext returns the context pinned for the request's session.
+func sessionContext(ctx context.Context) SessionContext {
+	session := mcp.SessionFromContext(ctx)
+	if session == nil {
+		return SessionContext{}
+	}
+	sc, _ := session.Get(sessionContextKey)
+	pinned, _ := sc.(SessionContext)
+	return pinned
+}
+
+func (h *Handler) setContext(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	session := mcp.SessionFromContext(ctx)
+	if session == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "set_context requires an MCP session"}},
+			IsError: true,
+		}, nil
+	}
+
+	memory := h.sessionMemory(ctx)
+	if clear, _ := args["clear"].(bool); clear {
+		session.Delete(sessionContextKey)
+		if memory != nil {
+			memory.reset()
+		}
+	}
+
+	pinned := sessionContext(ctx)
+	if path, _ := args["path"].(string); path != "" {
+		if !filepath.IsAbs(path) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{{Type: "text", Text: "path must be absolute"}},
+				IsError: true,
+			}, nil
+		}
+		repo := repoFromPath(path)
+		if repo == "" {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{{Type: "text", Text: path + " is not inside an indexed repository"}},
+				IsError: true,
+			}, nil
+		}
+		pinned = SessionContext{Repo: repo, Root: filepath.Clean(path)}
+	}
+	if repo, ok := args["repo"].(string); ok && repo != pinned.Repo {
+		// A different repo invalidates the module and root pinned for the old one
+		pinned = SessionContext{Repo: repo}
+	}
+	if module, ok := args["module"].(string); ok {
+		pinned.Module = module
+	}
+
+	if pinned.Repo != "" && !mcp.RepoAllowed(ctx, pinned.Repo) {
+		return repoDenied(mcp.TokenInfoFromContext(ctx), pinned.Repo), nil
+	}
+
+	if pinned == (SessionContext{}) {
+		session.Delete(sessionContextKey)
+	} else {
+		session.Set(sessionContextKey, pinned)
+	}
+
+	effective := pinned
+	if effective.Repo == "" {
+		effective.Repo = h.inferRepo(ctx)
+	}
+	out := map[string]interface{}{
+		"pinned":    pinned,
+		"effective": effective,
+	}
+	if memory != nil {
+		out["memory"] = memory.snapshot()
+	}
+	data, _ := json.MarshalIndent(out, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+// inferRepo picks the repo for calls that don't name one: the session's
+// pinned repo, then the client's workspace root, then the server's cwd (or
+// the only repo the caller's token allows).
+func (h *Handler) inferRepo(ctx context.Context) string {
+	if pinned := sessionContext(ctx); pinned.Repo != "" {
+		return pinned.Repo
+	}
+	if dir := sessionRoot(ctx); dir != "" {
+		if repo := repoFromPath(dir); repo != "" {
+			return repo
+		}
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	repo := repoFromPath(cwd)
+
+	// The server's cwd means nothing to a remote caller; a token scoped to a
+	// single repo implies it
+	if token := mcp.TokenInfoFromContext(ctx); token != nil && len(token.Repos) == 1 && !token.AllowsRepo(repo) {
+		return token.Repos[0]
+	}
+	return repo
+}
+
+// scope resolves the repo and module filters for a call. The pinned module
+// only applies while searching the pinned repo.
+func (h *Handler) scope(ctx context.Context, args map[string]interface{}) (repo, module string) {
+	repo, _ = args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	module, _ = args["module"].(string)
+	if module == "" {
+		if pinned := sessionContext(ctx); pinned.Module != "" && (pinned.Repo == "" || pinned.Repo == repo) {
+			module = pinned.Module
+		}
+	}
+	return repo, module
+}
+
+// sessionRoot returns the session's workspace directory: the root pinned by
+// set_context, else the file:// rootUri sent in initialize.
+func sessionRoot(ctx context.Context) string {
+	if pinned := sessionContext(ctx); pinned.Root != "" {
+		return pinned.Root
+	}
+	session := mcp.SessionFromContext(ctx)
+	if session == nil {
+		return ""
+	}
+	u, err := url.Parse(session.RootURI())
+	if err != nil || u.Scheme != "file" || !filepath.IsAbs(u.Path) {
+		return ""
+	}
+	return filepath.Clean(u.Path)
+}
+
+// repoFromPath names the repo containing dir: the first component under
+// ~/repos, else the name in the nearest .ai-devtools.yaml.
+func repoFromPath(dir string) string {
+	homeDir, _ := os.UserHomeDir()
+	reposDir := filepath.Join(homeDir, "repos")
+
+	if rel, err := filepath.Rel(reposDir, dir); err == nil {
+		// Check we're actually under reposDir (rel doesn't start with ..)
+		if !strings.HasPrefix(rel, "..") {
+			// First path component is the repo name
+			parts := strings.SplitN(rel, string(filepath.Separator), 2)
+			if len(parts) > 0 && parts[0] != "." {
+				return parts[0]
+			}
+		}
+	}
+
+	if root := findRepoRoot(dir); root != "" {
+		if repoCfg, err := config.LoadRepoConfig(root); err == nil {
+			return repoCfg.Name
+		}
+	}
+	return ""
+}
+
+// sessionID returns the client session's ID, "" outside a session.
+func sessionID(ctx context.Context) string {
+	if session := mcp.SessionFromContext(ctx); session != nil {
+		return session.ID
+	}
+	return ""
+}
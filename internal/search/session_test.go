@@ -0,0 +1,104 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callSetContext(t *testing.T, h *Handler, ctx context.Context, args map[string]interface{}) map[string]SessionContext {
+	t.Helper()
+	result, err := h.CallTool(ctx, "set_context", args)
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].Text)
+
+	var resp map[string]SessionContext
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &resp))
+	return resp
+}
+
+func TestSetContextRequiresSession(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "set_context", map[string]interface{}{"repo": "demo"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestSetContextPinsRepoAndModule(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	ctx := mcp.WithSession(context.Background(), mcp.NewSession())
+
+	resp := callSetContext(t, handler, ctx, map[string]interface{}{"repo": "demo", "module": "fisdb"})
+	assert.Equal(t, SessionContext{Repo: "demo", Module: "fisdb"}, resp["pinned"])
+	assert.Equal(t, "demo", handler.inferRepo(ctx))
+
+	repo, module := handler.scope(ctx, map[string]interface{}{})
+	assert.Equal(t, "demo", repo)
+	assert.Equal(t, "fisdb", module, "pinned module applies to the pinned repo")
+
+	repo, module = handler.scope(ctx, map[string]interface{}{"repo": "other"})
+	assert.Equal(t, "other", repo)
+	assert.Empty(t, module, "pinned module does not leak into other repos")
+
+	_, module = handler.scope(ctx, map[string]interface{}{"module": "imports"})
+	assert.Equal(t, "imports", module, "explicit module wins")
+
+	// Switching repos drops the module pinned for the old one
+	resp = callSetContext(t, handler, ctx, map[string]interface{}{"repo": "other"})
+	assert.Equal(t, SessionContext{Repo: "other"}, resp["pinned"])
+
+	resp = callSetContext(t, handler, ctx, map[string]interface{}{"clear": true})
+	assert.Equal(t, SessionContext{}, resp["pinned"])
+}
+
+func TestSetContextIsPerSession(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+	first := mcp.WithSession(context.Background(), mcp.NewSession())
+	second := mcp.WithSession(context.Background(), mcp.NewSession())
+
+	callSetContext(t, handler, first, map[string]interface{}{"repo": "demo"})
+
+	assert.Equal(t, "demo", handler.inferRepo(first))
+	assert.NotEqual(t, "demo", handler.inferRepo(second))
+}
+
+func TestSetContextFromPath(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, repoConfigFile), []byte("code-index:\n  name: demo\n"), 0644))
+	nested := filepath.Join(root, "pkg")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	handler := &Handler{config: config.DefaultConfig()}
+	ctx := mcp.WithSession(context.Background(), mcp.NewSession())
+
+	resp := callSetContext(t, handler, ctx, map[string]interface{}{"path": nested})
+	assert.Equal(t, SessionContext{Repo: "demo", Root: nested}, resp["pinned"])
+	assert.Equal(t, nested, sessionRoot(ctx))
+
+	result, err := handler.CallTool(ctx, "set_context", map[string]interface{}{"path": t.TempDir()})
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "path outside any repo")
+
+	result, err = handler.CallTool(ctx, "set_context", map[string]interface{}{"path": "pkg"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "relative path")
+}
+
+func TestRepoFromPath(t *testing.T) {
+	homeDir, err := os.UserHomeDir()
+	require.NoError(t, err)
+	assert.Equal(t, "demo", repoFromPath(filepath.Join(homeDir, "repos", "demo", "pkg")))
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, repoConfigFile), []byte("code-index:\n  name: named\n"), 0644))
+	assert.Equal(t, "named", repoFromPath(root))
+	assert.Equal(t, "", repoFromPath(t.TempDir()))
+}
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// defaultSimilarThreshold is the cosine similarity a chunk needs to count as
+// similar when the caller gives no threshold.
+const defaultSimilarThreshold = 0.8
+
+// SimilarMatch is one chunk returned by similar_code.
+type SimilarMatch struct {
+	Repo       string  `json:"repo"`
+	FilePath   string  `json:"file_path"`
+	StartLine  int     `json:"start_line"`
+	EndLine    int     `json:"end_line"`
+	Symbol     string  `json:"symbol,omitempty"`
+	Kind       string  `json:"kind,omitempty"`
+	Similarity float32 `json:"similarity"`
+	Content    string  `json:"content"`
+}
+
+// SimilarResponse is the similar_code tool result.
+type SimilarResponse struct {
+	Threshold float64        `json:"threshold"`
+	Matches   []SimilarMatch `json:"matches"`
+}
+
+func (h *Handler) similarCode(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	snippet, _ := args["snippet"].(string)
+	if strings.TrimSpace(snippet) == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "snippet parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+
+	threshold := defaultSimilarThreshold
+	if t, ok := args["threshold"].(float64); ok {
+		if t <= 0 || t > 1 {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{{Type: "text", Text: "threshold must be between 0 and 1"}},
+				IsError: true,
+			}, nil
+		}
+		threshold = t
+	}
+
+	limit := 10
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	if h.store == nil || h.embedder == nil {
+		return nil, fmt.Errorf("vector store not configured")
+	}
+
+	repo, _ := args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	includeTests, _ := args["include_tests"].(string)
+
+	filter := map[string]interface{}{
+		"type": string(chunk.ChunkTypeCode),
+	}
+	if repo != "" && repo != "all" {
+		filter["repo"] = repo
+	}
+	switch includeTests {
+	case "exclude":
+		filter["is_test"] = false
+	case "only":
+		filter["is_test"] = true
+	}
+
+	vectors, err := h.embedder.Embed(ctx, []string{snippet})
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+
+	// Over-fetch: class summaries are dropped from the results
+	results, err := h.store.Search(ctx, "chunks", vectors[0], limit*2, filter)
+	if err != nil {
+		return nil, fmt.Errorf("similar_code failed: %w", err)
+	}
+
+	response := SimilarResponse{
+		Threshold: threshold,
+		Matches:   similarMatches(results, threshold, limit),
+	}
+	if len(response.Matches) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("No indexed code is at least %.2f similar to the snippet.", threshold)}},
+		}, nil
+	}
+
+	data, _ := json.MarshalIndent(response, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+// similarMatches keeps up to limit results scoring at least threshold,
+// preserving the store's best-first order. Class and file summaries restate
+// code that is indexed on its own, so they are skipped.
+func similarMatches(results []chunk.Chunk, threshold float64, limit int) []SimilarMatch {
+	var matches []SimilarMatch
+	for _, c := range results {
+		if float64(c.Score) < threshold || c.Kind == "class_summary" || c.Kind == chunk.KindFileSummary {
+			continue
+		}
+		matches = append(matches, SimilarMatch{
+			Repo:       c.Repo,
+			FilePath:   c.FilePath,
+			StartLine:  c.StartLine,
+			EndLine:    c.EndLine,
+			Symbol:     c.SymbolName,
+			Kind:       c.Kind,
+			Similarity: c.Score,
+			Content:    c.Content,
+		})
+		if len(matches) == limit {
+			break
+		}
+	}
+	return matches
+}
@@ -0,0 +1,47 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarMatches(t *testing.T) {
+	results := []chunk.Chunk{
+		{FilePath: "a.py", SymbolName: "Retry", Kind: "class_summary", Score: 0.95},
+		{FilePath: "a.py", Kind: chunk.KindFileSummary, Score: 0.94},
+		{FilePath: "a.py", SymbolName: "retry", Kind: "function", Score: 0.93},
+		{FilePath: "b.py", SymbolName: "backoff", Kind: "function", Score: 0.85},
+		{FilePath: "c.py", SymbolName: "sleep", Kind: "function", Score: 0.71},
+	}
+
+	matches := similarMatches(results, 0.8, 10)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "retry", matches[0].Symbol)
+	assert.Equal(t, float32(0.93), matches[0].Similarity)
+	assert.Equal(t, "backoff", matches[1].Symbol)
+
+	assert.Len(t, similarMatches(results, 0.8, 1), 1)
+	assert.Empty(t, similarMatches(results, 0.99, 10))
+}
+
+func TestSimilarCodeValidation(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "similar_code", map[string]interface{}{"snippet": "  "})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "snippet parameter is required")
+
+	result, err = handler.CallTool(context.Background(), "similar_code", map[string]interface{}{
+		"snippet":   "def f(): pass",
+		"threshold": 1.5,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "threshold")
+}
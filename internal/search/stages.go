@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Stages of a search_code call timed for the metrics log.
+const (
+	stageEmbed  = "embed"  // Query embeddings (Voyage)
+	stageStore  = "store"  // Qdrant reads
+	stageGraph  = "graph"  // Neo4j expansion, call paths, and relationships
+	stageFormat = "format" // Pagination, highlighting, and the response JSON
+)
+
+// stageTimes sums the time spent per stage. Stages running concurrently
+// (federated repos) add up, so they may exceed the wall time.
+type stageTimes struct {
+	mu sync.Mutex
+	ms map[string]float64
+}
+
+type stageTimesKey struct{}
+
+// withStageTimes returns a context whose stages timeStage records.
+func withStageTimes(ctx context.Context) (context.Context, *stageTimes) {
+	t := &stageTimes{ms: make(map[string]float64)}
+	return context.WithValue(ctx, stageTimesKey{}, t), t
+}
+
+// timeStage starts timing stage for the search of ctx; call the returned
+// func when it ends. Outside a timed search it does nothing.
+func timeStage(ctx context.Context, stage string) func() {
+	t, _ := ctx.Value(stageTimesKey{}).(*stageTimes)
+	if t == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := float64(time.Since(start).Microseconds()) / 1000
+		t.mu.Lock()
+		t.ms[stage] += elapsed
+		t.mu.Unlock()
+	}
+}
+
+// snapshot returns the milliseconds spent per stage so far.
+func (t *stageTimes) snapshot() map[string]float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return maps.Clone(t.ms)
+}
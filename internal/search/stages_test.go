@@ -0,0 +1,36 @@
+package search
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStageTimes(t *testing.T) {
+	timeStage(context.Background(), stageEmbed)() // Untimed contexts are ignored
+
+	ctx, stages := withStageTimes(context.Background())
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done := timeStage(ctx, stageStore)
+			time.Sleep(5 * time.Millisecond)
+			done()
+		}()
+	}
+	wg.Wait()
+	timeStage(ctx, stageFormat)()
+
+	snapshot := stages.snapshot()
+	assert.GreaterOrEqual(t, snapshot[stageStore], 15.0, "concurrent stages add up")
+	assert.Contains(t, snapshot, stageFormat)
+	assert.NotContains(t, snapshot, stageEmbed)
+
+	snapshot[stageGraph] = 1
+	assert.NotContains(t, stages.snapshot(), stageGraph, "snapshots are copies")
+}
@@ -0,0 +1,39 @@
+package search
+
+// Values of search_code's strategy argument.
+const (
+	StrategyAuto     = "auto"     // Classify the query (default)
+	StrategySemantic = "semantic" // Embedding search only
+	StrategyHybrid   = "hybrid"   // Embedding search fused with keyword matches
+	StrategySymbol   = "symbol"   // Symbol name lookup
+	StrategyPattern  = "pattern"  // Detected pattern chunks
+)
+
+var searchStrategies = []string{StrategyAuto, StrategySemantic, StrategyHybrid, StrategySymbol, StrategyPattern}
+
+// Force returns the query type and retrieval strategy of an explicit
+// search_code strategy, bypassing classification: the routing of the query
+// type it implies, with its retrieval forced. ok is false for auto.
+func (c *Classifier) Force(name string) (QueryType, RetrievalStrategy, bool) {
+	var qt QueryType
+	switch name {
+	case StrategySemantic, StrategyHybrid:
+		qt = QueryTypeConcept
+	case StrategySymbol:
+		qt = QueryTypeSymbol
+	case StrategyPattern:
+		qt = QueryTypePattern
+	default:
+		return "", RetrievalStrategy{}, false
+	}
+
+	strategy := c.Route(qt)
+	strategy.UseSemanticSearch = qt == QueryTypeConcept
+	strategy.UseSymbolIndex = name == StrategySymbol
+	strategy.UsePatternIndex = name == StrategyPattern
+	if strategy.UseSemanticSearch {
+		hybrid := name == StrategyHybrid
+		strategy.Hybrid = &hybrid
+	}
+	return qt, strategy, true
+}
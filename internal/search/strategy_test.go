@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifierForce(t *testing.T) {
+	classifier := NewClassifier()
+
+	_, _, ok := classifier.Force(StrategyAuto)
+	assert.False(t, ok)
+	_, _, ok = classifier.Force("")
+	assert.False(t, ok)
+
+	qt, strategy, ok := classifier.Force(StrategyHybrid)
+	require.True(t, ok)
+	assert.Equal(t, QueryTypeConcept, qt)
+	assert.True(t, strategy.UseSemanticSearch)
+	require.NotNil(t, strategy.Hybrid)
+	assert.True(t, *strategy.Hybrid)
+
+	_, strategy, _ = classifier.Force(StrategySemantic)
+	require.NotNil(t, strategy.Hybrid)
+	assert.False(t, *strategy.Hybrid)
+
+	qt, strategy, _ = classifier.Force(StrategySymbol)
+	assert.Equal(t, QueryTypeSymbol, qt)
+	assert.True(t, strategy.UseSymbolIndex)
+	assert.False(t, strategy.UseSemanticSearch)
+	assert.Nil(t, strategy.Hybrid)
+
+	qt, strategy, _ = classifier.Force(StrategyPattern)
+	assert.Equal(t, QueryTypePattern, qt)
+	assert.True(t, strategy.UsePatternIndex)
+	assert.Equal(t, 5, strategy.MaxResults, "the query type's configured routing still applies")
+}
+
+func TestHandlerSearchCodeInvalidStrategy(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "search_code", map[string]interface{}{
+		"query":    "upload handler",
+		"strategy": "fuzzy",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "unsupported strategy")
+}
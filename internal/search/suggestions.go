@@ -1,22 +1,40 @@
 package search
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
+// vocabularyTTL is how long a repo's loaded vocabulary is reused before
+// reloading picks up reindexed symbols.
+const vocabularyTTL = 10 * time.Minute
+
 // SuggestionGenerator creates search suggestions for empty results.
 type SuggestionGenerator struct {
 	synonyms   map[string][]string
-	knownTerms map[string]int // term -> count
+	mu         sync.RWMutex
+	knownTerms map[string]int         // term -> count
+	vocab      map[string]*vocabulary // repo ("all" for every repo) -> indexed terms
+}
+
+// vocabulary is a repo's indexed symbol names and module path segments.
+type vocabulary struct {
+	terms     map[string]int    // lowercased term -> chunk count
+	spellings map[string]string // lowercased term -> spelling as indexed
+	loaded    time.Time
 }
 
 // Suggestion is a search suggestion.
 type Suggestion struct {
-	Term   string `json:"term"`
-	Count  int    `json:"count,omitempty"`
-	Reason string `json:"reason,omitempty"`
+	Term       string `json:"term"`
+	Count      int    `json:"count,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Correction bool   `json:"correction,omitempty"` // Close spelling of a query word
 }
 
 // NewSuggestionGenerator creates a new generator with default synonyms.
@@ -41,16 +59,54 @@ func NewSuggestionGenerator() *SuggestionGenerator {
 			"timeout":        {"expiry", "ttl", "deadline", "retry"},
 		},
 		knownTerms: make(map[string]int),
+		vocab:      make(map[string]*vocabulary),
 	}
 }
 
 // AddKnownTerms adds terms that exist in the index.
 func (g *SuggestionGenerator) AddKnownTerms(terms []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	for _, term := range terms {
 		g.knownTerms[strings.ToLower(term)]++
 	}
 }
 
+// SetVocabulary replaces repo's vocabulary with terms, counted by chunk and
+// keyed by their indexed spelling.
+func (g *SuggestionGenerator) SetVocabulary(repo string, terms map[string]int) {
+	v := &vocabulary{
+		terms:     make(map[string]int, len(terms)),
+		spellings: make(map[string]string, len(terms)),
+		loaded:    time.Now(),
+	}
+	for term, count := range terms {
+		lower := strings.ToLower(term)
+		if spelling, ok := v.spellings[lower]; !ok || terms[spelling] < count {
+			v.spellings[lower] = term
+		}
+		v.terms[lower] += count
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.vocab[vocabularyKey(repo)] = v
+}
+
+// HasVocabulary reports whether repo's vocabulary was loaded recently.
+func (g *SuggestionGenerator) HasVocabulary(repo string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	v, ok := g.vocab[vocabularyKey(repo)]
+	return ok && time.Since(v.loaded) < vocabularyTTL
+}
+
+func vocabularyKey(repo string) string {
+	if repo == "" {
+		return "all"
+	}
+	return repo
+}
+
 // GetSynonyms returns synonyms for a term.
 func (g *SuggestionGenerator) GetSynonyms(term string) []string {
 	return g.synonyms[strings.ToLower(term)]
@@ -58,7 +114,19 @@ func (g *SuggestionGenerator) GetSynonyms(term string) []string {
 
 // Generate creates suggestions for a failed query.
 func (g *SuggestionGenerator) Generate(query string) []Suggestion {
-	words := strings.Fields(strings.ToLower(query))
+	return g.GenerateFor(query, "")
+}
+
+// GenerateFor creates suggestions for a failed query in repo. Besides
+// synonyms and partial matches of known terms, query words a typo or two
+// away from a term of repo's vocabulary suggest that term, as indexed.
+func (g *SuggestionGenerator) GenerateFor(query, repo string) []Suggestion {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
 	suggestions := make(map[string]*Suggestion)
 
 	for _, word := range words {
@@ -91,14 +159,36 @@ func (g *SuggestionGenerator) Generate(query string) []Suggestion {
 		}
 	}
 
-	// Convert to slice and sort by count
+	if v := g.vocab[vocabularyKey(repo)]; v != nil {
+		for _, word := range words {
+			if _, known := v.terms[word]; known {
+				continue
+			}
+			for _, term := range closeTerms(word, v.terms) {
+				suggestions[term] = &Suggestion{
+					Term:       v.spellings[term],
+					Count:      v.terms[term],
+					Reason:     fmt.Sprintf("close spelling of '%s'", word),
+					Correction: true,
+				}
+			}
+		}
+	}
+
+	// Convert to slice and sort corrections first, then by count
 	result := make([]Suggestion, 0, len(suggestions))
 	for _, s := range suggestions {
 		result = append(result, *s)
 	}
 
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Count > result[j].Count
+		if result[i].Correction != result[j].Correction {
+			return result[i].Correction
+		}
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Term < result[j].Term
 	})
 
 	// Limit to top 5
@@ -109,6 +199,55 @@ func (g *SuggestionGenerator) Generate(query string) []Suggestion {
 	return result
 }
 
+// closeTerms returns the terms within maxEdits(word) edits of word, up to 3,
+// fewest edits first.
+func closeTerms(word string, terms map[string]int) []string {
+	limit := maxEdits(word)
+	if limit == 0 {
+		return nil
+	}
+	type match struct {
+		term  string
+		edits int
+	}
+	var matches []match
+	for term := range terms {
+		if diff := len(term) - len(word); diff > limit || -diff > limit {
+			continue
+		}
+		if edits := editDistance(word, term); edits > 0 && edits <= limit {
+			matches = append(matches, match{term, edits})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].edits != matches[j].edits {
+			return matches[i].edits < matches[j].edits
+		}
+		if terms[matches[i].term] != terms[matches[j].term] {
+			return terms[matches[i].term] > terms[matches[j].term]
+		}
+		return matches[i].term < matches[j].term
+	})
+	closest := make([]string, 0, min(len(matches), 3))
+	for _, m := range matches[:min(len(matches), 3)] {
+		closest = append(closest, m.term)
+	}
+	return closest
+}
+
+// maxEdits is how many edits a word of its length may be from a suggested
+// term: none under 4 characters, where most words are a typo from another.
+func maxEdits(word string) int {
+	switch n := len(word); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
 // FormatEmptyResponse creates a helpful response when search returns nothing.
 func (g *SuggestionGenerator) FormatEmptyResponse(query, repo string, suggestions []Suggestion) map[string]interface{} {
 	response := map[string]interface{}{
@@ -117,6 +256,17 @@ func (g *SuggestionGenerator) FormatEmptyResponse(query, repo string, suggestion
 		"message":    fmt.Sprintf("No direct matches for '%s'", query),
 	}
 
+	var corrections []string
+	for _, s := range suggestions {
+		if s.Correction {
+			corrections = append(corrections, s.Term)
+		}
+	}
+	if len(corrections) > 0 {
+		response["did_you_mean"] = corrections
+		response["message"] = fmt.Sprintf("No direct matches for '%s'. Did you mean `%s`?", query, corrections[0])
+	}
+
 	if len(suggestions) > 0 {
 		suggestionStrs := make([]string, len(suggestions))
 		for i, s := range suggestions {
@@ -140,3 +290,35 @@ func (g *SuggestionGenerator) FormatEmptyResponse(query, repo string, suggestion
 
 	return response
 }
+
+// loadVocabulary loads repo's symbol names and module path segments into
+// the suggestion generator, unless loaded recently. Failures only cost the
+// did-you-mean suggestions.
+func (h *Handler) loadVocabulary(ctx context.Context, repo string) {
+	if h.store == nil || h.suggestionGen.HasVocabulary(repo) {
+		return
+	}
+	filter := make(map[string]interface{})
+	if repo != "" && repo != "all" {
+		filter["repo"] = repo
+	}
+	symbols, err := h.store.CountByField(ctx, "chunks", "symbol_name", filter)
+	if err != nil {
+		h.logger.Warn("failed to load symbol vocabulary", "repo", repo, "error", err)
+		return
+	}
+	modules, err := h.store.CountByField(ctx, "chunks", "module_path", filter)
+	if err != nil {
+		h.logger.Warn("failed to load module vocabulary", "repo", repo, "error", err)
+		return
+	}
+	terms := symbols
+	for path, count := range modules {
+		for _, segment := range strings.Split(path, ".") {
+			if segment != "" {
+				terms[segment] += count
+			}
+		}
+	}
+	h.suggestionGen.SetVocabulary(repo, terms)
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateSuggestions(t *testing.T) {
@@ -100,3 +101,35 @@ func TestSuggestionLimitedToFive(t *testing.T) {
 	// Should be limited to 5
 	assert.LessOrEqual(t, len(suggestions), 5)
 }
+
+func TestGenerateDidYouMean(t *testing.T) {
+	gen := NewSuggestionGenerator()
+	gen.SetVocabulary("r3", map[string]int{
+		"BOUpserter": 4,
+		"BOUpdater":  2,
+		"imports":    9,
+		"run":        30,
+	})
+
+	suggestions := gen.GenerateFor("BOUpsertr run", "r3")
+	require.NotEmpty(t, suggestions)
+	assert.Equal(t, Suggestion{Term: "BOUpserter", Count: 4, Reason: "close spelling of 'boupsertr'", Correction: true}, suggestions[0])
+	for _, s := range suggestions {
+		assert.NotEqual(t, "run", s.Term, "known words are not corrected")
+	}
+
+	assert.Empty(t, gen.GenerateFor("BOUpsertr", "other"), "vocabularies are per repo")
+	assert.Empty(t, gen.GenerateFor("rum", "r3"), "short words are not corrected")
+
+	response := gen.FormatEmptyResponse("BOUpsertr", "r3", suggestions)
+	assert.Equal(t, []string{"BOUpserter"}, response["did_you_mean"])
+	assert.Contains(t, response["message"], "Did you mean `BOUpserter`?")
+}
+
+func TestHasVocabulary(t *testing.T) {
+	gen := NewSuggestionGenerator()
+	assert.False(t, gen.HasVocabulary("r3"))
+	gen.SetVocabulary("", map[string]int{"upload": 1})
+	assert.True(t, gen.HasVocabulary("all"), "no repo means every repo")
+	assert.False(t, gen.HasVocabulary("r3"))
+}
@@ -0,0 +1,273 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+const (
+	// fuzzySymbolThreshold is the minimum name similarity for a fuzzy match.
+	fuzzySymbolThreshold = 0.6
+	// maxFuzzySymbols bounds how many candidate names a fuzzy lookup returns.
+	maxFuzzySymbols = 5
+)
+
+// SymbolDefinition is a definition returned by get_symbol.
+type SymbolDefinition struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Module    string `json:"module,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Docstring string `json:"docstring,omitempty"`
+	Content   string `json:"content"`
+	IsTest    bool   `json:"is_test,omitempty"`
+}
+
+// SymbolResponse is the get_symbol tool result.
+type SymbolResponse struct {
+	Query       string             `json:"query"`
+	Match       string             `json:"match"` // exact | fuzzy
+	Definitions []SymbolDefinition `json:"definitions"`
+}
+
+func (h *Handler) getSymbol(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	name, _ := args["name"].(string)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "name parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+	if h.store == nil {
+		return nil, fmt.Errorf("vector store not configured")
+	}
+
+	repo, _ := args["repo"].(string)
+	if repo == "" {
+		repo = h.inferRepo(ctx)
+	}
+	kind, _ := args["kind"].(string)
+
+	limit := 5
+	if l, ok := args["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+
+	filter := make(map[string]interface{})
+	if repo != "" && repo != "all" {
+		filter["repo"] = repo
+	}
+	if kind != "" {
+		filter["kind"] = kind
+	}
+
+	// "Class.method" looks up the method, preferring chunks from that class
+	lookup, owner := name, ""
+	if i := strings.LastIndex(name, "."); i > 0 && i < len(name)-1 {
+		owner, lookup = name[:i], name[i+1:]
+	}
+
+	response := SymbolResponse{Query: name, Match: "exact"}
+
+	chunks, err := h.findSymbolChunks(ctx, lookup, filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get_symbol failed: %w", err)
+	}
+
+	if len(chunks) == 0 {
+		response.Match = "fuzzy"
+		names, err := h.store.CountByField(ctx, "chunks", "symbol_name", filter)
+		if err != nil {
+			return nil, fmt.Errorf("get_symbol failed: %w", err)
+		}
+		for _, candidate := range rankSymbolNames(lookup, names, maxFuzzySymbols) {
+			found, err := h.findSymbolChunks(ctx, candidate, filter, limit)
+			if err != nil {
+				return nil, fmt.Errorf("get_symbol failed: %w", err)
+			}
+			chunks = append(chunks, found...)
+		}
+	}
+
+	if owner != "" {
+		preferOwner(chunks, owner)
+	}
+	if len(chunks) > limit {
+		chunks = chunks[:limit]
+	}
+
+	if len(chunks) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: fmt.Sprintf("No symbol matching %q found. Try search_code with a description instead.", name)}},
+		}, nil
+	}
+
+	for _, c := range chunks {
+		response.Definitions = append(response.Definitions, SymbolDefinition{
+			Name:      c.SymbolName,
+			Kind:      c.Kind,
+			FilePath:  c.FilePath,
+			StartLine: c.StartLine,
+			EndLine:   c.EndLine,
+			Module:    c.ModulePath,
+			Signature: c.Signature,
+			Docstring: c.Docstring,
+			Content:   c.Content,
+			IsTest:    c.IsTest,
+		})
+	}
+
+	data, _ := json.MarshalIndent(response, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
+
+// findSymbolChunks returns definition chunks for an exact symbol name.
+func (h *Handler) findSymbolChunks(ctx context.Context, name string, filter map[string]interface{}, limit int) ([]chunk.Chunk, error) {
+	symbolFilter := make(map[string]interface{}, len(filter)+1)
+	for k, v := range filter {
+		symbolFilter[k] = v
+	}
+	symbolFilter["symbol_name"] = name
+
+	// Over-fetch: class summaries are dropped when the full class is present
+	chunks, err := h.store.SearchByFilter(ctx, "chunks", symbolFilter, limit*2)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankDefinitions(chunks), nil
+}
+
+// rankDefinitions drops class summaries shadowed by a full class chunk at the
+// same location and orders non-test definitions first.
+func rankDefinitions(chunks []chunk.Chunk) []chunk.Chunk {
+	full := make(map[string]bool)
+	for _, c := range chunks {
+		if c.Kind != "class_summary" {
+			full[fmt.Sprintf("%s:%d", c.FilePath, c.StartLine)] = true
+		}
+	}
+
+	var defs []chunk.Chunk
+	for _, c := range chunks {
+		if c.Kind == "class_summary" && full[fmt.Sprintf("%s:%d", c.FilePath, c.StartLine)] {
+			continue
+		}
+		defs = append(defs, c)
+	}
+
+	sort.SliceStable(defs, func(i, j int) bool {
+		if defs[i].IsTest != defs[j].IsTest {
+			return !defs[i].IsTest
+		}
+		if defs[i].FilePath != defs[j].FilePath {
+			return defs[i].FilePath < defs[j].FilePath
+		}
+		return defs[i].StartLine < defs[j].StartLine
+	})
+
+	return defs
+}
+
+// preferOwner moves chunks whose context names the owning class to the front.
+func preferOwner(chunks []chunk.Chunk, owner string) {
+	marker := "# Class: " + owner
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return strings.Contains(chunks[i].ContextHeader, marker) && !strings.Contains(chunks[j].ContextHeader, marker)
+	})
+}
+
+// rankSymbolNames returns up to limit names most similar to query, best first.
+func rankSymbolNames(query string, names map[string]int, limit int) []string {
+	type scored struct {
+		name  string
+		score float64
+	}
+
+	var candidates []scored
+	for name := range names {
+		if s := symbolSimilarity(query, name); s >= fuzzySymbolThreshold {
+			candidates = append(candidates, scored{name, s})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	result := make([]string, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.name
+	}
+	return result
+}
+
+// symbolSimilarity scores two identifiers in [0, 1], ignoring case and
+// underscores so validate_token and validateToken compare equal.
+func symbolSimilarity(a, b string) float64 {
+	na, nb := normalizeIdentifier(a), normalizeIdentifier(b)
+	if na == "" || nb == "" {
+		return 0
+	}
+	if na == nb {
+		return 1
+	}
+
+	longest := len(na)
+	if len(nb) > longest {
+		longest = len(nb)
+	}
+	score := 1 - float64(editDistance(na, nb))/float64(longest)
+
+	// A name containing the whole query (e.g. "token" in "validate_token")
+	// is a reasonable match even when lengths differ a lot
+	if strings.Contains(nb, na) && score < 0.7 {
+		score = 0.7
+	}
+	return score
+}
+
+func normalizeIdentifier(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+// editDistance is the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, symbolSimilarity("validate_token", "validateToken"))
+	assert.Equal(t, 1.0, symbolSimilarity("UserService", "userservice"))
+	assert.GreaterOrEqual(t, symbolSimilarity("validte_token", "validate_token"), fuzzySymbolThreshold)
+	assert.GreaterOrEqual(t, symbolSimilarity("token", "validate_token"), fuzzySymbolThreshold)
+	assert.Less(t, symbolSimilarity("parse_config", "send_email"), fuzzySymbolThreshold)
+	assert.Equal(t, 0.0, symbolSimilarity("", "x"))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("abc", "abc"))
+	assert.Equal(t, 1, editDistance("abc", "abd"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+}
+
+func TestRankSymbolNames(t *testing.T) {
+	names := map[string]int{
+		"validate_token": 1,
+		"validateToken":  1,
+		"refresh_token":  2,
+		"send_email":     1,
+	}
+
+	ranked := rankSymbolNames("validte_token", names, 2)
+
+	require.Len(t, ranked, 2)
+	assert.ElementsMatch(t, []string{"validate_token", "validateToken"}, ranked)
+}
+
+func TestRankDefinitions(t *testing.T) {
+	chunks := []chunk.Chunk{
+		{FilePath: "tests/test_auth.py", StartLine: 1, Kind: "function", IsTest: true},
+		{FilePath: "auth.py", StartLine: 10, Kind: "class_summary"},
+		{FilePath: "auth.py", StartLine: 10, Kind: "class"},
+		{FilePath: "other.py", StartLine: 5, Kind: "class_summary"},
+	}
+
+	defs := rankDefinitions(chunks)
+
+	require.Len(t, defs, 3)
+	assert.Equal(t, "class", defs[0].Kind)
+	assert.Equal(t, "other.py", defs[1].FilePath)
+	assert.True(t, defs[2].IsTest)
+}
+
+func TestPreferOwner(t *testing.T) {
+	chunks := []chunk.Chunk{
+		{FilePath: "a.py", ContextHeader: "# File: a.py\n# Class: Other"},
+		{FilePath: "b.py", ContextHeader: "# File: b.py\n# Class: Importer"},
+	}
+
+	preferOwner(chunks, "Importer")
+
+	assert.Equal(t, "b.py", chunks[0].FilePath)
+}
+
+func TestHandlerGetSymbolMissingName(t *testing.T) {
+	handler := &Handler{config: config.DefaultConfig()}
+
+	result, err := handler.CallTool(context.Background(), "get_symbol", map[string]interface{}{})
+
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "name parameter is required")
+}
@@ -0,0 +1,43 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/randalmurphal/code-indexer/internal/mcp"
+)
+
+// UsedResponse confirms a mark_used notification.
+type UsedResponse struct {
+	FilePath string `json:"file_path"`
+	Recorded bool   `json:"recorded"` // False without a metrics log
+}
+
+// markUsed records that the client opened a file returned by a search, for
+// Analyzer.Usage to relate to the search's strategy. It only logs: ranking
+// is unaffected until weights are tuned from the numbers.
+func (h *Handler) markUsed(ctx context.Context, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	filePath, _ := args["file_path"].(string)
+	if filePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{{Type: "text", Text: "file_path parameter is required"}},
+			IsError: true,
+		}, nil
+	}
+	query, _ := args["query"].(string)
+	id, _ := args["id"].(string)
+	repo, _ := args["repo"].(string)
+	if repo != "" && !mcp.RepoAllowed(ctx, repo) {
+		return repoDenied(mcp.TokenInfoFromContext(ctx), repo), nil
+	}
+
+	usage := h.usageMetrics(ctx)
+	if usage != nil {
+		usage.LogUsed(query, sessionID(ctx), repo, filePath, id)
+	}
+
+	data, _ := json.MarshalIndent(UsedResponse{FilePath: filePath, Recorded: usage != nil}, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{{Type: "text", Text: string(data)}},
+	}, nil
+}
@@ -0,0 +1,44 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkUsed(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	logger, err := metrics.NewLogger(logPath)
+	require.NoError(t, err)
+	handler := &Handler{config: config.DefaultConfig(), metrics: logger}
+
+	result, err := handler.CallTool(context.Background(), "mark_used", map[string]interface{}{"query": "auth"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "file_path is required")
+
+	result, err = handler.CallTool(context.Background(), "mark_used", map[string]interface{}{
+		"file_path": "auth/login.py", "query": "auth", "id": "chunk-1", "repo": "r3",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var resp UsedResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &resp))
+	assert.True(t, resp.Recorded)
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &event))
+	assert.Equal(t, "used", event["event"])
+	assert.Equal(t, "auth/login.py", event["file_path"])
+	assert.Equal(t, "chunk-1", event["chunk_id"])
+	assert.Equal(t, "r3", event["repo"])
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/metrics"
+)
+
+// warmingKey marks the context of a cache warming search.
+type warmingKey struct{}
+
+// usageMetrics returns the metrics logger for a search made with ctx, nil
+// when there is none or the search is warming the cache: warming re-runs
+// logged searches, and logging them again would make them rank ever higher.
+func (h *Handler) usageMetrics(ctx context.Context) *metrics.Logger {
+	if ctx.Value(warmingKey{}) != nil {
+		return nil
+	}
+	return h.metrics
+}
+
+// WarmCache re-runs repo's most frequent recent searches (cache.warm) so
+// their responses are cached against the current index version. It returns
+// how many searches succeeded; without Redis or with warming disabled it
+// does nothing.
+func (h *Handler) WarmCache(ctx context.Context, repo string) (int, error) {
+	if h.cache == nil || h.config == nil || h.config.Cache.Warm.Queries <= 0 || h.metricsPath == "" {
+		return 0, nil
+	}
+	cfg := h.config.Cache.Warm
+	queries, err := metrics.NewAnalyzer(h.metricsPath).TopQueries(time.Duration(cfg.WindowHours)*time.Hour, repo, cfg.Queries)
+	if err != nil {
+		return 0, fmt.Errorf("load top queries: %w", err)
+	}
+
+	ctx = context.WithValue(ctx, warmingKey{}, true)
+	warmed := 0
+	for _, q := range queries {
+		if err := ctx.Err(); err != nil {
+			return warmed, err
+		}
+		result, err := h.CallTool(ctx, "search_code", map[string]interface{}{"query": q.Query, "repo": repo})
+		if err != nil || result.IsError {
+			if h.logger != nil {
+				h.logger.Debug("cache warming search failed", "repo", repo, "query", q.Query, "error", err)
+			}
+			continue
+		}
+		warmed++
+	}
+	return warmed, nil
+}
@@ -0,0 +1,31 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageMetricsSkipsWarming(t *testing.T) {
+	logger, err := metrics.NewLogger(filepath.Join(t.TempDir(), "metrics.jsonl"))
+	require.NoError(t, err)
+	defer logger.Close()
+	h := &Handler{metrics: logger}
+
+	assert.Same(t, logger, h.usageMetrics(context.Background()))
+	assert.Nil(t, h.usageMetrics(context.WithValue(context.Background(), warmingKey{}, true)))
+	assert.Nil(t, (&Handler{}).usageMetrics(context.Background()))
+}
+
+func TestWarmCacheWithoutRedis(t *testing.T) {
+	h := &Handler{config: config.DefaultConfig(), metricsPath: filepath.Join(t.TempDir(), "metrics.jsonl")}
+
+	warmed, err := h.WarmCache(context.Background(), "r3")
+	require.NoError(t, err)
+	assert.Zero(t, warmed)
+}
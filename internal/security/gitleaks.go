@@ -0,0 +1,379 @@
+package security
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LoadGitleaks reads the [[rules]] and global [allowlist] of a gitleaks
+// config: each rule's regex, secretGroup, and allowlist regexes and
+// stopwords. Rules without a regex (path-only) are skipped, and entropy and
+// keyword filters are ignored, so such rules match on their regex alone.
+// [extend] is ignored too; the built-in patterns stand in for gitleaks'
+// defaults.
+func LoadGitleaks(path string) ([]Rule, Allowlist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, Allowlist{}, err
+	}
+	rules, allow, err := parseGitleaks(string(data))
+	if err != nil {
+		return nil, Allowlist{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, allow, nil
+}
+
+func parseGitleaks(data string) ([]Rule, Allowlist, error) {
+	tables, err := parseTOML(data)
+	if err != nil {
+		return nil, Allowlist{}, err
+	}
+
+	var rules []Rule
+	var allow Allowlist
+	for _, t := range tables {
+		switch t.name {
+		case "rules":
+			id := t.str("id")
+			regex := t.str("regex")
+			if regex == "" {
+				continue // Path-only rule
+			}
+			rules = append(rules, Rule{Name: id, Pattern: regex, SecretGroup: int(t.integer("secretGroup"))})
+		case "rules.allowlist", "rules.allowlists":
+			if len(rules) == 0 {
+				continue
+			}
+			last := &rules[len(rules)-1]
+			last.Allow = append(last.Allow, t.strs("regexes")...)
+			last.Allow = append(last.Allow, quoteAll(t.strs("stopwords"))...)
+		case "allowlist", "allowlists":
+			allow.Values = append(allow.Values, t.strs("regexes")...)
+			allow.Values = append(allow.Values, quoteAll(t.strs("stopwords"))...)
+			allow.PathPatterns = append(allow.PathPatterns, t.strs("paths")...)
+		}
+	}
+	return rules, allow, nil
+}
+
+func quoteAll(words []string) []string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	return quoted
+}
+
+// tomlTable is one [table] or [[array table]] of a TOML document.
+type tomlTable struct {
+	name   string
+	values map[string]interface{} // string, int64, bool, or []interface{}
+}
+
+func (t tomlTable) str(key string) string {
+	s, _ := t.values[key].(string)
+	return s
+}
+
+func (t tomlTable) integer(key string) int64 {
+	n, _ := t.values[key].(int64)
+	return n
+}
+
+func (t tomlTable) strs(key string) []string {
+	items, _ := t.values[key].([]interface{})
+	var out []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// parseTOML parses the subset of TOML gitleaks configs use: tables, array
+// tables, and key/value pairs of strings (all four quotings), integers,
+// floats, booleans, and arrays. Top-level keys form a table named "".
+func parseTOML(data string) ([]tomlTable, error) {
+	p := &tomlParser{src: data, line: 1}
+	tables := []tomlTable{{values: make(map[string]interface{})}}
+	for {
+		p.skipSpace(true)
+		if p.done() {
+			return tables, nil
+		}
+		if p.peek() == '[' {
+			name, err := p.header()
+			if err != nil {
+				return nil, err
+			}
+			tables = append(tables, tomlTable{name: name, values: make(map[string]interface{})})
+		} else {
+			key, value, err := p.keyValue()
+			if err != nil {
+				return nil, err
+			}
+			tables[len(tables)-1].values[key] = value
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) done() bool { return p.pos >= len(p.src) }
+
+func (p *tomlParser) peek() byte { return p.src[p.pos] }
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips blanks and comments, and newlines too when multiline.
+func (p *tomlParser) skipSpace(multiline bool) {
+	for !p.done() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && multiline:
+			p.pos++
+			p.line++
+		case c == '#':
+			for !p.done() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace(false)
+	if p.done() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("unexpected %q", p.peek())
+	}
+	return nil
+}
+
+// header parses [name] or [[name]], returning the name.
+func (p *tomlParser) header() (string, error) {
+	end := strings.IndexByte(p.src[p.pos:], '\n')
+	if end < 0 {
+		end = len(p.src) - p.pos
+	}
+	line := p.src[p.pos : p.pos+end]
+	if i := strings.Index(line, "#"); i >= 0 {
+		line = line[:i]
+	}
+	line = strings.TrimSpace(line)
+	p.pos += end
+	name := strings.TrimSuffix(strings.TrimPrefix(line, "[["), "]]")
+	if name == line {
+		name = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+	}
+	if name == line || name == "" {
+		return "", p.errorf("invalid table header %q", line)
+	}
+	return strings.TrimSpace(name), nil
+}
+
+func (p *tomlParser) keyValue() (string, interface{}, error) {
+	var key string
+	if c := p.peek(); c == '"' || c == '\'' {
+		value, err := p.value()
+		if err != nil {
+			return "", nil, err
+		}
+		key, _ = value.(string)
+	} else {
+		start := p.pos
+		for !p.done() && isBareKey(p.peek()) {
+			p.pos++
+		}
+		key = p.src[start:p.pos]
+	}
+	p.skipSpace(false)
+	if key == "" || p.done() || p.peek() != '=' {
+		return "", nil, p.errorf("expected key = value")
+	}
+	p.pos++
+	p.skipSpace(false)
+	value, err := p.value()
+	return key, value, err
+}
+
+func isBareKey(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func (p *tomlParser) value() (interface{}, error) {
+	if p.done() {
+		return nil, p.errorf("missing value")
+	}
+	rest := p.src[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "'''"):
+		return p.multiline("'''", false)
+	case strings.HasPrefix(rest, `"""`):
+		return p.multiline(`"""`, true)
+	case rest[0] == '\'':
+		end := strings.IndexAny(rest[1:], "'\n")
+		if end < 0 || rest[1+end] != '\'' {
+			return nil, p.errorf("unterminated string")
+		}
+		p.pos += end + 2
+		return rest[1 : end+1], nil
+	case rest[0] == '"':
+		for i := 1; i < len(rest) && rest[i] != '\n'; i++ {
+			if rest[i] == '\\' {
+				i++
+				continue
+			}
+			if rest[i] == '"' {
+				p.pos += i + 1
+				return unescape(rest[1:i], p)
+			}
+		}
+		return nil, p.errorf("unterminated string")
+	case rest[0] == '[':
+		return p.array()
+	case rest[0] == '{':
+		return nil, p.errorf("inline tables are not supported")
+	}
+
+	end := strings.IndexAny(rest, " \t\r\n,]#")
+	if end < 0 {
+		end = len(rest)
+	}
+	raw := rest[:end]
+	p.pos += end
+	if raw == "true" || raw == "false" {
+		return raw == "true", nil
+	}
+	if n, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 0, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64); err == nil {
+		return f, nil
+	}
+	return nil, p.errorf("invalid value %q", raw)
+}
+
+// multiline parses a multiline literal or basic string, trimming a newline
+// right after the opening delimiter.
+func (p *tomlParser) multiline(delim string, escapes bool) (interface{}, error) {
+	p.pos += len(delim)
+	end := strings.Index(p.src[p.pos:], delim)
+	if end < 0 {
+		return nil, p.errorf("unterminated string")
+	}
+	// Up to two quotes may directly precede the closing delimiter
+	for end+len(delim) < len(p.src)-p.pos && p.src[p.pos+end+len(delim)] == delim[0] {
+		end++
+	}
+	body := p.src[p.pos : p.pos+end]
+	p.line += strings.Count(body, "\n")
+	p.pos += end + len(delim)
+	body = strings.TrimPrefix(strings.TrimPrefix(body, "\r"), "\n")
+	if !escapes {
+		return body, nil
+	}
+	return unescape(body, p)
+}
+
+// unescape resolves the escapes of a basic string. In a multiline one, a
+// backslash ending a line trims the newline and following whitespace.
+func unescape(s string, p *tomlParser) (interface{}, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return nil, p.errorf("invalid escape in %q", s)
+		}
+		switch c := s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(c)
+		case 'u', 'U':
+			digits := 4
+			if c == 'U' {
+				digits = 8
+			}
+			if i+digits >= len(s) {
+				return nil, p.errorf("invalid escape in %q", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+digits], 16, 32)
+			if err != nil {
+				return nil, p.errorf("invalid escape in %q", s)
+			}
+			b.WriteRune(rune(r))
+			i += digits
+		case ' ', '\t', '\r', '\n':
+			for i < len(s) && strings.IndexByte(" \t\r\n", s[i]) >= 0 {
+				i++
+			}
+			i--
+		default:
+			return nil, p.errorf("invalid escape in %q", s)
+		}
+	}
+	return b.String(), nil
+}
+
+func (p *tomlParser) array() (interface{}, error) {
+	p.pos++ // [
+	var items []interface{}
+	for {
+		p.skipSpace(true)
+		if p.done() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return items, nil
+		}
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		p.skipSpace(true)
+		if p.done() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != ']' {
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
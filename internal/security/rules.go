@@ -0,0 +1,151 @@
+package security
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Rule is an additional secret pattern, e.g. an internal token format.
+type Rule struct {
+	Name    string
+	Pattern string // Go regexp
+
+	// Redact replaces the match, expanding $1-style group references. Empty
+	// redacts SecretGroup of the match, or with no group the whole match as
+	// [REDACTED_<NAME>].
+	Redact      string
+	SecretGroup int
+
+	Allow []string // Regexps of matches this rule ignores
+}
+
+// Allowlist exempts known fake credentials, e.g. in test fixtures.
+type Allowlist struct {
+	Values       []string // Regexps; matches containing one are not secrets
+	Paths        []string // Doublestar globs of repo-relative files that are never redacted
+	PathPatterns []string // Regexps of repo-relative files that are never redacted (gitleaks)
+}
+
+// AddRules adds rules to the built-in patterns.
+func (d *SecretDetector) AddRules(rules []Rule) error {
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("secret rule %q: missing name", rule.Pattern)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("secret rule %s: %w", rule.Name, err)
+		}
+		if rule.SecretGroup < 0 || rule.SecretGroup > re.NumSubexp() {
+			return fmt.Errorf("secret rule %s: pattern has no group %d", rule.Name, rule.SecretGroup)
+		}
+		allow, err := compileAll(rule.Allow)
+		if err != nil {
+			return fmt.Errorf("secret rule %s allowlist: %w", rule.Name, err)
+		}
+		d.patterns = append(d.patterns, secretPattern{
+			name:   rule.Name,
+			regex:  re,
+			redact: ruleRedaction(re, rule),
+			allow:  allow,
+		})
+	}
+	return nil
+}
+
+// AddAllowlist exempts the allowlist's matches and files.
+func (d *SecretDetector) AddAllowlist(a Allowlist) error {
+	values, err := compileAll(a.Values)
+	if err != nil {
+		return fmt.Errorf("secret allowlist: %w", err)
+	}
+	for _, glob := range a.Paths {
+		if !doublestar.ValidatePattern(glob) {
+			return fmt.Errorf("secret allowlist: invalid path glob %q", glob)
+		}
+	}
+	pathRes, err := compileAll(a.PathPatterns)
+	if err != nil {
+		return fmt.Errorf("secret allowlist: %w", err)
+	}
+	d.allowValues = append(d.allowValues, values...)
+	d.allowPaths = append(d.allowPaths, a.Paths...)
+	d.allowPathRes = append(d.allowPathRes, pathRes...)
+	return nil
+}
+
+// PathAllowed reports whether the repo-relative file is allowlisted, so its
+// content is neither flagged nor redacted.
+func (d *SecretDetector) PathAllowed(path string) bool {
+	for _, glob := range d.allowPaths {
+		if ok, _ := doublestar.Match(glob, path); ok {
+			return true
+		}
+	}
+	for _, re := range d.allowPathRes {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed reports whether a match of pattern is a known fake credential.
+func (d *SecretDetector) allowed(pattern secretPattern, match string) bool {
+	for _, re := range d.allowValues {
+		if re.MatchString(match) {
+			return true
+		}
+	}
+	for _, re := range pattern.allow {
+		if re.MatchString(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleRedaction returns the redact function of rule, compiled to re.
+func ruleRedaction(re *regexp.Regexp, rule Rule) func(string) string {
+	switch {
+	case rule.Redact != "":
+		return func(match string) string {
+			loc := re.FindStringSubmatchIndex(match)
+			if loc == nil {
+				return rule.Redact
+			}
+			return string(re.ExpandString(nil, rule.Redact, match, loc))
+		}
+	case rule.SecretGroup > 0:
+		group := rule.SecretGroup
+		return func(match string) string {
+			loc := re.FindStringSubmatchIndex(match)
+			if loc == nil || loc[2*group] < 0 {
+				return "[REDACTED]"
+			}
+			return match[:loc[2*group]] + "[REDACTED]" + match[loc[2*group+1]:]
+		}
+	default:
+		placeholder := "[REDACTED_" + strings.ToUpper(nonWord.ReplaceAllString(rule.Name, "_")) + "]"
+		return func(string) string {
+			return placeholder
+		}
+	}
+}
+
+var nonWord = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
@@ -19,12 +19,17 @@ type Secret struct {
 type SecretDetector struct {
 	patterns     []secretPattern
 	placeholders []string
+
+	allowValues  []*regexp.Regexp // Matches known to be fake credentials
+	allowPaths   []string         // Globs of files never redacted
+	allowPathRes []*regexp.Regexp // Gitleaks-style path regexps of files never redacted
 }
 
 type secretPattern struct {
 	name   string
 	regex  *regexp.Regexp
 	redact func(match string) string
+	allow  []*regexp.Regexp // The rule's own allowlist
 }
 
 // NewSecretDetector creates a new detector with default patterns.
@@ -98,6 +103,9 @@ func (d *SecretDetector) Detect(content string) []Secret {
 		for _, pattern := range d.patterns {
 			matches := pattern.regex.FindAllStringIndex(line, -1)
 			for _, match := range matches {
+				if d.allowed(pattern, line[match[0]:match[1]]) {
+					continue
+				}
 				secrets = append(secrets, Secret{
 					Type:     pattern.name,
 					Line:     lineNum + 1,
@@ -120,7 +128,12 @@ func (d *SecretDetector) Redact(content string, secrets []Secret) string {
 	result := content
 
 	for _, pattern := range d.patterns {
-		result = pattern.regex.ReplaceAllStringFunc(result, pattern.redact)
+		result = pattern.regex.ReplaceAllStringFunc(result, func(match string) string {
+			if d.allowed(pattern, match) {
+				return match
+			}
+			return pattern.redact(match)
+		})
 	}
 
 	return result
@@ -4,9 +4,12 @@ package store
 import (
 	"context"
 	"fmt"
+	"path"
+	"slices"
 
 	"github.com/qdrant/go-client/qdrant"
 	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/parser"
 )
 
 // QdrantStore handles vector storage in Qdrant.
@@ -31,24 +34,63 @@ func (s *QdrantStore) Close() error {
 	return s.client.Close()
 }
 
-// EnsureCollection creates collection if it doesn't exist.
+// LexicalField is the payload field holding chunk.LexicalTerms, full-text
+// indexed for keyword search. Match it with ScrollByText.
+const LexicalField = "lexical_terms"
+
+// EnsureCollection creates collection if it doesn't exist, and the keyword
+// index on LexicalField if it is missing (collections created before it
+// get it on their next index run).
 func (s *QdrantStore) EnsureCollection(ctx context.Context, name string, vectorSize int) error {
 	exists, err := s.client.CollectionExists(ctx, name)
 	if err != nil {
 		return err
 	}
 
-	if exists {
-		return nil
+	if !exists {
+		err := s.client.CreateCollection(ctx, &qdrant.CreateCollection{
+			CollectionName: name,
+			VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
+				Size:     uint64(vectorSize),
+				Distance: qdrant.Distance_Cosine,
+			}),
+		})
+		if err != nil {
+			return err
+		}
 	}
 
-	return s.client.CreateCollection(ctx, &qdrant.CreateCollection{
-		CollectionName: name,
-		VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
-			Size:     uint64(vectorSize),
-			Distance: qdrant.Distance_Cosine,
+	return s.ensureLexicalIndex(ctx, name)
+}
+
+// ensureLexicalIndex creates the full-text index on LexicalField. Terms are
+// already lowercased and deduplicated, so whitespace tokenizing suffices.
+func (s *QdrantStore) ensureLexicalIndex(ctx context.Context, collection string) error {
+	info, err := s.client.GetCollectionInfo(ctx, collection)
+	if err != nil {
+		return fmt.Errorf("get collection info: %w", err)
+	}
+	if _, ok := info.GetPayloadSchema()[LexicalField]; ok {
+		return nil
+	}
+	_, err = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+		CollectionName: collection,
+		FieldName:      LexicalField,
+		FieldType:      qdrant.FieldType_FieldTypeText.Enum(),
+		FieldIndexParams: qdrant.NewPayloadIndexParamsText(&qdrant.TextIndexParams{
+			Tokenizer: qdrant.TokenizerType_Whitespace,
+			Lowercase: qdrant.PtrOf(true),
 		}),
 	})
+	if err != nil {
+		return fmt.Errorf("create %s index: %w", LexicalField, err)
+	}
+	return nil
+}
+
+// CollectionExists reports whether a collection has been created.
+func (s *QdrantStore) CollectionExists(ctx context.Context, name string) (bool, error) {
+	return s.client.CollectionExists(ctx, name)
 }
 
 // DeleteCollection removes a collection.
@@ -62,25 +104,52 @@ func (s *QdrantStore) UpsertChunks(ctx context.Context, collection string, chunk
 
 	for i, c := range chunks {
 		payload := map[string]interface{}{
-			"repo":             c.Repo,
-			"file_path":        c.FilePath,
-			"start_line":       c.StartLine,
-			"end_line":         c.EndLine,
-			"type":             string(c.Type),
-			"kind":             c.Kind,
-			"module_path":      c.ModulePath,
-			"module_root":      c.ModuleRoot,
-			"submodule":        c.Submodule,
-			"symbol_name":      c.SymbolName,
-			"heading_path":     c.HeadingPath,
-			"content":          c.Content,
-			"context_header":   c.ContextHeader,
-			"signature":        c.Signature,
-			"docstring":        c.Docstring,
-			"is_test":          c.IsTest,
-			"retrieval_weight": c.RetrievalWeight,
-			"has_secrets":      c.HasSecrets,
-			"follows_pattern":  c.FollowsPattern,
+			"repo":              c.Repo,
+			"file_path":         c.FilePath,
+			"start_line":        c.StartLine,
+			"end_line":          c.EndLine,
+			"type":              string(c.Type),
+			"kind":              c.Kind,
+			"module_path":       c.ModulePath,
+			"module_root":       c.ModuleRoot,
+			"submodule":         c.Submodule,
+			"symbol_name":       c.SymbolName,
+			"heading_path":      c.HeadingPath,
+			"content":           c.Content,
+			"context_header":    c.ContextHeader,
+			"signature":         c.Signature,
+			"docstring":         c.Docstring,
+			"summary":           c.Summary,
+			"is_test":           c.IsTest,
+			"retrieval_weight":  c.RetrievalWeight,
+			"has_secrets":       c.HasSecrets,
+			"follows_pattern":   c.FollowsPattern,
+			"last_author":       c.LastAuthor,
+			"last_author_email": c.LastAuthorEmail,
+			"last_commit":       c.LastCommit,
+			LexicalField:        chunk.LexicalTerms(c),
+			"dirs":              stringsPayload(ancestorDirs(c.FilePath)),
+		}
+		if lang, ok := parser.DetectLanguage(c.FilePath); ok {
+			payload["language"] = string(lang)
+		}
+		if len(c.Duplicates) > 0 {
+			payload["duplicates"] = DuplicatesPayload(c.Duplicates)
+		}
+		if len(c.Owners) > 0 {
+			payload["owners"] = stringsPayload(c.Owners)
+		}
+		if c.License != "" {
+			payload["license"] = c.License
+		}
+		if c.Branch != "" {
+			payload["branch"] = c.Branch
+		}
+		if c.Workspace != "" {
+			payload["workspace"] = c.Workspace
+		}
+		if len(c.Metadata) > 0 {
+			payload["metadata"] = metadataPayload(c.Metadata)
 		}
 
 		points[i] = &qdrant.PointStruct{
@@ -148,6 +217,23 @@ func (s *QdrantStore) SearchByFilter(ctx context.Context, collection string, fil
 	return chunks, nil
 }
 
+// GetChunk returns the chunk stored under id, or nil if there is none.
+func (s *QdrantStore) GetChunk(ctx context.Context, collection, id string) (*chunk.Chunk, error) {
+	points, err := s.client.Get(ctx, &qdrant.GetPoints{
+		CollectionName: collection,
+		Ids:            []*qdrant.PointId{qdrant.NewID(id)},
+		WithPayload:    qdrant.NewWithPayload(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, nil
+	}
+	c := payloadToChunk(points[0].Id.GetUuid(), points[0].Payload)
+	return &c, nil
+}
+
 // CollectionInfo contains collection metadata.
 type CollectionInfo struct {
 	PointsCount int64
@@ -183,11 +269,37 @@ func (s *QdrantStore) CollectionInfo(ctx context.Context, name string) (*Collect
 	}, nil
 }
 
+// MustNot is the filter key of a nested filter map whose conditions each
+// exclude the points they match.
+const MustNot = "$must_not"
+
+// TextTerms is a filter value matching a full-text field once per term:
+// every term must be present, or under MustNot, none may be.
+type TextTerms []string
+
+// Empty is a filter value matching points whose field is missing, null, or
+// an empty list; under MustNot, points where it holds a value.
+type Empty struct{}
+
+// buildFilter turns a filter map into Qdrant conditions. Values match a
+// keyword (string), any of several keywords ([]string), a bool, full-text
+// terms (TextTerms), or no value (Empty); MustNot nests another map of
+// exclusions.
 func buildFilter(filter map[string]interface{}) *qdrant.Filter {
-	var must []*qdrant.Condition
+	var must, mustNot []*qdrant.Condition
 
 	for key, value := range filter {
 		switch v := value.(type) {
+		case map[string]interface{}:
+			if key == MustNot {
+				mustNot = append(mustNot, buildFilter(v).Must...)
+			}
+		case TextTerms:
+			for _, term := range v {
+				must = append(must, qdrant.NewMatchText(key, term))
+			}
+		case Empty:
+			must = append(must, qdrant.NewIsEmpty(key))
 		case string:
 			must = append(must, &qdrant.Condition{
 				ConditionOneOf: &qdrant.Condition_Field{
@@ -199,6 +311,8 @@ func buildFilter(filter map[string]interface{}) *qdrant.Filter {
 					},
 				},
 			})
+		case []string:
+			must = append(must, qdrant.NewMatchKeywords(key, v...))
 		case bool:
 			must = append(must, &qdrant.Condition{
 				ConditionOneOf: &qdrant.Condition_Field{
@@ -213,7 +327,7 @@ func buildFilter(filter map[string]interface{}) *qdrant.Filter {
 		}
 	}
 
-	return &qdrant.Filter{Must: must}
+	return &qdrant.Filter{Must: must, MustNot: mustNot}
 }
 
 func payloadToChunk(id string, payload map[string]*qdrant.Value) chunk.Chunk {
@@ -259,9 +373,280 @@ func payloadToChunk(id string, payload map[string]*qdrant.Value) chunk.Chunk {
 		ContextHeader:   getString("context_header"),
 		Signature:       getString("signature"),
 		Docstring:       getString("docstring"),
+		Summary:         getString("summary"),
 		IsTest:          getBool("is_test"),
 		RetrievalWeight: getFloat("retrieval_weight"),
 		HasSecrets:      getBool("has_secrets"),
 		FollowsPattern:  getString("follows_pattern"),
+		LastAuthor:      getString("last_author"),
+		LastAuthorEmail: getString("last_author_email"),
+		LastCommit:      getString("last_commit"),
+		Duplicates:      payloadLocations(payload["duplicates"]),
+		Owners:          payloadStrings(payload["owners"]),
+		License:         getString("license"),
+		Branch:          getString("branch"),
+		Workspace:       getString("workspace"),
+		Metadata:        payloadMetadata(payload["metadata"]),
+	}
+}
+
+// ancestorDirs lists the directories containing a repo-relative path,
+// outermost first, for the "dirs" payload that path filters match.
+func ancestorDirs(filePath string) []string {
+	var dirs []string
+	for dir := path.Dir(filePath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	slices.Reverse(dirs)
+	return dirs
+}
+
+func stringsPayload(strs []string) []interface{} {
+	values := make([]interface{}, len(strs))
+	for i, s := range strs {
+		values[i] = s
+	}
+	return values
+}
+
+func payloadStrings(v *qdrant.Value) []string {
+	values := v.GetListValue().GetValues()
+	if len(values) == 0 {
+		return nil
+	}
+	strs := make([]string, len(values))
+	for i, value := range values {
+		strs[i] = value.GetStringValue()
+	}
+	return strs
+}
+
+func metadataPayload(metadata map[string]string) map[string]interface{} {
+	values := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		values[k] = v
+	}
+	return values
+}
+
+func payloadMetadata(v *qdrant.Value) map[string]string {
+	fields := v.GetStructValue().GetFields()
+	if len(fields) == 0 {
+		return nil
+	}
+	metadata := make(map[string]string, len(fields))
+	for k, field := range fields {
+		metadata[k] = field.GetStringValue()
+	}
+	return metadata
+}
+
+// DuplicatesPayload converts locations to the "duplicates" payload value.
+func DuplicatesPayload(locations []chunk.Location) []interface{} {
+	values := make([]interface{}, len(locations))
+	for i, loc := range locations {
+		values[i] = map[string]interface{}{
+			"file_path":  loc.FilePath,
+			"start_line": loc.StartLine,
+			"end_line":   loc.EndLine,
+		}
+	}
+	return values
+}
+
+func payloadLocations(v *qdrant.Value) []chunk.Location {
+	values := v.GetListValue().GetValues()
+	if len(values) == 0 {
+		return nil
+	}
+	locations := make([]chunk.Location, 0, len(values))
+	for _, value := range values {
+		fields := value.GetStructValue().GetFields()
+		locations = append(locations, chunk.Location{
+			FilePath:  fields["file_path"].GetStringValue(),
+			StartLine: int(fields["start_line"].GetIntegerValue()),
+			EndLine:   int(fields["end_line"].GetIntegerValue()),
+		})
+	}
+	return locations
+}
+
+// ScrollChunks pages through chunks matching filter, including their vectors.
+// Pass an empty offset to start from the beginning; the returned offset is
+// empty once the last page has been read.
+func (s *QdrantStore) ScrollChunks(ctx context.Context, collection string, filter map[string]interface{}, limit int, offset string) ([]chunk.Chunk, string, error) {
+	req := &qdrant.ScrollPoints{
+		CollectionName: collection,
+		Filter:         buildFilter(filter),
+		Limit:          qdrant.PtrOf(uint32(limit)),
+		WithPayload:    qdrant.NewWithPayload(true),
+		WithVectors:    qdrant.NewWithVectors(true),
+	}
+	if offset != "" {
+		req.Offset = qdrant.NewID(offset)
+	}
+
+	results, next, err := s.client.ScrollAndOffset(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	chunks := make([]chunk.Chunk, len(results))
+	for i, r := range results {
+		chunks[i] = payloadToChunk(r.Id.GetUuid(), r.Payload)
+		chunks[i].Vector = vectorData(r.GetVectors())
+	}
+
+	return chunks, next.GetUuid(), nil
+}
+
+// ScrollByText pages through chunks matching filter whose payload field
+// contains text. Without a full-text index on the field Qdrant treats this as
+// a case-sensitive substring match. An empty text applies only filter. Pass an
+// empty offset to start; the returned offset is empty after the last page.
+func (s *QdrantStore) ScrollByText(ctx context.Context, collection, field, text string, filter map[string]interface{}, limit int, offset string) ([]chunk.Chunk, string, error) {
+	qdrantFilter := buildFilter(filter)
+	if text != "" {
+		qdrantFilter.Must = append(qdrantFilter.Must, qdrant.NewMatchText(field, text))
+	}
+
+	req := &qdrant.ScrollPoints{
+		CollectionName: collection,
+		Filter:         qdrantFilter,
+		Limit:          qdrant.PtrOf(uint32(limit)),
+		WithPayload:    qdrant.NewWithPayload(true),
+	}
+	if offset != "" {
+		req.Offset = qdrant.NewID(offset)
+	}
+
+	results, next, err := s.client.ScrollAndOffset(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	chunks := make([]chunk.Chunk, len(results))
+	for i, r := range results {
+		chunks[i] = payloadToChunk(r.Id.GetUuid(), r.Payload)
+	}
+
+	return chunks, next.GetUuid(), nil
+}
+
+// ListPointIDs returns the IDs of all points matching filter.
+func (s *QdrantStore) ListPointIDs(ctx context.Context, collection string, filter map[string]interface{}) ([]string, error) {
+	var ids []string
+	var offset *qdrant.PointId
+
+	for {
+		results, next, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: collection,
+			Filter:         buildFilter(filter),
+			Limit:          qdrant.PtrOf(uint32(1000)),
+			Offset:         offset,
+			WithPayload:    qdrant.NewWithPayload(false),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range results {
+			ids = append(ids, r.Id.GetUuid())
+		}
+
+		if next == nil {
+			return ids, nil
+		}
+		offset = next
+	}
+}
+
+// CountByField returns how many points matching filter have each value of a
+// string payload field. Points without the field are not counted.
+func (s *QdrantStore) CountByField(ctx context.Context, collection, field string, filter map[string]interface{}) (map[string]int, error) {
+	counts := make(map[string]int)
+	var offset *qdrant.PointId
+
+	for {
+		results, next, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: collection,
+			Filter:         buildFilter(filter),
+			Limit:          qdrant.PtrOf(uint32(1000)),
+			Offset:         offset,
+			WithPayload:    qdrant.NewWithPayloadInclude(field),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range results {
+			if v := r.Payload[field].GetStringValue(); v != "" {
+				counts[v]++
+			}
+		}
+
+		if next == nil {
+			return counts, nil
+		}
+		offset = next
+	}
+}
+
+// DeletePoints removes points by ID.
+func (s *QdrantStore) DeletePoints(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pointIDs := make([]*qdrant.PointId, len(ids))
+	for i, id := range ids {
+		pointIDs[i] = qdrant.NewID(id)
+	}
+
+	_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: collection,
+		Points:         qdrant.NewPointsSelector(pointIDs...),
+	})
+	return err
+}
+
+// DeleteByFilter removes all points matching filter.
+func (s *QdrantStore) DeleteByFilter(ctx context.Context, collection string, filter map[string]interface{}) error {
+	_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: collection,
+		Points:         qdrant.NewPointsSelectorFilter(buildFilter(filter)),
+	})
+	return err
+}
+
+// SetPayloadByFilter overwrites the given payload fields on all points
+// matching filter, leaving vectors and other fields untouched.
+func (s *QdrantStore) SetPayloadByFilter(ctx context.Context, collection string, filter, payload map[string]interface{}) error {
+	_, err := s.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: collection,
+		Payload:        qdrant.NewValueMap(payload),
+		PointsSelector: qdrant.NewPointsSelectorFilter(buildFilter(filter)),
+	})
+	return err
+}
+
+// SetPayload overwrites the given payload fields on one point.
+func (s *QdrantStore) SetPayload(ctx context.Context, collection, id string, payload map[string]interface{}) error {
+	_, err := s.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: collection,
+		Payload:        qdrant.NewValueMap(payload),
+		PointsSelector: qdrant.NewPointsSelector(qdrant.NewID(id)),
+	})
+	return err
+}
+
+func vectorData(vectors *qdrant.VectorsOutput) []float32 {
+	v := vectors.GetVector()
+	if v == nil {
+		return nil
+	}
+	if dense := v.GetDense(); dense != nil {
+		return dense.GetData()
 	}
+	return v.GetData()
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/qdrant/go-client/qdrant"
 	"github.com/randalmurphal/code-indexer/internal/chunk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -162,10 +163,18 @@ func TestQdrantStoreEnsureCollectionIdempotent(t *testing.T) {
 	collectionName := "test_idempotent"
 	_ = store.DeleteCollection(ctx, collectionName)
 
+	exists, err := store.CollectionExists(ctx, collectionName)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
 	// Create collection twice - should not error
 	err = store.EnsureCollection(ctx, collectionName, 1024)
 	require.NoError(t, err)
 
+	exists, err = store.CollectionExists(ctx, collectionName)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
 	err = store.EnsureCollection(ctx, collectionName, 1024)
 	require.NoError(t, err)
 
@@ -173,3 +182,112 @@ func TestQdrantStoreEnsureCollectionIdempotent(t *testing.T) {
 	err = store.DeleteCollection(ctx, collectionName)
 	require.NoError(t, err)
 }
+
+func TestQdrantStoreScrollByText(t *testing.T) {
+	if os.Getenv("QDRANT_URL") == "" {
+		t.Skip("QDRANT_URL not set, skipping integration test")
+	}
+
+	ctx := context.Background()
+	store, err := NewQdrantStore(os.Getenv("QDRANT_URL"))
+	require.NoError(t, err)
+
+	collectionName := "test_text_chunks"
+	_ = store.DeleteCollection(ctx, collectionName)
+
+	err = store.EnsureCollection(ctx, collectionName, 1024)
+	require.NoError(t, err)
+
+	chunks := []chunk.Chunk{
+		{ID: "chunk-001", Repo: "test-repo", FilePath: "a.py", Content: `key = os.environ["VOYAGE_API_KEY"]`, Vector: make([]float32, 1024)},
+		{ID: "chunk-002", Repo: "test-repo", FilePath: "b.py", Content: "def unrelated(): pass", Vector: make([]float32, 1024)},
+	}
+	err = store.UpsertChunks(ctx, collectionName, chunks)
+	require.NoError(t, err)
+
+	results, next, err := store.ScrollByText(ctx, collectionName, "content", "VOYAGE_API_KEY", map[string]interface{}{"repo": "test-repo"}, 10, "")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "a.py", results[0].FilePath)
+	assert.Empty(t, next)
+
+	results, _, err = store.ScrollByText(ctx, collectionName, "content", "", map[string]interface{}{"repo": "test-repo"}, 10, "")
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// Clean up
+	err = store.DeleteCollection(ctx, collectionName)
+	require.NoError(t, err)
+}
+
+func TestDuplicatesPayloadRoundTrip(t *testing.T) {
+	locations := []chunk.Location{
+		{FilePath: "vendor/util.py", StartLine: 10, EndLine: 12},
+		{FilePath: "third_party/util.py", StartLine: 5, EndLine: 7},
+	}
+
+	payload := qdrant.NewValueMap(map[string]any{"duplicates": DuplicatesPayload(locations)})
+	assert.Equal(t, locations, payloadLocations(payload["duplicates"]))
+	assert.Nil(t, payloadLocations(payload["missing"]))
+}
+
+func TestMetadataPayloadRoundTrip(t *testing.T) {
+	metadata := map[string]string{"service": "billing", "tier": "1"}
+
+	payload := qdrant.NewValueMap(map[string]any{"metadata": metadataPayload(metadata)})
+	assert.Equal(t, metadata, payloadMetadata(payload["metadata"]))
+	assert.Nil(t, payloadMetadata(payload["missing"]))
+}
+
+func TestOwnersPayloadRoundTrip(t *testing.T) {
+	owners := []string{"@acme/payments", "ops@example.com"}
+
+	payload := qdrant.NewValueMap(map[string]any{"owners": stringsPayload(owners)})
+	assert.Equal(t, owners, payloadStrings(payload["owners"]))
+	assert.Nil(t, payloadStrings(payload["missing"]))
+}
+
+func TestAncestorDirs(t *testing.T) {
+	assert.Equal(t, []string{"api", "api/handlers"}, ancestorDirs("api/handlers/user.py"))
+	assert.Nil(t, ancestorDirs("setup.py"))
+}
+
+func TestBuildFilterKeywordList(t *testing.T) {
+	filter := buildFilter(map[string]interface{}{"kind": []string{"class", "class_summary"}})
+
+	require.Len(t, filter.Must, 1)
+	field := filter.Must[0].GetField()
+	assert.Equal(t, "kind", field.Key)
+	assert.Equal(t, []string{"class", "class_summary"}, field.Match.GetKeywords().GetStrings())
+}
+
+func TestBuildFilterMustNot(t *testing.T) {
+	filter := buildFilter(map[string]interface{}{
+		"repo": "shop",
+		MustNot: map[string]interface{}{
+			"module_path": []string{"shop.legacy"},
+			LexicalField:  TextTerms{"deprecated", "legacy"},
+		},
+	})
+
+	require.Len(t, filter.Must, 1)
+	assert.Equal(t, "repo", filter.Must[0].GetField().Key)
+	require.Len(t, filter.MustNot, 3)
+	var texts []string
+	for _, cond := range filter.MustNot {
+		if text := cond.GetField().Match.GetText(); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	assert.ElementsMatch(t, []string{"deprecated", "legacy"}, texts)
+}
+
+func TestBuildFilterEmpty(t *testing.T) {
+	filter := buildFilter(map[string]interface{}{
+		"repo":  "shop",
+		MustNot: map[string]interface{}{"duplicates": Empty{}},
+	})
+
+	require.Len(t, filter.MustNot, 1)
+	assert.Equal(t, "duplicates", filter.MustNot[0].GetIsEmpty().GetKey())
+}
@@ -0,0 +1,152 @@
+// Package summarize asks a chat completions endpoint for natural-language
+// summaries of code chunks at index time.
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/security"
+)
+
+const systemPrompt = "You summarize source code for a code search index. " +
+	"Answer with one plain-text paragraph of at most four sentences describing what the code does, " +
+	"its responsibilities, and the concepts it deals with. Do not repeat the code or use markdown."
+
+// maxSummaryTokens bounds each completion; a paragraph needs far fewer.
+const maxSummaryTokens = 256
+
+// Client summarizes chunks with an OpenAI-compatible chat completions API.
+type Client struct {
+	url      string
+	model    string
+	apiKey   string
+	minLines int
+	client   *http.Client
+	secrets  *security.SecretDetector
+}
+
+// NewClient creates a client from the summaries config, reading the API key
+// from cfg.APIKeyEnv. Unset fields fall back to the config defaults.
+func NewClient(cfg config.SummariesConfig) (*Client, error) {
+	defaults := config.DefaultConfig().Summaries
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("summaries.model is required")
+	}
+	if cfg.URL == "" {
+		cfg.URL = defaults.URL
+	}
+	if cfg.MinLines <= 0 {
+		cfg.MinLines = defaults.MinLines
+	}
+	if cfg.TimeoutSeconds <= 0 {
+		cfg.TimeoutSeconds = defaults.TimeoutSeconds
+	}
+	var apiKey string
+	if cfg.APIKeyEnv != "" {
+		apiKey = os.Getenv(cfg.APIKeyEnv)
+	}
+	return &Client{
+		url:      cfg.URL,
+		model:    cfg.Model,
+		apiKey:   apiKey,
+		minLines: cfg.MinLines,
+		client:   &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		secrets:  security.NewSecretDetector(),
+	}, nil
+}
+
+// Wants reports whether c is a symbol large enough to summarize.
+func (c *Client) Wants(ch chunk.Chunk) bool {
+	if ch.Type != chunk.ChunkTypeCode || ch.SymbolName == "" {
+		return false
+	}
+	switch ch.Kind {
+	case "function", "method", "class", "class_summary":
+		return ch.EndLine-ch.StartLine+1 >= c.minLines
+	}
+	return false
+}
+
+type chatRequest struct {
+	Model     string        `json:"model"`
+	Messages  []chatMessage `json:"messages"`
+	MaxTokens int           `json:"max_tokens"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Summarize returns a one-paragraph description of the chunk. Secrets are
+// redacted before the code leaves the process.
+func (c *Client) Summarize(ctx context.Context, ch chunk.Chunk) (string, error) {
+	prompt := fmt.Sprintf("%s %s in %s:\n\n", ch.Kind, ch.SymbolName, ch.FilePath)
+	if ch.ContextHeader != "" {
+		prompt += ch.ContextHeader + "\n"
+	}
+	prompt += ch.Content
+	if found := c.secrets.Detect(prompt); len(found) > 0 {
+		prompt = c.secrets.Redact(prompt, found)
+	}
+
+	body, err := json.Marshal(chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens: maxSummaryTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("summaries API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result chatResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("unmarshal response: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("summaries API returned no choices")
+	}
+	return strings.TrimSpace(result.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,83 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/randalmurphal/code-indexer/internal/chunk"
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSummarize(t *testing.T) {
+	var got chatRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"  Retries failed uploads with backoff.  "}}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_SUMMARIES_KEY", "sk-test")
+	client, err := NewClient(config.SummariesConfig{URL: server.URL, Model: "small-model", APIKeyEnv: "TEST_SUMMARIES_KEY"})
+	require.NoError(t, err)
+
+	summary, err := client.Summarize(context.Background(), chunk.Chunk{
+		FilePath:   "upload.py",
+		Kind:       "function",
+		SymbolName: "upload",
+		Content:    "def upload():\n    api_key = \"sk9f8e7d6c5b4a3f2e1d0c9b8a\"\n",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Retries failed uploads with backoff.", summary)
+	assert.Equal(t, "Bearer sk-test", auth)
+	assert.Equal(t, "small-model", got.Model)
+	require.Len(t, got.Messages, 2)
+	assert.Contains(t, got.Messages[1].Content, "function upload in upload.py")
+	assert.NotContains(t, got.Messages[1].Content, "sk9f8e7d6c5b4a3f2e1d0c9b8a", "secrets are redacted before sending")
+}
+
+func TestClientSummarizeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"overloaded"}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(config.SummariesConfig{URL: server.URL, Model: "m"})
+	require.NoError(t, err)
+	_, err = client.Summarize(context.Background(), chunk.Chunk{Content: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 503")
+}
+
+func TestNewClientRequiresModel(t *testing.T) {
+	_, err := NewClient(config.SummariesConfig{Enabled: true})
+	assert.Error(t, err)
+}
+
+func TestClientWants(t *testing.T) {
+	client, err := NewClient(config.SummariesConfig{Model: "m", MinLines: 10})
+	require.NoError(t, err)
+
+	large := chunk.Chunk{Type: chunk.ChunkTypeCode, Kind: "function", SymbolName: "f", StartLine: 1, EndLine: 10}
+	assert.True(t, client.Wants(large))
+
+	small := large
+	small.EndLine = 9
+	assert.False(t, client.Wants(small))
+
+	summary := large
+	summary.Kind = chunk.KindFileSummary
+	summary.SymbolName = ""
+	assert.False(t, client.Wants(summary))
+
+	doc := large
+	doc.Type = chunk.ChunkTypeDoc
+	assert.False(t, client.Wants(doc))
+}
@@ -0,0 +1,175 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	stdsync "sync"
+	"time"
+)
+
+// RepoStatus is what the daemon last saw of a repo.
+type RepoStatus struct {
+	Name        string        `json:"name"`
+	Path        string        `json:"path"`
+	Branch      string        `json:"branch,omitempty"`
+	Head        string        `json:"head,omitempty"`        // Last HEAD synced
+	Syncing     bool          `json:"syncing"`               // A check or sync is running
+	Queued      bool          `json:"queued"`                // A forced sync is waiting
+	Paused      bool          `json:"paused"`                // In the pause file; checks are skipped
+	NextCheck   time.Time     `json:"next_check,omitzero"`   // Scheduled by the interval daemon
+	LastCheck   time.Time     `json:"last_check,omitzero"`   // Last HEAD comparison, changed or not
+	LastSync    time.Time     `json:"last_sync,omitzero"`    // Last completed index run
+	Duration    time.Duration `json:"duration_ns,omitempty"` // Of the last index run
+	Files       int           `json:"files"`                 // Processed by the last index run
+	Chunks      int           `json:"chunks"`                // Created by the last index run
+	LastError   string        `json:"last_error,omitempty"`  // Of the last check; cleared by a good one
+	LastErrorAt time.Time     `json:"last_error_at,omitzero"`
+
+	Failures     int       `json:"failures"`               // Consecutive failed checks
+	BackoffUntil time.Time `json:"backoff_until,omitzero"` // Scheduled checks wait until then (SetAlerts)
+
+	LatestHead string    `json:"latest_head,omitempty"` // HEAD at the last check; differs from Head until synced
+	CommitAt   time.Time `json:"commit_at,omitzero"`    // Commit time of LatestHead
+	IndexLagS  float64   `json:"index_lag_s"`           // IndexLag when the status was taken
+}
+
+// daemonState is the daemon's status shared with admin requests, which are
+// served on other goroutines than the sync loop.
+type daemonState struct {
+	mu       stdsync.Mutex
+	repos    map[string]*RepoStatus
+	forced   map[string]bool           // Repos whose sync was requested
+	locks    map[string]*stdsync.Mutex // Held while a job of the repo is queued or running
+	wake     chan struct{}             // Signals forced syncs; capacity 1
+	finished chan struct{}             // Signals a job finished, freeing its repo; capacity 1
+	saving   stdsync.Mutex             // Serializes state file writes (saveState)
+}
+
+func newDaemonState() *daemonState {
+	return &daemonState{
+		repos:    make(map[string]*RepoStatus),
+		forced:   make(map[string]bool),
+		locks:    make(map[string]*stdsync.Mutex),
+		wake:     make(chan struct{}, 1),
+		finished: make(chan struct{}, 1),
+	}
+}
+
+// update changes repo's status under the lock.
+func (d *Daemon) update(repo RepoWatch, change func(s *RepoStatus)) {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	s, ok := d.state.repos[repo.Name]
+	if !ok {
+		s = &RepoStatus{Name: repo.Name, Path: repo.Path}
+		d.state.repos[repo.Name] = s
+	}
+	change(s)
+}
+
+// Status returns every repo's status in watch order.
+func (d *Daemon) Status() []RepoStatus {
+	pauses := Pauses{}
+	if d.pausePath != "" {
+		if p, err := LoadPauses(d.pausePath); err == nil {
+			pauses = p
+		}
+	}
+	now := time.Now()
+
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	out := make([]RepoStatus, 0, len(d.repos))
+	for _, repo := range d.repos {
+		s := RepoStatus{Name: repo.Name, Path: repo.Path}
+		if cur, ok := d.state.repos[repo.Name]; ok {
+			s = *cur
+		}
+		s.Queued = d.state.forced[repo.Name]
+		s.Paused = pauses.Paused(repo.Name, now)
+		s.IndexLagS = s.IndexLag(now).Seconds()
+		out = append(out, s)
+	}
+	return out
+}
+
+// RequestSync queues a sync of the named repo that reindexes it even if
+// its HEAD did not change. Requests for a repo already queued coalesce.
+func (d *Daemon) RequestSync(name string) error {
+	if _, ok := d.repo(name); !ok {
+		return fmt.Errorf("repo %q is not watched", name)
+	}
+	d.state.mu.Lock()
+	d.state.forced[name] = true
+	d.state.mu.Unlock()
+	select {
+	case d.state.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// takeForced returns the repos whose sync was requested, clearing them.
+func (d *Daemon) takeForced() []RepoWatch {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	var repos []RepoWatch
+	for _, repo := range d.repos {
+		if d.state.forced[repo.Name] {
+			repos = append(repos, repo)
+			delete(d.state.forced, repo.Name)
+		}
+	}
+	return repos
+}
+
+func (d *Daemon) repo(name string) (RepoWatch, bool) {
+	for _, repo := range d.repos {
+		if repo.Name == name {
+			return repo, true
+		}
+	}
+	return RepoWatch{}, false
+}
+
+// AdminHandler serves the daemon's admin endpoints:
+//
+//	GET  /healthz          200 while the daemon runs
+//	GET  /status           every repo's RepoStatus
+//	GET  /metrics          freshness gauges in the Prometheus text format
+//	POST /sync-now/{repo}  queue a forced sync (202; 404 for unwatched repos)
+//
+// It has no authentication; serve it on a loopback or otherwise private
+// address.
+func (d *Daemon) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"repos": d.Status()})
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writePrometheus(w, d.Status(), time.Now())
+	})
+	mux.HandleFunc("POST /sync-now/{repo}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("repo")
+		if err := d.RequestSync(name); err != nil {
+			writeAdminJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		d.logger.Info("sync requested", "repo", name, "remote", r.RemoteAddr)
+		writeAdminJSON(w, http.StatusAccepted, map[string]string{"status": "queued", "repo": name})
+	})
+	return mux
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
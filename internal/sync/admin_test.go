@@ -0,0 +1,53 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	notGit := RepoWatch{Name: "broken", Path: t.TempDir()}
+	d := NewDaemon([]RepoWatch{notGit, {Name: "idle", Path: t.TempDir()}}, 0, nil, logger)
+	require.Error(t, d.syncRepo(context.Background(), nil, notGit))
+	admin := d.AdminHandler()
+
+	rec := httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/sync-now/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/sync-now/idle", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var status struct {
+		Repos []RepoStatus `json:"repos"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	require.Len(t, status.Repos, 2, "repos never checked are listed too")
+	assert.Equal(t, "broken", status.Repos[0].Name)
+	assert.Contains(t, status.Repos[0].LastError, "failed to get HEAD")
+	assert.False(t, status.Repos[0].LastCheck.IsZero())
+	assert.False(t, status.Repos[0].Syncing)
+	assert.True(t, status.Repos[1].Queued)
+
+	forced := d.takeForced()
+	require.Len(t, forced, 1)
+	assert.Equal(t, "idle", forced[0].Name)
+	assert.Empty(t, d.takeForced(), "taking forced syncs clears them")
+}
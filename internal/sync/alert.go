@@ -0,0 +1,175 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/security"
+)
+
+// Alert events.
+const (
+	AlertFailing   = "sync_failing"
+	AlertRecovered = "sync_recovered"
+)
+
+// Alert reports a repo whose syncs keep failing, or that synced again.
+type Alert struct {
+	Event    string    `json:"event"` // AlertFailing or AlertRecovered
+	Repo     string    `json:"repo"`
+	Path     string    `json:"path"`
+	Failures int       `json:"failures"`        // Consecutive failures so far (failing) or before (recovered)
+	Error    string    `json:"error,omitempty"` // The last failure, secrets redacted
+	Time     time.Time `json:"time"`
+}
+
+// alertHook is an AlertWebhook with its URL resolved.
+type alertHook struct {
+	url    string
+	format string
+}
+
+// SetAlerts fires alerts.webhooks once a repo fails alerts.after_failures
+// checks in a row, and again when it recovers. Every failure also delays
+// the repo's next scheduled check, doubling from a minute up to
+// alerts.max_backoff_minutes; pushes and sync-now requests still run.
+// Webhooks without a URL (an unset url_env) are skipped with a warning.
+func (d *Daemon) SetAlerts(alerts config.SyncAlertsConfig) {
+	d.alertAfter = alerts.AfterFailures
+	d.maxBackoff = time.Duration(alerts.MaxBackoffMinutes) * time.Minute
+	d.alertHooks = nil
+	for i, hook := range alerts.Webhooks {
+		url := hook.URL
+		if hook.URLEnv != "" {
+			url = os.Getenv(hook.URLEnv)
+		}
+		if url == "" {
+			d.logger.Warn("alert webhook has no URL, skipping", "index", i, "url_env", hook.URLEnv)
+			continue
+		}
+		format := hook.Format
+		if format == "" {
+			format = "json"
+		}
+		if format != "json" && format != "slack" {
+			d.logger.Warn("alert webhook has an unknown format, skipping", "index", i, "format", format)
+			continue
+		}
+		d.alertHooks = append(d.alertHooks, alertHook{url: url, format: format})
+	}
+}
+
+// backoff is the wait after failures consecutive failures: a minute,
+// doubling per failure, capped at max.
+func backoff(failures int, max time.Duration) time.Duration {
+	if max <= 0 || failures <= 0 {
+		return 0
+	}
+	wait := time.Minute
+	for i := 1; i < failures && wait < max; i++ {
+		wait *= 2
+	}
+	return min(wait, max)
+}
+
+// backingOff reports whether repo's checks wait for its backoff to pass.
+func (d *Daemon) backingOff(repo RepoWatch) bool {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	s, ok := d.state.repos[repo.Name]
+	return ok && time.Now().Before(s.BackoffUntil)
+}
+
+// recordError marks repo's last check as failed, backs off its checks, and
+// alerts once it has failed alertAfter times in a row.
+func (d *Daemon) recordError(ctx context.Context, repo RepoWatch, err error) {
+	var failures int
+	var until time.Time
+	d.update(repo, func(s *RepoStatus) {
+		s.LastError = err.Error()
+		s.LastErrorAt = time.Now()
+		s.Failures++
+		if wait := backoff(s.Failures, d.maxBackoff); wait > 0 {
+			s.BackoffUntil = s.LastErrorAt.Add(wait)
+		}
+		failures, until = s.Failures, s.BackoffUntil
+	})
+	if !until.IsZero() {
+		d.logger.Warn("backing off failing repo", "repo", repo.Name, "failures", failures, "until", until)
+	}
+	if d.alertAfter > 0 && failures == d.alertAfter {
+		d.alert(ctx, Alert{Event: AlertFailing, Repo: repo.Name, Path: repo.Path, Failures: failures, Error: err.Error()})
+	}
+}
+
+// recordSuccess clears repo's failures, alerting that it recovered if its
+// failures had been alerted.
+func (d *Daemon) recordSuccess(ctx context.Context, repo RepoWatch) {
+	var failures int
+	d.update(repo, func(s *RepoStatus) {
+		failures = s.Failures
+		s.LastError = ""
+		s.Failures = 0
+		s.BackoffUntil = time.Time{}
+	})
+	if d.alertAfter > 0 && failures >= d.alertAfter {
+		d.alert(ctx, Alert{Event: AlertRecovered, Repo: repo.Name, Path: repo.Path, Failures: failures})
+	}
+}
+
+// alert posts a to every alert webhook. Failures are only logged.
+func (d *Daemon) alert(ctx context.Context, a Alert) {
+	if len(d.alertHooks) == 0 {
+		return
+	}
+	a.Time = time.Now()
+	detector := security.NewSecretDetector()
+	if found := detector.Detect(a.Error); len(found) > 0 {
+		a.Error = detector.Redact(a.Error, found)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	for _, hook := range d.alertHooks {
+		if err := postAlert(ctx, client, hook, a); err != nil {
+			d.logger.Error("failed to send sync alert", "repo", a.Repo, "event", a.Event, "error", err)
+		}
+	}
+}
+
+func postAlert(ctx context.Context, client *http.Client, hook alertHook, a Alert) error {
+	var payload interface{} = a
+	if hook.format == "slack" {
+		payload = map[string]string{"text": slackText(a)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal alert: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post alert: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("post alert: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// slackText is a's message for Slack incoming webhooks.
+func slackText(a Alert) string {
+	if a.Event == AlertRecovered {
+		return fmt.Sprintf(":white_check_mark: code-indexer: %s syncs again after %d failed checks", a.Repo, a.Failures)
+	}
+	return fmt.Sprintf(":warning: code-indexer: %s failed to sync %d times in a row; its search results are going stale.\n```%s```", a.Repo, a.Failures, a.Error)
+}
@@ -13,7 +13,9 @@ import (
 	"time"
 
 	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/randalmurphal/code-indexer/internal/graph"
 	"github.com/randalmurphal/code-indexer/internal/indexer"
+	"github.com/randalmurphal/code-indexer/internal/metrics"
 )
 
 // Daemon watches repositories and syncs on changes.
@@ -22,7 +24,36 @@ type Daemon struct {
 	interval time.Duration
 	indexer  *indexer.Indexer
 	logger   *slog.Logger
-	headHash map[string]string // repo name -> last known HEAD hash
+	headHash map[string]string // repo name -> last known HEAD hash; guarded by state.mu
+	branches map[string]string // repo name -> last known branch; "" when detached; guarded by state.mu
+	state    *daemonState      // Status, forced syncs, and repo locks, shared with workers and AdminHandler
+
+	// repo name -> file hashes of the last sync without a graph store; guarded by state.mu
+	fileHashes map[string]map[string]string
+	// repo name -> dirty file hashes as last indexed (SetDirtyTracking); guarded by state.mu
+	dirty map[string]map[string]string
+
+	concurrency int          // Repos synced at once
+	jobs        chan syncJob // Pool queue; see startWorkers
+
+	graphStore   *graph.Neo4jStore // Nil keeps file hashes in fileHashes without updating the graph
+	historyPath  string            // Index run history log; empty disables
+	pausePath    string            // Pause file (Pauses); empty disables pausing
+	statePath    string            // Daemon state file (SetStatePath); empty keeps state in memory only
+	trigger      string            // Recorded with each run: "watch" or "webhook"
+	trackDirty   bool              // Index uncommitted changes between commits
+	alertAfter   int               // Consecutive failures that fire an alert; 0 disables
+	maxBackoff   time.Duration     // Longest backoff of a failing repo; 0 disables
+	alertHooks   []alertHook       // Where alerts are posted
+	warmer       CacheWarmer       // Nil disables cache warming
+	usage        *metrics.Logger   // Freshness events; nil disables
+	fileDebounce time.Duration     // Quiet period before changed files are reindexed; 0 disables file watching
+}
+
+// CacheWarmer re-runs a repo's popular searches once it is reindexed, so
+// they hit the freshly invalidated query cache (search.Handler).
+type CacheWarmer interface {
+	WarmCache(ctx context.Context, repo string) (int, error)
 }
 
 // RepoWatch defines a repository to watch.
@@ -40,40 +71,171 @@ func NewDaemon(repos []RepoWatch, interval time.Duration, idx *indexer.Indexer,
 		indexer:  idx,
 		logger:   logger,
 		headHash: make(map[string]string),
+		branches: make(map[string]string),
+		trigger:  "watch",
+		state:    newDaemonState(),
+
+		fileHashes:  make(map[string]map[string]string),
+		dirty:       make(map[string]map[string]string),
+		concurrency: 1,
 	}
 }
 
-// Run starts the daemon.
+// SetHistoryPath records every sync's index run in the history log at path.
+func (d *Daemon) SetHistoryPath(path string) {
+	d.historyPath = path
+}
+
+// SetGraphStore stores relationships on every sync and makes syncs
+// incremental, indexing only files whose hashes changed since the last run.
+func (d *Daemon) SetGraphStore(g *graph.Neo4jStore) {
+	d.graphStore = g
+}
+
+// SetWarmer warms the query cache after every sync that changes the index.
+func (d *Daemon) SetWarmer(w CacheWarmer) {
+	d.warmer = w
+}
+
+// Run starts the daemon. Each repo is checked on its own schedule (see
+// repoSchedule); an invalid one fails before anything is synced.
 func (d *Daemon) Run(ctx context.Context) error {
-	d.logger.Info("starting sync daemon", "interval", d.interval, "repos", len(d.repos))
+	schedules := make(map[string]schedule, len(d.repos))
+	for _, repo := range d.repos {
+		s, err := repoSchedule(repo, d.interval)
+		if err != nil {
+			return err
+		}
+		schedules[repo.Name] = s
+	}
 
-	ticker := time.NewTicker(d.interval)
-	defer ticker.Stop()
+	d.logger.Info("starting sync daemon", "interval", d.interval, "repos", len(d.repos), "concurrency", d.concurrency, "file_debounce", d.fileDebounce)
+
+	wait := d.startWorkers(ctx)
+	defer wait()
+
+	// Watch before the initial sync so edits made during it are not missed
+	var batches <-chan fileBatch
+	if d.fileDebounce > 0 {
+		batches = d.watchFiles(ctx)
+	}
+	pendingFiles := make(map[string]fileBatch) // Batches of repos busy when they arrived
 
 	// Initial sync
-	d.syncAll(ctx)
+	next := make(map[string]time.Time, len(d.repos))
+	for _, repo := range d.repos {
+		d.checkRepo(repo)
+		next[repo.Name] = d.scheduleNext(repo, schedules[repo.Name])
+	}
+
+	timer := time.NewTimer(untilEarliest(next))
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			d.logger.Info("daemon shutting down")
 			return ctx.Err()
-		case <-ticker.C:
-			d.syncAll(ctx)
+		case <-timer.C:
+			for _, repo := range d.repos {
+				if time.Now().Before(next[repo.Name]) {
+					continue
+				}
+				d.checkRepo(repo)
+				next[repo.Name] = d.scheduleNext(repo, schedules[repo.Name])
+			}
+			timer.Reset(untilEarliest(next))
+		case batch := <-batches:
+			if pending, ok := pendingFiles[batch.repo.Name]; ok {
+				batch = pending.merge(batch)
+			}
+			pendingFiles[batch.repo.Name] = batch
+			d.submitFiles(pendingFiles)
+		case <-d.state.wake:
+			d.submitForced(d.forceSync)
+		case <-d.state.finished:
+			d.submitForced(d.forceSync)
+			d.submitFiles(pendingFiles)
 		}
 	}
 }
 
-func (d *Daemon) syncAll(ctx context.Context) {
-	for _, repo := range d.repos {
-		if err := d.syncRepo(ctx, repo); err != nil {
+// checkRepo queues a sync of repo unless it is paused or already syncing;
+// the next scheduled check catches up on a skipped one.
+func (d *Daemon) checkRepo(repo RepoWatch) {
+	if d.paused(repo) {
+		d.logger.Debug("repo paused, skipping", "name", repo.Name)
+		return
+	}
+	if d.backingOff(repo) {
+		d.logger.Debug("repo failing, backing off", "name", repo.Name)
+		return
+	}
+	submitted := d.trySubmit(repo, func(ctx context.Context, ix *indexer.Indexer) {
+		if err := d.syncRepo(ctx, ix, repo); err != nil {
 			d.logger.Error("sync failed", "repo", repo.Name, "error", err)
 		}
+	})
+	if !submitted {
+		d.logger.Debug("repo still syncing, skipping check", "name", repo.Name)
+	}
+}
+
+// scheduleNext returns repo's next check time, recording it in its status.
+func (d *Daemon) scheduleNext(repo RepoWatch, s schedule) time.Time {
+	next := s.next(time.Now())
+	d.update(repo, func(st *RepoStatus) { st.NextCheck = next })
+	return next
+}
+
+// untilEarliest returns the wait until the earliest of next, an hour when
+// there is none.
+func untilEarliest(next map[string]time.Time) time.Duration {
+	var earliest time.Time
+	for _, t := range next {
+		if !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+	if earliest.IsZero() {
+		return time.Hour
 	}
+	return max(time.Until(earliest), 0)
 }
 
-func (d *Daemon) syncRepo(ctx context.Context, repo RepoWatch) error {
+// forceSync reindexes repo even if its HEAD is unchanged.
+func (d *Daemon) forceSync(ctx context.Context, ix *indexer.Indexer, repo RepoWatch) {
+	d.forget(repo)
+	if err := d.syncRepo(ctx, ix, repo); err != nil {
+		d.logger.Error("sync failed", "repo", repo.Name, "error", err)
+	}
+}
+
+// forget drops what the daemon knows of repo's HEAD and files, so its next
+// check reindexes it in full.
+func (d *Daemon) forget(repo RepoWatch) {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	delete(d.headHash, repo.Name)
+	delete(d.fileHashes, repo.Name)
+}
+
+// syncRepo reindexes repo with ix if its HEAD or branch changed, recording
+// the outcome in its status.
+func (d *Daemon) syncRepo(ctx context.Context, ix *indexer.Indexer, repo RepoWatch) (err error) {
 	d.logger.Debug("checking repo", "name", repo.Name)
+	d.update(repo, func(s *RepoStatus) { s.Syncing = true })
+	defer func() {
+		d.update(repo, func(s *RepoStatus) {
+			s.Syncing = false
+			s.LastCheck = time.Now()
+		})
+		if err != nil {
+			d.recordError(ctx, repo, err)
+		} else {
+			d.recordSuccess(ctx, repo)
+		}
+	}()
 
 	// Get current HEAD hash
 	currentHead, err := d.getGitHead(repo.Path)
@@ -81,33 +243,119 @@ func (d *Daemon) syncRepo(ctx context.Context, repo RepoWatch) error {
 		return fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
-	// Compare with cached HEAD
+	currentBranch := d.getGitBranch(repo.Path)
+	commitAt, commitErr := commitTime(ctx, repo.Path)
+	if commitErr != nil {
+		d.logger.Debug("failed to get commit time", "repo", repo.Name, "error", commitErr)
+	}
+	d.update(repo, func(s *RepoStatus) {
+		s.LatestHead = currentHead
+		s.CommitAt = commitAt
+	})
+
+	// Compare with cached HEAD and branch: a new branch at the same commit
+	// still needs its chunks retagged
+	d.state.mu.Lock()
 	cachedHead := d.headHash[repo.Name]
-	if currentHead == cachedHead {
+	cachedBranch, known := d.branches[repo.Name]
+	fileHashes := d.fileHashes[repo.Name]
+	d.state.mu.Unlock()
+	if currentHead == cachedHead && (!known || currentBranch == cachedBranch) {
 		d.logger.Debug("repo unchanged", "name", repo.Name)
+		if d.trackDirty {
+			d.reindexDirty(ctx, ix, repo)
+		}
 		return nil
 	}
 
-	d.logger.Info("repo changed, syncing", "name", repo.Name, "old_head", truncateHash(cachedHead), "new_head", truncateHash(currentHead))
+	if known && currentBranch != cachedBranch {
+		// The full index below drops chunks of files only on the old branch
+		d.logger.Info("branch switched, syncing", "name", repo.Name, "old_branch", cachedBranch, "new_branch", currentBranch)
+	} else {
+		d.logger.Info("repo changed, syncing", "name", repo.Name, "old_head", truncateHash(cachedHead), "new_head", truncateHash(currentHead))
+	}
+
+	// The run indexes dirty files as they are now (or later)
+	var dirty map[string]string
+	if d.trackDirty {
+		var dirtyErr error
+		if dirty, dirtyErr = dirtyHashes(ctx, repo); dirtyErr != nil {
+			d.logger.Warn("failed to list dirty files", "repo", repo.Name, "error", dirtyErr)
+		}
+	}
 
 	// Run index
-	result, err := d.indexer.Index(ctx, repo.Path, repo.Config)
+	started := time.Now()
+	result, err := ix.IndexWithOptions(ctx, repo.Path, repo.Config, indexer.IndexOptions{
+		Incremental: d.graphStore != nil || fileHashes != nil,
+		GraphStore:  d.graphStore,
+		FileHashes:  fileHashes,
+		HistoryPath: d.historyPath,
+		Trigger:     d.trigger,
+	})
 	if err != nil {
 		return fmt.Errorf("indexing failed: %w", err)
 	}
 
 	d.logger.Info("sync complete",
 		"repo", repo.Name,
+		"branch", result.Branch,
 		"files", result.FilesProcessed,
 		"chunks", result.ChunksCreated,
 	)
 
-	// Update cached HEAD
+	// Update cached HEAD and branch
+	d.state.mu.Lock()
 	d.headHash[repo.Name] = currentHead
+	d.branches[repo.Name] = currentBranch
+	if d.graphStore == nil {
+		d.fileHashes[repo.Name] = result.FileHashes
+	}
+	if dirty != nil {
+		d.dirty[repo.Name] = dirty
+	}
+	d.state.mu.Unlock()
+	d.update(repo, func(s *RepoStatus) {
+		s.Head = currentHead
+		s.Branch = currentBranch
+		s.LastSync = time.Now()
+		s.Duration = time.Since(started)
+		s.Files = result.FilesProcessed
+		s.Chunks = result.ChunksCreated
+	})
+	d.saveState()
+	if d.usage != nil && !commitAt.IsZero() {
+		d.usage.LogFreshness(repo.Name, commitAt, time.Now())
+	}
+
+	if result.ChangedIndex() {
+		d.warmCache(ctx, repo)
+	}
 
 	return nil
 }
 
+// warmCache re-runs repo's popular searches. A failure is only logged: the
+// searches then miss the cache once as they would without warming.
+func (d *Daemon) warmCache(ctx context.Context, repo RepoWatch) {
+	if d.warmer == nil {
+		return
+	}
+	name := repo.Name
+	if repo.Config != nil && repo.Config.Name != "" {
+		name = repo.Config.Name
+	}
+	started := time.Now()
+	warmed, err := d.warmer.WarmCache(ctx, name)
+	if err != nil {
+		d.logger.Warn("cache warming failed", "repo", name, "warmed", warmed, "error", err)
+		return
+	}
+	if warmed > 0 {
+		d.logger.Info("cache warmed", "repo", name, "queries", warmed, "duration", time.Since(started).Round(time.Millisecond))
+	}
+}
+
 // getGitHead returns the current HEAD commit hash.
 func (d *Daemon) getGitHead(repoPath string) (string, error) {
 	// Try git rev-parse first (most reliable)
@@ -143,6 +391,19 @@ func (d *Daemon) getGitHead(repoPath string) (string, error) {
 	return content, nil
 }
 
+// getGitBranch returns the checked-out branch, or "" when HEAD is detached
+// or git is unavailable.
+func (d *Daemon) getGitBranch(repoPath string) string {
+	output, err := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	if branch := strings.TrimSpace(string(output)); branch != "HEAD" {
+		return branch
+	}
+	return ""
+}
+
 func truncateHash(hash string) string {
 	if len(hash) > 8 {
 		return hash[:8]
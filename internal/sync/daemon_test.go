@@ -151,3 +151,27 @@ func TestDaemonRunCancellation(t *testing.T) {
 		t.Fatal("daemon did not stop after cancellation")
 	}
 }
+
+type fakeWarmer struct {
+	repos []string
+}
+
+func (w *fakeWarmer) WarmCache(ctx context.Context, repo string) (int, error) {
+	w.repos = append(w.repos, repo)
+	return 3, nil
+}
+
+func TestDaemonWarmCache(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	daemon := NewDaemon(nil, time.Minute, nil, logger)
+
+	// No warmer: nothing to do
+	daemon.warmCache(context.Background(), RepoWatch{Name: "r3"})
+
+	warmer := &fakeWarmer{}
+	daemon.SetWarmer(warmer)
+	daemon.warmCache(context.Background(), RepoWatch{Name: "r3-checkout", Config: &config.RepoConfig{Name: "r3"}})
+	daemon.warmCache(context.Background(), RepoWatch{Name: "m32rimm", Config: &config.RepoConfig{}})
+
+	assert.Equal(t, []string{"r3", "m32rimm"}, warmer.repos)
+}
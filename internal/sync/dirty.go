@@ -0,0 +1,92 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+)
+
+// SetDirtyTracking also indexes uncommitted changes: every check of a repo
+// whose HEAD is unchanged hashes the files `git status` lists as dirty and
+// reindexes those that changed since the previous check, plus those that
+// became clean, so their chunks lose the dirty tag (workspace payload).
+func (d *Daemon) SetDirtyTracking(enabled bool) {
+	d.trackDirty = enabled
+}
+
+// dirtyHashes hashes repo's dirty files; deleted ones hash to "".
+func dirtyHashes(ctx context.Context, repo RepoWatch) (map[string]string, error) {
+	paths, err := indexer.DirtyFiles(ctx, repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Join(repo.Path, filepath.FromSlash(path)))
+		if err != nil {
+			hashes[path] = ""
+			continue
+		}
+		sum := sha256.Sum256(content)
+		hashes[path] = hex.EncodeToString(sum[:])
+	}
+	return hashes, nil
+}
+
+// reindexDirty reindexes with ix the files of repo whose dirty state or
+// content changed since the previous check. Files that fail keep their
+// previous hash, so the next check retries them.
+func (d *Daemon) reindexDirty(ctx context.Context, ix *indexer.Indexer, repo RepoWatch) {
+	current, err := dirtyHashes(ctx, repo)
+	if err != nil {
+		d.logger.Warn("failed to list dirty files", "repo", repo.Name, "error", err)
+		return
+	}
+	d.state.mu.Lock()
+	previous := d.dirty[repo.Name]
+	d.state.mu.Unlock()
+
+	var changed []string
+	for path, hash := range current {
+		if prev, ok := previous[path]; !ok || prev != hash {
+			changed = append(changed, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	walker := indexer.NewRepoWalker(repo.Config)
+	files, chunks := 0, 0
+	for _, path := range changed {
+		if !walker.Accepts(repo.Path, path) {
+			continue
+		}
+		result, err := ix.IndexFile(ctx, repo.Path, repo.Config, path, nil)
+		if err != nil {
+			d.logger.Error("dirty file reindex failed", "repo", repo.Name, "path", path, "error", err)
+			if prev, ok := previous[path]; ok {
+				current[path] = prev
+			} else {
+				delete(current, path)
+			}
+			continue
+		}
+		files++
+		chunks += result.ChunksCreated
+	}
+
+	d.state.mu.Lock()
+	d.dirty[repo.Name] = current
+	d.state.mu.Unlock()
+	if files > 0 {
+		d.logger.Info("dirty files reindexed", "repo", repo.Name, "files", files, "chunks", chunks)
+		d.saveState()
+	}
+}
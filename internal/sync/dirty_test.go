@@ -0,0 +1,49 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirtyHashes(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, args := range [][]string{{"init"}, {"config", "user.email", "test@test.com"}, {"config", "user.name", "Test"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		require.NoError(t, cmd.Run())
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.py"), []byte("x = 1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "old.py"), []byte("y = 1\n"), 0644))
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "initial"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		require.NoError(t, cmd.Run())
+	}
+	repo := RepoWatch{Name: "test", Path: tmpDir}
+
+	hashes, err := dirtyHashes(context.Background(), repo)
+	require.NoError(t, err)
+	assert.Empty(t, hashes)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.py"), []byte("x = 2\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "old.py")))
+	hashes, err = dirtyHashes(context.Background(), repo)
+	require.NoError(t, err)
+	require.Len(t, hashes, 2)
+	assert.Len(t, hashes["main.py"], 64)
+	assert.Equal(t, "", hashes["old.py"], "deleted files hash to empty")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.py"), []byte("x = 3\n"), 0644))
+	edited, err := dirtyHashes(context.Background(), repo)
+	require.NoError(t, err)
+	assert.NotEqual(t, hashes["main.py"], edited["main.py"])
+
+	_, err = dirtyHashes(context.Background(), RepoWatch{Name: "plain", Path: t.TempDir()})
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+)
+
+// fileBatch is a repo's files changed since its previous batch.
+type fileBatch struct {
+	repo     RepoWatch
+	paths    []string // Absolute
+	overflow bool     // Events were lost; the repo needs a full sync
+}
+
+// merge adds the paths of a later batch of the same repo.
+func (b fileBatch) merge(later fileBatch) fileBatch {
+	paths := append(slices.Clone(b.paths), later.paths...)
+	slices.Sort(paths)
+	return fileBatch{
+		repo:     b.repo,
+		paths:    slices.Compact(paths),
+		overflow: b.overflow || later.overflow,
+	}
+}
+
+// SetFileWatch also watches the repos' files, reindexing each file saved,
+// created, or deleted once its repo has had no changes for debounce, so
+// uncommitted edits are searchable right away. The interval check keeps
+// handling commits and branch switches. 0 disables.
+func (d *Daemon) SetFileWatch(debounce time.Duration) {
+	d.fileDebounce = debounce
+}
+
+// watchFiles watches every repo's tree, returning their debounced batches.
+func (d *Daemon) watchFiles(ctx context.Context) <-chan fileBatch {
+	batches := make(chan fileBatch)
+	for _, repo := range d.repos {
+		walker := indexer.NewRepoWalker(repo.Config)
+		events := watchTree(ctx, repo.Path, walker.ExcludesDir, d.logger)
+		go debounce(ctx, events, d.fileDebounce, func(paths []string, overflow bool) bool {
+			select {
+			case batches <- fileBatch{repo: repo, paths: paths, overflow: overflow}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}
+	return batches
+}
+
+// debounce collects events until they pause for wait, then passes the
+// changed paths, sorted, to flush. It returns when events closes, ctx is
+// done, or flush returns false.
+func debounce(ctx context.Context, events <-chan fileEvent, wait time.Duration, flush func(paths []string, overflow bool) bool) {
+	pending := make(map[string]bool)
+	overflow := false
+	timer := time.NewTimer(wait)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if e.overflow {
+				overflow = true
+			} else {
+				pending[e.path] = true
+			}
+			timer.Reset(wait)
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			slices.Sort(paths)
+			if !flush(paths, overflow) {
+				return
+			}
+			pending = make(map[string]bool)
+			overflow = false
+		}
+	}
+}
+
+// submitFiles queues the pending batches of repos not busy, removing them;
+// the others wait for a job to finish.
+func (d *Daemon) submitFiles(pending map[string]fileBatch) {
+	for name, batch := range pending {
+		submitted := d.trySubmit(batch.repo, func(ctx context.Context, ix *indexer.Indexer) {
+			d.reindexFiles(ctx, ix, batch)
+		})
+		if submitted {
+			delete(pending, name)
+		}
+	}
+}
+
+// reindexFiles reindexes with ix the files of a batch that the repo's
+// patterns and ignore files include; deleted ones have their chunks
+// removed. Lost events force a full sync of the repo instead.
+func (d *Daemon) reindexFiles(ctx context.Context, ix *indexer.Indexer, batch fileBatch) {
+	repo := batch.repo
+	if d.paused(repo) {
+		d.logger.Debug("repo paused, skipping file reindex", "name", repo.Name, "files", len(batch.paths))
+		return
+	}
+	if batch.overflow {
+		d.logger.Warn("file events lost, running a full sync", "repo", repo.Name)
+		d.forceSync(ctx, ix, repo)
+		return
+	}
+
+	walker := indexer.NewRepoWalker(repo.Config)
+	files, chunks := 0, 0
+	for _, path := range batch.paths {
+		rel, err := filepath.Rel(repo.Path, path)
+		if err != nil || !walker.Accepts(repo.Path, rel) {
+			continue
+		}
+		result, err := ix.IndexFile(ctx, repo.Path, repo.Config, rel, nil)
+		if err != nil {
+			d.logger.Error("file reindex failed", "repo", repo.Name, "path", rel, "error", err)
+			continue
+		}
+		files++
+		chunks += result.ChunksCreated
+	}
+	if files > 0 {
+		d.logger.Info("files reindexed", "repo", repo.Name, "files", files, "chunks", chunks)
+	}
+}
@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebounce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan fileEvent)
+	type flushed struct {
+		paths    []string
+		overflow bool
+	}
+	flushes := make(chan flushed, 4)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		debounce(ctx, events, 50*time.Millisecond, func(paths []string, overflow bool) bool {
+			flushes <- flushed{paths, overflow}
+			return true
+		})
+	}()
+
+	events <- fileEvent{path: "/repo/b.py"}
+	events <- fileEvent{path: "/repo/a.py"}
+	events <- fileEvent{path: "/repo/b.py"}
+	select {
+	case f := <-flushes:
+		assert.Equal(t, []string{"/repo/a.py", "/repo/b.py"}, f.paths, "one sorted batch per pause")
+		assert.False(t, f.overflow)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch after the pause")
+	}
+
+	events <- fileEvent{overflow: true}
+	select {
+	case f := <-flushes:
+		assert.Empty(t, f.paths)
+		assert.True(t, f.overflow)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch after the overflow")
+	}
+
+	close(events)
+	<-done
+}
+
+func TestWatchTree(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "node_modules"), 0755))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	events := watchTree(ctx, root, func(rel string) bool { return rel == "node_modules" }, logger)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "node_modules", "dep.js"), []byte("x"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pkg", "app.py"), []byte("x = 1\n"), 0644))
+
+	want := filepath.Join(root, "pkg", "app.py")
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case e := <-events:
+			assert.NotContains(t, e.path, "node_modules", "skipped directories are not watched")
+			if e.path == want {
+				cancel()
+				for range events {
+				}
+				return
+			}
+		case <-deadline:
+			t.Fatal("no event for a file written in a new directory")
+		}
+	}
+}
+
+func TestScanTree(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.py"), []byte("a"), 0644))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	stamps := scanTree(root, func(rel string) bool { return rel == ".git" }, logger)
+	assert.Len(t, stamps, 1)
+	assert.Equal(t, int64(1), stamps[filepath.Join(root, "a.py")].size)
+}
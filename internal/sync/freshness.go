@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/metrics"
+)
+
+// SetMetrics logs a freshness event to usage after every completed sync:
+// how long after the commit time of the HEAD it indexed the sync finished.
+func (d *Daemon) SetMetrics(usage *metrics.Logger) {
+	d.usage = usage
+}
+
+// commitTime is the committer time of repoPath's HEAD.
+func commitTime(ctx context.Context, repoPath string) (time.Time, error) {
+	output, err := exec.CommandContext(ctx, "git", "-C", repoPath, "log", "-1", "--format=%ct", "HEAD").Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log: %w", err)
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse commit time: %w", err)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// IndexLag is how far the repo's index is behind its latest commit at now:
+// while the HEAD last seen is not synced, the time since its commit; once
+// synced, how long after the commit the sync finished. Zero before the
+// repo's commit time is known.
+func (s RepoStatus) IndexLag(now time.Time) time.Duration {
+	if s.CommitAt.IsZero() {
+		return 0
+	}
+	if s.LatestHead != s.Head {
+		return max(0, now.Sub(s.CommitAt))
+	}
+	return max(0, s.LastSync.Sub(s.CommitAt))
+}
+
+// writePrometheus writes repos' freshness gauges in the Prometheus text
+// exposition format.
+func writePrometheus(w io.Writer, repos []RepoStatus, now time.Time) {
+	fmt.Fprintln(w, "# HELP code_indexer_index_lag_seconds Seconds the repo's index lags its latest commit.")
+	fmt.Fprintln(w, "# TYPE code_indexer_index_lag_seconds gauge")
+	for _, s := range repos {
+		if s.CommitAt.IsZero() {
+			continue
+		}
+		fmt.Fprintf(w, "code_indexer_index_lag_seconds{repo=\"%s\"} %g\n", labelValue(s.Name), s.IndexLag(now).Seconds())
+	}
+	fmt.Fprintln(w, "# HELP code_indexer_last_sync_timestamp_seconds Unix time of the repo's last completed sync.")
+	fmt.Fprintln(w, "# TYPE code_indexer_last_sync_timestamp_seconds gauge")
+	for _, s := range repos {
+		if s.LastSync.IsZero() {
+			continue
+		}
+		fmt.Fprintf(w, "code_indexer_last_sync_timestamp_seconds{repo=\"%s\"} %d\n", labelValue(s.Name), s.LastSync.Unix())
+	}
+	fmt.Fprintln(w, "# HELP code_indexer_sync_failures Consecutive failed checks of the repo.")
+	fmt.Fprintln(w, "# TYPE code_indexer_sync_failures gauge")
+	for _, s := range repos {
+		fmt.Fprintf(w, "code_indexer_sync_failures{repo=\"%s\"} %d\n", labelValue(s.Name), s.Failures)
+	}
+}
+
+// labelValue escapes a Prometheus label value.
+func labelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
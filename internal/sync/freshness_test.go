@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitTime(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.py"), []byte("x = 1\n"), 0644))
+	for _, args := range [][]string{{"init"}, {"config", "user.email", "test@test.com"}, {"config", "user.name", "Test"}, {"add", "."}, {"commit", "-m", "initial"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2026-03-01T12:00:00Z")
+		require.NoError(t, cmd.Run())
+	}
+
+	at, err := commitTime(context.Background(), tmpDir)
+	require.NoError(t, err)
+	assert.True(t, at.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)), at)
+
+	_, err = commitTime(context.Background(), t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestIndexLag(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	commit := now.Add(-time.Hour)
+
+	assert.Zero(t, RepoStatus{Head: "a", LatestHead: "a"}.IndexLag(now), "unknown commit time")
+	synced := RepoStatus{Head: "a", LatestHead: "a", CommitAt: commit, LastSync: commit.Add(2 * time.Minute)}
+	assert.Equal(t, 2*time.Minute, synced.IndexLag(now), "synced: lag frozen at the sync")
+	pending := RepoStatus{Head: "a", LatestHead: "b", CommitAt: commit, LastSync: commit.Add(-time.Hour)}
+	assert.Equal(t, time.Hour, pending.IndexLag(now), "unsynced: lag grows")
+	early := RepoStatus{Head: "a", LatestHead: "a", CommitAt: commit, LastSync: commit.Add(-time.Minute)}
+	assert.Zero(t, early.IndexLag(now), "clock skew never lags negatively")
+}
+
+func TestWritePrometheus(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	var out bytes.Buffer
+	writePrometheus(&out, []RepoStatus{
+		{Name: `we"ird`, Head: "a", LatestHead: "b", CommitAt: now.Add(-90 * time.Second), LastSync: now.Add(-time.Hour), Failures: 2},
+		{Name: "new"},
+	}, now)
+
+	text := out.String()
+	assert.Contains(t, text, "# TYPE code_indexer_index_lag_seconds gauge\n")
+	assert.Contains(t, text, `code_indexer_index_lag_seconds{repo="we\"ird"} 90`+"\n")
+	assert.Contains(t, text, `code_indexer_last_sync_timestamp_seconds{repo="we\"ird"} 1799996400`+"\n")
+	assert.Contains(t, text, `code_indexer_sync_failures{repo="new"} 0`+"\n")
+	assert.NotContains(t, text, `code_indexer_index_lag_seconds{repo="new"}`, "repos never checked have no lag")
+}
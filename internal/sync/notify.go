@@ -0,0 +1,108 @@
+package sync
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval is how often the polling notifier rescans a repo.
+const pollInterval = 2 * time.Second
+
+// fileEvent is a file created, written, moved, or deleted under a watched
+// root. Overflow means events were lost and the whole tree may have changed.
+type fileEvent struct {
+	path     string // Absolute
+	overflow bool
+}
+
+// skipDirFunc reports whether a repo-relative directory is left unwatched.
+type skipDirFunc func(relDir string) bool
+
+// watchTree sends events for changes under root until ctx is done, then
+// closes the channel. It uses the platform's file notifications where
+// supported (inotify on Linux) and falls back to polling file modification
+// times, e.g. when the inotify watch limit is reached.
+func watchTree(ctx context.Context, root string, skipDir skipDirFunc, logger *slog.Logger) <-chan fileEvent {
+	events, err := notifyTree(ctx, root, skipDir, logger)
+	if err == nil {
+		return events
+	}
+	logger.Warn("file notifications unavailable, polling for changes", "path", root, "interval", pollInterval, "error", err)
+	return pollTree(ctx, root, skipDir, logger)
+}
+
+// fileStamp is what pollTree compares between scans.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// pollTree reports files whose modification time or size changed, and
+// files created or deleted, by rescanning root every pollInterval.
+func pollTree(ctx context.Context, root string, skipDir skipDirFunc, logger *slog.Logger) <-chan fileEvent {
+	events := make(chan fileEvent)
+	go func() {
+		defer close(events)
+		seen := scanTree(root, skipDir, logger)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current := scanTree(root, skipDir, logger)
+			var changed []string
+			for path, stamp := range current {
+				if old, ok := seen[path]; !ok || old != stamp {
+					changed = append(changed, path)
+				}
+			}
+			for path := range seen {
+				if _, ok := current[path]; !ok {
+					changed = append(changed, path)
+				}
+			}
+			seen = current
+			for _, path := range changed {
+				select {
+				case events <- fileEvent{path: path}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events
+}
+
+// scanTree stamps every regular file under root outside skipped directories.
+func scanTree(root string, skipDir skipDirFunc, logger *slog.Logger) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Deleted mid-scan or unreadable; the next scan sees it
+		}
+		if d.IsDir() {
+			if rel, _ := filepath.Rel(root, path); rel != "." && skipDir(filepath.ToSlash(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Warn("scanning for changes failed", "path", root, "error", err)
+	}
+	return stamps
+}
@@ -0,0 +1,186 @@
+//go:build linux
+
+package sync
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// inotifyMask selects saves (close after write, rename into place),
+	// deletions, and created directories, which get watched in turn.
+	inotifyMask = unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO | unix.IN_MOVED_FROM |
+		unix.IN_DELETE | unix.IN_CREATE | unix.IN_EXCL_UNLINK
+
+	inotifyPollMs = 500 // How often the reader checks for cancellation
+)
+
+// inotifyTree watches root and every directory below it not skipped.
+type inotifyTree struct {
+	fd      int
+	root    string
+	skipDir skipDirFunc
+	logger  *slog.Logger
+	dirs    map[int32]string // Watch descriptor → absolute directory
+}
+
+// notifyTree watches root with inotify. Adding the initial watches fails
+// when the per-user watch limit (fs.inotify.max_user_watches) is too low
+// for the tree.
+func notifyTree(ctx context.Context, root string, skipDir skipDirFunc, logger *slog.Logger) (<-chan fileEvent, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %w", err)
+	}
+	t := &inotifyTree{fd: fd, root: root, skipDir: skipDir, logger: logger, dirs: make(map[int32]string)}
+	if err := t.addTree(root, nil); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	events := make(chan fileEvent)
+	go func() {
+		defer close(events)
+		defer unix.Close(fd)
+		t.read(ctx, events)
+	}()
+	return events, nil
+}
+
+// addTree watches dir and the directories below it. With created set, the
+// files found are passed to it: they may have been written before their
+// directory was watched.
+func (t *inotifyTree) addTree(dir string, created func(path string)) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			if created != nil && d.Type().IsRegular() {
+				created(path)
+			}
+			return nil
+		}
+		if rel, _ := filepath.Rel(t.root, path); rel != "." && t.skipDir(filepath.ToSlash(rel)) {
+			return filepath.SkipDir
+		}
+		wd, err := unix.InotifyAddWatch(t.fd, path, inotifyMask)
+		if err != nil {
+			if errors.Is(err, unix.ENOSPC) {
+				return fmt.Errorf("inotify watch limit reached at %s (raise fs.inotify.max_user_watches): %w", path, err)
+			}
+			return fmt.Errorf("watch %s: %w", path, err)
+		}
+		t.dirs[int32(wd)] = path
+		return nil
+	})
+}
+
+// removeTree drops the watches of dir and the directories below it.
+func (t *inotifyTree) removeTree(dir string) {
+	for wd, path := range t.dirs {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			unix.InotifyRmWatch(t.fd, uint32(wd))
+			delete(t.dirs, wd)
+		}
+	}
+}
+
+// read forwards events until ctx is done.
+func (t *inotifyTree) read(ctx context.Context, events chan<- fileEvent) {
+	buf := make([]byte, 64*1024)
+	send := func(e fileEvent) bool {
+		select {
+		case events <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	fds := []unix.PollFd{{Fd: int32(t.fd), Events: unix.POLLIN}}
+	for ctx.Err() == nil {
+		n, err := unix.Poll(fds, inotifyPollMs)
+		if err != nil && !errors.Is(err, unix.EINTR) {
+			t.logger.Error("inotify poll failed", "path", t.root, "error", err)
+			return
+		}
+		if n <= 0 {
+			continue
+		}
+		n, err = unix.Read(t.fd, buf)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			t.logger.Error("inotify read failed", "path", t.root, "error", err)
+			return
+		}
+		for _, e := range t.parse(buf[:n]) {
+			if !send(e) {
+				return
+			}
+		}
+	}
+}
+
+// parse decodes a buffer of inotify events, watching created directories.
+func (t *inotifyTree) parse(buf []byte) []fileEvent {
+	var out []fileEvent
+	for off := 0; off+unix.SizeofInotifyEvent <= len(buf); {
+		wd := int32(binary.NativeEndian.Uint32(buf[off:]))
+		mask := binary.NativeEndian.Uint32(buf[off+4:])
+		nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+		start := off + unix.SizeofInotifyEvent
+		off = start + nameLen
+		if off > len(buf) {
+			break
+		}
+		name := strings.TrimRight(string(buf[start:off]), "\x00")
+
+		switch {
+		case mask&unix.IN_Q_OVERFLOW != 0:
+			out = append(out, fileEvent{overflow: true})
+			continue
+		case mask&unix.IN_IGNORED != 0:
+			delete(t.dirs, wd) // Directory deleted or moved away
+			continue
+		}
+		dir, ok := t.dirs[wd]
+		if !ok || name == "" {
+			continue
+		}
+		path := filepath.Join(dir, name)
+
+		if mask&unix.IN_ISDIR != 0 {
+			if mask&unix.IN_MOVED_FROM != 0 {
+				t.removeTree(path) // Watched again if moved to a watched directory
+			}
+			if mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+				err := t.addTree(path, func(file string) {
+					out = append(out, fileEvent{path: file})
+				})
+				if err != nil {
+					t.logger.Warn("failed to watch new directory", "path", path, "error", err)
+				}
+			}
+			continue
+		}
+		if mask&unix.IN_CREATE != 0 {
+			continue // The close after writing follows
+		}
+		out = append(out, fileEvent{path: path})
+	}
+	return out
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package sync
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// notifyTree is only implemented with inotify; elsewhere watchTree polls.
+func notifyTree(ctx context.Context, root string, skipDir skipDirFunc, logger *slog.Logger) (<-chan fileEvent, error) {
+	return nil, errors.New("file notifications are only supported on Linux")
+}
@@ -0,0 +1,82 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Pauses maps repo names to when their pause ends; the zero time pauses
+// until resumed. The daemon rereads them before every check, so pausing a
+// repo (code-indexer pause) takes effect on a running daemon.
+type Pauses map[string]time.Time
+
+// Paused reports whether repo is paused at now.
+func (p Pauses) Paused(repo string, now time.Time) bool {
+	until, ok := p[repo]
+	return ok && (until.IsZero() || now.Before(until))
+}
+
+// LoadPauses reads the pause file at path; a missing file pauses nothing.
+// Expired pauses are dropped.
+func LoadPauses(path string) (Pauses, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Pauses{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read pauses: %w", err)
+	}
+	var pauses Pauses
+	if err := json.Unmarshal(data, &pauses); err != nil {
+		return nil, fmt.Errorf("parse pauses %s: %w", path, err)
+	}
+	now := time.Now()
+	for repo := range pauses {
+		if !pauses.Paused(repo, now) {
+			delete(pauses, repo)
+		}
+	}
+	return pauses, nil
+}
+
+// SavePauses writes the pause file at path, replacing it atomically.
+func SavePauses(path string, pauses Pauses) error {
+	data, err := json.MarshalIndent(pauses, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create pause dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write pauses: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write pauses: %w", err)
+	}
+	return nil
+}
+
+// SetPausePath skips checks, file reindexes, and pushes of the repos paused
+// in the pause file at path. Forced syncs (sync-now) still run.
+func (d *Daemon) SetPausePath(path string) {
+	d.pausePath = path
+}
+
+// paused reports whether repo is paused now. An unreadable pause file is
+// logged and pauses nothing, so a bad edit never stops every sync.
+func (d *Daemon) paused(repo RepoWatch) bool {
+	if d.pausePath == "" {
+		return false
+	}
+	pauses, err := LoadPauses(d.pausePath)
+	if err != nil {
+		d.logger.Warn("ignoring pause file", "path", d.pausePath, "error", err)
+		return false
+	}
+	return pauses.Paused(repo.Name, time.Now())
+}
@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"context"
+	stdsync "sync"
+
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+)
+
+// syncJob is work on one repo. A pool worker runs it with its own indexer
+// while the repo's lock is held, so runs of one repo never overlap.
+type syncJob struct {
+	repo RepoWatch
+	run  func(ctx context.Context, ix *indexer.Indexer)
+}
+
+// SetConcurrency syncs up to n repos at once (default 1), so one slow repo
+// no longer delays the others. Each worker indexes with its own fork of the
+// indexer (Indexer.Fork).
+func (d *Daemon) SetConcurrency(n int) {
+	d.concurrency = max(n, 1)
+}
+
+// startWorkers starts the pool. The returned function waits for the
+// workers, which stop once ctx is done and their current job returns.
+func (d *Daemon) startWorkers(ctx context.Context) (wait func()) {
+	// Every queued job holds its repo's lock, so one slot per repo never blocks
+	d.jobs = make(chan syncJob, len(d.repos))
+	var wg stdsync.WaitGroup
+	for i := range max(d.concurrency, 1) {
+		ix := d.indexer
+		if i > 0 && ix != nil {
+			ix = ix.Fork()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-d.jobs:
+					job.run(ctx, ix)
+					d.repoLock(job.repo.Name).Unlock()
+					select {
+					case d.state.finished <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}()
+	}
+	return wg.Wait
+}
+
+// trySubmit queues run for repo unless a job of repo is queued or running.
+func (d *Daemon) trySubmit(repo RepoWatch, run func(ctx context.Context, ix *indexer.Indexer)) bool {
+	lock := d.repoLock(repo.Name)
+	if !lock.TryLock() {
+		return false
+	}
+	d.jobs <- syncJob{repo: repo, run: run}
+	return true
+}
+
+// repoLock returns the lock held while a job of the named repo is queued
+// or running.
+func (d *Daemon) repoLock(name string) *stdsync.Mutex {
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	lock, ok := d.state.locks[name]
+	if !ok {
+		lock = &stdsync.Mutex{}
+		d.state.locks[name] = lock
+	}
+	return lock
+}
+
+// submitForced queues the forced syncs of repos not busy; the others stay
+// requested until a job finishes.
+func (d *Daemon) submitForced(run func(ctx context.Context, ix *indexer.Indexer, repo RepoWatch)) {
+	for _, repo := range d.takeForced() {
+		if !d.trySubmit(repo, func(ctx context.Context, ix *indexer.Indexer) { run(ctx, ix, repo) }) {
+			d.state.mu.Lock()
+			d.state.forced[repo.Name] = true
+			d.state.mu.Unlock()
+		}
+	}
+}
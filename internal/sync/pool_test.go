@@ -0,0 +1,50 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	a, b := RepoWatch{Name: "a"}, RepoWatch{Name: "b"}
+	d := NewDaemon([]RepoWatch{a, b}, time.Minute, nil, logger)
+	d.SetConcurrency(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wait := d.startWorkers(ctx)
+	defer wait()
+	defer cancel()
+
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	blocking := func(name string) func(context.Context, *indexer.Indexer) {
+		return func(context.Context, *indexer.Indexer) {
+			started <- name
+			<-release
+		}
+	}
+	noop := func(context.Context, *indexer.Indexer) {}
+
+	require.True(t, d.trySubmit(a, blocking("a")))
+	require.True(t, d.trySubmit(b, blocking("b")))
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("repos did not sync concurrently")
+		}
+	}
+	assert.False(t, d.trySubmit(a, noop), "a repo runs one job at a time")
+
+	close(release)
+	require.Eventually(t, func() bool { return d.trySubmit(a, noop) }, 5*time.Second, 10*time.Millisecond,
+		"a repo is free once its job finishes")
+}
@@ -0,0 +1,169 @@
+package sync
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule decides when a repo is next checked for changes.
+type schedule interface {
+	// next returns the first check time after after, or the zero time if
+	// there is none.
+	next(after time.Time) time.Time
+}
+
+// everySchedule checks at a fixed interval after the previous check.
+type everySchedule time.Duration
+
+func (s everySchedule) next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// repoSchedule returns the schedule of a repo: its config's sync.schedule,
+// else its sync.interval, else the daemon's interval.
+func repoSchedule(repo RepoWatch, interval time.Duration) (schedule, error) {
+	if repo.Config != nil && repo.Config.Sync.Schedule != "" {
+		s, err := parseCron(repo.Config.Sync.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("repo %s: sync.schedule: %w", repo.Name, err)
+		}
+		return s, nil
+	}
+	if repo.Config != nil && repo.Config.Sync.Interval != "" {
+		d, err := time.ParseDuration(repo.Config.Sync.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("repo %s: sync.interval: %w", repo.Name, err)
+		}
+		interval = d
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("repo %s: sync interval must be positive", repo.Name)
+	}
+	return everySchedule(interval), nil
+}
+
+// cronSchedule is a standard five-field cron expression in local time.
+// Each field is a bitset of the values it allows.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	anyDom, anyDow                bool // The day fields were "*"
+}
+
+// cronDescriptors are the shorthands parseCron accepts.
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// parseCron parses "minute hour day-of-month month day-of-week", where each
+// field is "*", a number, a range "a-b", or a list of those, each
+// optionally with a step "/n". Day of week runs 0-6 from Sunday (7 is also
+// Sunday). As in cron, a day matching either restricted day field matches.
+func parseCron(spec string) (*cronSchedule, error) {
+	if expanded, ok := cronDescriptors[strings.TrimSpace(spec)]; ok {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%q: want 5 fields (minute hour day month weekday), got %d", spec, len(fields))
+	}
+	s := &cronSchedule{anyDom: fields[2] == "*", anyDow: fields[4] == "*"}
+	bounds := []struct {
+		set      *uint64
+		min, max int
+		name     string
+	}{
+		{&s.minute, 0, 59, "minute"},
+		{&s.hour, 0, 23, "hour"},
+		{&s.dom, 1, 31, "day of month"},
+		{&s.month, 1, 12, "month"},
+		{&s.dow, 0, 7, "day of week"},
+	}
+	for i, b := range bounds {
+		set, err := parseCronField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %s: %w", spec, b.name, err)
+		}
+		*b.set = set
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 // 7 is Sunday too
+	}
+	if s.next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%q never matches", spec)
+	}
+	return s, nil
+}
+
+// parseCronField returns the bitset of values a field allows.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step %q", stepStr)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, fmt.Errorf("bad value %q", loStr)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, fmt.Errorf("bad value %q", hiStr)
+				}
+			} else if hasStep {
+				hi = max // "a/n" steps from a to the end
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (s *cronSchedule) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.anyDom || s.anyDow {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/randalmurphal/code-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		tm, err := time.ParseInLocation(time.DateTime, s, time.Local)
+		require.NoError(t, err)
+		return tm
+	}
+	// 2026-10-16 is a Friday
+	tests := []struct {
+		spec, after, want string
+	}{
+		{"*/15 * * * *", "2026-10-16 10:07:30", "2026-10-16 10:15:00"},
+		{"0 */2 * * 1-5", "2026-10-16 23:10:00", "2026-10-19 00:00:00"}, // Over the weekend
+		{"30 9 * * 7", "2026-10-16 10:00:00", "2026-10-18 09:30:00"},    // 7 is Sunday
+		{"0 0 1 * 5", "2026-10-16 00:00:00", "2026-10-23 00:00:00"},     // Either day field matches
+		{"@daily", "2026-12-31 12:00:00", "2027-01-01 00:00:00"},
+		{"5,10-12/2 3 * * *", "2026-10-16 03:06:00", "2026-10-16 03:10:00"},
+	}
+	for _, tt := range tests {
+		s, err := parseCron(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, at(tt.want), s.next(at(tt.after)), tt.spec)
+	}
+
+	for _, bad := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "0 0 31 2 *", "x * * * *"} {
+		_, err := parseCron(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRepoSchedule(t *testing.T) {
+	now := time.Now()
+
+	s, err := repoSchedule(RepoWatch{Name: "r3"}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), s.next(now), "repos without sync config use the daemon interval")
+
+	s, err = repoSchedule(RepoWatch{Name: "r3", Config: &config.RepoConfig{Sync: config.RepoSyncConfig{Interval: "10m"}}}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(10*time.Minute), s.next(now))
+
+	s, err = repoSchedule(RepoWatch{Name: "r3", Config: &config.RepoConfig{Sync: config.RepoSyncConfig{Interval: "10m", Schedule: "@hourly"}}}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, s.next(now).Minute(), "schedule wins over interval")
+
+	_, err = repoSchedule(RepoWatch{Name: "r3", Config: &config.RepoConfig{Sync: config.RepoSyncConfig{Interval: "often"}}}, time.Minute)
+	assert.ErrorContains(t, err, "sync.interval")
+	_, err = repoSchedule(RepoWatch{Name: "r3"}, 0)
+	assert.Error(t, err)
+}
+
+func TestPauses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "paused.json")
+	pauses, err := LoadPauses(path)
+	require.NoError(t, err)
+	assert.Empty(t, pauses, "a missing file pauses nothing")
+
+	now := time.Now()
+	pauses["r3"] = time.Time{}
+	pauses["m32rimm"] = now.Add(time.Hour)
+	pauses["old"] = now.Add(-time.Hour)
+	require.NoError(t, SavePauses(path, pauses))
+
+	loaded, err := LoadPauses(path)
+	require.NoError(t, err)
+	assert.True(t, loaded.Paused("r3", now))
+	assert.True(t, loaded.Paused("m32rimm", now))
+	assert.False(t, loaded.Paused("m32rimm", now.Add(2*time.Hour)))
+	assert.NotContains(t, loaded, "old", "expired pauses are dropped")
+	assert.False(t, loaded.Paused("other", now))
+}
@@ -0,0 +1,145 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// persistedState is the daemon state file: what the daemon knew of each
+// repo after its last sync, so a restart does not resync every repo.
+type persistedState struct {
+	Repos map[string]persistedRepo `json:"repos"`
+}
+
+type persistedRepo struct {
+	Path     string            `json:"path"`
+	Head     string            `json:"head"`
+	Branch   string            `json:"branch,omitempty"`
+	SyncedAt time.Time         `json:"synced_at"`
+	Files    map[string]string `json:"files,omitempty"` // File hashes; only kept without a graph store
+	Dirty    map[string]string `json:"dirty,omitempty"` // Dirty file hashes as last indexed (SetDirtyTracking)
+}
+
+// SetStatePath keeps the daemon's last synced HEAD and branch of every repo
+// (and file hashes, without a graph store) in the state file at path, and
+// loads what an earlier daemon left there. Repos whose HEAD is unchanged
+// since are then not resynced on startup. An unreadable state file is
+// logged and ignored: every repo is then synced once, as without one.
+func (d *Daemon) SetStatePath(path string) {
+	d.statePath = path
+	state, err := loadState(path)
+	if err != nil {
+		d.logger.Warn("ignoring daemon state", "path", path, "error", err)
+		return
+	}
+
+	d.state.mu.Lock()
+	defer d.state.mu.Unlock()
+	for _, repo := range d.repos {
+		saved, ok := state.Repos[repo.Name]
+		if !ok || saved.Path != repo.Path {
+			// A repo renamed onto another path is a different checkout
+			continue
+		}
+		d.headHash[repo.Name] = saved.Head
+		d.branches[repo.Name] = saved.Branch
+		if saved.Files != nil {
+			d.fileHashes[repo.Name] = saved.Files
+		}
+		if saved.Dirty != nil {
+			d.dirty[repo.Name] = saved.Dirty
+		}
+		d.state.repos[repo.Name] = &RepoStatus{
+			Name:     repo.Name,
+			Path:     repo.Path,
+			Head:     saved.Head,
+			Branch:   saved.Branch,
+			LastSync: saved.SyncedAt,
+		}
+	}
+}
+
+// loadState reads the state file at path; a missing file is empty.
+func loadState(path string) (persistedState, error) {
+	state := persistedState{Repos: map[string]persistedRepo{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("read daemon state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("parse daemon state %s: %w", path, err)
+	}
+	if state.Repos == nil {
+		state.Repos = map[string]persistedRepo{}
+	}
+	return state, nil
+}
+
+// saveState writes the state of every synced repo to the state file,
+// replacing it atomically so a crash mid-write leaves the old one. A
+// failure is only logged: the next restart then resyncs some repos.
+func (d *Daemon) saveState() {
+	if d.statePath == "" {
+		return
+	}
+	// Held from snapshot to rename, so an older snapshot never replaces a newer one
+	d.state.saving.Lock()
+	defer d.state.saving.Unlock()
+
+	// Keep the repos of other daemons sharing the file
+	state, err := loadState(d.statePath)
+	if err != nil {
+		state = persistedState{Repos: map[string]persistedRepo{}}
+	}
+
+	d.state.mu.Lock()
+	for _, repo := range d.repos {
+		head, ok := d.headHash[repo.Name]
+		if !ok {
+			delete(state.Repos, repo.Name)
+			continue
+		}
+		saved := persistedRepo{
+			Path:   repo.Path,
+			Head:   head,
+			Branch: d.branches[repo.Name],
+			Files:  d.fileHashes[repo.Name],
+			Dirty:  d.dirty[repo.Name],
+		}
+		if s, ok := d.state.repos[repo.Name]; ok {
+			saved.SyncedAt = s.LastSync
+		}
+		state.Repos[repo.Name] = saved
+	}
+	// Marshal under the lock: syncs replace the maps, never modify them
+	data, err := json.Marshal(state)
+	d.state.mu.Unlock()
+	if err != nil {
+		d.logger.Warn("failed to save daemon state", "error", err)
+		return
+	}
+
+	if err := writeStateFile(d.statePath, data); err != nil {
+		d.logger.Warn("failed to save daemon state", "path", d.statePath, "error", err)
+	}
+}
+
+func writeStateFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("write daemon state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write daemon state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package sync
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemonStateRoundTrip(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "state", "daemon-state.json")
+	repos := []RepoWatch{{Name: "api", Path: "/repos/api"}, {Name: "web", Path: "/repos/web"}}
+	synced := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	d := NewDaemon(repos, 0, nil, logger)
+	d.SetStatePath(path) // Missing file: nothing to load
+	d.headHash["api"] = "abc123"
+	d.branches["api"] = "main"
+	d.fileHashes["api"] = map[string]string{"main.py": "h1"}
+	d.dirty["api"] = map[string]string{"wip.py": "h2"}
+	d.update(repos[0], func(s *RepoStatus) { s.LastSync = synced })
+	d.saveState()
+
+	restarted := NewDaemon(repos, 0, nil, logger)
+	restarted.SetStatePath(path)
+	assert.Equal(t, map[string]string{"api": "abc123"}, restarted.headHash)
+	assert.Equal(t, map[string]string{"api": "main"}, restarted.branches)
+	assert.Equal(t, map[string]string{"main.py": "h1"}, restarted.fileHashes["api"])
+	assert.Equal(t, map[string]string{"wip.py": "h2"}, restarted.dirty["api"])
+	status := restarted.Status()
+	assert.Equal(t, "abc123", status[0].Head)
+	assert.True(t, status[0].LastSync.Equal(synced))
+	assert.Empty(t, status[1].Head, "repos never synced stay unknown")
+
+	// Another daemon watching only web keeps api's entry in the shared file
+	other := NewDaemon(repos[1:], 0, nil, logger)
+	other.SetStatePath(path)
+	other.headHash["web"] = "def456"
+	other.saveState()
+	state, err := loadState(path)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", state.Repos["api"].Head)
+	assert.Equal(t, "def456", state.Repos["web"].Head)
+}
+
+func TestDaemonStateIgnoresMovedAndCorrupt(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "daemon-state.json")
+
+	d := NewDaemon([]RepoWatch{{Name: "api", Path: "/repos/api"}}, 0, nil, logger)
+	d.SetStatePath(path)
+	d.headHash["api"] = "abc123"
+	d.saveState()
+
+	moved := NewDaemon([]RepoWatch{{Name: "api", Path: "/elsewhere/api"}}, 0, nil, logger)
+	moved.SetStatePath(path)
+	assert.Empty(t, moved.headHash, "a repo at another path is a different checkout")
+
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0644))
+	corrupt := NewDaemon([]RepoWatch{{Name: "api", Path: "/repos/api"}}, 0, nil, logger)
+	corrupt.SetStatePath(path)
+	assert.Empty(t, corrupt.headHash)
+}
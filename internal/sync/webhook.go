@@ -0,0 +1,325 @@
+package sync
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/randalmurphal/code-indexer/internal/indexer"
+)
+
+// maxWebhookBody bounds push payloads; GitHub caps its own at 25 MB.
+const maxWebhookBody = 25 << 20
+
+// Webhook receives GitHub and GitLab push webhooks and syncs the pushed
+// repos through a Daemon, so shared servers need not poll. Pushes are
+// answered right away and synced in the background one repo at a time;
+// pushes arriving while a repo is queued are coalesced into one sync.
+type Webhook struct {
+	daemon  *Daemon
+	secret  []byte
+	remotes map[string][]string      // Repo name → normalized remote URLs
+	pending map[string]chan struct{} // Repo name → queued sync; capacity 1
+	wake    chan struct{}
+}
+
+// NewWebhook serves pushes for the daemon's repos, which are matched to
+// payloads by their git remote URLs, else by name. GitHub deliveries must be
+// signed with secret (X-Hub-Signature-256); GitLab ones must carry it as
+// their token (X-Gitlab-Token). Syncs pull with --ff-only, and run
+// incrementally when the daemon has a graph store.
+func NewWebhook(ctx context.Context, d *Daemon, secret string) (*Webhook, error) {
+	if secret == "" {
+		return nil, errors.New("webhook secret is empty")
+	}
+	d.trigger = "webhook"
+	w := &Webhook{
+		daemon:  d,
+		secret:  []byte(secret),
+		remotes: make(map[string][]string),
+		pending: make(map[string]chan struct{}),
+		wake:    make(chan struct{}, 1),
+	}
+	for _, repo := range d.repos {
+		w.remotes[repo.Name] = gitRemotes(ctx, repo.Path)
+		w.pending[repo.Name] = make(chan struct{}, 1)
+	}
+	return w, nil
+}
+
+// Run syncs every repo once, catching up on pushes missed while down, then
+// syncs queued repos on the daemon's worker pool until ctx is done.
+func (w *Webhook) Run(ctx context.Context) error {
+	d := w.daemon
+	d.logger.Info("starting webhook sync", "repos", len(d.repos), "concurrency", d.concurrency, "incremental", d.graphStore != nil)
+	wait := d.startWorkers(ctx)
+	defer wait()
+
+	for _, repo := range d.repos {
+		w.enqueue(repo.Name)
+	}
+	forced := func(ctx context.Context, ix *indexer.Indexer, repo RepoWatch) {
+		d.forget(repo)
+		w.pullAndSync(ctx, ix, repo)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info("webhook sync shutting down")
+			return ctx.Err()
+		case <-w.wake:
+		case <-d.state.wake:
+		case <-d.state.finished:
+		}
+		d.submitForced(forced)
+		for _, repo := range d.repos {
+			select {
+			case <-w.pending[repo.Name]:
+			default:
+				continue
+			}
+			if d.paused(repo) {
+				d.logger.Info("repo paused, skipping push", "repo", repo.Name)
+				continue
+			}
+			submitted := d.trySubmit(repo, func(ctx context.Context, ix *indexer.Indexer) {
+				w.pullAndSync(ctx, ix, repo)
+			})
+			if !submitted {
+				// Retried when the running sync finishes
+				select {
+				case w.pending[repo.Name] <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// enqueue queues a sync of repo unless one is already queued.
+func (w *Webhook) enqueue(repo string) {
+	select {
+	case w.pending[repo] <- struct{}{}:
+	default:
+	}
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pullAndSync fast-forwards the repo's checkout, then syncs it. A failed
+// pull (diverged history, unreachable remote) skips the sync.
+func (w *Webhook) pullAndSync(ctx context.Context, ix *indexer.Indexer, repo RepoWatch) {
+	d := w.daemon
+	cmd := exec.CommandContext(ctx, "git", "-C", repo.Path, "pull", "--ff-only", "--quiet")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		d.logger.Error("git pull failed", "repo", repo.Name, "error", err, "output", strings.TrimSpace(string(output)))
+		d.recordError(ctx, repo, fmt.Errorf("git pull: %w", err))
+		return
+	}
+	if err := d.syncRepo(ctx, ix, repo); err != nil {
+		d.logger.Error("sync failed", "repo", repo.Name, "error", err)
+	}
+}
+
+// pushEvent holds the fields of GitHub and GitLab push payloads used to
+// match a watched repo.
+type pushEvent struct {
+	Ref        string `json:"ref"`
+	Deleted    bool   `json:"deleted"` // GitHub
+	After      string `json:"after"`
+	Repository struct {
+		Name       string `json:"name"`
+		CloneURL   string `json:"clone_url"`    // GitHub
+		SSHURL     string `json:"ssh_url"`      // GitHub
+		HTMLURL    string `json:"html_url"`     // GitHub
+		GitHTTPURL string `json:"git_http_url"` // GitLab
+		GitSSHURL  string `json:"git_ssh_url"`  // GitLab
+	} `json:"repository"`
+	Project struct { // GitLab
+		Name       string `json:"name"`
+		WebURL     string `json:"web_url"`
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+	} `json:"project"`
+}
+
+func (e *pushEvent) names() []string {
+	return nonEmpty(e.Repository.Name, e.Project.Name)
+}
+
+func (e *pushEvent) urls() []string {
+	return nonEmpty(e.Repository.CloneURL, e.Repository.SSHURL, e.Repository.HTMLURL,
+		e.Repository.GitHTTPURL, e.Repository.GitSSHURL,
+		e.Project.WebURL, e.Project.GitHTTPURL, e.Project.GitSSHURL)
+}
+
+// ServeHTTP verifies a delivery and queues a sync of the repo it pushed to.
+// Pings, other events, tags, deleted branches, and pushes to branches other
+// than the one checked out are acknowledged and ignored.
+func (w *Webhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(rw, "failed to read body", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !w.verify(r.Header, body) {
+		w.daemon.logger.Warn("webhook rejected: bad signature or token", "remote", r.RemoteAddr)
+		http.Error(rw, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	switch event := firstNonEmpty(r.Header.Get("X-GitHub-Event"), r.Header.Get("X-Gitlab-Event")); event {
+	case "push", "Push Hook":
+	case "ping":
+		writeWebhookStatus(rw, http.StatusOK, "pong", "")
+		return
+	default:
+		writeWebhookStatus(rw, http.StatusOK, "ignored", "")
+		return
+	}
+
+	var push pushEvent
+	if err := json.Unmarshal(body, &push); err != nil {
+		http.Error(rw, "invalid push payload", http.StatusBadRequest)
+		return
+	}
+	repo, ok := w.match(&push)
+	if !ok {
+		w.daemon.logger.Warn("webhook push for an unwatched repo", "repos", push.names(), "urls", push.urls())
+		http.Error(rw, "no watched repo matches the push", http.StatusNotFound)
+		return
+	}
+	branch, isBranch := strings.CutPrefix(push.Ref, "refs/heads/")
+	if !isBranch || push.Deleted || strings.Trim(push.After, "0") == "" {
+		writeWebhookStatus(rw, http.StatusOK, "ignored", repo.Name)
+		return
+	}
+	if current := w.daemon.getGitBranch(repo.Path); current != branch {
+		w.daemon.logger.Debug("push to another branch ignored", "repo", repo.Name, "branch", branch, "checked_out", current)
+		writeWebhookStatus(rw, http.StatusOK, "ignored", repo.Name)
+		return
+	}
+
+	w.daemon.logger.Info("push received, queueing sync", "repo", repo.Name, "branch", branch, "after", truncateHash(push.After))
+	w.enqueue(repo.Name)
+	writeWebhookStatus(rw, http.StatusAccepted, "queued", repo.Name)
+}
+
+// verify checks a GitHub HMAC-SHA256 signature of the body, or a GitLab
+// token, in constant time.
+func (w *Webhook) verify(h http.Header, body []byte) bool {
+	if sig := h.Get("X-Hub-Signature-256"); sig != "" {
+		got, err := hex.DecodeString(strings.TrimPrefix(sig, "sha256="))
+		if err != nil || !strings.HasPrefix(sig, "sha256=") {
+			return false
+		}
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+	if token := h.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), w.secret) == 1
+	}
+	return false
+}
+
+// match finds the watched repo a push is for: by remote URL, else by the
+// repo name or the name in its config.
+func (w *Webhook) match(push *pushEvent) (RepoWatch, bool) {
+	for _, u := range push.urls() {
+		want := normalizeRemote(u)
+		for _, repo := range w.daemon.repos {
+			for _, remote := range w.remotes[repo.Name] {
+				if remote == want {
+					return repo, true
+				}
+			}
+		}
+	}
+	for _, name := range push.names() {
+		for _, repo := range w.daemon.repos {
+			if repo.Name == name || (repo.Config != nil && repo.Config.Name == name) {
+				return repo, true
+			}
+		}
+	}
+	return RepoWatch{}, false
+}
+
+// gitRemotes returns the normalized fetch URLs of a repo's remotes.
+func gitRemotes(ctx context.Context, repoPath string) []string {
+	output, err := exec.CommandContext(ctx, "git", "-C", repoPath, "remote", "-v").Output()
+	if err != nil {
+		return nil
+	}
+	var remotes []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[2] == "(fetch)" {
+			remotes = append(remotes, normalizeRemote(fields[1]))
+		}
+	}
+	return remotes
+}
+
+// normalizeRemote reduces a git remote or web URL to host/path so HTTPS,
+// SSH, and scp-like forms of one repo compare equal:
+// git@github.com:org/repo.git and https://github.com/org/repo both become
+// github.com/org/repo.
+func normalizeRemote(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		remote = u.Hostname() + u.Path
+	} else if at := strings.Index(remote, "@"); at >= 0 {
+		// scp-like: user@host:path
+		remote = strings.Replace(remote[at+1:], ":", "/", 1)
+	}
+	remote = strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git")
+	return strings.ToLower(remote)
+}
+
+func writeWebhookStatus(rw http.ResponseWriter, code int, status, repo string) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	resp := map[string]string{"status": status}
+	if repo != "" {
+		resp["repo"] = repo
+	}
+	_ = json.NewEncoder(rw).Encode(resp)
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
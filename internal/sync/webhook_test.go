@@ -0,0 +1,133 @@
+package sync
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeRemote(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:Org/App.git":              "github.com/org/app",
+		"https://github.com/org/app":              "github.com/org/app",
+		"https://token@github.com/org/app.git":    "github.com/org/app",
+		"ssh://git@gitlab.example.com:2222/g/app": "gitlab.example.com/g/app",
+		"http://gitlab.example.com/g/app.git/":    "gitlab.example.com/g/app",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, normalizeRemote(in), in)
+	}
+}
+
+// newTestWebhook returns a webhook for a repo on branch main with an
+// origin of git@github.com:org/app.git.
+func newTestWebhook(t *testing.T) *Webhook {
+	t.Helper()
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-b", "main")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "Test")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.py"), []byte("x = 1\n"), 0644))
+	git("add", ".")
+	git("commit", "-m", "initial")
+	git("remote", "add", "origin", "git@github.com:org/app.git")
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	d := NewDaemon([]RepoWatch{{Name: "app-local", Path: dir}}, 0, nil, logger)
+	w, err := NewWebhook(context.Background(), d, "s3cret")
+	require.NoError(t, err)
+	return w
+}
+
+func githubPush(t *testing.T, secret, event, body string) *http.Request {
+	t.Helper()
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func queued(w *Webhook, repo string) bool {
+	select {
+	case <-w.pending[repo]:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestWebhookGitHubPush(t *testing.T) {
+	w := newTestWebhook(t)
+	push := `{"ref":"refs/heads/main","after":"0123456789abcdef","repository":{"name":"app","ssh_url":"git@github.com:org/app.git"}}`
+
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, githubPush(t, "s3cret", "push", push))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"repo":"app-local"`, "matched by remote, not by name")
+	assert.True(t, queued(w, "app-local"))
+
+	rec = httptest.NewRecorder()
+	w.ServeHTTP(rec, githubPush(t, "wrong", "push", push))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.False(t, queued(w, "app-local"))
+
+	rec = httptest.NewRecorder()
+	w.ServeHTTP(rec, githubPush(t, "s3cret", "ping", `{"zen":"hi"}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "pong")
+
+	other := strings.Replace(push, "refs/heads/main", "refs/heads/feature", 1)
+	rec = httptest.NewRecorder()
+	w.ServeHTTP(rec, githubPush(t, "s3cret", "push", other))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "ignored")
+	assert.False(t, queued(w, "app-local"), "pushes to other branches are not pulled")
+
+	unknown := `{"ref":"refs/heads/main","after":"01234567","repository":{"name":"other","clone_url":"https://github.com/org/other.git"}}`
+	rec = httptest.NewRecorder()
+	w.ServeHTTP(rec, githubPush(t, "s3cret", "push", unknown))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestWebhookGitLabPush(t *testing.T) {
+	w := newTestWebhook(t)
+	push := `{"object_kind":"push","ref":"refs/heads/main","after":"0123456789abcdef","project":{"name":"app","git_http_url":"https://github.com/org/app.git"}}`
+
+	for token, want := range map[string]int{"s3cret": http.StatusAccepted, "s3cre": http.StatusUnauthorized, "": http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(push))
+		req.Header.Set("X-Gitlab-Event", "Push Hook")
+		if token != "" {
+			req.Header.Set("X-Gitlab-Token", token)
+		}
+		rec := httptest.NewRecorder()
+		w.ServeHTTP(rec, req)
+		assert.Equal(t, want, rec.Code, "token %q", token)
+	}
+	assert.True(t, queued(w, "app-local"))
+}
+
+func TestNewWebhookRequiresSecret(t *testing.T) {
+	d := NewDaemon(nil, 0, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	_, err := NewWebhook(context.Background(), d, "")
+	assert.Error(t, err)
+}
@@ -105,7 +105,7 @@ func TestMCPServerProtocol(t *testing.T) {
 
 		tools, ok := result["tools"].([]interface{})
 		require.True(t, ok)
-		require.Len(t, tools, 1, "should have 1 tool")
+		require.Len(t, tools, 16, "should have 16 tools")
 
 		tool := tools[0].(map[string]interface{})
 		assert.Equal(t, "search_code", tool["name"])